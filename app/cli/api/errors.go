@@ -44,6 +44,10 @@ func HandleApiError(r *http.Response, errBody []byte) *shared.ApiError {
 
 func refreshAuthIfNeeded(apiErr *shared.ApiError) (bool, *shared.ApiError) {
 	if apiErr.Type == shared.ApiErrorTypeInvalidToken {
+		// API tokens can't be refreshed--a new one has to be created
+		if auth.IsApiTokenAuth() {
+			return false, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: "API token is invalid, expired or revoked"}
+		}
 		err := auth.RefreshInvalidToken()
 		if err != nil {
 			return false, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: "error refreshing invalid token"}
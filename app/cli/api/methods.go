@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"plandex-cli/types"
 	"strings"
 
@@ -599,13 +600,64 @@ func (a *Api) RespondMissingFile(planId, branch string, req shared.RespondMissin
 
 }
 
-func (a *Api) ConnectPlan(planId, branch string, onStream types.OnStreamPlan) *shared.ApiError {
+func (a *Api) RespondDiagnostics(planId, branch string, req shared.DiagnosticsResponse) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/diagnostics", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPost, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+
+		if didRefresh {
+			return a.RespondDiagnostics(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+// ConnectPlan attaches to an active plan stream. With resumeFromSeq > 0, the server replays the messages sent after that seq instead of re-sending the current state, if it still has them. connectReq is optional--it lets the server resume the stream if the server running it went away.
+func (a *Api) ConnectPlan(planId, branch string, resumeFromSeq int64, connectReq *shared.ConnectPlanRequest, onStream types.OnStreamPlan) *shared.ApiError {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/connect", GetApiHost(), planId, branch)
+	if resumeFromSeq > 0 {
+		serverUrl += fmt.Sprintf("?resumeFrom=%d", resumeFromSeq)
+	}
 
-	req, err := http.NewRequest(http.MethodPatch, serverUrl, nil)
+	var body io.Reader
+	if connectReq != nil {
+		reqBytes, err := json.Marshal(connectReq)
+		if err != nil {
+			return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+		}
+		body = bytes.NewBuffer(reqBytes)
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, serverUrl, body)
 	if err != nil {
 		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
+	if connectReq != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := authenticatedStreamingClient.Do(req)
 	if err != nil {
@@ -619,7 +671,7 @@ func (a *Api) ConnectPlan(planId, branch string, onStream types.OnStreamPlan) *s
 		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
 
 		if didRefresh {
-			return a.ConnectPlan(planId, branch, onStream)
+			return a.ConnectPlan(planId, branch, resumeFromSeq, connectReq, onStream)
 		}
 
 		return apiErr
@@ -895,6 +947,74 @@ func (a *Api) RejectFile(planId, branch, filePath string) *shared.ApiError {
 	return nil
 }
 
+func (a *Api) RejectReplacement(planId, branch string, req shared.RejectReplacementRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/reject_replacement", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(httpReq)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.RejectReplacement(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) EditReplacement(planId, branch string, req shared.EditReplacementRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/edit_replacement", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(httpReq)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.EditReplacement(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
 func (a *Api) RejectFiles(planId, branch string, paths []string) *shared.ApiError {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/reject_files", GetApiHost(), planId, branch)
 
@@ -1318,6 +1438,91 @@ func (a *Api) CreateAccount(req shared.CreateAccountRequest, customHost string)
 	return &sessionResponse, nil
 }
 
+func (a *Api) GetOidcConfig(customHost string) (*shared.OidcConfigResponse, *shared.ApiError) {
+	host := customHost
+	if host == "" {
+		host = CloudApiHost
+	}
+	serverUrl := host + "/accounts/oidc"
+
+	resp, err := unauthenticatedClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		return nil, HandleApiError(resp, errorBody)
+	}
+
+	var res shared.OidcConfigResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) StartOidcDeviceAuth(customHost string) (*shared.StartOidcDeviceAuthResponse, *shared.ApiError) {
+	host := customHost
+	if host == "" {
+		host = CloudApiHost
+	}
+	serverUrl := host + "/accounts/oidc/device"
+
+	resp, err := unauthenticatedClient.Post(serverUrl, "application/json", nil)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		return nil, HandleApiError(resp, errorBody)
+	}
+
+	var res shared.StartOidcDeviceAuthResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) PollOidcDeviceAuth(req shared.PollOidcDeviceAuthRequest, customHost string) (*shared.PollOidcDeviceAuthResponse, *shared.ApiError) {
+	host := customHost
+	if host == "" {
+		host = CloudApiHost
+	}
+	serverUrl := host + "/accounts/oidc/device/token"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := unauthenticatedClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		return nil, HandleApiError(resp, errorBody)
+	}
+
+	var res shared.PollOidcDeviceAuthResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
 func (a *Api) CreateOrg(req shared.CreateOrgRequest) (*shared.CreateOrgResponse, *shared.ApiError) {
 	serverUrl := GetApiHost() + "/orgs"
 	reqBytes, err := json.Marshal(req)
@@ -1432,42 +1637,20 @@ func (a *Api) DeleteUser(userId string) *shared.ApiError {
 	return nil
 }
 
-func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/orgs/roles"
-	resp, err := authenticatedFastClient.Get(serverUrl)
-	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		errorBody, _ := io.ReadAll(resp.Body)
-
-		apiErr := HandleApiError(resp, errorBody)
-		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
-		if authRefreshed {
-			return a.ListOrgRoles()
-		}
-		return nil, apiErr
-	}
-
-	var roles []*shared.OrgRole
-	err = json.NewDecoder(resp.Body).Decode(&roles)
+func (a *Api) SetUserRole(userId string, req shared.SetOrgRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/users/%s/role", GetApiHost(), userId)
+	reqBytes, err := json.Marshal(req)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
 
-	return roles, nil
-}
-
-func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
-	serverUrl := GetApiHost() + "/invites"
-	reqBytes, err := json.Marshal(req)
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
+	request.Header.Set("Content-Type", "application/json")
 
-	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	resp, err := authenticatedFastClient.Do(request)
 	if err != nil {
 		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
@@ -1479,7 +1662,7 @@ func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
 
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.InviteUser(req)
+			return a.SetUserRole(userId, req)
 		}
 		return apiErr
 	}
@@ -1487,97 +1670,307 @@ func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
 	return nil
 }
 
-func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/pending"
+func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
+
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListPendingInvites()
+			return a.ListOrgRoles()
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var roles []*shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&roles)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
 	}
 
-	return invites, nil
+	return roles, nil
 }
 
-func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/accepted"
+func (a *Api) GetCurrentOrgRole() (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles/current"
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
+
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListAcceptedInvites()
+			return a.GetCurrentOrgRole()
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
 	}
 
-	return invites, nil
+	return &role, nil
 }
 
-func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/all"
+func (a *Api) ListOrgPermissions() ([]*shared.OrgPermission, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/permissions"
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
+
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListAllInvites()
+			return a.ListOrgPermissions()
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var permissions []*shared.OrgPermission
+	err = json.NewDecoder(resp.Body).Decode(&permissions)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
 	}
 
-	return invites, nil
+	return permissions, nil
 }
 
-func (a *Api) DeleteInvite(inviteId string) *shared.ApiError {
-	serverUrl := fmt.Sprintf("%s/invites/%s", GetApiHost(), inviteId)
-	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+func (a *Api) CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
+	reqBytes, err := json.Marshal(req)
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
 
-	resp, err := authenticatedFastClient.Do(req)
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateOrgRole(req)
+		}
+		return nil, apiErr
+	}
+
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &role, nil
+}
+
+func (a *Api) UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpdateOrgRole(roleId, req)
+		}
+		return nil, apiErr
+	}
+
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &role, nil
+}
+
+func (a *Api) DeleteOrgRole(roleId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteOrgRole(roleId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/invites"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.InviteUser(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/pending"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPendingInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/accepted"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAcceptedInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/all"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAllInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) DeleteInvite(inviteId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/invites/%s", GetApiHost(), inviteId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -1595,6 +1988,39 @@ func (a *Api) DeleteInvite(inviteId string) *shared.ApiError {
 	return nil
 }
 
+func (a *Api) SetInviteRole(inviteId string, req shared.SetOrgRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/invites/%s/role", GetApiHost(), inviteId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetInviteRole(inviteId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
 func (a *Api) CreateEmailVerification(email, customHost, userId string) (*shared.CreateEmailVerificationResponse, *shared.ApiError) {
 	host := customHost
 	if host == "" {
@@ -2414,6 +2840,38 @@ func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapRespon
 	return &respBody, nil
 }
 
+func (a *Api) GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_map/symbols", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileSymbols(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileSymbolsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
 func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context/%s/body", GetApiHost(), planId, branch, contextId)
 
@@ -2511,3 +2969,791 @@ func (a *Api) GetBuildStatus(planId, branch string) (*shared.GetBuildStatusRespo
 
 	return &respBody, nil
 }
+
+func (a *Api) ListSpendBudgets() (*shared.ListSpendBudgetsResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/spend_budgets", GetApiHost())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListSpendBudgets()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListSpendBudgetsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) SetSpendBudget(req shared.SetSpendBudgetRequest) (*shared.SpendBudget, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/spend_budgets", GetApiHost())
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(httpReq)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetSpendBudget(req)
+		}
+		return nil, apiErr
+	}
+
+	var budget shared.SpendBudget
+	err = json.NewDecoder(resp.Body).Decode(&budget)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &budget, nil
+}
+
+func (a *Api) DeleteSpendBudget(budgetId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/spend_budgets/%s", GetApiHost(), budgetId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteSpendBudget(budgetId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetUsageSummary(req shared.UsageRequest) (*shared.UsageSummaryResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/usage/summary", GetApiHost())
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetUsageSummary(req)
+		}
+		return nil, apiErr
+	}
+
+	var res *shared.UsageSummaryResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res, nil
+}
+
+func (a *Api) GetUsageLog(pageSize, pageNum int, req shared.UsageRequest) (*shared.UsageLogResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/usage/log?size=%d&page=%d", GetApiHost(), pageSize, pageNum)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetUsageLog(pageSize, pageNum, req)
+		}
+		return nil, apiErr
+	}
+
+	var res *shared.UsageLogResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res, nil
+}
+
+func (a *Api) GetOrgExecPolicy() (*shared.ExecPolicy, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/exec_policy", GetApiHost())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetOrgExecPolicy()
+		}
+		return nil, apiErr
+	}
+
+	var policy shared.ExecPolicy
+	err = json.NewDecoder(resp.Body).Decode(&policy)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &policy, nil
+}
+
+func (a *Api) SetOrgExecPolicy(policy shared.ExecPolicy) (*shared.ExecPolicy, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/exec_policy", GetApiHost())
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(httpReq)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetOrgExecPolicy(policy)
+		}
+		return nil, apiErr
+	}
+
+	var updated shared.ExecPolicy
+	err = json.NewDecoder(resp.Body).Decode(&updated)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &updated, nil
+}
+
+func (a *Api) DeleteOrgExecPolicy() *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/exec_policy", GetApiHost())
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteOrgExecPolicy()
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListWebhooks() ([]*shared.Webhook, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/webhooks", GetApiHost())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListWebhooks()
+		}
+		return nil, apiErr
+	}
+
+	var webhooks []*shared.Webhook
+	err = json.NewDecoder(resp.Body).Decode(&webhooks)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return webhooks, nil
+}
+
+func (a *Api) CreateWebhook(req shared.CreateWebhookRequest) (*shared.CreateWebhookResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/webhooks", GetApiHost())
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateWebhook(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.CreateWebhookResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) DeleteWebhook(webhookId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s", GetApiHost(), webhookId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteWebhook(webhookId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) TestWebhook(webhookId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s/test", GetApiHost(), webhookId)
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.TestWebhook(webhookId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListWebhookDeliveries(webhookId string) (*shared.ListWebhookDeliveriesResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s/deliveries", GetApiHost(), webhookId)
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListWebhookDeliveries(webhookId)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListWebhookDeliveriesResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ListApiTokens(serviceAccountId string) ([]*shared.ApiToken, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/api_tokens", GetApiHost())
+	if serviceAccountId != "" {
+		serverUrl += "?serviceAccountId=" + url.QueryEscape(serviceAccountId)
+	}
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListApiTokens(serviceAccountId)
+		}
+		return nil, apiErr
+	}
+
+	var res []*shared.ApiToken
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res, nil
+}
+
+func (a *Api) CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/api_tokens", GetApiHost())
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateApiToken(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.CreateApiTokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) RevokeApiToken(tokenId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/api_tokens/%s", GetApiHost(), tokenId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.RevokeApiToken(tokenId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetApiTokenSession() (*shared.ApiTokenSessionResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/api_tokens/current", GetApiHost())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetApiTokenSession()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ApiTokenSessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ListServiceAccounts() ([]*shared.ServiceAccount, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/service_accounts", GetApiHost())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListServiceAccounts()
+		}
+		return nil, apiErr
+	}
+
+	var res []*shared.ServiceAccount
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res, nil
+}
+
+func (a *Api) CreateServiceAccount(req shared.CreateServiceAccountRequest) (*shared.ServiceAccount, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/service_accounts", GetApiHost())
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateServiceAccount(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ServiceAccount
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) DeleteServiceAccount(serviceAccountId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/service_accounts/%s", GetApiHost(), serviceAccountId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteServiceAccount(serviceAccountId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ExportPlan(planId string, w io.Writer) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/export", GetApiHost(), planId)
+
+	// no timeout since archives of large plans can take a while to download
+	resp, err := authenticatedStreamingClient.Get(serverUrl)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ExportPlan(planId, w)
+		}
+		return apiErr
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error reading response: %v", err)}
+	}
+
+	return nil
+}
+
+func (a *Api) ImportPlan(projectId string, archive io.ReadSeeker) (*shared.ImportPlanResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/plans/import", GetApiHost(), projectId)
+
+	_, err := archive.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error reading archive: %v", err)}
+	}
+
+	resp, err := authenticatedStreamingClient.Post(serverUrl, "application/gzip", archive)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ImportPlan(projectId, archive)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ImportPlanResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ListSharedPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/shared?", GetApiHost())
+	parts := []string{}
+	for _, projectId := range projectIds {
+		parts = append(parts, fmt.Sprintf("projectId=%s", projectId))
+	}
+	serverUrl += strings.Join(parts, "&")
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListSharedPlans(projectIds)
+		}
+		return nil, apiErr
+	}
+
+	var plans []*shared.Plan
+	err = json.NewDecoder(resp.Body).Decode(&plans)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return plans, nil
+}
+
+func (a *Api) ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanShares(planId)
+		}
+		return nil, apiErr
+	}
+
+	var shares []*shared.PlanShare
+	err = json.NewDecoder(resp.Body).Decode(&shares)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return shares, nil
+}
+
+func (a *Api) SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SharePlan(planId, req)
+		}
+		return nil, apiErr
+	}
+
+	var share shared.PlanShare
+	err = json.NewDecoder(resp.Body).Decode(&share)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &share, nil
+}
+
+func (a *Api) UnsharePlan(planId, userId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares/%s", GetApiHost(), planId, userId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UnsharePlan(planId, userId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
@@ -188,6 +188,26 @@ func promptSignInNewAccount() error {
 		if selected == SignInLocalOption {
 			email = "local-admin@plandex.ai"
 		} else {
+			if oidcConfig := getOidcConfig(host); oidcConfig != nil {
+				useSSO, err := promptOidcSignIn(oidcConfig)
+				if err != nil {
+					return err
+				}
+
+				if useSSO {
+					err = signInWithOidc(host)
+					if err != nil {
+						return fmt.Errorf("error signing in with SSO: %v", err)
+					}
+
+					if !term.IsRepl {
+						term.PrintCmds("", "")
+					}
+
+					return nil
+				}
+			}
+
 			email, err = term.GetRequiredUserStringInput("Your email:")
 		}
 
@@ -20,6 +20,13 @@ func SetAuthHeader(req *http.Request) error {
 	if Current == nil {
 		return fmt.Errorf("error setting auth header: auth not loaded")
 	}
+
+	// API tokens are sent as-is--the token identifies the org
+	if IsApiTokenAuth() {
+		req.Header.Set("Authorization", "Bearer "+Current.Token)
+		return nil
+	}
+
 	hash := Current.ToHash()
 
 	authHeader := shared.AuthHeader{
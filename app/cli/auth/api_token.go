@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	shared "plandex-shared"
+)
+
+// ApiTokenEnvVar holds an API token for headless use, like in CI. When it's set, it's used instead of auth.json, and nothing is written to disk.
+const ApiTokenEnvVar = "PLANDEX_API_TOKEN"
+
+func IsApiTokenAuth() bool {
+	return Current != nil && shared.IsApiToken(Current.Token)
+}
+
+func resolveApiTokenAuth(token string) error {
+	if !shared.IsApiToken(token) {
+		return fmt.Errorf("%s isn't a Plandex API token", ApiTokenEnvVar)
+	}
+
+	// with no host, requests go to the cloud host, which PLANDEX_API_HOST also sets
+	host := os.Getenv("PLANDEX_API_HOST")
+
+	Current = &shared.ClientAuth{
+		ClientAccount: shared.ClientAccount{
+			IsCloud: host == "",
+			Host:    host,
+			Token:   token,
+		},
+	}
+
+	res, apiErr := apiClient.GetApiTokenSession()
+
+	if apiErr != nil {
+		return fmt.Errorf("error getting API token session: %v", apiErr.Msg)
+	}
+
+	Current.UserId = res.UserId
+	Current.Email = res.Email
+	Current.UserName = res.UserName
+
+	if res.Org != nil {
+		Current.OrgId = res.Org.Id
+		Current.OrgName = res.Org.Name
+		Current.OrgIsTrial = res.Org.IsTrial
+		Current.IntegratedModelsMode = res.Org.IntegratedModelsMode
+	}
+
+	return nil
+}
@@ -30,6 +30,20 @@ func MustResolveAuth(requireOrg bool) {
 		term.OutputErrorAndExit("error resolving auth: api client not set")
 	}
 
+	if token := os.Getenv(ApiTokenEnvVar); token != "" {
+		if IsApiTokenAuth() {
+			return
+		}
+
+		err := resolveApiTokenAuth(token)
+
+		if err != nil {
+			term.OutputErrorAndExit("error resolving auth: %v", err)
+		}
+
+		return
+	}
+
 	// load HomeAuthPath file into ClientAuth struct
 	bytes, err := os.ReadFile(fs.HomeAuthPath)
 
@@ -91,6 +105,12 @@ func RefreshInvalidToken() error {
 	if Current == nil {
 		return fmt.Errorf("error refreshing token: auth not loaded")
 	}
+
+	// hosts that require single sign-on don't send email pins
+	if oidcConfig := getOidcConfig(Current.Host); oidcConfig != nil && oidcConfig.Required {
+		return signInWithOidc(Current.Host)
+	}
+
 	res, err := verifyEmail(Current.Email, Current.Host)
 
 	if err != nil {
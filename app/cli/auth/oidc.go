@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"fmt"
+	"plandex-cli/term"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const (
+	SignInSSOOption   = "Sign in with SSO"
+	SignInEmailOption = "Sign in with an email pin"
+)
+
+var openURL func(msg, url string)
+
+func SetOpenURLFn(fn func(msg, url string)) {
+	openURL = fn
+}
+
+// getOidcConfig returns the host's single sign-on config, or nil if the host doesn't have it enabled (including older servers without the endpoint)
+func getOidcConfig(host string) *shared.OidcConfigResponse {
+	if host == "" {
+		return nil
+	}
+
+	term.StartSpinner("")
+	res, apiErr := apiClient.GetOidcConfig(host)
+	term.StopSpinner()
+
+	if apiErr != nil || !res.Enabled {
+		return nil
+	}
+
+	return res
+}
+
+// promptOidcSignIn asks whether to use SSO or an email pin when the host allows both. It returns true if SSO was chosen.
+func promptOidcSignIn(config *shared.OidcConfigResponse) (bool, error) {
+	if config.Required {
+		return true, nil
+	}
+
+	ssoOption := fmt.Sprintf("%s (%s)", SignInSSOOption, config.Issuer)
+
+	selected, err := term.SelectFromList("How do you want to sign in?", []string{ssoOption, SignInEmailOption})
+
+	if err != nil {
+		return false, fmt.Errorf("error selecting sign in method: %v", err)
+	}
+
+	return selected == ssoOption, nil
+}
+
+func SignInWithSSO(host string) error {
+	if getOidcConfig(host) == nil {
+		return fmt.Errorf("single sign-on isn't enabled on %s", host)
+	}
+
+	return signInWithOidc(host)
+}
+
+func signInWithOidc(host string) error {
+	term.StartSpinner("")
+	deviceAuth, apiErr := apiClient.StartOidcDeviceAuth(host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return fmt.Errorf("error starting SSO sign in: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("🔑 Your sign in code is %s\n\n", color.New(color.Bold, term.ColorHiYellow).Sprint(deviceAuth.UserCode))
+
+	url := deviceAuth.VerificationUriComplete
+	if url == "" {
+		url = deviceAuth.VerificationUri
+	}
+
+	if openURL != nil {
+		openURL("Opening your identity provider to confirm the code...", url)
+	} else {
+		fmt.Printf("Open this URL to confirm the code:\n%s\n", url)
+	}
+	fmt.Println()
+
+	term.StartSpinner("Waiting for confirmation...")
+	res, err := pollOidcDeviceAuth(deviceAuth, host)
+	term.StopSpinner()
+
+	if err != nil {
+		return err
+	}
+
+	return handleSignInResponse(res.Session, host)
+}
+
+func pollOidcDeviceAuth(deviceAuth *shared.StartOidcDeviceAuthResponse, host string) (*shared.PollOidcDeviceAuthResponse, error) {
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(deviceAuth.ExpiresIn) * time.Second)
+
+	for {
+		if deviceAuth.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("sign in code expired before it was confirmed")
+		}
+
+		time.Sleep(interval)
+
+		res, apiErr := apiClient.PollOidcDeviceAuth(shared.PollOidcDeviceAuthRequest{
+			DeviceCode: deviceAuth.DeviceCode,
+		}, host)
+
+		if apiErr != nil {
+			return nil, fmt.Errorf("error completing SSO sign in: %v", apiErr.Msg)
+		}
+
+		switch res.Status {
+		case shared.OidcDeviceAuthStatusPending:
+			continue
+		case shared.OidcDeviceAuthStatusSlowDown:
+			interval += 5 * time.Second
+			continue
+		case shared.OidcDeviceAuthStatusComplete:
+			return res, nil
+		default:
+			return nil, fmt.Errorf("unexpected SSO sign in status: %s", res.Status)
+		}
+	}
+}
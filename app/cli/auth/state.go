@@ -91,6 +91,11 @@ func writeCurrentAuth() error {
 		return fmt.Errorf("error writing auth: auth not loaded")
 	}
 
+	// API token auth only lives in memory
+	if IsApiTokenAuth() {
+		return nil
+	}
+
 	bytes, err := json.Marshal(Current)
 
 	if err != nil {
@@ -1,18 +1,21 @@
 package cmd
 
 import (
+	"path/filepath"
 	"plandex-cli/api"
 	"plandex-cli/auth"
+	"plandex-cli/fs"
 	"plandex-cli/lib"
 	"plandex-cli/plan_exec"
 	"plandex-cli/term"
 	"plandex-cli/types"
 	shared "plandex-shared"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
-var autoCommit, skipCommit, autoExec bool
+var autoCommit, skipCommit, autoExec, noVerify bool
 
 func init() {
 	initApplyFlags(applyCmd, false)
@@ -23,9 +26,10 @@ func init() {
 }
 
 var applyCmd = &cobra.Command{
-	Use:     "apply",
+	Use:     "apply [files...]",
 	Aliases: []string{"ap"},
 	Short:   "Apply a plan to the project",
+	Long:    "Apply pending changes to the project. If files or directories are passed, only pending changes to those paths are applied--the rest stay pending.",
 	Run:     apply,
 }
 
@@ -60,6 +64,8 @@ func apply(cmd *cobra.Command, args []string) {
 		AutoExec:    autoExec,
 		NoExec:      noExec,
 		AutoDebug:   autoDebug,
+		ExecSandbox: execSandbox,
+		NoVerify:    noVerify,
 	}
 
 	tellFlags := types.TellFlags{
@@ -77,5 +83,26 @@ func apply(cmd *cobra.Command, args []string) {
 		ApplyFlags: applyFlags,
 		TellFlags:  tellFlags,
 		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+		Paths:      mustResolveProjectRelPaths(args),
 	})
 }
+
+// mustResolveProjectRelPaths converts paths passed on the command line, which are relative to the
+// working directory, into paths relative to the project root
+func mustResolveProjectRelPaths(args []string) []string {
+	var paths []string
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			term.OutputErrorAndExit("Error resolving path %s: %v", arg, err)
+		}
+
+		rel, err := filepath.Rel(fs.ProjectRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			term.OutputErrorAndExit("Path %s is outside the project", arg)
+		}
+
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
@@ -24,6 +24,7 @@ var branchesCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(branchesCmd)
+	supportJsonOutput(branchesCmd)
 }
 
 func branches(cmd *cobra.Command, args []string) {
@@ -45,6 +46,14 @@ func branches(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, map[string]interface{}{
+			"currentBranch": lib.CurrentBranch,
+			"branches":      branches,
+		})
+		return
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
 	table.SetHeader([]string{"#", "Name", "Updated" /* "Created",*/, "Context", "Convo"})
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var budgetCurrentPlan bool
+var budgetAllPlans bool
+var budgetUserEmail string
+var budgetAllUsers bool
+var budgetWarnPct int
+
+var budgetsCmd = &cobra.Command{
+	Use:   "budgets",
+	Short: "List spend budgets for the current org",
+	Run:   listSpendBudgets,
+}
+
+var setBudgetCmd = &cobra.Command{
+	Use:   "set <daily|monthly> <limit-usd>",
+	Short: "Set a daily or monthly spend budget",
+	Long:  "Set a daily or monthly spend budget. Applies to the whole org by default—use --plan or --user to limit a single plan or user, or --all-plans or --all-users to set a default for every plan or user.",
+	Args:  cobra.ExactArgs(2),
+	Run:   setSpendBudget,
+}
+
+var deleteBudgetCmd = &cobra.Command{
+	Use:     "rm [index]",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a spend budget",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteSpendBudget,
+}
+
+func init() {
+	RootCmd.AddCommand(budgetsCmd)
+	budgetsCmd.AddCommand(setBudgetCmd)
+	budgetsCmd.AddCommand(deleteBudgetCmd)
+
+	setBudgetCmd.Flags().BoolVar(&budgetCurrentPlan, "plan", false, "Set the budget for the current plan")
+	setBudgetCmd.Flags().BoolVar(&budgetAllPlans, "all-plans", false, "Set the default budget for each plan")
+	setBudgetCmd.Flags().StringVar(&budgetUserEmail, "user", "", "Set the budget for the user with this email")
+	setBudgetCmd.Flags().BoolVar(&budgetAllUsers, "all-users", false, "Set the default budget for each user")
+	setBudgetCmd.Flags().IntVar(&budgetWarnPct, "warn", 80, "Warn when this percentage of the budget is used (0 to disable)")
+}
+
+func listSpendBudgets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ListSpendBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching spend budgets: %v", apiErr.Msg)
+		return
+	}
+
+	if len(res.Budgets) == 0 {
+		fmt.Println("🤷‍♂️ No spend budgets")
+		fmt.Println()
+		term.PrintCmds("", "budgets set")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Applies To", "Period", "Limit", "Spent", "Warn At"})
+
+	for i, budget := range res.Budgets {
+		spent := "-"
+		if budget.CurrentSpend != nil {
+			spent = formatSpend(*budget.CurrentSpend)
+		}
+
+		warnAt := "-"
+		if budget.WarnPct > 0 {
+			warnAt = fmt.Sprintf("%d%%", budget.WarnPct)
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			budgetTargetLabel(budget, res),
+			string(budget.Period),
+			"$" + budget.LimitUsd.StringFixed(2),
+			spent,
+			warnAt,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "budgets set", "budgets rm", "usage")
+}
+
+func setSpendBudget(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	period := shared.SpendBudgetPeriod(args[0])
+	if period != shared.SpendBudgetPeriodDaily && period != shared.SpendBudgetPeriodMonthly {
+		term.OutputErrorAndExit("Budget period must be 'daily' or 'monthly'")
+		return
+	}
+
+	limit, err := decimal.NewFromString(args[1])
+	if err != nil || !limit.IsPositive() {
+		term.OutputErrorAndExit("Budget limit must be a positive dollar amount")
+		return
+	}
+
+	numTargets := 0
+	for _, set := range []bool{budgetCurrentPlan, budgetAllPlans, budgetUserEmail != "", budgetAllUsers} {
+		if set {
+			numTargets++
+		}
+	}
+	if numTargets > 1 {
+		term.OutputErrorAndExit("Use only one of --plan, --all-plans, --user, or --all-users")
+		return
+	}
+
+	req := shared.SetSpendBudgetRequest{
+		Scope:    shared.SpendBudgetScopeOrg,
+		Period:   period,
+		LimitUsd: limit,
+		WarnPct:  budgetWarnPct,
+	}
+
+	if budgetCurrentPlan {
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		planId := lib.CurrentPlanId
+		req.Scope = shared.SpendBudgetScopePlan
+		req.PlanId = &planId
+	} else if budgetAllPlans {
+		req.Scope = shared.SpendBudgetScopePlan
+	} else if budgetUserEmail != "" {
+		term.StartSpinner("")
+		usersRes, apiErr := api.Client.ListUsers()
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error fetching users: %v", apiErr.Msg)
+			return
+		}
+
+		for _, user := range usersRes.Users {
+			if user.Email == budgetUserEmail {
+				userId := user.Id
+				req.UserId = &userId
+				break
+			}
+		}
+		if req.UserId == nil {
+			term.OutputErrorAndExit("No user with email %s in this org", budgetUserEmail)
+			return
+		}
+		req.Scope = shared.SpendBudgetScopeUser
+	} else if budgetAllUsers {
+		req.Scope = shared.SpendBudgetScopeUser
+	}
+
+	term.StartSpinner("")
+	_, apiErr := api.Client.SetSpendBudget(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting spend budget: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Set %s spend budget of %s\n", period, color.New(color.Bold, term.ColorHiCyan).Sprint("$"+limit.StringFixed(2)))
+	fmt.Println()
+	term.PrintCmds("", "budgets")
+}
+
+func deleteSpendBudget(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ListSpendBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching spend budgets: %v", apiErr.Msg)
+		return
+	}
+
+	if len(res.Budgets) == 0 {
+		fmt.Println("🤷‍♂️ No spend budgets")
+		return
+	}
+
+	var toDelete *shared.SpendBudget
+
+	if len(args) == 1 {
+		index, err := strconv.Atoi(args[0])
+		if err != nil || index < 1 || index > len(res.Budgets) {
+			term.OutputErrorAndExit("Invalid budget index: %s", args[0])
+			return
+		}
+		toDelete = res.Budgets[index-1]
+	} else {
+		opts := make([]string, len(res.Budgets))
+		for i, budget := range res.Budgets {
+			opts[i] = fmt.Sprintf("%s → %s $%s", budgetTargetLabel(budget, res), budget.Period, budget.LimitUsd.StringFixed(2))
+		}
+
+		selected, err := term.SelectFromList("Select budget to remove:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting budget: %v", err)
+			return
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				toDelete = res.Budgets[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.DeleteSpendBudget(toDelete.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing spend budget: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Removed %s spend budget for %s\n", toDelete.Period, budgetTargetLabel(toDelete, res))
+}
+
+func budgetTargetLabel(budget *shared.SpendBudget, res *shared.ListSpendBudgetsResponse) string {
+	switch budget.Scope {
+	case shared.SpendBudgetScopePlan:
+		if budget.PlanId == nil {
+			return "Each plan"
+		}
+		return "Plan " + res.PlanNamesById[*budget.PlanId]
+	case shared.SpendBudgetScopeUser:
+		if budget.UserId == nil {
+			return "Each user"
+		}
+		return "User " + res.UserEmailsById[*budget.UserId]
+	}
+	return "Org"
+}
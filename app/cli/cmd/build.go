@@ -80,6 +80,8 @@ func build(cmd *cobra.Command, args []string) {
 			NoExec:      noExec,
 			AutoExec:    autoExec,
 			AutoDebug:   autoDebug,
+			ExecSandbox: execSandbox,
+			NoVerify:    noVerify,
 		}
 
 		tellFlags := types.TellFlags{
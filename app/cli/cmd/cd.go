@@ -47,7 +47,7 @@ func cd(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
-	if len(plans) == 0 {
+	if len(plans) == 0 && nameOrIdx == "" {
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
 		term.PrintCmds("", "new")
@@ -93,6 +93,24 @@ func cd(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// plans shared with you live in their owners' projects, so fall back to them by name
+	if plan == nil && nameOrIdx != "" {
+		term.StartSpinner("")
+		sharedPlans, apiErr := api.Client.ListSharedPlans(nil)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting shared plans: %v", apiErr)
+		}
+
+		for _, p := range sharedPlans {
+			if p.Name == nameOrIdx {
+				plan = p
+				break
+			}
+		}
+	}
+
 	if plan == nil {
 		term.OutputErrorAndExit("Plan not found")
 	}
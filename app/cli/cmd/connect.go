@@ -42,7 +42,16 @@ func connect(cmd *cobra.Command, args []string) {
 	}
 
 	term.StartSpinner("")
-	apiErr := api.Client.ConnectPlan(planId, branch, stream.OnStreamPlan)
+
+	// diagnostics commands run during the stream use the plan's sandbox config
+	config, apiErr := api.Client.GetPlanConfig(planId)
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error getting plan config: %v", apiErr)
+	}
+	lib.ExecSandbox = config.ExecSandbox
+
+	apiErr = api.Client.ConnectPlan(planId, branch, 0, nil, stream.OnStreamPlan)
 	term.StopSpinner()
 
 	if apiErr != nil {
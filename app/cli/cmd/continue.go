@@ -74,6 +74,8 @@ func doContinue(cmd *cobra.Command, args []string) {
 			AutoExec:    autoExec,
 			NoExec:      noExec,
 			AutoDebug:   autoDebug,
+			ExecSandbox: execSandbox,
+			NoVerify:    noVerify,
 		}
 
 		lib.MustApplyPlan(lib.ApplyPlanParams{
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	shared "plandex-shared"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +29,7 @@ var convoCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(convoCmd)
+	supportJsonOutput(convoCmd)
 
 	convoCmd.Flags().BoolVarP(&plainTextOutput, "plain", "p", false, "Output conversation in plain text with no ANSI codes")
 
@@ -49,7 +52,7 @@ func convo(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error loading conversation: %v", apiErr.Msg)
 	}
 
-	if len(conversation) == 0 {
+	if len(conversation) == 0 && !outputJson {
 		fmt.Println("🤷‍♂️ No conversation history")
 		return
 	}
@@ -81,6 +84,20 @@ func convo(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if outputJson {
+		messages := []*shared.ConvoMessage{}
+		for _, msg := range conversation {
+			if (msgRangeStart > 0 && msg.Num < msgRangeStart) || (msgRangeEnd > 0 && msg.Num > msgRangeEnd) {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+		printJson(cmd, map[string]interface{}{
+			"messages": messages,
+		})
+		return
+	}
+
 	var convo string
 	var totalTokens int
 	var didCut bool
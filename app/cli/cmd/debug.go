@@ -260,6 +260,9 @@ func doDebug(cmd *cobra.Command, args []string) {
 			NoCommit:    !autoCommit,
 			NoExec:      false,
 			AutoExec:    true,
+			ExecSandbox: execSandbox,
+			// the debug command is the check here, so the project verification command doesn't run too
+			NoVerify: true,
 		}
 
 		lib.MustApplyPlan(lib.ApplyPlanParams{
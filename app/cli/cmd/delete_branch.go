@@ -122,6 +122,12 @@ func deleteBranch(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// the git branch is kept, only the worktree is removed
+	err = lib.RemoveGitMirror(lib.CurrentPlanId, branch)
+	if err != nil {
+		term.OutputErrorAndExit("Error removing git mirror: %v", err)
+	}
+
 	fmt.Printf("✅ Deleted branch %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(branch))
 
 	fmt.Println()
@@ -34,6 +34,7 @@ var diffsCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(diffsCmd)
+	supportJsonOutput(diffsCmd)
 
 	diffsCmd.Flags().BoolVarP(&plainTextOutput, "plain", "p", false, "Output diffs in plain text with no ANSI codes")
 	diffsCmd.Flags().BoolVar(&showDiffUi, "ui", false, "Show diffs in a browser UI")
@@ -56,6 +57,12 @@ func diffs(cmd *cobra.Command, args []string) {
 
 	term.StartSpinner("")
 
+	if outputJson {
+		showDiffUi = false
+		diffGit = false
+		plainTextOutput = true
+	}
+
 	if showDiffUi {
 		diffGit = false
 	} else if diffGit || plainTextOutput {
@@ -71,6 +78,13 @@ func diffs(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, map[string]interface{}{
+			"diff": diffs,
+		})
+		return
+	}
+
 	if len(diffs) == 0 {
 		fmt.Println("🤷‍♂️ No pending changes")
 		return
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var execPolicyCmd = &cobra.Command{
+	Use:   "exec-policy",
+	Short: "Show the org and project command execution policies",
+	Run:   showExecPolicy,
+}
+
+var setExecPolicyCmd = &cobra.Command{
+	Use:   "set <file>",
+	Short: "Set the org command execution policy from a JSON file",
+	Long:  `Set the org command execution policy from a JSON file with "allow" and "deny" lists of command patterns. The format is the same as the project policy file (` + shared.ExecPolicyFileName + `).`,
+	Args:  cobra.ExactArgs(1),
+	Run:   setExecPolicy,
+}
+
+var clearExecPolicyCmd = &cobra.Command{
+	Use:     "clear",
+	Aliases: []string{"rm"},
+	Short:   "Remove the org command execution policy",
+	Args:    cobra.NoArgs,
+	Run:     clearExecPolicy,
+}
+
+func init() {
+	RootCmd.AddCommand(execPolicyCmd)
+	execPolicyCmd.AddCommand(setExecPolicyCmd)
+	execPolicyCmd.AddCommand(clearExecPolicyCmd)
+}
+
+func showExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgPolicy, apiErr := api.Client.GetOrgExecPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org exec policy: %v", apiErr.Msg)
+		return
+	}
+
+	projectPolicy, err := fs.LoadProjectExecPolicy()
+	if err != nil {
+		term.OutputErrorAndExit("Error loading project exec policy: %v", err)
+		return
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Println("🏢 Org Policy")
+	renderExecPolicy(orgPolicy)
+
+	color.New(color.Bold, term.ColorHiCyan).Printf("📁 Project Policy (%s)\n", shared.ExecPolicyFileName)
+	renderExecPolicy(projectPolicy)
+
+	term.PrintCmds("", "exec-policy set", "exec-policy clear")
+}
+
+func renderExecPolicy(policy *shared.ExecPolicy) {
+	if policy.IsEmpty() {
+		fmt.Println("🤷‍♂️ No policy")
+		fmt.Println()
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"🚫 Denied", "✅ Allowed"})
+	table.Append([]string{strings.Join(policy.Deny, "\n"), strings.Join(policy.Allow, "\n")})
+	table.Render()
+	fmt.Println()
+}
+
+func setExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	bytes, err := os.ReadFile(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error reading policy file: %v", err)
+		return
+	}
+
+	var policy shared.ExecPolicy
+	err = json.Unmarshal(bytes, &policy)
+	if err != nil {
+		term.OutputErrorAndExit("Error parsing policy file: %v", err)
+		return
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		term.OutputErrorAndExit("Invalid policy file: %v", err)
+		return
+	}
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.SetOrgExecPolicy(policy)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting org exec policy: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Org exec policy updated")
+	fmt.Println()
+	renderExecPolicy(updated)
+	term.PrintCmds("", "exec-policy")
+}
+
+func clearExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgExecPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing org exec policy: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Org exec policy removed")
+	fmt.Println()
+	term.PrintCmds("", "exec-policy")
+}
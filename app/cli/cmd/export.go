@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"regexp"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var exportOutputPath string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [name-or-index]",
+	Short: "Export a plan to a portable archive",
+	Long:  "Export a plan to a portable archive with all its branches, history, context, conversation, pending changes, and settings. Defaults to the current plan. Restore it on any server or org with 'plandex import'.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   export,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "Path to write the archive to (defaults to <plan-name>.plandex.tar.gz)")
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func export(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	var plan *shared.Plan
+
+	if len(args) == 0 {
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+
+		term.StartSpinner("")
+		var apiErr *shared.ApiError
+		plan, apiErr = api.Client.GetPlan(lib.CurrentPlanId)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr.Msg)
+		}
+	} else {
+		nameOrIdx := strings.TrimSpace(args[0])
+
+		term.StartSpinner("")
+		plans, apiErr := api.Client.ListPlans([]string{lib.CurrentProjectId})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plans: %v", apiErr.Msg)
+		}
+
+		idx, err := strconv.Atoi(nameOrIdx)
+		if err == nil && idx > 0 && idx <= len(plans) {
+			plan = plans[idx-1]
+		} else {
+			for _, p := range plans {
+				if p.Name == nameOrIdx {
+					plan = p
+					break
+				}
+			}
+		}
+
+		if plan == nil {
+			term.OutputErrorAndExit("Plan not found")
+		}
+	}
+
+	outputPath := exportOutputPath
+	if outputPath == "" {
+		outputPath = unsafeFileNameChars.ReplaceAllString(plan.Name, "-") + ".plandex.tar.gz"
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		term.OutputErrorAndExit("Error creating archive file: %v", err)
+	}
+
+	term.StartSpinner("📦 Exporting plan...")
+	apiErr := api.Client.ExportPlan(plan.Id, f)
+	term.StopSpinner()
+
+	closeErr := f.Close()
+
+	if apiErr != nil {
+		os.Remove(outputPath)
+		term.OutputErrorAndExit("Error exporting plan: %v", apiErr.Msg)
+	}
+
+	if closeErr != nil {
+		term.OutputErrorAndExit("Error writing archive file: %v", closeErr)
+	}
+
+	fmt.Printf("✅ Exported plan %s to %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name), color.New(color.Bold, term.ColorHiCyan).Sprint(outputPath))
+	fmt.Println()
+
+	term.PrintCmds("", "import")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a plan from an archive",
+	Long:  "Import a plan from an archive created with 'plandex export' into the current project, and set it as the current plan. The imported plan is owned by you.",
+	Args:  cobra.ExactArgs(1),
+	Run:   importPlan,
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+}
+
+func importPlan(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveOrCreateProject()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error opening archive: %v", err)
+	}
+	defer f.Close()
+
+	term.StartSpinner("📦 Importing plan...")
+	res, apiErr := api.Client.ImportPlan(lib.CurrentProjectId, f)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error importing plan: %v", apiErr.Msg)
+	}
+
+	err = lib.WriteCurrentPlan(res.Id)
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current plan: %v", err)
+	}
+
+	err = lib.WriteCurrentBranch("main")
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current branch: %v", err)
+	}
+
+	branchesLbl := "branch"
+	if res.NumBranches != 1 {
+		branchesLbl = "branches"
+	}
+
+	fmt.Printf("✅ Imported plan %s with %d %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(res.Name), res.NumBranches, branchesLbl)
+	fmt.Println()
+
+	term.PrintCmds("", "convo", "log", "branches")
+}
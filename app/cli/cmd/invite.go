@@ -75,7 +75,7 @@ func invite(cmd *cobra.Command, args []string) {
 
 	var orgRoleId string
 	for _, orgRole := range orgRoles {
-		if orgRole.Label == orgRoleName {
+		if orgRole.Label == orgRoleName || orgRole.Name == orgRoleName {
 			orgRoleId = orgRole.Id
 			break
 		}
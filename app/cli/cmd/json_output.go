@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"plandex-cli/term"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// bump on any breaking change to the shape of --json output
+const jsonOutputVersion = 1
+
+const jsonOutputAnnotation = "jsonOutput"
+
+var outputJson bool
+
+type jsonOutput struct {
+	Version int         `json:"version"`
+	Command string      `json:"command"`
+	Data    interface{} `json:"data"`
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&outputJson, "json", false, "Output JSON instead of formatted text (read-only commands only)")
+
+	RootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if !outputJson {
+			return
+		}
+
+		if cmd.Annotations[jsonOutputAnnotation] != "true" {
+			term.OutputErrorAndExit("--json isn't supported for '%s'", cmd.CommandPath())
+		}
+
+		term.DisableSpinner()
+	}
+}
+
+// supportJsonOutput marks commands that can render their output with --json
+func supportJsonOutput(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[jsonOutputAnnotation] = "true"
+	}
+}
+
+func printJson(cmd *cobra.Command, data interface{}) {
+	bytes, err := json.MarshalIndent(jsonOutput{
+		Version: jsonOutputVersion,
+		Command: strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+		Data:    data,
+	}, "", "  ")
+
+	if err != nil {
+		term.OutputErrorAndExit("Error marshalling JSON output: %v", err)
+	}
+
+	fmt.Println(string(bytes))
+}
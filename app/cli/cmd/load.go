@@ -19,13 +19,14 @@ var (
 	forceSkipIgnore bool
 	imageDetail     string
 	defsOnly        bool
+	symbols         []string
 )
 
 var contextLoadCmd = &cobra.Command{
 	Use:     "load [files-or-urls...]",
 	Aliases: []string{"l", "add"},
 	Short:   "Load context from various inputs",
-	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data.`,
+	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data. Load a single function, type, or class from a file with path#Symbol or --symbol--methods can be qualified by their type, like server.go#Server.Start.`,
 	Run:     contextLoad,
 }
 
@@ -36,6 +37,7 @@ func init() {
 	contextLoadCmd.Flags().BoolVarP(&forceSkipIgnore, "force", "f", false, "Load files even when ignored by .gitignore or .plandexignore")
 	contextLoadCmd.Flags().StringVarP(&imageDetail, "detail", "d", "high", "Image detail level (high or low)")
 	contextLoadCmd.Flags().BoolVar(&defsOnly, "map", false, "Load file maps (function/method/class signatures, variable names, types, etc.)")
+	contextLoadCmd.Flags().StringArrayVar(&symbols, "symbol", nil, "Load only the named function, type, or class from each file (repeatable)--same as path#Symbol")
 	RootCmd.AddCommand(contextLoadCmd)
 }
 
@@ -55,6 +57,7 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		ForceSkipIgnore: forceSkipIgnore,
 		ImageDetail:     openai.ImageURLDetail(imageDetail),
 		DefsOnly:        defsOnly,
+		Symbols:         symbols,
 		SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
 	})
 
@@ -8,6 +8,8 @@ import (
 	"plandex-cli/term"
 	"time"
 
+	shared "plandex-shared"
+
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +26,7 @@ var logCmd = &cobra.Command{
 func init() {
 	// Add log command
 	RootCmd.AddCommand(logCmd)
+	supportJsonOutput(logCmd)
 }
 
 func runLog(cmd *cobra.Command, args []string) {
@@ -42,6 +45,17 @@ func runLog(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting logs: %v", apiErr)
 	}
 
+	if outputJson {
+		entries := res.Entries
+		if entries == nil {
+			entries = []shared.LogEntry{}
+		}
+		printJson(cmd, map[string]interface{}{
+			"entries": entries,
+		})
+		return
+	}
+
 	withLocalTimestamps, err := convertTimestampsToLocal(res.Body)
 
 	if err != nil {
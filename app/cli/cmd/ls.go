@@ -41,6 +41,17 @@ func listContext(cmd *cobra.Command, args []string) {
 	}
 	term.StopSpinner()
 
+	if outputJson {
+		if contexts == nil {
+			contexts = []*shared.Context{}
+		}
+		printJson(cmd, map[string]interface{}{
+			"contexts":        contexts,
+			"autoLoadContext": planConfig.AutoLoadContext,
+		})
+		return
+	}
+
 	totalTokens := 0
 	totalPlannerTokens := 0
 	totalMapTokens := 0
@@ -111,5 +122,6 @@ func listContext(cmd *cobra.Command, args []string) {
 
 func init() {
 	RootCmd.AddCommand(contextCmd)
+	supportJsonOutput(contextCmd)
 
 }
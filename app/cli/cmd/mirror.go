@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var mirrorGitBranch string
+var mirrorWorktreePath string
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror [base-ref]",
+	Short: "Mirror the current plan branch to a git branch and worktree",
+	Long: `Mirror the current plan branch to a dedicated git branch, checked out in its own worktree.
+
+While a plan branch is mirrored, 'plandex apply' writes changes to the worktree and commits them to the git branch instead of updating your working tree, and 'plandex rewind' resets the git branch to match. Push the git branch to open a pull request from plan output.
+
+The git branch is created from base-ref, which defaults to HEAD. If the current branch is already mirrored, shows the mirror instead.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  mirror,
+}
+
+var mirrorOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop mirroring the current plan branch to git",
+	Long:  "Stop mirroring the current plan branch to git and remove its worktree. The git branch and its commits are kept.",
+	Args:  cobra.NoArgs,
+	Run:   mirrorOff,
+}
+
+func init() {
+	RootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.AddCommand(mirrorOffCmd)
+
+	mirrorCmd.Flags().StringVarP(&mirrorGitBranch, "git-branch", "b", "", "Name of the git branch to create (defaults to plandex/<plan-name>/<branch>)")
+	mirrorCmd.Flags().StringVar(&mirrorWorktreePath, "path", "", "Path for the worktree (defaults to a directory in the plandex home dir)")
+}
+
+func mirror(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	gitMirror, err := lib.GetGitMirror(lib.CurrentPlanId, lib.CurrentBranch)
+	if err != nil {
+		term.OutputErrorAndExit("Error getting git mirror: %v", err)
+	}
+
+	if gitMirror != nil {
+		if len(args) > 0 || mirrorGitBranch != "" || mirrorWorktreePath != "" {
+			term.OutputErrorAndExit("Branch %s is already mirrored to git branch %s. Use 'plandex mirror off' first to change it.", lib.CurrentBranch, gitMirror.GitBranch)
+		}
+		showGitMirror(gitMirror)
+		return
+	}
+
+	if !fs.ProjectRootIsGitRepo() {
+		term.OutputErrorAndExit("The project root isn't a git repository. Mirroring plan branches requires git.")
+	}
+
+	baseRef := "HEAD"
+	if len(args) > 0 {
+		baseRef = args[0]
+	}
+
+	gitBranch := mirrorGitBranch
+	if gitBranch == "" {
+		term.StartSpinner("")
+		plan, apiErr := api.Client.GetPlan(lib.CurrentPlanId)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr.Msg)
+		}
+
+		gitBranch = lib.DefaultGitMirrorBranch(plan.Name, lib.CurrentBranch)
+	}
+
+	worktree := mirrorWorktreePath
+	if worktree == "" {
+		worktree = lib.DefaultGitMirrorWorktree(lib.CurrentPlanId, lib.CurrentBranch)
+	}
+
+	term.StartSpinner("")
+	gitMirror, err = lib.CreateGitMirror(lib.CreateGitMirrorParams{
+		PlanId:    lib.CurrentPlanId,
+		Branch:    lib.CurrentBranch,
+		GitBranch: gitBranch,
+		Worktree:  worktree,
+		BaseRef:   baseRef,
+	})
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error creating git mirror: %v", err)
+	}
+
+	fmt.Printf("✅ Branch %s is now mirrored to git branch %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch), color.New(color.Bold, term.ColorHiCyan).Sprint(gitMirror.GitBranch))
+	fmt.Println("📂 Worktree: " + gitMirror.Worktree)
+	fmt.Println()
+	fmt.Println("Applied changes will be committed to the git branch. Your working tree won't be touched.")
+	fmt.Println()
+
+	term.PrintCmds("", "apply", "rewind", "mirror off")
+}
+
+func showGitMirror(gitMirror *types.GitMirror) {
+	term.StartSpinner("")
+	commits, err := lib.ListGitMirrorCommits(gitMirror)
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error listing git mirror commits: %v", err)
+	}
+
+	fmt.Printf("🪞 Branch %s is mirrored to git branch %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch), color.New(color.Bold, term.ColorHiCyan).Sprint(gitMirror.GitBranch))
+	fmt.Println("📂 Worktree: " + gitMirror.Worktree)
+	fmt.Println()
+
+	if len(commits) == 0 {
+		fmt.Printf("No commits since %s\n", gitMirror.BaseSha[:7])
+	} else {
+		fmt.Printf("Commits since %s:\n", gitMirror.BaseSha[:7])
+		for _, commit := range commits {
+			fmt.Printf(" • %s %s\n", commit.Sha[:7], commit.Subject)
+		}
+	}
+	fmt.Println()
+
+	term.PrintCmds("", "apply", "rewind", "mirror off")
+}
+
+func mirrorOff(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	gitMirror, err := lib.GetGitMirror(lib.CurrentPlanId, lib.CurrentBranch)
+	if err != nil {
+		term.OutputErrorAndExit("Error getting git mirror: %v", err)
+	}
+
+	if gitMirror == nil {
+		fmt.Printf("🤷‍♂️ Branch %s isn't mirrored to git\n", lib.CurrentBranch)
+		return
+	}
+
+	term.StartSpinner("")
+	err = lib.RemoveGitMirror(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error removing git mirror: %v", err)
+	}
+
+	fmt.Printf("✅ Stopped mirroring branch %s. Git branch %s was kept.\n", color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch), color.New(color.Bold, term.ColorHiCyan).Sprint(gitMirror.GitBranch))
+}
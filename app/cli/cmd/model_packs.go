@@ -140,7 +140,7 @@ func listModelPacks(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 
 	term.StartSpinner("")
-	builtInModelPacks := shared.BuiltInModelPacks
+	builtInModelPacks := shared.GetBuiltInModelPacks(auth.Current.IsCloud)
 	customModelPacks, err := api.Client.ListModelPacks()
 	term.StopSpinner()
 
@@ -390,7 +390,7 @@ func showModelPack(cmd *cobra.Command, args []string) {
 
 	modelPacks := []*shared.ModelPack{}
 	modelPacks = append(modelPacks, customModelPacks...)
-	modelPacks = append(modelPacks, shared.BuiltInModelPacks...)
+	modelPacks = append(modelPacks, shared.GetBuiltInModelPacks(auth.Current.IsCloud)...)
 
 	var name string
 	if len(args) > 0 {
@@ -25,6 +25,7 @@ func init() {
 	modelsCmd.AddCommand(createCustomModelCmd)
 	modelsCmd.AddCommand(deleteCustomModelCmd)
 	modelsCmd.AddCommand(defaultModelsCmd)
+	supportJsonOutput(modelsCmd, defaultModelsCmd, listAvailableModelsCmd)
 
 	listAvailableModelsCmd.Flags().BoolVarP(&customModelsOnly, "custom", "c", false, "List custom models only")
 }
@@ -74,10 +75,10 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 
 	opts := shared.AllModelProviders
 	if auth.Current.IsCloud {
-		// remove custom provider if we're in cloud
+		// remove custom and local providers if we're in cloud
 		filtered := []string{}
 		for _, provider := range opts {
-			if provider != string(shared.ModelProviderCustom) {
+			if !shared.ModelProvider(provider).IsSelfHostedOnly() {
 				filtered = append(filtered, provider)
 			}
 		}
@@ -138,6 +139,14 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 		baseUrl = strings.TrimSuffix(baseUrl, "/")
 
 		model.BaseUrl = baseUrl
+	} else if model.Provider == shared.ModelProviderOllama {
+		baseUrl, err := term.GetRequiredUserStringInputWithDefault("Base URL:", shared.OllamaBaseUrl)
+		if err != nil {
+			term.OutputErrorAndExit("Error reading base URL: %v", err)
+			return
+		}
+
+		model.BaseUrl = shared.OllamaOpenAIBaseUrl(baseUrl)
 	} else {
 		model.BaseUrl = shared.BaseUrlByProvider[model.Provider]
 	}
@@ -308,6 +317,15 @@ func models(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, map[string]interface{}{
+			"planId":   plan.Id,
+			"planName": plan.Name,
+			"settings": settings,
+		})
+		return
+	}
+
 	title := fmt.Sprintf("%s Model Settings", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name))
 
 	table := tablewriter.NewWriter(os.Stdout)
@@ -333,6 +351,13 @@ func defaultModels(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, map[string]interface{}{
+			"settings": settings,
+		})
+		return
+	}
+
 	title := fmt.Sprintf("%s Model Settings", color.New(color.Bold, term.ColorHiGreen).Sprint("Org-Wide Default"))
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
@@ -359,6 +384,20 @@ func listAvailableModels(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		if customModels == nil {
+			customModels = []*shared.AvailableModel{}
+		}
+		data := map[string]interface{}{
+			"custom": customModels,
+		}
+		if !customModelsOnly {
+			data["builtIn"] = shared.AvailableModels
+		}
+		printJson(cmd, data)
+		return
+	}
+
 	if !customModelsOnly {
 		color.New(color.Bold, term.ColorHiCyan).Println("🏠 Built-in Models")
 		builtIn := shared.AvailableModels
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var discoverBaseUrl string
+var discoverPackName string
+
+var discoverModelsCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Add models installed on a local Ollama server",
+	Run:   discoverModels,
+}
+
+func init() {
+	modelsCmd.AddCommand(discoverModelsCmd)
+
+	discoverModelsCmd.Flags().StringVar(&discoverBaseUrl, "url", shared.OllamaBaseUrl, "Base URL of the local model server")
+	discoverModelsCmd.Flags().StringVar(&discoverPackName, "pack", "", "Also create a model pack with this name using the discovered models")
+}
+
+func discoverModels(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if auth.Current.IsCloud {
+		term.OutputErrorAndExit("Local models are only supported when self-hosting Plandex")
+		return
+	}
+
+	term.StartSpinner("")
+	infos, err := lib.DiscoverLocalModels(discoverBaseUrl)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error discovering local models: %v", err)
+		return
+	}
+
+	existing, apiErr := api.Client.ListCustomModels()
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error fetching custom models: %v", apiErr.Msg)
+		return
+	}
+	term.StopSpinner()
+
+	if len(infos) == 0 {
+		fmt.Println("🤷‍♂️ No local models found at", discoverBaseUrl)
+		return
+	}
+
+	existingById := map[shared.ModelId]*shared.AvailableModel{}
+	for _, m := range existing {
+		if m.Provider == shared.ModelProviderOllama {
+			existingById[m.ModelId] = m
+		}
+	}
+
+	modelsByName := map[string]*shared.AvailableModel{}
+
+	term.StartSpinner("")
+	for _, info := range infos {
+		model := shared.NewLocalAvailableModel(discoverBaseUrl, info)
+		modelsByName[info.Name] = model
+
+		var verb string
+		if prev, ok := existingById[model.ModelId]; ok {
+			model.Id = prev.Id
+			apiErr = api.Client.UpdateCustomModel(model)
+			verb = "Updated"
+		} else {
+			apiErr = api.Client.CreateCustomModel(model)
+			verb = "Added"
+		}
+
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error saving model %s: %v", model.ModelId, apiErr.Msg)
+			return
+		}
+
+		term.StopSpinner()
+		fmt.Printf("✅ %s %s %s\n", verb, color.New(color.Bold, term.ColorHiCyan).Sprint(string(model.ModelId)), color.New(color.FgHiBlack).Sprintf("(%dk context)", model.MaxTokens/1024))
+		term.StartSpinner("")
+	}
+	term.StopSpinner()
+
+	fmt.Println()
+	fmt.Println("Ollama uses a small context window by default. Set OLLAMA_CONTEXT_LENGTH on the Ollama server so it matches the context lengths above.")
+
+	if discoverPackName != "" {
+		heavyInfo, lightInfo := shared.PickLocalPackModels(infos)
+		pack := shared.NewLocalModelPack(
+			discoverPackName,
+			fmt.Sprintf("Local models via Ollama: %s for heavy roles, %s for light roles", heavyInfo.Name, lightInfo.Name),
+			modelsByName[heavyInfo.Name],
+			modelsByName[lightInfo.Name],
+		)
+
+		term.StartSpinner("")
+		apiErr = api.Client.CreateModelPack(&pack)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error creating model pack: %v", apiErr.Msg)
+			return
+		}
+
+		fmt.Println()
+		fmt.Println("✅ Created model pack", color.New(color.Bold, term.ColorHiCyan).Sprint(discoverPackName))
+		fmt.Println()
+		term.PrintCmds("", "set-model", "model-packs")
+		return
+	}
+
+	fmt.Println()
+	term.PrintCmds("", "models available --custom", "model-packs create")
+}
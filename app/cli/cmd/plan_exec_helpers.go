@@ -33,6 +33,8 @@ var tellAutoContext bool
 var tellSmartContext bool
 var noExec bool
 var autoDebug int
+var execSandboxFlag bool
+var execSandbox shared.ExecSandboxConfig
 
 var editor string
 var editorSetByFlag bool
@@ -114,6 +116,12 @@ func initApplyFlags(cmd *cobra.Command, applyFlag bool) {
 	}
 	cmd.Flags().BoolVarP(&autoCommit, "commit", "c", false, commitDesc)
 	cmd.Flags().BoolVar(&skipCommit, "skip-commit", false, skipCommitDesc)
+
+	noVerifyDesc := "Skip the project's verification command"
+	if applyFlag {
+		noVerifyDesc += " when --apply is passed"
+	}
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, noVerifyDesc)
 }
 
 func initExecScriptFlags(cmd *cobra.Command) {
@@ -121,6 +129,7 @@ func initExecScriptFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&autoExec, "auto-exec", false, "Automatically execute commands without confirmation")
 	cmd.Flags().Var(newAutoDebugValue(&autoDebug), "debug", "Automatically execute and debug failing commands (optionally specify number of tries—default is 5)")
 	cmd.Flag("debug").NoOptDefVal = strconv.Itoa(defaultAutoDebugTries)
+	cmd.Flags().BoolVar(&execSandboxFlag, "sandbox", false, "Execute commands in a sandbox (read-only filesystem outside the project, allowlisted env vars)")
 }
 
 func validatePlanExecFlags() {
@@ -203,6 +212,12 @@ func mustSetPlanExecFlagsWithConfig(cmd *cobra.Command, config *shared.PlanConfi
 		}
 	}
 
+	execSandbox = config.ExecSandbox
+	if execSandboxFlag {
+		execSandbox.Mode = shared.ExecSandboxModeSandbox
+	}
+	lib.ExecSandbox = execSandbox
+
 	if !editorSetByFlag {
 		editor = config.Editor
 	}
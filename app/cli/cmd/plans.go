@@ -27,10 +27,13 @@ import (
 )
 
 var archivedOnly bool
+var sharedOnly bool
 
 func init() {
 	RootCmd.AddCommand(plansCmd)
+	supportJsonOutput(plansCmd)
 	plansCmd.Flags().BoolVarP(&archivedOnly, "archived", "a", false, "List archived plans")
+	plansCmd.Flags().BoolVarP(&sharedOnly, "shared", "s", false, "List plans other org members have shared with you")
 }
 
 // plansCmd represents the list command
@@ -46,13 +49,15 @@ func plans(cmd *cobra.Command, args []string) {
 	lib.MaybeResolveProject()
 
 	if archivedOnly {
-		listArchived()
+		listArchived(cmd)
+	} else if sharedOnly {
+		listShared(cmd)
 	} else {
-		listActive()
+		listActive(cmd)
 	}
 }
 
-func listActive() {
+func listActive(cmd *cobra.Command) {
 	errCh := make(chan error)
 
 	var parentProjectIdsWithPaths [][2]string
@@ -113,6 +118,10 @@ func listActive() {
 	}
 
 	if len(projectIds) == 0 {
+		if outputJson {
+			printPlansJson(cmd, nil, nil)
+			return
+		}
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
 		term.PrintCmds("", "new")
@@ -127,6 +136,33 @@ func listActive() {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
+	if outputJson {
+		var currentBranchesByPlanId map[string]*shared.Branch
+		var currentProjectPlanIds []string
+		for _, p := range plans {
+			if p.ProjectId == lib.CurrentProjectId {
+				currentProjectPlanIds = append(currentProjectPlanIds, p.Id)
+			}
+		}
+
+		if len(currentProjectPlanIds) > 0 {
+			currentBranchNamesByPlanId, err := lib.GetCurrentBranchNamesByPlanId(currentProjectPlanIds)
+			if err != nil {
+				term.OutputErrorAndExit("Error getting current branches: %v", err)
+			}
+
+			currentBranchesByPlanId, apiErr = api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
+				CurrentBranchByPlanId: currentBranchNamesByPlanId,
+			})
+			if apiErr != nil {
+				term.OutputErrorAndExit("Error getting current branches: %v", apiErr)
+			}
+		}
+
+		printPlansJson(cmd, plans, currentBranchesByPlanId)
+		return
+	}
+
 	if len(plans) == 0 {
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
@@ -352,7 +388,7 @@ func listActive() {
 	}
 }
 
-func listArchived() {
+func listArchived(cmd *cobra.Command) {
 	var projectIds []string
 
 	if lib.CurrentProjectId != "" {
@@ -367,6 +403,11 @@ func listArchived() {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
+	if outputJson {
+		printPlansJson(cmd, plans, nil)
+		return
+	}
+
 	if len(plans) == 0 {
 		fmt.Println("🤷‍♂️ No archived plans")
 		fmt.Println()
@@ -412,3 +453,112 @@ func listArchived() {
 	fmt.Println()
 	term.PrintCmds("", "unarchive")
 }
+
+func listShared(cmd *cobra.Command) {
+	var plans []*shared.Plan
+	var usersResp *shared.ListUsersResponse
+	errCh := make(chan error)
+
+	term.StartSpinner("")
+
+	go func() {
+		var apiErr *shared.ApiError
+		// plans live in their owners' projects, so list shared plans from every project
+		plans, apiErr = api.Client.ListSharedPlans(nil)
+		if apiErr != nil {
+			errCh <- fmt.Errorf("error getting shared plans: %v", apiErr.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	go func() {
+		var apiErr *shared.ApiError
+		usersResp, apiErr = api.Client.ListUsers()
+		if apiErr != nil {
+			errCh <- fmt.Errorf("error getting users: %v", apiErr.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	for i := 0; i < 2; i++ {
+		err := <-errCh
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	term.StopSpinner()
+
+	if outputJson {
+		printPlansJson(cmd, plans, nil)
+		return
+	}
+
+	if len(plans) == 0 {
+		fmt.Println("🤷‍♂️ No plans shared with you")
+		fmt.Println()
+		term.PrintCmds("", "plans")
+		return
+	}
+
+	ownersById := map[string]string{}
+	for _, user := range usersResp.Users {
+		ownersById[user.Id] = user.Name
+	}
+
+	var b strings.Builder
+	table := tablewriter.NewWriter(&b)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Owner", "Access", "Updated"})
+
+	for i, p := range plans {
+		num := strconv.Itoa(i + 1)
+		name := p.Name
+		if p.Id == lib.CurrentPlanId {
+			num = color.New(color.Bold, term.ColorHiGreen).Sprint(num)
+			name = color.New(color.Bold, term.ColorHiGreen).Sprint(p.Name) + fmt.Sprint(" 👈")
+		}
+
+		owner := ownersById[p.OwnerId]
+		if owner == "" {
+			owner = "-"
+		}
+
+		row := []string{
+			num,
+			name,
+			owner,
+			string(p.SharedAccess),
+			format.Time(p.UpdatedAt),
+		}
+
+		table.Rich(row, []tablewriter.Colors{{tablewriter.Bold}})
+	}
+	table.Render()
+
+	term.PageOutput(b.String())
+
+	fmt.Println()
+	term.PrintCmds("", "cd", "unshare")
+}
+
+// currentBranchesByPlanId is only set for plans in the current project
+func printPlansJson(cmd *cobra.Command, plans []*shared.Plan, currentBranchesByPlanId map[string]*shared.Branch) {
+	if plans == nil {
+		plans = []*shared.Plan{}
+	}
+
+	data := map[string]interface{}{
+		"currentProjectId": lib.CurrentProjectId,
+		"currentPlanId":    lib.CurrentPlanId,
+		"plans":            plans,
+	}
+	if currentBranchesByPlanId != nil {
+		data["currentBranchesByPlanId"] = currentBranchesByPlanId
+	}
+
+	printJson(cmd, data)
+}
@@ -23,6 +23,7 @@ var psCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(psCmd)
+	supportJsonOutput(psCmd)
 }
 
 func ps(cmd *cobra.Command, args []string) {
@@ -42,6 +43,11 @@ func ps(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, res)
+		return
+	}
+
 	if len(res.Branches) == 0 {
 		fmt.Println("🤷‍♂️ No active or recently finished streams")
 		return
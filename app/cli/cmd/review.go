@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:     "review [files...]",
+	Aliases: []string{"rv"},
+	Short:   "Review pending changes one at a time",
+	Long:    "Walk through pending changes file by file and change by change, accepting, rejecting, or editing each one. Accepted files can then be applied while the rest stay pending.",
+	Run:     review,
+}
+
+func init() {
+	initApplyFlags(reviewCmd, false)
+	RootCmd.AddCommand(reviewCmd)
+}
+
+const (
+	reviewOptionAccept     = "Accept"
+	reviewOptionReject     = "Reject"
+	reviewOptionEdit       = "Edit"
+	reviewOptionAcceptFile = "Accept rest of file"
+	reviewOptionRejectFile = "Reject rest of file"
+	reviewOptionQuit       = "Quit review"
+)
+
+func review(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	currentPlanState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr)
+	}
+
+	if currentPlanState.HasPendingBuilds() {
+		term.OutputErrorAndExit("This plan has changes that need to be built before they can be reviewed")
+	}
+
+	filterPaths := mustResolveProjectRelPaths(args)
+
+	var paths []string
+	for _, path := range currentPlanState.PlanResult.SortedPaths {
+		if path == "_apply.sh" || currentPlanState.PlanResult.NumPendingForPath(path) == 0 {
+			continue
+		}
+		if len(filterPaths) > 0 && !lib.PathMatchesAny(path, filterPaths) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("🤷‍♂️ No pending changes to review")
+		return
+	}
+
+	var accepted []string
+	var rejected []string
+	var incomplete []string
+
+	for i, path := range paths {
+		fmt.Println()
+		color.New(color.Bold, term.ColorHiCyan).Printf("📄 %s (%d/%d)\n", path, i+1, len(paths))
+		fmt.Println()
+
+		fileAccepted, complete, quit := reviewFile(path, currentPlanState.PlanResult.FileResultsByPath[path])
+
+		if !complete {
+			// applying the file would also apply the changes that weren't reviewed
+			incomplete = append(incomplete, path)
+		} else if fileAccepted {
+			accepted = append(accepted, path)
+		} else {
+			rejected = append(rejected, path)
+		}
+
+		if quit {
+			break
+		}
+	}
+
+	fmt.Println()
+
+	if len(incomplete) > 0 {
+		fmt.Printf("⏸️  Review stopped partway through %d file%s—changes are still pending and won't be applied\n", len(incomplete), pluralSuffix(len(incomplete)))
+		for _, path := range incomplete {
+			fmt.Println(" • 📄 " + path)
+		}
+		fmt.Println()
+	}
+
+	if len(rejected) > 0 {
+		fmt.Printf("🚫 Rejected all changes to %d file%s\n", len(rejected), pluralSuffix(len(rejected)))
+		for _, path := range rejected {
+			fmt.Println(" • 📄 " + path)
+		}
+		fmt.Println()
+	}
+
+	if len(accepted) == 0 {
+		fmt.Println("🤷‍♂️ No accepted changes to apply")
+		return
+	}
+
+	sort.Strings(accepted)
+
+	fmt.Printf("✅ Accepted changes to %d file%s\n", len(accepted), pluralSuffix(len(accepted)))
+	for _, path := range accepted {
+		fmt.Println(" • 📄 " + path)
+	}
+	fmt.Println()
+
+	shouldApply, err := term.ConfirmYesNo("Apply accepted changes now?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting confirmation: %v", err)
+	}
+
+	if !shouldApply {
+		fmt.Println("Accepted changes are still pending. Apply them with:")
+		fmt.Println()
+		fmt.Println("  plandex apply " + strings.Join(accepted, " "))
+		return
+	}
+
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  autoCommit,
+		NoCommit:    skipCommit,
+		NoVerify:    noVerify,
+	}
+
+	tellFlags := types.TellFlags{}
+
+	lib.MustApplyPlan(lib.ApplyPlanParams{
+		PlanId:     lib.CurrentPlanId,
+		Branch:     lib.CurrentBranch,
+		ApplyFlags: applyFlags,
+		TellFlags:  tellFlags,
+		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+		Paths:      accepted,
+	})
+}
+
+// reviewFile walks the pending results for a single file. It returns whether any change to the file
+// was accepted, whether every change was reviewed, and whether the user asked to stop reviewing.
+func reviewFile(path string, results []*shared.PlanFileResult) (accepted, complete, quit bool) {
+	var pendingResults []*shared.PlanFileResult
+	for _, result := range results {
+		if result.IsPending() {
+			pendingResults = append(pendingResults, result)
+		}
+	}
+
+	anyAccepted := false
+
+	// rejects the given replacement and everything after it in the file
+	rejectRest := func(resultIdx int, replacement *shared.Replacement) {
+		term.StartSpinner("")
+		defer term.StopSpinner()
+
+		reached := replacement == nil
+		for _, rep := range pendingResults[resultIdx].Replacements {
+			if rep == replacement {
+				reached = true
+			}
+			if reached && rep.IsPending() {
+				mustRejectReplacement(pendingResults[resultIdx], rep)
+			}
+		}
+
+		for _, result := range pendingResults[resultIdx+1:] {
+			mustRejectResult(result)
+		}
+	}
+
+	for i, result := range pendingResults {
+		// whole-file results (new files, full rewrites, removals) can only be accepted or rejected as a unit
+		if result.RemovedFile || len(result.Replacements) == 0 {
+			if result.RemovedFile {
+				color.New(term.ColorHiRed).Println("File will be removed")
+			} else {
+				printReviewLines(result.Content, "+ ", term.ColorHiGreen)
+			}
+			fmt.Println()
+
+			choice, err := term.SelectFromList("Keep this change?", []string{reviewOptionAccept, reviewOptionReject, reviewOptionQuit})
+			if err != nil {
+				term.OutputErrorAndExit("Error getting selection: %v", err)
+			}
+
+			switch choice {
+			case reviewOptionAccept:
+				anyAccepted = true
+			case reviewOptionReject:
+				term.StartSpinner("")
+				mustRejectResult(result)
+				term.StopSpinner()
+				fmt.Println("🚫 Rejected change")
+			case reviewOptionQuit:
+				// anything not yet reviewed is left pending
+				return anyAccepted, false, true
+			}
+
+			fmt.Println()
+			continue
+		}
+
+		numPending := result.NumPendingReplacements()
+		n := 0
+
+		for _, replacement := range result.Replacements {
+			if !replacement.IsPending() {
+				continue
+			}
+			n++
+
+			summary := replacement.Summary
+			if summary == "" {
+				summary = "Change"
+			}
+			color.New(color.Bold).Printf("%s (%d/%d)\n", summary, n, numPending)
+			printReviewLines(shared.RemoveLineNums(shared.LineNumberedTextType(replacement.Old)), "- ", term.ColorHiRed)
+			printReviewLines(replacement.New, "+ ", term.ColorHiGreen)
+			fmt.Println()
+
+			choice, err := term.SelectFromList("Keep this change?", []string{
+				reviewOptionAccept,
+				reviewOptionReject,
+				reviewOptionEdit,
+				reviewOptionAcceptFile,
+				reviewOptionRejectFile,
+				reviewOptionQuit,
+			})
+			if err != nil {
+				term.OutputErrorAndExit("Error getting selection: %v", err)
+			}
+
+			switch choice {
+			case reviewOptionAccept:
+				anyAccepted = true
+
+			case reviewOptionReject:
+				term.StartSpinner("")
+				mustRejectReplacement(result, replacement)
+				term.StopSpinner()
+				fmt.Println("🚫 Rejected change")
+
+			case reviewOptionEdit:
+				updated, changed := editReplacementInEditor(path, replacement.New)
+				if changed {
+					term.StartSpinner("")
+					apiErr := api.Client.EditReplacement(lib.CurrentPlanId, lib.CurrentBranch, shared.EditReplacementRequest{
+						ResultId:      result.Id,
+						ReplacementId: replacement.Id,
+						New:           updated,
+					})
+					term.StopSpinner()
+					if apiErr != nil {
+						term.OutputErrorAndExit("Error editing change: %v", apiErr.Msg)
+					}
+					fmt.Println("✏️  Edited change")
+				} else {
+					fmt.Println("No edits made--keeping the change as is")
+				}
+				anyAccepted = true
+
+			case reviewOptionAcceptFile:
+				return true, true, false
+
+			case reviewOptionRejectFile:
+				rejectRest(i, replacement)
+				fmt.Println("🚫 Rejected remaining changes to " + path)
+				return anyAccepted, true, false
+
+			case reviewOptionQuit:
+				return anyAccepted, false, true
+			}
+
+			fmt.Println()
+		}
+	}
+
+	return anyAccepted, true, false
+}
+
+func mustRejectReplacement(result *shared.PlanFileResult, replacement *shared.Replacement) {
+	apiErr := api.Client.RejectReplacement(lib.CurrentPlanId, lib.CurrentBranch, shared.RejectReplacementRequest{
+		ResultId:      result.Id,
+		ReplacementId: replacement.Id,
+	})
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error rejecting change: %v", apiErr.Msg)
+	}
+}
+
+// rejecting by result id only rejects that result, not every pending change to the file
+func mustRejectResult(result *shared.PlanFileResult) {
+	apiErr := api.Client.RejectFile(lib.CurrentPlanId, lib.CurrentBranch, result.Id)
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error rejecting change: %v", apiErr.Msg)
+	}
+}
+
+func editReplacementInEditor(path, content string) (string, bool) {
+	tempFile, err := os.CreateTemp(os.TempDir(), "plandex_review_*"+filepath.Ext(path))
+	if err != nil {
+		term.OutputErrorAndExit("Failed to create temporary file: %v", err)
+	}
+	filename := tempFile.Name()
+	defer os.Remove(filename)
+
+	_, err = tempFile.WriteString(content)
+	tempFile.Close()
+	if err != nil {
+		term.OutputErrorAndExit("Failed to write to temporary file: %v", err)
+	}
+
+	editorCmd := prepareEditorCommand(defaultEditor, filename)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	err = editorCmd.Run()
+	if err != nil {
+		term.OutputErrorAndExit("Error opening editor: %v", err)
+	}
+
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		term.OutputErrorAndExit("Failed to read edited change: %v", err)
+	}
+
+	updated := string(bytes)
+	return updated, updated != content
+}
+
+func printReviewLines(content, prefix string, c color.Attribute) {
+	if content == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		color.New(c).Println(prefix + line)
+	}
+}
+
+func pluralSuffix(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -233,6 +233,33 @@ func rewind(cmd *cobra.Command, args []string) {
 	// Get list of applies that will be undone
 	undonePlanApplies := lib.GetUndonePlanApplies(currentState, targetShaTimestamp)
 
+	// Mirrored branches are applied to their git mirror rather than project files, so reset the mirror branch instead of reverting
+	gitMirror, err := lib.GetGitMirror(lib.CurrentPlanId, lib.CurrentBranch)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error getting git mirror: %v", err)
+	}
+
+	if gitMirror != nil {
+		doRewind()
+
+		term.StartSpinner("")
+		resetSha, err := lib.RewindGitMirror(gitMirror, undonePlanApplies)
+		term.StopSpinner()
+		if err != nil {
+			term.OutputErrorAndExit("Error rewinding git mirror: %v", err)
+		}
+
+		if resetSha == "" {
+			fmt.Printf("🙅‍♂️ No commits on git branch %s were undone\n", gitMirror.GitBranch)
+		} else {
+			fmt.Printf("⏪ Reset git branch %s to %s\n", gitMirror.GitBranch, resetSha[:7])
+		}
+		fmt.Println()
+		printCmds()
+		return
+	}
+
 	// If no applies are being undone, skip revert entirely
 	if len(undonePlanApplies) == 0 {
 		// Just do the rewind, no need for any file operations
@@ -0,0 +1,582 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"sort"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/plandex-ai/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+var roleLabel string
+var roleDescription string
+var rolePermissions []string
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List org roles",
+	Run:   listRoles,
+}
+
+var showRoleCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show a role's permissions",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   showRole,
+}
+
+var createRoleCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a custom role",
+	Long:  "Create a custom role from any permissions you have yourself. Permissions that apply to users with a particular role are written as permission:role, e.g. invite_user:member.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createRole,
+}
+
+var updateRoleCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update a custom role's label, description or permissions",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   updateRole,
+}
+
+var deleteRoleCmd = &cobra.Command{
+	Use:     "rm [name]",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a custom role",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteRole,
+}
+
+var assignRoleCmd = &cobra.Command{
+	Use:   "assign [email] [role]",
+	Short: "Set the role of a user or pending invite",
+	Args:  cobra.MaximumNArgs(2),
+	Run:   assignRole,
+}
+
+func init() {
+	RootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(showRoleCmd)
+	rolesCmd.AddCommand(createRoleCmd)
+	rolesCmd.AddCommand(updateRoleCmd)
+	rolesCmd.AddCommand(deleteRoleCmd)
+	rolesCmd.AddCommand(assignRoleCmd)
+
+	supportJsonOutput(rolesCmd, showRoleCmd)
+
+	for _, cmd := range []*cobra.Command{createRoleCmd, updateRoleCmd} {
+		cmd.Flags().StringVar(&roleLabel, "label", "", "Display name for the role")
+		cmd.Flags().StringVar(&roleDescription, "description", "", "Description of the role")
+		cmd.Flags().StringSliceVar(&rolePermissions, "permissions", nil, "Comma-separated permissions (skips the prompt)")
+	}
+}
+
+func listRoles(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, roles)
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Label", "Type", "Permissions"})
+
+	for i, role := range roles {
+		roleType := "Custom"
+		if role.IsDefault {
+			roleType = "Default"
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			role.Name,
+			role.Label,
+			roleType,
+			strconv.Itoa(len(role.Permissions)),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "roles show", "roles create", "roles assign")
+}
+
+func showRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	var permissions []*shared.OrgPermission
+	if apiErr == nil {
+		permissions, apiErr = api.Client.ListOrgPermissions()
+	}
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	role := mustSelectRole(roles, name, "Select a role:", false)
+
+	if outputJson {
+		printJson(cmd, role)
+		return
+	}
+
+	descriptions := map[string]string{}
+	for _, permission := range permissions {
+		descriptions[permission.Key] = permission.Description
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Println(role.Label)
+	if role.Description != "" {
+		fmt.Println(role.Description)
+	}
+	fmt.Println()
+
+	if len(role.Permissions) == 0 {
+		fmt.Println("🤷‍♂️ No permissions")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Permission", "Description"})
+
+	for _, key := range role.Permissions {
+		table.Append([]string{permissionName(key, roles), descriptions[key]})
+	}
+
+	table.Render()
+}
+
+func createRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = strings.TrimSpace(args[0])
+	}
+
+	if name == "" {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Name (lowercase, e.g. reviewer):")
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get name: %v", err)
+		}
+	}
+
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	var permissions []*shared.OrgPermission
+	if apiErr == nil {
+		permissions, apiErr = api.Client.ListOrgPermissions()
+	}
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	label := roleLabel
+	if label == "" && !cmd.Flags().Changed("permissions") {
+		var err error
+		label, err = term.GetRequiredUserStringInputWithDefault("Label:", name)
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get label: %v", err)
+		}
+	}
+
+	keys := mustResolveRolePermissions(cmd, roles, permissions, nil)
+
+	term.StartSpinner("")
+	role, apiErr := api.Client.CreateOrgRole(shared.CreateOrgRoleRequest{
+		Name:        name,
+		Label:       label,
+		Description: roleDescription,
+		Permissions: keys,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created role %s with %d permissions\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label), len(role.Permissions))
+	fmt.Println()
+	term.PrintCmds("", "invite", "roles assign")
+}
+
+func updateRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	var permissions []*shared.OrgPermission
+	if apiErr == nil {
+		permissions, apiErr = api.Client.ListOrgPermissions()
+	}
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	role := mustSelectRole(roles, name, "Select a role to update:", true)
+
+	label := role.Label
+	if cmd.Flags().Changed("label") {
+		label = roleLabel
+	}
+
+	description := role.Description
+	if cmd.Flags().Changed("description") {
+		description = roleDescription
+	}
+
+	keys := role.Permissions
+	if cmd.Flags().Changed("permissions") || (!cmd.Flags().Changed("label") && !cmd.Flags().Changed("description")) {
+		keys = mustResolveRolePermissions(cmd, roles, permissions, role.Permissions)
+	}
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.UpdateOrgRole(role.Id, shared.UpdateOrgRoleRequest{
+		Label:       label,
+		Description: description,
+		Permissions: keys,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Updated role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(updated.Label))
+}
+
+func deleteRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	role := mustSelectRole(roles, name, "Select a role to remove:", true)
+
+	term.StartSpinner("")
+	apiErr = api.Client.DeleteOrgRole(role.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Removed role %s\n", role.Label)
+}
+
+func assignRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var email, roleName string
+	if len(args) > 0 {
+		email = args[0]
+	}
+	if len(args) > 1 {
+		roleName = args[1]
+	}
+
+	var userResp *shared.ListUsersResponse
+	var pendingInvites []*shared.Invite
+	var roles []*shared.OrgRole
+	errCh := make(chan error)
+
+	term.StartSpinner("")
+
+	go func() {
+		var err *shared.ApiError
+		userResp, err = api.Client.ListUsers()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching users: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	go func() {
+		var err *shared.ApiError
+		pendingInvites, err = api.Client.ListPendingInvites()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching pending invites: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	go func() {
+		var err *shared.ApiError
+		roles, err = api.Client.ListOrgRoles()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching org roles: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	for i := 0; i < 3; i++ {
+		err := <-errCh
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	term.StopSpinner()
+
+	type assignee struct {
+		Id       string
+		IsInvite bool
+	}
+
+	assigneesByEmail := map[string]assignee{}
+	labelToEmail := map[string]string{}
+
+	opts := make([]string, 0, len(userResp.Users)+len(pendingInvites))
+	for _, user := range userResp.Users {
+		label := fmt.Sprintf("%s <%s>", user.Name, user.Email)
+		labelToEmail[label] = user.Email
+		opts = append(opts, label)
+		assigneesByEmail[user.Email] = assignee{Id: user.Id}
+	}
+	for _, invite := range pendingInvites {
+		label := fmt.Sprintf("%s <%s> (invite pending)", invite.Name, invite.Email)
+		labelToEmail[label] = invite.Email
+		opts = append(opts, label)
+		assigneesByEmail[invite.Email] = assignee{Id: invite.Id, IsInvite: true}
+	}
+
+	if email == "" {
+		selected, err := term.SelectFromList("Select a user or invite:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+		email = labelToEmail[selected]
+	}
+
+	target, ok := assigneesByEmail[email]
+	if !ok {
+		term.OutputErrorAndExit("No user or pending invite found for email '%s'", email)
+	}
+
+	role := mustSelectRole(roles, roleName, "Select a role:", false)
+	req := shared.SetOrgRoleRequest{OrgRoleId: role.Id}
+
+	term.StartSpinner("")
+	var apiErr *shared.ApiError
+	if target.IsInvite {
+		apiErr = api.Client.SetInviteRole(target.Id, req)
+	} else {
+		apiErr = api.Client.SetUserRole(target.Id, req)
+	}
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Set role of %s to %s\n", email, color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+}
+
+// mustSelectRole finds a role by name or label, or prompts for one if name is empty
+func mustSelectRole(roles []*shared.OrgRole, name, prompt string, customOnly bool) *shared.OrgRole {
+	var candidates []*shared.OrgRole
+	for _, role := range roles {
+		if customOnly && role.IsDefault {
+			continue
+		}
+		candidates = append(candidates, role)
+	}
+
+	if name != "" {
+		for _, role := range candidates {
+			if role.Name == name || strings.EqualFold(role.Label, name) {
+				return role
+			}
+		}
+
+		for _, role := range roles {
+			if role.Name == name || strings.EqualFold(role.Label, name) {
+				term.OutputErrorAndExit("%s is a default role and can't be changed", role.Label)
+			}
+		}
+
+		term.OutputErrorAndExit("Role not found: %s", name)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println("🤷‍♂️ No custom roles")
+		fmt.Println()
+		term.PrintCmds("", "roles create")
+		os.Exit(0)
+	}
+
+	opts := make([]string, len(candidates))
+	for i, role := range candidates {
+		opts[i] = role.Label
+	}
+
+	selected, err := term.SelectFromList(prompt, opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting role: %v", err)
+	}
+
+	for _, role := range candidates {
+		if role.Label == selected {
+			return role
+		}
+	}
+
+	term.OutputErrorAndExit("Role not found: %s", selected)
+	return nil
+}
+
+// mustResolveRolePermissions returns permission keys from the --permissions flag, or prompts for them with current selected
+func mustResolveRolePermissions(cmd *cobra.Command, roles []*shared.OrgRole, permissions []*shared.OrgPermission, current []string) []string {
+	if cmd.Flags().Changed("permissions") {
+		var keys []string
+		for _, name := range rolePermissions {
+			key, err := permissionKey(strings.TrimSpace(name), roles)
+			if err != nil {
+				term.OutputErrorAndExit("%v", err)
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	opts := make([]string, len(permissions))
+	keysByOpt := map[string]string{}
+	descriptions := map[string]string{}
+	for i, permission := range permissions {
+		opt := permissionName(permission.Key, roles)
+		opts[i] = opt
+		keysByOpt[opt] = permission.Key
+		descriptions[opt] = permission.Description
+	}
+	sort.Strings(opts)
+
+	var defaults []string
+	for _, key := range current {
+		defaults = append(defaults, permissionName(key, roles))
+	}
+
+	var selected []string
+	prompt := &survey.MultiSelect{
+		Message: "Select permissions:",
+		Options: opts,
+		Default: defaults,
+		Description: func(value string, index int) string {
+			return descriptions[value]
+		},
+		PageSize: 15,
+	}
+
+	err := survey.AskOne(prompt, &selected)
+	if err != nil {
+		if err.Error() == "interrupt" {
+			os.Exit(0)
+		}
+		term.OutputErrorAndExit("Error selecting permissions: %v", err)
+	}
+
+	keys := make([]string, len(selected))
+	for i, opt := range selected {
+		keys[i] = keysByOpt[opt]
+	}
+
+	return keys
+}
+
+// permissionName formats a permission key for display, using role names in place of role ids: invite_user:member
+func permissionName(key string, roles []*shared.OrgRole) string {
+	permission, resourceId := shared.ParsePermissionKey(key)
+	if resourceId == "" {
+		return string(permission)
+	}
+
+	for _, role := range roles {
+		if role.Id == resourceId {
+			return string(permission) + ":" + role.Name
+		}
+	}
+
+	return string(permission) + ":" + resourceId
+}
+
+// permissionKey parses a permission name from permissionName back into a key
+func permissionKey(name string, roles []*shared.OrgRole) (string, error) {
+	permission, roleName, hasRole := strings.Cut(name, ":")
+
+	if !hasRole {
+		if shared.IsRoleScopedPermission(shared.Permission(permission)) {
+			return "", fmt.Errorf("%s applies to users with a particular role--use %s:<role>", permission, permission)
+		}
+		return permission, nil
+	}
+
+	for _, role := range roles {
+		if role.Name == roleName {
+			return shared.PermissionKey(shared.Permission(permission), role.Id), nil
+		}
+	}
+
+	return "", fmt.Errorf("role not found in permission %s: %s", name, roleName)
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var serviceAccountRole string
+
+var serviceAccountsCmd = &cobra.Command{
+	Use:     "service-accounts",
+	Aliases: []string{"sa"},
+	Short:   "List service accounts for the current org",
+	Run:     listServiceAccounts,
+}
+
+var createServiceAccountCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a service account",
+	Long:  "Create a service account for CI pipelines and other automation. It joins the org with the member role by default—use --role to choose. Create tokens for it with 'plandex tokens create --service-account <name>'.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createServiceAccount,
+}
+
+var deleteServiceAccountCmd = &cobra.Command{
+	Use:     "rm [name-or-index]",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a service account and revoke its tokens",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteServiceAccount,
+}
+
+func init() {
+	RootCmd.AddCommand(serviceAccountsCmd)
+	serviceAccountsCmd.AddCommand(createServiceAccountCmd)
+	serviceAccountsCmd.AddCommand(deleteServiceAccountCmd)
+
+	supportJsonOutput(serviceAccountsCmd)
+
+	createServiceAccountCmd.Flags().StringVar(&serviceAccountRole, "role", "", "Org role for the service account (default member)")
+}
+
+func listServiceAccounts(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	accounts, apiErr := api.Client.ListServiceAccounts()
+	var orgRoles []*shared.OrgRole
+	if apiErr == nil && len(accounts) > 0 {
+		orgRoles, apiErr = api.Client.ListOrgRoles()
+	}
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching service accounts: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, accounts)
+		return
+	}
+
+	if len(accounts) == 0 {
+		fmt.Println("🤷‍♂️ No service accounts")
+		fmt.Println()
+		term.PrintCmds("", "service-accounts create")
+		return
+	}
+
+	labelsById := map[string]string{}
+	for _, role := range orgRoles {
+		labelsById[role.Id] = role.Label
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Role", "Created"})
+
+	for i, account := range accounts {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			account.Name,
+			labelsById[account.OrgRoleId],
+			format.Time(account.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "service-accounts create", "tokens create --service-account", "service-accounts rm")
+}
+
+func createServiceAccount(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = strings.TrimSpace(args[0])
+	}
+
+	if name == "" {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Name:")
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get name: %v", err)
+		}
+	}
+
+	var orgRoleId string
+	if serviceAccountRole != "" {
+		term.StartSpinner("")
+		orgRoles, apiErr := api.Client.ListOrgRoles()
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Failed to list org roles: %v", apiErr.Msg)
+		}
+
+		for _, role := range orgRoles {
+			if role.Name == serviceAccountRole || strings.EqualFold(role.Label, serviceAccountRole) {
+				orgRoleId = role.Id
+				break
+			}
+		}
+
+		if orgRoleId == "" {
+			term.OutputErrorAndExit("Org role not found: %s", serviceAccountRole)
+		}
+	}
+
+	term.StartSpinner("")
+	account, apiErr := api.Client.CreateServiceAccount(shared.CreateServiceAccountRequest{
+		Name:      name,
+		OrgRoleId: orgRoleId,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating service account: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created service account %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(account.Name))
+	fmt.Println()
+	term.PrintCmds("", "tokens create --service-account "+account.Name)
+}
+
+func deleteServiceAccount(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var nameOrIdx string
+	if len(args) > 0 {
+		nameOrIdx = args[0]
+	}
+
+	account := mustSelectServiceAccount(nameOrIdx, "Select service account to remove:")
+	if account == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteServiceAccount(account.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing service account: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Removed service account %s and revoked its tokens\n", account.Name)
+}
+
+// mustSelectServiceAccount finds a service account by name or index, or prompts for one if nameOrIdx is empty
+func mustSelectServiceAccount(nameOrIdx, prompt string) *shared.ServiceAccount {
+	term.StartSpinner("")
+	accounts, apiErr := api.Client.ListServiceAccounts()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching service accounts: %v", apiErr.Msg)
+		return nil
+	}
+
+	if len(accounts) == 0 {
+		fmt.Println("🤷‍♂️ No service accounts")
+		return nil
+	}
+
+	if nameOrIdx != "" {
+		for _, account := range accounts {
+			if account.Name == nameOrIdx {
+				return account
+			}
+		}
+
+		index, err := strconv.Atoi(nameOrIdx)
+		if err != nil || index < 1 || index > len(accounts) {
+			term.OutputErrorAndExit("Service account not found: %s", nameOrIdx)
+			return nil
+		}
+		return accounts[index-1]
+	}
+
+	if len(accounts) == 1 {
+		return accounts[0]
+	}
+
+	opts := make([]string, len(accounts))
+	for i, account := range accounts {
+		opts[i] = account.Name
+	}
+
+	selected, err := term.SelectFromList(prompt, opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting service account: %v", err)
+		return nil
+	}
+
+	for i, opt := range opts {
+		if opt == selected {
+			return accounts[i]
+		}
+	}
+
+	return nil
+}
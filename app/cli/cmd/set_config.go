@@ -246,7 +246,10 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 		} else if cfgSetting.StringSetter != nil {
 			var selection string
 			var err error
-			choices := *cfgSetting.Choices
+			var choices []string
+			if cfgSetting.Choices != nil {
+				choices = *cfgSetting.Choices
+			}
 			if len(choices) > 0 {
 				if cfgSetting.HasCustomChoice {
 					choices = append(choices, "Other")
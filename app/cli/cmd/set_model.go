@@ -125,6 +125,8 @@ func updateModelSettings(args []string, originalSettings *shared.PlanSettings) *
 		return nil
 	}
 
+	builtInModelPacks := shared.GetBuiltInModelPacks(auth.Current.IsCloud)
+
 	var modelSetOrRoleOrSetting, propertyCompact, value string
 	var modelPack *shared.ModelPack
 	var role shared.ModelRole
@@ -139,7 +141,7 @@ func updateModelSettings(args []string, originalSettings *shared.PlanSettings) *
 		modelSetOrRoleOrSetting = args[0]
 
 		compare := modelSetOrRoleOrSetting
-		for _, ms := range shared.BuiltInModelPacks {
+		for _, ms := range builtInModelPacks {
 			if strings.EqualFold(ms.Name, compare) {
 				modelPack = ms
 				break
@@ -199,7 +201,7 @@ func updateModelSettings(args []string, originalSettings *shared.PlanSettings) *
 
 		if idx == 0 {
 			var opts []string
-			for _, ms := range shared.BuiltInModelPacks {
+			for _, ms := range builtInModelPacks {
 				opts = append(opts, "Built-in | "+ms.Name)
 			}
 
@@ -240,10 +242,10 @@ func updateModelSettings(args []string, originalSettings *shared.PlanSettings) *
 				}
 			}
 
-			if idx < len(shared.BuiltInModelPacks) {
-				modelPack = shared.BuiltInModelPacks[idx]
+			if idx < len(builtInModelPacks) {
+				modelPack = builtInModelPacks[idx]
 			} else {
-				modelPack = customModelPacks[idx-len(shared.BuiltInModelPacks)]
+				modelPack = customModelPacks[idx-len(builtInModelPacks)]
 			}
 
 		} else if idx < len(shared.AllModelRoles)+1 {
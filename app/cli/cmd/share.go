@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var shareReadOnly bool
+
+var shareCmd = &cobra.Command{
+	Use:   "share [email]",
+	Short: "Share the current plan with an org member, or list who it's shared with",
+	Long: `Share the current plan with an org member, or list who it's shared with.
+
+Collaborators can do everything but rename, archive or delete the plan. With --read, they can view the plan, its context, changes and logs, and connect to its streams.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  share,
+}
+
+func init() {
+	RootCmd.AddCommand(shareCmd)
+	shareCmd.Flags().BoolVarP(&shareReadOnly, "read", "r", false, "Share read-only instead of as a collaborator")
+}
+
+func share(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	if len(args) == 0 {
+		listPlanShares()
+		return
+	}
+
+	access := shared.PlanShareAccessCollaborator
+	if shareReadOnly {
+		access = shared.PlanShareAccessRead
+	}
+
+	term.StartSpinner("")
+	planShare, apiErr := api.Client.SharePlan(lib.CurrentPlanId, shared.SharePlanRequest{
+		Email:  strings.TrimSpace(args[0]),
+		Access: access,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error sharing plan: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Shared plan with %s as %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(planShare.UserEmail), planShareAccessLabel(planShare.Access))
+	fmt.Println()
+	term.PrintCmds("", "share", "unshare")
+}
+
+func listPlanShares() {
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared with anyone")
+		fmt.Println()
+		term.PrintCmds("", "share")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Email", "Access", "Shared"})
+
+	for _, planShare := range shares {
+		table.Append([]string{
+			planShare.UserName,
+			planShare.UserEmail,
+			planShareAccessLabel(planShare.Access),
+			format.Time(planShare.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "share", "unshare")
+}
+
+func planShareAccessLabel(access shared.PlanShareAccess) string {
+	if access == shared.PlanShareAccessRead {
+		return "read-only"
+	}
+	return string(access)
+}
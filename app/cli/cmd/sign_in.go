@@ -8,6 +8,7 @@ import (
 )
 
 var pin string
+var ssoHost string
 
 var signInCmd = &cobra.Command{
 	Use:   "sign-in",
@@ -20,6 +21,7 @@ func init() {
 	RootCmd.AddCommand(signInCmd)
 
 	signInCmd.Flags().StringVar(&pin, "pin", "", "Sign in with a pin from the Plandex Cloud web UI")
+	signInCmd.Flags().StringVar(&ssoHost, "sso", "", "Sign in with single sign-on to a self-hosted server (pass its host)")
 }
 
 func signIn(cmd *cobra.Command, args []string) {
@@ -33,6 +35,16 @@ func signIn(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if ssoHost != "" {
+		err := auth.SignInWithSSO(ssoHost)
+
+		if err != nil {
+			term.OutputErrorAndExit("Error signing in: %v", err)
+		}
+
+		return
+	}
+
 	err := auth.SelectOrSignInOrCreate()
 
 	if err != nil {
@@ -20,6 +20,7 @@ var statusCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(statusCmd)
+	supportJsonOutput(statusCmd)
 
 	statusCmd.Flags().BoolVarP(&summaryPlain, "plain", "p", false, "Output summary in plain text with no ANSI codes")
 }
@@ -36,6 +37,13 @@ func status(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error loading conversation: %v", apiErr.Msg)
 	}
 
+	if outputJson {
+		printJson(cmd, map[string]interface{}{
+			"summary": status,
+		})
+		return
+	}
+
 	if status == "" {
 		fmt.Println("🤷‍♂️ No summary available")
 	}
@@ -91,6 +91,8 @@ func doTell(cmd *cobra.Command, args []string) {
 			NoExec:      noExec,
 			AutoExec:    autoExec || autoDebug > 0,
 			AutoDebug:   autoDebug,
+			ExecSandbox: execSandbox,
+			NoVerify:    noVerify,
 		}
 
 		lib.MustApplyPlan(lib.ApplyPlanParams{
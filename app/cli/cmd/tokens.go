@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var tokenServiceAccount string
+var tokenScopes []string
+var tokenExpiresInDays int
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "List your API tokens for the current org",
+	Run:   listApiTokens,
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an API token",
+	Long:  "Create an API token for headless use, like in CI. Set it as PLANDEX_API_TOKEN and Plandex uses it instead of signing in. Use --service-account to create the token for a service account rather than yourself.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createApiToken,
+}
+
+var revokeTokenCmd = &cobra.Command{
+	Use:     "revoke [index]",
+	Aliases: []string{"rm"},
+	Short:   "Revoke an API token",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     revokeApiToken,
+}
+
+func init() {
+	RootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(createTokenCmd)
+	tokensCmd.AddCommand(revokeTokenCmd)
+
+	supportJsonOutput(tokensCmd)
+
+	scopeNames := make([]string, len(shared.ApiTokenScopes))
+	for i, scope := range shared.ApiTokenScopes {
+		scopeNames[i] = string(scope)
+	}
+
+	for _, c := range []*cobra.Command{tokensCmd, createTokenCmd, revokeTokenCmd} {
+		c.Flags().StringVarP(&tokenServiceAccount, "service-account", "s", "", "Service account name or index")
+	}
+
+	createTokenCmd.Flags().StringSliceVar(&tokenScopes, "scopes", []string{string(shared.ApiTokenScopeRead), string(shared.ApiTokenScopeTell)}, "Comma-separated scopes: "+strings.Join(scopeNames, ", "))
+	createTokenCmd.Flags().IntVar(&tokenExpiresInDays, "expires-in", 90, "Days until the token expires, or 0 for no expiry")
+}
+
+func listApiTokens(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var serviceAccountId string
+	if tokenServiceAccount != "" {
+		account := mustSelectServiceAccount(tokenServiceAccount, "")
+		if account == nil {
+			return
+		}
+		serviceAccountId = account.Id
+	}
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens(serviceAccountId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching API tokens: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, tokens)
+		return
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No API tokens")
+		fmt.Println()
+		term.PrintCmds("", "tokens create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Token", "Scopes", "Expires", "Last Used", "Created"})
+
+	for i, token := range tokens {
+		expires := "never"
+		if token.ExpiresAt != nil {
+			expires = format.Time(*token.ExpiresAt)
+		}
+
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = format.Time(*token.LastUsedAt)
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			token.Name,
+			token.TokenPrefix + "…",
+			apiTokenScopesLabel(token.Scopes),
+			expires,
+			lastUsed,
+			format.Time(token.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "tokens create", "tokens revoke")
+}
+
+func createApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = strings.TrimSpace(args[0])
+	}
+
+	if name == "" {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Name:")
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get name: %v", err)
+		}
+	}
+
+	scopes := shared.ApiTokenScopeList{}
+	for _, s := range tokenScopes {
+		scope := shared.ApiTokenScope(strings.TrimSpace(s))
+		if !shared.IsValidApiTokenScope(scope) {
+			term.OutputErrorAndExit("Invalid scope: %s", s)
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+
+	req := shared.CreateApiTokenRequest{
+		Name:          name,
+		Scopes:        scopes,
+		ExpiresInDays: tokenExpiresInDays,
+	}
+
+	if tokenServiceAccount != "" {
+		account := mustSelectServiceAccount(tokenServiceAccount, "")
+		if account == nil {
+			return
+		}
+		req.ServiceAccountId = account.Id
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateApiToken(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating API token: %v", apiErr.Msg)
+		return
+	}
+
+	expires := "never expires"
+	if res.ApiToken.ExpiresAt != nil {
+		expires = "expires " + format.Time(*res.ApiToken.ExpiresAt)
+	}
+
+	fmt.Printf("✅ Created API token %s with %s scopes (%s)\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.ApiToken.Name), apiTokenScopesLabel(res.ApiToken.Scopes), expires)
+	fmt.Println()
+	fmt.Println("🔑 Token—copy it now, it won't be shown again:")
+	fmt.Println(color.New(color.Bold).Sprint(res.Token))
+	fmt.Println()
+	fmt.Printf("Set it as %s to run Plandex headlessly, like in CI.\n", auth.ApiTokenEnvVar)
+}
+
+func revokeApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var serviceAccountId string
+	if tokenServiceAccount != "" {
+		account := mustSelectServiceAccount(tokenServiceAccount, "")
+		if account == nil {
+			return
+		}
+		serviceAccountId = account.Id
+	}
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens(serviceAccountId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching API tokens: %v", apiErr.Msg)
+		return
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No API tokens")
+		return
+	}
+
+	var token *shared.ApiToken
+	if len(args) == 1 {
+		index, err := strconv.Atoi(args[0])
+		if err != nil || index < 1 || index > len(tokens) {
+			term.OutputErrorAndExit("Invalid token index: %s", args[0])
+			return
+		}
+		token = tokens[index-1]
+	} else {
+		opts := make([]string, len(tokens))
+		for i, t := range tokens {
+			opts[i] = fmt.Sprintf("%d. %s (%s…)", i+1, t.Name, t.TokenPrefix)
+		}
+
+		selected, err := term.SelectFromList("Select token to revoke:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting token: %v", err)
+			return
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				token = tokens[i]
+				break
+			}
+		}
+	}
+
+	if token == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.RevokeApiToken(token.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error revoking API token: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Revoked API token %s\n", token.Name)
+}
+
+func apiTokenScopesLabel(scopes shared.ApiTokenScopeList) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var unshareCmd = &cobra.Command{
+	Use:   "unshare [email]",
+	Short: "Stop sharing the current plan with an org member",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   unshare,
+}
+
+func init() {
+	RootCmd.AddCommand(unshareCmd)
+}
+
+func unshare(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared with anyone")
+		return
+	}
+
+	var email string
+	if len(args) > 0 {
+		email = strings.TrimSpace(args[0])
+	} else {
+		opts := make([]string, len(shares))
+		for i, planShare := range shares {
+			opts[i] = planShare.UserEmail
+		}
+
+		var err error
+		email, err = term.SelectFromList("Stop sharing with:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+	}
+
+	var userId string
+	for _, planShare := range shares {
+		if strings.EqualFold(planShare.UserEmail, email) {
+			userId = planShare.UserId
+			break
+		}
+	}
+
+	if userId == "" {
+		term.OutputErrorAndExit("Plan isn't shared with %s", email)
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.UnsharePlan(lib.CurrentPlanId, userId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error unsharing plan: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Stopped sharing plan with %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(email))
+}
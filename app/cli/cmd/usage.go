@@ -39,11 +39,13 @@ var creditsCurrentPlan bool
 var usageCmd = &cobra.Command{
 	Use:   "usage",
 	Short: "Display credits balance and usage report",
+	Long:  "Display credits balance and usage report. On a self-hosted server, shows spend, token usage, and cache hit rates from the server's usage ledger.",
 	Run:   usage,
 }
 
 func init() {
 	RootCmd.AddCommand(usageCmd)
+	supportJsonOutput(usageCmd)
 
 	usageCmd.Flags().BoolVar(&showUsageLog, "log", false, "Show usage log")
 	usageCmd.Flags().IntVarP(&logCreditsPageSize, "page-size", "s", 100, "Number of transactions to display per page")
@@ -52,7 +54,7 @@ func init() {
 	usageCmd.Flags().BoolVar(&logCreditsCreditsOnly, "purchases", false, "Show only purchases in the log")
 
 	usageCmd.Flags().BoolVar(&creditsToday, "today", false, "Show usage for today")
-	usageCmd.Flags().BoolVar(&creditsMonth, "month", false, "Show usage for current billing month")
+	usageCmd.Flags().BoolVar(&creditsMonth, "month", false, "Show usage for current billing month (calendar month in UTC when self-hosting)")
 	usageCmd.Flags().BoolVar(&creditsCurrentPlan, "plan", false, "Show usage for the current plan")
 }
 
@@ -60,11 +62,11 @@ func usage(cmd *cobra.Command, args []string) {
 	if showUsageLog {
 		showLog(cmd, args)
 	} else {
-		showUsage()
+		showUsage(cmd)
 	}
 }
 
-func showUsage() {
+func showUsage(cmd *cobra.Command) {
 	auth.MustResolveAuthWithOrg()
 
 	term.StartSpinner("")
@@ -104,6 +106,16 @@ func showUsage() {
 		currentPlanName = plan.Name
 	}
 
+	if !auth.Current.IsCloud {
+		showLedgerUsage(cmd, shared.UsageRequest{
+			SessionId: sessionId,
+			DayStart:  dayStart,
+			Month:     creditsMonth,
+			PlanId:    planId,
+		}, currentPlanName)
+		return
+	}
+
 	req := shared.CreditsLogRequest{
 		SessionId: sessionId,
 		DayStart:  dayStart,
@@ -118,6 +130,11 @@ func showUsage() {
 		term.OutputErrorAndExit("Error getting credits summary: %v", apiErr)
 	}
 
+	if outputJson {
+		printJson(cmd, res)
+		return
+	}
+
 	builder := strings.Builder{}
 
 	balance := res.Balance
@@ -283,6 +300,16 @@ func showLog(cmd *cobra.Command, args []string) {
 		planName = plan.Name
 	}
 
+	if !auth.Current.IsCloud {
+		showLedgerLog(cmd, args, shared.UsageRequest{
+			SessionId: sessionId,
+			DayStart:  dayStart,
+			Month:     creditsMonth,
+			PlanId:    planId,
+		}, planName)
+		return
+	}
+
 	req := shared.CreditsLogRequest{
 		TransactionType: transactionType,
 		SessionId:       sessionId,
@@ -299,6 +326,11 @@ func showLog(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputJson {
+		printJson(cmd, res)
+		return
+	}
+
 	transactions := res.Transactions
 
 	if len(transactions) == 0 {
@@ -418,13 +450,20 @@ func showLog(cmd *cobra.Command, args []string) {
 
 	term.PageOutput(output)
 
+	if res.NumPages > 1 {
+		promptUsageLogPage(cmd, args, res.NumPages, res.NumPagesMax, pageLine)
+	}
+}
+
+// promptUsageLogPage lets the user page through the usage log, re-running the log command for the selected page
+func promptUsageLogPage(cmd *cobra.Command, args []string, numPages int, numPagesMax bool, pageLine string) {
 	var inputFn func()
 	inputFn = func() {
 		fmt.Println("\n" + pageLine)
 
 		prompts := []string{}
 
-		if res.NumPages > 1 && logCreditsPage < res.NumPages {
+		if numPages > 1 && logCreditsPage < numPages {
 			prompts = append(prompts, "Press 'n' for next page")
 		}
 
@@ -467,7 +506,7 @@ func showLog(cmd *cobra.Command, args []string) {
 					}
 
 					// Check if the page number is valid
-					if pageNumber >= 1 && (pageNumber <= res.NumPages || res.NumPagesMax) {
+					if pageNumber >= 1 && (pageNumber <= numPages || numPagesMax) {
 						logCreditsPage = pageNumber
 						showLog(cmd, args) // Re-run the log command with the new page
 					} else {
@@ -506,7 +545,7 @@ func showLog(cmd *cobra.Command, args []string) {
 		fmt.Print(string(char))
 		switch char {
 		case 'n':
-			if logCreditsPage < res.NumPages || res.NumPagesMax {
+			if logCreditsPage < numPages || numPagesMax {
 				logCreditsPage++
 				showLog(cmd, args)
 			} else {
@@ -534,9 +573,7 @@ func showLog(cmd *cobra.Command, args []string) {
 
 	}
 
-	if res.NumPages > 1 {
-		inputFn()
-	}
+	inputFn()
 }
 
 func formatSpend(spend decimal.Decimal) string {
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"plandex-cli/api"
+	"plandex-cli/term"
+	shared "plandex-shared"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// self-hosted servers record usage in their own ledger rather than in Plandex Cloud credits
+
+func showLedgerUsage(cmd *cobra.Command, req shared.UsageRequest, currentPlanName string) {
+	res, apiErr := api.Client.GetUsageSummary(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting usage summary: %v", apiErr.Msg)
+	}
+
+	if outputJson {
+		printJson(cmd, res)
+		return
+	}
+
+	builder := strings.Builder{}
+
+	spendLbl := "💸 Spent"
+	if req.SessionId != "" {
+		spendLbl += " This Session"
+	} else if req.DayStart != nil {
+		spendLbl += " Today"
+	} else if req.Month {
+		spendLbl += fmt.Sprintf(" This Month (since %s)", res.MonthStart.Format("Jan 2"))
+	} else if req.PlanId != "" {
+		spendLbl += fmt.Sprintf(" On Plan 📋 %s", currentPlanName)
+	}
+
+	table := tablewriter.NewWriter(&builder)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{spendLbl, "📨 Requests", "🪙 Input", "🪙 Output", "🎯 Cache Hits"})
+	table.Append([]string{
+		formatSpend(res.Total.Spend),
+		strconv.Itoa(res.Total.NumRequests),
+		strconv.Itoa(res.Total.InputTokens),
+		strconv.Itoa(res.Total.OutputTokens),
+		formatCacheHitRate(res.Total),
+	})
+	table.Render()
+	fmt.Fprintln(&builder)
+
+	if !res.CacheSavings.IsZero() {
+		table := tablewriter.NewWriter(&builder)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"🎯 Cache Savings"})
+		table.Append([]string{formatSpend(res.CacheSavings)})
+		table.Render()
+		fmt.Fprintln(&builder)
+	}
+
+	if req.PlanId == "" && len(res.ByPlanId) > 0 {
+		byPlanName := map[string]shared.UsageBreakdown{}
+		for id, breakdown := range res.ByPlanId {
+			byPlanName[res.PlanNamesById[id]] = breakdown
+		}
+		renderUsageBreakdown(&builder, "📋 Plan", byPlanName)
+	}
+
+	renderUsageBreakdown(&builder, "🎭 Role", res.ByModelRole)
+	renderUsageBreakdown(&builder, "⚡️ Purpose", res.ByPurpose)
+	renderUsageBreakdown(&builder, "🤖 Model", res.ByModelName)
+
+	term.PageOutput(builder.String())
+
+	term.PrintCmds("", "usage --log", "budgets")
+}
+
+func renderUsageBreakdown(w io.Writer, label string, byKey map[string]shared.UsageBreakdown) {
+	if len(byKey) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return byKey[keys[i]].Spend.GreaterThan(byKey[keys[j]].Spend)
+	})
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{label, "💸 Spent", "📨 Requests", "🎯 Cache Hits"})
+	for _, key := range keys {
+		breakdown := byKey[key]
+		table.Append([]string{
+			key,
+			formatSpend(breakdown.Spend),
+			strconv.Itoa(breakdown.NumRequests),
+			formatCacheHitRate(breakdown),
+		})
+	}
+	table.Render()
+	fmt.Fprintln(w)
+}
+
+func formatCacheHitRate(breakdown shared.UsageBreakdown) string {
+	if breakdown.InputTokens == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", breakdown.CacheHitRate()*100)
+}
+
+func showLedgerLog(cmd *cobra.Command, args []string, req shared.UsageRequest, planName string) {
+	res, apiErr := api.Client.GetUsageLog(logCreditsPageSize, logCreditsPage, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting usage log: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, res)
+		return
+	}
+
+	if len(res.Entries) == 0 {
+		lbl := "🤷‍♂️ No usage"
+		if req.SessionId != "" {
+			lbl = "🤷‍♂️ No usage so far this session"
+		} else if req.DayStart != nil {
+			tz, _ := time.Now().Zone()
+			lbl = fmt.Sprintf("🤷‍♂️ No usage so far today (since midnight %s)", tz)
+		} else if req.Month {
+			lbl = fmt.Sprintf("🤷‍♂️ No usage so far this month (since %s)", res.MonthStart.Format("Jan 2"))
+		} else if req.PlanId != "" {
+			lbl = "🤷‍♂️ No usage so far for current plan 👉 " + planName
+		}
+		fmt.Println(lbl)
+		return
+	}
+
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Cost", "Request"})
+
+	for _, entry := range res.Entries {
+		desc := entry.CreatedAt.Local().Format("2006-01-02 15:04:05.000 EST") + "\n"
+
+		if entry.PlanId != nil {
+			desc += fmt.Sprintf("Plan → %s\n", res.PlanNamesById[*entry.PlanId])
+		}
+
+		if entry.Purpose != "" {
+			desc += fmt.Sprintf("⚡️ %s\n", entry.Purpose)
+		}
+
+		model := string(entry.ModelName)
+		if entry.ModelRole != "" {
+			model = fmt.Sprintf("%s → %s", entry.ModelRole, model)
+		}
+		if entry.ModelPackName != "" {
+			model = fmt.Sprintf("%s | %s", entry.ModelPackName, model)
+		}
+		desc += fmt.Sprintf("🧠 %s\n", model)
+
+		desc += fmt.Sprintf("🪙 Used → %d input / %d output", entry.InputTokens, entry.OutputTokens)
+		if entry.ReasoningTokens > 0 {
+			desc += fmt.Sprintf(" (%d reasoning)", entry.ReasoningTokens)
+		}
+		desc += "\n"
+
+		if entry.CachedTokens > 0 {
+			desc += fmt.Sprintf("🎯 Cache hits → %d tokens (%d%%)", entry.CachedTokens, entry.CachedTokens*100/entry.InputTokens)
+			if !entry.CacheSavings.IsZero() {
+				desc += fmt.Sprintf(", saved %s", formatSpend(entry.CacheSavings))
+			}
+			desc += "\n"
+		}
+
+		if entry.UserCancelled {
+			desc += "🛑 Cancelled\n"
+		} else if entry.HadError {
+			desc += "🚨 Error\n"
+		} else if entry.StoppedEarly {
+			desc += "⏹️ Stopped early\n"
+		}
+
+		if entry.NoReportedUsage {
+			desc += "⚠️  No usage reported by provider—tokens are estimated\n"
+		}
+
+		table.Append([]string{
+			color.New(term.ColorHiRed).Sprint(formatSpend(entry.Cost)),
+			desc,
+		})
+	}
+
+	table.Render()
+
+	var output string
+	var pageLine string
+
+	if res.NumPages > 1 {
+		pageLine = fmt.Sprintf("Page size %d. Showing page %d of %d", logCreditsPageSize, logCreditsPage, res.NumPages)
+		output = pageLine + "\n\n" + tableString.String()
+	} else {
+		output = tableString.String()
+	}
+
+	term.PageOutput(output)
+
+	if res.NumPages > 1 {
+		promptUsageLogPage(cmd, args, res.NumPages, false, pageLine)
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var webhookEvents []string
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "List outgoing webhooks for the current org",
+	Run:   listWebhooks,
+}
+
+var addWebhookCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add an outgoing webhook",
+	Long:  "Add an outgoing webhook. Each delivery is a signed JSON POST to the url. Sends every event by default—use --events to choose.",
+	Args:  cobra.ExactArgs(1),
+	Run:   addWebhook,
+}
+
+var deleteWebhookCmd = &cobra.Command{
+	Use:     "rm [index]",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove an outgoing webhook",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteWebhook,
+}
+
+var testWebhookCmd = &cobra.Command{
+	Use:   "test [index]",
+	Short: "Send a test delivery to a webhook",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   testWebhook,
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries [index]",
+	Short: "Show recent deliveries for a webhook",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   listWebhookDeliveries,
+}
+
+func init() {
+	RootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(addWebhookCmd)
+	webhooksCmd.AddCommand(deleteWebhookCmd)
+	webhooksCmd.AddCommand(testWebhookCmd)
+	webhooksCmd.AddCommand(webhookDeliveriesCmd)
+
+	supportJsonOutput(webhooksCmd, webhookDeliveriesCmd)
+
+	eventNames := make([]string, len(shared.WebhookEvents))
+	for i, event := range shared.WebhookEvents {
+		eventNames[i] = string(event)
+	}
+	addWebhookCmd.Flags().StringSliceVar(&webhookEvents, "events", nil, "Comma-separated events to send: "+strings.Join(eventNames, ", "))
+}
+
+func listWebhooks(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, webhooks)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		fmt.Println()
+		term.PrintCmds("", "webhooks add")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Url", "Events", "Created"})
+
+	for i, webhook := range webhooks {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			webhook.Url,
+			webhookEventsLabel(webhook.Events),
+			format.Time(webhook.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "webhooks add", "webhooks test", "webhooks deliveries", "webhooks rm")
+}
+
+func addWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	events := shared.WebhookEventList{}
+	if len(webhookEvents) == 0 {
+		events = append(events, shared.WebhookEvents...)
+	} else {
+		for _, name := range webhookEvents {
+			event := shared.WebhookEvent(strings.TrimSpace(name))
+			if !shared.IsValidWebhookEvent(event) {
+				term.OutputErrorAndExit("Invalid webhook event: %s", name)
+				return
+			}
+			events = append(events, event)
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateWebhook(shared.CreateWebhookRequest{
+		Url:    args[0],
+		Events: events,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error adding webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Added webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhookEventsLabel(res.Webhook.Events)))
+	fmt.Println()
+	fmt.Println("🔑 Signing secret—copy it now, it won't be shown again:")
+	fmt.Println(color.New(color.Bold).Sprint(res.Secret))
+	fmt.Println()
+	fmt.Printf("Each delivery has a %s header: 'sha256=' followed by the hex HMAC-SHA256 of '<%s>.<body>' keyed with this secret.\n", shared.WebhookSignatureHeader, shared.WebhookTimestampHeader)
+	fmt.Println()
+	term.PrintCmds("", "webhooks test", "webhooks")
+}
+
+func deleteWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "Select webhook to remove:")
+	if webhook == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Removed webhook for %s\n", webhook.Url)
+}
+
+func testWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "Select webhook to test:")
+	if webhook == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.TestWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("%v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Sent a test delivery to %s\n", webhook.Url)
+}
+
+func listWebhookDeliveries(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "Select webhook:")
+	if webhook == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ListWebhookDeliveries(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhook deliveries: %v", apiErr.Msg)
+		return
+	}
+
+	if outputJson {
+		printJson(cmd, res.Deliveries)
+		return
+	}
+
+	if len(res.Deliveries) == 0 {
+		fmt.Println("🤷‍♂️ No deliveries yet")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Event", "Status", "Attempts", "Last Response", "Created"})
+
+	for _, delivery := range res.Deliveries {
+		status := string(delivery.Status)
+		if delivery.Status == shared.WebhookDeliveryStatusPending && delivery.Attempts > 0 {
+			status = "retrying " + format.Time(delivery.NextAttemptAt)
+		}
+
+		lastResponse := "-"
+		if delivery.LastError != nil {
+			lastResponse = *delivery.LastError
+		} else if delivery.LastStatusCode != nil {
+			lastResponse = strconv.Itoa(*delivery.LastStatusCode)
+		}
+		if len(lastResponse) > 60 {
+			lastResponse = lastResponse[:60] + "…"
+		}
+
+		table.Append([]string{
+			string(delivery.Event),
+			status,
+			strconv.Itoa(delivery.Attempts),
+			lastResponse,
+			format.Time(delivery.CreatedAt),
+		})
+	}
+
+	table.Render()
+}
+
+func mustSelectWebhook(args []string, prompt string) *shared.Webhook {
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+		return nil
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		return nil
+	}
+
+	if len(args) == 1 {
+		index, err := strconv.Atoi(args[0])
+		if err != nil || index < 1 || index > len(webhooks) {
+			term.OutputErrorAndExit("Invalid webhook index: %s", args[0])
+			return nil
+		}
+		return webhooks[index-1]
+	}
+
+	if len(webhooks) == 1 {
+		return webhooks[0]
+	}
+
+	opts := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		opts[i] = fmt.Sprintf("%d. %s (%s)", i+1, webhook.Url, webhookEventsLabel(webhook.Events))
+	}
+
+	selected, err := term.SelectFromList(prompt, opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting webhook: %v", err)
+		return nil
+	}
+
+	for i, opt := range opts {
+		if opt == selected {
+			return webhooks[i]
+		}
+	}
+
+	return nil
+}
+
+func webhookEventsLabel(events shared.WebhookEventList) string {
+	if len(events) == len(shared.WebhookEvents) {
+		return "all events"
+	}
+
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,38 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	shared "plandex-shared"
+)
+
+// LoadProjectDiagnosticsConfig reads the diagnostics config file in the project root, if there is one
+func LoadProjectDiagnosticsConfig() (*shared.DiagnosticsConfig, error) {
+	if ProjectRoot == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(ProjectRoot, shared.DiagnosticsConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %v", shared.DiagnosticsConfigFileName, err)
+	}
+
+	var config shared.DiagnosticsConfig
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", shared.DiagnosticsConfigFileName, err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", shared.DiagnosticsConfigFileName, err)
+	}
+
+	return &config, nil
+}
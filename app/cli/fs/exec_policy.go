@@ -0,0 +1,38 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	shared "plandex-shared"
+)
+
+// LoadProjectExecPolicy reads the exec policy file in the project root, if there is one
+func LoadProjectExecPolicy() (*shared.ExecPolicy, error) {
+	if ProjectRoot == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(ProjectRoot, shared.ExecPolicyFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %v", shared.ExecPolicyFileName, err)
+	}
+
+	var policy shared.ExecPolicy
+	err = json.Unmarshal(bytes, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", shared.ExecPolicyFileName, err)
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", shared.ExecPolicyFileName, err)
+	}
+
+	return &policy, nil
+}
@@ -0,0 +1,38 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// LoadProjectVerifyConfig reads the verification config file in the project root, if there is one
+func LoadProjectVerifyConfig() (*shared.VerifyConfig, error) {
+	if ProjectRoot == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(ProjectRoot, shared.VerifyConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %v", shared.VerifyConfigFileName, err)
+	}
+
+	var config shared.VerifyConfig
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", shared.VerifyConfigFileName, err)
+	}
+
+	if strings.TrimSpace(config.Command) == "" {
+		return nil, fmt.Errorf("invalid %s: command can't be empty", shared.VerifyConfigFileName)
+	}
+
+	return &config, nil
+}
@@ -15,6 +15,7 @@ import (
 	"plandex-cli/term"
 	"plandex-cli/types"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -52,6 +53,8 @@ type ApplyPlanParams struct {
 	TellFlags   types.TellFlags
 	OnExecFail  types.OnApplyExecFailFn
 	ExecCommand string
+	// if set, only pending changes to these paths (or paths under these directories) are applied
+	Paths []string
 }
 
 func MustApplyPlan(
@@ -150,11 +153,33 @@ func MustApplyPlanAttempt(
 
 	toApply := currentPlanFiles.Files
 	toRemove := currentPlanFiles.Removed
-	hasExec := currentPlanFiles.Files["_apply.sh"] != ""
+
+	var applyPaths []string
+	if len(params.Paths) > 0 {
+		toApply, toRemove, applyPaths, err = filterPendingPaths(toApply, toRemove, params.Paths)
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	if protected := protectedConfigPaths(fs.ProjectRoot, toApply, toRemove); len(protected) > 0 {
+		term.StopSpinner()
+		color.New(term.ColorHiRed, color.Bold).Println("🚫 Plans can't change config files that control which commands Plandex runs")
+		for _, path := range protected {
+			fmt.Println("• " + path)
+		}
+		fmt.Println()
+		fmt.Println("Reject these changes, then apply again. If you want them, make them yourself.")
+		term.PrintCmds("", "reject")
+		os.Exit(1)
+	}
+
+	hasExec := toApply["_apply.sh"] != ""
 
 	log.Printf("Files to apply: %d, Has exec script: %v", len(toApply), hasExec)
 
-	if len(toApply) == 0 && !hasExec {
+	if len(toApply) == 0 && len(toRemove) == 0 && !hasExec {
 		term.StopSpinner()
 		fmt.Println("🤷‍♂️ No changes to apply")
 		return
@@ -179,6 +204,35 @@ func MustApplyPlanAttempt(
 		term.OutputSimpleError(errMsg, unformattedErrMsg)
 	}
 
+	gitMirror, err := GetGitMirror(planId, branch)
+	if err != nil {
+		onErr("failed to get git mirror: %s", err)
+	}
+
+	if gitMirror != nil {
+		term.StopSpinner()
+		mustApplyToGitMirror(params, currentPlanState, toApply, toRemove, applyPaths, gitMirror)
+		return
+	}
+
+	var verifyConfig *shared.VerifyConfig
+	if !applyFlags.NoVerify {
+		verifyConfig, err = fs.LoadProjectVerifyConfig()
+		if err != nil {
+			onErr("failed to load verification config: %s", err)
+		}
+	}
+
+	if verifyConfig != nil {
+		shouldVerify, err := checkVerifyCommand(verifyConfig)
+		if err != nil {
+			onErr("%s", err)
+		}
+		if !shouldVerify {
+			verifyConfig = nil
+		}
+	}
+
 	log.Println("Has file changes:", hasFileChanges)
 
 	if hasFileChanges {
@@ -205,7 +259,7 @@ func MustApplyPlanAttempt(
 
 		log.Println("Applying plan files")
 
-		if hasExec {
+		if hasExec || verifyConfig != nil {
 			term.StopSpinner()
 			fmt.Println("🔄 Tentatively applying changes")
 			term.ResumeSpinner()
@@ -222,7 +276,7 @@ func MustApplyPlanAttempt(
 
 	onExecSuccess := func() {
 		term.StartSpinner("")
-		commitSummary, err := apiApplyPlan(planId, branch)
+		commitSummary, err := apiApplyPlan(planId, branch, applyPaths)
 
 		if err != nil {
 			onErr("apply plan server error: %s", err)
@@ -257,10 +311,23 @@ func MustApplyPlanAttempt(
 		}
 	}
 
+	// runs after _apply.sh succeeds, or right away if there's no script to run
+	onApplied := func() {
+		if verifyConfig != nil && toRollback != nil && toRollback.HasChanges() {
+			term.StopSpinner()
+			ok, failure := runVerifyCommand(verifyConfig, applyFlags.ExecSandbox)
+			if !ok {
+				onExecFail(failure, attempt, toRollback, onErr, onExecSuccess)
+				return
+			}
+		}
+		onExecSuccess()
+	}
+
 	if _, ok := toApply["_apply.sh"]; ok && !noExec {
-		handleApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onExecSuccess)
+		handleApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onApplied)
 	} else {
-		onExecSuccess()
+		onApplied()
 	}
 }
 
@@ -294,12 +361,57 @@ func handleApplyScript(
 
 	fmt.Println(strings.TrimSpace(md))
 
+	permissionErr := CheckExecPermission()
+	if permissionErr != nil && permissionErr != ErrExecNotPermitted {
+		onErr("failed to check exec permission: %s", permissionErr)
+	}
+
+	var denied, notAllowed []shared.ExecPolicyViolation
+	if permissionErr == nil {
+		policies, err := GetExecPolicies()
+		if err != nil {
+			onErr("failed to load exec policy: %s", err)
+		}
+
+		for _, violation := range shared.CheckExecPolicies(content, policies) {
+			if violation.Type == shared.ExecPolicyViolationDenied || violation.Type == shared.ExecPolicyViolationUnparseable {
+				denied = append(denied, violation)
+			} else {
+				notAllowed = append(notAllowed, violation)
+			}
+		}
+	}
+
 	log.Println("Asking user to confirm executing apply script")
 
 	var confirmed bool
-	if params.ApplyFlags.AutoExec {
+	if permissionErr == ErrExecNotPermitted {
+		fmt.Println()
+		color.New(term.ColorHiRed, color.Bold).Println("🚫 Commands blocked—your org role doesn't allow executing commands")
+		fmt.Println()
+	} else if len(denied) > 0 {
+		fmt.Println()
+		color.New(term.ColorHiRed, color.Bold).Println("🚫 Commands blocked by exec policy")
+		for _, violation := range denied {
+			if violation.Type == shared.ExecPolicyViolationUnparseable {
+				fmt.Printf("• %s → can't be checked against %s policy deny rules\n", violation.Command, strings.ToLower(violation.Source))
+			} else {
+				fmt.Printf("• %s → denied by %s policy (%s)\n", violation.Command, strings.ToLower(violation.Source), violation.Pattern)
+			}
+		}
+		fmt.Println()
+	} else if params.ApplyFlags.AutoExec && len(notAllowed) == 0 {
 		confirmed = true
 	} else {
+		if len(notAllowed) > 0 {
+			fmt.Println()
+			color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Commands not allowed by exec policy")
+			for _, violation := range notAllowed {
+				fmt.Printf("• %s → not in %s policy allowlist\n", violation.Command, strings.ToLower(violation.Source))
+			}
+			fmt.Println()
+		}
+
 		confirmed, err = term.ConfirmYesNo("Execute now?")
 		if err != nil {
 			onErr("failed to get confirmation user input: %s", err)
@@ -353,7 +465,15 @@ func execApplyScript(
 ) {
 	log.Println("Executing apply script")
 
+	executor, err := GetApplyScriptExecutor(params.ApplyFlags.ExecSandbox)
+	if err != nil {
+		onErr("failed to set up command execution: %s", err)
+	}
+
 	color.New(term.ColorHiYellow, color.Bold).Println("👉 For long-running commands, use ctrl+c to exit")
+	if desc := executor.Description(); desc != "" {
+		color.New(term.ColorHiCyan, color.Bold).Println(desc)
+	}
 	color.New(term.ColorHiCyan, color.Bold).Println("🚀 Executing... output below 👇")
 
 	fmt.Println()
@@ -403,15 +523,18 @@ func execApplyScript(
 
 	header := shebang + "\n" + errorHandling
 	content = header + "\n" + strings.Join(filteredLines, "\n")
-	err := os.WriteFile(scriptPath, []byte(content), 0755)
+	err = os.WriteFile(scriptPath, []byte(content), 0755)
 
 	if err != nil {
 		onErr("failed to write _apply.sh: %s", err)
 	}
 
-	execCmd := exec.Command(shell, "-c", scriptPath)
-	execCmd.Dir = fs.ProjectRoot
-	execCmd.Env = os.Environ()
+	execCmd, err := executor.Command(shell, fs.ProjectRoot, shellQuote(scriptPath))
+	if err != nil {
+		// best effort cleanup
+		os.Remove(scriptPath)
+		onErr("failed to create command: %s", err)
+	}
 	execCmd.Stdin = os.Stdin
 
 	// Create a pipe for both stdout and stderr
@@ -511,6 +634,17 @@ func execApplyScript(
 		}
 	}()
 
+	var timedOut atomic.Bool
+	if timeout := executor.Timeout(); timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			if err := KillProcessGroup(execCmd, syscall.SIGKILL); err != nil {
+				log.Printf("Failed to kill timed out process group: %v", err)
+			}
+		})
+		defer timer.Stop()
+	}
+
 	// Read and display output in real-time
 	scanner := bufio.NewScanner(pipe)
 	var outputBuilder strings.Builder
@@ -535,6 +669,11 @@ func execApplyScript(
 
 	success := err == nil
 
+	if timedOut.Load() {
+		fmt.Println()
+		color.New(term.ColorHiYellow, color.Bold).Printf("⏱️  Commands timed out after %s\n", executor.Timeout())
+	}
+
 	if interrupted.Load() {
 		os.Remove(scriptPath)
 
@@ -572,7 +711,7 @@ func execApplyScript(
 		if ok {
 			status = exitErr.ExitCode()
 		}
-		onExecFail(status, outputBuilder.String(), attempt, toRollback, onErr, onSuccess)
+		onExecFail(types.ApplyFailure{Status: status, Output: outputBuilder.String()}, attempt, toRollback, onErr, onSuccess)
 	} else {
 		fmt.Println()
 		fmt.Println("✅ Commands succeeded")
@@ -580,7 +719,7 @@ func execApplyScript(
 	}
 }
 
-func apiApplyPlan(planId, branch string) (string, error) {
+func apiApplyPlan(planId, branch string, paths []string) (string, error) {
 	log.Println("Getting API keys")
 
 	var apiKeys map[string]string
@@ -601,6 +740,7 @@ func apiApplyPlan(planId, branch string) (string, error) {
 		ApiKeys:     apiKeys,
 		OpenAIBase:  openAIBase,
 		OpenAIOrgId: os.Getenv("OPENAI_ORG_ID"),
+		Paths:       paths,
 	})
 
 	if apiErr != nil {
@@ -636,6 +776,220 @@ func commitApplied(autoCommit bool, commitSummary string, updatedFiles []string,
 	return nil
 }
 
+func mustApplyToGitMirror(params ApplyPlanParams, currentPlanState *shared.CurrentPlanState, toApply map[string]string, toRemove map[string]bool, applyPaths []string, mirror *types.GitMirror) {
+	log.Println("Applying plan to git mirror", mirror.GitBranch)
+
+	planId := params.PlanId
+	branch := params.Branch
+	gitBranch := color.New(color.Bold, term.ColorHiCyan).Sprint(mirror.GitBranch)
+
+	if _, ok := toApply["_apply.sh"]; ok && !params.ApplyFlags.NoExec {
+		fmt.Println("⚠️  Commands in _apply.sh aren't run when applying to a git mirror. Run them from the worktree if needed.")
+		fmt.Println()
+	}
+
+	if !params.ApplyFlags.AutoConfirm {
+		numToApply := len(toApply)
+		suffix := ""
+		if numToApply > 1 {
+			suffix = "s"
+		}
+		shouldContinue, err := term.ConfirmYesNo("Apply changes to %d file%s on git branch %s?", numToApply, suffix, gitBranch)
+
+		if err != nil {
+			term.OutputErrorAndExit("failed to get confirmation user input: %s", err)
+		}
+
+		if !shouldContinue {
+			os.Exit(0)
+		}
+	}
+
+	term.StartSpinner("")
+
+	updatedFiles, err := ApplyToGitMirror(mirror, toApply, toRemove)
+	if err != nil {
+		DiscardGitMirrorChanges(mirror)
+		term.StopSpinner()
+		term.OutputErrorAndExit("failed to apply files to git mirror: %s", err)
+	}
+
+	commitSummary, err := apiApplyPlan(planId, branch, applyPaths)
+	if err != nil {
+		DiscardGitMirrorChanges(mirror)
+		term.StopSpinner()
+		term.OutputErrorAndExit("apply plan server error: %s", err)
+	}
+
+	if len(updatedFiles) == 0 {
+		term.StopSpinner()
+		fmt.Printf("✅ Applied changes, but no files were updated on git branch %s\n", gitBranch)
+		return
+	}
+
+	// the apply id is recorded on the mirror commit so 'rewind' can reset the branch
+	var applyId string
+	appliedState, apiErr := api.Client.GetCurrentPlanState(planId, branch)
+	if apiErr != nil {
+		log.Printf("Error getting plan state after apply: %v", apiErr.Msg)
+	} else {
+		var latest *shared.PlanApply
+		for _, apply := range appliedState.PlanApplies {
+			if latest == nil || apply.CreatedAt.After(latest.CreatedAt) {
+				latest = apply
+			}
+		}
+		if latest != nil {
+			applyId = latest.Id
+		}
+	}
+
+	msg := sanitizeCommitSummary(currentPlanState.PendingChangesSummaryForApply(commitSummary))
+	gitErr := CommitGitMirror(mirror, msg, applyId)
+	term.StopSpinner()
+
+	suffix := ""
+	if len(updatedFiles) > 1 {
+		suffix = "s"
+	}
+	fmt.Printf("✅ Applied changes to git branch %s, %d file%s updated\n", gitBranch, len(updatedFiles), suffix)
+	for _, file := range updatedFiles {
+		fmt.Println(" • 📄 " + file)
+	}
+	fmt.Println()
+	fmt.Println("📂 Worktree: " + mirror.Worktree)
+
+	if gitErr != nil {
+		fmt.Println()
+		term.OutputSimpleError("Failed to commit changes to git mirror:", gitErr.Error())
+	}
+}
+
+// PathMatchesAny reports whether a project-relative path is one of paths or is inside one of them
+func PathMatchesAny(path string, paths []string) bool {
+	for _, p := range paths {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		if p == "." || path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPendingPaths narrows pending changes to the given paths. A path matches a pending file
+// exactly or as a parent directory. The _apply.sh script is never included in a partial apply,
+// so it stays pending until the rest of the plan is applied.
+func filterPendingPaths(toApply map[string]string, toRemove map[string]bool, paths []string) (map[string]string, map[string]bool, []string, error) {
+	matches := func(path string) bool {
+		return PathMatchesAny(path, paths)
+	}
+
+	filteredApply := map[string]string{}
+	filteredRemove := map[string]bool{}
+	var matched []string
+
+	for path, content := range toApply {
+		if path == "_apply.sh" || !matches(path) {
+			continue
+		}
+		filteredApply[path] = content
+		matched = append(matched, path)
+	}
+
+	for path, removed := range toRemove {
+		if !removed || !matches(path) {
+			continue
+		}
+		filteredRemove[path] = true
+		if _, ok := filteredApply[path]; !ok {
+			matched = append(matched, path)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, nil, nil, fmt.Errorf("no pending changes match %s", strings.Join(paths, ", "))
+	}
+
+	sort.Strings(matched)
+
+	return filteredApply, filteredRemove, matched, nil
+}
+
+// protectedConfigPaths returns the pending paths that would create, change, or remove a protected config file (see shared.ProtectedConfigFileNames), including through a symlink in the project
+func protectedConfigPaths(root string, toApply map[string]string, toRemove map[string]bool) []string {
+	var res []string
+	for path := range toApply {
+		if path != "_apply.sh" && isProtectedConfigTarget(root, path) {
+			res = append(res, path)
+		}
+	}
+	for path := range toRemove {
+		if isProtectedConfigTarget(root, path) {
+			res = append(res, path)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// symlinks followed when checking for a protected config file before giving up
+const maxProtectedConfigLinks = 8
+
+func isProtectedConfigTarget(root, path string) bool {
+	return isProtectedConfigTargetDepth(root, path, 0)
+}
+
+func isProtectedConfigTargetDepth(root, path string, depth int) bool {
+	if shared.IsProtectedConfigPath(path) {
+		return true
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+
+	// the file may not exist yet--resolve it or its nearest existing parent
+	existing := filepath.Join(root, path)
+	rest := ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return false
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		// a dangling link--writing through it creates its target
+		target, linkErr := os.Readlink(existing)
+		if linkErr != nil || depth >= maxProtectedConfigLinks {
+			return false
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(existing), target)
+		}
+		for _, base := range []string{root, realRoot} {
+			rel, err := filepath.Rel(base, filepath.Join(target, rest))
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return isProtectedConfigTargetDepth(root, rel, depth+1)
+			}
+		}
+		return false
+	}
+
+	rel, err := filepath.Rel(realRoot, filepath.Join(realExisting, rest))
+	if err != nil {
+		return false
+	}
+	return shared.IsProtectedConfigPath(rel)
+}
+
 func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPaths *types.ProjectPaths) ([]string, *types.ApplyRollbackPlan, error) {
 	var updatedFiles []string
 	var toRevert = map[string]types.ApplyReversion{}
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// ExecSandbox is the sandbox config for the current command, from the plan config and flags. Commands that run during a stream, like diagnostics, use it since they aren't passed apply flags.
+var ExecSandbox shared.ExecSandboxConfig
+
+// ApplyScriptExecutor builds the commands Plandex runs in a project: _apply.sh, the verification command, and diagnostics commands and language servers. execApplyScript starts the _apply.sh command, streams its output, and handles interrupts and timeouts.
+type ApplyScriptExecutor interface {
+	// Command runs a command line with 'shell -c' in dir, which is the project root except for diagnostics commands
+	Command(shell, dir, command string) (*exec.Cmd, error)
+
+	// Timeout is the wall-clock limit for the script—0 means no limit
+	Timeout() time.Duration
+
+	Description() string
+}
+
+func GetApplyScriptExecutor(config shared.ExecSandboxConfig) (ApplyScriptExecutor, error) {
+	switch config.Mode {
+	case "", shared.ExecSandboxModeNone:
+		return hostExecutor{}, nil
+	case shared.ExecSandboxModeSandbox:
+		return newSandboxExecutor(config)
+	}
+	return nil, fmt.Errorf("unknown exec sandbox mode: %s", config.Mode)
+}
+
+// hostExecutor runs the script directly with the user's shell and full environment
+type hostExecutor struct{}
+
+func (hostExecutor) Command(shell, dir, command string) (*exec.Cmd, error) {
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	return cmd, nil
+}
+
+func (hostExecutor) Timeout() time.Duration {
+	return 0
+}
+
+func (hostExecutor) Description() string {
+	return ""
+}
+
+// env vars that sandboxed commands always get, in addition to the configured allowlist
+var defaultSandboxEnv = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"LOGNAME",
+	"SHELL",
+	"TERM",
+	"LANG",
+	"LC_ALL",
+	"LC_CTYPE",
+	"TZ",
+}
+
+func sandboxEnv(config shared.ExecSandboxConfig) []string {
+	env := []string{"TMPDIR=/tmp"}
+	seen := map[string]bool{"TMPDIR": true}
+
+	for _, name := range append(append([]string{}, defaultSandboxEnv...), config.EnvAllowlist...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if val, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+val)
+		}
+	}
+
+	return env
+}
+
+// sandboxScriptCommand applies the CPU time limit with ulimit before running the command, so it's inherited by every command it starts. Memory is limited with a cgroup instead (see sandboxMemoryLimitArgs), since 'ulimit -v' caps virtual address space and breaks toolchains like Go, Node, and the JVM that reserve large amounts of it up front.
+func sandboxScriptCommand(config shared.ExecSandboxConfig, command string) string {
+	if config.CpuSeconds > 0 {
+		return fmt.Sprintf("ulimit -t %d || exit 1\n%s", config.CpuSeconds, command)
+	}
+	return command
+}
+
+// sandboxMemoryLimitArgs are the systemd-run args that run a command in a transient cgroup scope with the configured memory limit. Swap is disabled for the scope so the limit can't be exceeded by swapping.
+func sandboxMemoryLimitArgs(memoryMb int) []string {
+	return []string{
+		"--user",
+		"--scope",
+		"--quiet",
+		"--collect",
+		"-p", fmt.Sprintf("MemoryMax=%dM", memoryMb),
+		"-p", "MemorySwapMax=0",
+		"--",
+	}
+}
+
+func sandboxDescription(config shared.ExecSandboxConfig) string {
+	desc := []string{"read-only filesystem outside project"}
+	if config.DisableNetwork {
+		desc = append(desc, "no network")
+	}
+	if config.CpuSeconds > 0 {
+		desc = append(desc, fmt.Sprintf("%ds CPU", config.CpuSeconds))
+	}
+	if config.MemoryMb > 0 {
+		desc = append(desc, fmt.Sprintf("%dMB memory", config.MemoryMb))
+	}
+	if config.TimeoutSeconds > 0 {
+		desc = append(desc, fmt.Sprintf("%ds timeout", config.TimeoutSeconds))
+	}
+	return "🔒 Sandboxed → " + strings.Join(desc, ", ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
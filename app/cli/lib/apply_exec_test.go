@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"plandex-cli/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "''"},
+		{"/tmp/_apply.sh", "'/tmp/_apply.sh'"},
+		{"/my project/_apply.sh", "'/my project/_apply.sh'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf /)", "'$(rm -rf /)'"},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+
+		// the quoted string must come back unchanged through a real shell
+		out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(tt.in)).Output()
+		if err != nil {
+			t.Fatalf("sh: %v", err)
+		}
+		if string(out) != tt.in {
+			t.Errorf("round trip of %q gave %q", tt.in, out)
+		}
+	}
+}
+
+func TestSandboxScriptCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		config shared.ExecSandboxConfig
+		want   string
+	}{
+		{"no limits", shared.ExecSandboxConfig{}, "'/p/_apply.sh'"},
+		{"cpu", shared.ExecSandboxConfig{CpuSeconds: 30}, "ulimit -t 30 || exit 1\n'/p/_apply.sh'"},
+		{"memory isn't a ulimit", shared.ExecSandboxConfig{MemoryMb: 512}, "'/p/_apply.sh'"},
+	}
+
+	for _, tt := range tests {
+		if got := sandboxScriptCommand(tt.config, "'/p/_apply.sh'"); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSandboxMemoryLimitArgs(t *testing.T) {
+	got := sandboxMemoryLimitArgs(512)
+	want := []string{"--user", "--scope", "--quiet", "--collect", "-p", "MemoryMax=512M", "-p", "MemorySwapMax=0", "--"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("TMPDIR", "/var/tmp/host")
+	t.Setenv("NODE_ENV", "test")
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	os.Unsetenv("GOPATH")
+
+	env := sandboxEnv(shared.ExecSandboxConfig{EnvAllowlist: []string{"NODE_ENV", "GOPATH", "PATH"}})
+
+	byName := map[string]string{}
+	for _, kv := range env {
+		name, val, _ := strings.Cut(kv, "=")
+		if _, dup := byName[name]; dup {
+			t.Errorf("duplicate env var %s", name)
+		}
+		byName[name] = val
+	}
+
+	if byName["TMPDIR"] != "/tmp" {
+		t.Errorf("TMPDIR = %q, want the sandbox's private /tmp", byName["TMPDIR"])
+	}
+	if byName["PATH"] != "/usr/bin" || byName["HOME"] != "/home/dev" {
+		t.Errorf("expected default env vars to pass through: %v", env)
+	}
+	if byName["NODE_ENV"] != "test" {
+		t.Errorf("expected allowlisted NODE_ENV to pass through: %v", env)
+	}
+	if _, ok := byName["GOPATH"]; ok {
+		t.Errorf("unset allowlisted vars shouldn't be added: %v", env)
+	}
+	if _, ok := byName["SECRET_TOKEN"]; ok {
+		t.Errorf("vars outside the allowlist leaked into the sandbox: %v", env)
+	}
+}
+
+func TestGetApplyScriptExecutor(t *testing.T) {
+	fs.ProjectRoot = t.TempDir()
+
+	for _, mode := range []shared.ExecSandboxMode{"", shared.ExecSandboxModeNone} {
+		executor, err := GetApplyScriptExecutor(shared.ExecSandboxConfig{Mode: mode})
+		if err != nil {
+			t.Fatalf("mode %q: %v", mode, err)
+		}
+		if _, ok := executor.(hostExecutor); !ok {
+			t.Fatalf("mode %q: expected the host executor, got %T", mode, executor)
+		}
+
+		cmd, err := executor.Command("/bin/sh", fs.ProjectRoot, "'/p/_apply.sh'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cmd.Args, []string{"/bin/sh", "-c", "'/p/_apply.sh'"}) || cmd.Dir != fs.ProjectRoot {
+			t.Errorf("unexpected host command: %v in %s", cmd.Args, cmd.Dir)
+		}
+		if executor.Timeout() != 0 || executor.Description() != "" {
+			t.Errorf("host executor shouldn't have a timeout or description")
+		}
+	}
+
+	if _, err := GetApplyScriptExecutor(shared.ExecSandboxConfig{Mode: "docker"}); err == nil {
+		t.Errorf("expected an error for an unknown mode")
+	}
+
+	config := shared.ExecSandboxConfig{Mode: shared.ExecSandboxModeSandbox, DisableNetwork: true, TimeoutSeconds: 60}
+	executor, err := GetApplyScriptExecutor(config)
+	if err != nil {
+		// bubblewrap isn't installed everywhere--the error should say how to fix it
+		if !strings.Contains(err.Error(), "exec-sandbox") {
+			t.Errorf("unhelpful sandbox error: %v", err)
+		}
+		return
+	}
+
+	if executor.Timeout().Seconds() != 60 {
+		t.Errorf("timeout = %s", executor.Timeout())
+	}
+	if !strings.Contains(executor.Description(), "no network") {
+		t.Errorf("description = %q", executor.Description())
+	}
+
+	cmd, err := executor.Command("/bin/sh", fs.ProjectRoot, "'/p/_apply.sh'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--unshare-net") || !strings.Contains(args, "--bind "+fs.ProjectRoot+" "+fs.ProjectRoot) {
+		t.Errorf("unexpected sandbox args: %s", args)
+	}
+	if strings.Contains(args, "ulimit -v") {
+		t.Errorf("memory shouldn't be limited with ulimit -v: %s", args)
+	}
+}
@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestPathMatchesAny(t *testing.T) {
+	tests := []struct {
+		path  string
+		paths []string
+		want  bool
+	}{
+		{"src/main.go", []string{"src/main.go"}, true},
+		{"src/main.go", []string{"src"}, true},
+		{"src/main.go", []string{"src/"}, true},
+		{"src/main.go", []string{"."}, true},
+		{"src/main.go", []string{"sr"}, false},
+		{"src/main.go", []string{"src/main"}, false},
+		{"src2/main.go", []string{"src"}, false},
+		{"src/main.go", []string{"lib", "src/main.go"}, true},
+		{"src/main.go", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := PathMatchesAny(tt.path, tt.paths); got != tt.want {
+			t.Errorf("PathMatchesAny(%q, %q) = %v, want %v", tt.path, tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestFilterPendingPaths(t *testing.T) {
+	toApply := map[string]string{
+		"_apply.sh":       "npm test",
+		"src/main.go":     "main",
+		"src/util/fmt.go": "fmt",
+		"README.md":       "readme",
+	}
+	toRemove := map[string]bool{
+		"src/old.go": true,
+		"docs/x.md":  false,
+	}
+
+	filteredApply, filteredRemove, matched, err := filterPendingPaths(toApply, toRemove, []string{"src"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantApply := map[string]string{"src/main.go": "main", "src/util/fmt.go": "fmt"}
+	if !reflect.DeepEqual(filteredApply, wantApply) {
+		t.Errorf("toApply = %v, want %v", filteredApply, wantApply)
+	}
+	if !reflect.DeepEqual(filteredRemove, map[string]bool{"src/old.go": true}) {
+		t.Errorf("toRemove = %v", filteredRemove)
+	}
+	if !reflect.DeepEqual(matched, []string{"src/main.go", "src/old.go", "src/util/fmt.go"}) {
+		t.Errorf("matched = %v", matched)
+	}
+
+	// the apply script is never part of a partial apply
+	filteredApply, _, _, err = filterPendingPaths(toApply, toRemove, []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := filteredApply["_apply.sh"]; ok {
+		t.Errorf("_apply.sh shouldn't be included in a partial apply")
+	}
+
+	for _, paths := range [][]string{{"docs/x.md"}, {"_apply.sh"}, {"missing"}} {
+		if _, _, _, err := filterPendingPaths(toApply, toRemove, paths); err == nil {
+			t.Errorf("expected an error for %v", paths)
+		}
+	}
+}
+
+func TestProtectedConfigPaths(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// a link back to the project root, and one to the config file itself
+	if err := os.Symlink(root, filepath.Join(root, "src", "up")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, shared.DiagnosticsConfigFileName), filepath.Join(root, "config.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	toApply := map[string]string{
+		"_apply.sh":                      "echo hi",
+		"src/main.go":                    "package main",
+		shared.DiagnosticsConfigFileName: "{}",
+		"src/up/" + shared.DiagnosticsConfigFileName: "{}",
+		"config.json": "{}",
+	}
+	toRemove := map[string]bool{
+		"./" + shared.DiagnosticsConfigFileName: true,
+		"src/old.go":                            true,
+	}
+
+	got := protectedConfigPaths(root, toApply, toRemove)
+	want := []string{
+		"./" + shared.DiagnosticsConfigFileName,
+		shared.DiagnosticsConfigFileName,
+		"config.json",
+		"src/up/" + shared.DiagnosticsConfigFileName,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if got := protectedConfigPaths(root, map[string]string{"src/main.go": ""}, nil); len(got) != 0 {
+		t.Fatalf("expected no protected paths, got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build linux
+// +build linux
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// sandboxExecutor runs commands under bubblewrap in new mount, pid, ipc, and uts namespaces (plus a network namespace if network is disabled). The whole filesystem is mounted read-only except the command's dir (the project root for _apply.sh) and a private /tmp—within dir, .git and the protected config files stay read-only too. With a memory limit, bubblewrap itself runs in a systemd scope so the limit is enforced by a cgroup.
+type sandboxExecutor struct {
+	config     shared.ExecSandboxConfig
+	bwrap      string
+	systemdRun string
+	env        string
+}
+
+func newSandboxExecutor(config shared.ExecSandboxConfig) (ApplyScriptExecutor, error) {
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return nil, fmt.Errorf("sandboxed execution requires bubblewrap—install the 'bubblewrap' package or set exec-sandbox to 'none': %v", err)
+	}
+
+	executor := sandboxExecutor{config: config, bwrap: bwrap}
+
+	if config.MemoryMb > 0 {
+		systemdRun, err := exec.LookPath("systemd-run")
+		if err != nil {
+			return nil, fmt.Errorf("a sandbox memory limit is enforced with a cgroup and requires systemd-run—set exec-memory-limit to 0 to run without one: %v", err)
+		}
+		executor.systemdRun = systemdRun
+
+		env, err := exec.LookPath("env")
+		if err != nil {
+			return nil, fmt.Errorf("a sandbox memory limit requires 'env' to clear the environment inside the systemd scope: %v", err)
+		}
+		executor.env = env
+	}
+
+	return executor, nil
+}
+
+func (e sandboxExecutor) Command(shell, dir, command string) (*exec.Cmd, error) {
+	args := sandboxBwrapArgs(e.config, dir)
+	args = append(args, "--", shell, "-c", sandboxScriptCommand(e.config, command))
+
+	var cmd *exec.Cmd
+	if e.systemdRun != "" {
+		// systemd-run needs the caller's session bus to create the scope, so it gets those vars too—'env -i' then clears them so bubblewrap only gets the sandbox env
+		scopeArgs := append(sandboxMemoryLimitArgs(e.config.MemoryMb), e.env, "-i")
+		scopeArgs = append(scopeArgs, sandboxEnv(e.config)...)
+		scopeArgs = append(scopeArgs, e.bwrap)
+		cmd = exec.Command(e.systemdRun, append(scopeArgs, args...)...)
+		cmd.Env = append(sandboxEnv(e.config), systemdRunEnv()...)
+	} else {
+		cmd = exec.Command(e.bwrap, args...)
+		cmd.Env = sandboxEnv(e.config)
+	}
+	cmd.Dir = dir
+	return cmd, nil
+}
+
+// sandboxBwrapArgs are the bubblewrap args up to the command. dir is bound after the private /tmp so a diagnostics workspace under the host's /tmp stays visible, then .git and any protected config files in it are bound read-only over it so commands can't rewrite history or give the next plan new commands to run.
+func sandboxBwrapArgs(config shared.ExecSandboxConfig, dir string) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--bind", dir, dir,
+	}
+
+	for _, name := range append([]string{".git"}, shared.ProtectedConfigFileNames...) {
+		path := filepath.Join(dir, name)
+		if _, err := os.Lstat(path); err == nil {
+			args = append(args, "--ro-bind", path, path)
+		}
+	}
+
+	args = append(args,
+		"--chdir", dir,
+		"--unshare-pid",
+		"--unshare-ipc",
+		"--unshare-uts",
+		"--die-with-parent",
+	)
+
+	if config.DisableNetwork {
+		args = append(args, "--unshare-net")
+	}
+
+	return args
+}
+
+// systemdRunEnv is what systemd-run needs from the caller's environment to reach the user's service manager
+func systemdRunEnv() []string {
+	var env []string
+	for _, name := range []string{"XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS"} {
+		if val, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+val)
+		}
+	}
+	return env
+}
+
+func (e sandboxExecutor) Timeout() time.Duration {
+	return time.Duration(e.config.TimeoutSeconds) * time.Second
+}
+
+func (e sandboxExecutor) Description() string {
+	return sandboxDescription(e.config)
+}
@@ -0,0 +1,162 @@
+//go:build linux
+// +build linux
+
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestSandboxCommandWithMemoryLimit(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/run/user/1000/bus")
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	for _, name := range []string{"USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "LC_CTYPE", "TZ"} {
+		os.Unsetenv(name)
+	}
+
+	config := shared.ExecSandboxConfig{Mode: shared.ExecSandboxModeSandbox, MemoryMb: 512}
+	executor := sandboxExecutor{config: config, bwrap: "/usr/bin/bwrap", systemdRun: "/usr/bin/systemd-run", env: "/usr/bin/env"}
+
+	dir := t.TempDir()
+	cmd, err := executor.Command("/bin/sh", dir, "'/p/_apply.sh'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantArgs := []string{"/usr/bin/systemd-run"}
+	wantArgs = append(wantArgs, sandboxMemoryLimitArgs(512)...)
+	wantArgs = append(wantArgs, "/usr/bin/env", "-i", "TMPDIR=/tmp", "PATH=/usr/bin", "HOME=/home/dev", "/usr/bin/bwrap")
+	wantArgs = append(wantArgs, sandboxBwrapArgs(config, dir)...)
+	wantArgs = append(wantArgs, "--", "/bin/sh", "-c", "'/p/_apply.sh'")
+
+	if cmd.Path != "/usr/bin/systemd-run" {
+		t.Errorf("path = %s", cmd.Path)
+	}
+	if !reflect.DeepEqual(cmd.Args, wantArgs) {
+		t.Errorf("args = %q\nwant %q", cmd.Args, wantArgs)
+	}
+
+	// systemd-run gets the session bus; bubblewrap only gets the sandbox env through 'env -i'
+	wantEnv := []string{"TMPDIR=/tmp", "PATH=/usr/bin", "HOME=/home/dev", "XDG_RUNTIME_DIR=/run/user/1000", "DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/1000/bus"}
+	if !reflect.DeepEqual(cmd.Env, wantEnv) {
+		t.Errorf("env = %q\nwant %q", cmd.Env, wantEnv)
+	}
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "hunter2") {
+			t.Errorf("vars outside the allowlist leaked into the sandbox: %q", cmd.Args)
+		}
+	}
+
+	// without a memory limit, bubblewrap runs directly with the sandbox env
+	executor = sandboxExecutor{config: shared.ExecSandboxConfig{Mode: shared.ExecSandboxModeSandbox}, bwrap: "/usr/bin/bwrap"}
+	cmd, err = executor.Command("/bin/sh", dir, "'/p/_apply.sh'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.Path != "/usr/bin/bwrap" {
+		t.Errorf("path = %s", cmd.Path)
+	}
+	if !reflect.DeepEqual(cmd.Env, []string{"TMPDIR=/tmp", "PATH=/usr/bin", "HOME=/home/dev"}) {
+		t.Errorf("env = %q", cmd.Env)
+	}
+}
+
+func TestSandboxBwrapArgsProtectedPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, shared.VerifyConfigFileName), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := sandboxBwrapArgs(shared.ExecSandboxConfig{}, dir)
+	joined := strings.Join(args, " ")
+
+	bind := "--bind " + dir + " " + dir
+	bindIdx := strings.Index(joined, bind)
+	if bindIdx == -1 {
+		t.Fatalf("expected %q in %q", bind, joined)
+	}
+
+	for _, name := range []string{".git", shared.VerifyConfigFileName} {
+		path := filepath.Join(dir, name)
+		roBind := "--ro-bind " + path + " " + path
+		idx := strings.Index(joined, roBind)
+		if idx == -1 {
+			t.Errorf("expected %q in %q", roBind, joined)
+		} else if idx < bindIdx {
+			t.Errorf("%s must be bound read-only after the project dir", name)
+		}
+	}
+
+	// files that don't exist can't be bound
+	for _, name := range []string{shared.ExecPolicyFileName, shared.DiagnosticsConfigFileName} {
+		if strings.Contains(joined, filepath.Join(dir, name)) {
+			t.Errorf("unexpected bind for missing %s in %q", name, joined)
+		}
+	}
+}
+
+func TestSandboxProtectedPathsReadOnly(t *testing.T) {
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		t.Skip("bwrap isn't installed")
+	}
+	if out, err := exec.Command(bwrap, "--ro-bind", "/", "/", "true").CombinedOutput(); err != nil {
+		t.Skipf("bwrap can't create a sandbox here: %v: %s", err, out)
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	protected := append([]string{filepath.Join(".git", "config")}, shared.ProtectedConfigFileNames...)
+	for _, name := range protected {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("original"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	executor := sandboxExecutor{config: shared.ExecSandboxConfig{Mode: shared.ExecSandboxModeSandbox}, bwrap: bwrap}
+
+	run := func(command string) error {
+		cmd, err := executor.Command("/bin/sh", dir, command)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cmd.Run()
+	}
+
+	if err := run("echo changed > main.go"); err != nil {
+		t.Fatalf("expected the project dir to be writable: %v", err)
+	}
+
+	for _, name := range protected {
+		if err := run("echo changed > " + shellQuote(name)); err == nil {
+			t.Errorf("expected writing %s to fail", name)
+		}
+		if err := run("rm -f " + shellQuote(name)); err == nil {
+			t.Errorf("expected removing %s to fail", name)
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(content) != "original" {
+			t.Errorf("%s changed: %q, %v", name, content, err)
+		}
+	}
+
+	if err := run("mkdir .git/hooks"); err == nil {
+		t.Errorf("expected writing inside .git to fail")
+	}
+}
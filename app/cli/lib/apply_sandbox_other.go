@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package lib
+
+import (
+	"fmt"
+	"runtime"
+
+	shared "plandex-shared"
+)
+
+func newSandboxExecutor(config shared.ExecSandboxConfig) (ApplyScriptExecutor, error) {
+	return nil, fmt.Errorf("sandboxed execution isn't supported on %s yet—set exec-sandbox to 'none' to run commands directly", runtime.GOOS)
+}
@@ -0,0 +1,189 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const defaultVerifyTimeout = 10 * time.Minute
+
+// checkVerifyCommand checks the verification command against the user's role and the exec policies before any changes are applied. A command the role doesn't allow or a policy denies is an error. A command that isn't in an allowlist runs only if the user confirms it--otherwise verification is skipped and it returns false.
+func checkVerifyCommand(config *shared.VerifyConfig) (bool, error) {
+	err := CheckExecPermission()
+	if err == ErrExecNotPermitted {
+		return false, fmt.Errorf("verification command '%s' can't run—%v. Apply with --no-verify", config.Command, err)
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check exec permission: %v", err)
+	}
+
+	policies, err := GetExecPolicies()
+	if err != nil {
+		return false, fmt.Errorf("failed to load exec policy: %v", err)
+	}
+
+	violations := shared.CheckExecPolicies(config.Command, policies)
+	if len(violations) == 0 {
+		return true, nil
+	}
+
+	var notAllowed []shared.ExecPolicyViolation
+	for _, violation := range violations {
+		switch violation.Type {
+		case shared.ExecPolicyViolationDenied:
+			return false, fmt.Errorf("verification command '%s' is denied by %s policy (%s)—change %s or apply with --no-verify", violation.Command, strings.ToLower(violation.Source), violation.Pattern, shared.VerifyConfigFileName)
+		case shared.ExecPolicyViolationUnparseable:
+			return false, fmt.Errorf("verification command '%s' can't be checked against %s policy deny rules—change %s or apply with --no-verify", violation.Command, strings.ToLower(violation.Source), shared.VerifyConfigFileName)
+		}
+		notAllowed = append(notAllowed, violation)
+	}
+
+	term.StopSpinner()
+	fmt.Println()
+	color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Verification command not allowed by exec policy")
+	for _, violation := range notAllowed {
+		fmt.Printf("• %s → not in %s policy allowlist\n", violation.Command, strings.ToLower(violation.Source))
+	}
+	fmt.Println()
+
+	confirmed, err := term.ConfirmYesNo("Run verification command '%s' anyway?", config.Command)
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmation user input: %v", err)
+	}
+	if !confirmed {
+		fmt.Println("🙅‍♂️ Skipping verification")
+	}
+	return confirmed, nil
+}
+
+// runVerifyCommand runs the project's verification command on the applied changes with the same executor as _apply.sh and streams its output. It returns false with the failure if the command exits non-zero, times out, or is interrupted.
+func runVerifyCommand(config *shared.VerifyConfig, sandbox shared.ExecSandboxConfig) (bool, types.ApplyFailure) {
+	failure := types.ApplyFailure{
+		Status:        -1,
+		VerifyCommand: config.Command,
+	}
+
+	executor, err := GetApplyScriptExecutor(sandbox)
+	if err != nil {
+		failure.Output = fmt.Sprintf("failed to set up command execution: %v", err)
+		return false, failure
+	}
+
+	timeout := defaultVerifyTimeout
+	if config.TimeoutSeconds > 0 {
+		timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+	if executor.Timeout() > 0 && executor.Timeout() < timeout {
+		timeout = executor.Timeout()
+	}
+
+	fmt.Println()
+	color.New(term.ColorHiCyan, color.Bold).Printf("🧪 Verifying changes with '%s'... output below 👇\n", config.Command)
+	if desc := executor.Description(); desc != "" {
+		color.New(term.ColorHiCyan, color.Bold).Println(desc)
+	}
+	fmt.Println()
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/bash"
+	}
+
+	cmd, err := executor.Command(shell, fs.ProjectRoot, config.Command)
+	if err != nil {
+		failure.Output = fmt.Sprintf("failed to create verification command: %v", err)
+		return false, failure
+	}
+	SetPlatformSpecificAttrs(cmd)
+
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		failure.Output = fmt.Sprintf("failed to create stdout pipe: %v", err)
+		return false, failure
+	}
+	cmd.Stderr = cmd.Stdout
+
+	err = cmd.Start()
+	if err != nil {
+		failure.Output = fmt.Sprintf("failed to start verification command: %v", err)
+		return false, failure
+	}
+
+	var interrupted, timedOut atomic.Bool
+
+	// the command runs in its own process group, so ctrl+c only reaches it through here--the changes still get rolled back or kept afterward
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-sigChan:
+			interrupted.Store(true)
+			if err := KillProcessGroup(cmd, syscall.SIGKILL); err != nil {
+				log.Printf("Failed to kill verification command: %v", err)
+			}
+		case <-done:
+		}
+	}()
+
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		if err := KillProcessGroup(cmd, syscall.SIGKILL); err != nil {
+			log.Printf("Failed to kill timed out verification command: %v", err)
+		}
+	})
+
+	scanner := bufio.NewScanner(pipe)
+	var outputBuilder strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Println(line)
+		outputBuilder.WriteString(line + "\n")
+	}
+
+	if scanErr := scanner.Err(); scanErr != nil {
+		log.Printf("⚠️ Scanner error reading verification output: %v", scanErr)
+	}
+
+	err = cmd.Wait()
+
+	timer.Stop()
+	close(done)
+	signal.Stop(sigChan)
+
+	failure.Output = outputBuilder.String()
+
+	if timedOut.Load() {
+		failure.Output += fmt.Sprintf("\nTimed out after %s", timeout)
+	} else if interrupted.Load() {
+		failure.Output += "\nInterrupted"
+	} else if err == nil {
+		fmt.Println()
+		fmt.Println("✅ Verification succeeded")
+		return true, types.ApplyFailure{}
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && !timedOut.Load() && !interrupted.Load() {
+		failure.Status = exitErr.ExitCode()
+	}
+
+	fmt.Println()
+	color.New(term.ColorHiRed, color.Bold).Println("🚨 Verification failed")
+
+	return false, failure
+}
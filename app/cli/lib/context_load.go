@@ -96,6 +96,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 	var inputUrls []string
 	var inputFilePaths []string
+	symbolsByPath := map[string][]string{}
 
 	if len(resources) > 0 {
 		for _, resource := range resources {
@@ -107,6 +108,26 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					resource = resource[2:]
 				}
 
+				path, symbol := splitSymbolInput(resource)
+				if symbol != "" || len(params.Symbols) > 0 {
+					if params.DefsOnly || params.NamesOnly {
+						onErr(fmt.Errorf("symbols can't be loaded with --map or --tree"))
+					}
+					if fileInfo, err := os.Stat(path); err != nil || fileInfo.IsDir() {
+						onErr(fmt.Errorf("symbols can only be loaded from files: %s", path))
+					}
+					if !shared.HasFileMapSupport(path) {
+						onErr(fmt.Errorf("symbols can't be loaded from %s since its language isn't supported for maps--load the whole file instead", path))
+					}
+
+					if symbol != "" {
+						symbolsByPath[path] = append(symbolsByPath[path], symbol)
+					} else {
+						symbolsByPath[path] = append(symbolsByPath[path], params.Symbols...)
+					}
+					continue
+				}
+
 				inputFilePaths = append(inputFilePaths, resource)
 			}
 		}
@@ -142,6 +163,8 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.FilePath}, "|")] = context
 		case shared.ContextURLType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.Url}, "|")] = context
+		case shared.ContextSymbolType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Name}, "|")] = context
 		}
 	}
 
@@ -622,6 +645,33 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	var symbolsNotFound []string
+	if len(symbolsByPath) > 0 {
+		res, err := getSymbolContexts(symbolsByPath, params, existsByComposite)
+		if err != nil {
+			onErr(err)
+		}
+
+		for composite, context := range res.alreadyLoaded {
+			alreadyLoadedByComposite[composite] = context
+		}
+		for path, reason := range res.ignoredPaths {
+			ignoredPaths[path] = reason
+		}
+		filesSkippedTooLarge = append(filesSkippedTooLarge, res.skippedTooLarge...)
+		symbolsNotFound = res.notFound
+
+		for _, loadParams := range res.loadParams {
+			size := int64(len(loadParams.Body))
+			if totalSize+size > shared.MaxContextBodySize {
+				filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, loadParams.Name)
+				continue
+			}
+			totalSize += size
+			loadContextReq = append(loadContextReq, loadParams)
+		}
+	}
+
 	if params.DefsOnly {
 		allMapBodies, err := processMapBatches(mapInputBatches)
 		if err != nil {
@@ -694,6 +744,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			printIgnoredMsg()
 			didOutputReason = true
 		}
+		if len(symbolsNotFound) > 0 {
+			printSymbolsNotFoundMsg(symbolsNotFound)
+			didOutputReason = true
+		}
 
 		if !didOutputReason {
 			fmt.Println()
@@ -709,6 +763,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			fmt.Println()
 			fmt.Printf("%s with the --tree flag:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load a directory layout (file names only)"))
 
+			fmt.Println()
+			fmt.Printf("%s with path#Symbol or the --symbol flag:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load a single function, type, or class"))
+			fmt.Println("plandex load lib/server.go#HandleRequest")
+
 			fmt.Println()
 			fmt.Printf("%s file paths are relative to the current directory\n", color.New(color.Bold, term.ColorHiYellow).Sprint("Note:"))
 
@@ -755,6 +813,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		printIgnoredMsg()
 	}
 
+	if len(symbolsNotFound) > 0 {
+		printSymbolsNotFoundMsg(symbolsNotFound)
+	}
+
 	if len(filesSkippedTooLarge) > 0 || len(filesSkippedAfterSizeLimit) > 0 ||
 		len(mapFilesTruncatedTooLarge) > 0 || len(mapFilesSkippedAfterSizeLimit) > 0 {
 		printSkippedFilesMsg(filesSkippedTooLarge, filesSkippedAfterSizeLimit,
@@ -150,6 +150,7 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 		go func(batch shared.FileMapInputs) {
 			mapRes, apiErr := api.Client.GetFileMap(shared.GetFileMapRequest{
 				MapInputs: batch,
+				PlanId:    CurrentPlanId,
 			})
 			if apiErr != nil {
 				errCh <- fmt.Errorf("failed to get file map: %v", apiErr)
@@ -0,0 +1,175 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+type symbolContextsResult struct {
+	loadParams      []*shared.LoadContextParams
+	alreadyLoaded   map[string]*shared.Context
+	ignoredPaths    map[string]string
+	notFound        []string
+	skippedTooLarge []filePathWithSize
+}
+
+// splitSymbolInput splits a 'path#Symbol' load argument into its path and symbol. Files that exist with a '#' in their name are loaded whole.
+func splitSymbolInput(resource string) (string, string) {
+	idx := strings.LastIndex(resource, "#")
+	if idx <= 0 || idx == len(resource)-1 {
+		return resource, ""
+	}
+	if _, err := os.Stat(resource); err == nil {
+		return resource, ""
+	}
+	return resource[:idx], resource[idx+1:]
+}
+
+func symbolContextName(path, symbol string) string {
+	return path + "#" + symbol
+}
+
+func getSymbolContexts(symbolsByPath map[string][]string, params *types.LoadContextParams, existsByComposite map[string]*shared.Context) (*symbolContextsResult, error) {
+	res := &symbolContextsResult{
+		alreadyLoaded: map[string]*shared.Context{},
+		ignoredPaths:  map[string]string{},
+	}
+
+	var paths []string
+	for path := range symbolsByPath {
+		paths = append(paths, path)
+	}
+
+	baseDir := fs.GetBaseDirForFilePaths(paths)
+	projectPaths, err := fs.GetProjectPaths(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project paths: %v", err)
+	}
+
+	req := shared.GetFileSymbolsRequest{
+		Inputs: map[string]*shared.FileSymbolsInput{},
+	}
+
+	for _, path := range paths {
+		if !params.ForceSkipIgnore {
+			if _, ok := projectPaths.ActivePaths[path]; !ok {
+				ignored, reason, err := fs.IsIgnored(projectPaths, path, baseDir)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check if %s is ignored: %v", path, err)
+				}
+				if ignored {
+					res.ignoredPaths[path] = reason
+				}
+				continue
+			}
+		}
+
+		var toFind []string
+		seen := map[string]bool{}
+		for _, symbol := range symbolsByPath[path] {
+			if seen[symbol] {
+				continue
+			}
+			seen[symbol] = true
+
+			composite := strings.Join([]string{string(shared.ContextSymbolType), symbolContextName(path, symbol)}, "|")
+			if existing := existsByComposite[composite]; existing != nil {
+				res.alreadyLoaded[composite] = existing
+				continue
+			}
+			toFind = append(toFind, symbol)
+		}
+
+		if len(toFind) == 0 {
+			continue
+		}
+
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info for %s: %v", path, err)
+		}
+		if fileInfo.Size() > shared.MaxContextBodySize {
+			res.skippedTooLarge = append(res.skippedTooLarge, filePathWithSize{Path: path, Size: fileInfo.Size()})
+			continue
+		}
+
+		fileContent, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file %s: %v", path, err)
+		}
+
+		req.Inputs[path] = &shared.FileSymbolsInput{
+			Content: string(shared.NormalizeEOL(fileContent)),
+			Symbols: toFind,
+		}
+	}
+
+	if len(req.Inputs) == 0 {
+		return res, nil
+	}
+
+	symbolsRes, apiErr := api.Client.GetFileSymbols(req)
+	if apiErr != nil {
+		return nil, fmt.Errorf("failed to find symbols: %v", apiErr.Msg)
+	}
+
+	for path, input := range req.Inputs {
+		for _, symbol := range input.Symbols {
+			name := symbolContextName(path, symbol)
+
+			body, ok := symbolsRes.Bodies[path][symbol]
+			if !ok {
+				res.notFound = append(res.notFound, name)
+				continue
+			}
+
+			res.loadParams = append(res.loadParams, &shared.LoadContextParams{
+				ContextType: shared.ContextSymbolType,
+				Name:        name,
+				FilePath:    path,
+				Symbol:      symbol,
+				Body:        body,
+				AutoLoaded:  params.AutoLoaded,
+			})
+		}
+	}
+
+	sort.Strings(res.notFound)
+
+	return res, nil
+}
+
+// getSymbolBody returns the current source of a symbol context's definition, or false if the symbol is no longer defined in its file
+func getSymbolBody(path, symbol string, fileContent []byte) (string, bool, error) {
+	res, apiErr := api.Client.GetFileSymbols(shared.GetFileSymbolsRequest{
+		Inputs: map[string]*shared.FileSymbolsInput{
+			path: {
+				Content: string(fileContent),
+				Symbols: []string{symbol},
+			},
+		},
+	})
+	if apiErr != nil {
+		return "", false, fmt.Errorf("failed to find symbol %s in %s: %v", symbol, path, apiErr.Msg)
+	}
+
+	body, ok := res.Bodies[path][symbol]
+	return body, ok, nil
+}
+
+func printSymbolsNotFoundMsg(notFound []string) {
+	fmt.Println()
+	fmt.Println("🔎 " + color.New(color.FgWhite).Sprint("These symbols weren't found, so they weren't loaded:"))
+	for _, name := range notFound {
+		fmt.Printf("  • %s\n", name)
+	}
+}
@@ -89,6 +89,14 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumMaps) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumSymbols > 0 {
+			lbl := "symbol"
+			if outdatedRes.NumSymbols > 1 {
+				lbl = "symbols"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumSymbols) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -136,6 +144,14 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumTreesRemoved) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumSymbolsRemoved > 0 {
+			lbl := "symbol"
+			if outdatedRes.NumSymbolsRemoved > 1 {
+				lbl = "symbols"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumSymbolsRemoved) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -330,8 +346,10 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 	var numUrls int
 	var numTrees int
 	var numMaps int
+	var numSymbols int
 	var numFilesRemoved int
 	var numTreesRemoved int
+	var numSymbolsRemoved int
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	contextsById := make(map[string]*shared.Context)
@@ -474,6 +492,101 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				}
 			}(context)
 
+		case shared.ContextSymbolType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				remove := func() {
+					mu.Lock()
+					defer mu.Unlock()
+
+					deleteIds[ctx.Id] = true
+					numSymbolsRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+				}
+
+				fileInfo, err := os.Stat(ctx.FilePath)
+				if os.IsNotExist(err) {
+					remove()
+					return
+				} else if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to get file info for %s: %v", ctx.FilePath, err))
+					return
+				}
+
+				if fileInfo.Size() > shared.MaxContextBodySize {
+					mu.Lock()
+					defer mu.Unlock()
+
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.FilePath, Size: fileInfo.Size()})
+					return
+				}
+
+				fileContent, err := os.ReadFile(ctx.FilePath)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to read the file %s: %v", ctx.FilePath, err))
+					return
+				}
+				fileContent = shared.NormalizeEOL(fileContent)
+
+				// only the symbol's own source is tracked, so changes elsewhere in the file don't trigger an update
+				body, found, err := getSymbolBody(ctx.FilePath, ctx.Symbol, fileContent)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, err)
+					return
+				}
+
+				// renamed or deleted
+				if !found {
+					remove()
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha != ctx.Sha {
+					size := int64(len(body))
+
+					mu.Lock()
+					defer mu.Unlock()
+
+					if totalContextCount >= shared.MaxContextCount {
+						filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+						return
+					}
+
+					oldBodySize := int64(len(ctx.Body))
+					if totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+						filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+						return
+					}
+
+					totalSize += size
+					totalContextCount++
+					totalBodySize += (size - oldBodySize)
+
+					tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+					numSymbols++
+					updatedContexts = append(updatedContexts, ctx)
+
+					reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+						return &shared.UpdateContextParams{
+							Body: body,
+						}, nil
+					}
+				}
+			}(context)
+
 		case shared.ContextDirectoryTreeType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -962,16 +1075,18 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 	// Build final result
 	outdatedRes := types.ContextOutdatedResult{
-		UpdatedContexts: updatedContexts,
-		RemovedContexts: removedContexts,
-		TokenDiffsById:  tokenDiffsById,
-		NumFiles:        numFiles,
-		NumUrls:         numUrls,
-		NumTrees:        numTrees,
-		NumMaps:         numMaps,
-		NumFilesRemoved: numFilesRemoved,
-		NumTreesRemoved: numTreesRemoved,
-		ReqFn:           reqFn,
+		UpdatedContexts:   updatedContexts,
+		RemovedContexts:   removedContexts,
+		TokenDiffsById:    tokenDiffsById,
+		NumFiles:          numFiles,
+		NumUrls:           numUrls,
+		NumTrees:          numTrees,
+		NumMaps:           numMaps,
+		NumSymbols:        numSymbols,
+		NumFilesRemoved:   numFilesRemoved,
+		NumTreesRemoved:   numTreesRemoved,
+		NumSymbolsRemoved: numSymbolsRemoved,
+		ReqFn:             reqFn,
 	}
 
 	var hasConflicts bool
@@ -999,6 +1114,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 			NumTrees:    numTrees,
 			NumUrls:     numUrls,
 			NumMaps:     numMaps,
+			NumSymbols:  numSymbols,
 			TokensDiff:  tokensDiff,
 			TotalTokens: newTotal,
 		})
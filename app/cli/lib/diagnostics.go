@@ -0,0 +1,519 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const defaultDiagnosticsTimeout = 30 * time.Second
+
+// errors beyond this many are left out of the response so the fix prompt stays small
+const maxDiagnosticsErrors = 20
+
+var (
+	diagnosticsMu           sync.Mutex
+	diagnosticsConfig       *shared.DiagnosticsConfig
+	diagnosticsConfigLoaded bool
+	diagnosticsExecutor     ApplyScriptExecutor
+
+	// commands left out of the config because of the exec policy, shown once before the stream starts
+	diagnosticsSkipped []string
+
+	// started on the first check of a matching file, by index in the config
+	languageServers = map[int]*lspClient{}
+)
+
+var positionPattern = regexp.MustCompile(`(?i):\d+(:\d+)?|\bline \d+|\bcol(umn)? \d+|\(\d+,\d+\)`)
+
+// diagnostic is a single error from a language server or a compiler command. key leaves out the error's position so errors that were already in the original file aren't counted as new when lines shift.
+type diagnostic struct {
+	line    int
+	message string
+	key     string
+}
+
+func (d diagnostic) String() string {
+	if d.line > 0 {
+		return fmt.Sprintf("line %d: %s", d.line, d.message)
+	}
+	return d.message
+}
+
+// GetDiagnosticsExts loads the project's diagnostics config, if there is one, and returns the extensions it can check. Commands blocked by the user's role or the exec policy are left out with a warning.
+func GetDiagnosticsExts() ([]string, error) {
+	config, err := getDiagnosticsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosticsMu.Lock()
+	skipped := diagnosticsSkipped
+	diagnosticsSkipped = nil
+	diagnosticsMu.Unlock()
+
+	if len(skipped) > 0 {
+		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Diagnostics commands blocked")
+		for _, msg := range skipped {
+			fmt.Println("• " + msg)
+		}
+		fmt.Println()
+	}
+
+	if config == nil {
+		return nil, nil
+	}
+	return config.Extensions(), nil
+}
+
+// RespondDiagnostics checks a built file for the server and sends back the errors that only the updated content has
+func RespondDiagnostics(req *shared.DiagnosticsRequest) {
+	res := shared.DiagnosticsResponse{
+		Id:     req.Id,
+		Errors: []string{},
+	}
+
+	errs, err := CheckDiagnostics(req.Path, req.Original, req.Updated)
+	if err != nil {
+		log.Printf("Error checking diagnostics for %s: %v\n", req.Path, err)
+		res.Error = err.Error()
+	} else {
+		res.Errors = errs
+	}
+
+	apiErr := api.Client.RespondDiagnostics(CurrentPlanId, CurrentBranch, res)
+	if apiErr != nil {
+		log.Printf("Error sending diagnostics for %s: %v\n", req.Path, apiErr.Msg)
+	}
+}
+
+func CheckDiagnostics(path, original, updated string) ([]string, error) {
+	config, err := getDiagnosticsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	timeout := defaultDiagnosticsTimeout
+	if config.TimeoutSeconds > 0 {
+		timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+
+	ext := shared.NormalizeDiagnosticsExt(filepath.Ext(path))
+
+	var before, after []diagnostic
+
+	if i, server := findLanguageServer(config, ext); server != nil {
+		client, err := getLanguageServer(i, server)
+		if err != nil {
+			return nil, err
+		}
+
+		languageId := server.LanguageId
+		if languageId == "" {
+			languageId = guessLanguageId(ext)
+		}
+
+		absPath := filepath.Join(fs.ProjectRoot, path)
+
+		for j, content := range []string{original, updated} {
+			lspDiagnostics, err := client.check(absPath, languageId, content, timeout)
+			if err != nil {
+				stopLanguageServer(i)
+				return nil, err
+			}
+
+			res := make([]diagnostic, len(lspDiagnostics))
+			for k, d := range lspDiagnostics {
+				res[k] = diagnostic{
+					line:    d.Range.Start.Line + 1,
+					message: d.Message,
+					key:     d.Source + "|" + string(d.Code) + "|" + d.Message,
+				}
+			}
+
+			if j == 0 {
+				before = res
+			} else {
+				after = res
+			}
+		}
+	} else if command := findDiagnosticsCommand(config, ext); command != nil {
+		before, err = runDiagnosticsCommand(command.Command, path, original, timeout)
+		if err != nil {
+			return nil, err
+		}
+		after, err = runDiagnosticsCommand(command.Command, path, updated, timeout)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, nil
+	}
+
+	return newDiagnosticErrors(before, after), nil
+}
+
+// StopLanguageServers shuts down any language servers started for this stream
+func StopLanguageServers() {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+
+	for i, client := range languageServers {
+		client.stop()
+		delete(languageServers, i)
+	}
+}
+
+func getDiagnosticsConfig() (*shared.DiagnosticsConfig, error) {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+
+	if diagnosticsConfigLoaded {
+		return diagnosticsConfig, nil
+	}
+
+	config, err := fs.LoadProjectDiagnosticsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if config != nil {
+		executor, err := GetApplyScriptExecutor(ExecSandbox)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up diagnostics command execution: %v", err)
+		}
+
+		var skipped []string
+		err = CheckExecPermission()
+		if err == ErrExecNotPermitted {
+			config, skipped = nil, refusedDiagnosticsCommands(config, err)
+		} else if err != nil {
+			return nil, err
+		} else {
+			policies, err := GetExecPolicies()
+			if err != nil {
+				return nil, err
+			}
+
+			config, skipped = allowedDiagnosticsConfig(config, policies)
+		}
+		diagnosticsExecutor = executor
+		diagnosticsSkipped = skipped
+	}
+
+	diagnosticsConfig = config
+	diagnosticsConfigLoaded = true
+	return config, nil
+}
+
+// refusedDiagnosticsCommands lists every language server and command in the config as skipped for reason
+func refusedDiagnosticsCommands(config *shared.DiagnosticsConfig, reason error) []string {
+	var skipped []string
+	for _, server := range config.LanguageServers {
+		skipped = append(skipped, fmt.Sprintf("%s → %v", strings.Join(server.Command, " "), reason))
+	}
+	for _, command := range config.Commands {
+		skipped = append(skipped, fmt.Sprintf("%s → %v", command.Command, reason))
+	}
+	return skipped
+}
+
+// allowedDiagnosticsConfig leaves out language servers and commands that the exec policies don't allow. Diagnostics run in the middle of a stream where there's no way to confirm a command, so one that isn't in an allowlist is left out too. It returns nil if nothing is left.
+func allowedDiagnosticsConfig(config *shared.DiagnosticsConfig, policies []shared.NamedExecPolicy) (*shared.DiagnosticsConfig, []string) {
+	allowed := &shared.DiagnosticsConfig{TimeoutSeconds: config.TimeoutSeconds}
+	var skipped []string
+
+	for _, server := range config.LanguageServers {
+		if msg := diagnosticsPolicyViolation(strings.Join(server.Command, " "), policies); msg != "" {
+			skipped = append(skipped, msg)
+			continue
+		}
+		allowed.LanguageServers = append(allowed.LanguageServers, server)
+	}
+
+	for _, command := range config.Commands {
+		if msg := diagnosticsPolicyViolation(command.Command, policies); msg != "" {
+			skipped = append(skipped, msg)
+			continue
+		}
+		allowed.Commands = append(allowed.Commands, command)
+	}
+
+	if len(allowed.LanguageServers) == 0 && len(allowed.Commands) == 0 {
+		return nil, skipped
+	}
+	return allowed, skipped
+}
+
+func diagnosticsPolicyViolation(command string, policies []shared.NamedExecPolicy) string {
+	violations := shared.CheckExecPolicies(command, policies)
+	if len(violations) == 0 {
+		return ""
+	}
+
+	violation := violations[0]
+	switch violation.Type {
+	case shared.ExecPolicyViolationDenied:
+		return fmt.Sprintf("%s → denied by %s policy (%s)", violation.Command, strings.ToLower(violation.Source), violation.Pattern)
+	case shared.ExecPolicyViolationUnparseable:
+		return fmt.Sprintf("%s → can't be checked against %s policy deny rules", violation.Command, strings.ToLower(violation.Source))
+	}
+	return fmt.Sprintf("%s → not in %s policy allowlist", violation.Command, strings.ToLower(violation.Source))
+}
+
+func findLanguageServer(config *shared.DiagnosticsConfig, ext string) (int, *shared.DiagnosticsLanguageServer) {
+	for i := range config.LanguageServers {
+		if diagnosticsExtMatches(config.LanguageServers[i].Extensions, ext) {
+			return i, &config.LanguageServers[i]
+		}
+	}
+	return -1, nil
+}
+
+func findDiagnosticsCommand(config *shared.DiagnosticsConfig, ext string) *shared.DiagnosticsCommand {
+	for i := range config.Commands {
+		if diagnosticsExtMatches(config.Commands[i].Extensions, ext) {
+			return &config.Commands[i]
+		}
+	}
+	return nil
+}
+
+func diagnosticsExtMatches(exts []string, ext string) bool {
+	for _, e := range exts {
+		if shared.NormalizeDiagnosticsExt(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func getLanguageServer(i int, server *shared.DiagnosticsLanguageServer) (*lspClient, error) {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+
+	if client, ok := languageServers[i]; ok {
+		return client, nil
+	}
+
+	client, err := startLanguageServer(diagnosticsExecutor, server.Command, fs.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	languageServers[i] = client
+	return client, nil
+}
+
+// stopLanguageServer stops a server after a failed check so the next check starts a fresh one
+func stopLanguageServer(i int) {
+	diagnosticsMu.Lock()
+	client, ok := languageServers[i]
+	delete(languageServers, i)
+	diagnosticsMu.Unlock()
+
+	if ok {
+		client.stop()
+	}
+}
+
+// runDiagnosticsCommand runs the command on a workspace that mirrors the project with path's content replaced (see newDiagnosticsWorkspace), so the command can resolve the file's imports. Output lines from a failing command are the errors, with workspace paths replaced by project paths.
+func runDiagnosticsCommand(command, path, content string, timeout time.Duration) ([]diagnostic, error) {
+	dir, err := newDiagnosticsWorkspace(fs.ProjectRoot, path, content)
+	if dir != "" {
+		defer os.RemoveAll(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	workspacePath := filepath.Join(dir, path)
+
+	diagnosticsMu.Lock()
+	executor := diagnosticsExecutor
+	diagnosticsMu.Unlock()
+
+	cmd, err := executor.Command(diagnosticsShell(), dir, strings.ReplaceAll(command, "{file}", shellQuote(workspacePath)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating diagnostics command: %v", err)
+	}
+	SetPlatformSpecificAttrs(cmd)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting diagnostics command: %v", err)
+	}
+
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		if err := KillProcessGroup(cmd, syscall.SIGKILL); err != nil {
+			log.Printf("Failed to kill timed out diagnostics command: %v", err)
+		}
+	})
+
+	err = cmd.Wait()
+	timer.Stop()
+
+	if timedOut.Load() {
+		return nil, fmt.Errorf("diagnostics command timed out after %s", timeout)
+	}
+	if err == nil {
+		return nil, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("error running diagnostics command: %v", err)
+	}
+
+	res := []diagnostic{}
+	for _, line := range strings.Split(output.String(), "\n") {
+		line = strings.ReplaceAll(line, workspacePath, path)
+		line = strings.TrimSpace(strings.ReplaceAll(line, dir, fs.ProjectRoot))
+		if line == "" {
+			continue
+		}
+		res = append(res, diagnostic{
+			message: line,
+			key:     positionPattern.ReplaceAllString(line, ""),
+		})
+	}
+
+	return res, nil
+}
+
+// newDiagnosticsWorkspace creates a temp dir that mirrors the project root with symlinks, except along path, where directories are real so that path itself can be a real file with content. Commands see the whole project, like the file's package and the project's build config, without the project being copied or changed. The caller removes the returned dir, which is set even if there's an error.
+func newDiagnosticsWorkspace(root, path, content string) (string, error) {
+	cleaned := filepath.Clean(path)
+	if filepath.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path for diagnostics: %s", path)
+	}
+
+	dir, err := os.MkdirTemp("", "plandex-diagnostics-")
+	if err != nil {
+		return "", fmt.Errorf("error creating temp dir: %v", err)
+	}
+
+	parts := strings.Split(cleaned, string(filepath.Separator))
+	src := root
+	dst := dir
+
+	for i, part := range parts {
+		entries, err := os.ReadDir(src)
+		// a new file can be in a dir that doesn't exist yet
+		if err != nil && !os.IsNotExist(err) {
+			return dir, fmt.Errorf("error reading %s: %v", src, err)
+		}
+
+		for _, entry := range entries {
+			if entry.Name() == part {
+				continue
+			}
+			err = os.Symlink(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()))
+			if err != nil {
+				return dir, fmt.Errorf("error linking %s: %v", entry.Name(), err)
+			}
+		}
+
+		src = filepath.Join(src, part)
+		dst = filepath.Join(dst, part)
+
+		if i == len(parts)-1 {
+			err = os.WriteFile(dst, []byte(content), 0644)
+			if err != nil {
+				return dir, fmt.Errorf("error writing temp file: %v", err)
+			}
+		} else {
+			err = os.Mkdir(dst, 0755)
+			if err != nil {
+				return dir, fmt.Errorf("error creating temp dir: %v", err)
+			}
+		}
+	}
+
+	return dir, nil
+}
+
+func diagnosticsShell() string {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/bash"
+	}
+	return shell
+}
+
+// newDiagnosticErrors returns the errors in after that aren't in before, counting duplicates, so a second copy of an existing error is still new
+func newDiagnosticErrors(before, after []diagnostic) []string {
+	counts := map[string]int{}
+	for _, d := range before {
+		counts[d.key]++
+	}
+
+	res := []string{}
+	for _, d := range after {
+		if counts[d.key] > 0 {
+			counts[d.key]--
+			continue
+		}
+		res = append(res, d.String())
+	}
+
+	if len(res) > maxDiagnosticsErrors {
+		numMore := len(res) - maxDiagnosticsErrors
+		res = append(res[:maxDiagnosticsErrors], fmt.Sprintf("(%d more errors)", numMore))
+	}
+
+	return res
+}
+
+var languageIdsByExt = map[string]string{
+	".go":    "go",
+	".ts":    "typescript",
+	".tsx":   "typescriptreact",
+	".js":    "javascript",
+	".jsx":   "javascriptreact",
+	".py":    "python",
+	".rs":    "rust",
+	".rb":    "ruby",
+	".java":  "java",
+	".kt":    "kotlin",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".php":   "php",
+	".swift": "swift",
+}
+
+func guessLanguageId(ext string) string {
+	if id, ok := languageIdsByExt[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
@@ -0,0 +1,475 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	lspInitTimeout     = 60 * time.Second
+	lspShutdownTimeout = 2 * time.Second
+
+	// servers can publish more than once for a version--wait this long for another publish before using the latest
+	lspSettleDelay = 500 * time.Millisecond
+
+	lspSeverityError = 1
+)
+
+// lspClient is a minimal LSP client over stdio. It only does what's needed to get diagnostics for a document: initialize, open or change the document, and wait for the server to publish.
+type lspClient struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	nextId  atomic.Int64
+
+	pendingMu sync.Mutex
+	pending   map[int64]chan *lspMessage
+
+	publishCh chan lspPublishDiagnosticsParams
+
+	// checks are serialized so each one only sees publishes for its own change
+	checkMu sync.Mutex
+
+	// versions and last published diagnostics of open documents, by uri
+	versions  map[string]int
+	published map[string][]lspDiagnostic
+
+	// closed when the server's stdout closes
+	done chan struct{}
+}
+
+type lspMessage struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *lspError       `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	Uri         string          `json:"uri"`
+	Version     *int            `json:"version,omitempty"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspDiagnostic struct {
+	Range struct {
+		Start struct {
+			Line      int `json:"line"`
+			Character int `json:"character"`
+		} `json:"start"`
+	} `json:"range"`
+	Severity int             `json:"severity,omitempty"`
+	Code     json.RawMessage `json:"code,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// languageServerCommandLine quotes a language server's command for 'shell -c'. exec replaces the shell so stopping the server doesn't leave it running.
+func languageServerCommandLine(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = shellQuote(arg)
+	}
+	return "exec " + strings.Join(quoted, " ")
+}
+
+func startLanguageServer(executor ApplyScriptExecutor, command []string, root string) (*lspClient, error) {
+	cmd, err := executor.Command(diagnosticsShell(), root, languageServerCommandLine(command))
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s command: %v", command[0], err)
+	}
+	cmd.Stderr = io.Discard
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdin pipe: %v", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdout pipe: %v", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting %s: %v", command[0], err)
+	}
+
+	c := &lspClient{
+		name:      command[0],
+		cmd:       cmd,
+		stdin:     stdin,
+		pending:   map[int64]chan *lspMessage{},
+		publishCh: make(chan lspPublishDiagnosticsParams, 64),
+		versions:  map[string]int{},
+		published: map[string][]lspDiagnostic{},
+		done:      make(chan struct{}),
+	}
+
+	go c.readLoop(bufio.NewReader(stdout))
+
+	rootUri := lspFileUri(root)
+	_, err = c.call("initialize", map[string]any{
+		"processId": os.Getpid(),
+		"rootUri":   rootUri,
+		"workspaceFolders": []map[string]any{
+			{"uri": rootUri, "name": filepath.Base(root)},
+		},
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{
+				"synchronization":    map[string]any{},
+				"publishDiagnostics": map[string]any{"versionSupport": true},
+			},
+			"workspace": map[string]any{
+				"configuration":    true,
+				"workspaceFolders": true,
+			},
+		},
+	}, lspInitTimeout)
+	if err != nil {
+		c.stop()
+		return nil, fmt.Errorf("error initializing %s: %v", c.name, err)
+	}
+
+	err = c.notify("initialized", map[string]any{})
+	if err != nil {
+		c.stop()
+		return nil, fmt.Errorf("error initializing %s: %v", c.name, err)
+	}
+
+	return c, nil
+}
+
+// check sets the document at path to text and returns the errors the server publishes for it. The document stays open with the new text, so later checks of other files see it.
+func (c *lspClient) check(path, languageId, text string, timeout time.Duration) ([]lspDiagnostic, error) {
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+
+	uri := lspFileUri(path)
+
+	// drop publishes left over from earlier checks
+	for drained := false; !drained; {
+		select {
+		case params := <-c.publishCh:
+			c.published[params.Uri] = params.Diagnostics
+		default:
+			drained = true
+		}
+	}
+
+	version := c.versions[uri] + 1
+
+	var err error
+	if version == 1 {
+		err = c.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{
+				"uri":        uri,
+				"languageId": languageId,
+				"version":    version,
+				"text":       text,
+			},
+		})
+	} else {
+		err = c.notify("textDocument/didChange", map[string]any{
+			"textDocument": map[string]any{
+				"uri":     uri,
+				"version": version,
+			},
+			"contentChanges": []map[string]any{
+				{"text": text},
+			},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.versions[uri] = version
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var latest []lspDiagnostic
+	received := false
+	var settle <-chan time.Time
+
+	for {
+		select {
+		case params := <-c.publishCh:
+			if !lspSameUri(params.Uri, uri) {
+				c.published[params.Uri] = params.Diagnostics
+				continue
+			}
+			if params.Version != nil && *params.Version < version {
+				continue
+			}
+			latest = params.Diagnostics
+			received = true
+			c.published[uri] = latest
+			settle = time.After(lspSettleDelay)
+
+		case <-settle:
+			return lspErrors(latest), nil
+
+		case <-deadline.C:
+			// some servers skip publishing when the diagnostics didn't change
+			if prev, ok := c.published[uri]; ok && !received {
+				log.Printf("%s didn't publish diagnostics for %s version %d, using the last ones it published\n", c.name, path, version)
+				return lspErrors(prev), nil
+			}
+			if received {
+				return lspErrors(latest), nil
+			}
+			return nil, fmt.Errorf("timed out waiting for diagnostics from %s", c.name)
+
+		case <-c.done:
+			return nil, fmt.Errorf("%s exited", c.name)
+		}
+	}
+}
+
+func (c *lspClient) stop() {
+	select {
+	case <-c.done:
+	default:
+		c.call("shutdown", nil, lspShutdownTimeout)
+		c.notify("exit", nil)
+	}
+
+	c.stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+	case <-time.After(lspShutdownTimeout):
+		c.cmd.Process.Kill()
+	}
+}
+
+func (c *lspClient) call(method string, params any, timeout time.Duration) (json.RawMessage, error) {
+	id := c.nextId.Add(1)
+	ch := make(chan *lspMessage, 1)
+
+	c.pendingMu.Lock()
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	err := c.write(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return nil, fmt.Errorf("%s error %d: %s", method, msg.Error.Code, msg.Error.Message)
+		}
+		return msg.Result, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("timed out waiting for %s response", method)
+	case <-c.done:
+		return nil, fmt.Errorf("%s exited", c.name)
+	}
+}
+
+func (c *lspClient) notify(method string, params any) error {
+	msg := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	return c.write(msg)
+}
+
+func (c *lspClient) write(msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling message: %v", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err = fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	if err != nil {
+		return fmt.Errorf("error writing to %s: %v", c.name, err)
+	}
+	return nil
+}
+
+func (c *lspClient) readLoop(r *bufio.Reader) {
+	defer close(c.done)
+
+	for {
+		body, err := readLspMessage(r)
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Error reading from %s: %v\n", c.name, err)
+			}
+			return
+		}
+
+		var msg lspMessage
+		err = json.Unmarshal(body, &msg)
+		if err != nil {
+			log.Printf("Error parsing message from %s: %v\n", c.name, err)
+			continue
+		}
+
+		switch {
+		case msg.Method != "" && len(msg.Id) > 0:
+			c.replyToServer(&msg)
+
+		case msg.Method == "textDocument/publishDiagnostics":
+			var params lspPublishDiagnosticsParams
+			if json.Unmarshal(msg.Params, &params) == nil {
+				select {
+				case c.publishCh <- params:
+				default:
+				}
+			}
+
+		case msg.Method == "" && len(msg.Id) > 0:
+			id, err := strconv.ParseInt(string(msg.Id), 10, 64)
+			if err != nil {
+				continue
+			}
+			c.pendingMu.Lock()
+			ch, ok := c.pending[id]
+			c.pendingMu.Unlock()
+			if ok {
+				ch <- &msg
+			}
+		}
+	}
+}
+
+// replyToServer answers requests from the server--servers can wait on these before publishing, so every request gets an empty result
+func (c *lspClient) replyToServer(msg *lspMessage) {
+	var result any
+	if msg.Method == "workspace/configuration" {
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		result = make([]any, len(params.Items))
+	}
+
+	err := c.write(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      msg.Id,
+		"result":  result,
+	})
+	if err != nil {
+		log.Printf("Error replying to %s request from %s: %v\n", msg.Method, c.name, err)
+	}
+}
+
+func readLspMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if length >= 0 {
+				break
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %v", err)
+			}
+		}
+	}
+
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func lspErrors(diagnostics []lspDiagnostic) []lspDiagnostic {
+	res := []lspDiagnostic{}
+	for _, d := range diagnostics {
+		// severity is optional--servers that leave it out mean an error
+		if d.Severity == 0 || d.Severity == lspSeverityError {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func lspFileUri(path string) string {
+	path = filepath.ToSlash(path)
+	if runtime.GOOS == "windows" {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// servers don't always escape uris the same way, so compare the paths
+func lspSameUri(a, b string) bool {
+	parsedA, errA := url.Parse(a)
+	parsedB, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(parsedA.Path, parsedB.Path)
+	}
+	return parsedA.Path == parsedB.Path
+}
@@ -0,0 +1,102 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewDiagnosticsWorkspace(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "go.mod"), "module example")
+	mustWrite(t, filepath.Join(root, "pkg", "a.go"), "original")
+	mustWrite(t, filepath.Join(root, "pkg", "b.go"), "sibling")
+
+	dir, err := newDiagnosticsWorkspace(root, "pkg/a.go", "updated")
+	if dir != "" {
+		defer os.RemoveAll(dir)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{"go.mod": "module example", "pkg/a.go": "updated", "pkg/b.go": "sibling"} {
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q (%v), want %q", path, got, err, want)
+		}
+	}
+
+	if info, err := os.Lstat(filepath.Join(dir, "pkg", "a.go")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("expected the checked file to be a real file")
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(root, "pkg", "a.go")); string(got) != "original" {
+		t.Errorf("the project file was changed: %q", got)
+	}
+
+	// a new file in a new dir
+	newDir, err := newDiagnosticsWorkspace(root, "cmd/tool/main.go", "new")
+	if newDir != "" {
+		defer os.RemoveAll(newDir)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(newDir, "cmd", "tool", "main.go")); string(got) != "new" {
+		t.Errorf("new file: got %q", got)
+	}
+
+	for _, path := range []string{"../a.go", "/etc/passwd", "."} {
+		if _, err := newDiagnosticsWorkspace(root, path, ""); err == nil {
+			t.Errorf("expected %q to be rejected", path)
+		}
+	}
+}
+
+func TestRunDiagnosticsCommand(t *testing.T) {
+	fs.ProjectRoot = t.TempDir()
+	mustWrite(t, filepath.Join(fs.ProjectRoot, "pkg", "a.txt"), "ok")
+	mustWrite(t, filepath.Join(fs.ProjectRoot, "pkg", "b.txt"), "sibling")
+
+	diagnosticsExecutor = hostExecutor{}
+	defer func() { diagnosticsExecutor = nil }()
+
+	// the command can see the rest of the project, and errors point at project paths
+	command := `if grep -q bad {file}; then echo {file}:1: bad content; cat pkg/b.txt; exit 1; fi`
+
+	res, err := runDiagnosticsCommand(command, "pkg/a.txt", "ok", 10*time.Second)
+	if err != nil || len(res) != 0 {
+		t.Fatalf("expected no errors, got %v %v", res, err)
+	}
+
+	res, err = runDiagnosticsCommand(command, "pkg/a.txt", "bad", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, d := range res {
+		messages = append(messages, d.message)
+	}
+	want := []string{"pkg/a.txt:1: bad content", "sibling"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("got %v, want %v", messages, want)
+	}
+
+	if _, err = runDiagnosticsCommand("sleep 5", "pkg/a.txt", "ok", 100*time.Millisecond); err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+
+	shared "plandex-shared"
+)
+
+// ErrExecNotPermitted is returned by CheckExecPermission when the user's org role doesn't allow running commands
+var ErrExecNotPermitted = errors.New("your org role doesn't allow running commands")
+
+// CheckExecPermission returns ErrExecNotPermitted if the user's org role lacks the exec_commands permission. Commands are refused outright in that case, before they're checked against the exec policies--a policy can't allow them.
+func CheckExecPermission() error {
+	// older servers don't have roles with exec permissions
+	role, apiErr := api.Client.GetCurrentOrgRole()
+	if apiErr != nil && apiErr.Status != http.StatusNotFound {
+		return fmt.Errorf("error getting org role: %v", apiErr.Msg)
+	}
+
+	if !roleCanExec(role) {
+		return ErrExecNotPermitted
+	}
+
+	return nil
+}
+
+func roleCanExec(role *shared.OrgRole) bool {
+	if role == nil {
+		return true
+	}
+
+	permissions := shared.Permissions{}
+	for _, key := range role.Permissions {
+		permissions[key] = true
+	}
+
+	return permissions.HasPermission(shared.PermissionExecCommands)
+}
+
+// GetExecPolicies loads the org policy from the server and the project policy from the project root. Both apply—see shared.CheckExecPolicies. Check CheckExecPermission first--the policies don't cover the user's role.
+func GetExecPolicies() ([]shared.NamedExecPolicy, error) {
+	orgPolicy, apiErr := api.Client.GetOrgExecPolicy()
+	if apiErr != nil {
+		return nil, fmt.Errorf("error getting org exec policy: %v", apiErr.Msg)
+	}
+
+	projectPolicy, err := fs.LoadProjectExecPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	return []shared.NamedExecPolicy{
+		{Source: "Org", Policy: orgPolicy},
+		{Source: "Project", Policy: projectPolicy},
+	}, nil
+}
@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestRoleCanExec(t *testing.T) {
+	tests := []struct {
+		name string
+		role *shared.OrgRole
+		want bool
+	}{
+		{"older server without roles", nil, true},
+		{"role with exec_commands", &shared.OrgRole{Permissions: []string{"create_plan", "exec_commands"}}, true},
+		{"role without exec_commands", &shared.OrgRole{Permissions: []string{"create_plan", "apply_plan"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roleCanExec(tt.role); got != tt.want {
+				t.Errorf("roleCanExec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefusedDiagnosticsCommands(t *testing.T) {
+	config := &shared.DiagnosticsConfig{
+		LanguageServers: []shared.DiagnosticsLanguageServer{{Command: []string{"gopls", "serve"}}},
+		Commands:        []shared.DiagnosticsCommand{{Command: "go vet ./..."}},
+	}
+
+	got := refusedDiagnosticsCommands(config, ErrExecNotPermitted)
+	want := []string{
+		"gopls serve → your org role doesn't allow running commands",
+		"go vet ./... → your org role doesn't allow running commands",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("refusedDiagnosticsCommands() = %v, want %v", got, want)
+	}
+}
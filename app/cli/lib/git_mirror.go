@@ -0,0 +1,451 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"regexp"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// mirror commits record the plan apply they came from so rewinds can find them again
+const gitMirrorApplyTrailer = "Plandex-Apply:"
+
+var unsafeGitBranchChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func gitMirrorsPath(planId string) string {
+	return filepath.Join(HomeCurrentProjectDir, planId, "git-mirrors.json")
+}
+
+func loadGitMirrors(planId string) (types.GitMirrorsByBranch, error) {
+	if HomeCurrentProjectDir == "" {
+		return nil, fmt.Errorf("no current project")
+	}
+
+	mirrors := types.GitMirrorsByBranch{}
+
+	bytes, err := os.ReadFile(gitMirrorsPath(planId))
+	if os.IsNotExist(err) {
+		return mirrors, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading git-mirrors.json: %v", err)
+	}
+
+	err = json.Unmarshal(bytes, &mirrors)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling git-mirrors.json: %v", err)
+	}
+
+	return mirrors, nil
+}
+
+func writeGitMirrors(planId string, mirrors types.GitMirrorsByBranch) error {
+	path := gitMirrorsPath(planId)
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating plan settings dir: %v", err)
+	}
+
+	bytes, err := json.Marshal(mirrors)
+	if err != nil {
+		return fmt.Errorf("error marshalling git mirrors: %v", err)
+	}
+
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing git-mirrors.json: %v", err)
+	}
+
+	return nil
+}
+
+// GetGitMirror returns the git mirror for a plan branch, or nil if the branch isn't mirrored
+func GetGitMirror(planId, branch string) (*types.GitMirror, error) {
+	mirrors, err := loadGitMirrors(planId)
+	if err != nil {
+		return nil, err
+	}
+
+	return mirrors[branch], nil
+}
+
+func DefaultGitMirrorBranch(planName, branch string) string {
+	return fmt.Sprintf("plandex/%s/%s",
+		strings.Trim(unsafeGitBranchChars.ReplaceAllString(planName, "-"), "-."),
+		strings.Trim(unsafeGitBranchChars.ReplaceAllString(branch, "-"), "-."),
+	)
+}
+
+func DefaultGitMirrorWorktree(planId, branch string) string {
+	return filepath.Join(HomeCurrentProjectDir, planId, "worktrees", unsafeGitBranchChars.ReplaceAllString(branch, "-"))
+}
+
+type CreateGitMirrorParams struct {
+	PlanId    string
+	Branch    string
+	GitBranch string
+	Worktree  string
+	BaseRef   string
+}
+
+// CreateGitMirror creates a git branch from BaseRef, checks it out in a dedicated worktree, and
+// records it as the mirror for the plan branch
+func CreateGitMirror(params CreateGitMirrorParams) (*types.GitMirror, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "check-ref-format", "--branch", params.GitBranch).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("invalid git branch name %q: %s", params.GitBranch, strings.TrimSpace(string(res)))
+	}
+
+	res, err = exec.Command("git", "-C", fs.ProjectRoot, "rev-parse", "--verify", params.BaseRef+"^{commit}").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving base ref %q: %s", params.BaseRef, strings.TrimSpace(string(res)))
+	}
+	baseSha := strings.TrimSpace(string(res))
+
+	res, err = exec.Command("git", "-C", fs.ProjectRoot, "rev-parse", "--show-prefix").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error getting project path in git repo: %v, output: %s", err, string(res))
+	}
+	prefix := strings.TrimSpace(string(res))
+
+	worktree, err := filepath.Abs(params.Worktree)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving worktree path: %v", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(worktree), os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("error creating worktree parent dir: %v", err)
+	}
+
+	res, err = exec.Command("git", "-C", fs.ProjectRoot, "worktree", "add", "-b", params.GitBranch, worktree, baseSha).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error creating git worktree: %v, output: %s", err, string(res))
+	}
+
+	mirror := &types.GitMirror{
+		GitBranch: params.GitBranch,
+		Worktree:  worktree,
+		BaseSha:   baseSha,
+		Prefix:    prefix,
+	}
+
+	mirrors, err := loadGitMirrors(params.PlanId)
+	if err != nil {
+		return nil, err
+	}
+	mirrors[params.Branch] = mirror
+
+	err = writeGitMirrors(params.PlanId, mirrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return mirror, nil
+}
+
+// RemoveGitMirror stops mirroring a plan branch and removes its worktree. The git branch is kept.
+func RemoveGitMirror(planId, branch string) error {
+	mirrors, err := loadGitMirrors(planId)
+	if err != nil {
+		return err
+	}
+
+	mirror := mirrors[branch]
+	if mirror == nil {
+		return nil
+	}
+
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	if _, err := os.Stat(mirror.Worktree); err == nil {
+		res, err := exec.Command("git", "-C", fs.ProjectRoot, "worktree", "remove", "--force", mirror.Worktree).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error removing git worktree: %v, output: %s", err, string(res))
+		}
+	}
+
+	delete(mirrors, branch)
+
+	return writeGitMirrors(planId, mirrors)
+}
+
+// ensureGitMirrorWorktree re-creates the mirror's worktree if it was removed outside of plandex
+func ensureGitMirrorWorktree(mirror *types.GitMirror) error {
+	if _, err := os.Stat(mirror.Worktree); err == nil {
+		return nil
+	}
+
+	res, err := exec.Command("git", "-C", fs.ProjectRoot, "worktree", "prune").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error pruning git worktrees: %v, output: %s", err, string(res))
+	}
+
+	res, err = exec.Command("git", "-C", fs.ProjectRoot, "worktree", "add", mirror.Worktree, mirror.GitBranch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error restoring git worktree: %v, output: %s", err, string(res))
+	}
+
+	return nil
+}
+
+// ApplyToGitMirror writes plan files to the mirror's worktree, leaving the project's working tree untouched.
+// Returns the updated paths.
+func ApplyToGitMirror(mirror *types.GitMirror, toApply map[string]string, toRemove map[string]bool) ([]string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	err := ensureGitMirrorWorktree(mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(mirror.Worktree, mirror.Prefix)
+
+	// check every path before writing anything so a bad path doesn't leave a partial apply
+	dstPaths := map[string]string{}
+	for path := range toApply {
+		if path == "_apply.sh" {
+			continue
+		}
+		dstPath, err := gitMirrorPath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		dstPaths[path] = dstPath
+	}
+	for path, remove := range toRemove {
+		if !remove {
+			continue
+		}
+		dstPath, err := gitMirrorPath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		dstPaths[path] = dstPath
+	}
+
+	var updatedFiles []string
+
+	for path, content := range toApply {
+		if path == "_apply.sh" {
+			continue
+		}
+
+		dstPath := dstPaths[path]
+		content = strings.ReplaceAll(content, "\\`\\`\\`", "```")
+
+		bytes, err := os.ReadFile(dstPath)
+		if err == nil && string(bytes) == content {
+			continue
+		} else if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %s", dstPath, err.Error())
+		}
+
+		err = os.MkdirAll(filepath.Dir(dstPath), 0755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %s", filepath.Dir(dstPath), err.Error())
+		}
+
+		err = os.WriteFile(dstPath, []byte(content), 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %s", dstPath, err.Error())
+		}
+
+		updatedFiles = append(updatedFiles, path)
+	}
+
+	for path, remove := range toRemove {
+		if !remove {
+			continue
+		}
+
+		dstPath := dstPaths[path]
+		err := os.Remove(dstPath)
+		if err == nil {
+			updatedFiles = append(updatedFiles, path)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %s", dstPath, err.Error())
+		}
+	}
+
+	return updatedFiles, nil
+}
+
+// gitMirrorPath resolves a plan file path inside the mirror root. Absolute paths, paths with '..' that leave the root, and paths through symlinks that point outside the root are rejected.
+func gitMirrorPath(root, path string) (string, error) {
+	if path == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("invalid path %q: must be relative to the project root", path)
+	}
+
+	dstPath := filepath.Join(root, path)
+	rel, err := filepath.Rel(root, dstPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path %q: escapes the git mirror", path)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving git mirror root: %v", err)
+	}
+
+	// the file may not exist yet--resolve it or its nearest existing parent
+	existing := dstPath
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s: %v", existing, err)
+	}
+
+	rel, err = filepath.Rel(realRoot, realExisting)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path %q: escapes the git mirror through a symlink", path)
+	}
+
+	return dstPath, nil
+}
+
+// CommitGitMirror commits everything in the mirror's worktree, tagging the commit with the plan apply id
+func CommitGitMirror(mirror *types.GitMirror, msg, applyId string) error {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	err := GitAdd(mirror.Worktree, ".", false)
+	if err != nil {
+		return err
+	}
+
+	if applyId != "" {
+		msg = fmt.Sprintf("%s\n\n%s %s", msg, gitMirrorApplyTrailer, applyId)
+	}
+
+	return GitCommit(mirror.Worktree, msg, nil, false)
+}
+
+// DiscardGitMirrorChanges clears anything written to the mirror's worktree since its last commit
+func DiscardGitMirrorChanges(mirror *types.GitMirror) error {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "-C", mirror.Worktree, "reset", "--hard").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error resetting git worktree: %v, output: %s", err, string(res))
+	}
+
+	res, err = exec.Command("git", "-C", mirror.Worktree, "clean", "-d", "-f").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error cleaning git worktree: %v, output: %s", err, string(res))
+	}
+
+	return nil
+}
+
+type GitMirrorCommit struct {
+	Sha     string
+	Subject string
+	ApplyId string
+}
+
+// ListGitMirrorCommits lists commits on the mirror branch since it was created, newest first
+func ListGitMirrorCommits(mirror *types.GitMirror) ([]GitMirrorCommit, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "-C", fs.ProjectRoot, "log", "--format=%H%x1f%B%x1e", mirror.BaseSha+".."+mirror.GitBranch).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error listing git mirror commits: %v, output: %s", err, string(res))
+	}
+
+	var commits []GitMirrorCommit
+	for _, entry := range strings.Split(string(res), "\x1e") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "\x1f", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		commit := GitMirrorCommit{Sha: parts[0]}
+		for i, line := range strings.Split(parts[1], "\n") {
+			if i == 0 {
+				commit.Subject = line
+			}
+			if strings.HasPrefix(line, gitMirrorApplyTrailer) {
+				commit.ApplyId = strings.TrimSpace(strings.TrimPrefix(line, gitMirrorApplyTrailer))
+			}
+		}
+
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// RewindGitMirror resets the mirror branch to just before the oldest commit made for any of the undone applies.
+// Returns the sha the branch was reset to, or an empty string if no mirror commits were undone.
+func RewindGitMirror(mirror *types.GitMirror, undoneApplies []*shared.PlanApply) (string, error) {
+	undoneIds := map[string]bool{}
+	for _, apply := range undoneApplies {
+		undoneIds[apply.Id] = true
+	}
+
+	commits, err := ListGitMirrorCommits(mirror)
+	if err != nil {
+		return "", err
+	}
+
+	var oldestUndone string
+	for _, commit := range commits {
+		if undoneIds[commit.ApplyId] {
+			oldestUndone = commit.Sha
+		}
+	}
+
+	if oldestUndone == "" {
+		return "", nil
+	}
+
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	err = ensureGitMirrorWorktree(mirror)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := exec.Command("git", "-C", mirror.Worktree, "rev-parse", oldestUndone+"^").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error resolving parent commit: %v, output: %s", err, string(res))
+	}
+	targetSha := strings.TrimSpace(string(res))
+
+	log.Printf("Rewinding git mirror %s to %s", mirror.GitBranch, targetSha)
+
+	res, err = exec.Command("git", "-C", mirror.Worktree, "reset", "--hard", targetSha).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error resetting git mirror branch: %v, output: %s", err, string(res))
+	}
+
+	return targetSha, nil
+}
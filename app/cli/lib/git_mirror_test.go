@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGitMirrorPath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "target.txt"), filepath.Join(root, "file-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []string{"main.go", "src/main.go", "new/dir/file.go", "src/../main.go", "./main.go"}
+	for _, path := range valid {
+		dstPath, err := gitMirrorPath(root, path)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", path, err)
+			continue
+		}
+		if !strings.HasPrefix(dstPath, root+string(filepath.Separator)) {
+			t.Errorf("%q resolved outside the root: %s", path, dstPath)
+		}
+	}
+
+	invalid := []string{"", ".", "..", "../evil.go", "src/../../evil.go", "/etc/passwd", "link/evil.go", "file-link"}
+	for _, path := range invalid {
+		if dstPath, err := gitMirrorPath(root, path); err == nil {
+			t.Errorf("%q: expected an error, got %s", path, dstPath)
+		}
+	}
+}
+
+func TestApplyToGitMirror(t *testing.T) {
+	worktree := t.TempDir()
+	mirror := &types.GitMirror{Worktree: worktree, Prefix: "app"}
+
+	if err := os.MkdirAll(filepath.Join(worktree, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "app", "old.go"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "app", "same.go"), []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := ApplyToGitMirror(mirror, map[string]string{
+		"_apply.sh":  "npm test",
+		"same.go":    "same",
+		"pkg/new.go": "new",
+		"fence.md":   "\\`\\`\\`go\\`\\`\\`",
+	}, map[string]bool{"old.go": true, "missing.go": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(updated)
+	if strings.Join(updated, ",") != "fence.md,old.go,pkg/new.go" {
+		t.Errorf("updated = %v", updated)
+	}
+
+	if b, _ := os.ReadFile(filepath.Join(worktree, "app", "pkg", "new.go")); string(b) != "new" {
+		t.Errorf("new file content = %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(worktree, "app", "fence.md")); string(b) != "```go```" {
+		t.Errorf("escaped fences not restored: %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "app", "old.go")); !os.IsNotExist(err) {
+		t.Errorf("old.go wasn't removed")
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "app", "_apply.sh")); !os.IsNotExist(err) {
+		t.Errorf("_apply.sh shouldn't be written to the mirror")
+	}
+}
+
+func TestApplyToGitMirrorRejectsEscapes(t *testing.T) {
+	parent := t.TempDir()
+	worktree := filepath.Join(parent, "worktree")
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mirror := &types.GitMirror{Worktree: worktree}
+
+	tests := []struct {
+		name     string
+		toApply  map[string]string
+		toRemove map[string]bool
+	}{
+		{"write with ..", map[string]string{"ok.go": "ok", "../evil.go": "evil"}, nil},
+		{"absolute write", map[string]string{filepath.Join(parent, "evil.go"): "evil"}, nil},
+		{"remove with ..", nil, map[string]bool{"../victim.go": true}},
+	}
+
+	if err := os.WriteFile(filepath.Join(parent, "victim.go"), []byte("victim"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyToGitMirror(mirror, tt.toApply, tt.toRemove); err == nil {
+				t.Fatalf("expected an error")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil.go")); !os.IsNotExist(err) {
+				t.Errorf("file written outside the mirror")
+			}
+			if _, err := os.Stat(filepath.Join(worktree, "ok.go")); !os.IsNotExist(err) {
+				t.Errorf("nothing should be written when any path is invalid")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "victim.go")); err != nil {
+				t.Errorf("file removed outside the mirror")
+			}
+		})
+	}
+}
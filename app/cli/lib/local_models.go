@@ -0,0 +1,110 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	shared "plandex-shared"
+)
+
+var localModelsClient = &http.Client{Timeout: 10 * time.Second}
+
+// DiscoverLocalModels lists the chat-capable models installed on a local Ollama server. If the server doesn't expose Ollama's native api (like a plain llama.cpp server), it falls back to the OpenAI-compatible /v1/models endpoint.
+func DiscoverLocalModels(baseUrl string) ([]shared.LocalModelInfo, error) {
+	nativeUrl := shared.OllamaNativeBaseUrl(baseUrl)
+
+	var tags shared.OllamaTagsResponse
+	err := getLocalJson(nativeUrl+"/api/tags", &tags)
+	if err != nil {
+		res, fallbackErr := discoverOpenAICompatibleModels(baseUrl)
+		if fallbackErr != nil {
+			return nil, fmt.Errorf("error listing local models at %s: %v", nativeUrl, err)
+		}
+		return res, nil
+	}
+
+	res := []shared.LocalModelInfo{}
+	for _, tag := range tags.Models {
+		var show shared.OllamaShowResponse
+		err := postLocalJson(nativeUrl+"/api/show", map[string]string{"model": tag.Name}, &show)
+		if err != nil {
+			return nil, fmt.Errorf("error getting details for local model %s: %v", tag.Name, err)
+		}
+
+		// skip embedding models—older Ollama versions don't report capabilities, so only filter when they're present
+		if len(show.Capabilities) > 0 && !show.HasCapability("completion") {
+			continue
+		}
+
+		paramSize := show.Details.ParameterSize
+		if paramSize == "" {
+			paramSize = tag.Details.ParameterSize
+		}
+
+		res = append(res, shared.LocalModelInfo{
+			Name:            tag.Name,
+			ContextLength:   show.GetContextLength(),
+			ParameterCount:  shared.ParseParameterSize(paramSize),
+			HasImageSupport: show.HasCapability("vision"),
+		})
+	}
+
+	return res, nil
+}
+
+func discoverOpenAICompatibleModels(baseUrl string) ([]shared.LocalModelInfo, error) {
+	var models shared.LlamaCppModelsResponse
+	err := getLocalJson(shared.OllamaOpenAIBaseUrl(baseUrl)+"/models", &models)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []shared.LocalModelInfo{}
+	for _, m := range models.Data {
+		info := shared.LocalModelInfo{Name: m.Id}
+		if m.Meta != nil {
+			info.ContextLength = m.Meta.NCtxTrain
+		}
+		res = append(res, info)
+	}
+
+	return res, nil
+}
+
+func getLocalJson(url string, out any) error {
+	resp, err := localModelsClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return decodeLocalResponse(resp, out)
+}
+
+func postLocalJson(url string, body any, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := localModelsClient.Post(url, "application/json", bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return decodeLocalResponse(resp, out)
+}
+
+func decodeLocalResponse(resp *http.Response, out any) error {
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
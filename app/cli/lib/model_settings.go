@@ -14,6 +14,9 @@ import (
 
 const GoBack = "← Go back"
 
+// sent for local providers that don't require an api key
+const localApiKeyPlaceholder = "local"
+
 func SelectModelForRole(customModels []*shared.AvailableModel, role shared.ModelRole, includeProviderGoBack bool) *shared.AvailableModel {
 	var providers []string
 	addedProviders := map[string]bool{}
@@ -164,7 +167,9 @@ func mustVerifyApiKeys(silent bool) map[string]string {
 	missingAny := false
 	if len(requiredEnvVars.RequiresAll) > 0 {
 		for envVar := range requiredEnvVars.RequiresAll {
-			if os.Getenv(envVar) == "" {
+			if os.Getenv(envVar) == "" && shared.OptionalApiKeyEnvVars[envVar] {
+				apiKeys[envVar] = localApiKeyPlaceholder
+			} else if os.Getenv(envVar) == "" {
 				fmt.Fprintln(os.Stderr, color.New(color.Bold, term.ColorHiRed).Sprintf("🚨 %s environment variable is not set.\n", envVar))
 				delete(requiredEnvVars.RequiresEither, envVar)
 				missingAny = true
@@ -177,12 +182,16 @@ func mustVerifyApiKeys(silent bool) map[string]string {
 	if len(requiredEnvVars.RequiresEither) > 0 {
 		vars := []string{}
 		for envVar := range requiredEnvVars.RequiresEither {
-			if os.Getenv(envVar) == "" {
-				vars = append(vars, envVar)
-			} else {
+			if os.Getenv(envVar) != "" {
 				apiKeys[envVar] = os.Getenv(envVar)
+			} else if shared.OptionalApiKeyEnvVars[envVar] {
+				// a local provider with no key set is still usable, but other missing keys are reported
+				apiKeys[envVar] = localApiKeyPlaceholder
+			} else {
+				vars = append(vars, envVar)
 			}
 		}
+
 		if len(vars) > 1 {
 			s := "🚨 Either "
 			if len(vars) == 2 {
@@ -200,5 +209,30 @@ func mustVerifyApiKeys(silent bool) map[string]string {
 		os.Exit(1)
 	}
 
+	addEmbeddingApiKey(apiKeys)
+
 	return apiKeys
 }
+
+// addEmbeddingApiKey sends the semantic index's embedding provider key along with the model pack's keys. It's optional--if it's not set, the server ranks the index with bm25.
+func addEmbeddingApiKey(apiKeys map[string]string) {
+	if CurrentPlanId == "" {
+		return
+	}
+
+	config, apiErr := api.Client.GetPlanConfig(CurrentPlanId)
+	if apiErr != nil || !config.UsesEmbeddings() {
+		return
+	}
+
+	envVar, ok := shared.ApiKeyByProvider[config.GetEmbeddingProvider()]
+	if !ok || apiKeys[envVar] != "" {
+		return
+	}
+
+	if os.Getenv(envVar) != "" {
+		apiKeys[envVar] = os.Getenv(envVar)
+	} else if shared.OptionalApiKeyEnvVars[envVar] {
+		apiKeys[envVar] = localApiKeyPlaceholder
+	}
+}
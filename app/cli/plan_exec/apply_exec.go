@@ -25,7 +25,7 @@ func GetOnApplyExecFailWithCommand(applyFlags types.ApplyFlags, tellFlags types.
 
 func getOnApplyExecFail(applyFlags types.ApplyFlags, tellFlags types.TellFlags, execCommand string) types.OnApplyExecFailFn {
 	var onExecFail types.OnApplyExecFailFn
-	onExecFail = func(status int, output string, attempt int, toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
+	onExecFail = func(failure types.ApplyFailure, attempt int, toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
 		var proceed bool
 		resetAttempts := false
 
@@ -35,7 +35,11 @@ func getOnApplyExecFail(applyFlags types.ApplyFlags, tellFlags types.TellFlags,
 				if attempt == 1 {
 					timesLbl = "time"
 				}
-				color.New(term.ColorHiRed, color.Bold).Printf("Commands failed %d %s.\n", attempt, timesLbl)
+				if failure.VerifyCommand != "" {
+					color.New(term.ColorHiRed, color.Bold).Printf("Verification failed %d %s.\n", attempt, timesLbl)
+				} else {
+					color.New(term.ColorHiRed, color.Bold).Printf("Commands failed %d %s.\n", attempt, timesLbl)
+				}
 			} else {
 				proceed = true
 			}
@@ -122,12 +126,22 @@ func getOnApplyExecFail(applyFlags types.ApplyFlags, tellFlags types.TellFlags,
 			if !auth.Current.IntegratedModelsMode {
 				apiKeys = lib.MustVerifyApiKeysSilent()
 			}
-			prompt := fmt.Sprintf("Execution failed with exit status %d. Output:\n\n%s\n\n--\n\n",
-				status, output)
+			var prompt string
+			if failure.VerifyCommand != "" {
+				prompt = fmt.Sprintf("The changes were applied, then the project's verification command '%s' failed with exit status %d, so the changes were rolled back. Output:\n\n%s\n\n--\n\n",
+					failure.VerifyCommand, failure.Status, failure.Output)
+			} else {
+				prompt = fmt.Sprintf("Execution failed with exit status %d. Output:\n\n%s\n\n--\n\n",
+					failure.Status, failure.Output)
+			}
 
 			tellFlags.IsUserContinue = false
 
-			if execCommand != "" {
+			if failure.VerifyCommand != "" {
+				// the verification command runs again on the next apply, so there's no need for the model to write commands to check the fix
+				tellFlags.IsApplyDebug = false
+				tellFlags.IsUserDebug = true
+			} else if execCommand != "" {
 				tellFlags.IsApplyDebug = false
 				tellFlags.ExecEnabled = false
 				tellFlags.IsUserDebug = true
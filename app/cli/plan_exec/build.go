@@ -6,6 +6,7 @@ import (
 	"os"
 	"plandex-cli/api"
 	"plandex-cli/fs"
+	"plandex-cli/lib"
 	"plandex-cli/stream"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
@@ -58,14 +59,23 @@ func Build(params ExecParams, flags types.BuildFlags) (bool, error) {
 	// log.Println("API keys:", params.ApiKeys)
 	// log.Println("Legacy API key:", legacyApiKey)
 
+	var diagnosticsExts []string
+	if !buildBg {
+		diagnosticsExts, err = lib.GetDiagnosticsExts()
+		if err != nil {
+			term.OutputErrorAndExit("Error loading diagnostics config: %v", err)
+		}
+	}
+
 	apiErr = api.Client.BuildPlan(params.CurrentPlanId, params.CurrentBranch, shared.BuildPlanRequest{
-		ConnectStream: !buildBg,
-		ProjectPaths:  paths.ActivePaths,
-		ApiKey:        legacyApiKey, // deprecated
-		Endpoint:      openAIBase,   // deprecated
-		ApiKeys:       params.ApiKeys,
-		OpenAIBase:    openAIBase,
-		OpenAIOrgId:   openAIOrgId,
+		ConnectStream:   !buildBg,
+		ProjectPaths:    paths.ActivePaths,
+		ApiKey:          legacyApiKey, // deprecated
+		Endpoint:        openAIBase,   // deprecated
+		ApiKeys:         params.ApiKeys,
+		OpenAIBase:      openAIBase,
+		OpenAIOrgId:     openAIOrgId,
+		DiagnosticsExts: diagnosticsExts,
 	}, stream.OnStreamPlan)
 
 	term.StopSpinner()
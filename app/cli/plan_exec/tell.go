@@ -7,6 +7,7 @@ import (
 	"plandex-cli/api"
 	"plandex-cli/auth"
 	"plandex-cli/fs"
+	"plandex-cli/lib"
 	"plandex-cli/stream"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
@@ -95,6 +96,24 @@ func TellPlan(
 		os.Exit(0)
 	}
 
+	var execPolicy *shared.ExecPolicy
+	if execEnabled {
+		execPolicy, err = fs.LoadProjectExecPolicy()
+		if err != nil {
+			outputPromptIfTell()
+			term.OutputErrorAndExit("Error loading exec policy: %v", err)
+		}
+	}
+
+	var diagnosticsExts []string
+	if !tellBg {
+		diagnosticsExts, err = lib.GetDiagnosticsExts()
+		if err != nil {
+			outputPromptIfTell()
+			term.OutputErrorAndExit("Error loading diagnostics config: %v", err)
+		}
+	}
+
 	var fn func() bool
 	fn = func() bool {
 
@@ -132,6 +151,12 @@ func TellPlan(
 
 		isGitRepo := fs.ProjectRootIsGitRepo()
 
+		stream.SetResumeCredentials(&shared.ConnectPlanRequest{
+			ApiKeys:     params.ApiKeys,
+			OpenAIBase:  openAIBase,
+			OpenAIOrgId: openAIOrgId,
+		})
+
 		apiErr := api.Client.TellPlan(params.CurrentPlanId, params.CurrentBranch, shared.TellPlanRequest{
 			Prompt:                 prompt,
 			ConnectStream:          !tellBg,
@@ -153,6 +178,8 @@ func TellPlan(
 			IsImplementationOfChat: isImplementationOfChat,
 			IsGitRepo:              isGitRepo,
 			SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
+			ExecPolicy:             execPolicy,
+			DiagnosticsExts:        diagnosticsExts,
 		}, stream.OnStreamPlan)
 
 		term.StopSpinner()
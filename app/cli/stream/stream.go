@@ -8,32 +8,53 @@ import (
 	"plandex-cli/term"
 	"plandex-cli/types"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	shared "plandex-shared"
 )
 
 var OnStreamPlan types.OnStreamPlan
 
+// backoff between attempts to resume a dropped stream
+var reconnectDelays = []time.Duration{
+	1 * time.Second,
+	2 * time.Second,
+	4 * time.Second,
+	8 * time.Second,
+	16 * time.Second,
+}
+
+// seq of the last message passed to the stream UI
+var lastSeq atomic.Int64
+
+// set while resuming, so the stream's start message doesn't reset lastSeq
+var resuming atomic.Bool
+
+// sent when reconnecting, so if the server running the stream went away, the server that's reached can resume it
+var resumeCredentials *shared.ConnectPlanRequest
+
+// SetResumeCredentials is called with the credentials a tell was sent with, before it starts streaming
+func SetResumeCredentials(req *shared.ConnectPlanRequest) {
+	resumeCredentials = req
+}
+
 func init() {
 	OnStreamPlan = func(params types.OnStreamPlanParams) {
 		if params.Err != nil {
 			if strings.Contains(params.Err.Error(), "missing heartbeats") || strings.Contains(strings.ToLower(params.Err.Error()), "eof") {
 				log.Println("Error in stream:", params.Err)
+
+				if reconnect() {
+					return
+				}
+
 				streamtui.Send(shared.StreamMessage{
 					Type: shared.StreamMessageError,
 					Error: &shared.ApiError{
 						Msg: "Stream error: " + params.Err.Error(),
 					},
 				})
-
-				// try to reconnect
-				term.StartSpinner("Reconnecting...")
-				apiErr := api.Client.ConnectPlan(lib.CurrentPlanId, lib.CurrentBranch, OnStreamPlan)
-				term.StopSpinner()
-
-				if apiErr != nil {
-					log.Println("Error reconnecting to stream:", apiErr)
-				}
 			}
 
 			return
@@ -41,12 +62,95 @@ func init() {
 
 		if params.Msg.Type == shared.StreamMessageStart {
 			log.Println("Stream started")
+			if !resuming.CompareAndSwap(true, false) {
+				lastSeq.Store(0)
+			}
 			return
 		}
 
+		// the server couldn't resume and sent the full current state instead
+		if params.Msg.Type == shared.StreamMessageConnectActive {
+			lastSeq.Store(0)
+		}
+
+		if params.Msg.Seq > 0 {
+			// already received before the connection dropped
+			if params.Msg.Seq <= lastSeq.Load() {
+				return
+			}
+			lastSeq.Store(params.Msg.Seq)
+		}
+
 		// log.Println("Stream message:")
 		// log.Println(spew.Sdump(*params.Msg))
 
 		streamtui.Send(*params.Msg)
 	}
 }
+
+// reconnect resumes a dropped stream. It returns true if the stream was resumed or if the plan
+// had already stopped running, in which case the stream UI is sent the message that ends it.
+func reconnect() bool {
+	term.StartSpinner("Reconnecting...")
+	defer term.StopSpinner()
+
+	for i, delay := range reconnectDelays {
+		if endMsg := planStreamEndMessage(); endMsg != nil {
+			log.Println("Plan is no longer running, not reconnecting:", endMsg.Type)
+			resuming.Store(false)
+			streamtui.Send(*endMsg)
+			return true
+		}
+
+		time.Sleep(delay)
+
+		fromSeq := lastSeq.Load()
+		resuming.Store(fromSeq > 0)
+
+		log.Printf("Reconnecting to stream, attempt %d, resuming from seq %d\n", i+1, fromSeq)
+
+		apiErr := api.Client.ConnectPlan(lib.CurrentPlanId, lib.CurrentBranch, fromSeq, resumeCredentials, OnStreamPlan)
+		if apiErr == nil {
+			return true
+		}
+
+		log.Println("Error reconnecting to stream:", apiErr)
+	}
+
+	resuming.Store(false)
+	return false
+}
+
+// planStreamEndMessage checks the branch status and returns the message that ends the stream if the
+// plan finished, stopped, or errored while disconnected. It returns nil if the plan is still running or
+// the status can't be loaded.
+func planStreamEndMessage() *shared.StreamMessage {
+	branches, apiErr := api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
+		CurrentBranchByPlanId: map[string]string{lib.CurrentPlanId: lib.CurrentBranch},
+	})
+	if apiErr != nil {
+		log.Println("Error getting plan status:", apiErr)
+		return nil
+	}
+
+	branch := branches[lib.CurrentPlanId]
+	if branch == nil {
+		return nil
+	}
+
+	switch branch.Status {
+	case shared.PlanStatusFinished:
+		return &shared.StreamMessage{Type: shared.StreamMessageFinished}
+	case shared.PlanStatusStopped:
+		return &shared.StreamMessage{Type: shared.StreamMessageAborted}
+	case shared.PlanStatusError:
+		return &shared.StreamMessage{
+			Type: shared.StreamMessageError,
+			Error: &shared.ApiError{
+				Msg: "Plan stopped with an error while the stream was disconnected",
+			},
+		}
+	}
+
+	return nil
+}
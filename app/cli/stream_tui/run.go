@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"plandex-cli/lib"
 	"plandex-cli/term"
 	"sync"
 
@@ -46,6 +47,8 @@ func StartStreamUI(prompt string, buildOnly, canSendToBg bool) error {
 	log.Println("Bubbletea program finished")
 	wg.Done()
 
+	lib.StopLanguageServers()
+
 	log.Println("Stream UI finished")
 
 	if err != nil {
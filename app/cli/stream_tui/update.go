@@ -507,6 +507,20 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			}),
 		)
 
+	case shared.StreamMessageDiagnostics:
+		if msg.DiagnosticsRequest != nil {
+			return m, diagnosticsCmd(msg.DiagnosticsRequest)
+		}
+
+	case shared.StreamMessageWarning:
+		m.updateState(func() {
+			m.reply += "\n\n⚠️  " + msg.Warning + "\n\n"
+		})
+
+		if !deferUIUpdate {
+			m.updateReplyDisplay()
+		}
+
 	case shared.StreamMessageError:
 		log.Println("Stream message error:", spew.Sdump(msg))
 
@@ -746,6 +760,13 @@ type contextLoadDoneMsg struct {
 	err  error
 }
 
+func diagnosticsCmd(req *shared.DiagnosticsRequest) tea.Cmd {
+	return func() tea.Msg {
+		lib.RespondDiagnostics(req)
+		return nil
+	}
+}
+
 func loadContextCmd(loadContextFiles []string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -77,6 +77,7 @@ var CliCommands = []CmdConfig{
 
 	{"diff --ui", "", "review pending changes in a browser UI", true},
 	{"diff", "", "review pending changes in 'git diff' format", true},
+	{"review", "rv", "review pending changes one at a time—accept, reject, or edit each change", true},
 	{"diff --plain", "", "review pending changes in 'git diff' format with no color formatting", false},
 	{"summary", "", "show the latest summary of the current plan", true},
 
@@ -98,11 +99,20 @@ var CliCommands = []CmdConfig{
 	{"branches", "br", "list plan branches", true},
 	{"checkout", "co", "checkout or create a branch", true},
 	{"delete-branch", "dlb", "delete a branch by name or index", true},
+	{"mirror", "", "mirror the current branch to a git branch and worktree", true},
+	{"mirror off", "", "stop mirroring the current branch to git", true},
 
 	{"plans --archived", "", "list archived plans", true},
 	{"archive", "arc", "archive a plan", true},
 	{"unarchive", "unarc", "unarchive a plan", true},
 
+	{"share", "", "share the current plan with an org member", true},
+	{"unshare", "", "stop sharing the current plan with an org member", true},
+	{"plans --shared", "", "list plans shared with you", true},
+
+	{"export", "", "export a plan to a portable archive", true},
+	{"import", "", "import a plan from an archive", true},
+
 	{"models", "", "show current plan model settings", true},
 	{"models default", "", "show the default model settings for new plans", true},
 	{"models available", "", "show all available models", true},
@@ -133,6 +143,29 @@ var CliCommands = []CmdConfig{
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
+	{"roles", "", "list org roles", true},
+	{"roles show", "", "show a role's permissions", true},
+	{"roles create", "", "create a custom role with fine-grained permissions", true},
+	{"roles update", "", "update a custom role's permissions", true},
+	{"roles rm", "", "remove a custom role", true},
+	{"roles assign", "", "set the role of a user or pending invite", true},
+
+	{"tokens", "", "list your API tokens for the current org", true},
+	{"tokens create", "", "create an API token for headless use, like in CI", true},
+	{"tokens revoke", "", "revoke an API token", true},
+	{"service-accounts", "sa", "list service accounts for the current org", true},
+	{"service-accounts create", "", "create a service account for CI and other automation", true},
+	{"service-accounts rm", "", "remove a service account and revoke its tokens", true},
+
+	{"exec-policy", "", "show the org and project command execution policies", true},
+	{"exec-policy set", "", "set the org command execution policy from a JSON file", true},
+	{"exec-policy clear", "", "remove the org command execution policy", true},
+
+	{"webhooks", "", "list outgoing webhooks for the current org", true},
+	{"webhooks add", "", "add an outgoing webhook for plan events", true},
+	{"webhooks test", "", "send a test delivery to a webhook", true},
+	{"webhooks deliveries", "", "show recent deliveries for a webhook", true},
+	{"webhooks rm", "", "remove an outgoing webhook", true},
 
 	{"usage", "", "show Plandex Cloud current balance and usage report", true},
 	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
@@ -285,11 +318,11 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "share", "unshare", "plans --shared", "export", "import")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "apply", "reject")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "review", "apply", "reject")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Context ")
@@ -297,7 +330,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Branches ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch", "mirror", "mirror off")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " History ")
@@ -329,7 +362,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "roles", "roles create", "roles assign", "tokens", "tokens create", "tokens revoke", "service-accounts", "service-accounts create", "service-accounts rm")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
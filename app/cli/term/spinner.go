@@ -16,8 +16,18 @@ var startedAt time.Time
 var lastMessage string
 var active bool
 var currentWarningLoop int32
+var spinnerDisabled bool
+
+// DisableSpinner turns spinners into no-ops so that stdout only contains command output
+func DisableSpinner() {
+	spinnerDisabled = true
+}
 
 func StartSpinner(msg string) {
+	if spinnerDisabled {
+		return
+	}
+
 	if active {
 		if msg == lastMessage {
 			return
@@ -34,6 +44,10 @@ func StartSpinner(msg string) {
 }
 
 func StopSpinner() {
+	if spinnerDisabled {
+		return
+	}
+
 	elapsed := time.Since(startedAt)
 
 	if lastMessage != "" && elapsed < withMessageMinDuration {
@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"io"
 	shared "plandex-shared"
 
 	"github.com/shopspring/decimal"
@@ -24,6 +25,9 @@ type ApiClient interface {
 
 	CreateAccount(req shared.CreateAccountRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
 	SignIn(req shared.SignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
+	GetOidcConfig(customHost string) (*shared.OidcConfigResponse, *shared.ApiError)
+	StartOidcDeviceAuth(customHost string) (*shared.StartOidcDeviceAuthResponse, *shared.ApiError)
+	PollOidcDeviceAuth(req shared.PollOidcDeviceAuthRequest, customHost string) (*shared.PollOidcDeviceAuthResponse, *shared.ApiError)
 
 	SignOut() *shared.ApiError
 
@@ -33,14 +37,21 @@ type ApiClient interface {
 
 	ListUsers() (*shared.ListUsersResponse, *shared.ApiError)
 	DeleteUser(userId string) *shared.ApiError
+	SetUserRole(userId string, req shared.SetOrgRoleRequest) *shared.ApiError
 
 	ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError)
+	GetCurrentOrgRole() (*shared.OrgRole, *shared.ApiError)
+	ListOrgPermissions() ([]*shared.OrgPermission, *shared.ApiError)
+	CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	DeleteOrgRole(roleId string) *shared.ApiError
 
 	InviteUser(req shared.InviteRequest) *shared.ApiError
 	ListPendingInvites() ([]*shared.Invite, *shared.ApiError)
 	ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError)
 	ListAllInvites() ([]*shared.Invite, *shared.ApiError)
 	DeleteInvite(inviteId string) *shared.ApiError
+	SetInviteRole(inviteId string, req shared.SetOrgRoleRequest) *shared.ApiError
 
 	CreateProject(req shared.CreateProjectRequest) (*shared.CreateProjectResponse, *shared.ApiError)
 	ListProjects() ([]*shared.Project, *shared.ApiError)
@@ -50,19 +61,27 @@ type ApiClient interface {
 	ListPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError)
 	ListArchivedPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError)
 	ListPlansRunning(projectIds []string, includeRecent bool) (*shared.ListPlansRunningResponse, *shared.ApiError)
+	ListSharedPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError)
+
+	ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError)
+	SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError)
+	UnsharePlan(planId, userId string) *shared.ApiError
 
 	GetCurrentBranchByPlanId(projectId string, req shared.GetCurrentBranchByPlanIdRequest) (map[string]*shared.Branch, *shared.ApiError)
 
 	GetPlan(planId string) (*shared.Plan, *shared.ApiError)
 	CreatePlan(projectId string, req shared.CreatePlanRequest) (*shared.CreatePlanResponse, *shared.ApiError)
+	ExportPlan(planId string, w io.Writer) *shared.ApiError
+	ImportPlan(projectId string, archive io.ReadSeeker) (*shared.ImportPlanResponse, *shared.ApiError)
 
 	TellPlan(planId, branch string, req shared.TellPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	BuildPlan(planId, branch string, req shared.BuildPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	RespondMissingFile(planId, branch string, req shared.RespondMissingFileRequest) *shared.ApiError
+	RespondDiagnostics(planId, branch string, req shared.DiagnosticsResponse) *shared.ApiError
 
 	DeletePlan(planId string) *shared.ApiError
 	DeleteAllPlans(projectId string) *shared.ApiError
-	ConnectPlan(planId, branch string, onStreamPlan OnStreamPlan) *shared.ApiError
+	ConnectPlan(planId, branch string, resumeFromSeq int64, req *shared.ConnectPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	StopPlan(ctx context.Context, planId, branch string) *shared.ApiError
 
 	ArchivePlan(planId string) *shared.ApiError
@@ -74,6 +93,8 @@ type ApiClient interface {
 	ApplyPlan(planId, branch string, req shared.ApplyPlanRequest) (string, *shared.ApiError)
 	RejectAllChanges(planId, branch string) *shared.ApiError
 	RejectFile(planId, branch, filePath string) *shared.ApiError
+	RejectReplacement(planId, branch string, req shared.RejectReplacementRequest) *shared.ApiError
+	EditReplacement(planId, branch string, req shared.EditReplacementRequest) *shared.ApiError
 	RejectFiles(planId, branch string, paths []string) *shared.ApiError
 	GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError)
 
@@ -113,11 +134,38 @@ type ApiClient interface {
 	ListModelPacks() ([]*shared.ModelPack, *shared.ApiError)
 	DeleteModelPack(setId string) *shared.ApiError
 
+	ListSpendBudgets() (*shared.ListSpendBudgetsResponse, *shared.ApiError)
+	SetSpendBudget(req shared.SetSpendBudgetRequest) (*shared.SpendBudget, *shared.ApiError)
+	DeleteSpendBudget(budgetId string) *shared.ApiError
+
+	GetOrgExecPolicy() (*shared.ExecPolicy, *shared.ApiError)
+	SetOrgExecPolicy(policy shared.ExecPolicy) (*shared.ExecPolicy, *shared.ApiError)
+	DeleteOrgExecPolicy() *shared.ApiError
+
+	ListWebhooks() ([]*shared.Webhook, *shared.ApiError)
+	CreateWebhook(req shared.CreateWebhookRequest) (*shared.CreateWebhookResponse, *shared.ApiError)
+	DeleteWebhook(webhookId string) *shared.ApiError
+	TestWebhook(webhookId string) *shared.ApiError
+	ListWebhookDeliveries(webhookId string) (*shared.ListWebhookDeliveriesResponse, *shared.ApiError)
+
+	ListApiTokens(serviceAccountId string) ([]*shared.ApiToken, *shared.ApiError)
+	CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError)
+	RevokeApiToken(tokenId string) *shared.ApiError
+	GetApiTokenSession() (*shared.ApiTokenSessionResponse, *shared.ApiError)
+
+	ListServiceAccounts() ([]*shared.ServiceAccount, *shared.ApiError)
+	CreateServiceAccount(req shared.CreateServiceAccountRequest) (*shared.ServiceAccount, *shared.ApiError)
+	DeleteServiceAccount(serviceAccountId string) *shared.ApiError
+
+	GetUsageSummary(req shared.UsageRequest) (*shared.UsageSummaryResponse, *shared.ApiError)
+	GetUsageLog(pageSize, pageNum int, req shared.UsageRequest) (*shared.UsageLogResponse, *shared.ApiError)
+
 	GetCreditsTransactions(pageSize, pageNum int, req shared.CreditsLogRequest) (*shared.CreditsLogResponse, *shared.ApiError)
 	GetCreditsSummary(req shared.CreditsLogRequest) (*shared.CreditsSummaryResponse, *shared.ApiError)
 	GetBalance() (decimal.Decimal, *shared.ApiError)
 
 	GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError)
+	GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError)
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	GetBuildStatus(planId, branch string) (*shared.GetBuildStatusResponse, *shared.ApiError)
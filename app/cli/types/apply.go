@@ -2,6 +2,8 @@ package types
 
 import (
 	"os"
+
+	shared "plandex-shared"
 )
 
 type ApplyFlags struct {
@@ -11,6 +13,8 @@ type ApplyFlags struct {
 	AutoExec    bool
 	NoExec      bool
 	AutoDebug   int
+	ExecSandbox shared.ExecSandboxConfig
+	NoVerify    bool
 }
 
 type ApplyRollbackOption string
@@ -20,7 +24,16 @@ const (
 	ApplyRollbackOptionRollback ApplyRollbackOption = "Roll back file changes"
 )
 
-type OnApplyExecFailFn func(status int, output string, attempt int, toRollback *ApplyRollbackPlan, onErr OnErrFn, onSuccess func())
+// ApplyFailure is a failed run of _apply.sh, or of the project's verification command after the files were written
+type ApplyFailure struct {
+	Status int
+	Output string
+
+	// set if the verification command failed
+	VerifyCommand string
+}
+
+type OnApplyExecFailFn func(failure ApplyFailure, attempt int, toRollback *ApplyRollbackPlan, onErr OnErrFn, onSuccess func())
 
 type ApplyReversion struct {
 	Content string
@@ -16,20 +16,23 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	Symbols           []string
 }
 
 type ContextOutdatedResult struct {
-	Msg             string
-	UpdatedContexts []*shared.Context
-	RemovedContexts []*shared.Context
-	TokenDiffsById  map[string]int
-	NumFiles        int
-	NumUrls         int
-	NumTrees        int
-	NumMaps         int
-	NumFilesRemoved int
-	NumTreesRemoved int
-	ReqFn           func() (map[string]*shared.UpdateContextParams, error)
+	Msg               string
+	UpdatedContexts   []*shared.Context
+	RemovedContexts   []*shared.Context
+	TokenDiffsById    map[string]int
+	NumFiles          int
+	NumUrls           int
+	NumTrees          int
+	NumMaps           int
+	NumSymbols        int
+	NumFilesRemoved   int
+	NumTreesRemoved   int
+	NumSymbolsRemoved int
+	ReqFn             func() (map[string]*shared.UpdateContextParams, error)
 }
 
 const (
@@ -52,9 +55,17 @@ type CurrentProjectSettings struct {
 	Id string `json:"id"`
 }
 
+type GitMirror struct {
+	GitBranch string `json:"gitBranch"`
+	Worktree  string `json:"worktree"`
+	BaseSha   string `json:"baseSha"`
+	Prefix    string `json:"prefix"`
+}
+
 type CurrentPlanSettingsByAccount map[string]*CurrentPlanSettings
 type PlanSettingsByAccount map[string]*PlanSettings
 type CurrentProjectSettingsByAccount map[string]*CurrentProjectSettings
+type GitMirrorsByBranch map[string]*GitMirror
 
 type ChangesUIScrollReplacement struct {
 	OldContent        string
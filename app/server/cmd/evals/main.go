@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"plandex-server/evals"
+	"plandex-server/model"
+	"plandex-server/shutdown"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// Runs the evals suites and writes a report comparing model packs. Run from app/server so the db package finds its .env:
+//
+//	go run ./cmd/evals --packs daily-driver,strong --transport record
+//	go run ./cmd/evals --packs daily-driver,strong --transport replay
+//
+// Live and record runs read the builder models' API keys from the environment.
+func main() {
+	suitesDir := flag.String("suites", "../../test/evals/suites", "directory of eval suites")
+	suiteNames := flag.String("suite", "", "comma-separated suites to run (default all)")
+	packNames := flag.String("packs", "", "comma-separated built-in model packs to compare against the apply-only baseline")
+	transport := flag.String("transport", "live", "model responses: live, record, or replay")
+	fixturesDir := flag.String("fixtures", "../../test/evals/fixtures", "directory for recorded model responses")
+	outDir := flag.String("out", "evals-out", "directory for the report")
+	flag.Parse()
+
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+
+	var mode model.ModelTransportMode
+	switch *transport {
+	case "live":
+		mode = model.ModelTransportLive
+	case "record":
+		mode = model.ModelTransportRecord
+	case "replay":
+		mode = model.ModelTransportReplay
+	default:
+		log.Fatalf("Unknown transport %q--use live, record, or replay", *transport)
+	}
+	model.SetModelTransport(mode, *fixturesDir)
+
+	suites, err := evals.LoadSuites(*suitesDir, splitList(*suiteNames))
+	if err != nil {
+		log.Fatalf("Error loading suites: %v", err)
+	}
+
+	packs, err := getModelPacks(splitList(*packNames))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	clients, err := initClients(packs, mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// the builder's active plans hang off the shutdown context, as they do in the server
+	shutdown.ShutdownCtx = ctx
+
+	report, err := evals.Run(ctx, evals.RunParams{
+		Suites:    suites,
+		Packs:     packs,
+		Clients:   clients,
+		Transport: mode,
+	})
+	if err != nil {
+		log.Fatalf("Error running evals: %v", err)
+	}
+
+	err = report.Write(*outDir)
+	if err != nil {
+		log.Fatalf("Error writing report: %v", err)
+	}
+
+	fmt.Println(report.Markdown())
+	fmt.Printf("Report written to %s\n", *outDir)
+}
+
+func getModelPacks(names []string) ([]*shared.ModelPack, error) {
+	var packs []*shared.ModelPack
+	for _, name := range names {
+		var found *shared.ModelPack
+		for _, pack := range shared.BuiltInModelPacks {
+			if pack.Name == name {
+				found = pack
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("unknown model pack %q", name)
+		}
+		packs = append(packs, found)
+	}
+	return packs, nil
+}
+
+// initClients creates a client for each API key the packs' builders use. Replayed responses never reach the provider, so replay runs use placeholder keys.
+func initClients(packs []*shared.ModelPack, mode model.ModelTransportMode) (map[string]model.ClientInfo, error) {
+	apiKeys := map[string]string{}
+	endpoints := map[string]string{}
+
+	for _, pack := range packs {
+		envVars := pack.Builder.GetRequiredEnvVars()
+
+		var missing []string
+		for envVar := range envVars.RequiresAll {
+			if !addApiKey(apiKeys, envVar, mode) {
+				missing = append(missing, envVar)
+			}
+		}
+
+		if len(envVars.RequiresEither) > 0 {
+			var found bool
+			var either []string
+			for envVar := range envVars.RequiresEither {
+				if addApiKey(apiKeys, envVar, mode) {
+					found = true
+				}
+				either = append(either, envVar)
+			}
+			if !found {
+				sort.Strings(either)
+				missing = append(missing, strings.Join(either, " or "))
+			}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, fmt.Errorf("model pack %s needs %s", pack.Name, strings.Join(missing, ", "))
+		}
+
+		for envVar := range apiKeys {
+			baseModelConfig := pack.Builder.BaseModelConfigForEnvVar(envVar)
+			if baseModelConfig != nil && baseModelConfig.BaseUrl != "" {
+				endpoints[envVar] = baseModelConfig.BaseUrl
+			}
+		}
+	}
+
+	return model.InitClients(apiKeys, endpoints, os.Getenv("OPENAI_API_BASE"), os.Getenv("OPENAI_ORG_ID")), nil
+}
+
+func addApiKey(apiKeys map[string]string, envVar string, mode model.ModelTransportMode) bool {
+	if envVar == "" {
+		return true
+	}
+	if apiKey := os.Getenv(envVar); apiKey != "" {
+		apiKeys[envVar] = apiKey
+		return true
+	}
+	if mode == model.ModelTransportReplay {
+		apiKeys[envVar] = "replay"
+		return true
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
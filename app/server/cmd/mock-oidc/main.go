@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/oidc/oidctest"
+)
+
+// Runs a mock OpenID Connect issuer for trying SSO locally. Point a dev server at it with:
+//
+//	OIDC_ISSUER=http://localhost:8098 OIDC_CLIENT_ID=plandex
+//
+// then run 'plandex sign-in' and approve the device at http://localhost:8098/device with any email and groups.
+func main() {
+	port := flag.Int("port", 8098, "port to listen on")
+	clientId := flag.String("client-id", "plandex", "client id the server must use")
+	flag.Parse()
+
+	url := fmt.Sprintf("http://localhost:%d", *port)
+
+	issuer, err := oidctest.NewIssuer(url, *clientId)
+	if err != nil {
+		log.Fatalf("Error creating issuer: %v", err)
+	}
+
+	log.Printf("Mock OIDC issuer listening on %s (client id %s)\n", url, *clientId)
+	log.Printf("Approve devices at %s/device\n", url)
+
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), issuer))
+}
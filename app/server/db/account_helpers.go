@@ -43,7 +43,7 @@ func CreateAccount(name, email, emailVerificationId string, tx *sqlx.Tx) (*Creat
 	}
 
 	// add to org matching domain if one exists and auto add domain users is true for that org
-	orgId, err := AddToOrgForDomain(domain, userId, tx)
+	orgId, err := AddToOrgForDomain(userId, domain, tx)
 
 	if err != nil {
 		return nil, fmt.Errorf("error adding user to org for domain: %v", err)
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const apiTokenDisplayPrefixLen = 12
+
+// NewApiToken generates a token and fills in its hash and display prefix. The token itself is never stored.
+func NewApiToken(apiToken *ApiToken) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating api token: %v", err)
+	}
+
+	token := shared.ApiTokenPrefix + hex.EncodeToString(bytes)
+	apiToken.TokenHash = hashApiToken(token)
+	apiToken.TokenPrefix = token[:apiTokenDisplayPrefixLen]
+
+	return token, nil
+}
+
+func hashApiToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
+
+func CreateApiToken(apiToken *ApiToken) error {
+	query := `INSERT INTO api_tokens (org_id, user_id, service_account_id, name, token_hash, token_prefix, scopes, expires_at, created_by)
+	VALUES (:org_id, :user_id, :service_account_id, :name, :token_hash, :token_prefix, :scopes, :expires_at, :created_by)
+	RETURNING id, created_at, updated_at`
+
+	rows, err := Conn.NamedQuery(query, apiToken)
+	if err != nil {
+		return fmt.Errorf("error creating api token: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&apiToken.Id, &apiToken.CreatedAt, &apiToken.UpdatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error creating api token: %v", err)
+	}
+
+	return nil
+}
+
+// ValidateApiToken returns the token if it exists and hasn't been revoked or expired
+func ValidateApiToken(token string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())", hashApiToken(token))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invalid token")
+		}
+		return nil, fmt.Errorf("error validating api token: %v", err)
+	}
+
+	// only record use once a minute so a busy pipeline doesn't write on every request
+	if apiToken.LastUsedAt == nil || time.Since(*apiToken.LastUsedAt) > time.Minute {
+		_, err = Conn.Exec("UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1", apiToken.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error updating api token last used: %v", err)
+		}
+	}
+
+	return &apiToken, nil
+}
+
+// ListApiTokens lists a user's unrevoked tokens in the org--a person's personal access tokens, or a service account's tokens
+func ListApiTokens(orgId, userId string) ([]*ApiToken, error) {
+	var apiTokens []*ApiToken
+	err := Conn.Select(&apiTokens, "SELECT * FROM api_tokens WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL ORDER BY created_at", orgId, userId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing api tokens: %v", err)
+	}
+
+	return apiTokens, nil
+}
+
+func GetApiToken(orgId, id string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE org_id = $1 AND id = $2 AND revoked_at IS NULL", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+func RevokeApiToken(orgId, id string) error {
+	res, err := Conn.Exec("UPDATE api_tokens SET revoked_at = NOW() WHERE org_id = $1 AND id = $2 AND revoked_at IS NULL", orgId, id)
+	if err != nil {
+		return fmt.Errorf("error revoking api token: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ErrServiceAccountExists is returned by CreateServiceAccount when the org already has a service account with the name
+var ErrServiceAccountExists = errors.New("service account already exists")
+
+func ListServiceAccounts(orgId string) ([]*ServiceAccount, error) {
+	var accounts []*ServiceAccount
+	err := Conn.Select(&accounts, `SELECT service_accounts.*, orgs_users.org_role_id FROM service_accounts
+	JOIN orgs_users ON orgs_users.user_id = service_accounts.user_id AND orgs_users.org_id = service_accounts.org_id
+	WHERE service_accounts.org_id = $1 ORDER BY service_accounts.created_at`, orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing service accounts: %v", err)
+	}
+
+	return accounts, nil
+}
+
+func GetServiceAccount(orgId, id string) (*ServiceAccount, error) {
+	var account ServiceAccount
+	err := Conn.Get(&account, `SELECT service_accounts.*, orgs_users.org_role_id FROM service_accounts
+	JOIN orgs_users ON orgs_users.user_id = service_accounts.user_id AND orgs_users.org_id = service_accounts.org_id
+	WHERE service_accounts.org_id = $1 AND service_accounts.id = $2`, orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting service account: %v", err)
+	}
+
+	return &account, nil
+}
+
+// CreateServiceAccount creates the account's user and adds it to the org with the account's role
+func CreateServiceAccount(ctx context.Context, account *ServiceAccount) error {
+	return WithTx(ctx, "create service account", func(tx *sqlx.Tx) error {
+		// service account users can't sign in--their email is only there because users need one
+		email := fmt.Sprintf("%s@service-accounts.plandex.internal", uuid.New().String())
+
+		var userId string
+		err := tx.QueryRow("INSERT INTO users (name, email, domain, is_service_account) VALUES ($1, $2, $3, TRUE) RETURNING id", account.Name, email, "service-accounts.plandex.internal").Scan(&userId)
+		if err != nil {
+			return fmt.Errorf("error creating service account user: %v", err)
+		}
+		account.UserId = userId
+
+		err = CreateOrgUser(account.OrgId, userId, account.OrgRoleId, tx)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.NamedQuery(`INSERT INTO service_accounts (org_id, user_id, name, created_by)
+		VALUES (:org_id, :user_id, :name, :created_by)
+		RETURNING id, created_at, updated_at`, account)
+		if err != nil {
+			if IsNonUniqueErr(err) {
+				return ErrServiceAccountExists
+			}
+			return fmt.Errorf("error creating service account: %v", err)
+		}
+		defer rows.Close()
+
+		if rows.Next() {
+			err = rows.Scan(&account.Id, &account.CreatedAt, &account.UpdatedAt)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error creating service account: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteServiceAccount removes the account from the org and deletes its tokens. Its user is kept so the plans it owns stay intact.
+func DeleteServiceAccount(ctx context.Context, account *ServiceAccount) error {
+	return WithTx(ctx, "delete service account", func(tx *sqlx.Tx) error {
+		err := DeleteOrgUser(account.OrgId, account.UserId, tx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("DELETE FROM service_accounts WHERE id = $1", account.Id)
+		if err != nil {
+			return fmt.Errorf("error deleting service account: %v", err)
+		}
+
+		return nil
+	})
+}
@@ -163,12 +163,14 @@ func ValidateSignInCode(pin string) (*ValidateSignInCodeRes, error) {
 func GetUserPermissions(userId, orgId string) ([]string, error) {
 	var permissions []string
 
+	// the default roles are shared across orgs and hold role-scoped permissions for every org's custom roles, so only keep those for this org's roles
 	query := `
     SELECT p.name, p.resource_id 
     FROM permissions p
     JOIN org_roles_permissions orp ON p.id = orp.permission_id
     JOIN orgs_users ou ON orp.org_role_id = ou.org_role_id
     WHERE ou.user_id = $1 AND ou.org_id = $2
+    AND (p.resource_id IS NULL OR p.resource_id IN (SELECT id FROM org_roles WHERE org_id IS NULL OR org_id = $2))
     `
 
 	rows, err := Conn.Query(query, userId, orgId)
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetSpendSince sums model spend for an org since a point in time, optionally narrowed to a single user or plan
+func GetSpendSince(orgId string, userId, planId *string, since time.Time) (decimal.Decimal, error) {
+	query := "SELECT COALESCE(SUM(cost), 0) FROM model_usage WHERE org_id = $1 AND created_at >= $2"
+	args := []interface{}{orgId, since}
+
+	if userId != nil {
+		args = append(args, *userId)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+
+	if planId != nil {
+		args = append(args, *planId)
+		query += fmt.Sprintf(" AND plan_id = $%d", len(args))
+	}
+
+	var spend decimal.Decimal
+	err := Conn.Get(&spend, query, args...)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("error getting spend: %v", err)
+	}
+
+	return spend, nil
+}
+
+func ListSpendBudgets(orgId string) ([]*SpendBudget, error) {
+	var budgets []*SpendBudget
+	err := Conn.Select(&budgets, "SELECT * FROM spend_budgets WHERE org_id = $1 ORDER BY scope, period, created_at", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing spend budgets: %v", err)
+	}
+
+	return budgets, nil
+}
+
+func GetSpendBudget(orgId, id string) (*SpendBudget, error) {
+	var budget SpendBudget
+	err := Conn.Get(&budget, "SELECT * FROM spend_budgets WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting spend budget: %v", err)
+	}
+
+	return &budget, nil
+}
+
+// GetSpendBudgetsForRequest returns the budgets that apply to a model request by a user on a plan. Where a plan or user has its own budget for a period, it replaces the default budget for that scope and period.
+func GetSpendBudgetsForRequest(orgId, userId, planId string) ([]*SpendBudget, error) {
+	var budgets []*SpendBudget
+	query := `SELECT * FROM spend_budgets WHERE org_id = $1 AND (
+		scope = 'org' OR
+		(scope = 'user' AND (user_id = $2 OR user_id IS NULL)) OR
+		(scope = 'plan' AND (plan_id = $3 OR plan_id IS NULL))
+	)`
+
+	var planIdArg *string
+	if planId != "" {
+		planIdArg = &planId
+	}
+
+	err := Conn.Select(&budgets, query, orgId, userId, planIdArg)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spend budgets for request: %v", err)
+	}
+
+	hasSpecific := map[string]bool{}
+	for _, budget := range budgets {
+		if budget.PlanId != nil || budget.UserId != nil {
+			hasSpecific[string(budget.Scope)+"|"+string(budget.Period)] = true
+		}
+	}
+
+	res := []*SpendBudget{}
+	for _, budget := range budgets {
+		isDefault := budget.Scope != shared.SpendBudgetScopeOrg && budget.PlanId == nil && budget.UserId == nil
+		if isDefault && hasSpecific[string(budget.Scope)+"|"+string(budget.Period)] {
+			continue
+		}
+		res = append(res, budget)
+	}
+
+	return res, nil
+}
+
+func SetSpendBudget(budget *SpendBudget) error {
+	query := `INSERT INTO spend_budgets (org_id, scope, plan_id, user_id, period, limit_usd, warn_pct)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (
+		org_id,
+		scope,
+		COALESCE(plan_id, '00000000-0000-0000-0000-000000000000'::uuid),
+		COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid),
+		period
+	) DO UPDATE SET limit_usd = EXCLUDED.limit_usd, warn_pct = EXCLUDED.warn_pct
+	RETURNING id, created_at, updated_at`
+
+	err := Conn.QueryRow(query, budget.OrgId, budget.Scope, budget.PlanId, budget.UserId, budget.Period, budget.LimitUsd, budget.WarnPct).Scan(&budget.Id, &budget.CreatedAt, &budget.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error setting spend budget: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteSpendBudget(orgId, id string) error {
+	_, err := Conn.Exec("DELETE FROM spend_budgets WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		return fmt.Errorf("error deleting spend budget: %v", err)
+	}
+
+	return nil
+}
+
+// GetSpendForBudget is the current period's spend that counts against a budget—for default plan and user budgets, that's the spend of the given plan or user
+func GetSpendForBudget(budget *SpendBudget, userId, planId string, now time.Time) (decimal.Decimal, error) {
+	since := budget.Period.Start(now)
+
+	switch budget.Scope {
+	case shared.SpendBudgetScopeUser:
+		if budget.UserId != nil {
+			userId = *budget.UserId
+		}
+		return GetSpendSince(budget.OrgId, &userId, nil, since)
+	case shared.SpendBudgetScopePlan:
+		if budget.PlanId != nil {
+			planId = *budget.PlanId
+		}
+		return GetSpendSince(budget.OrgId, nil, &planId, since)
+	}
+
+	return GetSpendSince(budget.OrgId, nil, nil, since)
+}
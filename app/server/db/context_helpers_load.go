@@ -309,6 +309,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					Name:            loadParams.Name,
 					Url:             loadParams.Url,
 					FilePath:        loadParams.FilePath,
+					Symbol:          loadParams.Symbol,
 					NumTokens:       numTokensByTempId[tempId],
 					Sha:             sha,
 					Body:            loadParams.Body,
@@ -120,6 +120,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	numUrls := 0
 	numTrees := 0
 	numMaps := 0
+	numSymbols := 0
 
 	var mu sync.Mutex
 	errCh := make(chan error, len(*req))
@@ -184,6 +185,8 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				numTrees++
 			case shared.ContextMapType:
 				numMaps++
+			case shared.ContextSymbolType:
+				numSymbols++
 			}
 
 			errCh <- nil
@@ -348,6 +351,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumSymbols:      numSymbols,
 		MaxTokens:       plannerMaxTokens,
 	}
 
@@ -361,6 +365,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumTrees:    numTrees,
 		NumUrls:     numUrls,
 		NumMaps:     numMaps,
+		NumSymbols:  numSymbols,
 		TokensDiff:  aggregateTokensDiff,
 		TotalTokens: totalTokens,
 	}) + "\n\n" + shared.TableForContextUpdate(updateRes)
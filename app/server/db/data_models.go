@@ -6,6 +6,7 @@ import (
 	shared "plandex-shared"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/shopspring/decimal"
 )
 
 // The models below should only be used server-side.
@@ -49,6 +50,7 @@ type User struct {
 	Domain            string             `db:"domain"`
 	NumNonDraftPlans  int                `db:"num_non_draft_plans"`
 	DefaultPlanConfig *shared.PlanConfig `db:"default_plan_config"`
+	IsServiceAccount  bool               `db:"is_service_account"`
 	CreatedAt         time.Time          `db:"created_at"`
 	UpdatedAt         time.Time          `db:"updated_at"`
 }
@@ -60,6 +62,7 @@ func (user *User) ToApi() *shared.User {
 		Email:             user.Email,
 		NumNonDraftPlans:  user.NumNonDraftPlans,
 		IsTrial:           false, // legacy field
+		IsServiceAccount:  user.IsServiceAccount,
 		DefaultPlanConfig: user.DefaultPlanConfig,
 	}
 }
@@ -248,21 +251,49 @@ type OrgRole struct {
 func (role *OrgRole) ToApi() *shared.OrgRole {
 	return &shared.OrgRole{
 		Id:          role.Id,
+		Name:        role.Name,
 		IsDefault:   role.OrgId == nil,
 		Label:       role.Label,
 		Description: role.Description,
 	}
 }
 
+type Permission struct {
+	Id          string    `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	ResourceId  *string   `db:"resource_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (permission *Permission) Key() string {
+	var resourceId string
+	if permission.ResourceId != nil {
+		resourceId = *permission.ResourceId
+	}
+	return shared.PermissionKey(shared.Permission(permission.Name), resourceId)
+}
+
+func (permission *Permission) ToApi() *shared.OrgPermission {
+	return &shared.OrgPermission{
+		Key:         permission.Key(),
+		Description: permission.Description,
+	}
+}
+
 type ModelStream struct {
 	Id              string     `db:"id"`
 	OrgId           string     `db:"org_id"`
 	PlanId          string     `db:"plan_id"`
+	UserId          *string    `db:"user_id"`
 	InternalIp      string     `db:"internal_ip"`
 	Branch          string     `db:"branch"`
 	LastHeartbeatAt time.Time  `db:"last_heartbeat_at"`
 	CreatedAt       time.Time  `db:"created_at"`
 	FinishedAt      *time.Time `db:"finished_at"`
+
+	// set for tell streams, which can be resumed by another instance--see ClaimOrphanedModelStream
+	ResumeRequest *shared.TellPlanRequest `db:"resume_request"`
 }
 
 // type ModelStreamSubscription struct {
@@ -372,6 +403,264 @@ func (model *AvailableModel) ToApi() *shared.AvailableModel {
 	}
 }
 
+type ModelUsage struct {
+	Id              string               `db:"id"`
+	OrgId           string               `db:"org_id"`
+	UserId          *string              `db:"user_id"`
+	PlanId          *string              `db:"plan_id"`
+	ModelId         *string              `db:"model_id"`
+	ModelProvider   shared.ModelProvider `db:"model_provider"`
+	ModelName       shared.ModelName     `db:"model_name"`
+	ModelRole       *string              `db:"model_role"`
+	ModelPackName   *string              `db:"model_pack_name"`
+	Purpose         *string              `db:"purpose"`
+	InputTokens     int                  `db:"input_tokens"`
+	OutputTokens    int                  `db:"output_tokens"`
+	CachedTokens    int                  `db:"cached_tokens"`
+	ReasoningTokens int                  `db:"reasoning_tokens"`
+	Cost            decimal.Decimal      `db:"cost"`
+	CacheSavings    decimal.Decimal      `db:"cache_savings"`
+	GenerationId    *string              `db:"generation_id"`
+	BuildId         *string              `db:"build_id"`
+	ConvoMessageId  *string              `db:"convo_message_id"`
+	SessionId       *string              `db:"session_id"`
+	StoppedEarly    bool                 `db:"stopped_early"`
+	UserCancelled   bool                 `db:"user_cancelled"`
+	HadError        bool                 `db:"had_error"`
+	NoReportedUsage bool                 `db:"no_reported_usage"`
+	CreatedAt       time.Time            `db:"created_at"`
+}
+
+func (usage *ModelUsage) ToApi() *shared.UsageEntry {
+	entry := &shared.UsageEntry{
+		Id:              usage.Id,
+		UserId:          usage.UserId,
+		PlanId:          usage.PlanId,
+		ModelProvider:   usage.ModelProvider,
+		ModelName:       usage.ModelName,
+		InputTokens:     usage.InputTokens,
+		OutputTokens:    usage.OutputTokens,
+		CachedTokens:    usage.CachedTokens,
+		ReasoningTokens: usage.ReasoningTokens,
+		Cost:            usage.Cost,
+		CacheSavings:    usage.CacheSavings,
+		StoppedEarly:    usage.StoppedEarly,
+		UserCancelled:   usage.UserCancelled,
+		HadError:        usage.HadError,
+		NoReportedUsage: usage.NoReportedUsage,
+		CreatedAt:       usage.CreatedAt,
+	}
+	if usage.ModelRole != nil {
+		entry.ModelRole = shared.ModelRole(*usage.ModelRole)
+	}
+	if usage.ModelPackName != nil {
+		entry.ModelPackName = *usage.ModelPackName
+	}
+	if usage.Purpose != nil {
+		entry.Purpose = *usage.Purpose
+	}
+	return entry
+}
+
+type SpendBudget struct {
+	Id        string                   `db:"id"`
+	OrgId     string                   `db:"org_id"`
+	Scope     shared.SpendBudgetScope  `db:"scope"`
+	PlanId    *string                  `db:"plan_id"`
+	UserId    *string                  `db:"user_id"`
+	Period    shared.SpendBudgetPeriod `db:"period"`
+	LimitUsd  decimal.Decimal          `db:"limit_usd"`
+	WarnPct   int                      `db:"warn_pct"`
+	CreatedAt time.Time                `db:"created_at"`
+	UpdatedAt time.Time                `db:"updated_at"`
+}
+
+func (budget *SpendBudget) ToApi() *shared.SpendBudget {
+	return &shared.SpendBudget{
+		Id:        budget.Id,
+		OrgId:     budget.OrgId,
+		Scope:     budget.Scope,
+		PlanId:    budget.PlanId,
+		UserId:    budget.UserId,
+		Period:    budget.Period,
+		LimitUsd:  budget.LimitUsd,
+		WarnPct:   budget.WarnPct,
+		CreatedAt: budget.CreatedAt,
+		UpdatedAt: budget.UpdatedAt,
+	}
+}
+
+type Webhook struct {
+	Id        string                  `db:"id"`
+	OrgId     string                  `db:"org_id"`
+	Url       string                  `db:"url"`
+	Secret    string                  `db:"secret"`
+	Events    shared.WebhookEventList `db:"events"`
+	CreatedBy *string                 `db:"created_by"`
+	CreatedAt time.Time               `db:"created_at"`
+	UpdatedAt time.Time               `db:"updated_at"`
+}
+
+func (webhook *Webhook) ToApi() *shared.Webhook {
+	return &shared.Webhook{
+		Id:        webhook.Id,
+		OrgId:     webhook.OrgId,
+		Url:       webhook.Url,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	Id             string                       `db:"id"`
+	WebhookId      string                       `db:"webhook_id"`
+	OrgId          string                       `db:"org_id"`
+	Event          shared.WebhookEvent          `db:"event"`
+	Payload        string                       `db:"payload"`
+	Status         shared.WebhookDeliveryStatus `db:"status"`
+	Attempts       int                          `db:"attempts"`
+	LastStatusCode *int                         `db:"last_status_code"`
+	LastError      *string                      `db:"last_error"`
+	NextAttemptAt  time.Time                    `db:"next_attempt_at"`
+	DeliveredAt    *time.Time                   `db:"delivered_at"`
+	CreatedAt      time.Time                    `db:"created_at"`
+	UpdatedAt      time.Time                    `db:"updated_at"`
+}
+
+func (delivery *WebhookDelivery) ToApi() *shared.WebhookDelivery {
+	return &shared.WebhookDelivery{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+type PlanShare struct {
+	Id        string                 `db:"id"`
+	OrgId     string                 `db:"org_id"`
+	PlanId    string                 `db:"plan_id"`
+	UserId    string                 `db:"user_id"`
+	Access    shared.PlanShareAccess `db:"access"`
+	CreatedBy *string                `db:"created_by"`
+	CreatedAt time.Time              `db:"created_at"`
+	UpdatedAt time.Time              `db:"updated_at"`
+
+	// joined from users when listing
+	UserEmail string `db:"user_email"`
+	UserName  string `db:"user_name"`
+}
+
+func (share *PlanShare) ToApi() *shared.PlanShare {
+	return &shared.PlanShare{
+		Id:        share.Id,
+		PlanId:    share.PlanId,
+		UserId:    share.UserId,
+		UserEmail: share.UserEmail,
+		UserName:  share.UserName,
+		Access:    share.Access,
+		CreatedBy: share.CreatedBy,
+		CreatedAt: share.CreatedAt,
+		UpdatedAt: share.UpdatedAt,
+	}
+}
+
+// SharedPlan is a plan listed for a user it's shared with, along with their access
+type SharedPlan struct {
+	Plan
+	Access shared.PlanShareAccess `db:"access"`
+}
+
+func (sharedPlan *SharedPlan) ToApi() *shared.Plan {
+	apiPlan := sharedPlan.Plan.ToApi()
+	apiPlan.SharedAccess = sharedPlan.Access
+	return apiPlan
+}
+
+type ServiceAccount struct {
+	Id        string    `db:"id"`
+	OrgId     string    `db:"org_id"`
+	UserId    string    `db:"user_id"`
+	Name      string    `db:"name"`
+	CreatedBy *string   `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	// joined from orgs_users when listing
+	OrgRoleId string `db:"org_role_id"`
+}
+
+func (account *ServiceAccount) ToApi() *shared.ServiceAccount {
+	return &shared.ServiceAccount{
+		Id:        account.Id,
+		OrgId:     account.OrgId,
+		UserId:    account.UserId,
+		Name:      account.Name,
+		OrgRoleId: account.OrgRoleId,
+		CreatedBy: account.CreatedBy,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+type ApiToken struct {
+	Id               string                   `db:"id"`
+	OrgId            string                   `db:"org_id"`
+	UserId           string                   `db:"user_id"`
+	ServiceAccountId *string                  `db:"service_account_id"`
+	Name             string                   `db:"name"`
+	TokenHash        string                   `db:"token_hash"`
+	TokenPrefix      string                   `db:"token_prefix"`
+	Scopes           shared.ApiTokenScopeList `db:"scopes"`
+	ExpiresAt        *time.Time               `db:"expires_at"`
+	LastUsedAt       *time.Time               `db:"last_used_at"`
+	RevokedAt        *time.Time               `db:"revoked_at"`
+	CreatedBy        *string                  `db:"created_by"`
+	CreatedAt        time.Time                `db:"created_at"`
+	UpdatedAt        time.Time                `db:"updated_at"`
+}
+
+func (token *ApiToken) ToApi() *shared.ApiToken {
+	return &shared.ApiToken{
+		Id:               token.Id,
+		OrgId:            token.OrgId,
+		UserId:           token.UserId,
+		ServiceAccountId: token.ServiceAccountId,
+		Name:             token.Name,
+		TokenPrefix:      token.TokenPrefix,
+		Scopes:           token.Scopes,
+		ExpiresAt:        token.ExpiresAt,
+		LastUsedAt:       token.LastUsedAt,
+		CreatedBy:        token.CreatedBy,
+		CreatedAt:        token.CreatedAt,
+	}
+}
+
+// SemanticChunk is a definition-sized piece of a project file in the semantic index. Terms are the chunk's normalized tokens, space-separated, for bm25 ranking. The pgvector embedding isn't loaded--chunks are ranked by embedding in postgres, which sets Score.
+type SemanticChunk struct {
+	Id             string    `db:"id"`
+	OrgId          string    `db:"org_id"`
+	ProjectId      string    `db:"project_id"`
+	Path           string    `db:"path"`
+	Sha            string    `db:"sha"`
+	Name           string    `db:"name"`
+	StartLine      int       `db:"start_line"`
+	EndLine        int       `db:"end_line"`
+	Body           string    `db:"body"`
+	Terms          string    `db:"terms"`
+	EmbeddingModel *string   `db:"embedding_model"`
+	EmbeddingDims  *int      `db:"embedding_dims"`
+	Score          float64   `db:"score"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
 type DefaultPlanSettings struct {
 	Id           string              `db:"id"`
 	OrgId        string              `db:"org_id"`
@@ -393,6 +682,7 @@ type Context struct {
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
 	FilePath        string                `json:"filePath"`
+	Symbol          string                `json:"symbol,omitempty"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
 	Body            string                `json:"body,omitempty"`
@@ -420,6 +710,7 @@ func (context *Context) ToMeta() *Context {
 		Name:            context.Name,
 		Url:             context.Url,
 		FilePath:        context.FilePath,
+		Symbol:          context.Symbol,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
 		BodySize:        context.BodySize,
@@ -442,6 +733,7 @@ func (context *Context) ToApi() *shared.Context {
 		Name:            context.Name,
 		Url:             context.Url,
 		FilePath:        context.FilePath,
+		Symbol:          context.Symbol,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
 		Body:            context.Body,
@@ -17,6 +17,9 @@ import (
 
 var Conn *sqlx.DB
 
+// connection url with session settings applied--kept for connections opened outside the pool (LISTEN)
+var connUrl string
+
 const LockTimeout = 4000
 const IdleInTransactionSessionTimeout = 90000
 const StatementTimeout = 30000
@@ -47,6 +50,8 @@ func Connect() error {
 		dbUrl += fmt.Sprintf("?statement_timeout=%d&lock_timeout=%d&timezone=UTC&idle_in_transaction_session_timeout=%d", StatementTimeout, LockTimeout, IdleInTransactionSessionTimeout)
 	}
 
+	connUrl = dbUrl
+
 	Conn, err = sqlx.Connect("postgres", dbUrl)
 	if err != nil {
 		return err
@@ -0,0 +1,165 @@
+// Package dbtest connects tests to a migrated postgres database. Tests that call Setup are skipped unless PLANDEX_TEST_DATABASE_URL is set to a database they can write to--each test creates its own org, so they can share it and don't clean up.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"plandex-server/db"
+	"runtime"
+	"sync"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const DatabaseUrlEnvVar = "PLANDEX_TEST_DATABASE_URL"
+
+var (
+	setupOnce sync.Once
+	setupErr  error
+)
+
+// Setup connects to the test database and migrates it on first use, and gives the test its own plans directory
+func Setup(t *testing.T) {
+	t.Helper()
+
+	dbUrl := os.Getenv(DatabaseUrlEnvVar)
+	if dbUrl == "" {
+		t.Skipf("%s isn't set", DatabaseUrlEnvVar)
+	}
+
+	setupOnce.Do(func() {
+		os.Setenv("DATABASE_URL", dbUrl)
+
+		setupErr = db.Connect()
+		if setupErr != nil {
+			return
+		}
+
+		_, file, _, _ := runtime.Caller(0)
+		setupErr = db.MigrationsUpWithDir(filepath.Join(filepath.Dir(file), "..", "..", "migrations"))
+		if setupErr != nil {
+			return
+		}
+
+		setupErr = db.CacheOrgRoleIds()
+	})
+
+	if setupErr != nil {
+		t.Fatalf("error setting up test database: %v", setupErr)
+	}
+
+	db.BaseDir = t.TempDir()
+}
+
+// Org is an org with a project and a plan in it owned by Owner
+type Org struct {
+	Org       *db.Org
+	Owner     *db.User
+	ProjectId string
+	Plan      *db.Plan
+
+	// email domain its users are created with
+	Domain string
+}
+
+func CreateOrg(t *testing.T) *Org {
+	t.Helper()
+	return createOrg(t, false, false)
+}
+
+// CreateDomainOrg creates an org with domain access for a domain of its own
+func CreateDomainOrg(t *testing.T, autoAddDomainUsers bool) *Org {
+	t.Helper()
+	return createOrg(t, true, autoAddDomainUsers)
+}
+
+func createOrg(t *testing.T, withDomain, autoAddDomainUsers bool) *Org {
+	t.Helper()
+
+	res := &Org{Domain: uuid.NewString() + ".dbtest.plandex.ai"}
+
+	var domain *string
+	if withDomain {
+		domain = &res.Domain
+	}
+
+	err := db.WithTx(context.Background(), "dbtest create org", func(tx *sqlx.Tx) error {
+		var err error
+		res.Owner, err = db.CreateUser("Owner", res.Email("owner"), tx)
+		if err != nil {
+			return err
+		}
+
+		res.Org, err = db.CreateOrg(&shared.CreateOrgRequest{Name: "dbtest " + uuid.NewString(), AutoAddDomainUsers: autoAddDomainUsers}, res.Owner.Id, domain, tx)
+		if err != nil {
+			return err
+		}
+
+		res.ProjectId, err = db.CreateProject(res.Org.Id, "dbtest", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating org: %v", err)
+	}
+
+	res.Plan, err = db.CreatePlan(context.Background(), res.Org.Id, res.ProjectId, res.Owner.Id, "dbtest")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+
+	return res
+}
+
+// AddMember adds a user to the org with the member role
+func (o *Org) AddMember(t *testing.T, name string) *db.User {
+	t.Helper()
+
+	roleId, err := db.GetOrgMemberRoleId()
+	if err != nil {
+		t.Fatalf("error getting member role: %v", err)
+	}
+
+	var user *db.User
+	err = db.WithTx(context.Background(), "dbtest add member", func(tx *sqlx.Tx) error {
+		var err error
+		user, err = db.CreateUser(name, o.Email(name), tx)
+		if err != nil {
+			return err
+		}
+		return db.CreateOrgUser(o.Org.Id, user.Id, roleId, tx)
+	})
+	if err != nil {
+		t.Fatalf("error adding member: %v", err)
+	}
+
+	return user
+}
+
+// SharePlan shares the org's plan with a member
+func (o *Org) SharePlan(t *testing.T, userId string, access shared.PlanShareAccess) *db.PlanShare {
+	t.Helper()
+
+	share := &db.PlanShare{
+		OrgId:     o.Org.Id,
+		PlanId:    o.Plan.Id,
+		UserId:    userId,
+		Access:    access,
+		CreatedBy: &o.Owner.Id,
+	}
+	err := db.UpsertPlanShare(share)
+	if err != nil {
+		t.Fatalf("error sharing plan: %v", err)
+	}
+
+	return share
+}
+
+// Email returns an address in the org's domain
+func (o *Org) Email(name string) string {
+	return name + "@" + o.Domain
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func GetOrgExecPolicy(orgId string) (*shared.ExecPolicy, error) {
+	var policy shared.ExecPolicy
+	err := Conn.Get(&policy, "SELECT policy FROM org_exec_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org exec policy: %v", err)
+	}
+
+	return &policy, nil
+}
+
+func SetOrgExecPolicy(orgId string, policy *shared.ExecPolicy) error {
+	query := `INSERT INTO org_exec_policies (org_id, policy) VALUES ($1, $2)
+	ON CONFLICT (org_id) DO UPDATE SET policy = EXCLUDED.policy`
+
+	_, err := Conn.Exec(query, orgId, policy)
+	if err != nil {
+		return fmt.Errorf("error setting org exec policy: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteOrgExecPolicy(orgId string) error {
+	_, err := Conn.Exec("DELETE FROM org_exec_policies WHERE org_id = $1", orgId)
+	if err != nil {
+		return fmt.Errorf("error deleting org exec policy: %v", err)
+	}
+
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	shared "plandex-shared"
+
 	"github.com/fatih/color"
 )
 
@@ -192,18 +194,18 @@ func (repo *GitRepo) GitCheckoutSha(sha string) error {
 	return nil
 }
 
-func (repo *GitRepo) GetGitCommitHistory(branch string) (body string, shas []string, err error) {
+func (repo *GitRepo) GetGitCommitHistory(branch string) (body string, entries []shared.LogEntry, err error) {
 	orgId := repo.orgId
 	planId := repo.planId
 
 	dir := getPlanDir(orgId, planId)
 
-	body, shas, err = getGitCommitHistory(dir)
+	body, entries, err = getGitCommitHistory(dir)
 	if err != nil {
 		return "", nil, fmt.Errorf("error getting git history for dir: %s, err: %v", dir, err)
 	}
 
-	return body, shas, nil
+	return body, entries, nil
 }
 
 func (repo *GitRepo) GetLatestCommit(branch string) (sha, body string, err error) {
@@ -485,7 +487,7 @@ func getLatestCommit(dir string) (sha, body string, err error) {
 	return sha, body, nil
 }
 
-func getGitCommitHistory(dir string) (body string, shas []string, err error) {
+func getGitCommitHistory(dir string) (body string, entries []shared.LogEntry, err error) {
 	var out bytes.Buffer
 	cmd := exec.Command("git", "log", "--pretty=%h@@|@@%at@@|@@%B@>>>@")
 	cmd.Dir = dir
@@ -495,61 +497,69 @@ func getGitCommitHistory(dir string) (body string, shas []string, err error) {
 		return "", nil, fmt.Errorf("error getting git history for dir: %s, err: %v", dir, err)
 	}
 
-	// Process the log output to get it in the desired format.
-	history := processGitHistoryOutput(strings.TrimSpace(out.String()))
+	entries = parseGitHistoryOutput(strings.TrimSpace(out.String()))
 
 	var output []string
-	for _, el := range history {
-		shas = append(shas, el[0])
-		output = append(output, el[1])
+	for _, entry := range entries {
+		output = append(output, formatGitHistoryEntry(entry))
 	}
 
-	return strings.Join(output, "\n\n"), shas, nil
+	return strings.Join(output, "\n\n"), entries, nil
 }
 
 // processGitHistoryOutput processes the raw output from the git log command and returns a formatted string.
 func processGitHistoryOutput(raw string) [][2]string {
 	var history [][2]string
-	entries := strings.Split(raw, "@>>>@") // Split entries using the custom separator.
+	for _, entry := range parseGitHistoryOutput(raw) {
+		history = append(history, [2]string{entry.Sha, formatGitHistoryEntry(entry)})
+	}
+	return history
+}
 
-	for _, entry := range entries {
+// parseGitHistoryOutput parses the raw output from the git log command into one entry per commit.
+func parseGitHistoryOutput(raw string) []shared.LogEntry {
+	var entries []shared.LogEntry
+
+	for _, entry := range strings.Split(raw, "@>>>@") { // Split entries using the custom separator.
 		// First clean up any leading/trailing whitespace or newlines from each entry.
 		entry = strings.TrimSpace(entry)
 
 		// Now split the cleaned entry into its parts.
 		parts := strings.Split(entry, "@@|@@")
-		if len(parts) == 3 {
-			sha := parts[0]
-			timestampStr := parts[1]
-			message := strings.TrimSpace(parts[2]) // Trim whitespace from message as well.
+		if len(parts) != 3 {
+			continue
+		}
 
-			// Extract and format timestamp.
-			timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
-			if err != nil {
-				continue // Skip entries with invalid timestamps.
-			}
+		timestamp, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue // Skip entries with invalid timestamps.
+		}
 
-			dt := time.Unix(timestamp, 0).UTC()
-			formattedTs := dt.Format("Mon Jan 2, 2006 | 3:04:05pm MST")
+		entries = append(entries, shared.LogEntry{
+			Sha:       parts[0],
+			Message:   strings.TrimSpace(parts[2]), // Trim whitespace from message as well.
+			CreatedAt: time.Unix(timestamp, 0).UTC(),
+		})
+	}
 
-			// Prepare the header with colors.
-			headerColor := color.New(color.FgCyan, color.Bold)
-			dateColor := color.New(color.FgCyan)
+	return entries
+}
 
-			// Combine sha, formatted timestamp, and message header into one string.
-			header := fmt.Sprintf("%s | %s", headerColor.Sprintf("📝 Update %s", sha), dateColor.Sprintf("%s", formattedTs))
+func formatGitHistoryEntry(entry shared.LogEntry) string {
+	formattedTs := entry.CreatedAt.Format("Mon Jan 2, 2006 | 3:04:05pm MST")
 
-			// Combine header and message with a newline only if the message is not empty.
-			fullEntry := header
-			if message != "" {
-				fullEntry += "\n" + message
-			}
+	// Prepare the header with colors.
+	headerColor := color.New(color.FgCyan, color.Bold)
+	dateColor := color.New(color.FgCyan)
 
-			history = append(history, [2]string{sha, fullEntry})
-		}
-	}
+	// Combine sha, formatted timestamp, and message header into one string.
+	header := fmt.Sprintf("%s | %s", headerColor.Sprintf("📝 Update %s", entry.Sha), dateColor.Sprintf("%s", formattedTs))
 
-	return history
+	// Combine header and message with a newline only if the message is not empty.
+	if entry.Message != "" {
+		return header + "\n" + entry.Message
+	}
+	return header
 }
 
 func removeLockFile(lockFilePath string) error {
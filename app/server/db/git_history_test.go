@@ -0,0 +1,78 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGitHistoryOutput(t *testing.T) {
+	raw := "abc1234@@|@@1700000000@@|@@Tell: add auth\n\nwith details\n@>>>@\n" +
+		"def5678@@|@@not-a-time@@|@@skipped@>>>@\n" +
+		"0123abc@@|@@1690000000@@|@@@>>>@"
+
+	entries := parseGitHistoryOutput(raw)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %+v", len(entries), entries)
+	}
+
+	if entries[0].Sha != "abc1234" || entries[0].Message != "Tell: add auth\n\nwith details" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if !entries[0].CreatedAt.Equal(time.Unix(1700000000, 0)) || entries[0].CreatedAt.Location() != time.UTC {
+		t.Errorf("unexpected timestamp: %v", entries[0].CreatedAt)
+	}
+	if entries[1].Sha != "0123abc" || entries[1].Message != "" {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+
+	formatted := formatGitHistoryEntry(entries[0])
+	if !strings.Contains(formatted, "📝 Update abc1234") || !strings.Contains(formatted, "Nov 14, 2023 | 10:13:20pm UTC") || !strings.HasSuffix(formatted, "\nwith details") {
+		t.Errorf("unexpected formatted entry: %q", formatted)
+	}
+	if strings.Contains(formatGitHistoryEntry(entries[1]), "\n") {
+		t.Errorf("entry without a message shouldn't have a body")
+	}
+}
+
+func TestGetGitCommitHistory(t *testing.T) {
+	dir := t.TempDir()
+	if err := initGitRepo(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, msg := range []string{"first update", "second update\n\nwith a body"} {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(strings.Repeat("x", i+1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := gitAdd(dir, "."); err != nil {
+			t.Fatal(err)
+		}
+		if err := gitCommit(dir, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, entries, err := getGitCommitHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+		if entry.Sha == "" || entry.CreatedAt.IsZero() {
+			t.Errorf("incomplete entry: %+v", entry)
+		}
+		if !strings.Contains(body, "📝 Update "+entry.Sha) {
+			t.Errorf("body is missing entry %s", entry.Sha)
+		}
+	}
+
+	// newest first, after the commit made by initGitRepo
+	if len(messages) < 2 || messages[0] != "second update\n\nwith a body" || messages[1] != "first update" {
+		t.Errorf("unexpected messages: %q", messages)
+	}
+}
@@ -114,6 +114,16 @@ func DeleteInvite(id string, tx *sqlx.Tx) error {
 	return nil
 }
 
+func SetInviteRole(id, orgRoleId string) error {
+	_, err := Conn.Exec("UPDATE invites SET org_role_id = $1 WHERE id = $2 AND accepted_at IS NULL", orgRoleId, id)
+
+	if err != nil {
+		return fmt.Errorf("error setting invite role: %v", err)
+	}
+
+	return nil
+}
+
 func AcceptInvite(ctx context.Context, invite *Invite, inviteeId string) error {
 	err := WithTx(ctx, "accept invite", func(tx *sqlx.Tx) error {
 
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func GetUserForOidcIdentity(issuer, subject string) (*User, error) {
+	var user User
+	err := Conn.Get(&user, "SELECT users.* FROM users JOIN oidc_identities ON oidc_identities.user_id = users.id WHERE oidc_identities.issuer = $1 AND oidc_identities.subject = $2", issuer, subject)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting user for oidc identity: %v", err)
+	}
+
+	return &user, nil
+}
+
+func CreateOidcIdentity(userId, issuer, subject string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("INSERT INTO oidc_identities (user_id, issuer, subject) VALUES ($1, $2, $3) ON CONFLICT (issuer, subject) DO NOTHING", userId, issuer, subject)
+
+	if err != nil {
+		return fmt.Errorf("error creating oidc identity: %v", err)
+	}
+
+	return nil
+}
+
+// GetOrgRoleByName returns the org's role with the name, or nil if there isn't one
+func GetOrgRoleByName(orgId, name string) (*OrgRole, error) {
+	var orgRole OrgRole
+	err := Conn.Get(&orgRole, "SELECT * FROM org_roles WHERE (org_id IS NULL OR org_id = $1) AND name = $2 ORDER BY org_id IS NULL LIMIT 1", orgId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &orgRole, nil
+}
+
+func SetOrgUserRole(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE orgs_users SET org_role_id = $1 WHERE org_id = $2 AND user_id = $3", orgRoleId, orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error setting org user role: %v", err)
+	}
+
+	return nil
+}
@@ -187,6 +187,19 @@ func DeleteOrgUser(orgId, userId string, tx *sqlx.Tx) error {
 		return fmt.Errorf("error deleting org member: %v", err)
 	}
 
+	// plans shared with the user stay with their owners
+	_, err = tx.Exec("DELETE FROM plan_shares WHERE org_id = $1 AND user_id = $2", orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan shares for org member: %v", err)
+	}
+
+	_, err = tx.Exec("UPDATE api_tokens SET revoked_at = NOW() WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL", orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error revoking api tokens for org member: %v", err)
+	}
+
 	return nil
 }
 
@@ -208,7 +221,7 @@ func CreateOrgUser(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
 
 func ListOrgRoles(orgId string) ([]*OrgRole, error) {
 	var orgRoles []*OrgRole
-	err := Conn.Select(&orgRoles, "SELECT * FROM org_roles WHERE org_id IS NULL OR org_id = $1", orgId)
+	err := Conn.Select(&orgRoles, "SELECT * FROM org_roles WHERE org_id IS NULL OR org_id = $1 ORDER BY org_id IS NOT NULL, created_at", orgId)
 
 	if err != nil {
 		return nil, fmt.Errorf("error listing org roles: %v", err)
@@ -224,14 +237,15 @@ func AddToOrgForDomain(userId, domain string, tx *sqlx.Tx) (string, error) {
 		return "", fmt.Errorf("error getting org for domain: %v", err)
 	}
 
-	orgOwnerRoleId, err := GetOrgOwnerRoleId()
+	// domain users join as members, like existing users do when the org is created
+	orgMemberRoleId, err := GetOrgMemberRoleId()
 
 	if err != nil {
-		return "", fmt.Errorf("error getting org owner role id: %v", err)
+		return "", fmt.Errorf("error getting org member role id: %v", err)
 	}
 
 	if org != nil && org.AutoAddDomainUsers {
-		err = CreateOrgUser(org.Id, userId, orgOwnerRoleId, tx)
+		err = CreateOrgUser(org.Id, userId, orgMemberRoleId, tx)
 
 		if err != nil {
 			return "", fmt.Errorf("error adding org user: %v", err)
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestPlanAccess(t *testing.T) {
+	sharedAt := time.Now()
+	readShare := &PlanShare{PlanId: "plan", UserId: "reader", Access: shared.PlanShareAccessRead}
+	collaboratorShare := &PlanShare{PlanId: "plan", UserId: "collaborator", Access: shared.PlanShareAccessCollaborator}
+
+	getShare := func(planId, userId string) (*PlanShare, error) {
+		if planId != "plan" {
+			t.Errorf("share looked up for plan %s", planId)
+		}
+		switch userId {
+		case "reader":
+			return readShare, nil
+		case "collaborator":
+			return collaboratorShare, nil
+		case "broken":
+			return nil, errors.New("connection reset")
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		name      string
+		plan      *Plan
+		userId    string
+		wantPlan  bool
+		wantShare *PlanShare
+		wantErr   bool
+	}{
+		{"owner", &Plan{Id: "plan", OwnerId: "owner"}, "owner", true, nil, false},
+		{"shared with org", &Plan{Id: "plan", OwnerId: "owner", SharedWithOrgAt: &sharedAt}, "reader", true, nil, false},
+		{"read share", &Plan{Id: "plan", OwnerId: "owner"}, "reader", true, readShare, false},
+		{"collaborator share", &Plan{Id: "plan", OwnerId: "owner"}, "collaborator", true, collaboratorShare, false},
+		{"not shared", &Plan{Id: "plan", OwnerId: "owner"}, "member", false, nil, false},
+		{"share lookup error", &Plan{Id: "plan", OwnerId: "owner"}, "broken", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, share, err := planAccess(tt.plan, tt.userId, getShare)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (plan != nil) != tt.wantPlan {
+				t.Errorf("plan = %v, want access %v", plan, tt.wantPlan)
+			}
+			if share != tt.wantShare {
+				t.Errorf("share = %+v, want %+v", share, tt.wantShare)
+			}
+		})
+	}
+}
@@ -0,0 +1,539 @@
+package db
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A plan archive is a gzipped tar with a manifest of the plan's db rows and a 'git fast-export' stream of the plan repo, which holds context, convo, results, subtasks, and settings for every branch along with their full history.
+
+const PlanArchiveVersion = 1
+
+const (
+	planArchiveManifestName = "manifest.json"
+	planArchiveRepoName     = "repo.fast-export"
+)
+
+const (
+	// MaxPlanArchiveSize limits the size of an uploaded (compressed) archive
+	MaxPlanArchiveSize = 200 << 20 // 200 MB
+
+	// maxPlanArchiveUncompressedSize limits how much an archive can expand to so that a small upload can't fill the disk
+	maxPlanArchiveUncompressedSize = 2 << 30 // 2 GB
+)
+
+var ErrPlanArchiveTooLarge = errors.New("plan archive is too large")
+
+// limitedArchiveReader fails with ErrPlanArchiveTooLarge once more than remaining bytes are read, rather than silently truncating like io.LimitReader
+type limitedArchiveReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedArchiveReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// see if there's anything left before failing
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrPlanArchiveTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+type PlanArchiveManifest struct {
+	Version        int                  `json:"version"`
+	ExportedAt     time.Time            `json:"exportedAt"`
+	OrgId          string               `json:"orgId"`
+	PlanId         string               `json:"planId"`
+	OwnerId        string               `json:"ownerId"`
+	Name           string               `json:"name"`
+	TotalReplies   int                  `json:"totalReplies"`
+	PlanConfig     *shared.PlanConfig   `json:"planConfig"`
+	Branches       []PlanArchiveBranch  `json:"branches"`
+	ConvoSummaries []PlanArchiveSummary `json:"convoSummaries"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+type PlanArchiveBranch struct {
+	Name             string            `json:"name"`
+	ParentBranchName *string           `json:"parentBranchName"`
+	Status           shared.PlanStatus `json:"status"`
+	Error            *string           `json:"error"`
+	ContextTokens    int               `json:"contextTokens"`
+	ConvoTokens      int               `json:"convoTokens"`
+	ArchivedAt       *time.Time        `json:"archivedAt"`
+	CreatedAt        time.Time         `json:"createdAt"`
+}
+
+type PlanArchiveSummary struct {
+	LatestConvoMessageId        string    `json:"latestConvoMessageId"`
+	LatestConvoMessageCreatedAt time.Time `json:"latestConvoMessageCreatedAt"`
+	Summary                     string    `json:"summary"`
+	Tokens                      int       `json:"tokens"`
+	NumMessages                 int       `json:"numMessages"`
+	CreatedAt                   time.Time `json:"createdAt"`
+}
+
+// PlanArchive is an archive that's been read and is ready to import. The repo stream is kept in a temp file—call Cleanup when done.
+type PlanArchive struct {
+	Manifest PlanArchiveManifest
+	repoPath string
+}
+
+func (archive *PlanArchive) Cleanup() {
+	if archive.repoPath != "" {
+		os.Remove(archive.repoPath)
+	}
+}
+
+// ExportPlanArchive writes an archive of the plan to w. Call it with at least a read lock on the plan repo.
+func ExportPlanArchive(repo *GitRepo, plan *Plan, w io.Writer) error {
+	branches, err := ListPlanBranches(repo, plan.Id)
+	if err != nil {
+		return err
+	}
+
+	var summaries []*ConvoSummary
+	err = Conn.Select(&summaries, "SELECT * FROM convo_summaries WHERE plan_id = $1 ORDER BY created_at", plan.Id)
+	if err != nil {
+		return fmt.Errorf("error getting convo summaries: %v", err)
+	}
+
+	manifest := PlanArchiveManifest{
+		Version:        PlanArchiveVersion,
+		ExportedAt:     time.Now(),
+		OrgId:          plan.OrgId,
+		PlanId:         plan.Id,
+		OwnerId:        plan.OwnerId,
+		Name:           plan.Name,
+		TotalReplies:   plan.TotalReplies,
+		PlanConfig:     plan.PlanConfig,
+		Branches:       []PlanArchiveBranch{},
+		ConvoSummaries: []PlanArchiveSummary{},
+		CreatedAt:      plan.CreatedAt,
+	}
+
+	namesById := map[string]string{}
+	for _, branch := range branches {
+		namesById[branch.Id] = branch.Name
+	}
+
+	for _, branch := range branches {
+		archiveBranch := PlanArchiveBranch{
+			Name:          branch.Name,
+			Status:        branch.Status,
+			Error:         branch.Error,
+			ContextTokens: branch.ContextTokens,
+			ConvoTokens:   branch.ConvoTokens,
+			ArchivedAt:    branch.ArchivedAt,
+			CreatedAt:     branch.CreatedAt,
+		}
+		if branch.ParentBranchId != nil {
+			if name, ok := namesById[*branch.ParentBranchId]; ok {
+				archiveBranch.ParentBranchName = &name
+			}
+		}
+		manifest.Branches = append(manifest.Branches, archiveBranch)
+	}
+
+	for _, summary := range summaries {
+		manifest.ConvoSummaries = append(manifest.ConvoSummaries, PlanArchiveSummary{
+			LatestConvoMessageId:        summary.LatestConvoMessageId,
+			LatestConvoMessageCreatedAt: summary.LatestConvoMessageCreatedAt,
+			Summary:                     summary.Summary,
+			Tokens:                      summary.Tokens,
+			NumMessages:                 summary.NumMessages,
+			CreatedAt:                   summary.CreatedAt,
+		})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling manifest: %v", err)
+	}
+
+	// the tar header needs the stream size up front, so export to a temp file first
+	repoFile, err := os.CreateTemp("", "plandex-export-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %v", err)
+	}
+	defer os.Remove(repoFile.Name())
+	defer repoFile.Close()
+
+	dir := getPlanDir(plan.OrgId, plan.Id)
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "fast-export", "--all", "--signed-tags=strip")
+	cmd.Stdout = repoFile
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("error exporting git repository for dir: %s, err: %v, output: %s", dir, err, stderr.String())
+	}
+
+	repoSize, err := repoFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("error getting export size: %v", err)
+	}
+	_, err = repoFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("error seeking export file: %v", err)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    planArchiveManifestName,
+		Mode:    0644,
+		Size:    int64(len(manifestBytes)),
+		ModTime: manifest.ExportedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+	_, err = tw.Write(manifestBytes)
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    planArchiveRepoName,
+		Mode:    0644,
+		Size:    repoSize,
+		ModTime: manifest.ExportedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+	_, err = io.Copy(tw, repoFile)
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+
+	err = gzw.Close()
+	if err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+
+	return nil
+}
+
+// ReadPlanArchive reads an archive into a PlanArchive. It fails with ErrPlanArchiveTooLarge if the archive decompresses to more than maxPlanArchiveUncompressedSize.
+func ReadPlanArchive(r io.Reader) (*PlanArchive, error) {
+	return readPlanArchive(r, maxPlanArchiveUncompressedSize)
+}
+
+func readPlanArchive(r io.Reader, maxUncompressedSize int64) (*PlanArchive, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive: %w", err)
+	}
+	defer gzr.Close()
+
+	archive := &PlanArchive{}
+	var hasManifest bool
+
+	tr := tar.NewReader(&limitedArchiveReader{r: gzr, remaining: maxUncompressedSize})
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			archive.Cleanup()
+			return nil, fmt.Errorf("error reading archive: %w", err)
+		}
+
+		switch header.Name {
+		case planArchiveManifestName:
+			err = json.NewDecoder(tr).Decode(&archive.Manifest)
+			if err != nil {
+				archive.Cleanup()
+				return nil, fmt.Errorf("error reading archive manifest: %w", err)
+			}
+			hasManifest = true
+
+		case planArchiveRepoName:
+			repoFile, err := os.CreateTemp("", "plandex-import-*")
+			if err != nil {
+				archive.Cleanup()
+				return nil, fmt.Errorf("error creating temp file: %v", err)
+			}
+			archive.repoPath = repoFile.Name()
+
+			_, err = io.Copy(repoFile, tr)
+			repoFile.Close()
+			if err != nil {
+				archive.Cleanup()
+				return nil, fmt.Errorf("error reading archive repo: %w", err)
+			}
+		}
+	}
+
+	if !hasManifest || archive.repoPath == "" {
+		archive.Cleanup()
+		return nil, fmt.Errorf("not a plan archive")
+	}
+
+	if archive.Manifest.Version > PlanArchiveVersion {
+		archive.Cleanup()
+		return nil, fmt.Errorf("plan archive version %d is newer than this server supports (%d)—upgrade the server to import it", archive.Manifest.Version, PlanArchiveVersion)
+	}
+
+	return archive, nil
+}
+
+type ImportPlanArchiveParams struct {
+	OrgId     string
+	ProjectId string
+	UserId    string
+	Name      string
+}
+
+// ImportPlanArchive creates a new plan from an archive. The plan gets a new id, and is owned by the importing user.
+func ImportPlanArchive(ctx context.Context, archive *PlanArchive, params ImportPlanArchiveParams) (*Plan, error) {
+	manifest := archive.Manifest
+
+	var plan *Plan
+	var dir string
+
+	err := WithTx(ctx, "import plan", func(tx *sqlx.Tx) error {
+		query := `INSERT INTO plans (org_id, owner_id, project_id, name, plan_config, total_replies, active_branches)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at`
+
+		plan = &Plan{
+			OrgId:          params.OrgId,
+			OwnerId:        params.UserId,
+			ProjectId:      params.ProjectId,
+			Name:           params.Name,
+			PlanConfig:     manifest.PlanConfig,
+			TotalReplies:   manifest.TotalReplies,
+			ActiveBranches: len(manifest.Branches),
+		}
+
+		err := tx.QueryRow(
+			query,
+			plan.OrgId,
+			plan.OwnerId,
+			plan.ProjectId,
+			plan.Name,
+			plan.PlanConfig,
+			plan.TotalReplies,
+			plan.ActiveBranches,
+		).Scan(
+			&plan.Id,
+			&plan.CreatedAt,
+			&plan.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error creating plan: %v", err)
+		}
+
+		_, err = tx.Exec("INSERT INTO lockable_plan_ids (plan_id) VALUES ($1)", plan.Id)
+		if err != nil {
+			return fmt.Errorf("error inserting lockable plan id: %v", err)
+		}
+
+		branchIdsByName := map[string]string{}
+		for _, branch := range manifest.Branches {
+			status := branch.Status
+			switch status {
+			case shared.PlanStatusReplying, shared.PlanStatusDescribing, shared.PlanStatusBuilding, shared.PlanStatusMissingFile:
+				// the stream didn't come along with the plan
+				status = shared.PlanStatusStopped
+			}
+
+			var id string
+			err = tx.QueryRow(
+				`INSERT INTO branches (org_id, owner_id, plan_id, name, status, error, context_tokens, convo_tokens, archived_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				RETURNING id`,
+				plan.OrgId,
+				plan.OwnerId,
+				plan.Id,
+				branch.Name,
+				status,
+				branch.Error,
+				branch.ContextTokens,
+				branch.ConvoTokens,
+				branch.ArchivedAt,
+				branch.CreatedAt,
+			).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error creating branch %s: %v", branch.Name, err)
+			}
+			branchIdsByName[branch.Name] = id
+		}
+
+		for _, branch := range manifest.Branches {
+			if branch.ParentBranchName == nil {
+				continue
+			}
+			parentId, ok := branchIdsByName[*branch.ParentBranchName]
+			if !ok {
+				continue
+			}
+			_, err = tx.Exec("UPDATE branches SET parent_branch_id = $1 WHERE id = $2", parentId, branchIdsByName[branch.Name])
+			if err != nil {
+				return fmt.Errorf("error setting parent branch for %s: %v", branch.Name, err)
+			}
+		}
+
+		for _, summary := range manifest.ConvoSummaries {
+			_, err = tx.Exec(
+				`INSERT INTO convo_summaries (org_id, plan_id, latest_convo_message_id, latest_convo_message_created_at, summary, tokens, num_messages, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+				plan.OrgId,
+				plan.Id,
+				summary.LatestConvoMessageId,
+				summary.LatestConvoMessageCreatedAt,
+				summary.Summary,
+				summary.Tokens,
+				summary.NumMessages,
+				summary.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error storing convo summary: %v", err)
+			}
+		}
+
+		dir = getPlanDir(plan.OrgId, plan.Id)
+		err = os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("error creating plan dir: %v", err)
+		}
+
+		err = initGitRepo(dir)
+		if err != nil {
+			return err
+		}
+
+		// plan files reference the org, plan, and owner by id—swap in the new ids throughout the history
+		err = importGitRepo(dir, archive.repoPath, map[string]string{
+			manifest.OrgId:   plan.OrgId,
+			manifest.PlanId:  plan.Id,
+			manifest.OwnerId: plan.OwnerId,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if dir != "" {
+			if rmErr := os.RemoveAll(dir); rmErr != nil {
+				log.Printf("Error removing plan dir after failed import: %v\n", rmErr)
+			}
+		}
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func importGitRepo(dir, streamPath string, replaceIds map[string]string) error {
+	streamFile, err := os.Open(streamPath)
+	if err != nil {
+		return fmt.Errorf("error opening repo stream: %v", err)
+	}
+	defer streamFile.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(replaceIdsInStream(streamFile, pw, replaceIds))
+	}()
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "fast-import", "--quiet")
+	cmd.Stdin = pr
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("error importing git repository for dir: %s, err: %v, output: %s", dir, err, stderr.String())
+	}
+
+	res, err := exec.Command("git", "-C", dir, "reset", "--hard", "main").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error checking out main branch for dir: %s, err: %v, output: %s", dir, err, string(res))
+	}
+
+	return nil
+}
+
+// replaceIdsInStream replaces ids in a fast-export stream. Ids are uuids, so every replacement is the same length and the stream's 'data <size>' headers stay valid.
+func replaceIdsInStream(r io.Reader, w io.Writer, replaceIds map[string]string) error {
+	const idLen = 36
+
+	var oldnew [][]byte
+	for oldId, newId := range replaceIds {
+		if len(oldId) != idLen || len(newId) != idLen {
+			return fmt.Errorf("invalid id replacement: %s -> %s", oldId, newId)
+		}
+		if oldId != newId {
+			oldnew = append(oldnew, []byte(oldId), []byte(newId))
+		}
+	}
+
+	br := bufio.NewReader(r)
+	chunk := make([]byte, 64*1024)
+	var buf []byte
+
+	for {
+		n, readErr := br.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		for i := 0; i < len(oldnew); i += 2 {
+			buf = bytes.ReplaceAll(buf, oldnew[i], oldnew[i+1])
+		}
+
+		if readErr == io.EOF {
+			_, err := w.Write(buf)
+			return err
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		// hold back enough to complete an id that's split across reads
+		if len(buf) > idLen-1 {
+			flush := len(buf) - (idLen - 1)
+			_, err := w.Write(buf[:flush])
+			if err != nil {
+				return err
+			}
+			buf = append(buf[:0], buf[flush:]...)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const (
+	testOldOrgId  = "11111111-1111-1111-1111-111111111111"
+	testNewOrgId  = "22222222-2222-2222-2222-222222222222"
+	testOldPlanId = "33333333-3333-3333-3333-333333333333"
+	testNewPlanId = "44444444-4444-4444-4444-444444444444"
+)
+
+func TestReplaceIdsInStream(t *testing.T) {
+	input := strings.Repeat("x", 70000) + testOldOrgId + "/" + testOldPlanId + strings.Repeat("y", 10) + testOldOrgId
+
+	var out bytes.Buffer
+	// one byte at a time so ids are always split across reads
+	err := replaceIdsInStream(iotest.OneByteReader(strings.NewReader(input)), &out, map[string]string{
+		testOldOrgId:  testNewOrgId,
+		testOldPlanId: testNewPlanId,
+	})
+	if err != nil {
+		t.Fatalf("replaceIdsInStream: %v", err)
+	}
+
+	expected := strings.Repeat("x", 70000) + testNewOrgId + "/" + testNewPlanId + strings.Repeat("y", 10) + testNewOrgId
+	if out.String() != expected {
+		t.Errorf("ids not replaced—got %d bytes, expected %d", out.Len(), len(expected))
+	}
+}
+
+func TestImportGitRepo(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := initGitRepo(srcDir); err != nil {
+		t.Fatal(err)
+	}
+
+	writeAndCommit := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(srcDir, "context.meta"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := gitAdd(srcDir, "."); err != nil {
+			t.Fatal(err)
+		}
+		if err := gitCommit(srcDir, "commit for plan "+testOldPlanId); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeAndCommit(`{"orgId":"` + testOldOrgId + `","planId":"` + testOldPlanId + `"}`)
+	if res, err := exec.Command("git", "-C", srcDir, "checkout", "-b", "feature").CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, res)
+	}
+	writeAndCommit(`{"orgId":"` + testOldOrgId + `","planId":"` + testOldPlanId + `","branch":"feature"}`)
+	if res, err := exec.Command("git", "-C", srcDir, "checkout", "main").CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, res)
+	}
+
+	streamPath := filepath.Join(t.TempDir(), "repo.fast-export")
+	stream, err := exec.Command("git", "-C", srcDir, "fast-export", "--all").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(streamPath, stream, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dstDir := t.TempDir()
+	if err := initGitRepo(dstDir); err != nil {
+		t.Fatal(err)
+	}
+
+	err = importGitRepo(dstDir, streamPath, map[string]string{
+		testOldOrgId:  testNewOrgId,
+		testOldPlanId: testNewPlanId,
+	})
+	if err != nil {
+		t.Fatalf("importGitRepo: %v", err)
+	}
+
+	mainContent, err := os.ReadFile(filepath.Join(dstDir, "context.meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mainContent) != `{"orgId":"`+testNewOrgId+`","planId":"`+testNewPlanId+`"}` {
+		t.Errorf("unexpected main content: %s", mainContent)
+	}
+
+	featureContent, err := exec.Command("git", "-C", dstDir, "show", "feature:context.meta").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(featureContent), testOldOrgId) || !strings.Contains(string(featureContent), testNewPlanId) {
+		t.Errorf("ids not replaced on feature branch: %s", featureContent)
+	}
+
+	msg, err := exec.Command("git", "-C", dstDir, "log", "-1", "--format=%s", "feature").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(msg)) != "commit for plan "+testNewPlanId {
+		t.Errorf("unexpected commit message: %s", msg)
+	}
+}
+
+func TestReadPlanArchiveSizeLimit(t *testing.T) {
+	buildArchive := func(repoSize int) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		gzw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gzw)
+
+		files := []struct {
+			name    string
+			content []byte
+		}{
+			{planArchiveManifestName, []byte(`{"version":1,"name":"plan"}`)},
+			{planArchiveRepoName, bytes.Repeat([]byte("0"), repoSize)},
+		}
+		for _, f := range files {
+			if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write(f.content); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tw.Close()
+		gzw.Close()
+		return buf.Bytes()
+	}
+
+	archive, err := readPlanArchive(bytes.NewReader(buildArchive(1000)), 1<<20)
+	if err != nil {
+		t.Fatalf("readPlanArchive: %v", err)
+	}
+	archive.Cleanup()
+
+	// compresses to a few KB but expands past the limit
+	data := buildArchive(4 << 20)
+	if len(data) > 64<<10 {
+		t.Fatalf("expected a highly compressed archive, got %d bytes", len(data))
+	}
+
+	_, err = readPlanArchive(bytes.NewReader(data), 1<<20)
+	if !errors.Is(err, ErrPlanArchiveTooLarge) {
+		t.Errorf("expected ErrPlanArchiveTooLarge, got %v", err)
+	}
+}
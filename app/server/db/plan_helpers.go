@@ -399,43 +399,60 @@ func DeleteOwnerPlans(orgId, projectId, userId string) error {
 	return nil
 }
 
-func ValidatePlanAccess(planId, userId, orgId string) (*Plan, error) {
+// ValidatePlanAccess returns the plan if the user can access it, along with their share if that's how they have access. A nil share means full access as the owner or through the org.
+func ValidatePlanAccess(planId, userId, orgId string) (*Plan, *PlanShare, error) {
 	// get plan
 	plan, err := GetPlan(planId)
 
 	if err != nil {
-		return nil, fmt.Errorf("error getting plan: %v", err)
+		return nil, nil, fmt.Errorf("error getting plan: %v", err)
 	}
 
 	if plan == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	if plan.OrgId != orgId {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	hasProjectAccess, err := ProjectExists(orgId, plan.ProjectId)
 
 	if err != nil {
-		return nil, fmt.Errorf("error validating project membership: %v", err)
+		return nil, nil, fmt.Errorf("error validating project membership: %v", err)
 	}
 
 	if !hasProjectAccess {
-		return nil, nil
+		return nil, nil, nil
 	}
 
+	return planAccess(plan, userId, GetPlanShare)
+}
+
+// planAccess decides a user's access to a plan in their org's project--split from ValidatePlanAccess so the share lookup can be stubbed
+func planAccess(plan *Plan, userId string, getShare func(planId, userId string) (*PlanShare, error)) (*Plan, *PlanShare, error) {
 	// owner has access
 	if plan.OwnerId == userId {
-		return plan, nil
+		return plan, nil, nil
 	}
 
 	// plan is shared with org
 	if plan.SharedWithOrgAt != nil {
-		return plan, nil
+		return plan, nil, nil
+	}
+
+	// plan is shared with user
+	share, err := getShare(plan.Id, userId)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting plan share: %v", err)
+	}
+
+	if share != nil {
+		return plan, share, nil
 	}
 
-	return nil, nil
+	return nil, nil, nil
 }
 
 func BumpPlanUpdatedAt(planId string, t time.Time) error {
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+const planShareColumns = `plan_shares.*, users.email AS user_email, users.name AS user_name`
+
+func ListPlanShares(planId string) ([]*PlanShare, error) {
+	var shares []*PlanShare
+	err := Conn.Select(&shares, "SELECT "+planShareColumns+" FROM plan_shares JOIN users ON users.id = plan_shares.user_id WHERE plan_shares.plan_id = $1 ORDER BY plan_shares.created_at", planId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan shares: %v", err)
+	}
+
+	return shares, nil
+}
+
+func GetPlanShare(planId, userId string) (*PlanShare, error) {
+	var share PlanShare
+	err := Conn.Get(&share, "SELECT "+planShareColumns+" FROM plan_shares JOIN users ON users.id = plan_shares.user_id WHERE plan_shares.plan_id = $1 AND plan_shares.user_id = $2", planId, userId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan share: %v", err)
+	}
+
+	return &share, nil
+}
+
+// UpsertPlanShare shares a plan with a user, or changes their access if it's already shared with them
+func UpsertPlanShare(share *PlanShare) error {
+	query := `INSERT INTO plan_shares (org_id, plan_id, user_id, access, created_by)
+	VALUES (:org_id, :plan_id, :user_id, :access, :created_by)
+	ON CONFLICT (plan_id, user_id) DO UPDATE SET access = EXCLUDED.access
+	RETURNING id, created_by, created_at, updated_at`
+
+	rows, err := Conn.NamedQuery(query, share)
+	if err != nil {
+		return fmt.Errorf("error sharing plan: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&share.Id, &share.CreatedBy, &share.CreatedAt, &share.UpdatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error sharing plan: %v", err)
+	}
+
+	return nil
+}
+
+func DeletePlanShare(planId, userId string) error {
+	res, err := Conn.Exec("DELETE FROM plan_shares WHERE plan_id = $1 AND user_id = $2", planId, userId)
+	if err != nil {
+		return fmt.Errorf("error deleting plan share: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListSharedPlans lists plans in the org that are shared with the user. Pass nil projectIds for every project.
+func ListSharedPlans(orgId, userId string, projectIds []string, archived bool) ([]*SharedPlan, error) {
+	qs := `SELECT plans.*, plan_shares.access FROM plans
+	JOIN plan_shares ON plan_shares.plan_id = plans.id
+	WHERE plan_shares.org_id = $1 AND plan_shares.user_id = $2 AND plans.org_id = $1`
+	qargs := []interface{}{orgId, userId}
+
+	if projectIds != nil {
+		qs += " AND plans.project_id = ANY($3)"
+		qargs = append(qargs, pq.Array(projectIds))
+	}
+
+	if archived {
+		qs += " AND plans.archived_at IS NOT NULL"
+	} else {
+		qs += " AND plans.archived_at IS NULL"
+	}
+
+	qs += " ORDER BY plans.updated_at DESC"
+
+	var plans []*SharedPlan
+	err := Conn.Select(&plans, qs, qargs...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing shared plans: %v", err)
+	}
+
+	return plans, nil
+}
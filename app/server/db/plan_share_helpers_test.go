@@ -0,0 +1,103 @@
+package db_test
+
+import (
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestValidatePlanAccessShares(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateOrg(t)
+	reader := org.AddMember(t, "reader")
+	collaborator := org.AddMember(t, "collaborator")
+	member := org.AddMember(t, "member")
+	org.SharePlan(t, reader.Id, shared.PlanShareAccessRead)
+	org.SharePlan(t, collaborator.Id, shared.PlanShareAccessCollaborator)
+
+	outsider := dbtest.CreateOrg(t)
+
+	tests := []struct {
+		name       string
+		userId     string
+		orgId      string
+		wantPlan   bool
+		wantAccess shared.PlanShareAccess
+	}{
+		{"owner", org.Owner.Id, org.Org.Id, true, ""},
+		{"read share", reader.Id, org.Org.Id, true, shared.PlanShareAccessRead},
+		{"collaborator share", collaborator.Id, org.Org.Id, true, shared.PlanShareAccessCollaborator},
+		{"not shared", member.Id, org.Org.Id, false, ""},
+		{"other org", outsider.Owner.Id, outsider.Org.Id, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, share, err := db.ValidatePlanAccess(org.Plan.Id, tt.userId, tt.orgId)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (plan != nil) != tt.wantPlan {
+				t.Fatalf("plan = %v, want access %v", plan, tt.wantPlan)
+			}
+
+			var access shared.PlanShareAccess
+			if share != nil {
+				access = share.Access
+			}
+			if access != tt.wantAccess {
+				t.Errorf("share access = %q, want %q", access, tt.wantAccess)
+			}
+		})
+	}
+
+	// changing a share's access replaces it rather than adding a second share
+	org.SharePlan(t, reader.Id, shared.PlanShareAccessCollaborator)
+	_, share, err := db.ValidatePlanAccess(org.Plan.Id, reader.Id, org.Org.Id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if share == nil || share.Access != shared.PlanShareAccessCollaborator {
+		t.Errorf("expected upgraded collaborator share, got %+v", share)
+	}
+}
+
+func TestStoreModelStreamClaimsBranchOnce(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateOrg(t)
+	collaborator := org.AddMember(t, "collaborator")
+	org.SharePlan(t, collaborator.Id, shared.PlanShareAccessCollaborator)
+
+	first := &db.ModelStream{OrgId: org.Org.Id, PlanId: org.Plan.Id, UserId: &org.Owner.Id, InternalIp: "127.0.0.1", Branch: "main"}
+	err := db.StoreModelStream(first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := &db.ModelStream{OrgId: org.Org.Id, PlanId: org.Plan.Id, UserId: &collaborator.Id, InternalIp: "127.0.0.2", Branch: "main"}
+	err = db.StoreModelStream(second)
+	if err != db.ErrModelStreamActive {
+		t.Fatalf("expected ErrModelStreamActive while the branch is claimed, got %v", err)
+	}
+
+	// other branches aren't blocked
+	other := &db.ModelStream{OrgId: org.Org.Id, PlanId: org.Plan.Id, UserId: &collaborator.Id, InternalIp: "127.0.0.2", Branch: "other"}
+	err = db.StoreModelStream(other)
+	if err != nil {
+		t.Fatalf("unexpected error claiming another branch: %v", err)
+	}
+
+	err = db.SetModelStreamFinished(first.Id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = db.StoreModelStream(second)
+	if err != nil {
+		t.Fatalf("expected the branch to be claimable once the stream finished, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
 )
 
 var orgOwnerRoleId string
@@ -74,7 +79,7 @@ func CacheOrgRoleIds() error {
 
 func cacheOrgOwnerRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'owner'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'owner'")
 
 	if err != nil {
 		return fmt.Errorf("error getting owner role id: %v", err)
@@ -87,7 +92,7 @@ func cacheOrgOwnerRoleId() error {
 
 func cacheOrgMemberRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'member'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member'")
 
 	if err != nil {
 		return fmt.Errorf("error getting member role id: %v", err)
@@ -97,3 +102,256 @@ func cacheOrgMemberRoleId() error {
 
 	return nil
 }
+
+// ErrOrgRoleExists is returned by CreateOrgRole when the org already has a role with the name
+var ErrOrgRoleExists = errors.New("org role already exists")
+
+// ErrOrgRoleInUse is returned by DeleteOrgRole when users or pending invites still have the role
+var ErrOrgRoleInUse = errors.New("org role is in use")
+
+// GetOrgRole returns a default role or one of the org's custom roles, or nil if the org has no role with the id
+func GetOrgRole(orgId, roleId string) (*OrgRole, error) {
+	var orgRole OrgRole
+	err := Conn.Get(&orgRole, "SELECT * FROM org_roles WHERE id = $1 AND (org_id IS NULL OR org_id = $2)", roleId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &orgRole, nil
+}
+
+// orgPermissionsQuery selects the permissions an org's roles can have: those without a resource, plus role-scoped permissions for the default roles and the org's custom roles
+const orgPermissionsQuery = `
+    SELECT * FROM permissions
+    WHERE resource_id IS NULL OR resource_id IN (SELECT id FROM org_roles WHERE org_id IS NULL OR org_id = $1)
+    ORDER BY name, description
+    `
+
+func ListOrgPermissions(orgId string) ([]*Permission, error) {
+	return listOrgPermissions(Conn, orgId)
+}
+
+func listOrgPermissions(q sqlx.Queryer, orgId string) ([]*Permission, error) {
+	var permissions []*Permission
+	err := sqlx.Select(q, &permissions, orgPermissionsQuery, orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing org permissions: %v", err)
+	}
+
+	return permissions, nil
+}
+
+// ListOrgRolePermissions returns the permission keys of each of the org's roles, keyed by role id
+func ListOrgRolePermissions(orgId string) (map[string][]string, error) {
+	query := `
+    SELECT orp.org_role_id, p.name, p.resource_id
+    FROM org_roles_permissions orp
+    JOIN permissions p ON p.id = orp.permission_id
+    JOIN org_roles r ON r.id = orp.org_role_id
+    WHERE (r.org_id IS NULL OR r.org_id = $1)
+    AND (p.resource_id IS NULL OR p.resource_id IN (SELECT id FROM org_roles WHERE org_id IS NULL OR org_id = $1))
+    ORDER BY p.name
+    `
+
+	rows, err := Conn.Query(query, orgId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing org role permissions: %v", err)
+	}
+	defer rows.Close()
+
+	res := map[string][]string{}
+	for rows.Next() {
+		var roleId, name string
+		var resourceId sql.NullString
+		if err := rows.Scan(&roleId, &name, &resourceId); err != nil {
+			return nil, fmt.Errorf("error scanning org role permission: %v", err)
+		}
+
+		res[roleId] = append(res[roleId], shared.PermissionKey(shared.Permission(name), resourceId.String))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing org role permissions: %v", err)
+	}
+
+	return res, nil
+}
+
+// CreateOrgRole creates a custom role with the given permission keys. It also creates the role's invite, remove and set role permissions, so users can be given the role.
+func CreateOrgRole(role *OrgRole, permissionKeys []string, tx *sqlx.Tx) error {
+	query := "INSERT INTO org_roles (org_id, name, label, description) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at"
+
+	err := tx.QueryRow(query, role.OrgId, role.Name, role.Label, role.Description).Scan(&role.Id, &role.CreatedAt, &role.UpdatedAt)
+
+	if err != nil {
+		if IsNonUniqueErr(err) {
+			return ErrOrgRoleExists
+		}
+		return fmt.Errorf("error creating org role: %v", err)
+	}
+
+	_, err = tx.Exec(`
+    INSERT INTO permissions (name, description, resource_id) VALUES
+      ($1, $2, $3), ($4, $5, $3), ($6, $7, $3)
+    `,
+		shared.PermissionInviteUser, fmt.Sprintf("Invite %s users to an org", role.Label), role.Id,
+		shared.PermissionRemoveUser, fmt.Sprintf("Remove %s users from an org", role.Label),
+		shared.PermissionSetUserRole, fmt.Sprintf("Update a %s user's role in an org", role.Label),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating org role permissions: %v", err)
+	}
+
+	return setOrgRolePermissions(role, permissionKeys, tx)
+}
+
+// UpdateOrgRole updates a custom role's label and description and replaces its permissions
+func UpdateOrgRole(role *OrgRole, permissionKeys []string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE org_roles SET label = $1, description = $2 WHERE id = $3 AND org_id = $4", role.Label, role.Description, role.Id, role.OrgId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role: %v", err)
+	}
+
+	_, err = tx.Exec(`
+    UPDATE permissions SET description = CASE name
+      WHEN $1 THEN $2
+      WHEN $3 THEN $4
+      WHEN $5 THEN $6
+    END
+    WHERE resource_id = $7
+    `,
+		shared.PermissionInviteUser, fmt.Sprintf("Invite %s users to an org", role.Label),
+		shared.PermissionRemoveUser, fmt.Sprintf("Remove %s users from an org", role.Label),
+		shared.PermissionSetUserRole, fmt.Sprintf("Update a %s user's role in an org", role.Label),
+		role.Id,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role permissions: %v", err)
+	}
+
+	return setOrgRolePermissions(role, permissionKeys, tx)
+}
+
+func setOrgRolePermissions(role *OrgRole, permissionKeys []string, tx *sqlx.Tx) error {
+	orgPermissions, err := listOrgPermissions(tx, *role.OrgId)
+	if err != nil {
+		return err
+	}
+
+	idsByKey := map[string]string{}
+	for _, permission := range orgPermissions {
+		idsByKey[permission.Key()] = permission.Id
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles_permissions WHERE org_role_id = $1", role.Id)
+	if err != nil {
+		return fmt.Errorf("error clearing org role permissions: %v", err)
+	}
+
+	for _, key := range permissionKeys {
+		permissionId, ok := idsByKey[key]
+		if !ok {
+			return fmt.Errorf("unknown permission: %s", key)
+		}
+
+		_, err = tx.Exec("INSERT INTO org_roles_permissions (org_role_id, permission_id) VALUES ($1, $2)", role.Id, permissionId)
+		if err != nil {
+			return fmt.Errorf("error adding org role permission: %v", err)
+		}
+	}
+
+	return syncOrgRoleManagers(*role.OrgId, tx)
+}
+
+// syncOrgRoleManagers lets the default owner and admin roles invite, remove and set the role of users with each of the org's custom roles, but only if they have every permission the custom role has--otherwise an admin could hand out more access than they have. A custom role can itself manage other custom roles, so grants are repeated until nothing changes.
+func syncOrgRoleManagers(orgId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec(`
+    DELETE FROM org_roles_permissions
+    WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NULL AND name IN ('owner', 'admin'))
+    AND permission_id IN (SELECT id FROM permissions WHERE resource_id IN (SELECT id FROM org_roles WHERE org_id = $1))
+    `, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error clearing org role managers: %v", err)
+	}
+
+	query := `
+    INSERT INTO org_roles_permissions (org_role_id, permission_id)
+    SELECT manager.id, p.id
+    FROM org_roles manager, org_roles custom, permissions p
+    WHERE manager.org_id IS NULL AND manager.name IN ('owner', 'admin')
+    AND custom.org_id = $1
+    AND p.resource_id = custom.id
+    AND NOT EXISTS (
+      SELECT 1 FROM org_roles_permissions WHERE org_role_id = manager.id AND permission_id = p.id
+    )
+    AND NOT EXISTS (
+      SELECT 1 FROM org_roles_permissions crp
+      JOIN permissions cp ON cp.id = crp.permission_id
+      WHERE crp.org_role_id = custom.id
+      AND (cp.resource_id IS NULL OR cp.resource_id != custom.id)
+      AND crp.permission_id NOT IN (SELECT permission_id FROM org_roles_permissions WHERE org_role_id = manager.id)
+    )
+    `
+
+	for {
+		res, err := tx.Exec(query, orgId)
+		if err != nil {
+			return fmt.Errorf("error syncing org role managers: %v", err)
+		}
+
+		numAdded, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error syncing org role managers: %v", err)
+		}
+
+		if numAdded == 0 {
+			return nil
+		}
+	}
+}
+
+// DeleteOrgRole deletes a custom role along with its role-scoped permissions. It fails with ErrOrgRoleInUse if any users or pending invites have the role.
+func DeleteOrgRole(orgId, roleId string, tx *sqlx.Tx) error {
+	var numInUse int
+	err := tx.Get(&numInUse, `
+    SELECT
+      (SELECT COUNT(*) FROM orgs_users WHERE org_id = $1 AND org_role_id = $2) +
+      (SELECT COUNT(*) FROM invites WHERE org_id = $1 AND org_role_id = $2 AND accepted_at IS NULL)
+    `, orgId, roleId)
+
+	if err != nil {
+		return fmt.Errorf("error checking if org role is in use: %v", err)
+	}
+
+	if numInUse > 0 {
+		return ErrOrgRoleInUse
+	}
+
+	// accepted invites only keep their role for the record
+	_, err = tx.Exec("UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member') WHERE org_id = $1 AND org_role_id = $2", orgId, roleId)
+	if err != nil {
+		return fmt.Errorf("error updating accepted invites: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM permissions WHERE resource_id = $1", roleId)
+	if err != nil {
+		return fmt.Errorf("error deleting org role permissions: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles WHERE id = $1 AND org_id = $2", roleId, orgId)
+	if err != nil {
+		return fmt.Errorf("error deleting org role: %v", err)
+	}
+
+	return nil
+}
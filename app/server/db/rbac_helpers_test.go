@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"context"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func createTestOrgRole(t *testing.T, orgId, name string, permissionKeys []string) *db.OrgRole {
+	t.Helper()
+
+	role := &db.OrgRole{OrgId: &orgId, Name: name, Label: name}
+	err := db.WithTx(context.Background(), "create test org role", func(tx *sqlx.Tx) error {
+		return db.CreateOrgRole(role, permissionKeys, tx)
+	})
+	if err != nil {
+		t.Fatalf("error creating role %s: %v", name, err)
+	}
+	return role
+}
+
+// canManage reports whether the default role can invite, remove and set the role of users with the custom role
+func canManage(t *testing.T, orgId, managerName string, custom *db.OrgRole) bool {
+	t.Helper()
+
+	manager, err := db.GetOrgRoleByName(orgId, managerName)
+	if err != nil || manager == nil {
+		t.Fatalf("error getting %s role: %v", managerName, err)
+	}
+
+	rolePermissions, err := db.ListOrgRolePermissions(orgId)
+	if err != nil {
+		t.Fatalf("error listing role permissions: %v", err)
+	}
+
+	permissions := shared.Permissions{}
+	for _, key := range rolePermissions[manager.Id] {
+		permissions[key] = true
+	}
+
+	numManaged := 0
+	for _, permission := range []shared.Permission{shared.PermissionInviteUser, shared.PermissionRemoveUser, shared.PermissionSetUserRole} {
+		if permissions.HasPermissionForResource(permission, custom.Id) {
+			numManaged++
+		}
+	}
+	if numManaged != 0 && numManaged != 3 {
+		t.Fatalf("%s has %d of the 3 manager permissions for %s", managerName, numManaged, custom.Name)
+	}
+	return numManaged == 3
+}
+
+func TestSyncOrgRoleManagers(t *testing.T) {
+	dbtest.Setup(t)
+
+	orgId := dbtest.CreateOrg(t).Org.Id
+
+	reviewer := createTestOrgRole(t, orgId, "reviewer", []string{string(shared.PermissionCreatePlan)})
+	billing := createTestOrgRole(t, orgId, "billing", []string{string(shared.PermissionManageBilling)})
+
+	// managing reviewers is granted in the first pass, which lets the second pass grant managing leads
+	lead := createTestOrgRole(t, orgId, "lead", []string{shared.PermissionKey(shared.PermissionInviteUser, reviewer.Id)})
+	// admins never get to manage billing users, so they can't manage users who can invite them either
+	billingLead := createTestOrgRole(t, orgId, "billing-lead", []string{shared.PermissionKey(shared.PermissionInviteUser, billing.Id)})
+
+	tests := []struct {
+		role      *db.OrgRole
+		wantAdmin bool
+	}{
+		{reviewer, true},
+		{billing, false},
+		{lead, true},
+		{billingLead, false},
+	}
+
+	for _, tt := range tests {
+		if !canManage(t, orgId, "owner", tt.role) {
+			t.Errorf("expected owner to manage %s", tt.role.Name)
+		}
+		if got := canManage(t, orgId, "admin", tt.role); got != tt.wantAdmin {
+			t.Errorf("admin manages %s = %v, want %v", tt.role.Name, got, tt.wantAdmin)
+		}
+	}
+
+	// grants are recomputed from scratch, so giving reviewers a permission admins lack takes away managing reviewers and leads
+	err := db.WithTx(context.Background(), "update test org role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgRole(reviewer, []string{string(shared.PermissionCreatePlan), string(shared.PermissionManageBilling)}, tx)
+	})
+	if err != nil {
+		t.Fatalf("error updating role: %v", err)
+	}
+
+	if canManage(t, orgId, "admin", reviewer) || canManage(t, orgId, "admin", lead) {
+		t.Errorf("expected admin to lose reviewer and lead after reviewers got manage_billing")
+	}
+	if !canManage(t, orgId, "owner", reviewer) || !canManage(t, orgId, "owner", lead) {
+		t.Errorf("expected owner to keep managing reviewer and lead")
+	}
+}
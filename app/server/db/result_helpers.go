@@ -176,7 +176,8 @@ func GetCurrentPlanState(params CurrentPlanStateParams) (*shared.CurrentPlanStat
 		}
 
 		for _, context := range contexts {
-			if context.FilePath != "" {
+			// symbols are only part of a file, so they can't stand in for its content
+			if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 				contextsByPath[context.FilePath] = context
 			}
 		}
@@ -438,6 +439,49 @@ type ApplyPlanParams struct {
 	CurrentPlanState       *shared.CurrentPlanState
 	CurrentPlanStateParams *CurrentPlanStateParams
 	CommitMsg              string
+
+	// if set, only pending results for these paths are applied
+	Paths []string
+}
+
+// filterResultsToApply returns the pending results to apply—only those for paths if it's non-empty—along with the descriptions that are fully applied by them
+func filterResultsToApply(planFileResults []*PlanFileResult, descriptions []*ConvoMessageDescription, paths []string) ([]*PlanFileResult, []*ConvoMessageDescription) {
+	var pathsSet map[string]bool
+	if len(paths) > 0 {
+		pathsSet = make(map[string]bool)
+		for _, path := range paths {
+			pathsSet[path] = true
+		}
+	}
+
+	var pendingDbResults []*PlanFileResult
+
+	// convo messages with results that are still pending after this apply
+	stillPendingConvoMessageIds := make(map[string]bool)
+
+	for _, result := range planFileResults {
+		apiResult := result.ToApi()
+		if apiResult.IsPending() {
+			if pathsSet != nil && !pathsSet[result.Path] {
+				stillPendingConvoMessageIds[result.ConvoMessageId] = true
+				continue
+			}
+			pendingDbResults = append(pendingDbResults, result)
+		}
+	}
+
+	// descriptions stay pending until all their results are applied so the remaining changes keep their commit messages
+	if len(stillPendingConvoMessageIds) > 0 {
+		var appliedDescriptions []*ConvoMessageDescription
+		for _, description := range descriptions {
+			if !stillPendingConvoMessageIds[description.ConvoMessageId] {
+				appliedDescriptions = append(appliedDescriptions, description)
+			}
+		}
+		descriptions = appliedDescriptions
+	}
+
+	return pendingDbResults, descriptions
 }
 
 func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error {
@@ -463,12 +507,7 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 		}
 	}
 
-	for _, result := range planFileResults {
-		apiResult := result.ToApi()
-		if apiResult.IsPending() {
-			pendingDbResults = append(pendingDbResults, result)
-		}
-	}
+	pendingDbResults, convoMessageDescriptions = filterResultsToApply(planFileResults, convoMessageDescriptions, params.Paths)
 
 	log.Printf("Pending db results: %d", len(pendingDbResults))
 
@@ -665,9 +704,17 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 
 	msg := "✅ Marked pending results as applied"
 
+	appliedPaths := make(map[string]bool)
+	for _, result := range pendingDbResults {
+		appliedPaths[result.Path] = true
+	}
+
 	currentFiles := currentPlanState.CurrentPlanFiles.Files
 	var sortedFiles []string
 	for path := range currentFiles {
+		if len(params.Paths) > 0 && !appliedPaths[path] {
+			continue
+		}
 		sortedFiles = append(sortedFiles, path)
 	}
 	sort.Strings(sortedFiles)
@@ -867,6 +914,20 @@ func RejectPlanFile(orgId, planId, filePathOrResultId string, now time.Time) err
 }
 
 func RejectReplacement(orgId, planId, resultId, replacementId string) error {
+	now := time.Now()
+	return updateReplacement(orgId, planId, resultId, replacementId, func(replacement *shared.Replacement) {
+		replacement.RejectedAt = &now
+	})
+}
+
+// EditReplacement swaps in new content for a pending replacement
+func EditReplacement(orgId, planId, resultId, replacementId, new string) error {
+	return updateReplacement(orgId, planId, resultId, replacementId, func(replacement *shared.Replacement) {
+		replacement.New = new
+	})
+}
+
+func updateReplacement(orgId, planId, resultId, replacementId string, fn func(replacement *shared.Replacement)) error {
 	resultsDir := getPlanResultsDir(orgId, planId)
 
 	bytes, err := os.ReadFile(filepath.Join(resultsDir, resultId+".json"))
@@ -882,16 +943,17 @@ func RejectReplacement(orgId, planId, resultId, replacementId string) error {
 		return fmt.Errorf("error unmarshalling result file: %v", err)
 	}
 
-	if result.RejectedAt != nil {
-		return nil
+	if result.AppliedAt != nil || result.RejectedAt != nil {
+		return fmt.Errorf("result is no longer pending: %s", resultId)
 	}
 
-	now := time.Now()
-
 	foundReplacement := false
 	for _, replacement := range result.Replacements {
 		if replacement.Id == replacementId {
-			replacement.RejectedAt = &now
+			if replacement.RejectedAt != nil {
+				return fmt.Errorf("replacement was already rejected: %s", replacementId)
+			}
+			fn(replacement)
 			foundReplacement = true
 			break
 		}
@@ -901,6 +963,16 @@ func RejectReplacement(orgId, planId, resultId, replacementId string) error {
 		return fmt.Errorf("replacement not found: %s", replacementId)
 	}
 
+	bytes, err = json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling result: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(resultsDir, resultId+".json"), bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing result file: %v", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestFilterResultsToApply(t *testing.T) {
+	now := time.Now()
+
+	results := []*PlanFileResult{
+		{Id: "a1", Path: "a.go", ConvoMessageId: "m1", Content: "a"},
+		{Id: "b1", Path: "b.go", ConvoMessageId: "m1", Replacements: []*shared.Replacement{{Id: "r1"}}},
+		{Id: "c1", Path: "c.go", ConvoMessageId: "m2", Content: "c"},
+		{Id: "a0", Path: "a.go", ConvoMessageId: "m0", Content: "old", AppliedAt: &now},
+		{Id: "d1", Path: "d.go", ConvoMessageId: "m3", Replacements: []*shared.Replacement{{Id: "r2", RejectedAt: &now}}},
+	}
+	descriptions := []*ConvoMessageDescription{
+		{Id: "d-m1", ConvoMessageId: "m1"},
+		{Id: "d-m2", ConvoMessageId: "m2"},
+		{Id: "d-m3", ConvoMessageId: "m3"},
+	}
+
+	ids := func(results []*PlanFileResult) []string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.Id)
+		}
+		return ids
+	}
+	descIds := func(descriptions []*ConvoMessageDescription) []string {
+		var ids []string
+		for _, d := range descriptions {
+			ids = append(ids, d.Id)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name      string
+		paths     []string
+		wantIds   []string
+		wantDescs []string
+	}{
+		{"everything", nil, []string{"a1", "b1", "c1"}, []string{"d-m1", "d-m2", "d-m3"}},
+		{"one path", []string{"c.go"}, []string{"c1"}, []string{"d-m2", "d-m3"}},
+		// m1 still has a pending result for b.go, so its description stays pending along with m2's
+		{"part of a message", []string{"a.go"}, []string{"a1"}, []string{"d-m3"}},
+		{"no pending results", []string{"d.go"}, nil, []string{"d-m3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResults, gotDescriptions := filterResultsToApply(results, descriptions, tt.paths)
+			if got := ids(gotResults); !equalStrings(got, tt.wantIds) {
+				t.Errorf("results = %v, want %v", got, tt.wantIds)
+			}
+			if got := descIds(gotDescriptions); !equalStrings(got, tt.wantDescs) {
+				t.Errorf("descriptions = %v, want %v", got, tt.wantDescs)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// keeps each insert well under postgres's bind parameter limit
+const semanticChunkInsertBatchSize = 500
+
+func GetSemanticIndexShas(projectId string) (map[string]string, error) {
+	var rows []struct {
+		Path string `db:"path"`
+		Sha  string `db:"sha"`
+	}
+	err := Conn.Select(&rows, "SELECT DISTINCT path, sha FROM semantic_chunks WHERE project_id = $1", projectId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting semantic index shas: %v", err)
+	}
+
+	res := make(map[string]string, len(rows))
+	for _, row := range rows {
+		res[row.Path] = row.Sha
+	}
+
+	return res, nil
+}
+
+func HasSemanticIndex(projectId string) (bool, error) {
+	var exists bool
+	err := Conn.Get(&exists, "SELECT EXISTS(SELECT 1 FROM semantic_chunks WHERE project_id = $1)", projectId)
+
+	if err != nil {
+		return false, fmt.Errorf("error checking semantic index: %v", err)
+	}
+
+	return exists, nil
+}
+
+// GetSemanticChunkTerms loads just the id and search terms of each of the project's chunks for bm25 ranking
+func GetSemanticChunkTerms(projectId string) ([]*SemanticChunk, error) {
+	var chunks []*SemanticChunk
+	err := Conn.Select(&chunks, "SELECT id, terms FROM semantic_chunks WHERE project_id = $1 ORDER BY path, start_line", projectId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting semantic chunk terms: %v", err)
+	}
+
+	return chunks, nil
+}
+
+func GetSemanticChunksByIds(projectId string, ids []string) ([]*SemanticChunk, error) {
+	var chunks []*SemanticChunk
+	err := Conn.Select(&chunks, "SELECT id, path, name, start_line, end_line, body FROM semantic_chunks WHERE project_id = $1 AND id = ANY($2)", projectId, pq.Array(ids))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting semantic chunks: %v", err)
+	}
+
+	return chunks, nil
+}
+
+// ReplaceSemanticChunks swaps out every indexed chunk for the given paths. A path with no chunks is removed from the index.
+func ReplaceSemanticChunks(ctx context.Context, projectId string, chunksByPath map[string][]*SemanticChunk) error {
+	if len(chunksByPath) == 0 {
+		return nil
+	}
+
+	var paths []string
+	var chunks []*SemanticChunk
+	for path, pathChunks := range chunksByPath {
+		paths = append(paths, path)
+		chunks = append(chunks, pathChunks...)
+	}
+
+	return WithTx(ctx, "replace semantic chunks", func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM semantic_chunks WHERE project_id = $1 AND path = ANY($2)", projectId, pq.Array(paths))
+		if err != nil {
+			return fmt.Errorf("error deleting semantic chunks: %v", err)
+		}
+
+		for i := 0; i < len(chunks); i += semanticChunkInsertBatchSize {
+			end := min(i+semanticChunkInsertBatchSize, len(chunks))
+
+			_, err = tx.NamedExec(`INSERT INTO semantic_chunks (org_id, project_id, path, sha, name, start_line, end_line, body, terms)
+			VALUES (:org_id, :project_id, :path, :sha, :name, :start_line, :end_line, :body, :terms)`, chunks[i:end])
+			if err != nil {
+				return fmt.Errorf("error inserting semantic chunks: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func DeleteSemanticChunks(projectId string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := Conn.Exec("DELETE FROM semantic_chunks WHERE project_id = $1 AND path = ANY($2)", projectId, pq.Array(paths))
+	if err != nil {
+		return fmt.Errorf("error deleting semantic chunks: %v", err)
+	}
+
+	return nil
+}
+
+// embedding sizes with an HNSW index--keep in sync with the 2025061900_semantic_vectors migration
+var indexedEmbeddingDims = map[int]bool{384: true, 768: true, 1024: true, 1536: true, 3072: true}
+
+var semanticVectorsMu sync.Mutex
+var semanticVectorsEnabled *bool
+
+// SemanticVectorsEnabled is true when the semantic index has a pgvector embedding column. It's only added by migrations if pgvector 0.8+ was available, so without it embedding models fall back to bm25.
+func SemanticVectorsEnabled() (bool, error) {
+	semanticVectorsMu.Lock()
+	defer semanticVectorsMu.Unlock()
+
+	if semanticVectorsEnabled != nil {
+		return *semanticVectorsEnabled, nil
+	}
+
+	var enabled bool
+	err := Conn.Get(&enabled, "SELECT EXISTS(SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'semantic_chunks' AND column_name = 'embedding')")
+	if err != nil {
+		return false, fmt.Errorf("error checking for semantic index embeddings: %v", err)
+	}
+
+	semanticVectorsEnabled = &enabled
+	return enabled, nil
+}
+
+func CountUnembeddedSemanticChunks(projectId, embeddingModel string) (int, error) {
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM semantic_chunks WHERE project_id = $1 AND embedding_model IS DISTINCT FROM $2", projectId, embeddingModel)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting unembedded semantic chunks: %v", err)
+	}
+
+	return count, nil
+}
+
+// GetUnembeddedSemanticChunks returns up to limit chunks that don't have an embedding from the given model yet
+func GetUnembeddedSemanticChunks(projectId, embeddingModel string, limit int) ([]*SemanticChunk, error) {
+	var chunks []*SemanticChunk
+	err := Conn.Select(&chunks, "SELECT id, path, name, body FROM semantic_chunks WHERE project_id = $1 AND embedding_model IS DISTINCT FROM $2 ORDER BY id LIMIT $3", projectId, embeddingModel, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting unembedded semantic chunks: %v", err)
+	}
+
+	return chunks, nil
+}
+
+func StoreSemanticEmbeddings(ctx context.Context, embeddingModel string, embeddingsById map[string][]float32) error {
+	if len(embeddingsById) == 0 {
+		return nil
+	}
+
+	return WithTx(ctx, "store semantic embeddings", func(tx *sqlx.Tx) error {
+		for id, embedding := range embeddingsById {
+			_, err := tx.Exec("UPDATE semantic_chunks SET embedding_model = $1, embedding = $2::halfvec, embedding_dims = $3 WHERE id = $4", embeddingModel, vectorLiteral(embedding), len(embedding), id)
+			if err != nil {
+				return fmt.Errorf("error storing semantic embedding: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// SearchSemanticEmbeddings returns the project's chunks nearest to the query embedding by cosine distance, best first, with Score set to cosine similarity
+func SearchSemanticEmbeddings(ctx context.Context, projectId, embeddingModel string, queryEmbedding []float32, limit int) ([]*SemanticChunk, error) {
+	var chunks []*SemanticChunk
+
+	err := WithTx(ctx, "search semantic embeddings", func(tx *sqlx.Tx) error {
+		// the HNSW index spans every project--iterative scans keep going until enough of this project's chunks are found
+		_, err := tx.Exec("SET LOCAL hnsw.iterative_scan = relaxed_order")
+		if err != nil {
+			return fmt.Errorf("error setting hnsw iterative scan: %v", err)
+		}
+
+		err = tx.Select(&chunks, semanticEmbeddingSearchQuery(len(queryEmbedding)), vectorLiteral(queryEmbedding), projectId, embeddingModel, limit)
+		if err != nil {
+			return fmt.Errorf("error searching semantic embeddings: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// relaxed order can return neighbors slightly out of order
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Score > chunks[j].Score
+	})
+
+	return chunks, nil
+}
+
+// semanticEmbeddingSearchQuery casts to the query's dimensions when they have an HNSW index so postgres can use it. Dimensions are an int, so formatting them into the query is safe.
+func semanticEmbeddingSearchQuery(dims int) string {
+	embedding := "embedding"
+	query := "$1::halfvec"
+	if indexedEmbeddingDims[dims] {
+		embedding = fmt.Sprintf("(embedding::halfvec(%d))", dims)
+		query = fmt.Sprintf("$1::halfvec(%d)", dims)
+	}
+
+	return fmt.Sprintf(`SELECT id, path, name, start_line, end_line, body, 1 - (%[1]s <=> %[2]s) AS score
+	FROM semantic_chunks
+	WHERE project_id = $2 AND embedding_model = $3 AND embedding_dims = %[3]d
+	ORDER BY %[1]s <=> %[2]s
+	LIMIT $4`, embedding, query, dims)
+}
+
+// vectorLiteral formats an embedding in pgvector's text format
+func vectorLiteral(embedding []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range embedding {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
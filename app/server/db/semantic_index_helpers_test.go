@@ -0,0 +1,41 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVectorLiteral(t *testing.T) {
+	got := vectorLiteral([]float32{1, -0.5, 0.125, 3e-8})
+	want := "[1,-0.5,0.125,3e-08]"
+	if got != want {
+		t.Errorf("vectorLiteral() = %q, want %q", got, want)
+	}
+}
+
+func TestSemanticEmbeddingSearchQuery(t *testing.T) {
+	tests := []struct {
+		dims    int
+		want    string
+		notWant string
+	}{
+		// cast to match the partial HNSW index for this size
+		{1536, "ORDER BY (embedding::halfvec(1536)) <=> $1::halfvec(1536)", ""},
+		{3072, "embedding_dims = 3072", ""},
+		// no index for this size, so it's an exact scan of the project's chunks
+		{100, "ORDER BY embedding <=> $1::halfvec", "halfvec(100)"},
+	}
+
+	for _, tt := range tests {
+		query := semanticEmbeddingSearchQuery(tt.dims)
+		if !strings.Contains(query, tt.want) {
+			t.Errorf("query for %d dims is missing %q:\n%s", tt.dims, tt.want, query)
+		}
+		if tt.notWant != "" && strings.Contains(query, tt.notWant) {
+			t.Errorf("query for %d dims shouldn't contain %q:\n%s", tt.dims, tt.notWant, query)
+		}
+		if !strings.Contains(query, "embedding_model = $3") || !strings.Contains(query, "project_id = $2") {
+			t.Errorf("query for %d dims isn't scoped to the project and model:\n%s", tt.dims, query)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -15,12 +16,44 @@ import (
 const modelStreamHeartbeatInterval = 1 * time.Second
 const modelStreamHeartbeatTimeout = 5 * time.Second
 
-func StoreModelStream(stream *ModelStream, ctx context.Context, cancelFn context.CancelFunc) error {
-	query := `INSERT INTO model_streams (org_id, plan_id, internal_ip, branch) VALUES (:org_id, :plan_id, :internal_ip, :branch) RETURNING id, created_at`
+// how long a resumable stream whose instance stopped heartbeating waits for another instance to take it over before it's set to error
+const modelStreamTakeoverTimeout = 60 * time.Second
+
+// NotifyPayloadLimit is the largest payload postgres accepts for NOTIFY (the server limit is 8000 bytes)
+const NotifyPayloadLimit = 7999
+
+func Notify(channel, payload string) error {
+	_, err := Conn.Exec("SELECT pg_notify($1, $2)", channel, payload)
+
+	if err != nil {
+		return fmt.Errorf("error sending notification: %v", err)
+	}
+
+	return nil
+}
+
+// NewListener opens a dedicated connection for LISTEN--notifications can't be received on pooled connections
+func NewListener(eventCallback pq.EventCallbackType) (*pq.Listener, error) {
+	if connUrl == "" {
+		return nil, fmt.Errorf("db not initialized")
+	}
+
+	return pq.NewListener(connUrl, 10*time.Second, time.Minute, eventCallback), nil
+}
+
+// ErrModelStreamActive is returned by StoreModelStream when the plan branch already has an unfinished stream
+var ErrModelStreamActive = errors.New("plan branch already has an active model stream")
+
+// StoreModelStream claims the plan branch for a new stream. Only one unfinished stream is allowed per branch, so this fails with ErrModelStreamActive if another user or host got there first.
+func StoreModelStream(stream *ModelStream) error {
+	query := `INSERT INTO model_streams (org_id, plan_id, user_id, internal_ip, branch) VALUES (:org_id, :plan_id, :user_id, :internal_ip, :branch) RETURNING id, created_at`
 
 	row, err := Conn.NamedQuery(query, stream)
 
 	if err != nil {
+		if IsNonUniqueErr(err) {
+			return ErrModelStreamActive
+		}
 		return fmt.Errorf("error storing model stream: %v", err)
 	}
 
@@ -37,20 +70,25 @@ func StoreModelStream(stream *ModelStream, ctx context.Context, cancelFn context
 		stream.CreatedAt = createdAt
 	}
 
+	return nil
+}
+
+// StartModelStreamHeartbeat keeps a stored stream's claim alive until ctx is done, then marks it finished. If another instance takes the stream over (see ClaimOrphanedModelStream), the heartbeat stops and cancels ctx without finishing the stream.
+func StartModelStreamHeartbeat(stream *ModelStream, ctx context.Context, cancelFn context.CancelFunc) {
 	// Start a goroutine to keep the lock alive
 	go func() {
 		numErrors := 0
 		for {
 			select {
 			case <-ctx.Done():
-				err := SetModelStreamFinished(stream.Id)
+				_, err := Conn.Exec("UPDATE model_streams SET finished_at = NOW() WHERE id = $1 AND internal_ip = $2", stream.Id, stream.InternalIp)
 				if err != nil {
 					log.Printf("Error setting model stream %s finished: %v\n", stream.Id, err)
 				}
 				return
 
 			default:
-				_, err := Conn.Exec("UPDATE model_streams SET last_heartbeat_at = NOW() WHERE id = $1", stream.Id)
+				res, err := Conn.Exec("UPDATE model_streams SET last_heartbeat_at = NOW() WHERE id = $1 AND internal_ip = $2", stream.Id, stream.InternalIp)
 
 				if err != nil {
 					log.Printf("Error updating model stream last heartbeat: %v\n", err)
@@ -61,6 +99,10 @@ func StoreModelStream(stream *ModelStream, ctx context.Context, cancelFn context
 						cancelFn()
 						return
 					}
+				} else if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+					log.Printf("Model stream %s was taken over by another instance--stopping\n", stream.Id)
+					cancelFn()
+					return
 				}
 
 				time.Sleep(modelStreamHeartbeatInterval)
@@ -68,8 +110,6 @@ func StoreModelStream(stream *ModelStream, ctx context.Context, cancelFn context
 
 		}
 	}()
-
-	return nil
 }
 
 func SetModelStreamFinished(id string) error {
@@ -86,6 +126,12 @@ func SetModelStreamFinished(id string) error {
 	return nil
 }
 
+// Orphaned is true when the stream's instance has stopped sending heartbeats, usually because it was restarted
+func (stream *ModelStream) Orphaned() bool {
+	return time.Since(stream.LastHeartbeatAt) > modelStreamHeartbeatTimeout
+}
+
+// GetActiveModelStream returns the plan branch's unfinished stream. A resumable stream that's been orphaned for less than modelStreamTakeoverTimeout is still returned so another instance can take it over; other orphaned streams are finished and the plan is set to error.
 func GetActiveModelStream(planId, branch string) (*ModelStream, error) {
 	var stream ModelStream
 	err := Conn.Get(&stream, "SELECT * FROM model_streams WHERE plan_id = $1 AND branch = $2 AND finished_at IS NULL", planId, branch)
@@ -98,29 +144,82 @@ func GetActiveModelStream(planId, branch string) (*ModelStream, error) {
 		return nil, fmt.Errorf("error getting active model stream: %v", err)
 	}
 
-	if time.Now().Add(-modelStreamHeartbeatTimeout).After(stream.LastHeartbeatAt) {
-		log.Printf("Model stream %s has not sent a heartbeat in %s\n", stream.Id, modelStreamHeartbeatTimeout)
+	if !stream.Orphaned() {
+		log.Printf("Model stream %s sent heartbeat %d seconds ago\n", stream.Id, int(time.Since(stream.LastHeartbeatAt).Seconds()))
+		return &stream, nil
+	}
 
-		err := SetModelStreamFinished(stream.Id)
+	log.Printf("Model stream %s has not sent a heartbeat in %s\n", stream.Id, modelStreamHeartbeatTimeout)
 
-		if err != nil {
-			return nil, fmt.Errorf("error setting model stream finished: %v", err)
-		}
+	if stream.ResumeRequest != nil && time.Since(stream.LastHeartbeatAt) < modelStreamTakeoverTimeout {
+		log.Printf("Model stream %s can be resumed by another instance\n", stream.Id)
+		return &stream, nil
+	}
 
-		err = SetPlanStatus(planId, branch, shared.PlanStatusError, "Model stream has not sent a heartbeat in 5 seconds")
+	finished, err := FinishOrphanedModelStream(stream.Id)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("error setting plan status to error: %v", err)
-		}
+	if !finished {
+		// taken over since it was loaded
+		return GetActiveModelStream(planId, branch)
+	}
 
-		return nil, nil
-	} else {
-		log.Printf("Model stream %s sent heartbeat %d seconds ago\n", stream.Id, int(time.Since(stream.LastHeartbeatAt).Seconds()))
+	err = SetPlanStatus(planId, branch, shared.PlanStatusError, "The server running the plan stopped")
+
+	if err != nil {
+		return nil, fmt.Errorf("error setting plan status to error: %v", err)
+	}
+
+	return nil, nil
+}
+
+// ErrModelStreamNotOrphaned is returned by ClaimOrphanedModelStream when the stream is finished, its instance is heartbeating again, or another instance claimed it first
+var ErrModelStreamNotOrphaned = errors.New("model stream isn't orphaned")
+
+// ClaimOrphanedModelStream hands an orphaned stream to the instance at internalIp. Only one instance can claim it--the original instance stops when its next heartbeat finds it's no longer the owner.
+func ClaimOrphanedModelStream(id, internalIp string) (*ModelStream, error) {
+	var stream ModelStream
+	query := fmt.Sprintf("UPDATE model_streams SET internal_ip = $2, last_heartbeat_at = NOW() WHERE id = $1 AND finished_at IS NULL AND last_heartbeat_at < NOW() - INTERVAL '%d seconds' RETURNING *", int(modelStreamHeartbeatTimeout.Seconds()))
+	err := Conn.Get(&stream, query, id, internalIp)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrModelStreamNotOrphaned
+		}
+		return nil, fmt.Errorf("error claiming model stream: %v", err)
 	}
 
 	return &stream, nil
 }
 
+// FinishOrphanedModelStream finishes a stream only if it's still orphaned, so a stream that was just taken over is left alone. Returns whether it was finished.
+func FinishOrphanedModelStream(id string) (bool, error) {
+	query := fmt.Sprintf("UPDATE model_streams SET finished_at = NOW() WHERE id = $1 AND finished_at IS NULL AND last_heartbeat_at < NOW() - INTERVAL '%d seconds'", int(modelStreamHeartbeatTimeout.Seconds()))
+	res, err := Conn.Exec(query, id)
+	if err != nil {
+		return false, fmt.Errorf("error finishing orphaned model stream: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error finishing orphaned model stream: %v", err)
+	}
+
+	return rows > 0, nil
+}
+
+func SetModelStreamResumeRequest(id string, req *shared.TellPlanRequest) error {
+	_, err := Conn.Exec("UPDATE model_streams SET resume_request = $1 WHERE id = $2", req, id)
+
+	if err != nil {
+		return fmt.Errorf("error setting model stream resume request: %v", err)
+	}
+
+	return nil
+}
+
 func GetActiveOrRecentModelStreams(planIds []string) ([]*ModelStream, error) {
 	var streams []*ModelStream
 	err := Conn.Select(&streams, "SELECT * FROM model_streams WHERE plan_id = ANY($1) AND (finished_at IS NULL OR finished_at > NOW() - INTERVAL '1 hour') ORDER BY created_at", pq.Array(planIds))
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func CreateModelUsage(usage *ModelUsage) error {
+	query := `INSERT INTO model_usage (org_id, user_id, plan_id, model_id, model_provider, model_name, model_role, model_pack_name, purpose, input_tokens, output_tokens, cached_tokens, reasoning_tokens, cost, cache_savings, generation_id, build_id, convo_message_id, session_id, stopped_early, user_cancelled, had_error, no_reported_usage)
+	VALUES (:org_id, :user_id, :plan_id, :model_id, :model_provider, :model_name, :model_role, :model_pack_name, :purpose, :input_tokens, :output_tokens, :cached_tokens, :reasoning_tokens, :cost, :cache_savings, :generation_id, :build_id, :convo_message_id, :session_id, :stopped_early, :user_cancelled, :had_error, :no_reported_usage)
+	RETURNING id, created_at`
+
+	rows, err := Conn.NamedQuery(query, usage)
+	if err != nil {
+		return fmt.Errorf("error creating model usage: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&usage.Id, &usage.CreatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error creating model usage: %v", err)
+	}
+
+	return nil
+}
+
+type UsageFilter struct {
+	OrgId     string
+	UserId    string
+	PlanId    string
+	SessionId string
+	Since     *time.Time
+}
+
+func (f UsageFilter) where() (string, []interface{}) {
+	conditions := []string{"org_id = $1"}
+	args := []interface{}{f.OrgId}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.UserId != "" {
+		add("user_id = $%d", f.UserId)
+	}
+	if f.PlanId != "" {
+		add("plan_id = $%d", f.PlanId)
+	}
+	if f.SessionId != "" {
+		add("session_id = $%d", f.SessionId)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d", *f.Since)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+type usageBreakdownRow struct {
+	Key          *string         `db:"key"`
+	Spend        decimal.Decimal `db:"spend"`
+	NumRequests  int             `db:"num_requests"`
+	InputTokens  int             `db:"input_tokens"`
+	OutputTokens int             `db:"output_tokens"`
+	CachedTokens int             `db:"cached_tokens"`
+	CacheSavings decimal.Decimal `db:"cache_savings"`
+}
+
+func (r usageBreakdownRow) toApi() shared.UsageBreakdown {
+	return shared.UsageBreakdown{
+		Spend:        r.Spend,
+		NumRequests:  r.NumRequests,
+		InputTokens:  r.InputTokens,
+		OutputTokens: r.OutputTokens,
+		CachedTokens: r.CachedTokens,
+	}
+}
+
+const usageBreakdownColumns = `COALESCE(SUM(cost), 0) AS spend, COUNT(*) AS num_requests, COALESCE(SUM(input_tokens), 0) AS input_tokens, COALESCE(SUM(output_tokens), 0) AS output_tokens, COALESCE(SUM(cached_tokens), 0) AS cached_tokens, COALESCE(SUM(cache_savings), 0) AS cache_savings`
+
+// GetUsageSummary totals the usage ledger for the filter, along with breakdowns by plan, model, model role, and purpose
+func GetUsageSummary(filter UsageFilter) (*shared.UsageSummaryResponse, error) {
+	where, args := filter.where()
+
+	var total usageBreakdownRow
+	err := Conn.Get(&total, fmt.Sprintf("SELECT NULL AS key, %s FROM model_usage WHERE %s", usageBreakdownColumns, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting usage total: %v", err)
+	}
+
+	res := &shared.UsageSummaryResponse{
+		Total:        total.toApi(),
+		CacheSavings: total.CacheSavings,
+	}
+
+	groupBy := func(column string) (map[string]shared.UsageBreakdown, error) {
+		var rows []usageBreakdownRow
+		query := fmt.Sprintf("SELECT %s::text AS key, %s FROM model_usage WHERE %s GROUP BY %s", column, usageBreakdownColumns, where, column)
+		err := Conn.Select(&rows, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting usage by %s: %v", column, err)
+		}
+
+		byKey := map[string]shared.UsageBreakdown{}
+		for _, row := range rows {
+			if row.Key == nil {
+				continue
+			}
+			byKey[*row.Key] = row.toApi()
+		}
+		return byKey, nil
+	}
+
+	res.ByPlanId, err = groupBy("plan_id")
+	if err != nil {
+		return nil, err
+	}
+	res.ByModelName, err = groupBy("model_name")
+	if err != nil {
+		return nil, err
+	}
+	res.ByModelRole, err = groupBy("model_role")
+	if err != nil {
+		return nil, err
+	}
+	res.ByPurpose, err = groupBy("purpose")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListUsage returns a page of usage ledger entries for the filter, newest first, along with the total number of matching entries
+func ListUsage(filter UsageFilter, limit, offset int) ([]*ModelUsage, int, error) {
+	where, args := filter.where()
+
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM model_usage WHERE "+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting model usage: %v", err)
+	}
+
+	var usage []*ModelUsage
+	query := fmt.Sprintf("SELECT * FROM model_usage WHERE %s ORDER BY created_at DESC LIMIT %d OFFSET %d", where, limit, offset)
+	err = Conn.Select(&usage, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing model usage: %v", err)
+	}
+
+	return usage, count, nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func ListWebhooks(orgId string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	err := Conn.Select(&webhooks, "SELECT * FROM webhooks WHERE org_id = $1 ORDER BY created_at", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+func GetWebhook(orgId, id string) (*Webhook, error) {
+	var webhook Webhook
+	err := Conn.Get(&webhook, "SELECT * FROM webhooks WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting webhook: %v", err)
+	}
+
+	return &webhook, nil
+}
+
+func CreateWebhook(webhook *Webhook) error {
+	query := `INSERT INTO webhooks (org_id, url, secret, events, created_by)
+	VALUES (:org_id, :url, :secret, :events, :created_by)
+	RETURNING id, created_at, updated_at`
+
+	rows, err := Conn.NamedQuery(query, webhook)
+	if err != nil {
+		return fmt.Errorf("error creating webhook: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&webhook.Id, &webhook.CreatedAt, &webhook.UpdatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteWebhook(orgId, id string) error {
+	res, err := Conn.Exec("DELETE FROM webhooks WHERE org_id = $1 AND id = $2", orgId, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (webhook_id, org_id, event, payload)
+	VALUES (:webhook_id, :org_id, :event, :payload)
+	RETURNING id, status, next_attempt_at, created_at, updated_at`
+
+	rows, err := Conn.NamedQuery(query, delivery)
+	if err != nil {
+		return fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&delivery.Id, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+func ListWebhookDeliveries(orgId, webhookId string, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	err := Conn.Select(&deliveries, "SELECT * FROM webhook_deliveries WHERE org_id = $1 AND webhook_id = $2 ORDER BY created_at DESC LIMIT $3", orgId, webhookId, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDeliveries picks up to 'limit' pending deliveries that are due and pushes back their next attempt by 'lease', so other server instances skip them while they're in flight. If this instance dies mid-delivery, they're retried once the lease runs out.
+func ClaimWebhookDeliveries(limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+	WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status = $3 AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *`
+
+	var deliveries []*WebhookDelivery
+	err := Conn.Select(&deliveries, query, limit, lease.Milliseconds(), shared.WebhookDeliveryStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDeliveryAttempt records the result of a delivery attempt. A nil retryIn means there are no retries left.
+func UpdateWebhookDeliveryAttempt(id string, succeeded bool, statusCode *int, errMsg *string, retryIn *time.Duration) error {
+	var query string
+	var args []interface{}
+
+	if succeeded {
+		query = "UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = NULL, delivered_at = NOW() WHERE id = $1"
+		args = []interface{}{id, shared.WebhookDeliveryStatusSucceeded, statusCode}
+	} else if retryIn == nil {
+		query = "UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4 WHERE id = $1"
+		args = []interface{}{id, shared.WebhookDeliveryStatusFailed, statusCode, errMsg}
+	} else {
+		query = "UPDATE webhook_deliveries SET attempts = attempts + 1, last_status_code = $2, last_error = $3, next_attempt_at = NOW() + $4 * INTERVAL '1 millisecond' WHERE id = $1"
+		args = []interface{}{id, statusCode, errMsg, retryIn.Milliseconds()}
+	}
+
+	_, err := Conn.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteOldWebhookDeliveries prunes finished deliveries--pending ones are kept until they succeed or run out of retries
+func DeleteOldWebhookDeliveries(olderThan time.Duration) error {
+	_, err := Conn.Exec("DELETE FROM webhook_deliveries WHERE status != $1 AND created_at < NOW() - $2 * INTERVAL '1 millisecond'", shared.WebhookDeliveryStatusPending, olderThan.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("error deleting old webhook deliveries: %v", err)
+	}
+
+	return nil
+}
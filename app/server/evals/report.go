@@ -0,0 +1,288 @@
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type CaseResult struct {
+	Case               string   `json:"case"`
+	Path               string   `json:"path"`
+	Passed             bool     `json:"passed"`
+	SyntaxValid        bool     `json:"syntaxValid"`
+	SyntaxErrors       []string `json:"syntaxErrors,omitempty"`
+	Similarity         float64  `json:"similarity"`
+	NeedsVerifyReasons []string `json:"needsVerifyReasons,omitempty"`
+	// the builder model ran its validate-and-fix loop
+	Validated         bool   `json:"validated"`
+	ValidationProblem string `json:"validationProblem,omitempty"`
+	Error             string `json:"error,omitempty"`
+	DurationMs        int64  `json:"durationMs"`
+
+	// the tree-sitter apply strategy on its own, for cases with references--only run in the apply-only baseline since it doesn't use a model
+	TreeSitter *StageResult `json:"treeSitter,omitempty"`
+
+	// the built file--written next to the report rather than into it
+	Result string `json:"-"`
+}
+
+// StageResult scores a single stage of the build
+type StageResult struct {
+	Passed             bool     `json:"passed"`
+	SyntaxValid        bool     `json:"syntaxValid"`
+	Similarity         float64  `json:"similarity"`
+	NeedsVerifyReasons []string `json:"needsVerifyReasons,omitempty"`
+	Error              string   `json:"error,omitempty"`
+
+	Result string `json:"-"`
+}
+
+func (res *StageResult) score(ctx context.Context, c *Case) {
+	res.SyntaxValid = res.Error == "" && len(getSyntaxErrors(ctx, c.Path, res.Result)) == 0
+	res.Passed = res.Error == "" && normalize(res.Result) == normalize(c.Expected)
+	res.Similarity = similarity(res.Result, c.Expected)
+}
+
+func (res *CaseResult) score(ctx context.Context, c *Case) {
+	res.SyntaxErrors = getSyntaxErrors(ctx, c.Path, res.Result)
+	res.SyntaxValid = res.Error == "" && len(res.SyntaxErrors) == 0
+	res.Passed = res.Error == "" && normalize(res.Result) == normalize(c.Expected)
+	res.Similarity = similarity(res.Result, c.Expected)
+}
+
+type PackSummary struct {
+	Cases         int     `json:"cases"`
+	Passed        int     `json:"passed"`
+	SyntaxValid   int     `json:"syntaxValid"`
+	AvgSimilarity float64 `json:"avgSimilarity"`
+	Validated     int     `json:"validated"`
+	Errors        int     `json:"errors"`
+	DurationMs    int64   `json:"durationMs"`
+
+	TreeSitterCases  int `json:"treeSitterCases,omitempty"`
+	TreeSitterPassed int `json:"treeSitterPassed,omitempty"`
+}
+
+type PackReport struct {
+	ModelPack string        `json:"modelPack"`
+	Builder   string        `json:"builder,omitempty"`
+	Summary   PackSummary   `json:"summary"`
+	Results   []*CaseResult `json:"results"`
+}
+
+func (r *PackReport) summarize() {
+	summary := PackSummary{Cases: len(r.Results)}
+	var totalSimilarity float64
+
+	for _, res := range r.Results {
+		if res.Passed {
+			summary.Passed++
+		}
+		if res.SyntaxValid {
+			summary.SyntaxValid++
+		}
+		if res.Validated {
+			summary.Validated++
+		}
+		if res.Error != "" {
+			summary.Errors++
+		}
+		if res.TreeSitter != nil {
+			summary.TreeSitterCases++
+			if res.TreeSitter.Passed {
+				summary.TreeSitterPassed++
+			}
+		}
+		totalSimilarity += res.Similarity
+		summary.DurationMs += res.DurationMs
+	}
+
+	if summary.Cases > 0 {
+		summary.AvgSimilarity = totalSimilarity / float64(summary.Cases)
+	}
+
+	r.Summary = summary
+}
+
+type Report struct {
+	StartedAt time.Time     `json:"startedAt"`
+	Transport string        `json:"transport"`
+	Suites    []string      `json:"suites"`
+	Packs     []*PackReport `json:"packs"`
+}
+
+// Write writes report.json, a report.md comparing the model packs, and each pack's built files under results/<pack>/<suite>/<case>
+func (r *Report) Write(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating report dir: %v", err)
+	}
+
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling report: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "report.json"), bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing report.json: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "report.md"), []byte(r.Markdown()), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing report.md: %v", err)
+	}
+
+	for _, pack := range r.Packs {
+		for _, res := range pack.Results {
+			resultPath := filepath.Join(dir, "results", pack.ModelPack, res.Case, "result"+filepath.Ext(res.Path))
+
+			err = os.MkdirAll(filepath.Dir(resultPath), 0755)
+			if err != nil {
+				return fmt.Errorf("error creating results dir: %v", err)
+			}
+
+			err = os.WriteFile(resultPath, []byte(res.Result), 0644)
+			if err != nil {
+				return fmt.Errorf("error writing result for %s: %v", res.Case, err)
+			}
+
+			if res.TreeSitter != nil {
+				treeSitterPath := filepath.Join(filepath.Dir(resultPath), "tree-sitter"+filepath.Ext(res.Path))
+				err = os.WriteFile(treeSitterPath, []byte(res.TreeSitter.Result), 0644)
+				if err != nil {
+					return fmt.Errorf("error writing tree-sitter result for %s: %v", res.Case, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Evals report\n\n")
+	fmt.Fprintf(&b, "Started %s with %s model responses. Suites: %s.\n\n", r.StartedAt.Format(time.RFC3339), r.Transport, strings.Join(r.Suites, ", "))
+
+	b.WriteString("## Model packs\n\n")
+	b.WriteString("| Model pack | Builder | Passed | Syntax valid | Avg similarity | Validated | Errors | Duration |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, pack := range r.Packs {
+		s := pack.Summary
+		builder := pack.Builder
+		if builder == "" {
+			builder = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %d/%d | %.3f | %d | %d | %s |\n",
+			pack.ModelPack, builder, s.Passed, s.Cases, s.SyntaxValid, s.Cases, s.AvgSimilarity, s.Validated, s.Errors,
+			(time.Duration(s.DurationMs) * time.Millisecond).String())
+	}
+
+	if len(r.Packs) == 0 || len(r.Packs[0].Results) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n## Cases\n\n")
+	b.WriteString("Similarity to the expected file; ✓ passed, ✗ invalid syntax.\n\n")
+
+	b.WriteString("| Case |")
+	for _, pack := range r.Packs {
+		fmt.Fprintf(&b, " %s |", pack.ModelPack)
+	}
+	b.WriteString("\n|---|")
+	for range r.Packs {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for i, res := range r.Packs[0].Results {
+		fmt.Fprintf(&b, "| %s |", res.Case)
+		for _, pack := range r.Packs {
+			fmt.Fprintf(&b, " %s |", formatCell(pack.Results[i]))
+		}
+		b.WriteString("\n")
+	}
+
+	baseline := r.Packs[0]
+	if baseline.Summary.TreeSitterCases > 0 {
+		b.WriteString("\n## Tree-sitter apply\n\n")
+		fmt.Fprintf(&b, "ApplyChanges only uses tree-sitter when a reference is ambiguous. Here it's run on its own for every case with references: %d/%d passed.\n\n", baseline.Summary.TreeSitterPassed, baseline.Summary.TreeSitterCases)
+		b.WriteString("| Case | ApplyChanges | Tree-sitter |\n")
+		b.WriteString("|---|---|---|\n")
+		for _, res := range baseline.Results {
+			if res.TreeSitter == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", res.Case, formatCell(res), formatStageCell(res.TreeSitter))
+		}
+	}
+
+	var failures []string
+	for _, pack := range r.Packs {
+		for _, res := range pack.Results {
+			if res.Passed {
+				continue
+			}
+			var details []string
+			if res.Error != "" {
+				details = append(details, "error: "+res.Error)
+			}
+			if len(res.NeedsVerifyReasons) > 0 {
+				details = append(details, "needs verify: "+strings.Join(res.NeedsVerifyReasons, ", "))
+			}
+			if len(res.SyntaxErrors) > 0 {
+				details = append(details, fmt.Sprintf("%d syntax errors", len(res.SyntaxErrors)))
+			}
+			if res.ValidationProblem != "" {
+				details = append(details, "validation: "+strings.ReplaceAll(strings.TrimSpace(res.ValidationProblem), "\n", " "))
+			}
+			if len(details) == 0 {
+				details = append(details, "differs from expected")
+			}
+			failures = append(failures, fmt.Sprintf("- %s | %s: %s", pack.ModelPack, res.Case, strings.Join(details, "; ")))
+		}
+	}
+
+	if len(failures) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		b.WriteString(strings.Join(failures, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func formatCell(res *CaseResult) string {
+	if res.Error != "" {
+		return "error"
+	}
+	cell := fmt.Sprintf("%.3f", res.Similarity)
+	if res.Passed {
+		cell += " ✓"
+	}
+	if !res.SyntaxValid {
+		cell += " ✗"
+	}
+	return cell
+}
+
+func formatStageCell(res *StageResult) string {
+	if res.Error != "" {
+		return "error"
+	}
+	cell := fmt.Sprintf("%.3f", res.Similarity)
+	if res.Passed {
+		cell += " ✓"
+	}
+	if !res.SyntaxValid {
+		cell += " ✗"
+	}
+	return cell
+}
@@ -0,0 +1,210 @@
+package evals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"plandex-server/model"
+	"plandex-server/model/plan"
+	"plandex-server/syntax"
+	"plandex-server/types"
+	"time"
+
+	shared "plandex-shared"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// the baseline column in reports--cases run through ApplyChanges only, without the builder model
+const ApplyOnlyPackName = "apply-only"
+
+// replies are fed to the parser in small chunks, like a model stream
+const replyChunkSize = 5
+
+type RunParams struct {
+	Suites []*Suite
+	// each pack's builder validates and fixes cases that ApplyChanges can't resolve on its own. The apply-only baseline always runs first.
+	Packs   []*shared.ModelPack
+	Clients map[string]model.ClientInfo
+
+	// recorded in the report
+	Transport model.ModelTransportMode
+}
+
+// Run runs every case in the suites once per model pack
+func Run(ctx context.Context, params RunParams) (*Report, error) {
+	report := &Report{
+		StartedAt: time.Now(),
+		Transport: string(params.Transport),
+	}
+	if report.Transport == "" {
+		report.Transport = "live"
+	}
+
+	for _, suite := range params.Suites {
+		report.Suites = append(report.Suites, suite.Name)
+	}
+
+	packs := append([]*shared.ModelPack{nil}, params.Packs...)
+
+	for _, pack := range packs {
+		packReport := &PackReport{ModelPack: ApplyOnlyPackName}
+		if pack != nil {
+			packReport.ModelPack = pack.Name
+			packReport.Builder = string(pack.Builder.BaseModelConfig.ModelName)
+		}
+
+		for _, suite := range params.Suites {
+			for _, c := range suite.Cases {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+
+				log.Printf("Evals - %s - running %s\n", packReport.ModelPack, c.Id())
+				res := runCase(ctx, c, pack, params.Clients)
+				if errors.Is(ctx.Err(), context.Canceled) {
+					return nil, ctx.Err()
+				}
+				log.Printf("Evals - %s - %s passed: %t, similarity: %.3f\n", packReport.ModelPack, c.Id(), res.Passed, res.Similarity)
+
+				packReport.Results = append(packReport.Results, res)
+			}
+		}
+
+		packReport.summarize()
+		report.Packs = append(report.Packs, packReport)
+	}
+
+	return report, nil
+}
+
+func runCase(ctx context.Context, c *Case, pack *shared.ModelPack, clients map[string]model.ClientInfo) *CaseResult {
+	started := time.Now()
+	res := &CaseResult{Case: c.Id(), Path: c.Path}
+
+	defer func() {
+		res.DurationMs = time.Since(started).Milliseconds()
+	}()
+
+	proposed, desc, err := getProposed(c)
+	if err != nil {
+		res.Error = err.Error()
+		res.score(ctx, c)
+		return res
+	}
+
+	parser, language, fallbackParser, fallbackLanguage := syntax.GetParserForPath(c.Path)
+	if parser != nil {
+		validationRes, err := syntax.ValidateWithParsers(ctx, language, parser, fallbackLanguage, fallbackParser, c.Original)
+		if err == nil {
+			parser = validationRes.Parser
+			language = validationRes.Lang
+		}
+	}
+
+	applyRes := syntax.ApplyChanges(ctx, syntax.ApplyChangesParams{
+		Original:               c.Original,
+		Proposed:               proposed,
+		Desc:                   desc,
+		AddMissingStartEndRefs: true,
+		Parser:                 parser,
+		Language:               language,
+	})
+	res.Result = applyRes.NewFile
+	for _, reason := range applyRes.NeedsVerifyReasons {
+		res.NeedsVerifyReasons = append(res.NeedsVerifyReasons, string(reason))
+	}
+
+	if pack == nil && parser != nil && len(applyRes.References)+len(applyRes.Removals) > 0 {
+		res.TreeSitter = runTreeSitterStage(ctx, c, applyRes, parser, language)
+	}
+
+	syntaxErrors := getSyntaxErrors(ctx, c.Path, applyRes.NewFile)
+
+	if pack != nil && (len(applyRes.NeedsVerifyReasons) > 0 || len(syntaxErrors) > 0) {
+		res.Validated = true
+
+		validateRes, err := plan.EvalBuildValidate(ctx, plan.EvalBuildValidateParams{
+			Clients:      clients,
+			Settings:     &shared.PlanSettings{ModelPack: pack},
+			FilePath:     c.Path,
+			Original:     c.Original,
+			Updated:      applyRes.NewFile,
+			Proposed:     proposed,
+			Desc:         desc,
+			Reasons:      applyRes.NeedsVerifyReasons,
+			SyntaxErrors: syntaxErrors,
+		})
+		if err != nil {
+			res.Error = fmt.Sprintf("error validating build: %v", err)
+		} else {
+			res.Result = validateRes.Updated
+			res.ValidationProblem = validateRes.Problem
+		}
+	}
+
+	res.score(ctx, c)
+	return res
+}
+
+// runTreeSitterStage applies the same proposed block, references and removals that ApplyChanges used with the tree-sitter strategy alone
+func runTreeSitterStage(ctx context.Context, c *Case, applyRes *syntax.ApplyChangesResult, parser *sitter.Parser, language shared.Language) *StageResult {
+	stage := &StageResult{}
+
+	treeSitterRes, err := syntax.ApplyTreeSitter(ctx, syntax.ApplyTreeSitterParams{
+		Original:   c.Original,
+		Proposed:   applyRes.Proposed,
+		References: applyRes.References,
+		Removals:   applyRes.Removals,
+		Parser:     parser,
+		Language:   language,
+	})
+	if err != nil {
+		stage.Error = fmt.Sprintf("error applying with tree-sitter: %v", err)
+	} else {
+		stage.Result = treeSitterRes.NewFile
+		for _, reason := range treeSitterRes.NeedsVerifyReasons {
+			stage.NeedsVerifyReasons = append(stage.NeedsVerifyReasons, string(reason))
+		}
+	}
+
+	stage.score(ctx, c)
+	return stage
+}
+
+// getProposed returns the proposed file block and its description, parsing them from the planner's reply if the case has one
+func getProposed(c *Case) (string, string, error) {
+	if !c.Reply {
+		return c.Output, c.Desc, nil
+	}
+
+	parser := types.NewReplyParser()
+	for i := 0; i < len(c.Output); i += replyChunkSize {
+		parser.AddChunk(c.Output[i:min(i+replyChunkSize, len(c.Output))], true)
+	}
+	parserRes := parser.FinishAndRead()
+
+	for _, op := range parserRes.Operations {
+		if op.Type == shared.OperationTypeFile && op.Path == c.Path {
+			desc := op.Description
+			if c.Desc != "" {
+				desc = c.Desc
+			}
+			return op.Content, desc, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("reply parser found no file block for %s in %d operations", c.Path, len(parserRes.Operations))
+}
+
+func getSyntaxErrors(ctx context.Context, path, content string) []string {
+	validationRes, err := syntax.ValidateFile(ctx, path, content)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if validationRes.Parser == nil || validationRes.TimedOut || validationRes.Valid {
+		return nil
+	}
+	return validationRes.Errors
+}
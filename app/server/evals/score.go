@@ -0,0 +1,55 @@
+package evals
+
+import "strings"
+
+// normalize ignores line endings, trailing whitespace, and blank lines at the start and end of the file, which the builder doesn't reliably preserve and which don't change a file's meaning
+func normalize(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// similarity is the share of lines two files have in common, in order: 2 * LCS / (len(a) + len(b)). Identical files score 1.
+func similarity(a, b string) float64 {
+	a = normalize(a)
+	b = normalize(b)
+
+	if a == b {
+		return 1
+	}
+
+	var aLines, bLines []string
+	if a != "" {
+		aLines = strings.Split(a, "\n")
+	}
+	if b != "" {
+		bLines = strings.Split(b, "\n")
+	}
+
+	if len(aLines)+len(bLines) == 0 {
+		return 1
+	}
+
+	return 2 * float64(lcsLen(aLines, bLines)) / float64(len(aLines)+len(bLines))
+}
+
+func lcsLen(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,168 @@
+package evals
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Evals harness for the builder and the reply parser. A suite is a directory of cases; each case is a directory with a case.json and three files:
+//
+//   - original.<ext>: the file before the change
+//   - output.<ext>: the model's output--the proposed file block, or the whole planner reply when case.json sets "reply"
+//   - expected.<ext>: the file after a correct build
+//
+// Cases run through the reply parser, syntax.ApplyChanges, the tree-sitter apply strategy on its own and, with a model pack, the builder's validate-and-fix loop. Model responses for the loop are live, or recorded and replayed through the model transport.
+
+const caseFileName = "case.json"
+
+type CaseConfig struct {
+	// the file's path in the project--its extension picks the parser
+	Path string `json:"path"`
+
+	// the file block's description, as the planner writes it above the block. Overrides the description parsed from a reply.
+	Desc string `json:"desc,omitempty"`
+
+	// output is a full planner reply rather than a single file block
+	Reply bool `json:"reply,omitempty"`
+}
+
+type Case struct {
+	CaseConfig
+	Suite    string
+	Name     string
+	Original string
+	Output   string
+	Expected string
+}
+
+func (c *Case) Id() string {
+	return c.Suite + "/" + c.Name
+}
+
+type Suite struct {
+	Name  string
+	Cases []*Case
+}
+
+// LoadSuites loads every suite directory under dir. If names is non-empty, only those suites are loaded.
+func LoadSuites(dir string, names []string) ([]*Suite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading suites dir: %v", err)
+	}
+
+	include := map[string]bool{}
+	for _, name := range names {
+		include[name] = true
+	}
+
+	var suites []*Suite
+	for _, entry := range entries {
+		if !entry.IsDir() || (len(include) > 0 && !include[entry.Name()]) {
+			continue
+		}
+
+		suite, err := LoadSuite(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+		delete(include, entry.Name())
+	}
+
+	for name := range include {
+		return nil, fmt.Errorf("suite %s not found in %s", name, dir)
+	}
+
+	return suites, nil
+}
+
+func LoadSuite(dir string) (*Suite, error) {
+	suite := &Suite{Name: filepath.Base(dir)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading suite %s: %v", suite.Name, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		c, err := loadCase(suite.Name, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	sort.Slice(suite.Cases, func(i, j int) bool {
+		return suite.Cases[i].Name < suite.Cases[j].Name
+	})
+
+	return suite, nil
+}
+
+func loadCase(suiteName, dir string) (*Case, error) {
+	c := &Case{
+		Suite: suiteName,
+		Name:  filepath.Base(dir),
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(dir, caseFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s for case %s: %v", caseFileName, c.Id(), err)
+	}
+
+	err = json.Unmarshal(bytes, &c.CaseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s for case %s: %v", caseFileName, c.Id(), err)
+	}
+
+	if c.Path == "" {
+		return nil, fmt.Errorf("case %s is missing a path", c.Id())
+	}
+
+	for name, dest := range map[string]*string{
+		"original": &c.Original,
+		"output":   &c.Output,
+		"expected": &c.Expected,
+	} {
+		content, err := readCaseFile(dir, name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading case %s: %v", c.Id(), err)
+		}
+		*dest = content
+	}
+
+	return c, nil
+}
+
+// readCaseFile reads the case file with the given name and any extension
+func readCaseFile(dir, name string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fileName := entry.Name()
+		if strings.TrimSuffix(fileName, filepath.Ext(fileName)) == name {
+			bytes, err := os.ReadFile(filepath.Join(dir, fileName))
+			if err != nil {
+				return "", err
+			}
+			return string(bytes), nil
+		}
+	}
+
+	return "", fmt.Errorf("missing %s file", name)
+}
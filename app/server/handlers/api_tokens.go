@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+// requireSessionAuth rejects requests made with an API token, so tokens can't mint more tokens or act on the account itself
+func requireSessionAuth(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if auth.ApiToken != nil {
+		log.Println("Request not allowed with an API token")
+		http.Error(w, "Not allowed with an API token--sign in to do this", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func ListApiTokensHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListApiTokensHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	userId := auth.User.Id
+
+	serviceAccountId := r.URL.Query().Get("serviceAccountId")
+	if serviceAccountId != "" {
+		account := authorizeServiceAccount(w, serviceAccountId, auth)
+		if account == nil {
+			return
+		}
+		userId = account.UserId
+	}
+
+	dbTokens, err := db.ListApiTokens(auth.OrgId, userId)
+	if err != nil {
+		log.Printf("Error listing api tokens: %v\n", err)
+		http.Error(w, "Error listing api tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := []*shared.ApiToken{}
+	for _, token := range dbTokens {
+		res = append(res, token.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling api tokens: %v\n", err)
+		http.Error(w, "Error marshalling api tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed api tokens")
+}
+
+func CreateApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	var req shared.CreateApiTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Token name is required", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		http.Error(w, "At least one scope is required", http.StatusBadRequest)
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !shared.IsValidApiTokenScope(scope) {
+			http.Error(w, "Invalid scope: "+string(scope), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.ExpiresInDays < 0 {
+		http.Error(w, "Expiry must be a positive number of days, or 0 for no expiry", http.StatusBadRequest)
+		return
+	}
+
+	creatorId := auth.User.Id
+	apiToken := db.ApiToken{
+		OrgId:     auth.OrgId,
+		UserId:    auth.User.Id,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedBy: &creatorId,
+	}
+
+	if req.ServiceAccountId != "" {
+		account := authorizeServiceAccount(w, req.ServiceAccountId, auth)
+		if account == nil {
+			return
+		}
+		apiToken.UserId = account.UserId
+		apiToken.ServiceAccountId = &account.Id
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	token, err := db.NewApiToken(&apiToken)
+	if err != nil {
+		log.Printf("Error generating api token: %v\n", err)
+		http.Error(w, "Error generating api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = db.CreateApiToken(&apiToken)
+	if err != nil {
+		log.Printf("Error creating api token: %v\n", err)
+		http.Error(w, "Error creating api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.CreateApiTokenResponse{
+		ApiToken: apiToken.ToApi(),
+		Token:    token,
+	})
+	if err != nil {
+		log.Printf("Error marshalling api token: %v\n", err)
+		http.Error(w, "Error marshalling api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created api token")
+}
+
+func RevokeApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RevokeApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	tokenId := mux.Vars(r)["tokenId"]
+
+	apiToken, err := db.GetApiToken(auth.OrgId, tokenId)
+	if err != nil {
+		log.Printf("Error getting api token: %v\n", err)
+		http.Error(w, "Error getting api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if apiToken == nil {
+		http.Error(w, "API token not found", http.StatusNotFound)
+		return
+	}
+
+	if apiToken.ServiceAccountId != nil {
+		if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+			log.Println("User does not have permission to manage service accounts")
+			http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+			return
+		}
+	} else if apiToken.UserId != auth.User.Id {
+		http.Error(w, "API token not found", http.StatusNotFound)
+		return
+	}
+
+	err = db.RevokeApiToken(auth.OrgId, tokenId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "API token not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error revoking api token: %v\n", err)
+		http.Error(w, "Error revoking api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully revoked api token")
+}
+
+// GetApiTokenSessionHandler describes the token a request was made with, so headless clients can resolve their user and org without signing in
+func GetApiTokenSessionHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetApiTokenSessionHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if auth.ApiToken == nil {
+		http.Error(w, "Request wasn't made with an API token", http.StatusBadRequest)
+		return
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.ApiTokenSessionResponse{
+		ApiToken: auth.ApiToken.ToApi(),
+		UserId:   auth.User.Id,
+		Email:    auth.User.Email,
+		UserName: auth.User.Name,
+		Org:      org.ToApi(),
+	})
+	if err != nil {
+		log.Printf("Error marshalling api token session: %v\n", err)
+		http.Error(w, "Error marshalling api token session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got api token session")
+}
+
+func ListServiceAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListServiceAccountsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageServiceAccounts(w, auth) {
+		return
+	}
+
+	dbAccounts, err := db.ListServiceAccounts(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing service accounts: %v\n", err)
+		http.Error(w, "Error listing service accounts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := []*shared.ServiceAccount{}
+	for _, account := range dbAccounts {
+		res = append(res, account.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling service accounts: %v\n", err)
+		http.Error(w, "Error marshalling service accounts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed service accounts")
+}
+
+func CreateServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateServiceAccountHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageServiceAccounts(w, auth) {
+		return
+	}
+
+	var req shared.CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Service account name is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.OrgRoleId == "" {
+		memberRoleId, err := db.GetOrgMemberRoleId()
+		if err != nil {
+			log.Printf("Error getting org member role id: %v\n", err)
+			http.Error(w, "Error getting org member role id: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.OrgRoleId = memberRoleId
+	}
+
+	// a service account can't be given a role its creator couldn't invite someone with
+	if !auth.HasPermissionForResource(shared.PermissionInviteUser, req.OrgRoleId) {
+		log.Printf("User does not have permission to create service account with role: %v\n", req.OrgRoleId)
+		http.Error(w, "User does not have permission to create service account with role: "+req.OrgRoleId, http.StatusForbidden)
+		return
+	}
+
+	creatorId := auth.User.Id
+	account := db.ServiceAccount{
+		OrgId:     auth.OrgId,
+		Name:      req.Name,
+		OrgRoleId: req.OrgRoleId,
+		CreatedBy: &creatorId,
+	}
+
+	err := db.CreateServiceAccount(r.Context(), &account)
+	if err != nil {
+		if err == db.ErrServiceAccountExists {
+			http.Error(w, "A service account named "+req.Name+" already exists", http.StatusConflict)
+			return
+		}
+		log.Printf("Error creating service account: %v\n", err)
+		http.Error(w, "Error creating service account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(account.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling service account: %v\n", err)
+		http.Error(w, "Error marshalling service account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created service account")
+}
+
+func DeleteServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteServiceAccountHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	account := authorizeServiceAccount(w, mux.Vars(r)["serviceAccountId"], auth)
+	if account == nil {
+		return
+	}
+
+	err := db.DeleteServiceAccount(r.Context(), account)
+	if err != nil {
+		log.Printf("Error deleting service account: %v\n", err)
+		http.Error(w, "Error deleting service account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully deleted service account")
+}
+
+func authorizeManageServiceAccounts(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !requireSessionAuth(w, auth) {
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		log.Println("User does not have permission to manage service accounts")
+		http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func authorizeServiceAccount(w http.ResponseWriter, serviceAccountId string, auth *types.ServerAuth) *db.ServiceAccount {
+	if !authorizeManageServiceAccounts(w, auth) {
+		return nil
+	}
+
+	account, err := db.GetServiceAccount(auth.OrgId, serviceAccountId)
+	if err != nil {
+		log.Printf("Error getting service account: %v\n", err)
+		http.Error(w, "Error getting service account: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if account == nil {
+		http.Error(w, "Service account not found", http.StatusNotFound)
+		return nil
+	}
+
+	return account
+}
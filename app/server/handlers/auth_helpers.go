@@ -11,10 +11,12 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/types"
 	"strings"
+	"sync"
 	"time"
 
 	shared "plandex-shared"
 
+	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -58,6 +60,11 @@ func GetAuthHeader(r *http.Request) (*shared.AuthHeader, error) {
 	// strip off the "Bearer " prefix
 	encoded := strings.TrimPrefix(authHeader, "Bearer ")
 
+	// API tokens can be sent as-is so CI pipelines don't need to encode anything
+	if shared.IsApiToken(encoded) {
+		return &shared.AuthHeader{Token: encoded}, nil
+	}
+
 	// decode the base64-encoded credentials
 	bytes, err := base64.URLEncoding.DecodeString(encoded)
 
@@ -441,6 +448,11 @@ func ValidateAndSignIn(w http.ResponseWriter, r *http.Request, req shared.SignIn
 func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, raiseErr bool) *types.ServerAuth {
 	log.Println("authenticating request")
 
+	// control requests forwarded over the stream bus were authenticated by the instance that received them--the context value can't be set from outside the server
+	if auth, ok := r.Context().Value(forwardedAuthContextKey{}).(*types.ServerAuth); ok {
+		return auth
+	}
+
 	parsed, err := GetAuthHeader(r)
 
 	if err != nil {
@@ -459,6 +471,10 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
+	if shared.IsApiToken(parsed.Token) {
+		return execAuthenticateApiToken(w, r, parsed, requireOrg, raiseErr)
+	}
+
 	// validate the token
 	authToken, err := db.ValidateAuthToken(parsed.Token)
 
@@ -592,6 +608,149 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 
 }
 
+// execAuthenticateApiToken authenticates a personal access token or service account token. Tokens belong to a single org, only get the non-admin permissions of their user's role, and only reach the routes their scopes allow.
+func execAuthenticateApiToken(w http.ResponseWriter, r *http.Request, parsed *shared.AuthHeader, requireOrg bool, raiseErr bool) *types.ServerAuth {
+	apiToken, err := db.ValidateApiToken(parsed.Token)
+
+	if err != nil {
+		log.Printf("error validating api token: %v\n", err)
+
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeInvalidToken,
+			Status: http.StatusUnauthorized,
+			Msg:    "Invalid API token",
+		})
+		return nil
+	}
+
+	if parsed.OrgId != "" && parsed.OrgId != apiToken.OrgId {
+		log.Println("api token org mismatch")
+		if raiseErr {
+			http.Error(w, "API token doesn't belong to org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	user, err := db.GetUser(apiToken.UserId)
+
+	if err != nil {
+		log.Printf("error getting user: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	auth := &types.ServerAuth{
+		User:     user,
+		ApiToken: apiToken,
+	}
+
+	scope, ok := apiTokenScope(r)
+	if !ok {
+		log.Printf("api token used on route without a scope: %s %s\n", r.Method, r.URL.Path)
+		if raiseErr {
+			http.Error(w, "Not allowed with an API token--sign in to do this", http.StatusForbidden)
+		}
+		return nil
+	}
+
+	if !auth.HasScope(scope) {
+		log.Printf("api token missing scope: %s\n", scope)
+		if raiseErr {
+			http.Error(w, fmt.Sprintf("API token doesn't have the %s scope", scope), http.StatusForbidden)
+		}
+		return nil
+	}
+
+	if !requireOrg {
+		return auth
+	}
+
+	// tokens never accept invites--the user or service account must already be a member
+	isMember, err := db.ValidateOrgMembership(apiToken.UserId, apiToken.OrgId)
+
+	if err != nil {
+		log.Printf("error validating org membership: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error validating org membership", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if !isMember {
+		log.Println("api token user is not a member of the org")
+		if raiseErr {
+			http.Error(w, "not a member of org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	permissions, err := db.GetUserPermissions(apiToken.UserId, apiToken.OrgId)
+
+	if err != nil {
+		log.Printf("error getting user permissions: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user permissions", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	permissionsMap := make(shared.Permissions)
+	for _, permission := range permissions {
+		split := strings.Split(permission, "|")
+		if shared.IsApiTokenPermission(shared.Permission(split[0])) {
+			permissionsMap[permission] = true
+		}
+	}
+
+	auth.OrgId = apiToken.OrgId
+	auth.Permissions = permissionsMap
+
+	_, apiErr := hooks.ExecHook(hooks.Authenticate, hooks.HookParams{
+		Auth: auth,
+		AuthenticateHookRequestParams: &hooks.AuthenticateHookRequestParams{
+			Path: r.URL.Path,
+		},
+	})
+
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return nil
+	}
+
+	log.Printf("UserId: %s, Email: %s, OrgId: %s, ApiTokenId: %s\n", apiToken.UserId, user.Email, apiToken.OrgId, apiToken.Id)
+
+	return auth
+}
+
+// scopes API tokens need for each route, set as routes are registered
+var apiTokenScopeByRoute sync.Map
+
+// SetApiTokenScope sets the scope an API token needs to call the route. API tokens are rejected on routes without a scope.
+func SetApiTokenScope(route *mux.Route, scope shared.ApiTokenScope) {
+	apiTokenScopeByRoute.Store(route, scope)
+}
+
+// GetApiTokenScope returns the scope an API token needs to call the route, or false if the route doesn't accept API tokens
+func GetApiTokenScope(route *mux.Route) (shared.ApiTokenScope, bool) {
+	scope, ok := apiTokenScopeByRoute.Load(route)
+	if !ok {
+		return "", false
+	}
+
+	return scope.(shared.ApiTokenScope), true
+}
+
+func apiTokenScope(r *http.Request) (shared.ApiTokenScope, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	return GetApiTokenScope(route)
+}
+
 func authorizeProject(w http.ResponseWriter, projectId string, auth *types.ServerAuth) bool {
 	return authorizeProjectOptional(w, projectId, auth, true)
 }
@@ -644,24 +803,51 @@ func authorizeProjectDelete(w http.ResponseWriter, projectId string, auth *types
 	return true
 }
 
+// authorizePlan allows read access, which includes users the plan is shared with read-only. Handlers that change the plan use authorizePlanWrite.
 func authorizePlan(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan, _ := authorizePlanAccess(w, planId, auth)
+	return plan
+}
+
+// authorizePlanWrite is authorizePlan, except users the plan is shared with read-only are forbidden
+func authorizePlanWrite(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan, share := authorizePlanAccess(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	return checkPlanWrite(w, plan, share)
+}
+
+func checkPlanWrite(w http.ResponseWriter, plan *db.Plan, share *db.PlanShare) *db.Plan {
+	if share != nil && share.Access == shared.PlanShareAccessRead {
+		log.Println("User has read-only access to plan")
+		http.Error(w, "Plan is shared with you read-only", http.StatusForbidden)
+		return nil
+	}
+
+	return plan
+}
+
+func authorizePlanAccess(w http.ResponseWriter, planId string, auth *types.ServerAuth) (*db.Plan, *db.PlanShare) {
 	log.Println("authorizing plan")
 
-	plan, err := db.ValidatePlanAccess(planId, auth.User.Id, auth.OrgId)
+	plan, share, err := db.ValidatePlanAccess(planId, auth.User.Id, auth.OrgId)
 
 	if err != nil {
 		log.Printf("error validating plan membership: %v\n", err)
 		http.Error(w, "error validating plan membership", http.StatusInternalServerError)
-		return nil
+		return nil, nil
 	}
 
 	if plan == nil {
 		log.Println("user doesn't have access the plan")
 		http.Error(w, "no access to plan", http.StatusUnauthorized)
-		return nil
+		return nil, nil
 	}
 
-	return plan
+	return plan, share
 }
 
 func authorizePlanUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
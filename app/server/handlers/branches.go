@@ -91,7 +91,7 @@ func CreateBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -171,7 +171,7 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListSpendBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListSpendBudgetsHandler")
+
+	if !checkSpendBudgetsSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// budgets show what other members and plans are spending, so listing them is limited to the same users who can change them
+	if !checkManageSpendBudgets(w, auth) {
+		return
+	}
+
+	budgets, err := db.ListSpendBudgets(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing spend budgets: %v\n", err)
+		http.Error(w, "Error listing spend budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListSpendBudgetsResponse{
+		Budgets:        []*shared.SpendBudget{},
+		PlanNamesById:  map[string]string{},
+		UserEmailsById: map[string]string{},
+	}
+
+	now := time.Now()
+	planIds := []string{}
+	for _, budget := range budgets {
+		apiBudget := budget.ToApi()
+
+		// current spend is only meaningful for budgets with a single target
+		if budget.Scope == shared.SpendBudgetScopeOrg || budget.PlanId != nil || budget.UserId != nil {
+			spend, err := db.GetSpendForBudget(budget, "", "", now)
+			if err != nil {
+				log.Printf("Error getting spend for budget: %v\n", err)
+				http.Error(w, "Error getting spend for budget: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			apiBudget.CurrentSpend = &spend
+		}
+
+		if budget.PlanId != nil {
+			planIds = append(planIds, *budget.PlanId)
+		}
+
+		res.Budgets = append(res.Budgets, apiBudget)
+	}
+
+	if len(planIds) > 0 {
+		res.PlanNamesById, err = db.GetPlanNamesById(planIds)
+		if err != nil {
+			log.Printf("Error getting plan names: %v\n", err)
+			http.Error(w, "Error getting plan names: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	users, err := db.ListUsers(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing users: %v\n", err)
+		http.Error(w, "Error listing users: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, user := range users {
+		res.UserEmailsById[user.Id] = user.Email
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling spend budgets: %v\n", err)
+		http.Error(w, "Error marshalling spend budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed spend budgets")
+}
+
+func SetSpendBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetSpendBudgetHandler")
+
+	if !checkSpendBudgetsSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !checkManageSpendBudgets(w, auth) {
+		return
+	}
+
+	var req shared.SetSpendBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Period != shared.SpendBudgetPeriodDaily && req.Period != shared.SpendBudgetPeriodMonthly {
+		http.Error(w, "Invalid budget period: "+string(req.Period), http.StatusBadRequest)
+		return
+	}
+
+	if !req.LimitUsd.IsPositive() {
+		http.Error(w, "Budget limit must be greater than zero", http.StatusBadRequest)
+		return
+	}
+
+	if req.WarnPct < 0 || req.WarnPct > 100 {
+		http.Error(w, "Warning threshold must be between 0 and 100", http.StatusBadRequest)
+		return
+	}
+
+	budget := &db.SpendBudget{
+		OrgId:    auth.OrgId,
+		Scope:    req.Scope,
+		Period:   req.Period,
+		LimitUsd: req.LimitUsd,
+		WarnPct:  req.WarnPct,
+	}
+
+	switch req.Scope {
+	case shared.SpendBudgetScopeOrg:
+	case shared.SpendBudgetScopePlan:
+		if req.PlanId != nil {
+			plan, err := db.GetPlan(*req.PlanId)
+			if err != nil {
+				log.Printf("Error getting plan: %v\n", err)
+				http.Error(w, "Error getting plan: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if plan == nil || plan.OrgId != auth.OrgId {
+				http.Error(w, "Plan not found", http.StatusNotFound)
+				return
+			}
+			budget.PlanId = req.PlanId
+		}
+	case shared.SpendBudgetScopeUser:
+		if req.UserId != nil {
+			orgUser, err := db.GetOrgUser(*req.UserId, auth.OrgId)
+			if err != nil {
+				log.Printf("Error getting org user: %v\n", err)
+				http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if orgUser == nil {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			budget.UserId = req.UserId
+		}
+	default:
+		http.Error(w, "Invalid budget scope: "+string(req.Scope), http.StatusBadRequest)
+		return
+	}
+
+	err := db.SetSpendBudget(budget)
+	if err != nil {
+		log.Printf("Error setting spend budget: %v\n", err)
+		http.Error(w, "Error setting spend budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(budget.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling spend budget: %v\n", err)
+		http.Error(w, "Error marshalling spend budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully set spend budget")
+}
+
+func DeleteSpendBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteSpendBudgetHandler")
+
+	if !checkSpendBudgetsSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !checkManageSpendBudgets(w, auth) {
+		return
+	}
+
+	budgetId := mux.Vars(r)["budgetId"]
+
+	budget, err := db.GetSpendBudget(auth.OrgId, budgetId)
+	if err != nil {
+		log.Printf("Error getting spend budget: %v\n", err)
+		http.Error(w, "Error getting spend budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if budget == nil {
+		http.Error(w, "Spend budget not found", http.StatusNotFound)
+		return
+	}
+
+	err = db.DeleteSpendBudget(auth.OrgId, budgetId)
+	if err != nil {
+		log.Printf("Error deleting spend budget: %v\n", err)
+		http.Error(w, "Error deleting spend budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully deleted spend budget")
+}
+
+func checkSpendBudgetsSupported(w http.ResponseWriter) bool {
+	if os.Getenv("IS_CLOUD") != "" {
+		http.Error(w, "Spend budgets are only supported when self-hosting—use billing settings to limit spend on Plandex Cloud", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func checkManageSpendBudgets(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !auth.HasPermission(shared.PermissionManageBilling) {
+		log.Println("User does not have permission to manage spend budgets")
+		http.Error(w, "User does not have permission to manage spend budgets", http.StatusForbidden)
+		return false
+	}
+	return true
+}
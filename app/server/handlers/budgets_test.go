@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/types"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestCheckManageSpendBudgets(t *testing.T) {
+	tests := []struct {
+		name       string
+		perms      shared.Permissions
+		want       bool
+		wantStatus int
+	}{
+		{
+			name:       "billing manager",
+			perms:      shared.Permissions{string(shared.PermissionManageBilling): true},
+			want:       true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "member",
+			perms:      shared.Permissions{string(shared.PermissionCreatePlan): true},
+			want:       false,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			got := checkManageSpendBudgets(w, &types.ServerAuth{Permissions: tt.perms})
+			if got != tt.want {
+				t.Errorf("checkManageSpendBudgets() = %v, want %v", got, tt.want)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
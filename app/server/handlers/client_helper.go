@@ -7,6 +7,8 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/model"
 	"plandex-server/types"
+
+	shared "plandex-shared"
 )
 
 type initClientsParams struct {
@@ -117,6 +119,18 @@ func initClients(params initClientsParams) map[string]model.ClientInfo {
 		}
 	}
 
+	// keys that aren't used by the model pack, like the semantic index's embedding provider, use the provider's default endpoint
+	for envVar := range apiKeys {
+		if _, ok := endpointsByApiKeyEnvVar[envVar]; ok {
+			continue
+		}
+		for provider, providerEnvVar := range shared.ApiKeyByProvider {
+			if providerEnvVar == envVar && provider != shared.ModelProviderOpenAI {
+				endpointsByApiKeyEnvVar[envVar] = shared.BaseUrlByProvider[provider]
+			}
+		}
+	}
+
 	if len(apiKeys) == 0 {
 		log.Println("API key is required")
 		http.Error(w, "API key is required", http.StatusBadRequest)
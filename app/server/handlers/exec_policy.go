@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+
+	shared "plandex-shared"
+)
+
+func GetOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	policy, err := db.GetOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org exec policy: %v\n", err)
+		http.Error(w, "Error getting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if policy == nil {
+		policy = &shared.ExecPolicy{}
+	}
+
+	bytes, err := json.Marshal(policy)
+	if err != nil {
+		log.Printf("Error marshalling org exec policy: %v\n", err)
+		http.Error(w, "Error marshalling org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got org exec policy")
+}
+
+func SetOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageExecPolicy) {
+		log.Println("User does not have permission to manage exec policy")
+		http.Error(w, "User does not have permission to manage exec policy", http.StatusForbidden)
+		return
+	}
+
+	var policy shared.ExecPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := policy.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if policy.IsEmpty() {
+		err = db.DeleteOrgExecPolicy(auth.OrgId)
+	} else {
+		err = db.SetOrgExecPolicy(auth.OrgId, &policy)
+	}
+
+	if err != nil {
+		log.Printf("Error setting org exec policy: %v\n", err)
+		http.Error(w, "Error setting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(policy)
+	if err != nil {
+		log.Printf("Error marshalling org exec policy: %v\n", err)
+		http.Error(w, "Error marshalling org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully set org exec policy")
+}
+
+func DeleteOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageExecPolicy) {
+		log.Println("User does not have permission to manage exec policy")
+		http.Error(w, "User does not have permission to manage exec policy", http.StatusForbidden)
+		return
+	}
+
+	err := db.DeleteOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error deleting org exec policy: %v\n", err)
+		http.Error(w, "Error deleting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully deleted org exec policy")
+}
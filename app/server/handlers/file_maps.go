@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/semantic"
+	"plandex-server/syntax/file_map"
 	"sync"
 
 	shared "plandex-shared"
@@ -28,6 +31,15 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var plan *db.Plan
+	if req.PlanId != "" {
+		// mapping with a plan id indexes the inputs into the plan's semantic index
+		plan = authorizePlanWrite(w, req.PlanId, auth)
+		if plan == nil {
+			return
+		}
+	}
+
 	log.Println("GetFileMapHandler: checking limits")
 
 	if len(req.MapInputs) > shared.MaxContextMapPaths {
@@ -85,6 +97,10 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if plan != nil {
+			indexSemanticInputs(r.Context(), plan, req.MapInputs)
+		}
+
 		resp := shared.GetFileMapResponse{
 			MapBodies: maps,
 		}
@@ -100,6 +116,70 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func GetFileSymbolsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetFileSymbolsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.GetFileSymbolsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Inputs) > shared.MaxContextCount {
+		http.Error(w, fmt.Sprintf("Too many files: %d (max %d)", len(req.Inputs), shared.MaxContextCount), http.StatusBadRequest)
+		return
+	}
+
+	totalSize := 0
+	for path, input := range req.Inputs {
+		if input == nil {
+			http.Error(w, fmt.Sprintf("Missing input for %s", path), http.StatusBadRequest)
+			return
+		}
+		totalSize += len(input.Content)
+	}
+
+	if totalSize > shared.MaxContextBodySize {
+		http.Error(w, fmt.Sprintf("Files are too large: %d (max %d)", totalSize, shared.MaxContextBodySize), http.StatusBadRequest)
+		return
+	}
+
+	bodies := map[string]map[string]string{}
+	for path, input := range req.Inputs {
+		for _, symbol := range input.Symbols {
+			body, found, err := file_map.FindSymbol(r.Context(), path, []byte(input.Content), symbol)
+			if err != nil {
+				log.Printf("GetFileSymbolsHandler: error finding symbol %s in %s: %v", symbol, path, err)
+				http.Error(w, fmt.Sprintf("Error finding symbol %s in %s: %v", symbol, path, err), http.StatusInternalServerError)
+				return
+			}
+			if !found {
+				continue
+			}
+			if bodies[path] == nil {
+				bodies[path] = map[string]string{}
+			}
+			bodies[path][symbol] = body
+		}
+	}
+
+	respBytes, err := json.Marshal(shared.GetFileSymbolsResponse{Bodies: bodies})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(respBytes)
+
+	log.Printf("GetFileSymbolsHandler success - found symbols in %d files", len(bodies))
+}
+
 func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for LoadCachedFileMapHandler")
 
@@ -113,7 +193,7 @@ func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId, "branchName: ", branchName)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -125,6 +205,26 @@ func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// a cached map doesn't send file contents, so map from scratch until the project's semantic index is built
+	planConfig, err := db.GetPlanConfig(planId)
+	if err != nil {
+		log.Printf("Error getting plan config: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error getting plan config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if planConfig.SemanticIndex {
+		hasIndex, err := db.HasSemanticIndex(plan.ProjectId)
+		if err != nil {
+			log.Printf("Error checking semantic index: %v\n", err)
+			http.Error(w, fmt.Sprintf("Error checking semantic index: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !hasIndex {
+			log.Println("LoadCachedFileMapHandler - semantic index not built yet, skipping map cache")
+			req.FilePaths = nil
+		}
+	}
+
 	cachedMetaByPath := map[string]*shared.Context{}
 	cachedMapsByPath := map[string]*db.CachedMap{}
 	var mu sync.Mutex
@@ -211,3 +311,24 @@ func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 }
+
+// indexSemanticInputs adds mapped files to the project's semantic index when the plan has it enabled. Indexing errors are logged rather than failing the map request.
+func indexSemanticInputs(ctx context.Context, plan *db.Plan, inputs shared.FileMapInputs) {
+	planConfig, err := db.GetPlanConfig(plan.Id)
+	if err != nil {
+		log.Printf("indexSemanticInputs - error getting plan config: %v\n", err)
+		return
+	}
+
+	if !planConfig.SemanticIndex {
+		return
+	}
+
+	err = semantic.IndexFiles(ctx, plan.OrgId, plan.ProjectId, inputs)
+	if err != nil {
+		log.Printf("indexSemanticInputs - error indexing %d files: %v\n", len(inputs), err)
+		return
+	}
+
+	log.Printf("indexSemanticInputs - indexed %d files\n", len(inputs))
+}
@@ -405,3 +405,95 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully deleted invite")
 }
+
+func SetInviteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetInviteRoleHandler")
+
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for invites",
+		})
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't update invites",
+		})
+		return
+	}
+
+	inviteId := mux.Vars(r)["inviteId"]
+
+	var req shared.SetOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invite, err := db.GetInvite(inviteId)
+	if err != nil {
+		log.Printf("Error getting invite: %v\n", err)
+		http.Error(w, "Error getting invite: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if invite == nil || invite.OrgId != auth.OrgId {
+		log.Printf("Invite not found: %v\n", inviteId)
+		http.Error(w, "Invite not found: "+inviteId, http.StatusNotFound)
+		return
+	}
+
+	if invite.AcceptedAt != nil {
+		http.Error(w, "Invite was already accepted", http.StatusBadRequest)
+		return
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role == nil {
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusNotFound)
+		return
+	}
+
+	// ensure current user could remove the invite and send it again with the new role
+	canRemove := auth.HasPermissionForResource(shared.PermissionRemoveUser, invite.OrgRoleId) ||
+		(auth.User.Id == invite.InviterId && auth.HasPermissionForResource(shared.PermissionInviteUser, invite.OrgRoleId))
+
+	if !canRemove || !auth.HasPermissionForResource(shared.PermissionInviteUser, role.Id) {
+		log.Printf("User does not have permission to change invite role %v to %v\n", invite.OrgRoleId, role.Id)
+		http.Error(w, "User does not have permission to change invite role "+invite.OrgRoleId+" to "+role.Id, http.StatusForbidden)
+		return
+	}
+
+	err = db.SetInviteRole(inviteId, role.Id)
+	if err != nil {
+		log.Printf("Error setting invite role: %v\n", err)
+		http.Error(w, "Error setting invite role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully set invite role")
+}
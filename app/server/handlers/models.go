@@ -20,6 +20,12 @@ func CreateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageCustomModels) {
+		log.Println("User does not have permission to manage custom models")
+		http.Error(w, "User does not have permission to manage custom models", http.StatusForbidden)
+		return
+	}
+
 	var model shared.AvailableModel
 	if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
 		log.Printf("Error decoding request body: %v\n", err)
@@ -27,8 +33,8 @@ func CreateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if os.Getenv("IS_CLOUD") != "" && model.Provider == shared.ModelProviderCustom {
-		http.Error(w, "Custom model providers are not supported on Plandex Cloud", http.StatusBadRequest)
+	if os.Getenv("IS_CLOUD") != "" && model.Provider.IsSelfHostedOnly() {
+		http.Error(w, "Custom and local model providers are not supported on Plandex Cloud", http.StatusBadRequest)
 		return
 	}
 
@@ -71,6 +77,12 @@ func UpdateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageCustomModels) {
+		log.Println("User does not have permission to manage custom models")
+		http.Error(w, "User does not have permission to manage custom models", http.StatusForbidden)
+		return
+	}
+
 	modelId := mux.Vars(r)["modelId"]
 
 	var model shared.AvailableModel
@@ -100,8 +112,8 @@ func UpdateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if os.Getenv("IS_CLOUD") != "" && model.Provider == shared.ModelProviderCustom {
-		http.Error(w, "Custom model providers are not supported on Plandex Cloud", http.StatusBadRequest)
+	if os.Getenv("IS_CLOUD") != "" && model.Provider.IsSelfHostedOnly() {
+		http.Error(w, "Custom and local model providers are not supported on Plandex Cloud", http.StatusBadRequest)
 		return
 	}
 
@@ -162,6 +174,12 @@ func DeleteAvailableModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageCustomModels) {
+		log.Println("User does not have permission to manage custom models")
+		http.Error(w, "User does not have permission to manage custom models", http.StatusForbidden)
+		return
+	}
+
 	modelId := mux.Vars(r)["modelId"]
 
 	models, err := db.ListCustomModels(auth.OrgId)
@@ -203,6 +221,12 @@ func CreateModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageModelPacks) {
+		log.Println("User does not have permission to manage model packs")
+		http.Error(w, "User does not have permission to manage model packs", http.StatusForbidden)
+		return
+	}
+
 	var ms shared.ModelPack
 	if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -243,6 +267,12 @@ func UpdateModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageModelPacks) {
+		log.Println("User does not have permission to manage model packs")
+		http.Error(w, "User does not have permission to manage model packs", http.StatusForbidden)
+		return
+	}
+
 	mpId := mux.Vars(r)["setId"]
 
 	var ms shared.ModelPack
@@ -332,6 +362,12 @@ func DeleteModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageModelPacks) {
+		log.Println("User does not have permission to manage model packs")
+		http.Error(w, "User does not have permission to manage model packs", http.StatusForbidden)
+		return
+	}
+
 	mpId := mux.Vars(r)["setId"]
 
 	packs, err := db.ListModelPacks(auth.OrgId)
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"plandex-server/oidc"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func GetOidcConfigHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOidcConfigHandler")
+
+	provider, err := oidc.Default()
+	if err != nil {
+		log.Printf("Error loading OIDC config: %v\n", err)
+		http.Error(w, "Error loading OIDC config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.OidcConfigResponse{}
+	if provider != nil {
+		res.Enabled = true
+		res.Issuer = provider.Config.Issuer
+		res.Required = provider.Config.Required
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got OIDC config")
+}
+
+func StartOidcDeviceAuthHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for StartOidcDeviceAuthHandler")
+
+	provider := requireOidcProvider(w)
+	if provider == nil {
+		return
+	}
+
+	deviceAuth, err := provider.StartDeviceAuth(r.Context())
+	if err != nil {
+		log.Printf("Error starting OIDC device auth: %v\n", err)
+		http.Error(w, "Error starting SSO sign in: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.StartOidcDeviceAuthResponse{
+		DeviceCode:              deviceAuth.DeviceCode,
+		UserCode:                deviceAuth.UserCode,
+		VerificationUri:         deviceAuth.VerificationUri,
+		VerificationUriComplete: deviceAuth.VerificationUriComplete,
+		ExpiresIn:               deviceAuth.ExpiresIn,
+		Interval:                deviceAuth.Interval,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully started OIDC device auth")
+}
+
+func PollOidcDeviceAuthHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for PollOidcDeviceAuthHandler")
+
+	provider := requireOidcProvider(w)
+	if provider == nil {
+		return
+	}
+
+	var req shared.PollOidcDeviceAuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var res shared.PollOidcDeviceAuthResponse
+
+	claims, err := provider.ExchangeDeviceCode(r.Context(), req.DeviceCode)
+	if err != nil {
+		var tokenErr *oidc.TokenError
+		switch {
+		case errors.Is(err, oidc.ErrAuthorizationPending):
+			res.Status = shared.OidcDeviceAuthStatusPending
+		case errors.Is(err, oidc.ErrSlowDown):
+			res.Status = shared.OidcDeviceAuthStatusSlowDown
+		case errors.As(err, &tokenErr):
+			log.Printf("OIDC device auth failed: %v\n", tokenErr)
+			http.Error(w, "SSO sign in failed: "+tokenErr.Error(), http.StatusUnauthorized)
+			return
+		default:
+			log.Printf("Error exchanging OIDC device code: %v\n", err)
+			http.Error(w, "Error completing SSO sign in: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+	} else {
+		session, apiErr := signInWithOidc(w, r, provider, claims)
+		if apiErr != nil {
+			writeApiError(w, *apiErr)
+			return
+		}
+		res.Status = shared.OidcDeviceAuthStatusComplete
+		res.Session = session
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully polled OIDC device auth: %s\n", res.Status)
+}
+
+// emailAuthDisabled rejects email pin sign in and account creation when the server requires SSO
+func emailAuthDisabled(w http.ResponseWriter) bool {
+	provider, err := oidc.Default()
+	if err != nil {
+		log.Printf("Error loading OIDC config: %v\n", err)
+		http.Error(w, "Error loading OIDC config: "+err.Error(), http.StatusInternalServerError)
+		return true
+	}
+
+	if provider != nil && provider.Config.Required {
+		log.Println("Email sign in is disabled--OIDC is required")
+		http.Error(w, "Email sign in is disabled on this server. Sign in with SSO instead.", http.StatusForbidden)
+		return true
+	}
+
+	return false
+}
+
+func requireOidcProvider(w http.ResponseWriter) *oidc.Provider {
+	provider, err := oidc.Default()
+	if err != nil {
+		log.Printf("Error loading OIDC config: %v\n", err)
+		http.Error(w, "Error loading OIDC config: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if provider == nil {
+		http.Error(w, "SSO isn't configured on this server", http.StatusNotFound)
+		return nil
+	}
+
+	return provider
+}
+
+// signInWithOidc finds or creates the user for verified ID token claims, adds them to their email domain's org with the role their groups map to, and starts a session
+func signInWithOidc(w http.ResponseWriter, r *http.Request, provider *oidc.Provider, claims *oidc.Claims) (*shared.SessionResponse, *shared.ApiError) {
+	if claims.Email == "" {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusForbidden, Msg: "The identity provider didn't share an email address--add the email scope"}
+	}
+
+	if !claims.EmailVerified {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusForbidden, Msg: "The identity provider hasn't verified " + claims.Email}
+	}
+
+	issuer := provider.Config.Issuer
+
+	user, err := db.GetUserForOidcIdentity(issuer, claims.Subject)
+	if err != nil {
+		log.Printf("Error getting user for OIDC identity: %v\n", err)
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusInternalServerError, Msg: "Error getting user: " + err.Error()}
+	}
+
+	// link existing accounts by email the first time they sign in with SSO
+	if user == nil {
+		user, err = db.GetUserByEmail(claims.Email)
+		if err != nil {
+			log.Printf("Error getting user: %v\n", err)
+			return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusInternalServerError, Msg: "Error getting user: " + err.Error()}
+		}
+	}
+
+	if user != nil && user.IsServiceAccount {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusForbidden, Msg: "Service accounts can't sign in"}
+	}
+
+	isNewUser := user == nil
+
+	var token string
+	var apiErr *shared.ApiError
+
+	err = db.WithTx(r.Context(), "oidc sign in", func(tx *sqlx.Tx) error {
+		if isNewUser {
+			name := claims.Name
+			if name == "" {
+				name = strings.Split(claims.Email, "@")[0]
+			}
+
+			user, err = db.CreateUser(name, claims.Email, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := db.CreateOidcIdentity(user.Id, issuer, claims.Subject, tx)
+		if err != nil {
+			return err
+		}
+
+		orgId, err := syncOidcDomainOrg(provider, user, claims.Groups, tx)
+		if err != nil {
+			return err
+		}
+
+		token, _, err = db.CreateAuthToken(user.Id, tx)
+		if err != nil {
+			return fmt.Errorf("error creating auth token: %v", err)
+		}
+
+		if isNewUser {
+			_, apiErr = hooks.ExecHook(hooks.CreateAccount, hooks.HookParams{
+				Auth: &types.ServerAuth{
+					User:  user,
+					OrgId: orgId,
+				},
+			})
+		}
+
+		return nil
+	})
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if err != nil {
+		log.Printf("Error signing in with OIDC: %v\n", err)
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusInternalServerError, Msg: "Error signing in: " + err.Error()}
+	}
+
+	orgs, err := db.GetAccessibleOrgsForUser(user)
+	if err != nil {
+		log.Printf("Error getting orgs for user: %v\n", err)
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusInternalServerError, Msg: "Error getting orgs for user: " + err.Error()}
+	}
+
+	var orgId string
+	if len(orgs) == 1 {
+		orgId = orgs[0].Id
+	}
+
+	err = SetAuthCookieIfBrowser(w, r, user, token, orgId)
+	if err != nil {
+		log.Printf("Error setting auth cookie: %v\n", err)
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusInternalServerError, Msg: "Error setting auth cookie: " + err.Error()}
+	}
+
+	apiOrgs, apiErr := toApiOrgs(orgs)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return &shared.SessionResponse{
+		UserId:      user.Id,
+		Token:       token,
+		Email:       user.Email,
+		UserName:    user.Name,
+		Orgs:        apiOrgs,
+		IsLocalMode: os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1",
+	}, nil
+}
+
+// syncOidcDomainOrg keeps the user's membership in the org for their email domain in step with their groups. They join it if it auto-adds domain users. Members get the role their groups map to--or the default role once OIDC_GROUP_ROLES is set and none of their groups match--on every sign in, whether or not the org auto-adds domain users. The org's last owner is never demoted.
+func syncOidcDomainOrg(provider *oidc.Provider, user *db.User, groups []string, tx *sqlx.Tx) (string, error) {
+	org, err := db.GetOrgForDomain(user.Domain)
+	if err != nil {
+		return "", err
+	}
+
+	if org == nil {
+		return "", nil
+	}
+
+	orgUser, err := db.GetOrgUser(user.Id, org.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if orgUser == nil && !org.AutoAddDomainUsers {
+		return "", nil
+	}
+
+	roleName := provider.Config.RoleForSignIn(groups, orgUser == nil)
+	if roleName == "" {
+		return org.Id, nil
+	}
+
+	var roleId string
+	role, err := db.GetOrgRoleByName(org.Id, roleName)
+	if err != nil {
+		return "", err
+	}
+
+	if role != nil {
+		roleId = role.Id
+	} else if orgUser != nil {
+		log.Printf("OIDC role %s doesn't exist in org %s--keeping the user's role\n", roleName, org.Id)
+		return org.Id, nil
+	} else {
+		log.Printf("OIDC role %s doesn't exist in org %s--adding the user as a member\n", roleName, org.Id)
+		roleId, err = db.GetOrgMemberRoleId()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if orgUser == nil {
+		return org.Id, db.CreateOrgUser(org.Id, user.Id, roleId, tx)
+	}
+
+	if roleId == orgUser.OrgRoleId {
+		return org.Id, nil
+	}
+
+	// never demote the org's last owner
+	ownerRoleId, err := db.GetOrgOwnerRoleId()
+	if err != nil {
+		return "", err
+	}
+	if orgUser.OrgRoleId == ownerRoleId {
+		numOwners, err := db.NumUsersWithRole(org.Id, ownerRoleId)
+		if err != nil {
+			return "", err
+		}
+		if numOwners <= 1 {
+			log.Printf("Not changing role of org %s's last owner\n", org.Id)
+			return org.Id, nil
+		}
+	}
+
+	return org.Id, db.SetOrgUserRole(org.Id, user.Id, roleId, tx)
+}
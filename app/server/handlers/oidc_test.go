@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"plandex-server/oidc"
+	"plandex-server/oidc/oidctest"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func newOidcTestProvider(t *testing.T, groupRoles string) (*oidc.Provider, *oidctest.Issuer) {
+	issuer, server, err := oidctest.NewServer("plandex")
+	if err != nil {
+		t.Fatalf("error starting mock issuer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	parsed, err := oidc.ParseGroupRoles(groupRoles)
+	if err != nil {
+		t.Fatalf("error parsing group roles: %v", err)
+	}
+
+	return oidc.NewProvider(oidc.Config{
+		Issuer:      issuer.URL,
+		ClientId:    "plandex",
+		Scopes:      []string{"openid", "email", "profile"},
+		GroupsClaim: "groups",
+		GroupRoles:  parsed,
+	}), issuer
+}
+
+// oidcSignIn signs in through signInWithOidc with claims verified from a token the mock issuer signed
+func oidcSignIn(t *testing.T, provider *oidc.Provider, issuer *oidctest.Issuer, identity oidctest.Identity) *db.User {
+	t.Helper()
+
+	token, err := issuer.IdToken(identity)
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+
+	claims, err := provider.VerifyIdToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("error verifying token: %v", err)
+	}
+
+	session, apiErr := signInWithOidc(httptest.NewRecorder(), httptest.NewRequest("POST", "/accounts/oidc/device/token", nil), provider, claims)
+	if apiErr != nil {
+		t.Fatalf("error signing in: %v", apiErr)
+	}
+
+	user, err := db.GetUser(session.UserId)
+	if err != nil {
+		t.Fatalf("error getting user: %v", err)
+	}
+	return user
+}
+
+// orgRoleName returns the name of the user's role in the org, or "" if they aren't a member
+func orgRoleName(t *testing.T, orgId, userId string) string {
+	t.Helper()
+
+	orgUser, err := db.GetOrgUser(userId, orgId)
+	if err != nil {
+		t.Fatalf("error getting org user: %v", err)
+	}
+	if orgUser == nil {
+		return ""
+	}
+
+	roles, err := db.ListOrgRoles(orgId)
+	if err != nil {
+		t.Fatalf("error listing roles: %v", err)
+	}
+	for _, role := range roles {
+		if role.Id == orgUser.OrgRoleId {
+			return role.Name
+		}
+	}
+
+	t.Fatalf("role %s isn't one of the org's roles", orgUser.OrgRoleId)
+	return ""
+}
+
+func setOrgRole(t *testing.T, orgId, userId, roleName string) {
+	t.Helper()
+
+	role, err := db.GetOrgRoleByName(orgId, roleName)
+	if err != nil || role == nil {
+		t.Fatalf("error getting role %s: %v", roleName, err)
+	}
+
+	err = db.WithTx(context.Background(), "set test role", func(tx *sqlx.Tx) error {
+		return db.SetOrgUserRole(orgId, userId, role.Id, tx)
+	})
+	if err != nil {
+		t.Fatalf("error setting role: %v", err)
+	}
+}
+
+func TestOidcSignInSyncsGroupRoles(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateDomainOrg(t, true)
+	provider, issuer := newOidcTestProvider(t, "plandex-admins=admin,engineering=member")
+
+	email := org.Email("dana")
+
+	user := oidcSignIn(t, provider, issuer, oidctest.Identity{Email: email, Name: "Dana", Groups: []string{"plandex-admins"}})
+	if role := orgRoleName(t, org.Org.Id, user.Id); role != "admin" {
+		t.Fatalf("expected new user to join as admin, got %q", role)
+	}
+
+	// leaving every mapped group falls back to the default role
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: email, Groups: []string{"sales"}})
+	if role := orgRoleName(t, org.Org.Id, user.Id); role != "member" {
+		t.Fatalf("expected admin outside every mapped group to be demoted to member, got %q", role)
+	}
+
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: email, Groups: []string{"plandex-admins"}})
+	if role := orgRoleName(t, org.Org.Id, user.Id); role != "admin" {
+		t.Fatalf("expected member to be promoted back to admin, got %q", role)
+	}
+}
+
+func TestOidcSignInNeverDemotesLastOwner(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateDomainOrg(t, true)
+	provider, issuer := newOidcTestProvider(t, "plandex-admins=admin")
+
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: org.Owner.Email})
+	if role := orgRoleName(t, org.Org.Id, org.Owner.Id); role != "owner" {
+		t.Fatalf("expected the last owner to stay owner, got %q", role)
+	}
+
+	// with a second owner, the first can be demoted
+	coOwner := org.AddMember(t, "co-owner")
+	setOrgRole(t, org.Org.Id, coOwner.Id, "owner")
+
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: org.Owner.Email})
+	if role := orgRoleName(t, org.Org.Id, org.Owner.Id); role != "member" {
+		t.Fatalf("expected owner outside every mapped group to be demoted once there's another owner, got %q", role)
+	}
+
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: coOwner.Email})
+	if role := orgRoleName(t, org.Org.Id, coOwner.Id); role != "owner" {
+		t.Fatalf("expected the remaining owner to stay owner, got %q", role)
+	}
+}
+
+func TestOidcSignInWithoutAutoAdd(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateDomainOrg(t, false)
+	provider, issuer := newOidcTestProvider(t, "plandex-admins=admin")
+
+	// new users aren't added to orgs that don't auto-add domain users
+	newUser := oidcSignIn(t, provider, issuer, oidctest.Identity{Email: org.Email("new"), Groups: []string{"plandex-admins"}})
+	if role := orgRoleName(t, org.Org.Id, newUser.Id); role != "" {
+		t.Fatalf("expected new user not to join, got %q", role)
+	}
+
+	// but existing members' roles still follow their groups
+	member := org.AddMember(t, "member")
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: member.Email, Groups: []string{"plandex-admins"}})
+	if role := orgRoleName(t, org.Org.Id, member.Id); role != "admin" {
+		t.Fatalf("expected existing member to get the mapped admin role, got %q", role)
+	}
+
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: member.Email})
+	if role := orgRoleName(t, org.Org.Id, member.Id); role != "member" {
+		t.Fatalf("expected existing member outside every mapped group to be demoted, got %q", role)
+	}
+}
+
+func TestOidcSignInWithoutGroupRoles(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateDomainOrg(t, true)
+	provider, issuer := newOidcTestProvider(t, "")
+
+	user := oidcSignIn(t, provider, issuer, oidctest.Identity{Email: org.Email("dana"), Groups: []string{"plandex-admins"}})
+	if role := orgRoleName(t, org.Org.Id, user.Id); role != "member" {
+		t.Fatalf("expected new user to join as member, got %q", role)
+	}
+
+	// without group roles, roles set in Plandex are left alone
+	setOrgRole(t, org.Org.Id, user.Id, "admin")
+	oidcSignIn(t, provider, issuer, oidctest.Identity{Email: user.Email})
+	if role := orgRoleName(t, org.Org.Id, user.Id); role != "admin" {
+		t.Fatalf("expected admin to keep their role, got %q", role)
+	}
+
+	// signing in again links the same account by subject
+	again := oidcSignIn(t, provider, issuer, oidctest.Identity{Email: user.Email})
+	if again.Id != user.Id {
+		t.Fatalf("expected the same user, got %s and %s", user.Id, again.Id)
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+var orgRoleNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func ListOrgPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListOrgPermissionsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionListOrgRoles) {
+		log.Println("User cannot list org roles")
+		http.Error(w, "User cannot list org roles", http.StatusForbidden)
+		return
+	}
+
+	permissions, err := db.ListOrgPermissions(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing org permissions: %v\n", err)
+		http.Error(w, "Error listing org permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiPermissions := []*shared.OrgPermission{}
+	for _, permission := range permissions {
+		apiPermissions = append(apiPermissions, permission.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiPermissions)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed org permissions")
+}
+
+// GetCurrentOrgRoleHandler returns the current user's role, with the permissions they actually have for this request (API tokens don't get org admin permissions)
+func GetCurrentOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetCurrentOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	orgUser, err := db.GetOrgUser(auth.User.Id, auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser == nil {
+		http.Error(w, "User isn't a member of the org", http.StatusNotFound)
+		return
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, orgUser.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role == nil {
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = []string{}
+	for key := range auth.Permissions {
+		apiRole.Permissions = append(apiRole.Permissions, key)
+	}
+	sort.Strings(apiRole.Permissions)
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got current org role")
+}
+
+func CreateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	var req shared.CreateOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Label = strings.TrimSpace(req.Label)
+	req.Description = strings.TrimSpace(req.Description)
+
+	if !orgRoleNameRegexp.MatchString(req.Name) {
+		http.Error(w, "Role name must be lowercase letters, numbers, '-' and '_', and at most 64 characters", http.StatusBadRequest)
+		return
+	}
+
+	if req.Label == "" {
+		req.Label = req.Name
+	}
+
+	existing, err := db.GetOrgRoleByName(auth.OrgId, req.Name)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if existing != nil {
+		http.Error(w, "A role named "+req.Name+" already exists", http.StatusConflict)
+		return
+	}
+
+	permissions := validateOrgRolePermissions(w, auth, req.Permissions)
+	if permissions == nil {
+		return
+	}
+
+	role := db.OrgRole{
+		OrgId:       &auth.OrgId,
+		Name:        req.Name,
+		Label:       req.Label,
+		Description: req.Description,
+	}
+
+	err = db.WithTx(r.Context(), "create org role", func(tx *sqlx.Tx) error {
+		return db.CreateOrgRole(&role, permissions, tx)
+	})
+
+	if err != nil {
+		if err == db.ErrOrgRoleExists {
+			http.Error(w, "A role named "+req.Name+" already exists", http.StatusConflict)
+			return
+		}
+		log.Printf("Error creating org role: %v\n", err)
+		http.Error(w, "Error creating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created org role")
+}
+
+func UpdateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	role := authorizeCustomOrgRole(w, mux.Vars(r)["roleId"], auth)
+	if role == nil {
+		return
+	}
+
+	var req shared.UpdateOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	permissions := validateOrgRolePermissions(w, auth, req.Permissions)
+	if permissions == nil {
+		return
+	}
+
+	if label := strings.TrimSpace(req.Label); label != "" {
+		role.Label = label
+	}
+	role.Description = strings.TrimSpace(req.Description)
+
+	err := db.WithTx(r.Context(), "update org role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgRole(role, permissions, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error updating org role: %v\n", err)
+		http.Error(w, "Error updating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully updated org role")
+}
+
+func DeleteOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	role := authorizeCustomOrgRole(w, mux.Vars(r)["roleId"], auth)
+	if role == nil {
+		return
+	}
+
+	err := db.WithTx(r.Context(), "delete org role", func(tx *sqlx.Tx) error {
+		return db.DeleteOrgRole(auth.OrgId, role.Id, tx)
+	})
+
+	if err != nil {
+		if err == db.ErrOrgRoleInUse {
+			http.Error(w, "Users or pending invites still have the "+role.Label+" role--give them another role first", http.StatusConflict)
+			return
+		}
+		log.Printf("Error deleting org role: %v\n", err)
+		http.Error(w, "Error deleting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully deleted org role")
+}
+
+func authorizeManageOrgRoles(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't manage org roles",
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		log.Println("User does not have permission to manage org roles")
+		http.Error(w, "User does not have permission to manage org roles", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// authorizeCustomOrgRole returns one of the org's custom roles if the user can manage it. Default roles can't be changed.
+func authorizeCustomOrgRole(w http.ResponseWriter, roleId string, auth *types.ServerAuth) *db.OrgRole {
+	if !authorizeManageOrgRoles(w, auth) {
+		return nil
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if role == nil {
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	if role.OrgId == nil {
+		http.Error(w, "Default roles can't be changed", http.StatusForbidden)
+		return nil
+	}
+
+	return role
+}
+
+// validateOrgRolePermissions checks that each permission key exists for the org and that the user has it themselves, so custom roles can't be used to gain access. It returns the de-duplicated keys, or nil after writing an error.
+func validateOrgRolePermissions(w http.ResponseWriter, auth *types.ServerAuth, keys []string) []string {
+	orgPermissions, err := db.ListOrgPermissions(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing org permissions: %v\n", err)
+		http.Error(w, "Error listing org permissions: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return checkOrgRolePermissions(w, auth, orgPermissions, keys)
+}
+
+func checkOrgRolePermissions(w http.ResponseWriter, auth *types.ServerAuth, orgPermissions []*db.Permission, keys []string) []string {
+	validKeys := map[string]bool{}
+	for _, permission := range orgPermissions {
+		validKeys[permission.Key()] = true
+	}
+
+	res := []string{}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if !validKeys[key] {
+			http.Error(w, "Unknown permission: "+key, http.StatusBadRequest)
+			return nil
+		}
+
+		if !auth.Permissions[key] {
+			http.Error(w, "You can't give a role a permission you don't have: "+key, http.StatusForbidden)
+			return nil
+		}
+
+		res = append(res, key)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
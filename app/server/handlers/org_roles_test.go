@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"plandex-server/types"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestCheckOrgRolePermissions(t *testing.T) {
+	ownerRoleId := "owner-role"
+	reviewerRoleId := "reviewer-role"
+
+	orgPermissions := []*db.Permission{
+		{Name: string(shared.PermissionCreatePlan)},
+		{Name: string(shared.PermissionUpdateAnyPlan)},
+		{Name: string(shared.PermissionManageBilling)},
+		{Name: string(shared.PermissionInviteUser), ResourceId: &ownerRoleId},
+		{Name: string(shared.PermissionInviteUser), ResourceId: &reviewerRoleId},
+	}
+
+	// an admin has neither manage_billing nor any permission scoped to the owner role
+	admin := &types.ServerAuth{Permissions: shared.Permissions{
+		string(shared.PermissionCreatePlan):                               true,
+		string(shared.PermissionUpdateAnyPlan):                            true,
+		shared.PermissionKey(shared.PermissionInviteUser, reviewerRoleId): true,
+	}}
+
+	tests := []struct {
+		name       string
+		keys       []string
+		want       []string
+		wantStatus int
+	}{
+		{
+			name:       "permissions the admin has",
+			keys:       []string{"update_any_plan", " create_plan", "create_plan", "invite_user|reviewer-role"},
+			want:       []string{"create_plan", "invite_user|reviewer-role", "update_any_plan"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no permissions",
+			keys:       nil,
+			want:       []string{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown permission",
+			keys:       []string{"create_plan", "launch_missiles"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "permission the admin lacks",
+			keys:       []string{"create_plan", "manage_billing"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "managing a role the admin can't manage",
+			keys:       []string{"invite_user|owner-role"},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			got := checkOrgRolePermissions(w, admin, orgPermissions, tt.keys)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("permissions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminCantGrantPermissionsTheyLack(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateOrg(t)
+	orgId := org.Org.Id
+
+	billing := &db.OrgRole{OrgId: &orgId, Name: "billing", Label: "Billing"}
+	err := db.WithTx(context.Background(), "create test org role", func(tx *sqlx.Tx) error {
+		return db.CreateOrgRole(billing, []string{string(shared.PermissionManageBilling)}, tx)
+	})
+	if err != nil {
+		t.Fatalf("error creating role: %v", err)
+	}
+
+	adminRole, err := db.GetOrgRoleByName(orgId, "admin")
+	if err != nil || adminRole == nil {
+		t.Fatalf("error getting admin role: %v", err)
+	}
+	rolePermissions, err := db.ListOrgRolePermissions(orgId)
+	if err != nil {
+		t.Fatalf("error listing role permissions: %v", err)
+	}
+
+	admin := &types.ServerAuth{User: org.Owner, OrgId: orgId, Permissions: shared.Permissions{}}
+	for _, key := range rolePermissions[adminRole.Id] {
+		admin.Permissions[key] = true
+	}
+
+	for _, keys := range [][]string{
+		{string(shared.PermissionManageBilling)},
+		{shared.PermissionKey(shared.PermissionInviteUser, billing.Id)},
+	} {
+		w := httptest.NewRecorder()
+		if res := validateOrgRolePermissions(w, admin, keys); res != nil || w.Code != http.StatusForbidden {
+			t.Errorf("expected admin creating a role with %v to be forbidden, got %d", keys, w.Code)
+		}
+	}
+
+	if admin.HasPermissionForResource(shared.PermissionSetUserRole, billing.Id) || admin.HasPermissionForResource(shared.PermissionInviteUser, billing.Id) {
+		t.Errorf("expected admin not to be able to assign the billing role")
+	}
+
+	w := httptest.NewRecorder()
+	if res := validateOrgRolePermissions(w, admin, []string{string(shared.PermissionCreatePlan)}); res == nil {
+		t.Errorf("expected admin to be able to grant create_plan, got %d: %s", w.Code, w.Body.String())
+	}
+}
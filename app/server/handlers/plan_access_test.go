@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"plandex-server/types"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCheckPlanWrite(t *testing.T) {
+	plan := &db.Plan{Id: "plan", OwnerId: "owner"}
+
+	tests := []struct {
+		name       string
+		share      *db.PlanShare
+		wantStatus int
+	}{
+		{"owner or org access", nil, http.StatusOK},
+		{"collaborator", &db.PlanShare{Access: shared.PlanShareAccessCollaborator}, http.StatusOK},
+		{"read-only", &db.PlanShare{Access: shared.PlanShareAccessRead}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			res := checkPlanWrite(w, plan, tt.share)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if (res != nil) != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("plan = %v with status %d", res, w.Code)
+			}
+		})
+	}
+}
+
+func TestCheckPlanExecUpdate(t *testing.T) {
+	plan := &db.Plan{Id: "plan", OwnerId: "owner"}
+
+	updateAny := shared.Permissions{string(shared.PermissionUpdateAnyPlan): true}
+
+	tests := []struct {
+		name        string
+		share       *db.PlanShare
+		userId      string
+		permissions shared.Permissions
+		wantStatus  int
+	}{
+		{"owner", nil, "owner", nil, http.StatusOK},
+		{"member with update_any_plan", nil, "member", updateAny, http.StatusOK},
+		{"member without update_any_plan", nil, "member", nil, http.StatusForbidden},
+		{"collaborator without update_any_plan", &db.PlanShare{Access: shared.PlanShareAccessCollaborator}, "member", nil, http.StatusOK},
+		{"read-only", &db.PlanShare{Access: shared.PlanShareAccessRead}, "member", nil, http.StatusForbidden},
+		{"read-only with update_any_plan", &db.PlanShare{Access: shared.PlanShareAccessRead}, "member", updateAny, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &types.ServerAuth{User: &db.User{Id: tt.userId}, Permissions: tt.permissions}
+
+			w := httptest.NewRecorder()
+			res := checkPlanExecUpdate(w, plan, tt.share, auth)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if (res != nil) != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("plan = %v with status %d", res, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthorizePlanShares(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateOrg(t)
+	reader := org.AddMember(t, "reader")
+	collaborator := org.AddMember(t, "collaborator")
+	member := org.AddMember(t, "member")
+	org.SharePlan(t, reader.Id, shared.PlanShareAccessRead)
+	org.SharePlan(t, collaborator.Id, shared.PlanShareAccessCollaborator)
+
+	authFor := func(user *db.User) *types.ServerAuth {
+		return &types.ServerAuth{User: user, OrgId: org.Org.Id, Permissions: shared.Permissions{}}
+	}
+
+	type authorizeFn func(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan
+
+	tests := []struct {
+		name       string
+		authorize  authorizeFn
+		user       *db.User
+		wantStatus int
+	}{
+		{"read as reader", authorizePlan, reader, http.StatusOK},
+		{"write as reader", authorizePlanWrite, reader, http.StatusForbidden},
+		{"exec as reader", authorizePlanExecUpdate, reader, http.StatusForbidden},
+		{"write as collaborator", authorizePlanWrite, collaborator, http.StatusOK},
+		{"exec as collaborator", authorizePlanExecUpdate, collaborator, http.StatusOK},
+		{"update as collaborator", authorizePlanUpdate, collaborator, http.StatusForbidden},
+		{"exec as owner", authorizePlanExecUpdate, org.Owner, http.StatusOK},
+		{"read without share", authorizePlan, member, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			plan := tt.authorize(w, org.Plan.Id, authFor(tt.user))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if (plan != nil) != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("plan = %v with status %d", plan, w.Code)
+			}
+		})
+	}
+}
+
+func TestFileMapRoutesReadShare(t *testing.T) {
+	dbtest.Setup(t)
+
+	org := dbtest.CreateOrg(t)
+	reader := org.AddMember(t, "reader")
+	org.SharePlan(t, reader.Id, shared.PlanShareAccessRead)
+
+	auth := &types.ServerAuth{User: reader, OrgId: org.Org.Id, Permissions: shared.Permissions{}}
+
+	newRequest := func(body any, vars map[string]string) *http.Request {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(bodyBytes))
+		r = r.WithContext(context.WithValue(r.Context(), forwardedAuthContextKey{}, auth))
+		return mux.SetURLVars(r, vars)
+	}
+
+	// both routes write to the plan--loaded contexts and the semantic index--so a read share can't use them
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		req     *http.Request
+	}{
+		{
+			"get file map",
+			GetFileMapHandler,
+			newRequest(shared.GetFileMapRequest{PlanId: org.Plan.Id, MapInputs: shared.FileMapInputs{"main.go": "package main"}}, nil),
+		},
+		{
+			"load cached file map",
+			LoadCachedFileMapHandler,
+			newRequest(shared.LoadCachedFileMapRequest{FilePaths: []string{"main.go"}}, map[string]string{"planId": org.Plan.Id, "branch": "main"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, tt.req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanSharesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanSharesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	planId := mux.Vars(r)["planId"]
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	shares, err := db.ListPlanShares(planId)
+	if err != nil {
+		log.Printf("Error listing plan shares: %v\n", err)
+		http.Error(w, "Error listing plan shares: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := []*shared.PlanShare{}
+	for _, share := range shares {
+		res = append(res, share.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling plan shares: %v\n", err)
+		http.Error(w, "Error marshalling plan shares: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed plan shares")
+}
+
+func SharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	planId := mux.Vars(r)["planId"]
+
+	plan := authorizePlanShares(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var req shared.SharePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Access == "" {
+		req.Access = shared.PlanShareAccessCollaborator
+	}
+
+	if !shared.IsValidPlanShareAccess(req.Access) {
+		http.Error(w, "Invalid access: "+string(req.Access), http.StatusBadRequest)
+		return
+	}
+
+	user, err := db.GetUserByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		log.Printf("Error getting user: %v\n", err)
+		http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var isMember bool
+	if user != nil {
+		isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+		if err != nil {
+			log.Printf("Error validating org membership: %v\n", err)
+			http.Error(w, "Error validating org membership: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if !isMember {
+		http.Error(w, "No org member with email "+req.Email, http.StatusNotFound)
+		return
+	}
+
+	if user.Id == plan.OwnerId {
+		http.Error(w, "Plan can't be shared with its owner", http.StatusBadRequest)
+		return
+	}
+
+	share := &db.PlanShare{
+		OrgId:     auth.OrgId,
+		PlanId:    plan.Id,
+		UserId:    user.Id,
+		Access:    req.Access,
+		CreatedBy: &auth.User.Id,
+		UserEmail: user.Email,
+		UserName:  user.Name,
+	}
+
+	err = db.UpsertPlanShare(share)
+	if err != nil {
+		log.Printf("Error sharing plan: %v\n", err)
+		http.Error(w, "Error sharing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(share.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling plan share: %v\n", err)
+		http.Error(w, "Error marshalling plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully shared plan")
+}
+
+func UnsharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UnsharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	userId := vars["userId"]
+
+	// users can always remove themselves from a plan shared with them
+	var plan *db.Plan
+	if userId == auth.User.Id {
+		plan = authorizePlan(w, planId, auth)
+	} else {
+		plan = authorizePlanShares(w, planId, auth)
+	}
+	if plan == nil {
+		return
+	}
+
+	err := db.DeletePlanShare(planId, userId)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Plan isn't shared with this user", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error unsharing plan: %v\n", err)
+		http.Error(w, "Error unsharing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully unshared plan")
+}
+
+func ListSharedPlansHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListSharedPlansHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// with no projectId, lists plans shared with the user in every project
+	var projectIds []string
+	if ids := r.URL.Query()["projectId"]; len(ids) > 0 {
+		projectIds = ids
+	}
+
+	plans, err := db.ListSharedPlans(auth.OrgId, auth.User.Id, projectIds, r.URL.Query().Get("archived") == "true")
+	if err != nil {
+		log.Printf("Error listing shared plans: %v\n", err)
+		http.Error(w, "Error listing shared plans: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := []*shared.Plan{}
+	for _, plan := range plans {
+		res = append(res, plan.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling shared plans: %v\n", err)
+		http.Error(w, "Error marshalling shared plans: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed shared plans")
+}
+
+// only the plan's owner, or users who can manage any plan's shares, can share it or remove others from it
+func authorizePlanShares(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan := authorizePlan(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionManageAnyPlanShares) {
+		log.Println("User does not have permission to manage plan shares")
+		http.Error(w, "User does not have permission to manage plan shares", http.StatusForbidden)
+		return nil
+	}
+
+	return plan
+}
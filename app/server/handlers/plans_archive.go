@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"regexp"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func ExportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ExportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	// build the archive in a temp file so errors can still be returned before anything is written to the response
+	archiveFile, err := os.CreateTemp("", "plandex-archive-*")
+	if err != nil {
+		log.Printf("Error creating temp file: %v\n", err)
+		http.Error(w, "Error creating temp file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   "main",
+		Reason:   "export plan",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		return db.ExportPlanArchive(repo, plan, archiveFile)
+	})
+
+	if err != nil {
+		log.Printf("Error exporting plan: %v\n", err)
+		http.Error(w, "Error exporting plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = archiveFile.Seek(0, io.SeekStart)
+	if err != nil {
+		log.Printf("Error reading plan archive: %v\n", err)
+		http.Error(w, "Error reading plan archive: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fileName := unsafeFileNameChars.ReplaceAllString(plan.Name, "-") + ".plandex.tar.gz"
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	_, err = io.Copy(w, archiveFile)
+	if err != nil {
+		log.Printf("Error writing plan archive: %v\n", err)
+		return
+	}
+
+	log.Println("Successfully exported plan")
+}
+
+func ImportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ImportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionCreatePlan) {
+		log.Println("User does not have permission to create a plan")
+		http.Error(w, "User does not have permission to create a plan", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillCreatePlan, hooks.HookParams{Auth: auth})
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, db.MaxPlanArchiveSize)
+	defer r.Body.Close()
+
+	archive, err := db.ReadPlanArchive(r.Body)
+	if err != nil {
+		log.Printf("Error reading plan archive: %v\n", err)
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || errors.Is(err, db.ErrPlanArchiveTooLarge) {
+			http.Error(w, "Error reading plan archive: "+db.ErrPlanArchiveTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		http.Error(w, "Error reading plan archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer archive.Cleanup()
+
+	name, err := getUniquePlanName(projectId, auth.User.Id, archive.Manifest.Name)
+	if err != nil {
+		log.Printf("Error checking if plan exists: %v\n", err)
+		http.Error(w, "Error checking if plan exists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	plan, err := db.ImportPlanArchive(r.Context(), archive, db.ImportPlanArchiveParams{
+		OrgId:     auth.OrgId,
+		ProjectId: projectId,
+		UserId:    auth.User.Id,
+		Name:      name,
+	})
+
+	if err != nil {
+		log.Printf("Error importing plan: %v\n", err)
+		http.Error(w, "Error importing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := shared.ImportPlanResponse{
+		Id:          plan.Id,
+		Name:        plan.Name,
+		NumBranches: plan.ActiveBranches,
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully imported plan: %s\n", plan.Id)
+}
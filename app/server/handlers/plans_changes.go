@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/webhooks"
 	"time"
 	"regexp"
 	"strings"
@@ -135,11 +136,17 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionApplyPlan) {
+		log.Println("User does not have permission to apply plans")
+		http.Error(w, "User does not have permission to apply plans", http.StatusForbidden)
+		return
+	}
+
 	var err error
 
 	// read the request body
@@ -158,6 +165,10 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(requestBody.Paths) > 0 {
+		log.Println("Applying only paths:", requestBody.Paths)
+	}
+
 	// Just in case this was sent immediately after a stream finished, wait a little before locking to allow for cleanup
 	time.Sleep(100 * time.Millisecond)
 
@@ -224,6 +235,8 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	commitMsg = sanitizeCommitMessage(commitMsg)
 
+	appliedPaths := pendingPathsToApply(currentPlanParams.PlanFileResults, requestBody.Paths)
+
 	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
 		OrgId:          auth.OrgId,
 		UserId:         auth.User.Id,
@@ -242,6 +255,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 			CurrentPlanState:       currentPlan,
 			CurrentPlanStateParams: &currentPlanParams,
 			CommitMsg:              commitMsg,
+			Paths:                  requestBody.Paths,
 		})
 	})
 
@@ -253,9 +267,42 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write([]byte(commitMsg))
 
+	webhooks.Trigger(webhooks.TriggerParams{
+		Event:     shared.WebhookEventChangesApplied,
+		OrgId:     auth.OrgId,
+		UserId:    auth.User.Id,
+		PlanId:    planId,
+		PlanName:  plan.Name,
+		Branch:    branch,
+		Paths:     appliedPaths,
+		CommitMsg: commitMsg,
+	})
+
 	log.Println("Successfully applied plan", planId)
 }
 
+func pendingPathsToApply(results []*db.PlanFileResult, onlyPaths []string) []string {
+	onlySet := map[string]bool{}
+	for _, path := range onlyPaths {
+		onlySet[path] = true
+	}
+
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, result := range results {
+		if result.Path == "_apply.sh" || seen[result.Path] || !result.ToApi().IsPending() {
+			continue
+		}
+		if len(onlySet) > 0 && !onlySet[result.Path] {
+			continue
+		}
+		seen[result.Path] = true
+		paths = append(paths, result.Path)
+	}
+
+	return paths
+}
+
 func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for RejectAllChangesHandler")
 
@@ -269,7 +316,7 @@ func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
@@ -321,7 +368,7 @@ func RejectFileHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
@@ -381,7 +428,7 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
@@ -437,6 +484,136 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
+func RejectReplacementHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RejectReplacementHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanWrite(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.RejectReplacementRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		result, err := db.GetPlanFileResultById(auth.OrgId, planId, req.ResultId)
+		if err != nil {
+			return fmt.Errorf("error getting plan file result: %v", err)
+		}
+
+		err = db.RejectReplacement(auth.OrgId, planId, req.ResultId, req.ReplacementId)
+		if err != nil {
+			return err
+		}
+
+		err = repo.GitAddAndCommit(branch, fmt.Sprintf("🚫 Rejected a pending change to file: %s", result.Path))
+		if err != nil {
+			return fmt.Errorf("error committing rejected change: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error rejecting replacement: %v\n", err)
+		http.Error(w, "Error rejecting replacement: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully rejected replacement", req.ReplacementId)
+}
+
+func EditReplacementHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for EditReplacementHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanWrite(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.EditReplacementRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		result, err := db.GetPlanFileResultById(auth.OrgId, planId, req.ResultId)
+		if err != nil {
+			return fmt.Errorf("error getting plan file result: %v", err)
+		}
+
+		err = db.EditReplacement(auth.OrgId, planId, req.ResultId, req.ReplacementId, req.New)
+		if err != nil {
+			return err
+		}
+
+		err = repo.GitAddAndCommit(branch, fmt.Sprintf("✏️  Edited a pending change to file: %s", result.Path))
+		if err != nil {
+			return fmt.Errorf("error committing edited change: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error editing replacement: %v\n", err)
+		http.Error(w, "Error editing replacement: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully edited replacement", req.ReplacementId)
+}
+
 func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 	auth := Authenticate(w, r, true)
 	if auth == nil {
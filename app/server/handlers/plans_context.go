@@ -164,7 +164,7 @@ func LoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -224,7 +224,7 @@ func UpdateContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -290,6 +290,21 @@ func UpdateContextHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !updateRes.MaxTokensExceeded {
+		var removedMapPaths []string
+		for _, params := range requestBody {
+			if params != nil {
+				removedMapPaths = append(removedMapPaths, params.RemovedMapPaths...)
+			}
+		}
+
+		// files removed from the project map are removed from the semantic index too
+		err = db.DeleteSemanticChunks(plan.ProjectId, removedMapPaths)
+		if err != nil {
+			log.Printf("Error removing paths from semantic index: %v\n", err)
+		}
+	}
+
 	if updateRes.MaxTokensExceeded {
 		log.Printf("The total number of tokens (%d) exceeds the maximum allowed (%d)", updateRes.TotalTokens, updateRes.MaxTokens)
 		bytes, err := json.Marshal(updateRes)
@@ -330,7 +345,7 @@ func DeleteContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 
 	if plan == nil {
 		return
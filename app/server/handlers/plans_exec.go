@@ -12,7 +12,9 @@ import (
 	"plandex-server/host"
 	modelPlan "plandex-server/model/plan"
 	"plandex-server/notify"
+	"plandex-server/streambus"
 	"plandex-server/types"
+	"strconv"
 	"time"
 
 	shared "plandex-shared"
@@ -59,6 +61,12 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the model shouldn't write commands for users who can't run them
+	if requestBody.ExecEnabled && !auth.HasPermission(shared.PermissionExecCommands) {
+		log.Println("User does not have permission to run commands--disabling exec")
+		requestBody.ExecEnabled = false
+	}
+
 	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
@@ -85,13 +93,17 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Printf("Error telling plan: %v\n", err)
+		if apiErr, ok := err.(*shared.ApiError); ok {
+			writeApiError(w, *apiErr)
+			return
+		}
 		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error telling plan: %v", err))
 		http.Error(w, "Error telling plan", http.StatusInternalServerError)
 		return
 	}
 
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		startResponseStream(r.Context(), w, auth, planId, branch, false, 0)
 	}
 
 	log.Println("Successfully processed request for TellPlanHandler")
@@ -146,10 +158,14 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 			plan:        plan,
 		},
 	)
-	numBuilds, err := modelPlan.Build(clients, plan, branch, auth, requestBody.SessionId)
+	numBuilds, err := modelPlan.Build(clients, plan, branch, auth, requestBody.SessionId, requestBody.DiagnosticsExts)
 
 	if err != nil {
 		log.Printf("Error building plan: %v\n", err)
+		if apiErr, ok := err.(*shared.ApiError); ok {
+			writeApiError(w, *apiErr)
+			return
+		}
 		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error building plan: %v", err))
 		http.Error(w, "Error building plan", http.StatusInternalServerError)
 		return
@@ -163,7 +179,7 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		startResponseStream(r.Context(), w, auth, planId, branch, false, 0)
 	}
 
 	log.Println("Successfully processed request for BuildPlanHandler")
@@ -180,6 +196,17 @@ func ConnectPlanHandler(w http.ResponseWriter, r *http.Request) {
 	active := modelPlan.GetActivePlan(planId, branch)
 	isProxy := r.URL.Query().Get("proxy") == "true"
 
+	// set by a client reconnecting after losing its connection--the seq of the last message it received
+	var resumeFrom int64
+	if resumeFromParam := r.URL.Query().Get("resumeFrom"); resumeFromParam != "" {
+		var err error
+		resumeFrom, err = strconv.ParseInt(resumeFromParam, 10, 64)
+		if err != nil || resumeFrom < 0 {
+			http.Error(w, "Invalid resumeFrom", http.StatusBadRequest)
+			return
+		}
+	}
+
 	if active == nil {
 		if isProxy {
 			log.Println("No active plan on proxied request")
@@ -188,6 +215,25 @@ func ConnectPlanHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if tryResumeOrphanedStream(w, r, planId, branch) {
+			return
+		}
+
+		if streambus.Distributed() {
+			auth := Authenticate(w, r, true)
+			if auth == nil {
+				return
+			}
+
+			if authorizePlan(w, planId, auth) == nil {
+				return
+			}
+
+			log.Println("No active plan -- relaying stream from stream bus")
+			startBusResponseStream(r.Context(), w, planId, branch, resumeFrom)
+			return
+		}
+
 		log.Println("No active plan -- proxying request")
 
 		proxyActivePlanMethod(w, r, planId, branch, "connect")
@@ -206,7 +252,7 @@ func ConnectPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startResponseStream(r.Context(), w, auth, planId, branch, true)
+	startResponseStream(r.Context(), w, auth, planId, branch, true, resumeFrom)
 
 	log.Println("Successfully processed request for ConnectPlanHandler")
 }
@@ -237,7 +283,7 @@ func StopPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
@@ -314,7 +360,7 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -381,6 +427,61 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully processed request for RespondMissingFileHandler")
 }
 
+func RespondDiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RespondDiagnosticsHandler", "ip:", host.Ip)
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId)
+	log.Println("branch: ", branch)
+	isProxy := r.URL.Query().Get("proxy") == "true"
+
+	active := modelPlan.GetActivePlan(planId, branch)
+	if active == nil {
+		if isProxy {
+			log.Println("No active plan on proxied request")
+			http.Error(w, "No active plan", http.StatusNotFound)
+			return
+		}
+
+		proxyActivePlanMethod(w, r, planId, branch, "diagnostics")
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	plan := authorizePlanWrite(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var requestBody shared.DiagnosticsResponse
+	if err := json.Unmarshal(body, &requestBody); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	// with more than one client connected, only the first response is used
+	if !active.ResolveDiagnostics(requestBody) {
+		log.Printf("No build waiting for diagnostics %s\n", requestBody.Id)
+	}
+
+	log.Println("Successfully processed request for RespondDiagnosticsHandler")
+}
+
 func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for AutoLoadContextHandler", "ip:", host.Ip)
 
@@ -409,7 +510,7 @@ func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -524,7 +625,7 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		proxyActivePlanMethod(w, r, planId, branch, "auto_load_context")
+		proxyActivePlanMethod(w, r, planId, branch, "build_status")
 		return
 	}
 
@@ -556,11 +657,25 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func authorizePlanExecUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
+	plan, share := authorizePlanAccess(w, planId, auth)
 	if plan == nil {
 		return nil
 	}
 
+	return checkPlanExecUpdate(w, plan, share, auth)
+}
+
+// checkPlanExecUpdate lets collaborators run tells and builds on a plan shared with them--their share grants it, so update_any_plan isn't needed
+func checkPlanExecUpdate(w http.ResponseWriter, plan *db.Plan, share *db.PlanShare, auth *types.ServerAuth) *db.Plan {
+	if share != nil {
+		if share.Access == shared.PlanShareAccessCollaborator {
+			return plan
+		}
+		log.Println("User has read-only access to plan")
+		http.Error(w, "Plan is shared with you read-only", http.StatusForbidden)
+		return nil
+	}
+
 	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
 		log.Println("User does not have permission to update plan")
 		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
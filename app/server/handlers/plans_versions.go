@@ -34,7 +34,7 @@ func ListLogsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 
 	var body string
-	var shas []string
+	var entries []shared.LogEntry
 
 	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
 		OrgId:    auth.OrgId,
@@ -47,7 +47,7 @@ func ListLogsHandler(w http.ResponseWriter, r *http.Request) {
 		CancelFn: cancel,
 	}, func(repo *db.GitRepo) error {
 		var err error
-		body, shas, err = repo.GetGitCommitHistory(branch)
+		body, entries, err = repo.GetGitCommitHistory(branch)
 		if err != nil {
 			return err
 		}
@@ -61,9 +61,15 @@ func ListLogsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	shas := make([]string, len(entries))
+	for i, entry := range entries {
+		shas[i] = entry.Sha
+	}
+
 	res := shared.LogResponse{
-		Body: body,
-		Shas: shas,
+		Body:    body,
+		Shas:    shas,
+		Entries: entries,
 	}
 
 	bytes, err := json.Marshal(res)
@@ -93,7 +99,7 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanWrite(w, planId, auth) == nil {
 		return
 	}
 
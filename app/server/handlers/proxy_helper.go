@@ -1,16 +1,26 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"plandex-server/db"
 	"plandex-server/host"
+	modelPlan "plandex-server/model/plan"
+	"plandex-server/streambus"
+	"plandex-server/types"
+	"strings"
 	"time"
 
 	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
 )
 
 func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branch, method string) {
@@ -28,6 +38,16 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 		return
 	}
 
+	if modelStream.Orphaned() {
+		handleOrphanedStreamMethod(w, r, modelStream, method)
+		return
+	}
+
+	if streambus.Distributed() {
+		proxyActivePlanMethodOverBus(w, r, planId, branch, method)
+		return
+	}
+
 	if modelStream.InternalIp == host.Ip {
 		// No active plan for this plan or else we wouldn't be calling proxyActivePlanMethod -- set the model stream to finished because something went wrong
 		err := db.SetModelStreamFinished(modelStream.Id)
@@ -47,6 +67,9 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 		log.Printf("Forwarding request to %s\n", modelStream.InternalIp)
 		proxyUrl := fmt.Sprintf("http://%s:%s/plans/%s/%s/%s", modelStream.InternalIp, os.Getenv("PORT"), planId, branch, method)
 		proxyUrl += "?proxy=true"
+		if r.URL.RawQuery != "" {
+			proxyUrl += "&" + r.URL.RawQuery
+		}
 
 		log.Printf("Proxy url: %s\n", proxyUrl)
 		proxyRequest(w, r, proxyUrl)
@@ -54,9 +77,53 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 	}
 }
 
+const orphanedStreamErrMsg = "The server running this plan stopped. The plan resumes when its stream reconnects, or use 'plandex continue'."
+
+// handleOrphanedStreamMethod handles requests for a stream whose instance stopped and that's waiting to be resumed (see tryResumeOrphanedStream). Stopping it finishes it; anything else waits for the resume.
+func handleOrphanedStreamMethod(w http.ResponseWriter, r *http.Request, modelStream *db.ModelStream, method string) {
+	if method != "stop" {
+		log.Printf("Model stream %s is orphaned--can't handle %s until it's resumed\n", modelStream.Id, method)
+		http.Error(w, orphanedStreamErrMsg, http.StatusServiceUnavailable)
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if authorizePlanWrite(w, modelStream.PlanId, auth) == nil {
+		return
+	}
+
+	finished, err := db.FinishOrphanedModelStream(modelStream.Id)
+	if err != nil {
+		log.Printf("Error finishing orphaned model stream: %v\n", err)
+		http.Error(w, "Error stopping plan", http.StatusInternalServerError)
+		return
+	}
+
+	if !finished {
+		// resumed since it was loaded
+		http.Error(w, "The plan was just resumed--try again", http.StatusConflict)
+		return
+	}
+
+	err = db.SetPlanStatus(modelStream.PlanId, modelStream.Branch, shared.PlanStatusStopped, "")
+	if err != nil {
+		log.Printf("Error setting plan %s status to stopped: %v\n", modelStream.PlanId, err)
+		http.Error(w, "Error stopping plan", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Stopped orphaned model stream %s\n", modelStream.Id)
+}
+
+const proxyTimeout = 10 * time.Second
+
 func proxyRequest(w http.ResponseWriter, originalRequest *http.Request, url string) {
 	client := &http.Client{
-		Timeout: time.Second * 10,
+		Timeout: proxyTimeout,
 	}
 
 	// Create a new request based on the original request
@@ -104,3 +171,187 @@ func proxyRequest(w http.ResponseWriter, originalRequest *http.Request, url stri
 		http.Error(w, "Error copying response body", http.StatusInternalServerError)
 	}
 }
+
+func proxyActivePlanMethodOverBus(w http.ResponseWriter, r *http.Request, planId, branch, method string) {
+	log.Printf("Forwarding %s request over stream bus\n", method)
+
+	// control requests reach every instance, so authenticate here and forward only the resolved ids
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var apiTokenId string
+	if auth.ApiToken != nil {
+		apiTokenId = auth.ApiToken.Id
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), proxyTimeout)
+	defer cancel()
+
+	res, err := streambus.Request(ctx, streambus.ControlRequest{
+		PlanId:     planId,
+		Branch:     branch,
+		Method:     method,
+		HttpMethod: r.Method,
+		UserId:     auth.User.Id,
+		OrgId:      auth.OrgId,
+		ApiTokenId: apiTokenId,
+		Body:       body,
+	})
+
+	if err != nil {
+		log.Printf("Error forwarding request over stream bus: %v\n", err)
+		http.Error(w, "Error forwarding request", http.StatusInternalServerError)
+		return
+	}
+
+	for name, headers := range res.Header {
+		for _, h := range headers {
+			w.Header().Add(name, h)
+		}
+	}
+	w.WriteHeader(res.Status)
+	w.Write(res.Body)
+
+	log.Printf("Stream bus forwarded successfully with status code: %d\n", res.Status)
+}
+
+// active plan methods that other instances can forward over the stream bus
+var activePlanControlHandlers = map[string]http.HandlerFunc{
+	"stop":                 StopPlanHandler,
+	"respond_missing_file": RespondMissingFileHandler,
+	"auto_load_context":    AutoLoadContextHandler,
+	"diagnostics":          RespondDiagnosticsHandler,
+	"build_status":         GetBuildStatusHandler,
+}
+
+// sent by an instance serving 'connect' for a plan that's active on another instance
+const connectStateControlMethod = "connect_state"
+
+func init() {
+	streambus.RegisterControlHandler(serveActivePlanControl)
+}
+
+func serveActivePlanControl(req *streambus.ControlRequest) *streambus.ControlResponse {
+	active := modelPlan.GetActivePlan(req.PlanId, req.Branch)
+	if active == nil {
+		// not the owner
+		return nil
+	}
+
+	log.Printf("Handling %s control request from stream bus for plan %s\n", req.Method, req.PlanId)
+
+	if req.Method == connectStateControlMethod {
+		var stateReq connectStateRequest
+		if len(req.Body) > 0 {
+			err := json.Unmarshal(req.Body, &stateReq)
+			if err != nil {
+				log.Printf("Error unmarshalling connect state request: %v\n", err)
+				return &streambus.ControlResponse{Status: http.StatusBadRequest, Body: []byte("Error parsing connect state request")}
+			}
+		}
+
+		state, err := getConnectState(active.OrgId, req.PlanId, req.Branch, stateReq.ResumeFrom)
+		if err != nil {
+			log.Printf("Error getting connect state: %v\n", err)
+			return &streambus.ControlResponse{Status: http.StatusInternalServerError, Body: []byte("Error getting connect state")}
+		}
+
+		bytes, err := json.Marshal(state)
+		if err != nil {
+			log.Printf("Error marshalling connect state: %v\n", err)
+			return &streambus.ControlResponse{Status: http.StatusInternalServerError, Body: []byte("Error marshalling connect state")}
+		}
+
+		return &streambus.ControlResponse{Status: http.StatusOK, Body: bytes}
+	}
+
+	handler, ok := activePlanControlHandlers[req.Method]
+	if !ok {
+		return &streambus.ControlResponse{Status: http.StatusNotFound, Body: []byte("Unknown method")}
+	}
+
+	auth, err := resolveForwardedAuth(req)
+	if err != nil {
+		log.Printf("Error resolving auth for %s control request: %v\n", req.Method, err)
+		return &streambus.ControlResponse{Status: http.StatusUnauthorized, Body: []byte("Error resolving auth for forwarded request")}
+	}
+
+	url := fmt.Sprintf("/plans/%s/%s/%s?proxy=true", req.PlanId, req.Branch, req.Method)
+	r := httptest.NewRequest(req.HttpMethod, url, bytes.NewReader(req.Body))
+	r.Header.Set("Content-Type", "application/json")
+	r = r.WithContext(context.WithValue(r.Context(), forwardedAuthContextKey{}, auth))
+	r = mux.SetURLVars(r, map[string]string{"planId": req.PlanId, "branch": req.Branch})
+
+	rec := httptest.NewRecorder()
+	handler(rec, r)
+
+	return &streambus.ControlResponse{
+		Status: rec.Code,
+		Header: rec.Header(),
+		Body:   rec.Body.Bytes(),
+	}
+}
+
+// forwardedAuthContextKey holds the auth for a control request forwarded over the stream bus—see execAuthenticate
+type forwardedAuthContextKey struct{}
+
+// resolveForwardedAuth rebuilds the auth for a forwarded control request from its ids, re-checking org membership and permissions since the request didn't come with credentials
+func resolveForwardedAuth(req *streambus.ControlRequest) (*types.ServerAuth, error) {
+	if req.UserId == "" || req.OrgId == "" {
+		return nil, fmt.Errorf("missing user or org id")
+	}
+
+	user, err := db.GetUser(req.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %v", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	isMember, err := db.ValidateOrgMembership(req.UserId, req.OrgId)
+	if err != nil {
+		return nil, fmt.Errorf("error validating org membership: %v", err)
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of the org")
+	}
+
+	auth := &types.ServerAuth{User: user, OrgId: req.OrgId}
+
+	if req.ApiTokenId != "" {
+		apiToken, err := db.GetApiToken(req.OrgId, req.ApiTokenId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting api token: %v", err)
+		}
+		if apiToken == nil || apiToken.UserId != req.UserId {
+			return nil, fmt.Errorf("api token not found")
+		}
+		auth.ApiToken = apiToken
+	}
+
+	permissions, err := db.GetUserPermissions(req.UserId, req.OrgId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user permissions: %v", err)
+	}
+
+	auth.Permissions = make(shared.Permissions)
+	for _, permission := range permissions {
+		// tokens only get the non-admin permissions of their user's role
+		if auth.ApiToken != nil && !shared.IsApiTokenPermission(shared.Permission(strings.Split(permission, "|")[0])) {
+			continue
+		}
+		auth.Permissions[permission] = true
+	}
+
+	return auth, nil
+}
@@ -18,6 +18,10 @@ import (
 func CreateEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for CreateEmailVerificationHandler")
 
+	if emailAuthDisabled(w) {
+		return
+	}
+
 	// read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -227,6 +231,11 @@ func SignInHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// sign in codes come from an existing session, so they're allowed even when SSO is required
+	if !req.IsSignInCode && emailAuthDisabled(w) {
+		return
+	}
+
 	log.Println("Validating and signing in")
 	resp, err := ValidateAndSignIn(w, r, req)
 
@@ -257,6 +266,10 @@ func SignOutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
 	_, err := db.Conn.Exec("UPDATE auth_tokens SET deleted_at = NOW() WHERE token_hash = $1", auth.AuthToken.TokenHash)
 
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"plandex-server/db"
 	"reflect"
 
@@ -90,7 +91,7 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanWrite(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -105,6 +106,11 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if os.Getenv("IS_CLOUD") != "" && req.Settings != nil && req.Settings.ModelPack != nil && req.Settings.ModelPack.UsesSelfHostedOnlyProvider() {
+		http.Error(w, "Custom and local model providers are not supported on Plandex Cloud", http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithCancel(r.Context())
 
 	var commitMsg string
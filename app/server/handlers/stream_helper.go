@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/streambus"
 	"plandex-server/types"
 	"time"
 
@@ -16,7 +18,8 @@ import (
 
 const HeartbeatInterval = 5 * time.Second
 
-func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *types.ServerAuth, planId, branch string, isConnect bool) {
+// resumeFrom is the seq of the last message a reconnecting client received, or 0 to start from the current state
+func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *types.ServerAuth, planId, branch string, isConnect bool, resumeFrom int64) {
 	log.Println("Response stream manager: starting plan stream")
 
 	active := modelPlan.GetActivePlan(planId, branch)
@@ -49,17 +52,42 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 		return
 	}
 
-	if isConnect {
-		time.Sleep(100 * time.Millisecond)
-		err = initConnectActive(auth, planId, branch, w)
+	var subscriptionId string
+	var ch chan string
+
+	// messages up to this seq were already replayed--skip them if they also come through the subscription
+	var replayedUpTo int64
+
+	if isConnect && resumeFrom > 0 {
+		// subscribe before reading the replay buffer so nothing sent in between is missed
+		subscriptionId, ch = modelPlan.SubscribePlan(reqCtx, planId, branch)
+
+		state, err := getConnectState(auth.OrgId, planId, branch, resumeFrom)
+		if err == nil {
+			err = sendConnectActiveMessages(w, state.Messages)
+		}
 
 		if err != nil {
-			log.Println("Response stream manager: error initializing connection to active plan:", err)
+			log.Println("Response stream manager: error resuming connection to active plan:", err)
+			modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
 			return
 		}
+
+		replayedUpTo = state.ResumedUpTo
+	} else {
+		if isConnect {
+			time.Sleep(100 * time.Millisecond)
+			err = initConnectActive(auth, planId, branch, w)
+
+			if err != nil {
+				log.Println("Response stream manager: error initializing connection to active plan:", err)
+				return
+			}
+		}
+
+		subscriptionId, ch = modelPlan.SubscribePlan(reqCtx, planId, branch)
 	}
 
-	subscriptionId, ch := modelPlan.SubscribePlan(reqCtx, planId, branch)
 	defer func() {
 		log.Println("Response stream manager: client stream closed")
 		modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
@@ -99,6 +127,14 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 				return
 			}
 		case msg := <-ch:
+			if replayedUpTo > 0 {
+				seq := streamMessageSeq(msg)
+				if seq <= replayedUpTo {
+					continue
+				}
+				replayedUpTo = 0
+			}
+
 			// log.Println("Response stream manager: sending message:", msg)
 			err = sendStreamMessage(w, msg)
 			if err != nil {
@@ -109,6 +145,44 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 
 }
 
+func streamMessageSeq(msg string) int64 {
+	var seqOnly struct {
+		Seq int64 `json:"seq"`
+	}
+	json.Unmarshal([]byte(msg), &seqOnly)
+	return seqOnly.Seq
+}
+
+type connectState struct {
+	Messages []string `json:"messages"`
+	// set when Messages resume the stream from the requested seq rather than re-sending the full state--the latest seq they cover
+	ResumedUpTo int64 `json:"resumedUpTo,omitempty"`
+}
+
+func getConnectState(orgId, planId, branch string, resumeFrom int64) (*connectState, error) {
+	if resumeFrom > 0 {
+		active := modelPlan.GetActivePlan(planId, branch)
+		if active == nil {
+			return nil, fmt.Errorf("active plan not found for plan ID %s on branch %s", planId, branch)
+		}
+
+		msgs, upTo, ok := active.StreamMessagesSince(resumeFrom)
+		if ok {
+			log.Printf("Response stream manager: resuming stream from seq %d with %d messages\n", resumeFrom, len(msgs))
+			return &connectState{Messages: msgs, ResumedUpTo: upTo}, nil
+		}
+
+		log.Printf("Response stream manager: can't resume stream from seq %d--sending full state\n", resumeFrom)
+	}
+
+	msgs, err := getConnectActiveMessages(orgId, planId, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connectState{Messages: msgs}, nil
+}
+
 func sendStreamMessage(w http.ResponseWriter, msg string) error {
 	bytes := []byte(msg + shared.STREAM_MESSAGE_SEPARATOR)
 
@@ -127,10 +201,35 @@ func sendStreamMessage(w http.ResponseWriter, msg string) error {
 func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.ResponseWriter) error {
 	log.Println("Response stream manager: initializing connection to active plan")
 
+	msgs, err := getConnectActiveMessages(auth.OrgId, planId, branch)
+	if err != nil {
+		return err
+	}
+
+	return sendConnectActiveMessages(w, msgs)
+}
+
+func sendConnectActiveMessages(w http.ResponseWriter, msgs []string) error {
+	for i, msg := range msgs {
+		if i == 0 {
+			log.Println("Response stream manager: sending connect message")
+		}
+
+		err := sendStreamMessage(w, msg)
+		if err != nil {
+			return fmt.Errorf("error sending message: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// getConnectActiveMessages builds the messages that catch a newly connected client up on an active plan
+func getConnectActiveMessages(orgId, planId, branch string) ([]string, error) {
 	active := modelPlan.GetActivePlan(planId, branch)
 
 	if active == nil {
-		return fmt.Errorf("active plan not found for plan ID %s on branch %s", planId, branch)
+		return nil, fmt.Errorf("active plan not found for plan ID %s on branch %s", planId, branch)
 	}
 
 	msg := shared.StreamMessage{
@@ -146,9 +245,9 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 	}
 
 	if len(active.StoredReplyIds) > 0 {
-		convo, err := db.GetPlanConvo(auth.OrgId, active.Id)
+		convo, err := db.GetPlanConvo(orgId, active.Id)
 		if err != nil {
-			return fmt.Errorf("error getting plan convo: %v", err)
+			return nil, fmt.Errorf("error getting plan convo: %v", err)
 		}
 
 		convoMsgById := map[string]*db.ConvoMessage{}
@@ -174,17 +273,12 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 	bytes, err := json.Marshal(msg)
 
 	if err != nil {
-		return fmt.Errorf("error marshalling message: %v", err)
+		return nil, fmt.Errorf("error marshalling message: %v", err)
 	}
 
-	log.Println("Response stream manager: sending connect message")
-	err = sendStreamMessage(w, string(bytes))
+	msgs := []string{string(bytes)}
 
-	if err != nil {
-		return fmt.Errorf("error sending connect message: %v", err)
-	}
-
-	buildQueuesByPath := modelPlan.GetActivePlan(planId, branch).BuildQueuesByPath
+	buildQueuesByPath := active.BuildQueuesByPath
 
 	// if we're connecting to an active stream and there are active builds, send initial build info
 	if len(buildQueuesByPath) > 0 {
@@ -211,18 +305,263 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 			bytes, err := json.Marshal(msg)
 
 			if err != nil {
-				return fmt.Errorf("error marshalling message: %v", err)
+				return nil, fmt.Errorf("error marshalling message: %v", err)
 			}
 
-			err = sendStreamMessage(w, string(bytes))
+			msgs = append(msgs, string(bytes))
+		}
+
+	}
+
+	return msgs, nil
+}
+
+// startBusResponseStream serves 'connect' for a plan that's active on another server instance, relaying its stream from the bus
+func startBusResponseStream(reqCtx context.Context, w http.ResponseWriter, planId, branch string, resumeFrom int64) {
+	log.Println("Response stream manager: starting plan stream from stream bus")
+
+	modelStream, err := db.GetActiveModelStream(planId, branch)
+	if err != nil {
+		log.Printf("Response stream manager: error getting active model stream: %v\n", err)
+		http.Error(w, "Error getting active model stream", http.StatusInternalServerError)
+		return
+	}
+
+	if modelStream == nil {
+		log.Printf("Response stream manager: no active model stream for plan ID %s on branch %s\n", planId, branch)
+		http.Error(w, "Active plan not found", http.StatusNotFound)
+		return
+	}
+
+	if modelStream.Orphaned() {
+		// no instance would answer the connect state request
+		http.Error(w, orphanedStreamErrMsg, http.StatusServiceUnavailable)
+		return
+	}
+
+	// subscribe before requesting the connect state so nothing streamed in between is missed
+	ch, unsubscribe := streambus.Current.Subscribe(streambus.StreamChannel(planId, branch))
+	defer func() {
+		log.Println("Response stream manager: client stream closed")
+		unsubscribe()
+	}()
+
+	reqBody, err := json.Marshal(connectStateRequest{ResumeFrom: resumeFrom})
+	if err != nil {
+		log.Printf("Response stream manager: error marshalling connect state request: %v\n", err)
+		http.Error(w, "Error connecting to active plan", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(reqCtx, proxyTimeout)
+	res, err := streambus.Request(ctx, streambus.ControlRequest{
+		PlanId: planId,
+		Branch: branch,
+		Method: connectStateControlMethod,
+		Body:   reqBody,
+	})
+	cancel()
+
+	if err != nil {
+		log.Printf("Response stream manager: error getting connect state over stream bus: %v\n", err)
+		http.Error(w, "Error connecting to active plan", http.StatusInternalServerError)
+		return
+	}
+
+	if res.Status != http.StatusOK {
+		log.Printf("Response stream manager: connect state request failed with status %d: %s\n", res.Status, string(res.Body))
+		http.Error(w, string(res.Body), res.Status)
+		return
+	}
+
+	var state connectState
+	err = json.Unmarshal(res.Body, &state)
+	if err != nil {
+		log.Printf("Response stream manager: error unmarshalling connect state: %v\n", err)
+		http.Error(w, "Error connecting to active plan", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	bytes, err := json.Marshal(shared.StreamMessage{
+		Type: shared.StreamMessageStart,
+	})
+
+	if err != nil {
+		log.Printf("Response stream manager: error marshalling message: %v\n", err)
+		return
+	}
+
+	err = sendStreamMessage(w, string(bytes))
+	if err != nil {
+		log.Println("Response stream manager: error sending initial message:", err)
+		return
+	}
+
+	err = sendConnectActiveMessages(w, state.Messages)
+	if err != nil {
+		log.Println("Response stream manager: error initializing connection to active plan:", err)
+		return
+	}
+
+	heartbeatTicker := time.NewTicker(HeartbeatInterval)
+	defer heartbeatTicker.Stop()
 
+	// the owning instance may go away without finishing the stream--check its model stream heartbeat
+	liveness := time.NewTicker(busStreamLivenessInterval)
+	defer liveness.Stop()
+
+	for {
+		select {
+		case <-reqCtx.Done():
+			log.Println("Response stream manager: request context done")
+			return
+		case <-heartbeatTicker.C:
+			err = sendStreamMessage(w, string(shared.StreamMessageHeartbeat))
 			if err != nil {
-				return fmt.Errorf("error sending message: %v", err)
+				return
+			}
+		case <-liveness.C:
+			modelStream, err := db.GetActiveModelStream(planId, branch)
+			if err != nil {
+				log.Printf("Response stream manager: error checking active model stream: %v\n", err)
+				continue
+			}
+			if modelStream == nil {
+				log.Println("Response stream manager: model stream is no longer active")
+				sendBusStreamEndedMessage(w)
+				return
+			}
+			if modelStream.Orphaned() {
+				// closing the connection makes the client reconnect, which resumes the stream on whichever instance it reaches
+				log.Println("Response stream manager: model stream's instance stopped--closing so the client reconnects")
+				return
+			}
+		case msg, ok := <-ch:
+			if !ok {
+				// this connection fell too far behind the stream--the client reconnects and resumes from its last message
+				log.Println("Response stream manager: stream bus subscription disconnected")
+				return
+			}
+			if state.ResumedUpTo > 0 {
+				seq := streamMessageSeq(msg)
+				if seq <= state.ResumedUpTo {
+					continue
+				}
+				state.ResumedUpTo = 0
 			}
 
+			err = sendStreamMessage(w, msg)
+			if err != nil {
+				return
+			}
+
+			var streamMsg shared.StreamMessage
+			if json.Unmarshal([]byte(msg), &streamMsg) == nil && isTerminalStreamMessage(streamMsg.Type) {
+				// the client closes the connection itself--just stop watching for liveness
+				liveness.Stop()
+			}
 		}
+	}
+}
+
+const busStreamLivenessInterval = 5 * time.Second
 
+type connectStateRequest struct {
+	ResumeFrom int64 `json:"resumeFrom,omitempty"`
+}
+
+func isTerminalStreamMessage(msgType shared.StreamMessageType) bool {
+	return msgType == shared.StreamMessageFinished || msgType == shared.StreamMessageError || msgType == shared.StreamMessageAborted
+}
+
+func sendBusStreamEndedMessage(w http.ResponseWriter) {
+	bytes, err := json.Marshal(shared.StreamMessage{
+		Type: shared.StreamMessageError,
+		Error: &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "The plan stream ended unexpectedly",
+		},
+	})
+
+	if err != nil {
+		log.Printf("Response stream manager: error marshalling message: %v\n", err)
+		return
 	}
 
-	return nil
+	sendStreamMessage(w, string(bytes))
+}
+
+// tryResumeOrphanedStream takes over the plan's stream if the instance running it stopped, when the client reconnecting is the stream's own user, and serves the connection from here. Returns false if there's nothing to resume here and the connection should be relayed as usual.
+func tryResumeOrphanedStream(w http.ResponseWriter, r *http.Request, planId, branch string) bool {
+	modelStream, err := db.GetActiveModelStream(planId, branch)
+	if err != nil {
+		log.Printf("Response stream manager: error getting active model stream: %v\n", err)
+		return false
+	}
+
+	if modelStream == nil || !modelStream.Orphaned() || modelStream.ResumeRequest == nil {
+		return false
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return true
+	}
+
+	if modelStream.UserId == nil || *modelStream.UserId != auth.User.Id {
+		return false
+	}
+
+	plan := authorizePlanExecUpdate(w, planId, auth)
+	if plan == nil {
+		return true
+	}
+
+	// only a client that's reconnecting its own tell sends credentials--'plandex connect' and older clients don't, so they wait for the stream to be resumed or time out
+	var req shared.ConnectPlanRequest
+	if r.Body == nil {
+		return false
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err == io.EOF {
+		return false
+	} else if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return true
+	}
+
+	clients := initClients(
+		initClientsParams{
+			w:           w,
+			auth:        auth,
+			apiKeys:     req.ApiKeys,
+			openAIBase:  req.OpenAIBase,
+			openAIOrgId: req.OpenAIOrgId,
+			plan:        plan,
+		},
+	)
+	if clients == nil {
+		return true
+	}
+
+	log.Printf("Response stream manager: resuming orphaned stream for plan ID %s on branch %s\n", planId, branch)
+
+	err = modelPlan.ResumeOrphanedStream(clients, plan, branch, auth, modelStream)
+	if err == db.ErrModelStreamNotOrphaned {
+		log.Println("Response stream manager: stream was resumed elsewhere")
+		return false
+	} else if err != nil {
+		log.Printf("Response stream manager: error resuming orphaned stream: %v\n", err)
+		http.Error(w, "Error resuming plan stream", http.StatusInternalServerError)
+		return true
+	}
+
+	// the resumed plan's messages don't follow on from the client's last seq, so it gets the full current state
+	startResponseStream(r.Context(), w, auth, planId, branch, true, 0)
+	return true
 }
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const maxUsageLogPageSize = 500
+
+func GetUsageSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetUsageSummaryHandler")
+
+	if !checkUsageLedgerSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.UsageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, monthStart := usageFilterForRequest(auth, req)
+
+	res, err := db.GetUsageSummary(filter)
+	if err != nil {
+		log.Printf("Error getting usage summary: %v\n", err)
+		http.Error(w, "Error getting usage summary: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.MonthStart = monthStart
+
+	planIds := []string{}
+	for planId := range res.ByPlanId {
+		planIds = append(planIds, planId)
+	}
+
+	res.PlanNamesById, err = getUsagePlanNames(planIds)
+	if err != nil {
+		log.Printf("Error getting plan names: %v\n", err)
+		http.Error(w, "Error getting plan names: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling usage summary: %v\n", err)
+		http.Error(w, "Error marshalling usage summary: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got usage summary")
+}
+
+func GetUsageLogHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetUsageLogHandler")
+
+	if !checkUsageLedgerSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 100
+	}
+	if pageSize > maxUsageLogPageSize {
+		pageSize = maxUsageLogPageSize
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	var req shared.UsageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, monthStart := usageFilterForRequest(auth, req)
+
+	usage, count, err := db.ListUsage(filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Printf("Error listing usage: %v\n", err)
+		http.Error(w, "Error listing usage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.UsageLogResponse{
+		Entries:    []*shared.UsageEntry{},
+		NumPages:   (count + pageSize - 1) / pageSize,
+		MonthStart: monthStart,
+	}
+
+	planIds := []string{}
+	for _, u := range usage {
+		res.Entries = append(res.Entries, u.ToApi())
+		if u.PlanId != nil {
+			planIds = append(planIds, *u.PlanId)
+		}
+	}
+
+	res.PlanNamesById, err = getUsagePlanNames(planIds)
+	if err != nil {
+		log.Printf("Error getting plan names: %v\n", err)
+		http.Error(w, "Error getting plan names: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling usage log: %v\n", err)
+		http.Error(w, "Error marshalling usage log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got usage log")
+}
+
+// usageFilterForRequest scopes a usage request to the org—users who can't manage billing only see their own usage
+func usageFilterForRequest(auth *types.ServerAuth, req shared.UsageRequest) (db.UsageFilter, time.Time) {
+	now := time.Now().UTC()
+	monthStart := shared.SpendBudgetPeriodMonthly.Start(now)
+
+	filter := db.UsageFilter{
+		OrgId:     auth.OrgId,
+		PlanId:    req.PlanId,
+		SessionId: req.SessionId,
+	}
+
+	if !auth.HasPermission(shared.PermissionManageBilling) {
+		filter.UserId = auth.User.Id
+	}
+
+	if req.DayStart != nil {
+		filter.Since = req.DayStart
+	} else if req.Month {
+		filter.Since = &monthStart
+	}
+
+	return filter, monthStart
+}
+
+func getUsagePlanNames(planIds []string) (map[string]string, error) {
+	if len(planIds) == 0 {
+		return map[string]string{}, nil
+	}
+	return db.GetPlanNamesById(planIds)
+}
+
+func checkUsageLedgerSupported(w http.ResponseWriter) bool {
+	if os.Getenv("IS_CLOUD") != "" {
+		http.Error(w, "The usage ledger is only available when self-hosting", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -221,3 +221,118 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully processed request for DeleteOrgUserHandler")
 }
+
+func SetOrgUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetOrgUserRoleHandler")
+
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for user management",
+		})
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't set user roles",
+		})
+		return
+	}
+
+	userId := mux.Vars(r)["userId"]
+
+	var req shared.SetOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	orgUser, err := db.GetOrgUser(userId, auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser == nil {
+		http.Error(w, "User "+userId+" is not a member of org "+auth.OrgId, http.StatusNotFound)
+		return
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role == nil {
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusNotFound)
+		return
+	}
+
+	// ensure current user can change both the target user's current role and the new one
+	if !auth.HasPermissionForResource(shared.PermissionSetUserRole, orgUser.OrgRoleId) ||
+		!auth.HasPermissionForResource(shared.PermissionSetUserRole, role.Id) {
+		log.Printf("User does not have permission to change role %v to %v\n", orgUser.OrgRoleId, role.Id)
+		http.Error(w, "User does not have permission to change role "+orgUser.OrgRoleId+" to "+role.Id, http.StatusForbidden)
+		return
+	}
+
+	if orgUser.OrgRoleId == role.Id {
+		log.Println("User already has role")
+		return
+	}
+
+	orgOwnerRoleId, err := db.GetOrgOwnerRoleId()
+	if err != nil {
+		log.Printf("Error getting org owner role id: %v\n", err)
+		http.Error(w, "Error getting org owner role id: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// verify user isn't the only org owner
+	if orgUser.OrgRoleId == orgOwnerRoleId {
+		numOwners, err := db.NumUsersWithRole(auth.OrgId, orgOwnerRoleId)
+		if err != nil {
+			log.Printf("Error getting number of org owners: %v\n", err)
+			http.Error(w, "Error getting number of org owners: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if numOwners == 1 {
+			log.Println("Cannot change the role of the only org owner")
+			http.Error(w, "Cannot change the role of the only org owner", http.StatusForbidden)
+			return
+		}
+	}
+
+	err = db.WithTx(r.Context(), "set org user role", func(tx *sqlx.Tx) error {
+		return db.SetOrgUserRole(auth.OrgId, userId, role.Id, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error setting org user role: %v\n", err)
+		http.Error(w, "Error setting org user role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully set org user role")
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"plandex-server/db"
+	"plandex-server/webhooks"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const webhookDeliveriesLimit = 50
+
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhooksHandler")
+
+	if !checkWebhooksSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	dbWebhooks, err := db.ListWebhooks(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v\n", err)
+		http.Error(w, "Error listing webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := []*shared.Webhook{}
+	for _, webhook := range dbWebhooks {
+		res = append(res, webhook.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling webhooks: %v\n", err)
+		http.Error(w, "Error marshalling webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed webhooks")
+}
+
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateWebhookHandler")
+
+	if !checkWebhooksSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	parsedUrl, err := url.Parse(req.Url)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		http.Error(w, "Webhook url must be an absolute http or https url", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Events) == 0 {
+		http.Error(w, "Webhook needs at least one event", http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range req.Events {
+		if !shared.IsValidWebhookEvent(event) {
+			http.Error(w, "Invalid webhook event: "+string(event), http.StatusBadRequest)
+			return
+		}
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		log.Printf("Error creating webhook secret: %v\n", err)
+		http.Error(w, "Error creating webhook secret: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	webhook := &db.Webhook{
+		OrgId:     auth.OrgId,
+		Url:       req.Url,
+		Secret:    secret,
+		Events:    req.Events,
+		CreatedBy: &auth.User.Id,
+	}
+
+	err = db.CreateWebhook(webhook)
+	if err != nil {
+		log.Printf("Error creating webhook: %v\n", err)
+		http.Error(w, "Error creating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.CreateWebhookResponse{
+		Webhook: webhook.ToApi(),
+		Secret:  secret,
+	})
+	if err != nil {
+		log.Printf("Error marshalling webhook: %v\n", err)
+		http.Error(w, "Error marshalling webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created webhook")
+}
+
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteWebhookHandler")
+
+	if !checkWebhooksSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	err := db.DeleteWebhook(auth.OrgId, webhookId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error deleting webhook: %v\n", err)
+		http.Error(w, "Error deleting webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Println("Successfully deleted webhook")
+}
+
+func TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for TestWebhookHandler")
+
+	if !checkWebhooksSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	statusCode, err := webhooks.SendPing(webhook, auth.User.Id)
+	if err != nil {
+		log.Printf("Error sending test webhook: %v\n", err)
+		if statusCode != 0 {
+			http.Error(w, fmt.Sprintf("Test delivery failed: received status %d", statusCode), http.StatusBadGateway)
+		} else {
+			http.Error(w, "Test delivery failed: "+err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	log.Printf("Successfully sent test webhook--received status %d\n", statusCode)
+}
+
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhookDeliveriesHandler")
+
+	if !checkWebhooksSupported(w) {
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(auth.OrgId, webhook.Id, webhookDeliveriesLimit)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v\n", err)
+		http.Error(w, "Error listing webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListWebhookDeliveriesResponse{Deliveries: []*shared.WebhookDelivery{}}
+	for _, delivery := range deliveries {
+		res.Deliveries = append(res.Deliveries, delivery.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling webhook deliveries: %v\n", err)
+		http.Error(w, "Error marshalling webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed webhook deliveries")
+}
+
+func checkWebhooksSupported(w http.ResponseWriter) bool {
+	if !webhooks.Enabled() {
+		http.Error(w, "Webhooks are only supported when self-hosting", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func getWebhookForRequest(w http.ResponseWriter, r *http.Request, orgId string) *db.Webhook {
+	webhookId := mux.Vars(r)["webhookId"]
+
+	webhook, err := db.GetWebhook(orgId, webhookId)
+	if err != nil {
+		log.Printf("Error getting webhook: %v\n", err)
+		http.Error(w, "Error getting webhook: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if webhook == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil
+	}
+
+	return webhook
+}
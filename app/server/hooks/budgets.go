@@ -0,0 +1,130 @@
+package hooks
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+// Default WillSendModelRequest hook for self-hosted servers: spend recorded in the usage ledger (see usage.go) is checked against the org's spend budgets. Plandex Cloud registers its own hooks in place of these.
+
+func init() {
+	RegisterHook(WillSendModelRequest, checkSpendBudgets)
+}
+
+type budgetStatus struct {
+	Budget   *db.SpendBudget
+	Spend    decimal.Decimal
+	Estimate decimal.Decimal
+}
+
+func (s budgetStatus) exceeded() bool {
+	return s.Spend.Add(s.Estimate).GreaterThan(s.Budget.LimitUsd)
+}
+
+func (s budgetStatus) nearLimit() bool {
+	if s.Budget.WarnPct <= 0 {
+		return false
+	}
+	threshold := s.Budget.LimitUsd.Mul(decimal.NewFromInt(int64(s.Budget.WarnPct))).Div(decimal.NewFromInt(100))
+	return s.Spend.Add(s.Estimate).GreaterThanOrEqual(threshold)
+}
+
+func (s budgetStatus) label() string {
+	var target string
+	switch s.Budget.Scope {
+	case shared.SpendBudgetScopeOrg:
+		target = "org"
+	case shared.SpendBudgetScopePlan:
+		target = "plan"
+	case shared.SpendBudgetScopeUser:
+		target = "user"
+	}
+	return fmt.Sprintf("%s %s spend budget", s.Budget.Period, target)
+}
+
+func checkSpendBudgets(params HookParams) (HookResult, *shared.ApiError) {
+	if params.Auth == nil || params.WillSendModelRequestParams == nil {
+		return HookResult{}, nil
+	}
+
+	reqParams := params.WillSendModelRequestParams
+
+	// models without pricing (like local models) are free
+	pricing, _ := shared.GetModelPricing(reqParams.ModelName)
+
+	var planId string
+	if params.Plan != nil {
+		planId = params.Plan.Id
+	}
+	userId := params.Auth.User.Id
+
+	budgets, err := db.GetSpendBudgetsForRequest(params.Auth.OrgId, userId, planId)
+	if err != nil {
+		log.Printf("Error getting spend budgets: %v\n", err)
+		return HookResult{}, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error getting spend budgets",
+		}
+	}
+
+	if len(budgets) == 0 {
+		return HookResult{}, nil
+	}
+
+	// worst case for this request—the same estimate Plandex Cloud uses for credit checks
+	estimate := pricing.Cost(reqParams.InputTokens, 0, reqParams.OutputTokens)
+
+	now := time.Now()
+	warnings := []string{}
+	for _, budget := range budgets {
+		if budget.Scope == shared.SpendBudgetScopePlan && planId == "" && budget.PlanId == nil {
+			continue
+		}
+
+		spend, err := db.GetSpendForBudget(budget, userId, planId, now)
+		if err != nil {
+			log.Printf("Error getting spend for budget %s: %v\n", budget.Id, err)
+			return HookResult{}, &shared.ApiError{
+				Type:   shared.ApiErrorTypeOther,
+				Status: http.StatusInternalServerError,
+				Msg:    "Error getting spend for budget",
+			}
+		}
+
+		status := budgetStatus{Budget: budget, Spend: spend, Estimate: estimate}
+
+		if status.exceeded() {
+			return HookResult{}, &shared.ApiError{
+				Type:   shared.ApiErrorTypeSpendBudgetExceeded,
+				Status: http.StatusForbidden,
+				Msg: fmt.Sprintf(
+					"This request would exceed the %s of $%s (spent $%s, this request could cost up to $%s). An org owner can raise it with 'plandex budgets set'.",
+					status.label(), budget.LimitUsd.StringFixed(2), spend.StringFixed(2), estimate.StringFixed(2),
+				),
+			}
+		}
+
+		if status.nearLimit() {
+			warnings = append(warnings, fmt.Sprintf("$%s of $%s %s used", spend.StringFixed(2), budget.LimitUsd.StringFixed(2), status.label()))
+		}
+	}
+
+	if len(warnings) == 0 {
+		return HookResult{}, nil
+	}
+
+	return HookResult{
+		WillSendModelRequestResult: &WillSendModelRequestResult{
+			Warning: "Approaching spend limit: " + strings.Join(warnings, ", "),
+		},
+	}, nil
+}
@@ -0,0 +1,49 @@
+package hooks
+
+import (
+	"plandex-server/db"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	budget := &db.SpendBudget{
+		Scope:    shared.SpendBudgetScopeOrg,
+		Period:   shared.SpendBudgetPeriodDaily,
+		LimitUsd: decimal.NewFromInt(10),
+		WarnPct:  80,
+	}
+
+	tests := []struct {
+		name          string
+		spend         string
+		estimate      string
+		wantExceeded  bool
+		wantNearLimit bool
+	}{
+		{"under", "2", "1", false, false},
+		{"near", "7.5", "0.5", false, true},
+		{"exactly at limit", "9", "1", false, true},
+		{"would exceed", "9.5", "1", true, true},
+		{"already over", "12", "0", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := budgetStatus{
+				Budget:   budget,
+				Spend:    decimal.RequireFromString(tt.spend),
+				Estimate: decimal.RequireFromString(tt.estimate),
+			}
+			if status.exceeded() != tt.wantExceeded {
+				t.Errorf("exceeded = %v, want %v", status.exceeded(), tt.wantExceeded)
+			}
+			if status.nearLimit() != tt.wantNearLimit {
+				t.Errorf("nearLimit = %v, want %v", status.nearLimit(), tt.wantNearLimit)
+			}
+		})
+	}
+}
@@ -40,6 +40,7 @@ type DidSendModelRequestParams struct {
 	InputTokens     int
 	OutputTokens    int
 	CachedTokens    int
+	ReasoningTokens int
 	ModelId         shared.ModelId
 	ModelName       shared.ModelName
 	ModelProvider   shared.ModelProvider
@@ -110,6 +111,9 @@ type DidFinishBuilderRunParams struct {
 	BuildWholeFileStartedAt  time.Time
 	BuildWholeFileFinishedAt time.Time
 
+	// errors reported by the CLI's language server or compiler command, across all fix attempts
+	DiagnosticsErrors []string
+
 	StartedAt  time.Time
 	FinishedAt time.Time
 }
@@ -154,14 +158,20 @@ type GetIntegratedModelsResult struct {
 	ApiKeys              map[string]string
 }
 
+type WillSendModelRequestResult struct {
+	// shown in the plan stream when a spend budget is nearly used up
+	Warning string
+}
+
 type FastApplyResult struct {
 	MergedCode string
 }
 
 type HookResult struct {
-	GetIntegratedModelsResult *GetIntegratedModelsResult
-	ApiOrgsById               map[string]*shared.Org
-	FastApplyResult           *FastApplyResult
+	GetIntegratedModelsResult  *GetIntegratedModelsResult
+	WillSendModelRequestResult *WillSendModelRequestResult
+	ApiOrgsById                map[string]*shared.Org
+	FastApplyResult            *FastApplyResult
 }
 
 type Hook func(params HookParams) (HookResult, *shared.ApiError)
@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"log"
+	"net/http"
+	"plandex-server/db"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+// Default DidSendModelRequest hook for self-hosted servers: every model request is written to the usage ledger, which backs 'plandex usage' and spend budgets.
+
+func init() {
+	RegisterHook(DidSendModelRequest, recordModelUsage)
+}
+
+func recordModelUsage(params HookParams) (HookResult, *shared.ApiError) {
+	if params.Auth == nil || params.DidSendModelRequestParams == nil {
+		return HookResult{}, nil
+	}
+
+	usage := modelUsageFromParams(params)
+
+	err := db.CreateModelUsage(usage)
+	if err != nil {
+		log.Printf("Error recording model usage: %v\n", err)
+		return HookResult{}, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error recording model usage",
+		}
+	}
+
+	return HookResult{}, nil
+}
+
+func modelUsageFromParams(params HookParams) *db.ModelUsage {
+	reqParams := params.DidSendModelRequestParams
+
+	var cost, cacheSavings decimal.Decimal
+	pricing, ok := shared.GetModelPricing(reqParams.ModelName)
+	if ok {
+		cost = pricing.Cost(reqParams.InputTokens, reqParams.CachedTokens, reqParams.OutputTokens)
+		cacheSavings = pricing.Cost(reqParams.InputTokens, 0, reqParams.OutputTokens).Sub(cost)
+	}
+
+	userId := params.Auth.User.Id
+	usage := &db.ModelUsage{
+		OrgId:           params.Auth.OrgId,
+		UserId:          &userId,
+		ModelProvider:   reqParams.ModelProvider,
+		ModelName:       reqParams.ModelName,
+		InputTokens:     reqParams.InputTokens,
+		OutputTokens:    reqParams.OutputTokens,
+		CachedTokens:    reqParams.CachedTokens,
+		ReasoningTokens: reqParams.ReasoningTokens,
+		Cost:            cost,
+		CacheSavings:    cacheSavings,
+		ModelId:         optionalString(string(reqParams.ModelId)),
+		ModelRole:       optionalString(string(reqParams.ModelRole)),
+		ModelPackName:   optionalString(reqParams.ModelPackName),
+		Purpose:         optionalString(reqParams.Purpose),
+		GenerationId:    optionalString(reqParams.GenerationId),
+		BuildId:         optionalString(reqParams.BuildId),
+		ConvoMessageId:  optionalString(reqParams.ConvoMessageId),
+		SessionId:       optionalString(reqParams.SessionId),
+		StoppedEarly:    reqParams.StoppedEarly,
+		UserCancelled:   reqParams.UserCancelled,
+		HadError:        reqParams.HadError,
+		NoReportedUsage: reqParams.NoReportedUsage,
+	}
+
+	if reqParams.PlanId != "" {
+		usage.PlanId = optionalString(reqParams.PlanId)
+	} else if params.Plan != nil {
+		usage.PlanId = &params.Plan.Id
+	}
+
+	return usage
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
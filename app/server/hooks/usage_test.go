@@ -0,0 +1,49 @@
+package hooks
+
+import (
+	"plandex-server/db"
+	"plandex-server/types"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestModelUsageFromParams(t *testing.T) {
+	params := HookParams{
+		Auth: &types.ServerAuth{OrgId: "org", User: &db.User{Id: "user"}},
+		Plan: &db.Plan{Id: "plan"},
+		DidSendModelRequestParams: &DidSendModelRequestParams{
+			InputTokens:   100000,
+			CachedTokens:  60000,
+			OutputTokens:  10000,
+			ModelProvider: shared.ModelProviderAnthropic,
+			ModelName:     "anthropic/claude-3.7-sonnet",
+			ModelRole:     shared.ModelRolePlanner,
+			Purpose:       "Response",
+			SessionId:     "session",
+		},
+	}
+
+	usage := modelUsageFromParams(params)
+
+	if !usage.Cost.Equal(decimal.RequireFromString("0.288")) {
+		t.Errorf("cost = %s", usage.Cost)
+	}
+	if !usage.CacheSavings.Equal(decimal.RequireFromString("0.162")) {
+		t.Errorf("cache savings = %s", usage.CacheSavings)
+	}
+	if usage.PlanId == nil || *usage.PlanId != "plan" {
+		t.Errorf("expected plan id from hook params")
+	}
+	if usage.ModelRole == nil || *usage.ModelRole != string(shared.ModelRolePlanner) {
+		t.Errorf("expected model role to be recorded")
+	}
+	if usage.BuildId != nil {
+		t.Errorf("empty build id should be stored as null")
+	}
+	if usage.ToApi().CacheSavings.String() != "0.162" {
+		t.Errorf("expected cache savings in api entry")
+	}
+}
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS spend_budgets (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  scope VARCHAR(16) NOT NULL,
+  plan_id UUID REFERENCES plans(id) ON DELETE CASCADE,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+  period VARCHAR(16) NOT NULL,
+  limit_usd NUMERIC(20, 4) NOT NULL,
+  warn_pct INTEGER NOT NULL DEFAULT 80,
+
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_spend_budgets_modtime BEFORE UPDATE ON spend_budgets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- one budget per target and period; a NULL plan_id/user_id is the default for the scope
+CREATE UNIQUE INDEX spend_budgets_target_idx ON spend_budgets(
+  org_id,
+  scope,
+  COALESCE(plan_id, '00000000-0000-0000-0000-000000000000'::uuid),
+  COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid),
+  period
+);
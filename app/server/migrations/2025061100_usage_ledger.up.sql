@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS model_usage (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  plan_id UUID REFERENCES plans(id) ON DELETE SET NULL,
+  build_id UUID,
+  convo_message_id UUID,
+  session_id VARCHAR(255),
+
+  model_provider VARCHAR(255) NOT NULL,
+  model_name VARCHAR(255) NOT NULL,
+  model_id VARCHAR(255),
+  model_role VARCHAR(255),
+  model_pack_name VARCHAR(255),
+  purpose VARCHAR(255),
+  generation_id VARCHAR(255),
+
+  input_tokens INTEGER NOT NULL,
+  output_tokens INTEGER NOT NULL,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+  reasoning_tokens INTEGER NOT NULL DEFAULT 0,
+  cost NUMERIC(20, 10) NOT NULL,
+  cache_savings NUMERIC(20, 10) NOT NULL DEFAULT 0,
+
+  stopped_early BOOLEAN NOT NULL DEFAULT FALSE,
+  user_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+  had_error BOOLEAN NOT NULL DEFAULT FALSE,
+  no_reported_usage BOOLEAN NOT NULL DEFAULT FALSE,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX model_usage_org_idx ON model_usage(org_id, created_at);
+CREATE INDEX model_usage_user_idx ON model_usage(user_id, created_at);
+CREATE INDEX model_usage_plan_idx ON model_usage(plan_id, created_at);
+CREATE INDEX model_usage_session_idx ON model_usage(session_id, created_at);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_exec_policy';
+
+DROP TABLE IF EXISTS org_exec_policies;
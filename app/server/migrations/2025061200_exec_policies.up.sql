@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS org_exec_policies (
+  org_id UUID PRIMARY KEY REFERENCES orgs(id) ON DELETE CASCADE,
+  policy JSON NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_org_exec_policies_modtime BEFORE UPDATE ON org_exec_policies FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_exec_policy', 'Manage an org''s command execution policy', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT 
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_exec_policy';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS semantic_chunks;
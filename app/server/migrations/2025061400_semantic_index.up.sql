@@ -0,0 +1,22 @@
+-- chunks of project files for ranking relevant code during auto context
+-- embeddings are filled in lazily for the plan's embedding model; terms are always stored for the local bm25 fallback
+CREATE TABLE IF NOT EXISTS semantic_chunks (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  path TEXT NOT NULL,
+  sha VARCHAR(64) NOT NULL,
+  name TEXT NOT NULL,
+  start_line INTEGER NOT NULL,
+  end_line INTEGER NOT NULL,
+  body TEXT NOT NULL,
+  terms TEXT NOT NULL,
+  embedding_model VARCHAR(255),
+  embedding REAL[],
+
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_semantic_chunks_modtime BEFORE UPDATE ON semantic_chunks FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX semantic_chunks_project_path_idx ON semantic_chunks(project_id, path);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS model_streams_active_idx;
+ALTER TABLE model_streams DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS plan_shares;
@@ -0,0 +1,28 @@
+-- plans shared with individual org members, read-only or as collaborators
+CREATE TABLE IF NOT EXISTS plan_shares (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  access VARCHAR(32) NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_plan_shares_modtime BEFORE UPDATE ON plan_shares FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX plan_shares_plan_user_idx ON plan_shares(plan_id, user_id);
+CREATE INDEX plan_shares_org_user_idx ON plan_shares(org_id, user_id);
+
+-- the user who started each stream, so a teammate who tries to tell the same plan branch can be told who's using it
+ALTER TABLE model_streams ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- model_streams_plan_idx includes finished_at, which is NULL for every active stream, so it never stopped two active streams on the same branch. Finish all but the newest active stream per branch, then enforce one.
+UPDATE model_streams SET finished_at = NOW()
+WHERE finished_at IS NULL AND id NOT IN (
+  SELECT DISTINCT ON (plan_id, branch) id FROM model_streams
+  WHERE finished_at IS NULL
+  ORDER BY plan_id, branch, created_at DESC
+);
+CREATE UNIQUE INDEX model_streams_active_idx ON model_streams(plan_id, branch) WHERE finished_at IS NULL;
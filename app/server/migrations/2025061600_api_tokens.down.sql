@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name = 'manage_service_accounts';
+
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS service_accounts;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_service_account;
@@ -0,0 +1,14 @@
+-- links users to their identity with an OIDC issuer, so sign in keeps working if their email changes
+CREATE TABLE IF NOT EXISTS oidc_identities (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  issuer VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_oidc_identities_modtime BEFORE UPDATE ON oidc_identities FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX oidc_identities_issuer_subject_idx ON oidc_identities(issuer, subject);
+CREATE INDEX oidc_identities_user_idx ON oidc_identities(user_id);
@@ -0,0 +1,11 @@
+-- custom roles go away, so their users and invites fall back to the member role
+UPDATE orgs_users SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+DELETE FROM permissions WHERE resource_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+DELETE FROM org_roles WHERE org_id IS NOT NULL;
+
+DELETE FROM permissions WHERE name IN ('manage_org_roles', 'apply_plan', 'exec_commands', 'manage_custom_models', 'manage_model_packs');
@@ -0,0 +1,29 @@
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_roles', 'Create, update and delete an org''s custom roles', NULL),
+  ('apply_plan', 'Apply a plan''s pending changes', NULL),
+  ('exec_commands', 'Run a plan''s commands after applying', NULL),
+  ('manage_custom_models', 'Add, update and delete an org''s custom models', NULL),
+  ('manage_model_packs', 'Create, update and delete an org''s model packs', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT 
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_org_roles';
+
+-- members could already do all of these, so they keep them
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT 
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin', 'member')
+    AND p.name IN ('apply_plan', 'exec_commands', 'manage_custom_models', 'manage_model_packs');
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS semantic_chunks_embedding_384_idx;
+DROP INDEX IF EXISTS semantic_chunks_embedding_768_idx;
+DROP INDEX IF EXISTS semantic_chunks_embedding_1024_idx;
+DROP INDEX IF EXISTS semantic_chunks_embedding_1536_idx;
+DROP INDEX IF EXISTS semantic_chunks_embedding_3072_idx;
+DROP INDEX IF EXISTS semantic_chunks_project_embedding_model_idx;
+
+ALTER TABLE semantic_chunks DROP COLUMN IF EXISTS embedding;
+ALTER TABLE semantic_chunks DROP COLUMN IF EXISTS embedding_dims;
+UPDATE semantic_chunks SET embedding_model = NULL;
+ALTER TABLE semantic_chunks ADD COLUMN embedding REAL[];
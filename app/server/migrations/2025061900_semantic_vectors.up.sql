@@ -0,0 +1,35 @@
+-- embeddings move from REAL[] columns scanned in the server to pgvector halfvec columns with HNSW indexes, so searching ranks in postgres
+-- halfvec keeps large models (e.g. 3072 dimensions) under the HNSW index limit. Existing embeddings are dropped and re-created in the background.
+ALTER TABLE semantic_chunks DROP COLUMN IF EXISTS embedding;
+UPDATE semantic_chunks SET embedding_model = NULL;
+ALTER TABLE semantic_chunks ADD COLUMN embedding_dims INTEGER;
+
+CREATE INDEX semantic_chunks_project_embedding_model_idx ON semantic_chunks(project_id, embedding_model);
+
+-- pgvector 0.8+ is needed for iterative index scans, which keep results from other projects from crowding out a project's matches
+-- without it, the index still works with bm25 ranking and embedding models fall back to bm25
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM pg_available_extensions
+    WHERE name = 'vector' AND string_to_array(default_version, '.')::int[] >= ARRAY[0, 8]
+  ) THEN
+    BEGIN
+      CREATE EXTENSION IF NOT EXISTS vector;
+    EXCEPTION WHEN insufficient_privilege THEN
+      RAISE NOTICE 'pgvector is available but the database user can''t create it--semantic index embeddings are disabled';
+      RETURN;
+    END;
+
+    EXECUTE 'ALTER TABLE semantic_chunks ADD COLUMN embedding halfvec';
+
+    -- HNSW indexes need a fixed dimension, so there's a partial index for each common embedding size. Other sizes are ranked with an exact scan of the project's chunks.
+    EXECUTE 'CREATE INDEX semantic_chunks_embedding_384_idx ON semantic_chunks USING hnsw ((embedding::halfvec(384)) halfvec_cosine_ops) WHERE embedding_dims = 384';
+    EXECUTE 'CREATE INDEX semantic_chunks_embedding_768_idx ON semantic_chunks USING hnsw ((embedding::halfvec(768)) halfvec_cosine_ops) WHERE embedding_dims = 768';
+    EXECUTE 'CREATE INDEX semantic_chunks_embedding_1024_idx ON semantic_chunks USING hnsw ((embedding::halfvec(1024)) halfvec_cosine_ops) WHERE embedding_dims = 1024';
+    EXECUTE 'CREATE INDEX semantic_chunks_embedding_1536_idx ON semantic_chunks USING hnsw ((embedding::halfvec(1536)) halfvec_cosine_ops) WHERE embedding_dims = 1536';
+    EXECUTE 'CREATE INDEX semantic_chunks_embedding_3072_idx ON semantic_chunks USING hnsw ((embedding::halfvec(3072)) halfvec_cosine_ops) WHERE embedding_dims = 3072';
+  ELSE
+    RAISE NOTICE 'pgvector 0.8+ isn''t available--semantic index embeddings are disabled';
+  END IF;
+END $$;
@@ -0,0 +1 @@
+ALTER TABLE model_streams DROP COLUMN IF EXISTS resume_request;
@@ -0,0 +1,2 @@
+-- the tell request a stream was started with, minus credentials, so another server instance can resume the stream if its own instance stops
+ALTER TABLE model_streams ADD COLUMN resume_request JSON;
@@ -74,11 +74,15 @@ func newClient(apiKey, endpoint, orgId string) ClientInfo {
 	}
 }
 
-// ExtendedChatCompletionStream can wrap either a native OpenAI stream or our custom implementation
+// ExtendedChatCompletionStream can wrap either a native OpenAI stream, our custom implementation, a native Anthropic Messages API stream, an OpenAI Responses API stream, or a replayed fixture
 type ExtendedChatCompletionStream struct {
-	openaiStream *openai.ChatCompletionStream
-	customReader *StreamReader[types.ExtendedChatCompletionStreamResponse]
-	ctx          context.Context
+	openaiStream    *openai.ChatCompletionStream
+	customReader    *StreamReader[types.ExtendedChatCompletionStreamResponse]
+	anthropicReader *AnthropicStreamReader
+	responsesReader *ResponsesStreamReader
+	replayReader    *ReplayStreamReader
+	recorder        *streamRecorder
+	ctx             context.Context
 }
 
 // StreamReader handles the SSE stream reading
@@ -162,8 +166,36 @@ func createChatCompletionStreamExtended(
 	ctx context.Context,
 	extendedReq types.ExtendedChatCompletionRequest,
 ) (*ExtendedChatCompletionStream, error) {
+	mode, fixturesDir := getModelTransport()
+	switch mode {
+	case ModelTransportReplay:
+		return replayChatCompletionStream(modelConfig, fixturesDir, ctx, extendedReq)
+	case ModelTransportRecord:
+		return recordChatCompletionStream(modelConfig, client, baseUrl, fixturesDir, ctx, extendedReq)
+	}
+
+	return createLiveChatCompletionStream(modelConfig, client, baseUrl, ctx, extendedReq)
+}
+
+func createLiveChatCompletionStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderAnthropic {
+		log.Println("Creating chat completion stream with native Anthropic provider request")
+		return createAnthropicMessagesStream(modelConfig, client, baseUrl, ctx, extendedReq)
+	}
+
+	if modelConfig.BaseModelConfig.UsesOpenAIResponsesAPI {
+		log.Println("Creating chat completion stream with OpenAI Responses API request")
+		return createOpenAIResponsesStream(modelConfig, client, baseUrl, ctx, extendedReq)
+	}
+
 	var openaiReq *types.ExtendedOpenAIChatCompletionRequest
-	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderOpenAI && !modelConfig.BaseModelConfig.UsesOpenAIResponsesAPI {
+	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderOpenAI {
 		openaiReq = extendedReq.ToOpenAI()
 		log.Println("Creating chat completion stream with direct OpenAI provider request")
 	}
@@ -183,14 +215,7 @@ func createChatCompletionStreamExtended(
 	// log.Println("request jsonBody", string(jsonBody))
 
 	// Create new request
-	var url string
-	if modelConfig.BaseModelConfig.UsesOpenAIResponsesAPI {
-		url = baseUrl + "/responses"
-	} else {
-		url = baseUrl + "/chat/completions"
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseUrl+"/chat/completions", bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -320,6 +345,14 @@ func (stream *StreamReader[T]) Close() error {
 
 // Recv returns the next message in the stream
 func (stream *ExtendedChatCompletionStream) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	response, err := stream.recv()
+	if stream.recorder != nil {
+		stream.recorder.add(response, err)
+	}
+	return response, err
+}
+
+func (stream *ExtendedChatCompletionStream) recv() (*types.ExtendedChatCompletionStreamResponse, error) {
 	select {
 	case <-stream.ctx.Done():
 		return nil, stream.ctx.Err()
@@ -337,15 +370,36 @@ func (stream *ExtendedChatCompletionStream) Recv() (*types.ExtendedChatCompletio
 			}
 			return &response, nil
 		}
+		if stream.anthropicReader != nil {
+			return stream.anthropicReader.Recv()
+		}
+		if stream.responsesReader != nil {
+			return stream.responsesReader.Recv()
+		}
+		if stream.replayReader != nil {
+			return stream.replayReader.Recv()
+		}
 		return stream.customReader.Recv()
 	}
 }
 
 // Close the response body
 func (stream *ExtendedChatCompletionStream) Close() error {
+	if stream.recorder != nil {
+		stream.recorder.flush()
+	}
+	if stream.replayReader != nil {
+		return stream.replayReader.Close()
+	}
 	if stream.openaiStream != nil {
 		return stream.openaiStream.Close()
 	}
+	if stream.anthropicReader != nil {
+		return stream.anthropicReader.Close()
+	}
+	if stream.responsesReader != nil {
+		return stream.responsesReader.Close()
+	}
 	return stream.customReader.Close()
 }
 
@@ -366,52 +420,7 @@ func resolveReq(req *types.ExtendedChatCompletionRequest, modelConfig *shared.Mo
 		}
 	}
 
-	// this isn't working yet
-	// if modelConfig.BaseModelConfig.UsesOpenAIResponsesAPI {
-	// 	log.Println("Using OpenAI Responses API")
-	// 	input := make([]types.ExtendedChatMessage, 0)
-
-	// 	for _, msg := range req.Messages {
-	// 		// isDevRole := false
-	// 		isAssistantRole := false
-	// 		if msg.Role == openai.ChatMessageRoleSystem {
-	// 			log.Println("Changing role of system message to 'developer'")
-	// 			msg.Role = openai.ChatMessageRoleDeveloper
-	// 			// isDevRole = true
-	// 		} else if msg.Role == openai.ChatMessageRoleAssistant {
-	// 			isAssistantRole = true
-	// 		}
-
-	// 		for j, part := range msg.Content {
-	// 			if part.Type == "text" {
-	// 				if isAssistantRole {
-	// 					part.Type = "output_text"
-	// 				} else {
-	// 					part.Type = "input_text"
-	// 				}
-	// 			}
-
-	// 			msg.Content[j] = part
-	// 		}
-
-	// 		input = append(input, msg)
-	// 	}
-
-	// 	// stop is not supported for the responses API
-	// 	if req.Stop != nil {
-	// 		req.Stop = nil
-	// 	}
-
-	// 	if req.StreamOptions != nil {
-	// 		req.StreamOptions = nil
-	// 	}
-
-	// 	req.Input = input
-	// 	req.Messages = nil
-
-	// 	log.Println("req.Input", len(req.Input))
-	// 	log.Println("req.Messages", len(req.Messages))
-	// }
+	// responses API requests are converted to input items with the 'developer' role in createOpenAIResponsesStream
 
 	if modelConfig.BaseModelConfig.RoleParamsDisabled {
 		log.Println("Role params disabled - setting temperature and top p to 1")
@@ -0,0 +1,572 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// native Anthropic Messages API support
+// requests are converted from our OpenAI-style ExtendedChatCompletionRequest, and streamed events are mapped back onto ExtendedChatCompletionStreamResponse so that callers don't need to know which wire format was used
+
+const AnthropicApiVersion = "2023-06-01"
+
+// used when neither the request nor the model config specifies an output limit—the Messages API requires max_tokens
+const anthropicDefaultMaxTokens = 8192
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                  `json:"type"`
+	Text         string                  `json:"text,omitempty"`
+	Source       *anthropicImageSource   `json:"source,omitempty"`
+	CacheControl *types.CacheControlSpec `json:"cache_control,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicMessagesRequest struct {
+	Model         shared.ModelName        `json:"model"`
+	System        []anthropicContentBlock `json:"system,omitempty"`
+	Messages      []anthropicMessage      `json:"messages"`
+	MaxTokens     int                     `json:"max_tokens"`
+	Temperature   *float32                `json:"temperature,omitempty"`
+	TopP          *float32                `json:"top_p,omitempty"`
+	StopSequences []string                `json:"stop_sequences,omitempty"`
+	Stream        bool                    `json:"stream"`
+	Thinking      *anthropicThinking      `json:"thinking,omitempty"`
+	Tools         []anthropicTool         `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice    `json:"tool_choice,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message *struct {
+		Id    string         `json:"id"`
+		Model string         `json:"model"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	ContentBlock *struct {
+		Type     string `json:"type"`
+		Id       string `json:"id,omitempty"`
+		Name     string `json:"name,omitempty"`
+		Text     string `json:"text,omitempty"`
+		Thinking string `json:"thinking,omitempty"`
+	} `json:"content_block,omitempty"`
+	Delta *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text,omitempty"`
+		Thinking    string `json:"thinking,omitempty"`
+		PartialJson string `json:"partial_json,omitempty"`
+		StopReason  string `json:"stop_reason,omitempty"`
+	} `json:"delta,omitempty"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func createAnthropicMessagesStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	anthropicReq := toAnthropicRequest(extendedReq, modelConfig)
+
+	jsonBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseUrl+"/messages", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("x-api-key", client.ApiKey)
+	req.Header.Set("anthropic-version", AnthropicApiVersion)
+
+	resp, err := httpClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading error response: %w", err)
+		}
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			Header:     resp.Header.Clone(),
+		}
+	}
+
+	return &ExtendedChatCompletionStream{
+		anthropicReader: &AnthropicStreamReader{
+			reader:           bufio.NewReader(resp.Body),
+			response:         resp,
+			errAccumulator:   NewErrorAccumulator(),
+			toolIndexByBlock: map[int]int{},
+		},
+		ctx: ctx,
+	}, nil
+}
+
+func toAnthropicRequest(req types.ExtendedChatCompletionRequest, modelConfig *shared.ModelRoleConfig) *anthropicMessagesRequest {
+	res := &anthropicMessagesRequest{
+		Model:         req.Model,
+		Stream:        true,
+		StopSequences: req.Stop,
+	}
+
+	// system messages become top-level system blocks (keeping cache_control breakpoints)
+	// images aren't allowed in system blocks, so they're moved to the start of the first user message
+	var systemImages []anthropicContentBlock
+
+	for _, msg := range req.Messages {
+		blocks := toAnthropicBlocks(msg.Content)
+
+		if msg.Role == openai.ChatMessageRoleSystem || msg.Role == openai.ChatMessageRoleDeveloper {
+			for _, block := range blocks {
+				if block.Type == "image" {
+					systemImages = append(systemImages, block)
+				} else {
+					res.System = append(res.System, block)
+				}
+			}
+			continue
+		}
+
+		role := "user"
+		if msg.Role == openai.ChatMessageRoleAssistant {
+			role = "assistant"
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		// the Messages API requires alternating roles, so consecutive messages with the same role are merged
+		if len(res.Messages) > 0 && res.Messages[len(res.Messages)-1].Role == role {
+			last := &res.Messages[len(res.Messages)-1]
+			last.Content = append(last.Content, blocks...)
+			continue
+		}
+
+		res.Messages = append(res.Messages, anthropicMessage{
+			Role:    role,
+			Content: blocks,
+		})
+	}
+
+	// the conversation must start with a user message, and there must be at least one
+	if len(res.Messages) == 0 || res.Messages[0].Role != "user" {
+		var content []anthropicContentBlock
+		if len(res.Messages) == 0 && len(res.System) > 0 {
+			// system prompt only—send it as the user message instead
+			content = res.System
+			res.System = nil
+		} else {
+			content = []anthropicContentBlock{{Type: "text", Text: "Continue."}}
+		}
+		res.Messages = append([]anthropicMessage{{Role: "user", Content: content}}, res.Messages...)
+	}
+
+	if len(systemImages) > 0 {
+		res.Messages[0].Content = append(systemImages, res.Messages[0].Content...)
+	}
+
+	maxTokens := req.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = req.MaxTokens
+	}
+	if maxTokens == 0 {
+		maxTokens = modelConfig.BaseModelConfig.MaxOutputTokens
+	}
+	if maxTokens == 0 {
+		maxTokens = anthropicDefaultMaxTokens
+	}
+
+	// input + max_tokens can't exceed the context window
+	if modelConfig.BaseModelConfig.MaxTokens > 0 {
+		available := modelConfig.BaseModelConfig.MaxTokens - GetMessagesTokenEstimate(req.Messages...) - TokensPerRequest
+		if available > 0 && available < maxTokens {
+			maxTokens = available
+		}
+	}
+
+	budget := modelConfig.BaseModelConfig.ReasoningBudget
+	if budget > 0 && budget < maxTokens {
+		res.Thinking = &anthropicThinking{
+			Type:         "enabled",
+			BudgetTokens: budget,
+		}
+	}
+	res.MaxTokens = maxTokens
+
+	// thinking requires default sampling params
+	if res.Thinking == nil {
+		if req.Temperature != 0 {
+			temperature := req.Temperature
+			res.Temperature = &temperature
+		}
+		if req.TopP != 0 && req.TopP != 1 {
+			topP := req.TopP
+			res.TopP = &topP
+		}
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		res.Tools = append(res.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+
+	if len(res.Tools) > 0 && res.Thinking == nil {
+		res.ToolChoice = toAnthropicToolChoice(req.ToolChoice)
+	}
+
+	return res
+}
+
+func toAnthropicBlocks(parts []types.ExtendedChatMessagePart) []anthropicContentBlock {
+	var blocks []anthropicContentBlock
+	for _, part := range parts {
+		switch part.Type {
+		case openai.ChatMessagePartTypeImageURL:
+			if part.ImageURL == nil {
+				continue
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:         "image",
+				Source:       toAnthropicImageSource(part.ImageURL.URL),
+				CacheControl: part.CacheControl,
+			})
+		default:
+			if part.Text == "" {
+				continue
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:         "text",
+				Text:         part.Text,
+				CacheControl: part.CacheControl,
+			})
+		}
+	}
+	return blocks
+}
+
+func toAnthropicImageSource(url string) *anthropicImageSource {
+	// data:image/png;base64,....
+	if strings.HasPrefix(url, "data:") {
+		meta, data, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+		if found {
+			return &anthropicImageSource{
+				Type:      "base64",
+				MediaType: strings.TrimSuffix(meta, ";base64"),
+				Data:      data,
+			}
+		}
+	}
+
+	return &anthropicImageSource{
+		Type: "url",
+		URL:  url,
+	}
+}
+
+func toAnthropicToolChoice(toolChoice any) *anthropicToolChoice {
+	switch tc := toolChoice.(type) {
+	case string:
+		if tc == "required" {
+			return &anthropicToolChoice{Type: "any"}
+		}
+	case *openai.ToolChoice:
+		if tc != nil && tc.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+		}
+	case openai.ToolChoice:
+		if tc.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+		}
+	}
+	return &anthropicToolChoice{Type: "auto"}
+}
+
+// AnthropicStreamReader reads Messages API server-sent events and maps them onto OpenAI-style stream chunks
+type AnthropicStreamReader struct {
+	reader         *bufio.Reader
+	response       *http.Response
+	errAccumulator *ErrorAccumulator
+
+	id               string
+	model            string
+	usage            anthropicUsage
+	toolIndexByBlock map[int]int
+	done             bool
+}
+
+func (stream *AnthropicStreamReader) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	for {
+		if stream.done {
+			return nil, io.EOF
+		}
+
+		line, err := stream.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+
+		// event names are duplicated in the 'type' field of the data payload, so only data lines matter
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event anthropicStreamEvent
+		err = json.Unmarshal([]byte(data), &event)
+		if err != nil {
+			stream.errAccumulator.Add(err)
+			continue
+		}
+
+		response := stream.handleEvent(&event)
+		if response != nil {
+			return response, nil
+		}
+	}
+}
+
+func (stream *AnthropicStreamReader) handleEvent(event *anthropicStreamEvent) *types.ExtendedChatCompletionStreamResponse {
+	switch event.Type {
+	case "message_start":
+		if event.Message == nil {
+			return nil
+		}
+		stream.id = event.Message.Id
+		stream.model = event.Message.Model
+		stream.usage = event.Message.Usage
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "")
+
+	case "content_block_start":
+		if event.ContentBlock == nil {
+			return nil
+		}
+		switch event.ContentBlock.Type {
+		case "tool_use":
+			toolIndex := len(stream.toolIndexByBlock)
+			stream.toolIndexByBlock[event.Index] = toolIndex
+			return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index: &toolIndex,
+					ID:    event.ContentBlock.Id,
+					Type:  openai.ToolTypeFunction,
+					Function: openai.FunctionCall{
+						Name: event.ContentBlock.Name,
+					},
+				}},
+			}, "")
+		case "text":
+			if event.ContentBlock.Text != "" {
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Content: event.ContentBlock.Text}, "")
+			}
+		case "thinking":
+			if event.ContentBlock.Thinking != "" {
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Reasoning: event.ContentBlock.Thinking}, "")
+			}
+		}
+		return nil
+
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil
+		}
+		switch event.Delta.Type {
+		case "text_delta":
+			return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Content: event.Delta.Text}, "")
+		case "thinking_delta":
+			return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Reasoning: event.Delta.Thinking}, "")
+		case "input_json_delta":
+			toolIndex := stream.toolIndexByBlock[event.Index]
+			return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index: &toolIndex,
+					Type:  openai.ToolTypeFunction,
+					Function: openai.FunctionCall{
+						Arguments: event.Delta.PartialJson,
+					},
+				}},
+			}, "")
+		}
+		// signature deltas and unknown delta types are ignored
+		return nil
+
+	case "message_delta":
+		if event.Usage != nil {
+			stream.usage.OutputTokens = event.Usage.OutputTokens
+		}
+		if event.Delta == nil || event.Delta.StopReason == "" {
+			return nil
+		}
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{}, anthropicFinishReason(event.Delta.StopReason))
+
+	case "message_stop":
+		stream.done = true
+
+		// cached and cache-write tokens are reported separately from input tokens
+		usage := stream.usage
+		return &types.ExtendedChatCompletionStreamResponse{
+			ID:      stream.id,
+			Object:  "chat.completion.chunk",
+			Model:   stream.model,
+			Choices: []types.ExtendedChatCompletionStreamChoice{},
+			Usage: &openai.Usage{
+				PromptTokens:     usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens,
+				CompletionTokens: usage.OutputTokens,
+				TotalTokens:      usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens + usage.OutputTokens,
+				PromptTokensDetails: &openai.PromptTokensDetails{
+					CachedTokens: usage.CacheReadInputTokens,
+				},
+			},
+		}
+
+	case "error":
+		if event.Error == nil {
+			return nil
+		}
+		log.Printf("Anthropic stream error: %s - %s", event.Error.Type, event.Error.Message)
+		return &types.ExtendedChatCompletionStreamResponse{
+			ID:    stream.id,
+			Model: stream.model,
+			Error: &types.ExtendedChatCompletionStreamError{
+				Message: event.Error.Type + ": " + event.Error.Message,
+				Code:    anthropicErrorCode(event.Error.Type),
+			},
+		}
+	}
+
+	// ping, content_block_stop
+	return nil
+}
+
+func (stream *AnthropicStreamReader) chunk(delta types.ExtendedChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) *types.ExtendedChatCompletionStreamResponse {
+	return &types.ExtendedChatCompletionStreamResponse{
+		ID:     stream.id,
+		Object: "chat.completion.chunk",
+		Model:  stream.model,
+		Choices: []types.ExtendedChatCompletionStreamChoice{
+			{
+				Index:        0,
+				Delta:        delta,
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
+func (stream *AnthropicStreamReader) Close() error {
+	if stream.response != nil {
+		return stream.response.Body.Close()
+	}
+	return nil
+}
+
+func anthropicFinishReason(stopReason string) openai.FinishReason {
+	switch stopReason {
+	case "max_tokens":
+		return openai.FinishReasonLength
+	case "tool_use":
+		return openai.FinishReasonToolCalls
+	case "refusal":
+		return openai.FinishReasonContentFilter
+	default:
+		// end_turn, stop_sequence, pause_turn
+		return openai.FinishReasonStop
+	}
+}
+
+// maps Messages API error types to the http status codes ClassifyModelError understands
+func anthropicErrorCode(errType string) int {
+	switch errType {
+	case "overloaded_error":
+		return 529
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "request_too_large":
+		return http.StatusRequestEntityTooLarge
+	case "invalid_request_error":
+		return http.StatusBadRequest
+	case "authentication_error":
+		return http.StatusUnauthorized
+	case "permission_error":
+		return http.StatusForbidden
+	case "not_found_error":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,244 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/types"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var anthropicTestEvents = []string{
+	`{"type":"message_start","message":{"id":"msg_1","model":"claude-test","usage":{"input_tokens":10,"output_tokens":1,"cache_read_input_tokens":90}}}`,
+	`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+	`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me think."}}`,
+	`{"type":"content_block_stop","index":0}`,
+	`{"type":"ping"}`,
+	`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+	`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Hello"}}`,
+	`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":" world"}}`,
+	`{"type":"content_block_stop","index":1}`,
+	`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":25}}`,
+	`{"type":"message_stop"}`,
+}
+
+func newFakeSSEServer(t *testing.T, events []string, onReq func(r *http.Request, body []byte)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if onReq != nil {
+			onReq(r, body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal([]byte(event), &typed); err != nil {
+				t.Fatalf("invalid test event: %v", err)
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, event)
+		}
+	}))
+}
+
+func TestAnthropicMessagesStream(t *testing.T) {
+	var gotReq anthropicMessagesRequest
+	var gotHeaders http.Header
+
+	server := newFakeSSEServer(t, anthropicTestEvents, func(r *http.Request, body []byte) {
+		gotHeaders = r.Header.Clone()
+		if err := json.Unmarshal(body, &gotReq); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+	})
+	defer server.Close()
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:        shared.ModelProviderAnthropic,
+			ModelName:       "claude-test",
+			MaxTokens:       200000,
+			MaxOutputTokens: 64000,
+			ReasoningBudget: 16000,
+		},
+	}
+
+	req := types.ExtendedChatCompletionRequest{
+		Model: "claude-test",
+		Messages: []types.ExtendedChatMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: []types.ExtendedChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "sys", CacheControl: &types.CacheControlSpec{Type: types.CacheControlTypeEphemeral}},
+				},
+			},
+			{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "a"}}},
+			{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "b"}}},
+		},
+		Temperature: 0.3,
+		Stop:        []string{"<PlandexFinish/>"},
+	}
+
+	stream, err := createAnthropicMessagesStream(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var content, reasoning strings.Builder
+	var finishReason openai.FinishReason
+	var usage *openai.Usage
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			reasoning.WriteString(choice.Delta.Reasoning)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	if gotHeaders.Get("x-api-key") != "test-key" || gotHeaders.Get("anthropic-version") != AnthropicApiVersion {
+		t.Errorf("missing auth headers: %v", gotHeaders)
+	}
+	if len(gotReq.System) != 1 || gotReq.System[0].CacheControl == nil {
+		t.Errorf("expected one cached system block, got %+v", gotReq.System)
+	}
+	if len(gotReq.Messages) != 1 || len(gotReq.Messages[0].Content) != 2 {
+		t.Errorf("expected consecutive user messages to be merged, got %+v", gotReq.Messages)
+	}
+	if gotReq.Thinking == nil || gotReq.Thinking.BudgetTokens != 16000 {
+		t.Errorf("expected thinking to be enabled, got %+v", gotReq.Thinking)
+	}
+	if gotReq.Temperature != nil {
+		t.Errorf("temperature should be omitted with thinking enabled")
+	}
+	if len(gotReq.StopSequences) != 1 {
+		t.Errorf("expected stop sequences to be passed through")
+	}
+
+	if content.String() != "Hello world" {
+		t.Errorf("content = %q", content.String())
+	}
+	if reasoning.String() != "Let me think." {
+		t.Errorf("reasoning = %q", reasoning.String())
+	}
+	if finishReason != openai.FinishReasonStop {
+		t.Errorf("finish reason = %q", finishReason)
+	}
+	if usage == nil || usage.PromptTokens != 100 || usage.CompletionTokens != 25 || usage.PromptTokensDetails.CachedTokens != 90 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAnthropicStreamToolUseAndErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		events       []string
+		wantArgs     string
+		wantFinish   openai.FinishReason
+		wantErrCode  int
+		wantErrMatch string
+	}{
+		{
+			name: "tool use",
+			events: []string{
+				`{"type":"message_start","message":{"id":"msg_2","model":"claude-test","usage":{"input_tokens":5}}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"listChangedFiles"}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"a\":"}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"1}"}}`,
+				`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":3}}`,
+				`{"type":"message_stop"}`,
+			},
+			wantArgs:   `{"a":1}`,
+			wantFinish: openai.FinishReasonToolCalls,
+		},
+		{
+			name: "overloaded",
+			events: []string{
+				`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			},
+			wantErrCode:  529,
+			wantErrMatch: "overloaded_error",
+		},
+	}
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:  shared.ModelProviderAnthropic,
+			ModelName: "claude-test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSSEServer(t, tt.events, nil)
+			defer server.Close()
+
+			req := types.ExtendedChatCompletionRequest{
+				Model:    "claude-test",
+				Messages: []types.ExtendedChatMessage{{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "hi"}}}},
+			}
+
+			stream, err := createAnthropicMessagesStream(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer stream.Close()
+
+			var args strings.Builder
+			var finishReason openai.FinishReason
+			var streamErr *types.ExtendedChatCompletionStreamError
+			for {
+				chunk, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected stream error: %v", err)
+				}
+				if chunk.Error != nil {
+					streamErr = chunk.Error
+				}
+				for _, choice := range chunk.Choices {
+					for _, toolCall := range choice.Delta.ToolCalls {
+						args.WriteString(toolCall.Function.Arguments)
+					}
+					if choice.FinishReason != "" {
+						finishReason = choice.FinishReason
+					}
+				}
+			}
+
+			if args.String() != tt.wantArgs {
+				t.Errorf("tool args = %q, want %q", args.String(), tt.wantArgs)
+			}
+			if finishReason != tt.wantFinish {
+				t.Errorf("finish reason = %q, want %q", finishReason, tt.wantFinish)
+			}
+			if tt.wantErrCode != 0 {
+				if streamErr == nil || streamErr.Code != tt.wantErrCode || !strings.Contains(streamErr.Message, tt.wantErrMatch) {
+					t.Errorf("unexpected stream error: %+v", streamErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,513 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// OpenAI Responses API support, used when BaseModelConfig.UsesOpenAIResponsesAPI is set
+// like the native Anthropic provider, requests are converted from ExtendedChatCompletionRequest and streamed events are mapped back onto ExtendedChatCompletionStreamResponse
+
+type responsesContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+type responsesInputMessage struct {
+	Type    string                 `json:"type"`
+	Role    string                 `json:"role"`
+	Content []responsesContentPart `json:"content"`
+}
+
+// assistant tool calls and the tool messages answering them are sent as separate input items, matched up by CallId
+type responsesFunctionCall struct {
+	Type      string `json:"type"`
+	CallId    string `json:"call_id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type responsesFunctionCallOutput struct {
+	Type   string `json:"type"`
+	CallId string `json:"call_id"`
+	Output string `json:"output"`
+}
+
+type responsesReasoning struct {
+	Effort  shared.ReasoningEffort `json:"effort,omitempty"`
+	Summary string                 `json:"summary,omitempty"`
+}
+
+type responsesTool struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+	Strict      bool   `json:"strict,omitempty"`
+}
+
+type responsesRequest struct {
+	Model shared.ModelName `json:"model"`
+	// responsesInputMessage, responsesFunctionCall, or responsesFunctionCallOutput
+	Input           []any               `json:"input"`
+	MaxOutputTokens int                 `json:"max_output_tokens,omitempty"`
+	Temperature     *float32            `json:"temperature,omitempty"`
+	TopP            *float32            `json:"top_p,omitempty"`
+	Stream          bool                `json:"stream"`
+	Store           bool                `json:"store"`
+	Reasoning       *responsesReasoning `json:"reasoning,omitempty"`
+	Tools           []responsesTool     `json:"tools,omitempty"`
+	ToolChoice      any                 `json:"tool_choice,omitempty"`
+	User            string              `json:"user,omitempty"`
+}
+
+type responsesUsage struct {
+	InputTokens        int `json:"input_tokens"`
+	OutputTokens       int `json:"output_tokens"`
+	TotalTokens        int `json:"total_tokens"`
+	InputTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+	OutputTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details"`
+}
+
+type responsesStreamEvent struct {
+	Type        string `json:"type"`
+	OutputIndex int    `json:"output_index"`
+	Delta       string `json:"delta,omitempty"`
+	Response    *struct {
+		Id                string          `json:"id"`
+		Model             string          `json:"model"`
+		Status            string          `json:"status"`
+		Usage             *responsesUsage `json:"usage,omitempty"`
+		IncompleteDetails *struct {
+			Reason string `json:"reason"`
+		} `json:"incomplete_details,omitempty"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	} `json:"response,omitempty"`
+	Item *struct {
+		Type   string `json:"type"`
+		Id     string `json:"id"`
+		CallId string `json:"call_id,omitempty"`
+		Name   string `json:"name,omitempty"`
+	} `json:"item,omitempty"`
+
+	// top-level 'error' events
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func createOpenAIResponsesStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	responsesReq := toResponsesRequest(extendedReq, modelConfig)
+
+	jsonBody, err := json.Marshal(responsesReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseUrl+"/responses", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Authorization", "Bearer "+client.ApiKey)
+	if client.OrgId != "" {
+		req.Header.Set("OpenAI-Organization", client.OrgId)
+	}
+
+	resp, err := httpClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading error response: %w", err)
+		}
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			Header:     resp.Header.Clone(),
+		}
+	}
+
+	return &ExtendedChatCompletionStream{
+		responsesReader: &ResponsesStreamReader{
+			reader:          bufio.NewReader(resp.Body),
+			response:        resp,
+			errAccumulator:  NewErrorAccumulator(),
+			toolIndexByItem: map[int]int{},
+		},
+		ctx: ctx,
+	}, nil
+}
+
+func toResponsesRequest(req types.ExtendedChatCompletionRequest, modelConfig *shared.ModelRoleConfig) *responsesRequest {
+	res := &responsesRequest{
+		Model:  req.Model,
+		Stream: true,
+		User:   req.User,
+	}
+
+	// stop sequences aren't supported by the responses API—models using it should set StopDisabled so that stops are handled manually
+	if len(req.Stop) > 0 {
+		log.Println("Responses API doesn't support stop sequences - ignoring")
+	}
+
+	for _, msg := range req.Messages {
+		if msg.Role == openai.ChatMessageRoleTool {
+			var output strings.Builder
+			for _, part := range msg.Content {
+				output.WriteString(part.Text)
+			}
+			res.Input = append(res.Input, responsesFunctionCallOutput{
+				Type:   "function_call_output",
+				CallId: msg.ToolCallID,
+				Output: output.String(),
+			})
+			continue
+		}
+
+		role := msg.Role
+		if role == openai.ChatMessageRoleSystem {
+			role = openai.ChatMessageRoleDeveloper
+		}
+
+		textType := "input_text"
+		if role == openai.ChatMessageRoleAssistant {
+			textType = "output_text"
+		}
+
+		content := []responsesContentPart{}
+		for _, part := range msg.Content {
+			switch part.Type {
+			case openai.ChatMessagePartTypeImageURL:
+				if part.ImageURL != nil {
+					content = append(content, responsesContentPart{
+						Type:     "input_image",
+						ImageURL: part.ImageURL.URL,
+					})
+				}
+			default:
+				if part.Text != "" {
+					content = append(content, responsesContentPart{
+						Type: textType,
+						Text: part.Text,
+					})
+				}
+			}
+		}
+
+		if len(content) > 0 {
+			res.Input = append(res.Input, responsesInputMessage{
+				Type:    "message",
+				Role:    role,
+				Content: content,
+			})
+		}
+
+		// calls follow any text the assistant wrote before making them
+		for _, toolCall := range msg.ToolCalls {
+			res.Input = append(res.Input, responsesFunctionCall{
+				Type:      "function_call",
+				CallId:    toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+	}
+
+	res.MaxOutputTokens = req.MaxCompletionTokens
+	if res.MaxOutputTokens == 0 {
+		res.MaxOutputTokens = req.MaxTokens
+	}
+
+	if !modelConfig.BaseModelConfig.RoleParamsDisabled {
+		if req.Temperature != 0 {
+			temperature := req.Temperature
+			res.Temperature = &temperature
+		}
+		if req.TopP != 0 {
+			topP := req.TopP
+			res.TopP = &topP
+		}
+	}
+
+	var effort shared.ReasoningEffort
+	if req.ReasoningEffort != nil {
+		effort = *req.ReasoningEffort
+	} else if modelConfig.BaseModelConfig.ReasoningEffortEnabled {
+		effort = modelConfig.ReasoningEffort
+		if effort == "" {
+			effort = modelConfig.BaseModelConfig.ReasoningEffort
+		}
+	}
+
+	if effort != "" || req.IncludeReasoning {
+		res.Reasoning = &responsesReasoning{Effort: effort}
+		if req.IncludeReasoning {
+			res.Reasoning.Summary = "auto"
+		}
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		res.Tools = append(res.Tools, responsesTool{
+			Type:        "function",
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+			Strict:      tool.Function.Strict,
+		})
+	}
+
+	if len(res.Tools) > 0 && req.ToolChoice != nil {
+		res.ToolChoice = toResponsesToolChoice(req.ToolChoice)
+	}
+
+	return res
+}
+
+func toResponsesToolChoice(toolChoice any) any {
+	switch tc := toolChoice.(type) {
+	case *openai.ToolChoice:
+		if tc != nil && tc.Function.Name != "" {
+			return map[string]string{"type": "function", "name": tc.Function.Name}
+		}
+	case openai.ToolChoice:
+		if tc.Function.Name != "" {
+			return map[string]string{"type": "function", "name": tc.Function.Name}
+		}
+	case string:
+		return tc
+	}
+	return "auto"
+}
+
+// ResponsesStreamReader reads Responses API server-sent events and maps them onto OpenAI-style chat completion chunks
+type ResponsesStreamReader struct {
+	reader         *bufio.Reader
+	response       *http.Response
+	errAccumulator *ErrorAccumulator
+
+	id              string
+	model           string
+	toolIndexByItem map[int]int
+	done            bool
+}
+
+func (stream *ResponsesStreamReader) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	for {
+		if stream.done {
+			return nil, io.EOF
+		}
+
+		line, err := stream.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return nil, io.EOF
+		}
+
+		var event responsesStreamEvent
+		err = json.Unmarshal([]byte(data), &event)
+		if err != nil {
+			stream.errAccumulator.Add(err)
+			continue
+		}
+
+		response := stream.handleEvent(&event)
+		if response != nil {
+			return response, nil
+		}
+	}
+}
+
+func (stream *ResponsesStreamReader) handleEvent(event *responsesStreamEvent) *types.ExtendedChatCompletionStreamResponse {
+	switch event.Type {
+	case "response.created":
+		if event.Response != nil {
+			stream.id = event.Response.Id
+			stream.model = event.Response.Model
+		}
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "")
+
+	case "response.output_text.delta":
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Content: event.Delta}, "")
+
+	case "response.reasoning_summary_text.delta":
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Reasoning: event.Delta}, "")
+
+	case "response.reasoning_summary_part.done":
+		// separate summary parts so they don't run together
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{Reasoning: "\n\n"}, "")
+
+	case "response.output_item.added":
+		if event.Item == nil || event.Item.Type != "function_call" {
+			return nil
+		}
+		toolIndex := len(stream.toolIndexByItem)
+		stream.toolIndexByItem[event.OutputIndex] = toolIndex
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+			ToolCalls: []openai.ToolCall{{
+				Index: &toolIndex,
+				ID:    event.Item.CallId,
+				Type:  openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name: event.Item.Name,
+				},
+			}},
+		}, "")
+
+	case "response.function_call_arguments.delta":
+		toolIndex := stream.toolIndexByItem[event.OutputIndex]
+		return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+			ToolCalls: []openai.ToolCall{{
+				Index: &toolIndex,
+				Type:  openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Arguments: event.Delta,
+				},
+			}},
+		}, "")
+
+	case "response.completed", "response.incomplete":
+		stream.done = true
+		if event.Response == nil {
+			return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop)
+		}
+
+		finishReason := openai.FinishReasonStop
+		if len(stream.toolIndexByItem) > 0 {
+			finishReason = openai.FinishReasonToolCalls
+		}
+		if event.Response.IncompleteDetails != nil {
+			switch event.Response.IncompleteDetails.Reason {
+			case "max_output_tokens":
+				finishReason = openai.FinishReasonLength
+			case "content_filter":
+				finishReason = openai.FinishReasonContentFilter
+			}
+		}
+
+		// like openrouter, usage is included in the final chunk
+		response := stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{}, finishReason)
+		if usage := event.Response.Usage; usage != nil {
+			response.Usage = &openai.Usage{
+				PromptTokens:     usage.InputTokens,
+				CompletionTokens: usage.OutputTokens,
+				TotalTokens:      usage.TotalTokens,
+				PromptTokensDetails: &openai.PromptTokensDetails{
+					CachedTokens: usage.InputTokensDetails.CachedTokens,
+				},
+				CompletionTokensDetails: &openai.CompletionTokensDetails{
+					ReasoningTokens: usage.OutputTokensDetails.ReasoningTokens,
+				},
+			}
+		}
+		return response
+
+	case "response.failed":
+		stream.done = true
+		msg := "response failed"
+		code := ""
+		if event.Response != nil && event.Response.Error != nil {
+			msg = event.Response.Error.Message
+			code = event.Response.Error.Code
+		}
+		return stream.errorChunk(code, msg)
+
+	case "error":
+		stream.done = true
+		return stream.errorChunk(event.Code, event.Message)
+	}
+
+	// output_text.done, content_part.added, in_progress and other lifecycle events
+	return nil
+}
+
+func (stream *ResponsesStreamReader) chunk(delta types.ExtendedChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) *types.ExtendedChatCompletionStreamResponse {
+	return &types.ExtendedChatCompletionStreamResponse{
+		ID:     stream.id,
+		Object: "chat.completion.chunk",
+		Model:  stream.model,
+		Choices: []types.ExtendedChatCompletionStreamChoice{
+			{
+				Index:        0,
+				Delta:        delta,
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
+func (stream *ResponsesStreamReader) errorChunk(code, msg string) *types.ExtendedChatCompletionStreamResponse {
+	log.Printf("Responses API stream error: %s - %s", code, msg)
+
+	status := http.StatusInternalServerError
+	switch code {
+	case "rate_limit_exceeded":
+		status = http.StatusTooManyRequests
+	case "context_length_exceeded":
+		status = http.StatusRequestEntityTooLarge
+	case "invalid_prompt", "invalid_request_error":
+		status = http.StatusBadRequest
+	}
+
+	return &types.ExtendedChatCompletionStreamResponse{
+		ID:    stream.id,
+		Model: stream.model,
+		Error: &types.ExtendedChatCompletionStreamError{
+			Message: strings.TrimPrefix(code+": "+msg, ": "),
+			Code:    status,
+		},
+	}
+}
+
+func (stream *ResponsesStreamReader) Close() error {
+	if stream.response != nil {
+		return stream.response.Body.Close()
+	}
+	return nil
+}
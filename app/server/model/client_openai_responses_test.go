@@ -0,0 +1,240 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"plandex-server/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestOpenAIResponsesStream(t *testing.T) {
+	events := []string{
+		`{"type":"response.created","response":{"id":"resp_1","model":"o3-pro","status":"in_progress"}}`,
+		`{"type":"response.reasoning_summary_text.delta","output_index":0,"delta":"Thinking"}`,
+		`{"type":"response.output_text.delta","output_index":1,"delta":"Hello"}`,
+		`{"type":"response.output_text.delta","output_index":1,"delta":" world"}`,
+		`{"type":"response.completed","response":{"id":"resp_1","model":"o3-pro","status":"completed","usage":{"input_tokens":100,"output_tokens":40,"total_tokens":140,"input_tokens_details":{"cached_tokens":60},"output_tokens_details":{"reasoning_tokens":30}}}}`,
+	}
+
+	var gotReq map[string]any
+	var gotPath string
+	server := newFakeSSEServer(t, events, func(r *http.Request, body []byte) {
+		gotPath = r.URL.Path
+		if err := json.Unmarshal(body, &gotReq); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+	})
+	defer server.Close()
+
+	modelConfig := &shared.ModelRoleConfig{
+		ReasoningEffort: shared.ReasoningEffortHigh,
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:               shared.ModelProviderOpenAI,
+			ModelName:              "o3-pro",
+			UsesOpenAIResponsesAPI: true,
+			ReasoningEffortEnabled: true,
+			RoleParamsDisabled:     true,
+		},
+	}
+
+	req := types.ExtendedChatCompletionRequest{
+		Model: "o3-pro",
+		Messages: []types.ExtendedChatMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "sys"}}},
+			{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "hi"}}},
+			{Role: openai.ChatMessageRoleAssistant, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "hello"}}},
+		},
+		IncludeReasoning: true,
+		Temperature:      0.3,
+	}
+
+	stream, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var content, reasoning strings.Builder
+	var usage *openai.Usage
+	var finishReason openai.FinishReason
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			reasoning.WriteString(choice.Delta.Reasoning)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	if gotPath != "/responses" {
+		t.Errorf("path = %q", gotPath)
+	}
+
+	input, _ := gotReq["input"].([]any)
+	if len(input) != 3 {
+		t.Fatalf("expected 3 input items, got %v", gotReq["input"])
+	}
+	roles := []string{}
+	partTypes := []string{}
+	for _, item := range input {
+		m := item.(map[string]any)
+		roles = append(roles, m["role"].(string))
+		part := m["content"].([]any)[0].(map[string]any)
+		partTypes = append(partTypes, part["type"].(string))
+	}
+	if strings.Join(roles, ",") != "developer,user,assistant" {
+		t.Errorf("roles = %v", roles)
+	}
+	if strings.Join(partTypes, ",") != "input_text,input_text,output_text" {
+		t.Errorf("part types = %v", partTypes)
+	}
+	reasoningParams, _ := gotReq["reasoning"].(map[string]any)
+	if reasoningParams["effort"] != "high" || reasoningParams["summary"] != "auto" {
+		t.Errorf("reasoning = %v", gotReq["reasoning"])
+	}
+	if _, ok := gotReq["temperature"]; ok {
+		t.Errorf("temperature should be omitted when role params are disabled")
+	}
+
+	if content.String() != "Hello world" || reasoning.String() != "Thinking" {
+		t.Errorf("content = %q, reasoning = %q", content.String(), reasoning.String())
+	}
+	if finishReason != openai.FinishReasonStop {
+		t.Errorf("finish reason = %q", finishReason)
+	}
+	if usage == nil || usage.PromptTokens != 100 || usage.PromptTokensDetails.CachedTokens != 60 || usage.CompletionTokensDetails.ReasoningTokens != 30 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestOpenAIResponsesToolCallRoundTrip(t *testing.T) {
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:               shared.ModelProviderOpenAI,
+			ModelName:              "o3-pro",
+			UsesOpenAIResponsesAPI: true,
+		},
+	}
+
+	// first turn: the model calls a tool
+	events := []string{
+		`{"type":"response.created","response":{"id":"resp_1","model":"o3-pro","status":"in_progress"}}`,
+		`{"type":"response.output_item.added","output_index":0,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"listFiles"}}`,
+		`{"type":"response.function_call_arguments.delta","output_index":0,"delta":"{\"dir\":"}`,
+		`{"type":"response.function_call_arguments.delta","output_index":0,"delta":"\"src\"}"}`,
+		`{"type":"response.completed","response":{"id":"resp_1","model":"o3-pro","status":"completed"}}`,
+	}
+	server := newFakeSSEServer(t, events, nil)
+	defer server.Close()
+
+	userMsg := types.ExtendedChatMessage{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "what's in src?"}}}
+
+	stream, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), types.ExtendedChatCompletionRequest{
+		Model:    "o3-pro",
+		Messages: []types.ExtendedChatMessage{userMsg},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var toolCall openai.ToolCall
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		for _, choice := range chunk.Choices {
+			for _, tc := range choice.Delta.ToolCalls {
+				if tc.ID != "" {
+					toolCall.ID = tc.ID
+					toolCall.Type = tc.Type
+				}
+				toolCall.Function.Name += tc.Function.Name
+				toolCall.Function.Arguments += tc.Function.Arguments
+			}
+		}
+	}
+	stream.Close()
+
+	if toolCall.ID != "call_1" || toolCall.Function.Name != "listFiles" || toolCall.Function.Arguments != `{"dir":"src"}` {
+		t.Fatalf("unexpected tool call: %+v", toolCall)
+	}
+
+	// second turn: the call and its result are sent back
+	var gotReq map[string]any
+	server2 := newFakeSSEServer(t, []string{
+		`{"type":"response.completed","response":{"id":"resp_2","model":"o3-pro","status":"completed"}}`,
+	}, func(r *http.Request, body []byte) {
+		if err := json.Unmarshal(body, &gotReq); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+	})
+	defer server2.Close()
+
+	stream, err = createChatCompletionStreamExtended(modelConfig, ClientInfo{ApiKey: "test-key"}, server2.URL, context.Background(), types.ExtendedChatCompletionRequest{
+		Model: "o3-pro",
+		Messages: []types.ExtendedChatMessage{
+			userMsg,
+			{
+				Role:      openai.ChatMessageRoleAssistant,
+				Content:   []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "Checking."}},
+				ToolCalls: []openai.ToolCall{toolCall},
+			},
+			{
+				Role:       openai.ChatMessageRoleTool,
+				ToolCallID: toolCall.ID,
+				Content:    []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "main.go"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+
+	input, _ := gotReq["input"].([]any)
+	if len(input) != 4 {
+		t.Fatalf("expected 4 input items, got %v", gotReq["input"])
+	}
+
+	if m := input[1].(map[string]any); m["type"] != "message" || m["role"] != "assistant" {
+		t.Errorf("assistant text item = %v", m)
+	}
+
+	want := []map[string]any{
+		{"type": "function_call", "call_id": "call_1", "name": "listFiles", "arguments": `{"dir":"src"}`},
+		{"type": "function_call_output", "call_id": "call_1", "output": "main.go"},
+	}
+	for i, w := range want {
+		if got := input[i+2].(map[string]any); !reflect.DeepEqual(got, w) {
+			t.Errorf("input[%d] = %v, want %v", i+2, got, w)
+		}
+	}
+}
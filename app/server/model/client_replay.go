@@ -0,0 +1,326 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"plandex-server/types"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// Record-and-replay transport for model requests. Set MODEL_TRANSPORT=record to capture every streamed model response to MODEL_FIXTURES_DIR, then MODEL_TRANSPORT=replay to serve the same responses offline. Fixtures are keyed by a hash of the provider, model, and full request body, so a replayed run needs the same prompts (and context) as the recorded one. Both CreateChatCompletionStream and CreateChatCompletionWithInternalStream go through this layer.
+
+type ModelTransportMode string
+
+const (
+	ModelTransportLive   ModelTransportMode = ""
+	ModelTransportRecord ModelTransportMode = "record"
+	ModelTransportReplay ModelTransportMode = "replay"
+)
+
+const defaultModelFixturesDir = "model-fixtures"
+
+// a fixture file holds every response recorded for one request, in order—retries of the same request (after a rate limit, for example) are replayed in the same sequence, and the last response repeats once the sequence is exhausted
+type ModelFixture struct {
+	RequestHash string                 `json:"requestHash"`
+	Provider    shared.ModelProvider   `json:"provider"`
+	ModelName   shared.ModelName       `json:"modelName"`
+	Request     json.RawMessage        `json:"request"`
+	Responses   []ModelFixtureResponse `json:"responses"`
+}
+
+type ModelFixtureResponse struct {
+	// set when the request itself failed (HTTPError)
+	StatusCode int    `json:"statusCode,omitempty"`
+	Body       string `json:"body,omitempty"`
+
+	Chunks []*types.ExtendedChatCompletionStreamResponse `json:"chunks,omitempty"`
+
+	// set when the stream failed with an error other than io.EOF
+	StreamError string `json:"streamError,omitempty"`
+}
+
+var modelTransport struct {
+	once sync.Once
+	mode ModelTransportMode
+	dir  string
+
+	mu sync.Mutex
+	// replay position per request hash
+	replayed map[string]int
+	// request hashes written during this process—a hash seen for the first time replaces any fixture left by a previous recording
+	recorded map[string]bool
+}
+
+// SetModelTransport overrides the MODEL_TRANSPORT and MODEL_FIXTURES_DIR env vars—used by tests
+func SetModelTransport(mode ModelTransportMode, dir string) {
+	modelTransport.once.Do(func() {})
+
+	modelTransport.mu.Lock()
+	defer modelTransport.mu.Unlock()
+
+	modelTransport.mode = mode
+	modelTransport.dir = dir
+	modelTransport.replayed = map[string]int{}
+	modelTransport.recorded = map[string]bool{}
+}
+
+func getModelTransport() (ModelTransportMode, string) {
+	modelTransport.once.Do(func() {
+		mode := ModelTransportMode(os.Getenv("MODEL_TRANSPORT"))
+		dir := os.Getenv("MODEL_FIXTURES_DIR")
+		if dir == "" {
+			dir = defaultModelFixturesDir
+		}
+
+		switch mode {
+		case ModelTransportLive:
+		case ModelTransportRecord, ModelTransportReplay:
+			log.Printf("Model transport: %s, fixtures dir: %s\n", mode, dir)
+		default:
+			log.Printf("Unknown MODEL_TRANSPORT %q, using live model requests\n", mode)
+			mode = ModelTransportLive
+		}
+
+		modelTransport.mode = mode
+		modelTransport.dir = dir
+		modelTransport.replayed = map[string]int{}
+		modelTransport.recorded = map[string]bool{}
+	})
+
+	modelTransport.mu.Lock()
+	defer modelTransport.mu.Unlock()
+	return modelTransport.mode, modelTransport.dir
+}
+
+func GetModelRequestHash(modelConfig *shared.ModelRoleConfig, req types.ExtendedChatCompletionRequest) (string, json.RawMessage, error) {
+	reqJson, err := json.Marshal(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("error marshalling request: %v", err)
+	}
+
+	keyJson, err := json.Marshal(struct {
+		Provider  shared.ModelProvider `json:"provider"`
+		ModelName shared.ModelName     `json:"modelName"`
+		Request   json.RawMessage      `json:"request"`
+	}{
+		Provider:  modelConfig.BaseModelConfig.Provider,
+		ModelName: modelConfig.BaseModelConfig.ModelName,
+		Request:   reqJson,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error marshalling request key: %v", err)
+	}
+
+	sum := sha256.Sum256(keyJson)
+	return hex.EncodeToString(sum[:]), reqJson, nil
+}
+
+func modelFixturePath(dir, hash string) string {
+	return filepath.Join(dir, hash+".json")
+}
+
+func replayChatCompletionStream(
+	modelConfig *shared.ModelRoleConfig,
+	dir string,
+	ctx context.Context,
+	req types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	hash, _, err := GetModelRequestHash(modelConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	path := modelFixturePath(dir, hash)
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no model fixture for request %s (%s) in %s—record it with MODEL_TRANSPORT=record", hash, modelConfig.BaseModelConfig.ModelName, dir)
+		}
+		return nil, fmt.Errorf("error reading model fixture %s: %v", path, err)
+	}
+
+	var fixture ModelFixture
+	err = json.Unmarshal(bytes, &fixture)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling model fixture %s: %v", path, err)
+	}
+
+	if len(fixture.Responses) == 0 {
+		return nil, fmt.Errorf("model fixture %s has no responses", path)
+	}
+
+	modelTransport.mu.Lock()
+	i := modelTransport.replayed[hash]
+	modelTransport.replayed[hash] = i + 1
+	modelTransport.mu.Unlock()
+
+	if i >= len(fixture.Responses) {
+		i = len(fixture.Responses) - 1
+	}
+	res := fixture.Responses[i]
+
+	log.Printf("Replaying model fixture %s (response %d/%d)\n", hash, i+1, len(fixture.Responses))
+
+	if res.StatusCode != 0 {
+		return nil, &HTTPError{
+			StatusCode: res.StatusCode,
+			Body:       res.Body,
+		}
+	}
+
+	return &ExtendedChatCompletionStream{
+		replayReader: &ReplayStreamReader{
+			chunks:      res.Chunks,
+			streamError: res.StreamError,
+		},
+		ctx: ctx,
+	}, nil
+}
+
+func recordChatCompletionStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	dir string,
+	ctx context.Context,
+	req types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	hash, reqJson, err := GetModelRequestHash(modelConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &streamRecorder{
+		dir: dir,
+		fixture: ModelFixture{
+			RequestHash: hash,
+			Provider:    modelConfig.BaseModelConfig.Provider,
+			ModelName:   modelConfig.BaseModelConfig.ModelName,
+			Request:     reqJson,
+		},
+	}
+
+	stream, err := createLiveChatCompletionStream(modelConfig, client, baseUrl, ctx, req)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			recorder.res.StatusCode = httpErr.StatusCode
+			recorder.res.Body = httpErr.Body
+			recorder.flush()
+		}
+		return nil, err
+	}
+
+	stream.recorder = recorder
+	return stream, nil
+}
+
+// streamRecorder collects the chunks received on a live stream and writes them to the request's fixture file when the stream ends or is closed
+type streamRecorder struct {
+	dir     string
+	fixture ModelFixture
+	res     ModelFixtureResponse
+	mu      sync.Mutex
+	flushed bool
+}
+
+func (r *streamRecorder) add(chunk *types.ExtendedChatCompletionStreamResponse, err error) {
+	if err != nil {
+		// a canceled stream is recorded as far as it got
+		if err != io.EOF && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			r.mu.Lock()
+			r.res.StreamError = err.Error()
+			r.mu.Unlock()
+		}
+		r.flush()
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.res.Chunks = append(r.res.Chunks, chunk)
+}
+
+func (r *streamRecorder) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.flushed {
+		return
+	}
+	r.flushed = true
+
+	hash := r.fixture.RequestHash
+	path := modelFixturePath(r.dir, hash)
+
+	modelTransport.mu.Lock()
+	defer modelTransport.mu.Unlock()
+
+	fixture := r.fixture
+	if modelTransport.recorded[hash] {
+		bytes, err := os.ReadFile(path)
+		if err == nil {
+			var existing ModelFixture
+			if err := json.Unmarshal(bytes, &existing); err == nil {
+				fixture.Responses = existing.Responses
+			}
+		}
+	}
+	fixture.Responses = append(fixture.Responses, r.res)
+	modelTransport.recorded[hash] = true
+
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		log.Printf("Error creating model fixtures dir %s: %v\n", r.dir, err)
+		return
+	}
+
+	bytes, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling model fixture %s: %v\n", hash, err)
+		return
+	}
+
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		log.Printf("Error writing model fixture %s: %v\n", path, err)
+		return
+	}
+
+	log.Printf("Recorded model fixture %s (response %d)\n", hash, len(fixture.Responses))
+}
+
+// ReplayStreamReader serves recorded chunks in order, then the recorded stream error or io.EOF
+type ReplayStreamReader struct {
+	chunks      []*types.ExtendedChatCompletionStreamResponse
+	streamError string
+	i           int
+}
+
+func (r *ReplayStreamReader) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	if r.i < len(r.chunks) {
+		chunk := r.chunks[r.i]
+		r.i++
+		return chunk, nil
+	}
+
+	if r.streamError != "" {
+		return nil, errors.New(r.streamError)
+	}
+
+	return nil, io.EOF
+}
+
+func (r *ReplayStreamReader) Close() error {
+	return nil
+}
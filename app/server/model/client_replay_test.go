@@ -0,0 +1,175 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"plandex-server/types"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func readStreamContent(t *testing.T, stream *ExtendedChatCompletionStream) (string, *openai.Usage) {
+	t.Helper()
+	defer stream.Close()
+
+	var content strings.Builder
+	var usage *openai.Usage
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+	}
+	return content.String(), usage
+}
+
+func TestModelTransportRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { SetModelTransport(ModelTransportLive, "") })
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:  shared.ModelProviderAnthropic,
+			ModelName: "claude-test",
+		},
+	}
+	req := types.ExtendedChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []types.ExtendedChatMessage{{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "hi"}}}},
+	}
+
+	// record
+	SetModelTransport(ModelTransportRecord, dir)
+	numRequests := 0
+	server := newFakeSSEServer(t, anthropicTestEvents, func(r *http.Request, body []byte) { numRequests++ })
+	stream, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recordedContent, recordedUsage := readStreamContent(t, stream)
+	server.Close()
+
+	hash, _, err := GetModelRequestHash(modelConfig, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(modelFixturePath(dir, hash)); err != nil {
+		t.Fatalf("expected fixture to be written: %v", err)
+	}
+
+	// replay with the server gone
+	SetModelTransport(ModelTransportReplay, dir)
+	stream, err = createChatCompletionStreamExtended(modelConfig, ClientInfo{}, server.URL, context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	replayedContent, replayedUsage := readStreamContent(t, stream)
+
+	if numRequests != 1 {
+		t.Errorf("expected 1 live request, got %d", numRequests)
+	}
+	if replayedContent != recordedContent || recordedContent != "Hello world" {
+		t.Errorf("recorded %q, replayed %q", recordedContent, replayedContent)
+	}
+	if replayedUsage == nil || recordedUsage == nil || *replayedUsage.PromptTokensDetails != *recordedUsage.PromptTokensDetails || replayedUsage.CompletionTokens != recordedUsage.CompletionTokens {
+		t.Errorf("recorded usage %+v, replayed usage %+v", recordedUsage, replayedUsage)
+	}
+
+	// a different request has no fixture
+	req.Messages[0].Content[0].Text = "bye"
+	_, err = createChatCompletionStreamExtended(modelConfig, ClientInfo{}, server.URL, context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "no model fixture") {
+		t.Errorf("expected missing fixture error, got %v", err)
+	}
+}
+
+func TestModelTransportReplaysErrorsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { SetModelTransport(ModelTransportLive, "") })
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:  shared.ModelProviderAnthropic,
+			ModelName: "claude-test",
+		},
+	}
+	req := types.ExtendedChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []types.ExtendedChatMessage{{Role: openai.ChatMessageRoleUser, Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "hi"}}}},
+	}
+
+	// first request is rate limited, the retry succeeds
+	SetModelTransport(ModelTransportRecord, dir)
+	numRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		if numRequests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+			return
+		}
+		sse := newFakeSSEServer(t, anthropicTestEvents, nil)
+		defer sse.Close()
+		resp, err := http.Post(sse.URL, "application/json", nil)
+		if err != nil {
+			t.Errorf("error proxying fake stream: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.Copy(w, resp.Body)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		stream, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{ApiKey: "test-key"}, server.URL, context.Background(), req)
+		if i == 0 {
+			if err == nil {
+				t.Fatalf("expected rate limit error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		readStreamContent(t, stream)
+	}
+
+	SetModelTransport(ModelTransportReplay, dir)
+
+	_, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{}, server.URL, context.Background(), req)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected replayed rate limit error, got %v", err)
+	}
+
+	stream, err := createChatCompletionStreamExtended(modelConfig, ClientInfo{}, server.URL, context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	content, _ := readStreamContent(t, stream)
+	if content != "Hello world" {
+		t.Errorf("replayed content = %q", content)
+	}
+
+	if numRequests != 2 {
+		t.Errorf("expected 2 live requests, got %d", numRequests)
+	}
+}
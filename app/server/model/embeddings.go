@@ -0,0 +1,165 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"plandex-server/types"
+	shared "plandex-shared"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	embedBatchSize = 128
+
+	// keeps inputs under embedding models' ~8k token limit
+	maxEmbedInputChars = 16000
+)
+
+type EmbedderParams struct {
+	Clients   map[string]ClientInfo
+	Auth      *types.ServerAuth
+	Plan      *db.Plan
+	Provider  shared.ModelProvider
+	ModelName shared.ModelName
+	Purpose   string
+	SessionId string
+}
+
+// Embedder creates embeddings through the provider's OpenAI-compatible embeddings endpoint. Like ModelRequest, each request goes through the WillSendModelRequest and DidSendModelRequest hooks so it's checked against spend budgets and recorded in usage.
+type Embedder struct {
+	params EmbedderParams
+	client ClientInfo
+}
+
+func NewEmbedder(params EmbedderParams) (*Embedder, error) {
+	if params.Purpose == "" {
+		return nil, fmt.Errorf("purpose is required")
+	}
+
+	if os.Getenv("IS_CLOUD") != "" && params.Provider.IsSelfHostedOnly() {
+		return nil, fmt.Errorf("%s embeddings aren't available on Plandex Cloud", params.Provider)
+	}
+
+	envVar, ok := shared.ApiKeyByProvider[params.Provider]
+	if !ok || params.Provider == shared.ModelProviderAnthropic {
+		return nil, fmt.Errorf("%s doesn't serve embeddings", params.Provider)
+	}
+
+	client, ok := params.Clients[envVar]
+	if !ok {
+		return nil, fmt.Errorf("no %s client for embeddings--%s isn't set", params.Provider, envVar)
+	}
+
+	return &Embedder{params: params, client: client}, nil
+}
+
+func (e *Embedder) Model() string {
+	return string(e.params.ModelName)
+}
+
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var res [][]float32
+
+	for i := 0; i < len(texts); i += embedBatchSize {
+		batch := texts[i:min(i+embedBatchSize, len(texts))]
+
+		vectors, err := e.embedBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, vectors...)
+	}
+
+	return res, nil
+}
+
+func (e *Embedder) embedBatch(ctx context.Context, batch []string) ([][]float32, error) {
+	auth := e.params.Auth
+	plan := e.params.Plan
+	modelName := e.params.ModelName
+
+	inputs := make([]string, len(batch))
+	inputTokensEstimate := 0
+	for i, text := range batch {
+		if len(text) > maxEmbedInputChars {
+			text = strings.ToValidUTF8(text[:maxEmbedInputChars], "")
+		}
+		inputs[i] = text
+		inputTokensEstimate += shared.GetNumTokensEstimate(text)
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+		Auth: auth,
+		Plan: plan,
+		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
+			InputTokens: inputTokensEstimate,
+			ModelName:   modelName,
+		},
+	})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	reqStarted := time.Now()
+
+	resp, err := e.client.Client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: inputs,
+		Model: openai.EmbeddingModel(modelName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating embeddings with %s: %v", modelName, err)
+	}
+
+	inputTokens := resp.Usage.PromptTokens
+	noReportedUsage := inputTokens == 0
+	if noReportedUsage {
+		inputTokens = inputTokensEstimate
+	}
+
+	var planId string
+	if plan != nil {
+		planId = plan.Id
+	}
+
+	go func() {
+		_, apiErr := hooks.ExecHook(hooks.DidSendModelRequest, hooks.HookParams{
+			Auth: auth,
+			Plan: plan,
+			DidSendModelRequestParams: &hooks.DidSendModelRequestParams{
+				InputTokens:      inputTokens,
+				ModelName:        modelName,
+				ModelProvider:    e.params.Provider,
+				Purpose:          e.params.Purpose,
+				PlanId:           planId,
+				SessionId:        e.params.SessionId,
+				NoReportedUsage:  noReportedUsage,
+				RequestStartedAt: reqStarted,
+			},
+		})
+
+		if apiErr != nil {
+			log.Printf("Embed - error executing DidSendModelRequest hook: %v", apiErr)
+		}
+	}()
+
+	if len(resp.Data) != len(batch) {
+		return nil, fmt.Errorf("expected %d embeddings from %s, got %d", len(batch), modelName, len(resp.Data))
+	}
+
+	vectors := make([][]float32, len(batch))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(batch) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+
+	return vectors, nil
+}
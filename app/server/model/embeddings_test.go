@@ -0,0 +1,176 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/hooks"
+	"sync"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestEmbedderRunsModelRequestHooks(t *testing.T) {
+	numRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		var req openai.EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("error decoding request: %v", err)
+		}
+		inputs, _ := req.Input.([]any)
+
+		res := openai.EmbeddingResponse{Usage: openai.Usage{PromptTokens: 7 * len(inputs)}}
+		for i := range inputs {
+			res.Data = append(res.Data, openai.Embedding{Index: i, Embedding: []float32{float32(i), 1}})
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var willSend []*hooks.WillSendModelRequestParams
+	var didSend []*hooks.DidSendModelRequestParams
+	done := make(chan struct{}, embedBatchSize)
+
+	hooks.RegisterHook(hooks.WillSendModelRequest, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+		mu.Lock()
+		defer mu.Unlock()
+		willSend = append(willSend, params.WillSendModelRequestParams)
+		return hooks.HookResult{}, nil
+	})
+	hooks.RegisterHook(hooks.DidSendModelRequest, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+		mu.Lock()
+		defer mu.Unlock()
+		didSend = append(didSend, params.DidSendModelRequestParams)
+		done <- struct{}{}
+		return hooks.HookResult{}, nil
+	})
+	t.Cleanup(func() {
+		noop := func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) { return hooks.HookResult{}, nil }
+		hooks.RegisterHook(hooks.WillSendModelRequest, noop)
+		hooks.RegisterHook(hooks.DidSendModelRequest, noop)
+	})
+
+	embedder, err := NewEmbedder(EmbedderParams{
+		Clients:   map[string]ClientInfo{shared.OpenAIEnvVar: newClient("test-key", server.URL, "")},
+		Provider:  shared.ModelProviderOpenAI,
+		ModelName: "text-embedding-3-small",
+		Purpose:   "Semantic index embeddings",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	texts := make([]string, embedBatchSize+1)
+	for i := range texts {
+		texts[i] = "func f() {}"
+	}
+
+	vectors, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		t.Fatalf("expected %d vectors, got %d", len(texts), len(vectors))
+	}
+	if vectors[1][0] != 1 || vectors[embedBatchSize][0] != 0 {
+		t.Errorf("vectors aren't in input order: %v, %v", vectors[1], vectors[embedBatchSize])
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for DidSendModelRequest hook")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if numRequests != 2 || len(willSend) != 2 || len(didSend) != 2 {
+		t.Fatalf("expected 2 requests with hooks, got %d requests, %d will send, %d did send", numRequests, len(willSend), len(didSend))
+	}
+	if willSend[0].ModelName != "text-embedding-3-small" || willSend[0].InputTokens == 0 {
+		t.Errorf("unexpected will send params: %+v", willSend[0])
+	}
+
+	inputTokens := map[int]bool{}
+	for _, params := range didSend {
+		inputTokens[params.InputTokens] = true
+		if params.ModelProvider != shared.ModelProviderOpenAI || params.Purpose != "Semantic index embeddings" || params.NoReportedUsage {
+			t.Errorf("unexpected did send params: %+v", params)
+		}
+	}
+	if !inputTokens[7*embedBatchSize] || !inputTokens[7] {
+		t.Errorf("expected reported usage for each batch, got %v", inputTokens)
+	}
+}
+
+func TestEmbedderStopsWhenHookRejects(t *testing.T) {
+	numRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+	}))
+	defer server.Close()
+
+	hooks.RegisterHook(hooks.WillSendModelRequest, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+		return hooks.HookResult{}, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusPaymentRequired, Msg: "Spend budget exceeded"}
+	})
+	t.Cleanup(func() {
+		hooks.RegisterHook(hooks.WillSendModelRequest, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) { return hooks.HookResult{}, nil })
+	})
+
+	embedder, err := NewEmbedder(EmbedderParams{
+		Clients:   map[string]ClientInfo{shared.OpenAIEnvVar: newClient("test-key", server.URL, "")},
+		Provider:  shared.ModelProviderOpenAI,
+		ModelName: "text-embedding-3-small",
+		Purpose:   "Semantic index embeddings",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = embedder.Embed(context.Background(), []string{"query"})
+	if err == nil {
+		t.Fatal("expected the hook's error")
+	}
+	if numRequests != 0 {
+		t.Errorf("expected no embeddings request, got %d", numRequests)
+	}
+}
+
+func TestNewEmbedderRequiresProviderClient(t *testing.T) {
+	clients := map[string]ClientInfo{shared.OpenAIEnvVar: newClient("test-key", "", "")}
+
+	tests := []struct {
+		name     string
+		provider shared.ModelProvider
+		wantErr  bool
+	}{
+		{"openai", shared.ModelProviderOpenAI, false},
+		{"missing key", shared.ModelProviderOpenRouter, true},
+		{"no embeddings", shared.ModelProviderAnthropic, true},
+		{"unknown provider", "custom", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEmbedder(EmbedderParams{
+				Clients:   clients,
+				Provider:  tt.provider,
+				ModelName: "text-embedding-3-small",
+				Purpose:   "Semantic index embeddings",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEmbedder(%s) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -69,6 +69,7 @@ func ClassifyModelError(code int, message string, headers http.Header) shared.Mo
 		case strings.Contains(msg, "maximum context length") ||
 			strings.Contains(msg, "context length exceeded") ||
 			strings.Contains(msg, "too many tokens") ||
+			strings.Contains(msg, "prompt is too long") ||
 			strings.Contains(msg, "payload too large"):
 			res = shared.ModelError{
 				Kind:              shared.ErrContextTooLong,
@@ -40,6 +40,9 @@ type ModelRequestParams struct {
 
 	OnStream func(string, string) bool
 
+	// called with any spend budget warning from the WillSendModelRequest hook
+	OnWarning func(string)
+
 	WillCacheNumTokens int
 }
 
@@ -86,7 +89,7 @@ func ModelRequest(
 		expectedOutputTokens = params.EstimatedOutputTokens
 	}
 
-	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+	hookRes, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
 		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
@@ -100,6 +103,13 @@ func ModelRequest(
 		return nil, apiErr
 	}
 
+	if hookRes.WillSendModelRequestResult != nil && hookRes.WillSendModelRequestResult.Warning != "" {
+		log.Printf("ModelRequest - %s\n", hookRes.WillSendModelRequestResult.Warning)
+		if params.OnWarning != nil {
+			params.OnWarning(hookRes.WillSendModelRequestResult.Warning)
+		}
+	}
+
 	if params.BeforeReq != nil {
 		params.BeforeReq()
 	}
@@ -179,11 +189,15 @@ func ModelRequest(
 	var inputTokens int
 	var outputTokens int
 	var cachedTokens int
+	var reasoningTokens int
 
 	if res.Usage != nil {
 		if res.Usage.PromptTokensDetails != nil {
 			cachedTokens = res.Usage.PromptTokensDetails.CachedTokens
 		}
+		if res.Usage.CompletionTokensDetails != nil {
+			reasoningTokens = res.Usage.CompletionTokensDetails.ReasoningTokens
+		}
 		inputTokens = res.Usage.PromptTokens
 		outputTokens = res.Usage.CompletionTokens
 	} else {
@@ -200,20 +214,21 @@ func ModelRequest(
 			Auth: auth,
 			Plan: plan,
 			DidSendModelRequestParams: &hooks.DidSendModelRequestParams{
-				InputTokens:    inputTokens,
-				OutputTokens:   outputTokens,
-				CachedTokens:   cachedTokens,
-				ModelId:        modelConfig.BaseModelConfig.ModelId,
-				ModelName:      modelConfig.BaseModelConfig.ModelName,
-				ModelProvider:  modelConfig.BaseModelConfig.Provider,
-				ModelPackName:  modelPackName,
-				ModelRole:      modelConfig.Role,
-				Purpose:        purpose,
-				GenerationId:   res.GenerationId,
-				PlanId:         plan.Id,
-				ModelStreamId:  modelStreamId,
-				ConvoMessageId: convoMessageId,
-				BuildId:        buildId,
+				InputTokens:     inputTokens,
+				OutputTokens:    outputTokens,
+				CachedTokens:    cachedTokens,
+				ReasoningTokens: reasoningTokens,
+				ModelId:         modelConfig.BaseModelConfig.ModelId,
+				ModelName:       modelConfig.BaseModelConfig.ModelName,
+				ModelProvider:   modelConfig.BaseModelConfig.Provider,
+				ModelPackName:   modelPackName,
+				ModelRole:       modelConfig.Role,
+				Purpose:         purpose,
+				GenerationId:    res.GenerationId,
+				PlanId:          plan.Id,
+				ModelStreamId:   modelStreamId,
+				ConvoMessageId:  convoMessageId,
+				BuildId:         buildId,
 
 				RequestStartedAt: reqStarted,
 				Streaming:        true,
@@ -3,6 +3,7 @@ package plan
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"plandex-server/db"
 	"plandex-server/host"
 	"plandex-server/model"
@@ -32,7 +33,7 @@ func activatePlan(
 	active := GetActivePlan(plan.Id, branch)
 	if active != nil {
 		log.Printf("Tell: Active plan found for plan ID %s on branch %s\n", plan.Id, branch) // Log if an active plan is found
-		return nil, fmt.Errorf("plan %s branch %s already has an active stream on this host", plan.Id, branch)
+		return nil, planStreamActiveErr(plan, branch, &active.UserId)
 	}
 
 	modelStream, err := db.GetActiveModelStream(plan.Id, branch)
@@ -41,43 +42,84 @@ func activatePlan(
 		return nil, fmt.Errorf("error getting active model stream: %v", err)
 	}
 
+	if modelStream != nil && modelStream.Orphaned() && modelStream.UserId != nil && *modelStream.UserId == auth.User.Id {
+		// the user's own stream is waiting to be resumed after its instance stopped--starting over replaces it
+		log.Printf("Tell: Replacing orphaned model stream %s for plan ID %s on branch %s\n", modelStream.Id, plan.Id, branch)
+		finished, err := db.FinishOrphanedModelStream(modelStream.Id)
+		if err != nil {
+			log.Printf("Error finishing orphaned model stream: %v\n", err)
+			return nil, fmt.Errorf("error finishing orphaned model stream: %v", err)
+		}
+		if finished {
+			modelStream = nil
+		}
+	}
+
 	if modelStream != nil {
 		log.Printf("Tell: Active model stream found for plan ID %s on branch %s on host %s\n", plan.Id, branch, modelStream.InternalIp) // Log if an active model stream is found
-		return nil, fmt.Errorf("plan %s branch %s already has an active stream on host %s", plan.Id, branch, modelStream.InternalIp)
+		return nil, planStreamActiveErr(plan, branch, modelStream.UserId)
 	}
 
-	active = CreateActivePlan(
-		auth.OrgId,
-		auth.User.Id,
-		plan.Id,
-		branch,
-		prompt,
-		buildOnly,
-		autoContext,
-		sessionId,
-	)
-
+	// claim the branch before activating--if a teammate's tell on another host claimed it since the check above, the insert fails and their stream is left alone
 	modelStream = &db.ModelStream{
 		OrgId:      auth.OrgId,
 		PlanId:     plan.Id,
+		UserId:     &auth.User.Id,
 		InternalIp: host.Ip,
 		Branch:     branch,
 	}
-	err = db.StoreModelStream(modelStream, active.Ctx, active.CancelFn)
-	if err != nil {
+	err = db.StoreModelStream(modelStream)
+	if err == db.ErrModelStreamActive {
+		log.Printf("Tell: Model stream claimed concurrently for plan ID %s on branch %s\n", plan.Id, branch)
+		return nil, planStreamActiveErr(plan, branch, nil)
+	} else if err != nil {
 		log.Printf("Tell: Error storing model stream for plan ID %s on branch %s: %v\n", plan.Id, branch, err) // Log error storing model stream
-		log.Printf("Error storing model stream: %v\n", err)
-		log.Printf("Tell: Error storing model stream: %v\n", err) // Log error storing model stream
-
-		active.StreamDoneCh <- &shared.ApiError{Msg: fmt.Sprintf("Error storing model stream: %v", err)}
-
 		return nil, fmt.Errorf("error storing model stream: %v", err)
 	}
 
-	active.ModelStreamId = modelStream.Id
+	active = startActivePlan(modelStream, auth, prompt, buildOnly, autoContext, sessionId)
 
 	log.Printf("Tell: Model stream stored with ID %s for plan ID %s on branch %s\n", modelStream.Id, plan.Id, branch) // Log successful storage of model stream
 	log.Println("Model stream id:", modelStream.Id)
 
 	return active, nil
 }
+
+// startActivePlan runs the plan on this instance for a model stream it has claimed
+func startActivePlan(modelStream *db.ModelStream, auth *types.ServerAuth, prompt string, buildOnly, autoContext bool, sessionId string) *types.ActivePlan {
+	active := CreateActivePlan(
+		auth.OrgId,
+		auth.User.Id,
+		modelStream.PlanId,
+		modelStream.Branch,
+		prompt,
+		buildOnly,
+		autoContext,
+		sessionId,
+	)
+
+	db.StartModelStreamHeartbeat(modelStream, active.Ctx, active.CancelFn)
+
+	active.ModelStreamId = modelStream.Id
+
+	return active
+}
+
+// planStreamActiveErr tells the caller who's using the branch, so a collaborator knows to wait or connect rather than retry
+func planStreamActiveErr(plan *db.Plan, branch string, userId *string) *shared.ApiError {
+	who := "Another stream"
+	if userId != nil {
+		user, err := db.GetUser(*userId)
+		if err != nil {
+			log.Printf("Error getting user for active stream: %v\n", err)
+		} else if user != nil {
+			who = fmt.Sprintf("%s (%s)", user.Name, user.Email)
+		}
+	}
+
+	return &shared.ApiError{
+		Type:   shared.ApiErrorTypePlanStreamActive,
+		Status: http.StatusConflict,
+		Msg:    fmt.Sprintf("%s is already running on plan %s, branch %s. Wait for it to finish or use 'plandex connect' to follow it.", who, plan.Name, branch),
+	}
+}
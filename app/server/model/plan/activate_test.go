@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"plandex-server/shutdown"
+	"plandex-server/types"
+	"sync"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestActivatePlanClaimsBranchOnce(t *testing.T) {
+	dbtest.Setup(t)
+
+	if shutdown.ShutdownCtx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		shutdown.ShutdownCtx = ctx
+		t.Cleanup(func() {
+			cancel()
+			shutdown.ShutdownCtx = nil
+		})
+	}
+
+	org := dbtest.CreateOrg(t)
+	collaborator := org.AddMember(t, "collaborator")
+	org.SharePlan(t, collaborator.Id, shared.PlanShareAccessCollaborator)
+
+	users := []*db.User{org.Owner, collaborator}
+	actives := make([]*types.ActivePlan, len(users))
+	errs := make([]error, len(users))
+
+	// both tells pass the in-memory and model stream checks during activatePlan's wait, so only the model_streams_active_idx claim can stop the second
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(i int, user *db.User) {
+			defer wg.Done()
+			auth := &types.ServerAuth{User: user, OrgId: org.Org.Id}
+			actives[i], errs[i] = activatePlan(nil, org.Plan, "main", auth, "prompt", false, false, "")
+		}(i, user)
+	}
+	wg.Wait()
+
+	var active *types.ActivePlan
+	var rejected error
+	for i := range users {
+		if errs[i] == nil {
+			if active != nil {
+				t.Fatal("both tells activated the same branch")
+			}
+			active = actives[i]
+		} else {
+			rejected = errs[i]
+		}
+	}
+
+	if active == nil {
+		t.Fatalf("neither tell activated the branch: %v", errs)
+	}
+	t.Cleanup(func() {
+		active.CancelFn()
+		waitForInactive(t, org.Plan.Id, "main")
+	})
+
+	var apiErr *shared.ApiError
+	if !errors.As(rejected, &apiErr) || apiErr.Type != shared.ApiErrorTypePlanStreamActive || apiErr.Status != http.StatusConflict {
+		t.Fatalf("expected a plan stream active conflict, got %v", rejected)
+	}
+
+	stream, err := db.GetActiveModelStream(org.Plan.Id, "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stream == nil || stream.Id != active.ModelStreamId || stream.UserId == nil || *stream.UserId != active.UserId {
+		t.Errorf("expected the active model stream to belong to the activated tell, got %+v", stream)
+	}
+}
+
+// waitForInactive waits for a cancelled active plan to clean up before the test's plans directory is removed
+func waitForInactive(t *testing.T, planId, branch string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for GetActivePlan(planId, branch) != nil {
+		if time.Now().After(deadline) {
+			t.Errorf("active plan wasn't cleaned up")
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,57 @@
+package plan
+
+import (
+	"context"
+	"log"
+	"plandex-server/types"
+)
+
+// each round checks the file with the CLI's language server or compiler command, then gives the builder one attempt at fixing what it found
+const MaxDiagnosticsFixAttempts = 2
+
+// fixDiagnostics runs after a file is built and syntax-valid. If the connected CLI can type check the file, any errors the build introduced go back to the builder to fix. Diagnostics are best effort--if the check fails or errors remain after the last attempt, the latest content is kept and the build continues.
+func (fileState *activeBuildStreamFileState) fixDiagnostics(ctx context.Context, activePlan *types.ActivePlan, updated string) (string, error) {
+	filePath := fileState.filePath
+	originalFile := fileState.preBuildState
+
+	if !activePlan.DiagnosticsEnabledForPath(filePath) {
+		return updated, nil
+	}
+
+	for attempt := 0; attempt < MaxDiagnosticsFixAttempts; attempt++ {
+		diagnostics, err := activePlan.RequestDiagnostics(filePath, originalFile, updated)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			log.Printf("fixDiagnostics - %s - skipping diagnostics: %v\n", filePath, err)
+			return updated, nil
+		}
+
+		log.Printf("fixDiagnostics - %s - attempt %d - %d new errors\n", filePath, attempt+1, len(diagnostics))
+
+		if len(diagnostics) == 0 {
+			return updated, nil
+		}
+
+		fileState.builderRun.DiagnosticsErrors = append(fileState.builderRun.DiagnosticsErrors, diagnostics...)
+
+		res, err := fileState.buildValidateLoop(ctx, buildValidateLoopParams{
+			originalFile:    originalFile,
+			updated:         updated,
+			proposedContent: fileState.activeBuild.FileContent,
+			desc:            fileState.activeBuild.FileDescription,
+			diagnostics:     diagnostics,
+			maxAttempts:     1,
+			sessionId:       activePlan.SessionId,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		updated = res.updated
+	}
+
+	// the last fix attempt wasn't checked--it still passed the syntax check in buildValidateLoop, so keep it
+	return updated, nil
+}
@@ -0,0 +1,130 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"plandex-server/db"
+	"plandex-server/model"
+	"plandex-server/shutdown"
+	"plandex-server/syntax"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+type EvalBuildValidateParams struct {
+	Clients      map[string]model.ClientInfo
+	Settings     *shared.PlanSettings
+	FilePath     string
+	Original     string
+	Updated      string
+	Proposed     string
+	Desc         string
+	Reasons      []syntax.NeedsVerifyReason
+	SyntaxErrors []string
+}
+
+type EvalBuildValidateResult struct {
+	Valid   bool
+	Updated string
+	Problem string
+}
+
+const evalBranch = "main"
+
+// EvalBuildValidate runs the builder's validate-and-fix loop on a single file outside of a plan, so the evals harness can score it against recorded or live model responses. Each call gets its own in-memory plan, build and active plan, so failed model requests are retried as they are in a real build. Nothing is written to the database, and usage hooks are skipped since there's no auth.
+func EvalBuildValidate(ctx context.Context, params EvalBuildValidateParams) (*EvalBuildValidateResult, error) {
+	if shutdown.ShutdownCtx == nil {
+		return nil, fmt.Errorf("shutdown context isn't set")
+	}
+
+	parser, language, fallbackParser, fallbackLanguage := syntax.GetParserForPath(params.FilePath)
+
+	now := time.Now()
+	planConfig := shared.DefaultPlanConfig
+	evalPlan := &db.Plan{
+		Id:         uuid.New().String(),
+		OrgId:      uuid.New().String(),
+		OwnerId:    uuid.New().String(),
+		ProjectId:  uuid.New().String(),
+		Name:       "evals",
+		PlanConfig: &planConfig,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	build := &db.PlanBuild{
+		Id:             uuid.New().String(),
+		OrgId:          evalPlan.OrgId,
+		PlanId:         evalPlan.Id,
+		ConvoMessageId: uuid.New().String(),
+		FilePath:       params.FilePath,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	// registered directly rather than with CreateActivePlan, which tracks the plan's status in the database
+	activePlan := types.NewActivePlan(evalPlan.OrgId, evalPlan.OwnerId, evalPlan.Id, evalBranch, "", true, false, "")
+	activePlanKey := strings.Join([]string{evalPlan.Id, evalBranch}, "|")
+	activePlans.Set(activePlanKey, activePlan)
+	defer func() {
+		activePlans.Delete(activePlanKey)
+		activePlan.CancelFn()
+	}()
+
+	fileState := &activeBuildStreamFileState{
+		activeBuildStreamState: &activeBuildStreamState{
+			clients:       params.Clients,
+			currentOrgId:  evalPlan.OrgId,
+			currentUserId: evalPlan.OwnerId,
+			plan:          evalPlan,
+			branch:        evalBranch,
+			settings:      params.Settings,
+			modelStreamId: uuid.New().String(),
+		},
+		filePath:       params.FilePath,
+		convoMessageId: build.ConvoMessageId,
+		build:          build,
+		parser:         parser,
+		language:       language,
+	}
+
+	// resolve the parser from the original file, as loadBuildFile does
+	if parser != nil {
+		validationRes, err := syntax.ValidateWithParsers(ctx, language, parser, fallbackLanguage, fallbackParser, params.Original)
+		if err != nil {
+			return nil, fmt.Errorf("error validating original file syntax: %v", err)
+		}
+
+		fileState.language = validationRes.Lang
+		fileState.parser = validationRes.Parser
+
+		if validationRes.TimedOut {
+			fileState.syntaxCheckTimedOut = true
+		} else if !validationRes.Valid {
+			fileState.preBuildStateSyntaxInvalid = true
+		}
+	}
+
+	res, err := fileState.buildValidateLoop(ctx, buildValidateLoopParams{
+		originalFile:    params.Original,
+		updated:         params.Updated,
+		proposedContent: params.Proposed,
+		desc:            params.Desc,
+		syntaxErrors:    params.SyntaxErrors,
+		reasons:         params.Reasons,
+		isInitial:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalBuildValidateResult{
+		Valid:   res.valid,
+		Updated: res.updated,
+		Problem: res.problem,
+	}, nil
+}
@@ -22,6 +22,7 @@ func Build(
 	branch string,
 	auth *types.ServerAuth,
 	sessionId string,
+	diagnosticsExts []string,
 ) (int, error) {
 	log.Printf("Build: Called with plan ID %s on branch %s\n", plan.Id, branch)
 	log.Println("Build: Starting Build operation")
@@ -50,6 +51,10 @@ func Build(
 
 	pendingBuildsByPath, err := state.loadPendingBuilds(sessionId)
 	if err != nil {
+		// the branch belongs to someone else's stream, so there's nothing of ours to finish
+		if apiErr, ok := err.(*shared.ApiError); ok && apiErr.Type == shared.ApiErrorTypePlanStreamActive {
+			return 0, err
+		}
 		return onErr(err)
 	}
 
@@ -66,6 +71,10 @@ func Build(
 		return onErr(fmt.Errorf("error setting plan status to building: %v", err))
 	}
 
+	UpdateActivePlan(plan.Id, branch, func(active *types.ActivePlan) {
+		active.DiagnosticsExts = diagnosticsExts
+	})
+
 	log.Printf("Starting %d builds\n", len(pendingBuildsByPath))
 
 	for _, pendingBuilds := range pendingBuildsByPath {
@@ -9,6 +9,7 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/notify"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -233,6 +234,16 @@ func (fileState *activeBuildStreamFileState) onFinishBuildFile(planRes *db.PlanF
 		DidFinishBuilderRunParams: &fileState.builderRun,
 	})
 
+	webhooks.Trigger(webhooks.TriggerParams{
+		Event:    shared.WebhookEventBuildFinished,
+		OrgId:    fileState.auth.OrgId,
+		UserId:   fileState.auth.User.Id,
+		PlanId:   fileState.plan.Id,
+		PlanName: fileState.plan.Name,
+		Branch:   fileState.branch,
+		Path:     filePath,
+	})
+
 	log.Printf("Finished building file %s - setting activeBuild.Success to true\n", filePath)
 	// log.Println(spew.Sdump(activeBuild))
 
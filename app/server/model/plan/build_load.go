@@ -22,6 +22,7 @@ func (state *activeBuildStreamState) loadPendingBuilds(sessionId string) (map[st
 
 	if err != nil {
 		log.Printf("Error activating plan: %v\n", err)
+		return nil, err
 	}
 
 	modelStreamId := active.ModelStreamId
@@ -98,7 +99,8 @@ func (state *activeBuildStreamState) loadPendingBuilds(sessionId string) (map[st
 	UpdateActivePlan(plan.Id, branch, func(ap *types.ActivePlan) {
 		ap.Contexts = modelContext
 		for _, context := range modelContext {
-			if context.FilePath != "" {
+			// symbols are only part of a file, so they can't be the base for a build
+			if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 				ap.ContextsByPath[context.FilePath] = context
 			}
 		}
@@ -165,6 +165,18 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 		updated = buildRaceResult.content
 	}
 
+	// buildCtx is canceled once the race finishes, so this uses the plan's context
+	updated, err := fileState.fixDiagnostics(activePlan.Ctx, activePlan, updated)
+	if err != nil {
+		if apiErr, ok := err.(*shared.ApiError); ok {
+			activePlan.StreamDoneCh <- apiErr
+			return
+		}
+		log.Printf("buildStructuredEdits - %s - error fixing diagnostics: %v\n", filePath, err)
+		fileState.onBuildFileError(fmt.Errorf("error fixing diagnostics: %v", err))
+		return
+	}
+
 	// output diff and store build results
 	buildInfo := &shared.BuildInfo{
 		Path:      filePath,
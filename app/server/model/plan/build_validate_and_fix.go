@@ -28,6 +28,7 @@ type buildValidateLoopParams struct {
 	proposedContent            string
 	desc                       string
 	syntaxErrors               []string
+	diagnostics                []string
 	reasons                    []syntax.NeedsVerifyReason
 	initialPhaseOnStream       func(chunk string, buffer string) bool
 	validateOnlyOnFinalAttempt bool
@@ -87,8 +88,10 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 		}
 
 		var reasons []syntax.NeedsVerifyReason
+		var diagnostics []string
 		if numAttempts == 0 {
 			reasons = params.reasons
+			diagnostics = params.diagnostics
 			log.Printf("Using initial reasons for validation")
 		} else {
 			reasons = []syntax.NeedsVerifyReason{}
@@ -112,6 +115,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 			desc:            desc,
 			onStream:        onStream,
 			syntaxErrors:    syntaxErrors,
+			diagnostics:     diagnostics,
 			reasons:         reasons,
 			modelConfig:     &modelConfig,
 			validateOnly:    isLastAttempt && params.validateOnlyOnFinalAttempt,
@@ -165,6 +169,7 @@ type buildValidateParams struct {
 	proposedContent string
 	desc            string
 	syntaxErrors    []string
+	diagnostics     []string
 	reasons         []syntax.NeedsVerifyReason
 	onStream        func(chunk string, buffer string) bool
 	phase           int
@@ -197,6 +202,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 	desc := params.desc
 	onStream := params.onStream
 	syntaxErrors := params.syntaxErrors
+	diagnostics := params.diagnostics
 	reasons := params.reasons
 	// Get diff for validation
 	log.Printf("Getting diffs between original and updated content")
@@ -227,6 +233,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 			ProposedWithLineNums: proposedWithLineNums,
 			Diff:                 diff,
 			SyntaxErrors:         syntaxErrors,
+			Diagnostics:          diagnostics,
 			Reasons:              reasons,
 		})
 
@@ -253,6 +260,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 			ProposedWithLineNums: proposedWithLineNums,
 			Diff:                 diff,
 			SyntaxErrors:         syntaxErrors,
+			Diagnostics:          diagnostics,
 			Reasons:              reasons,
 		})
 	}
@@ -338,6 +346,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 			fileState.builderRun.ReplacementFinishedAt = time.Now()
 		},
 		OnStream:              onStream,
+		OnWarning:             streamBudgetWarning(fileState.plan, fileState.branch),
 		Tools:                 tools,
 		ToolChoice:            toolChoice,
 		WillCacheNumTokens:    willCacheNumTokens,
@@ -119,6 +119,7 @@ func (fileState *activeBuildStreamFileState) buildWholeFileFallback(buildCtx con
 		ModelStreamId:  fileState.modelStreamId,
 		ConvoMessageId: fileState.convoMessageId,
 		BuildId:        fileState.build.Id,
+		OnWarning:      streamBudgetWarning(fileState.plan, fileState.branch),
 
 		BeforeReq: func() {
 			fileState.builderRun.BuiltWholeFile = true
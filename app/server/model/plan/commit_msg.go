@@ -95,6 +95,7 @@ func (state *activeTellStreamState) genPlanDescription() (*db.ConvoMessageDescri
 		ModelStreamId:  state.modelStreamId,
 		ConvoMessageId: state.replyId,
 		SessionId:      activePlan.SessionId,
+		OnWarning:      activePlan.StreamBudgetWarning,
 	}
 
 	if tools != nil {
@@ -167,6 +167,7 @@ func (state *activeTellStreamState) execStatusShouldContinue(currentMessage stri
 		ModelStreamId:  state.modelStreamId,
 		ConvoMessageId: state.replyId,
 		SessionId:      sessionId,
+		OnWarning:      streamBudgetWarning(plan, state.branch),
 	})
 
 	if err != nil {
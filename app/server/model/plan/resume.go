@@ -0,0 +1,72 @@
+package plan
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/host"
+	"plandex-server/model"
+	"plandex-server/types"
+
+	shared "plandex-shared"
+)
+
+// A tell stream whose instance stops (usually a restart) is taken over by the instance its client reconnects to. That instance claims the orphaned model stream and continues the plan from its stored convo, like 'plandex continue'. The reply that was streaming when the instance stopped is lost, so the client is sent the plan's current state and the continued reply streams from there.
+
+// ResumeOrphanedStream takes over an orphaned tell stream and continues the plan on this instance. It returns db.ErrModelStreamNotOrphaned if another instance claimed the stream first or its own instance came back.
+func ResumeOrphanedStream(clients map[string]model.ClientInfo, plan *db.Plan, branch string, auth *types.ServerAuth, modelStream *db.ModelStream) error {
+	if modelStream.ResumeRequest == nil {
+		return fmt.Errorf("model stream %s can't be resumed", modelStream.Id)
+	}
+
+	if GetActivePlan(plan.Id, branch) != nil {
+		return db.ErrModelStreamNotOrphaned
+	}
+
+	claimed, err := db.ClaimOrphanedModelStream(modelStream.Id, host.Ip)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Resume: Claimed orphaned model stream %s for plan ID %s on branch %s from host %s\n", claimed.Id, plan.Id, branch, modelStream.InternalIp)
+
+	req := resumedTellRequest(claimed.ResumeRequest, auth)
+
+	active := startActivePlan(claimed, auth, "", false, req.AutoContext, req.SessionId)
+	active.DiagnosticsExts = req.DiagnosticsExts
+
+	go execTellPlan(execTellPlanParams{
+		clients:            clients,
+		plan:               plan,
+		branch:             branch,
+		auth:               auth,
+		req:                req,
+		iteration:          0,
+		shouldBuildPending: !req.IsChatOnly && req.BuildMode == shared.BuildModeAuto,
+	})
+
+	return nil
+}
+
+// resumableTellRequest is what's stored to resume a tell--credentials are left out since every instance can read it, and the reconnecting client sends its own
+func resumableTellRequest(req *shared.TellPlanRequest) *shared.TellPlanRequest {
+	res := *req
+	res.ApiKey = ""
+	res.ApiKeys = nil
+	res.OpenAIOrgId = ""
+	return &res
+}
+
+// resumedTellRequest continues the plan where the stored convo left off--the original prompt is already in the convo
+func resumedTellRequest(stored *shared.TellPlanRequest, auth *types.ServerAuth) *shared.TellPlanRequest {
+	req := *stored
+	req.Prompt = ""
+	req.IsUserContinue = true
+	req.ConnectStream = false
+
+	if req.ExecEnabled && !auth.HasPermission(shared.PermissionExecCommands) {
+		req.ExecEnabled = false
+	}
+
+	return &req
+}
@@ -0,0 +1,241 @@
+package plan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"plandex-server/db"
+	"plandex-server/db/dbtest"
+	"plandex-server/hooks"
+	"plandex-server/host"
+	"plandex-server/shutdown"
+	"plandex-server/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestResumableTellRequest(t *testing.T) {
+	req := &shared.TellPlanRequest{
+		Prompt:      "add tests",
+		BuildMode:   shared.BuildModeAuto,
+		ExecEnabled: true,
+		ApiKey:      "sk-legacy",
+		ApiKeys:     map[string]string{"OPENAI_API_KEY": "sk-test"},
+		OpenAIBase:  "https://proxy.example.com/v1",
+		OpenAIOrgId: "org-1",
+		SessionId:   "session-1",
+	}
+
+	stored := resumableTellRequest(req)
+	if stored.ApiKey != "" || stored.ApiKeys != nil || stored.OpenAIOrgId != "" {
+		t.Errorf("credentials were stored: %+v", stored)
+	}
+	if req.ApiKeys == nil {
+		t.Errorf("the original request was modified")
+	}
+
+	executor := &types.ServerAuth{Permissions: shared.Permissions{string(shared.PermissionExecCommands): true}}
+	got := resumedTellRequest(stored, executor)
+	want := *stored
+	want.Prompt = ""
+	want.IsUserContinue = true
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("resumedTellRequest() = %+v, want %+v", *got, want)
+	}
+
+	// the role may have lost exec since the stream started
+	if resumedTellRequest(stored, &types.ServerAuth{Permissions: shared.Permissions{}}).ExecEnabled {
+		t.Errorf("expected exec to be disabled without exec_commands")
+	}
+}
+
+const streamOwnerEnvVar = "PLANDEX_TEST_STREAM_OWNER"
+
+// TestResumeOrphanedStreamOwner is the instance that starts the stream in TestResumeOrphanedStream. It runs in its own process so the test can kill it.
+func TestResumeOrphanedStreamOwner(t *testing.T) {
+	ids := strings.Split(os.Getenv(streamOwnerEnvVar), ",")
+	if len(ids) != 3 {
+		t.Skip("only run by TestResumeOrphanedStream")
+	}
+	orgId, planId, userId := ids[0], ids[1], ids[2]
+
+	dbtest.Setup(t)
+	shutdown.ShutdownCtx = context.Background()
+	host.Ip = "10.0.0.1"
+
+	plan, err := db.GetPlan(planId)
+	if err != nil {
+		t.Fatalf("error getting plan: %v", err)
+	}
+	user, err := db.GetUser(userId)
+	if err != nil {
+		t.Fatalf("error getting user: %v", err)
+	}
+
+	active, err := activatePlan(nil, plan, "main", &types.ServerAuth{User: user, OrgId: orgId}, "add tests", false, false, "")
+	if err != nil {
+		t.Fatalf("error activating plan: %v", err)
+	}
+
+	err = db.SetModelStreamResumeRequest(active.ModelStreamId, resumableTellRequest(&shared.TellPlanRequest{
+		Prompt:    "add tests",
+		BuildMode: shared.BuildModeAuto,
+		ApiKeys:   map[string]string{"OPENAI_API_KEY": "sk-test"},
+	}))
+	if err != nil {
+		t.Fatalf("error storing resume request: %v", err)
+	}
+
+	fmt.Println("READY")
+
+	// heartbeat until killed
+	select {}
+}
+
+func TestResumeOrphanedStream(t *testing.T) {
+	dbtest.Setup(t)
+
+	if shutdown.ShutdownCtx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		shutdown.ShutdownCtx = ctx
+		t.Cleanup(func() {
+			cancel()
+			shutdown.ShutdownCtx = nil
+		})
+	}
+
+	org := dbtest.CreateOrg(t)
+
+	owner := exec.Command(os.Args[0], "-test.run=^TestResumeOrphanedStreamOwner$")
+	owner.Env = append(os.Environ(), fmt.Sprintf("%s=%s,%s,%s", streamOwnerEnvVar, org.Org.Id, org.Plan.Id, org.Owner.Id))
+	owner.Stderr = os.Stderr
+	stdout, err := owner.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.Start(); err != nil {
+		t.Fatalf("error starting stream owner: %v", err)
+	}
+	t.Cleanup(func() {
+		owner.Process.Kill()
+		owner.Wait()
+	})
+
+	ready := make(chan bool)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if scanner.Text() == "READY" {
+				ready <- true
+				return
+			}
+		}
+		ready <- false
+	}()
+
+	select {
+	case ok := <-ready:
+		if !ok {
+			t.Fatal("stream owner exited before starting the stream")
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for the stream owner")
+	}
+
+	stream, err := db.GetActiveModelStream(org.Plan.Id, "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stream == nil || stream.InternalIp != "10.0.0.1" || stream.Orphaned() {
+		t.Fatalf("expected a live stream on the owner, got %+v", stream)
+	}
+
+	// no shutdown, no cleanup--the owner's heartbeat just stops
+	owner.Process.Kill()
+	owner.Wait()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		stream, err = db.GetActiveModelStream(org.Plan.Id, "main")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stream == nil {
+			t.Fatal("expected the killed owner's stream to wait for a takeover")
+		}
+		if stream.Orphaned() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the killed owner's stream never became orphaned")
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	prevIp := host.Ip
+	host.Ip = "10.0.0.2"
+	t.Cleanup(func() { host.Ip = prevIp })
+
+	// the resumed tell stops at its first hook, before it needs model clients
+	execStarted := make(chan *types.ServerAuth, 1)
+	release := make(chan struct{})
+	hooks.RegisterHook(hooks.WillExecPlan, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+		execStarted <- params.Auth
+		<-release
+		return hooks.HookResult{}, &shared.ApiError{Type: shared.ApiErrorTypeOther, Status: http.StatusPaymentRequired, Msg: "stopped by test"}
+	})
+	t.Cleanup(func() {
+		hooks.RegisterHook(hooks.WillExecPlan, func(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+			return hooks.HookResult{}, nil
+		})
+	})
+
+	auth := &types.ServerAuth{User: org.Owner, OrgId: org.Org.Id, Permissions: shared.Permissions{}}
+	err = ResumeOrphanedStream(nil, org.Plan, "main", auth, stream)
+	if err != nil {
+		t.Fatalf("error resuming stream: %v", err)
+	}
+	t.Cleanup(func() {
+		close(release)
+		waitForInactive(t, org.Plan.Id, "main")
+	})
+
+	select {
+	case execAuth := <-execStarted:
+		if execAuth.User.Id != org.Owner.Id {
+			t.Errorf("resumed as %s, want the stream's user", execAuth.User.Id)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the resumed tell never started")
+	}
+
+	// only one instance can take it over
+	_, err = db.ClaimOrphanedModelStream(stream.Id, "10.0.0.3")
+	if !errors.Is(err, db.ErrModelStreamNotOrphaned) {
+		t.Errorf("expected a second claim to fail, got %v", err)
+	}
+
+	resumed, err := db.GetActiveModelStream(org.Plan.Id, "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resumed == nil || resumed.Id != stream.Id || resumed.InternalIp != "10.0.0.2" || resumed.Orphaned() {
+		t.Errorf("expected the stream to be running on the new instance, got %+v", resumed)
+	}
+	if resumed != nil && (resumed.ResumeRequest == nil || resumed.ResumeRequest.ApiKeys != nil) {
+		t.Errorf("expected the stored request without credentials, got %+v", resumed.ResumeRequest)
+	}
+
+	active := GetActivePlan(org.Plan.Id, "main")
+	if active == nil || active.ModelStreamId != stream.Id {
+		t.Errorf("expected the plan to be active on the new instance, got %+v", active)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/shutdown"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -20,6 +21,19 @@ func GetActivePlan(planId, branch string) *types.ActivePlan {
 	return activePlans.Get(strings.Join([]string{planId, branch}, "|"))
 }
 
+// streamBudgetWarning returns a model request OnWarning callback that sends spend budget warnings to the plan's connected client
+func streamBudgetWarning(plan *db.Plan, branch string) func(string) {
+	return func(warning string) {
+		if plan == nil {
+			return
+		}
+		active := GetActivePlan(plan.Id, branch)
+		if active != nil {
+			active.StreamBudgetWarning(warning)
+		}
+	}
+}
+
 func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, autoContext bool, sessionId string) *types.ActivePlan {
 	activePlan := types.NewActivePlan(orgId, userId, planId, branch, prompt, buildOnly, autoContext, sessionId)
 	key := strings.Join([]string{planId, branch}, "|")
@@ -52,6 +66,14 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
 					}
 
+					webhooks.Trigger(webhooks.TriggerParams{
+						Event:  shared.WebhookEventPlanFinished,
+						OrgId:  orgId,
+						UserId: userId,
+						PlanId: planId,
+						Branch: branch,
+					})
+
 					// cancel *after* the DeleteActivePlan call
 					// allows queued operations to complete
 					DeleteActivePlan(orgId, userId, planId, branch)
@@ -65,6 +87,15 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to error: %v\n", planId, err)
 					}
 
+					webhooks.Trigger(webhooks.TriggerParams{
+						Event:  shared.WebhookEventPlanError,
+						OrgId:  orgId,
+						UserId: userId,
+						PlanId: planId,
+						Branch: branch,
+						Error:  apiErr.Msg,
+					})
+
 					log.Println("Sending error message to client")
 					activePlan.Stream(shared.StreamMessage{
 						Type:  shared.StreamMessageError,
@@ -84,6 +84,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		NumTokens   int
 		Body        string
 		ContextType shared.ContextType
+		Symbol      string
 		ImageDetail openai.ImageURLDetail
 		IsPending   bool
 	}
@@ -109,7 +110,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			}
 		}
 
-		if currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && part.ContextType == shared.ContextFileType && !uses[part.FilePath] {
+		if currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && (part.ContextType == shared.ContextFileType || part.ContextType == shared.ContextSymbolType) && !uses[part.FilePath] {
 			if verboseLogging {
 				log.Println("Tell plan - formatModelContext - skipping part -- currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && part.ContextType == shared.ContextFileType && !uses[part.FilePath]")
 			}
@@ -135,6 +136,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			NumTokens:   part.NumTokens,
 			Body:        part.Body,
 			ContextType: part.ContextType,
+			Symbol:      part.Symbol,
 			Name:        part.Name,
 			Url:         part.Url,
 			ImageDetail: part.ImageDetail,
@@ -262,6 +264,10 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		} else if part.ContextType == shared.ContextMapType {
 			fmtStr = "\n\n- %s | map:\n\n```\n%s\n```"
 			args = append(args, part.FilePath, part.Body)
+		} else if part.ContextType == shared.ContextSymbolType {
+			// only part of the file is loaded, so make sure the model doesn't treat it as the whole file
+			fmtStr = "\n\n- %s | excerpt with only the `%s` definition -- the rest of the file is not loaded:\n\n```\n%s\n```"
+			args = append(args, part.FilePath, part.Symbol, part.Body)
 		} else if part.Url != "" {
 			fmtStr = "\n\n- %s:\n\n```\n%s\n```"
 			args = append(args, part.Url, part.Body)
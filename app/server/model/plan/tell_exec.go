@@ -23,7 +23,7 @@ import (
 func Tell(clients map[string]model.ClientInfo, plan *db.Plan, branch string, auth *types.ServerAuth, req *shared.TellPlanRequest) error {
 	log.Printf("Tell: Called with plan ID %s on branch %s\n", plan.Id, branch)
 
-	_, err := activatePlan(
+	active, err := activatePlan(
 		clients,
 		plan,
 		branch,
@@ -39,6 +39,14 @@ func Tell(clients map[string]model.ClientInfo, plan *db.Plan, branch string, aut
 		return err
 	}
 
+	active.DiagnosticsExts = req.DiagnosticsExts
+
+	err = db.SetModelStreamResumeRequest(active.ModelStreamId, resumableTellRequest(req))
+	if err != nil {
+		// the stream still runs--it just can't be resumed elsewhere if this instance stops
+		log.Printf("Error storing resume request for plan ID %s on branch %s: %v\n", plan.Id, branch, err)
+	}
+
 	go execTellPlan(execTellPlanParams{
 		clients:            clients,
 		plan:               plan,
@@ -209,7 +217,21 @@ func execTellPlan(params execTellPlanParams) {
 			cacheControl:        true,
 		})
 
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext && req.AutoContext {
+			msg := types.ExtendedChatMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: []types.ExtendedChatMessagePart{},
+			}
+			for _, part := range planStageSharedMsgs {
+				msg.Content = append(msg.Content, *part)
+			}
+			tokensRemaining := tentativeMaxTokens - (model.GetMessagesTokenEstimate(msg) + tokensWithoutContext)
+
+			// leave most of the remaining room for the conversation and the architect's response
+			if tokensRemaining > 0 {
+				planningPhaseOnlyMsgs = state.formatSemanticContext(tokensRemaining / 2)
+			}
+		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
 			if req.AutoContext {
 				msg := types.ExtendedChatMessage{
 					Role:    openai.ChatMessageRoleSystem,
@@ -423,7 +445,7 @@ func execTellPlan(params execTellPlanParams) {
 		"tokens":   requestTokens,
 	}))
 
-	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+	hookRes, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
 		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
@@ -438,6 +460,10 @@ func execTellPlan(params execTellPlanParams) {
 		return
 	}
 
+	if hookRes.WillSendModelRequestResult != nil && hookRes.WillSendModelRequestResult.Warning != "" {
+		active.StreamBudgetWarning(hookRes.WillSendModelRequestResult.Warning)
+	}
+
 	state.doTellRequest()
 
 	if shouldBuildPending {
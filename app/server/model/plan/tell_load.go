@@ -287,6 +287,26 @@ func (state *activeTellStreamState) loadTellPlan() error {
 		return err
 	}
 
+	if req.ExecEnabled {
+		orgPolicy, err := db.GetOrgExecPolicy(currentOrgId)
+		if err != nil {
+			log.Printf("execTellPlan: error getting org exec policy: %v\n", err)
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error getting org exec policy: %v", err))
+
+			active.StreamDoneCh <- &shared.ApiError{
+				Type:   shared.ApiErrorTypeOther,
+				Status: http.StatusInternalServerError,
+				Msg:    "Error getting org exec policy",
+			}
+			return err
+		}
+
+		state.execPolicies = []shared.NamedExecPolicy{
+			{Source: "Org", Policy: orgPolicy},
+			{Source: "Project", Policy: req.ExecPolicy},
+		}
+	}
+
 	state.modelContext = modelContext
 	state.convo = convo
 	state.promptConvoMessage = promptMsg
@@ -331,7 +351,7 @@ func (state *activeTellStreamState) loadTellPlan() error {
 			ap.Contexts = state.modelContext
 
 			for _, context := range state.modelContext {
-				if context.FilePath != "" {
+				if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 					ap.ContextsByPath[context.FilePath] = context
 				}
 			}
@@ -84,6 +84,7 @@ func (state *activeTellStreamState) handleMissingFileResponse(unfinishedSubtaskR
 			OsDetails:                  req.OsDetails,
 			CurrentStage:               state.currentStage,
 			UnfinishedSubtaskReasoning: unfinishedSubtaskReasoning,
+			ExecPolicies:               state.execPolicies,
 		}
 
 		prompt := prompts.GetWrappedPrompt(params) + "\n\n" + skipPrompt // repetition of skip prompt to improve instruction following
@@ -113,6 +114,7 @@ func (state *activeTellStreamState) handleMissingFileResponse(unfinishedSubtaskR
 			OsDetails:                  req.OsDetails,
 			CurrentStage:               state.currentStage,
 			UnfinishedSubtaskReasoning: unfinishedSubtaskReasoning,
+			ExecPolicies:               state.execPolicies,
 		}
 
 		prompt := prompts.GetWrappedPrompt(params) + "\n\n" + missingPrompt // repetition of missing prompt to improve instruction following
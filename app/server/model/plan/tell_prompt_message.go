@@ -93,6 +93,7 @@ func (state *activeTellStreamState) resolvePromptMessage(
 				OsDetails:                  req.OsDetails,
 				CurrentStage:               state.currentStage,
 				UnfinishedSubtaskReasoning: unfinishedSubtaskReasoning,
+				ExecPolicies:               state.execPolicies,
 			}
 
 			promptMessage = &types.ExtendedChatMessage{
@@ -123,6 +124,7 @@ func (state *activeTellStreamState) resolvePromptMessage(
 				OsDetails:                  req.OsDetails,
 				CurrentStage:               state.currentStage,
 				UnfinishedSubtaskReasoning: unfinishedSubtaskReasoning,
+				ExecPolicies:               state.execPolicies,
 			}
 
 			promptMessage = &types.ExtendedChatMessage{
@@ -160,6 +162,7 @@ func (state *activeTellStreamState) resolvePromptMessage(
 			OsDetails:                  req.OsDetails,
 			CurrentStage:               state.currentStage,
 			UnfinishedSubtaskReasoning: unfinishedSubtaskReasoning,
+			ExecPolicies:               state.execPolicies,
 		}
 
 		finalPrompt := prompts.GetWrappedPrompt(params)
@@ -0,0 +1,114 @@
+package plan
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/model"
+	"plandex-server/semantic"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	semanticContextMaxResults = 40
+	semanticContextMaxTokens  = 12000
+)
+
+// formatSemanticContext searches the project's semantic index with the user's prompt and formats the best matching definitions for the architect during the context phase. Results that don't fit in maxTokens are listed by path only.
+func (state *activeTellStreamState) formatSemanticContext(maxTokens int) []*types.ExtendedChatMessagePart {
+	req := state.req
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil
+	}
+
+	planConfig, err := db.GetPlanConfig(state.plan.Id)
+	if err != nil {
+		log.Printf("Tell plan - formatSemanticContext - error getting plan config: %v\n", err)
+		return nil
+	}
+
+	if !planConfig.SemanticIndex {
+		return nil
+	}
+
+	var embedder semantic.Embedder
+	if planConfig.UsesEmbeddings() {
+		modelEmbedder, err := model.NewEmbedder(model.EmbedderParams{
+			Clients:   state.clients,
+			Auth:      state.auth,
+			Plan:      state.plan,
+			Provider:  planConfig.GetEmbeddingProvider(),
+			ModelName: shared.ModelName(planConfig.GetEmbeddingModel()),
+			Purpose:   "Semantic index embeddings",
+			SessionId: state.activePlan.SessionId,
+		})
+		if err != nil {
+			log.Printf("Tell plan - formatSemanticContext - can't embed, using bm25: %v\n", err)
+		} else {
+			embedder = modelEmbedder
+		}
+	}
+
+	results, err := semantic.Search(state.activePlan.Ctx, semantic.SearchParams{
+		ProjectId:  state.plan.ProjectId,
+		Query:      req.Prompt,
+		Embedder:   embedder,
+		MaxResults: semanticContextMaxResults,
+	})
+	if err != nil {
+		log.Printf("Tell plan - formatSemanticContext - error searching semantic index: %v\n", err)
+		return nil
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	maxTokens = min(maxTokens, semanticContextMaxTokens)
+
+	lines := []string{
+		"### RELEVANT CODE ###",
+		"These definitions from the project were ranked most relevant to the user's prompt by the project's semantic index, best first. They are excerpts, not whole files. Use them along with the project map to decide which files to load.",
+	}
+
+	totalTokens := shared.GetNumTokensEstimate(strings.Join(lines, "\n"))
+	var pathsOnly []string
+	pathsOnlySet := map[string]bool{}
+
+	for _, result := range results {
+		excerpt := fmt.Sprintf("\n- %s | `%s` (lines %d-%d):\n\n```\n%s\n```", result.Path, result.Name, result.StartLine, result.EndLine, result.Body)
+		numTokens := shared.GetNumTokensEstimate(excerpt)
+
+		if len(pathsOnly) == 0 && totalTokens+numTokens <= maxTokens {
+			lines = append(lines, excerpt)
+			totalTokens += numTokens
+			continue
+		}
+
+		if !pathsOnlySet[result.Path] {
+			pathsOnlySet[result.Path] = true
+			pathsOnly = append(pathsOnly, result.Path)
+		}
+	}
+
+	if len(pathsOnly) > 0 {
+		lines = append(lines, "\nOther relevant paths:")
+		for _, path := range pathsOnly {
+			lines = append(lines, fmt.Sprintf("- %s", path))
+		}
+	}
+
+	log.Printf("Tell plan - formatSemanticContext - %d results, %d paths only, %d tokens\n", len(results), len(pathsOnly), totalTokens)
+
+	return []*types.ExtendedChatMessagePart{
+		{
+			Type: openai.ChatMessagePartTypeText,
+			Text: strings.Join(lines, "\n"),
+		},
+	}
+}
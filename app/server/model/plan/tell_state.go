@@ -47,6 +47,7 @@ type activeTellStreamState struct {
 	currentStage          shared.CurrentStage
 	chunkProcessor        *chunkProcessor
 	generationId          string
+	execPolicies          []shared.NamedExecPolicy
 
 	requestStartedAt time.Time
 	firstTokenAt     time.Time
@@ -7,6 +7,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/notify"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"regexp"
 	"runtime/debug"
 	"strings"
@@ -665,6 +666,16 @@ func (state *activeTellStreamState) handleMissingFile(content, currentFile, bloc
 		MissingFileAutoContext: active.AutoContext,
 	})
 
+	webhooks.Trigger(webhooks.TriggerParams{
+		Event:    shared.WebhookEventMissingFile,
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		PlanName: plan.Name,
+		Branch:   branch,
+		Path:     currentFile,
+	})
+
 	log.Printf("Stopping stream for missing file: %s\n", currentFile)
 	// log.Printf("Chunk content: %s\n", content)
 	// log.Printf("Current reply content: %s\n", active.CurrentReplyContent)
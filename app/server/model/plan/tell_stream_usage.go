@@ -21,6 +21,11 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 		cachedTokens = usage.PromptTokensDetails.CachedTokens
 	}
 
+	var reasoningTokens int
+	if usage.CompletionTokensDetails != nil {
+		reasoningTokens = usage.CompletionTokensDetails.ReasoningTokens
+	}
+
 	sessionId := state.activePlan.SessionId
 
 	modelConfig := state.modelConfig
@@ -30,19 +35,20 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 			Auth: auth,
 			Plan: plan,
 			DidSendModelRequestParams: &hooks.DidSendModelRequestParams{
-				InputTokens:    usage.PromptTokens,
-				OutputTokens:   usage.CompletionTokens,
-				CachedTokens:   cachedTokens,
-				ModelId:        modelConfig.BaseModelConfig.ModelId,
-				ModelName:      modelConfig.BaseModelConfig.ModelName,
-				ModelProvider:  modelConfig.BaseModelConfig.Provider,
-				ModelPackName:  state.settings.ModelPack.Name,
-				ModelRole:      modelConfig.Role,
-				Purpose:        "Response",
-				GenerationId:   generationId,
-				PlanId:         plan.Id,
-				ModelStreamId:  state.modelStreamId,
-				ConvoMessageId: state.replyId,
+				InputTokens:     usage.PromptTokens,
+				OutputTokens:    usage.CompletionTokens,
+				CachedTokens:    cachedTokens,
+				ReasoningTokens: reasoningTokens,
+				ModelId:         modelConfig.BaseModelConfig.ModelId,
+				ModelName:       modelConfig.BaseModelConfig.ModelName,
+				ModelProvider:   modelConfig.BaseModelConfig.Provider,
+				ModelPackName:   state.settings.ModelPack.Name,
+				ModelRole:       modelConfig.Role,
+				Purpose:         "Response",
+				GenerationId:    generationId,
+				PlanId:          plan.Id,
+				ModelStreamId:   state.modelStreamId,
+				ConvoMessageId:  state.replyId,
 
 				RequestStartedAt: state.requestStartedAt,
 				Streaming:        true,
@@ -1,5 +1,11 @@
 package prompts
 
+import (
+	"strings"
+
+	shared "plandex-shared"
+)
+
 const ApplyScriptSharedPrompt = `
 ## _apply.sh file and command execution
 
@@ -1169,3 +1175,42 @@ ALWAYS put startup logic that goes beyond a single command without flags in a *s
 
 In startup scripts and _apply.sh, DO THE MINIMUM NECESSARY. Do not include extra options or ways of starting the project. Avoid conditional logic unless it's truly necessary. Don't output messages to the console. Don't include verbose logging. Don't include verbose comments. Keep it simple, short, and minimal. KEEP IT SIMPLE. Your goal is to accomplish the user's task. No less and no more. Don't go beyond what the user has asked for.
 `
+
+// GetExecPolicyPrompt lists the project and org command policies so the model doesn't write commands into _apply.sh that will be refused or held for confirmation
+func GetExecPolicyPrompt(policies []shared.NamedExecPolicy) string {
+	var sb strings.Builder
+
+	for _, policy := range policies {
+		if policy.Policy.IsEmpty() {
+			continue
+		}
+
+		sb.WriteString("\n" + policy.Source + " policy:\n")
+		if len(policy.Policy.Deny) > 0 {
+			sb.WriteString("Denied commands:\n")
+			for _, pattern := range policy.Policy.Deny {
+				sb.WriteString("- " + pattern + "\n")
+			}
+		}
+		if len(policy.Policy.Allow) > 0 {
+			sb.WriteString("Allowed commands:\n")
+			for _, pattern := range policy.Policy.Allow {
+				sb.WriteString("- " + pattern + "\n")
+			}
+		}
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return `
+## Command execution policy
+
+The user's project has a command execution policy for _apply.sh. Each line of _apply.sh is split into individual commands (on ';', '&&', '||', '|', and '&') and every command is checked against the policy before the script runs. In patterns, '*' matches any characters, and a pattern also matches the same command with additional arguments.
+
+If *any* command in _apply.sh matches a denied pattern, the *entire script* will be refused and won't run. NEVER write a command that matches a denied pattern, and don't try to work around a denied pattern with aliases, variables, subshells, or equivalent commands. If a task can't be completed without a denied command, tell the user which command they need to run themselves instead.
+
+If a policy has allowed commands, any command that doesn't match one of them will require the user's manual approval, even if automatic execution is enabled. Prefer commands that match the allowed patterns whenever possible.
+` + sb.String()
+}
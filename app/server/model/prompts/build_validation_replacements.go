@@ -16,12 +16,14 @@ type ValidationPromptParams struct {
 	Diff                 string
 	Reasons              []syntax.NeedsVerifyReason
 	SyntaxErrors         []string
+	Diagnostics          []string
 }
 
 // GetValidationReplacementsXmlPrompt constructs the complete prompt string for XML responses.
 func GetValidationReplacementsXmlPrompt(params ValidationPromptParams) (string, int) {
 	reasons := params.Reasons
 	syntaxErrs := params.SyntaxErrors
+	diagnostics := params.Diagnostics
 	path := params.Path
 	originalWithLineNums := params.OriginalWithLineNums
 	desc := params.Desc
@@ -64,6 +66,13 @@ Diff of applied changes:
 		))
 	}
 
+	if len(diagnostics) > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"The applied changes resulted in errors from the project's language server or compiler. The original file didn't have these errors:\n%s\n\nInclude an assessment of what caused these errors. These errors must be fixed, including missing imports or other problems outside the scope of the proposed changes if that's what caused them. If an error is caused by code that another file in the plan is expected to add, like a function or type that doesn't exist yet, leave it alone.",
+			strings.Join(diagnostics, "\n"),
+		))
+	}
+
 	s += strings.Join(parts, "\n\n")
 
 	s += `
@@ -71,8 +80,8 @@ Diff of applied changes:
 
 Your first task is to examine whether the changes were applied as described in the proposed changes explanation. Do NOT evaluate:
 - Code quality
-- Missing imports
-- Unused variables
+- Missing imports (unless a missing import caused language server or compiler errors that have been previously specified)
+- Unused variables (unless an unused variable caused language server or compiler errors that have been previously specified)
 - Best practices
 - Potential bugs
 - Syntax (unless syntax errors have been previously specified and you are determining the cause of the syntax errors)
@@ -83,6 +92,7 @@ b. Whether the changes included *all* the specified additions/modifications
 c. Whether *any* unintended changes were made to surrounding code
 d. Whether *any* specified code was accidentally removed or duplicated
 e. Any syntax errors that have been previously specified
+f. Any language server or compiler errors that have been previously specified
 
 --
 
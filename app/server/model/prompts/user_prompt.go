@@ -262,6 +262,7 @@ type UserPromptParams struct {
 	OsDetails                  string
 	CurrentStage               shared.CurrentStage
 	UnfinishedSubtaskReasoning string
+	ExecPolicies               []shared.NamedExecPolicy
 }
 
 func GetWrappedPrompt(params UserPromptParams) string {
@@ -304,6 +305,10 @@ func GetWrappedPrompt(params UserPromptParams) string {
 	s += "\n\n"
 	s += fmt.Sprintf(promptWrapperFormatStr, prompt, ts, osDetails, applyScriptSummary)
 
+	if params.ExecMode && applyScriptSummary != "" {
+		s += GetExecPolicyPrompt(params.ExecPolicies)
+	}
+
 	if currentStage.TellStage == shared.TellStageImplementation && params.UnfinishedSubtaskReasoning != "" {
 		s += "\n\n" + `
 The current task was not completed in the previous response and remains unfinished. Here is the reasoning for why it was not completed:
@@ -0,0 +1,142 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DeviceAuth is the issuer's response to a device authorization request (RFC 8628)
+type DeviceAuth struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+var (
+	// the user hasn't finished signing in yet
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// the client is polling too often and should back off
+	ErrSlowDown = errors.New("slow down")
+)
+
+// TokenError is an error response from the issuer's token endpoint, like access_denied or expired_token
+type TokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+func (p *Provider) StartDeviceAuth(ctx context.Context) (*DeviceAuth, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := p.clientForm()
+	form.Set("scope", strings.Join(p.Config.Scopes, " "))
+
+	var res DeviceAuth
+	err = p.postForm(ctx, doc.DeviceAuthorizationEndpoint, form, &res)
+	if err != nil {
+		return nil, fmt.Errorf("error starting device authorization: %v", err)
+	}
+
+	if res.DeviceCode == "" || res.UserCode == "" || res.VerificationUri == "" {
+		return nil, errors.New("device authorization response is missing fields")
+	}
+
+	if res.Interval == 0 {
+		res.Interval = 5
+	}
+
+	return &res, nil
+}
+
+// ExchangeDeviceCode polls the token endpoint once. It returns ErrAuthorizationPending or ErrSlowDown until the user finishes signing in, then the verified ID token claims.
+func (p *Provider) ExchangeDeviceCode(ctx context.Context, deviceCode string) (*Claims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := p.clientForm()
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	form.Set("device_code", deviceCode)
+
+	var res struct {
+		IdToken string `json:"id_token"`
+	}
+	err = p.postForm(ctx, doc.TokenEndpoint, form, &res)
+	if err != nil {
+		var tokenErr *TokenError
+		if errors.As(err, &tokenErr) {
+			switch tokenErr.Code {
+			case "authorization_pending":
+				return nil, ErrAuthorizationPending
+			case "slow_down":
+				return nil, ErrSlowDown
+			}
+		}
+		return nil, err
+	}
+
+	if res.IdToken == "" {
+		return nil, errors.New("token response has no ID token--is the openid scope set?")
+	}
+
+	return p.VerifyIdToken(ctx, res.IdToken)
+}
+
+func (p *Provider) clientForm() url.Values {
+	form := url.Values{}
+	form.Set("client_id", p.Config.ClientId)
+	if p.Config.ClientSecret != "" {
+		form.Set("client_secret", p.Config.ClientSecret)
+	}
+	return form
+}
+
+func (p *Provider) postForm(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		var tokenErr TokenError
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Code != "" {
+			return &tokenErr
+		}
+		return fmt.Errorf("%s returned %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,144 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OpenID Connect single sign-on for self-hosted servers. The server drives the device authorization flow against the configured issuer on the CLI's behalf, so the client secret never leaves the server, then signs the user in with the verified ID token.
+
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+type Config struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	GroupsClaim  string
+	// in priority order--a user in several mapped groups gets the first match's role
+	GroupRoles []GroupRole
+	// role for users whose groups don't map to one--defaults to member
+	DefaultRole string
+	// disables email pin sign in and account creation
+	Required bool
+}
+
+// LoadConfig reads the OIDC_* environment variables. It returns nil if OIDC_ISSUER isn't set.
+func LoadConfig() (*Config, error) {
+	issuer := strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	config := &Config{
+		Issuer:       issuer,
+		ClientId:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		Scopes:       []string{"openid", "email", "profile"},
+		GroupsClaim:  "groups",
+		Required:     os.Getenv("OIDC_REQUIRED") == "1" || os.Getenv("OIDC_REQUIRED") == "true",
+	}
+
+	if config.ClientId == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+	}
+
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		config.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+
+	if claim := os.Getenv("OIDC_GROUPS_CLAIM"); claim != "" {
+		config.GroupsClaim = claim
+	}
+
+	groupRoles, err := ParseGroupRoles(os.Getenv("OIDC_GROUP_ROLES"))
+	if err != nil {
+		return nil, err
+	}
+	config.GroupRoles = groupRoles
+
+	config.DefaultRole = strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE"))
+
+	return config, nil
+}
+
+// ParseGroupRoles parses a mapping like "plandex-owners=owner,engineering=member"
+func ParseGroupRoles(s string) ([]GroupRole, error) {
+	var res []GroupRole
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		group, role, ok := strings.Cut(pair, "=")
+		group = strings.TrimSpace(group)
+		role = strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid OIDC_GROUP_ROLES entry %q--expected group=role", pair)
+		}
+
+		res = append(res, GroupRole{Group: group, Role: role})
+	}
+	return res, nil
+}
+
+// RoleForGroups returns the org role name mapped to the first matching group, or "" if none match
+func (c *Config) RoleForGroups(groups []string) string {
+	for _, groupRole := range c.GroupRoles {
+		for _, group := range groups {
+			if group == groupRole.Group {
+				return groupRole.Role
+			}
+		}
+	}
+	return ""
+}
+
+// RoleForSignIn returns the org role name a domain org member should have after signing in, or "" to keep their current role. New members get the default role if none of their groups are mapped. Once group roles are configured, groups decide existing members' roles too, so members outside every mapped group fall back to the default role.
+func (c *Config) RoleForSignIn(groups []string, isNewMember bool) string {
+	if role := c.RoleForGroups(groups); role != "" {
+		return role
+	}
+
+	if isNewMember || len(c.GroupRoles) > 0 {
+		if c.DefaultRole == "" {
+			return "member"
+		}
+		return c.DefaultRole
+	}
+
+	return ""
+}
+
+var (
+	defaultMu       sync.Mutex
+	defaultProvider *Provider
+)
+
+// Default returns the provider configured from the environment, or nil if OIDC isn't configured
+func Default() (*Provider, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultProvider != nil {
+		return defaultProvider, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		return nil, nil
+	}
+
+	defaultProvider = NewProvider(*config)
+	return defaultProvider, nil
+}
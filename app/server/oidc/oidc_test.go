@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"plandex-server/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	issuer, server, err := oidctest.NewServer("plandex")
+	if err != nil {
+		t.Fatalf("error starting mock issuer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	return NewProvider(Config{
+		Issuer:      issuer.URL,
+		ClientId:    "plandex",
+		Scopes:      []string{"openid", "email", "profile"},
+		GroupsClaim: "groups",
+	}), issuer
+}
+
+func TestDeviceFlow(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+
+	auth, err := provider.StartDeviceAuth(ctx)
+	if err != nil {
+		t.Fatalf("error starting device auth: %v", err)
+	}
+
+	_, err = provider.ExchangeDeviceCode(ctx, auth.DeviceCode)
+	if !errors.Is(err, ErrAuthorizationPending) {
+		t.Fatalf("expected authorization pending, got %v", err)
+	}
+
+	err = issuer.Approve(auth.UserCode, oidctest.Identity{
+		Email:  "Dana@Example.com",
+		Name:   "Dana",
+		Groups: []string{"engineering", "plandex-admins"},
+	})
+	if err != nil {
+		t.Fatalf("error approving device: %v", err)
+	}
+
+	claims, err := provider.ExchangeDeviceCode(ctx, auth.DeviceCode)
+	if err != nil {
+		t.Fatalf("error exchanging device code: %v", err)
+	}
+
+	if claims.Email != "dana@example.com" || !claims.EmailVerified || claims.Name != "Dana" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if len(claims.Groups) != 2 || claims.Groups[1] != "plandex-admins" {
+		t.Fatalf("unexpected groups: %v", claims.Groups)
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+
+	auth, err := provider.StartDeviceAuth(ctx)
+	if err != nil {
+		t.Fatalf("error starting device auth: %v", err)
+	}
+
+	if err := issuer.Deny(auth.UserCode); err != nil {
+		t.Fatalf("error denying device: %v", err)
+	}
+
+	_, err = provider.ExchangeDeviceCode(ctx, auth.DeviceCode)
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Code != "access_denied" {
+		t.Fatalf("expected access_denied, got %v", err)
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+
+	token, err := issuer.IdToken(oidctest.Identity{Email: "dana@example.com"})
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+
+	if _, err := provider.VerifyIdToken(ctx, token); err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+
+	// tampered signature
+	if _, err := provider.VerifyIdToken(ctx, token[:len(token)-4]+"AAAA"); err == nil {
+		t.Fatalf("expected token with a bad signature to fail")
+	}
+
+	// tampered payload, signed for another identity
+	otherToken, err := issuer.IdToken(oidctest.Identity{Email: "mallory@example.com"})
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	otherParts := strings.Split(otherToken, ".")
+	if _, err := provider.VerifyIdToken(ctx, parts[0]+"."+otherParts[1]+"."+parts[2]); err == nil {
+		t.Fatalf("expected token with a swapped payload to fail")
+	}
+
+	// wrong issuer, signed with the issuer's own key
+	issuerUrl := issuer.URL
+	issuer.URL = "https://accounts.example.com"
+	wrongIssuerToken, err := issuer.IdToken(oidctest.Identity{Email: "dana@example.com"})
+	issuer.URL = issuerUrl
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+	if _, err := provider.VerifyIdToken(ctx, wrongIssuerToken); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Fatalf("expected token from another issuer to fail on its issuer, got %v", err)
+	}
+
+	// wrong audience
+	other := NewProvider(provider.Config)
+	other.Config.ClientId = "someone-else"
+	if _, err := other.VerifyIdToken(ctx, token); err == nil {
+		t.Fatalf("expected token for another client to fail")
+	}
+
+	// expired
+	later := NewProvider(provider.Config)
+	later.now = func() time.Time { return time.Now().Add(3 * time.Hour) }
+	if _, err := later.VerifyIdToken(ctx, token); err == nil {
+		t.Fatalf("expected expired token to fail")
+	}
+}
+
+func TestRoleForGroups(t *testing.T) {
+	groupRoles, err := ParseGroupRoles("plandex-owners=owner, plandex-admins=admin,engineering=member")
+	if err != nil {
+		t.Fatalf("error parsing group roles: %v", err)
+	}
+
+	config := Config{GroupRoles: groupRoles}
+
+	if role := config.RoleForGroups([]string{"engineering", "plandex-admins"}); role != "admin" {
+		t.Fatalf("expected admin, got %q", role)
+	}
+	if role := config.RoleForGroups([]string{"sales"}); role != "" {
+		t.Fatalf("expected no role, got %q", role)
+	}
+
+	if _, err := ParseGroupRoles("plandex-owners"); err == nil {
+		t.Fatalf("expected entry without a role to fail")
+	}
+}
+
+func TestRoleForSignIn(t *testing.T) {
+	mapped := Config{GroupRoles: []GroupRole{{Group: "plandex-admins", Role: "admin"}}}
+	withDefault := Config{GroupRoles: mapped.GroupRoles, DefaultRole: "viewer"}
+	unmapped := Config{}
+
+	tests := []struct {
+		name        string
+		config      Config
+		groups      []string
+		isNewMember bool
+		want        string
+	}{
+		{"mapped group", mapped, []string{"plandex-admins"}, false, "admin"},
+		{"new member without a mapped group", mapped, []string{"sales"}, true, "member"},
+		{"member leaves every mapped group", mapped, []string{"sales"}, false, "member"},
+		{"member falls back to the configured default", withDefault, nil, false, "viewer"},
+		{"new member without group roles", unmapped, []string{"plandex-admins"}, true, "member"},
+		{"member keeps their role without group roles", unmapped, []string{"plandex-admins"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.RoleForSignIn(tt.groups, tt.isNewMember); got != tt.want {
+				t.Errorf("RoleForSignIn() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
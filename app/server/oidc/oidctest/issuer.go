@@ -0,0 +1,364 @@
+// Package oidctest is a mock OpenID Connect issuer for testing SSO without a real identity provider. It supports discovery, signing keys, and the device authorization flow, and signs ID tokens with a generated RSA key.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyId = "oidctest"
+
+type Identity struct {
+	Email  string
+	Name   string
+	Groups []string
+	// defaults to the email
+	Subject string
+	// defaults to verified
+	EmailUnverified bool
+}
+
+type device struct {
+	deviceCode string
+	userCode   string
+	expiresAt  time.Time
+	identity   *Identity
+	denied     bool
+}
+
+type Issuer struct {
+	URL      string
+	ClientId string
+	// seconds clients should wait between polls
+	Interval int
+
+	key *rsa.PrivateKey
+
+	mu      sync.Mutex
+	devices map[string]*device
+}
+
+// NewIssuer returns an issuer that will be served at url
+func NewIssuer(url, clientId string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("error generating signing key: %v", err)
+	}
+
+	return &Issuer{
+		URL:      strings.TrimSuffix(url, "/"),
+		ClientId: clientId,
+		Interval: 1,
+		key:      key,
+		devices:  map[string]*device{},
+	}, nil
+}
+
+// NewServer starts an issuer on a local test server
+func NewServer(clientId string) (*Issuer, *httptest.Server, error) {
+	issuer, err := NewIssuer("", clientId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server := httptest.NewServer(issuer)
+	issuer.URL = server.URL
+
+	return issuer, server, nil
+}
+
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		i.handleDiscovery(w)
+	case "/jwks":
+		i.handleJwks(w)
+	case "/device_authorization":
+		i.handleDeviceAuthorization(w, r)
+	case "/token":
+		i.handleToken(w, r)
+	case "/device":
+		i.handleDevicePage(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Approve signs in the device with the user code as identity
+func (i *Issuer) Approve(userCode string, identity Identity) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	d := i.deviceByUserCode(userCode)
+	if d == nil {
+		return fmt.Errorf("unknown user code %s", userCode)
+	}
+	d.identity = &identity
+	return nil
+}
+
+// Deny rejects the device with the user code
+func (i *Issuer) Deny(userCode string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	d := i.deviceByUserCode(userCode)
+	if d == nil {
+		return fmt.Errorf("unknown user code %s", userCode)
+	}
+	d.denied = true
+	return nil
+}
+
+// IdToken signs an ID token for the identity, as the token endpoint would
+func (i *Issuer) IdToken(identity Identity) (string, error) {
+	subject := identity.Subject
+	if subject == "" {
+		subject = identity.Email
+	}
+
+	groups := identity.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+
+	now := time.Now()
+	return i.sign(map[string]interface{}{
+		"iss":            i.URL,
+		"aud":            i.ClientId,
+		"sub":            subject,
+		"email":          identity.Email,
+		"email_verified": !identity.EmailUnverified,
+		"name":           identity.Name,
+		"groups":         groups,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	})
+}
+
+func (i *Issuer) deviceByUserCode(userCode string) *device {
+	for _, d := range i.devices {
+		if d.userCode == userCode {
+			return d
+		}
+	}
+	return nil
+}
+
+func (i *Issuer) handleDiscovery(w http.ResponseWriter) {
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.URL,
+		"jwks_uri":                              i.URL + "/jwks",
+		"token_endpoint":                        i.URL + "/token",
+		"device_authorization_endpoint":         i.URL + "/device_authorization",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *Issuer) handleJwks(w http.ResponseWriter) {
+	pub := i.key.PublicKey
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyId,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) handleDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.PostFormValue("client_id") != i.ClientId {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	d := &device{
+		deviceCode: randomString(32),
+		userCode:   strings.ToUpper(randomString(4) + "-" + randomString(4)),
+		expiresAt:  time.Now().Add(10 * time.Minute),
+	}
+
+	i.mu.Lock()
+	i.devices[d.deviceCode] = d
+	i.mu.Unlock()
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"device_code":               d.deviceCode,
+		"user_code":                 d.userCode,
+		"verification_uri":          i.URL + "/device",
+		"verification_uri_complete": i.URL + "/device?user_code=" + d.userCode,
+		"expires_in":                600,
+		"interval":                  i.Interval,
+	})
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.PostFormValue("client_id") != i.ClientId {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if r.PostFormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	i.mu.Lock()
+	d := i.devices[r.PostFormValue("device_code")]
+	var identity *Identity
+	var denied, expired bool
+	if d != nil {
+		identity = d.identity
+		denied = d.denied
+		expired = time.Now().After(d.expiresAt)
+		if identity != nil || denied || expired {
+			delete(i.devices, d.deviceCode)
+		}
+	}
+	i.mu.Unlock()
+
+	switch {
+	case d == nil:
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	case denied:
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "access_denied"})
+	case expired:
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "expired_token"})
+	case identity == nil:
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+	default:
+		idToken, err := i.IdToken(*identity)
+		if err != nil {
+			writeJson(w, http.StatusInternalServerError, map[string]string{"error": "server_error", "error_description": err.Error()})
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]interface{}{
+			"access_token": randomString(32),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	}
+}
+
+var devicePage = template.Must(template.New("device").Parse(`<!doctype html>
+<html>
+<head><title>Mock OIDC sign in</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 40px auto">
+<h2>Mock OIDC sign in</h2>
+{{if .Message}}<p><strong>{{.Message}}</strong></p>{{end}}
+<form method="post">
+<p><label>Code<br><input name="user_code" value="{{.UserCode}}" required></label></p>
+<p><label>Email<br><input name="email" type="email" required></label></p>
+<p><label>Name<br><input name="name"></label></p>
+<p><label>Groups (comma-separated)<br><input name="groups"></label></p>
+<p><button name="action" value="approve">Sign in</button> <button name="action" value="deny">Deny</button></p>
+</form>
+</body>
+</html>`))
+
+// handleDevicePage lets someone approve a device by hand when the issuer is run with cmd/mock-oidc
+func (i *Issuer) handleDevicePage(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		UserCode string
+		Message  string
+	}{UserCode: r.URL.Query().Get("user_code")}
+
+	if r.Method == http.MethodPost {
+		userCode := strings.TrimSpace(r.PostFormValue("user_code"))
+		data.UserCode = userCode
+
+		var err error
+		if r.PostFormValue("action") == "deny" {
+			err = i.Deny(userCode)
+			data.Message = "Denied. You can close this window."
+		} else {
+			var groups []string
+			for _, g := range strings.Split(r.PostFormValue("groups"), ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					groups = append(groups, g)
+				}
+			}
+			err = i.Approve(userCode, Identity{
+				Email:  strings.TrimSpace(r.PostFormValue("email")),
+				Name:   strings.TrimSpace(r.PostFormValue("name")),
+				Groups: groups,
+			})
+			data.Message = "Signed in. You can close this window."
+		}
+
+		if err != nil {
+			data.Message = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	devicePage.Execute(w, data)
+}
+
+func (i *Issuer) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyId})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+const codeChars = "bcdfghjkmnpqrstvwxz23456789"
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeChars))))
+		if err != nil {
+			panic(err)
+		}
+		b[i] = codeChars[idx.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,393 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const httpTimeout = 10 * time.Second
+
+// keys are refetched at most this often when a token has an unknown key id
+const jwksRefetchInterval = 10 * time.Second
+
+// allowed clock skew when checking token expiry
+const clockSkew = time.Minute
+
+type discovery struct {
+	Issuer                      string `json:"issuer"`
+	JwksUri                     string `json:"jwks_uri"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+type Provider struct {
+	Config Config
+
+	client *http.Client
+	now    func() time.Time
+
+	mu            sync.Mutex
+	discovery     *discovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		Config: config,
+		client: &http.Client{Timeout: httpTimeout},
+		now:    time.Now,
+	}
+}
+
+// Claims are the ID token claims Plandex uses
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discovery
+	err := p.getJson(ctx, p.Config.Issuer+"/.well-known/openid-configuration", &doc)
+	if err != nil {
+		return nil, fmt.Errorf("error getting OIDC discovery document: %v", err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != p.Config.Issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %s doesn't match OIDC_ISSUER %s", doc.Issuer, p.Config.Issuer)
+	}
+
+	if doc.JwksUri == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing jwks_uri or token_endpoint")
+	}
+
+	if doc.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("OIDC issuer doesn't support the device authorization flow")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+
+	if p.now().Sub(p.keysFetchedAt) < jwksRefetchInterval {
+		return nil, fmt.Errorf("no signing key found for kid %q", kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = p.getJson(ctx, doc.JwksUri, &set)
+	if err != nil {
+		return nil, fmt.Errorf("error getting OIDC signing keys: %v", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJwk(k)
+		if err != nil {
+			// skip key types we don't use rather than failing the whole set
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = p.now()
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("no signing key found for kid %q", kid)
+}
+
+func (p *Provider) findKey(kid string) crypto.PublicKey {
+	if key, ok := p.keys[kid]; ok {
+		return key
+	}
+	// tokens without a kid are fine when there's only one key
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func parseJwk(k jwk) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// VerifyIdToken checks an ID token's signature, issuer, audience and expiry, and returns its claims
+func (p *Provider) VerifyIdToken(ctx context.Context, rawToken string) (*Claims, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token header: %v", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token header: %v", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token signature: %v", err)
+	}
+
+	key, err := p.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token payload: %v", err)
+	}
+
+	var payload map[string]interface{}
+	err = json.Unmarshal(payloadBytes, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token payload: %v", err)
+	}
+
+	return p.validateClaims(payload)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported ID token algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("ID token algorithm %s doesn't match RSA key", alg)
+		}
+		if rsa.VerifyPKCS1v15(k, hash, digest, sig) != nil {
+			return errors.New("invalid ID token signature")
+		}
+
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("ID token algorithm %s doesn't match EC key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid ID token signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid ID token signature")
+		}
+
+	default:
+		return errors.New("unsupported signing key")
+	}
+
+	return nil
+}
+
+func (p *Provider) validateClaims(payload map[string]interface{}) (*Claims, error) {
+	if iss, _ := payload["iss"].(string); strings.TrimSuffix(iss, "/") != p.Config.Issuer {
+		return nil, fmt.Errorf("ID token issuer %q doesn't match", iss)
+	}
+
+	var audOk bool
+	switch aud := payload["aud"].(type) {
+	case string:
+		audOk = aud == p.Config.ClientId
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == p.Config.ClientId {
+				audOk = true
+			}
+		}
+	}
+	if !audOk {
+		return nil, errors.New("ID token audience doesn't match OIDC_CLIENT_ID")
+	}
+
+	exp, ok := payload["exp"].(float64)
+	if !ok {
+		return nil, errors.New("ID token has no expiry")
+	}
+	if p.now().After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("ID token has expired")
+	}
+
+	claims := &Claims{}
+	claims.Subject, _ = payload["sub"].(string)
+	if claims.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	claims.Email, _ = payload["email"].(string)
+	claims.Email = strings.ToLower(claims.Email)
+
+	// some issuers send email_verified as a string
+	switch v := payload["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+
+	claims.Name, _ = payload["name"].(string)
+	if claims.Name == "" {
+		claims.Name, _ = payload["preferred_username"].(string)
+	}
+
+	switch groups := payload[p.Config.GroupsClaim].(type) {
+	case string:
+		claims.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				claims.Groups = append(claims.Groups, s)
+			}
+		}
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) getJson(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
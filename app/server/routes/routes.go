@@ -9,6 +9,8 @@ import (
 	"plandex-server/handlers"
 	"plandex-server/hooks"
 
+	shared "plandex-shared"
+
 	"github.com/gorilla/mux"
 )
 
@@ -27,6 +29,19 @@ func EnsureHandlePlandex() {
 	}
 }
 
+// readScoped, tellScoped and applyScoped set the scope an API token needs to call a route. Routes registered without one can only be called after signing in.
+func readScoped(route *mux.Route) {
+	handlers.SetApiTokenScope(route, shared.ApiTokenScopeRead)
+}
+
+func tellScoped(route *mux.Route) {
+	handlers.SetApiTokenScope(route, shared.ApiTokenScopeTell)
+}
+
+func applyScoped(route *mux.Route) {
+	handlers.SetApiTokenScope(route, shared.ApiTokenScopeApply)
+}
+
 func AddHealthRoutes(r *mux.Router) {
 	EnsureHandlePlandex()
 
@@ -96,103 +111,152 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/accounts/sign_out", false, handlers.SignOutHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/accounts", false, handlers.CreateAccountHandler).Methods("POST")
 
-	HandlePlandexFn(r, prefix+"/orgs/session", false, handlers.GetOrgSessionHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/orgs", false, handlers.ListOrgsHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/orgs", false, handlers.CreateOrgHandler).Methods("POST")
-
-	HandlePlandexFn(r, prefix+"/users", false, handlers.ListUsersHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/orgs/users/{userId}", false, handlers.DeleteOrgUserHandler).Methods("DELETE")
-	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.ListOrgRolesHandler).Methods("GET")
-
-	HandlePlandexFn(r, prefix+"/invites", false, handlers.InviteUserHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/invites/pending", false, handlers.ListPendingInvitesHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/invites/accepted", false, handlers.ListAcceptedInvitesHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/invites/all", false, handlers.ListAllInvitesHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/invites/{inviteId}", false, handlers.DeleteInviteHandler).Methods("DELETE")
-
-	HandlePlandexFn(r, prefix+"/projects", false, handlers.CreateProjectHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/projects", false, handlers.ListProjectsHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/projects/{projectId}/set_plan", false, handlers.ProjectSetPlanHandler).Methods("PUT")
-	HandlePlandexFn(r, prefix+"/projects/{projectId}/rename", false, handlers.RenameProjectHandler).Methods("PUT")
-
-	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/current_branches", false, handlers.GetCurrentBranchByPlanIdHandler).Methods("POST")
-
-	HandlePlandexFn(r, prefix+"/plans", false, handlers.ListPlansHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/archive", false, handlers.ListArchivedPlansHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/ps", false, handlers.ListPlansRunningHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/accounts/oidc", false, handlers.GetOidcConfigHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/accounts/oidc/device", false, handlers.StartOidcDeviceAuthHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/accounts/oidc/device/token", false, handlers.PollOidcDeviceAuthHandler).Methods("POST")
 
-	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("POST")
-
-	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("DELETE")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}", false, handlers.GetPlanHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}", false, handlers.DeletePlanHandler).Methods("DELETE")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/current_plan/{sha}", false, handlers.CurrentPlanHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/current_plan", false, handlers.CurrentPlanHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/apply", false, handlers.ApplyPlanHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/archive", false, handlers.ArchivePlanHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/unarchive", false, handlers.UnarchivePlanHandler).Methods("PATCH")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/rename", false, handlers.RenamePlanHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_all", false, handlers.RejectAllChangesHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_file", false, handlers.RejectFileHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_files", false, handlers.RejectFilesHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diffs", false, handlers.GetPlanDiffsHandler).Methods("GET")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.ListContextHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.LoadContextHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context/{contextId}/body", false, handlers.GetContextBodyHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.UpdateContextHandler).Methods("PUT")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.DeleteContextHandler).Methods("DELETE")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/convo", false, handlers.ListConvoHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/rewind", false, handlers.RewindPlanHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/logs", false, handlers.ListLogsHandler).Methods("GET")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/branches", false, handlers.ListBranchesHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/branches/{branch}", false, handlers.DeleteBranchHandler).Methods("DELETE")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/branches", false, handlers.CreateBranchHandler).Methods("POST")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.GetSettingsHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.UpdateSettingsHandler).Methods("PUT")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/status", false, handlers.GetPlanStatusHandler).Methods("GET")
-
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/tell", true, handlers.TellPlanHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build", true, handlers.BuildPlanHandler).Methods("PATCH")
-
-	HandlePlandexFn(r, prefix+"/custom_models", false, handlers.ListCustomModelsHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/custom_models", false, handlers.CreateCustomModelHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/custom_models/{modelId}", false, handlers.DeleteAvailableModelHandler).Methods("DELETE")
-	HandlePlandexFn(r, prefix+"/custom_models/{modelId}", false, handlers.UpdateCustomModelHandler).Methods("PUT")
+	readScoped(HandlePlandexFn(r, prefix+"/orgs/session", false, handlers.GetOrgSessionHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/orgs", false, handlers.ListOrgsHandler).Methods("GET"))
+	HandlePlandexFn(r, prefix+"/orgs", false, handlers.CreateOrgHandler).Methods("POST")
 
-	HandlePlandexFn(r, prefix+"/model_sets", false, handlers.ListModelPacksHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/model_sets", false, handlers.CreateModelPackHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/model_sets/{setId}", false, handlers.DeleteModelPackHandler).Methods("DELETE")
-	HandlePlandexFn(r, prefix+"/model_sets/{setId}", false, handlers.UpdateModelPackHandler).Methods("PUT")
-	HandlePlandexFn(r, prefix+"/default_settings", false, handlers.GetDefaultSettingsHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/default_settings", false, handlers.UpdateDefaultSettingsHandler).Methods("PUT")
+	readScoped(HandlePlandexFn(r, prefix+"/users", false, handlers.ListUsersHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/orgs/users/{userId}", false, handlers.DeleteOrgUserHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/orgs/users/{userId}/role", false, handlers.SetOrgUserRoleHandler).Methods("PUT"))
+	readScoped(HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.ListOrgRolesHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.CreateOrgRoleHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/orgs/roles/current", false, handlers.GetCurrentOrgRoleHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.UpdateOrgRoleHandler).Methods("PUT"))
+	tellScoped(HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.DeleteOrgRoleHandler).Methods("DELETE"))
+	readScoped(HandlePlandexFn(r, prefix+"/orgs/permissions", false, handlers.ListOrgPermissionsHandler).Methods("GET"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/invites", false, handlers.InviteUserHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/invites/pending", false, handlers.ListPendingInvitesHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/invites/accepted", false, handlers.ListAcceptedInvitesHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/invites/all", false, handlers.ListAllInvitesHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/invites/{inviteId}", false, handlers.DeleteInviteHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/invites/{inviteId}/role", false, handlers.SetInviteRoleHandler).Methods("PUT"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/projects", false, handlers.CreateProjectHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/projects", false, handlers.ListProjectsHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/set_plan", false, handlers.ProjectSetPlanHandler).Methods("PUT"))
+	tellScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/rename", false, handlers.RenameProjectHandler).Methods("PUT"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/current_branches", false, handlers.GetCurrentBranchByPlanIdHandler).Methods("POST"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans", false, handlers.ListPlansHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/archive", false, handlers.ListArchivedPlansHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/ps", false, handlers.ListPlansRunningHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/shared", false, handlers.ListSharedPlansHandler).Methods("GET"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("POST"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("DELETE"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}", false, handlers.GetPlanHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}", false, handlers.DeletePlanHandler).Methods("DELETE"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/export", false, handlers.ExportPlanHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/import", false, handlers.ImportPlanHandler).Methods("POST"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/current_plan/{sha}", false, handlers.CurrentPlanHandler).Methods("GET"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/current_plan", false, handlers.CurrentPlanHandler).Methods("GET"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/apply", false, handlers.ApplyPlanHandler).Methods("PATCH"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/archive", false, handlers.ArchivePlanHandler).Methods("PATCH"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/unarchive", false, handlers.UnarchivePlanHandler).Methods("PATCH"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/rename", false, handlers.RenamePlanHandler).Methods("PATCH"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.ListPlanSharesHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.SharePlanHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/shares/{userId}", false, handlers.UnsharePlanHandler).Methods("DELETE"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_all", false, handlers.RejectAllChangesHandler).Methods("PATCH"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_file", false, handlers.RejectFileHandler).Methods("PATCH"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_files", false, handlers.RejectFilesHandler).Methods("PATCH"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_replacement", false, handlers.RejectReplacementHandler).Methods("PATCH"))
+	applyScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/edit_replacement", false, handlers.EditReplacementHandler).Methods("PATCH"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diffs", false, handlers.GetPlanDiffsHandler).Methods("GET"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.ListContextHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.LoadContextHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context/{contextId}/body", false, handlers.GetContextBodyHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.UpdateContextHandler).Methods("PUT"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.DeleteContextHandler).Methods("DELETE"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/convo", false, handlers.ListConvoHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/rewind", false, handlers.RewindPlanHandler).Methods("PATCH"))
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/logs", false, handlers.ListLogsHandler).Methods("GET"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/branches", false, handlers.ListBranchesHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/branches/{branch}", false, handlers.DeleteBranchHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/branches", false, handlers.CreateBranchHandler).Methods("POST"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.GetSettingsHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.UpdateSettingsHandler).Methods("PUT"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/status", false, handlers.GetPlanStatusHandler).Methods("GET"))
+
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/tell", true, handlers.TellPlanHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build", true, handlers.BuildPlanHandler).Methods("PATCH"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/custom_models", false, handlers.ListCustomModelsHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/custom_models", false, handlers.CreateCustomModelHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/custom_models/{modelId}", false, handlers.DeleteAvailableModelHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/custom_models/{modelId}", false, handlers.UpdateCustomModelHandler).Methods("PUT"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/model_sets", false, handlers.ListModelPacksHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/model_sets", false, handlers.CreateModelPackHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/model_sets/{setId}", false, handlers.DeleteModelPackHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/model_sets/{setId}", false, handlers.UpdateModelPackHandler).Methods("PUT"))
+	readScoped(HandlePlandexFn(r, prefix+"/default_settings", false, handlers.GetDefaultSettingsHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/default_settings", false, handlers.UpdateDefaultSettingsHandler).Methods("PUT"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/spend_budgets", false, handlers.ListSpendBudgetsHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/spend_budgets", false, handlers.SetSpendBudgetHandler).Methods("PUT"))
+	tellScoped(HandlePlandexFn(r, prefix+"/spend_budgets/{budgetId}", false, handlers.DeleteSpendBudgetHandler).Methods("DELETE"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.GetOrgExecPolicyHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.SetOrgExecPolicyHandler).Methods("PUT"))
+	tellScoped(HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.DeleteOrgExecPolicyHandler).Methods("DELETE"))
+
+	readScoped(HandlePlandexFn(r, prefix+"/webhooks", false, handlers.ListWebhooksHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/webhooks", false, handlers.CreateWebhookHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/webhooks/{webhookId}", false, handlers.DeleteWebhookHandler).Methods("DELETE"))
+	tellScoped(HandlePlandexFn(r, prefix+"/webhooks/{webhookId}/test", false, handlers.TestWebhookHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/webhooks/{webhookId}/deliveries", false, handlers.ListWebhookDeliveriesHandler).Methods("GET"))
+
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.ListApiTokensHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.CreateApiTokenHandler).Methods("POST")
+	readScoped(HandlePlandexFn(r, prefix+"/api_tokens/current", false, handlers.GetApiTokenSessionHandler).Methods("GET"))
+	HandlePlandexFn(r, prefix+"/api_tokens/{tokenId}", false, handlers.RevokeApiTokenHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/service_accounts", false, handlers.ListServiceAccountsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/service_accounts", false, handlers.CreateServiceAccountHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/service_accounts/{serviceAccountId}", false, handlers.DeleteServiceAccountHandler).Methods("DELETE")
+
+	readScoped(HandlePlandexFn(r, prefix+"/usage/summary", false, handlers.GetUsageSummaryHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/usage/log", false, handlers.GetUsageLogHandler).Methods("POST"))
 
-	HandlePlandexFn(r, prefix+"/file_map", false, handlers.GetFileMapHandler).Methods("POST")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/load_cached_file_map", false, handlers.LoadCachedFileMapHandler).Methods("POST")
+	readScoped(HandlePlandexFn(r, prefix+"/file_map", false, handlers.GetFileMapHandler).Methods("POST"))
+	readScoped(HandlePlandexFn(r, prefix+"/file_map/symbols", false, handlers.GetFileSymbolsHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/load_cached_file_map", false, handlers.LoadCachedFileMapHandler).Methods("POST"))
 
-	HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.GetPlanConfigHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.UpdatePlanConfigHandler).Methods("PUT")
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.GetPlanConfigHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.UpdatePlanConfigHandler).Methods("PUT"))
 
-	HandlePlandexFn(r, prefix+"/default_plan_config", false, handlers.GetDefaultPlanConfigHandler).Methods("GET")
-	HandlePlandexFn(r, prefix+"/default_plan_config", false, handlers.UpdateDefaultPlanConfigHandler).Methods("PUT")
+	readScoped(HandlePlandexFn(r, prefix+"/default_plan_config", false, handlers.GetDefaultPlanConfigHandler).Methods("GET"))
+	tellScoped(HandlePlandexFn(r, prefix+"/default_plan_config", false, handlers.UpdateDefaultPlanConfigHandler).Methods("PUT"))
 }
 
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
 	EnsureHandlePlandex()
 
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/connect", true, handlers.ConnectPlanHandler).Methods("PATCH")
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/stop", false, handlers.StopPlanHandler).Methods("DELETE")
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/connect", true, handlers.ConnectPlanHandler).Methods("PATCH"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/stop", false, handlers.StopPlanHandler).Methods("DELETE"))
 
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/respond_missing_file", false, handlers.RespondMissingFileHandler).Methods("POST")
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/respond_missing_file", false, handlers.RespondMissingFileHandler).Methods("POST"))
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diagnostics", false, handlers.RespondDiagnosticsHandler).Methods("POST"))
 
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/auto_load_context", false, handlers.AutoLoadContextHandler).Methods("POST")
+	tellScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/auto_load_context", false, handlers.AutoLoadContextHandler).Methods("POST"))
 
-	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build_status", false, handlers.GetBuildStatusHandler).Methods("GET")
+	readScoped(HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build_status", false, handlers.GetBuildStatusHandler).Methods("GET"))
 }
@@ -0,0 +1,238 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/handlers"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestRouter(t *testing.T) *mux.Router {
+	prev := HandlePlandexFn
+	t.Cleanup(func() { HandlePlandexFn = prev })
+
+	RegisterHandlePlandex(func(router *mux.Router, path string, isStreaming bool, handler PlandexHandler) *mux.Route {
+		return router.HandleFunc(path, handler)
+	})
+
+	r := mux.NewRouter()
+	AddHealthRoutes(r)
+	AddApiRoutes(r)
+	AddProxyableApiRoutes(r)
+
+	return r
+}
+
+// every api route is listed, so a new route fails here until it's given a scope or deliberately left session-only
+func TestApiTokenScopes(t *testing.T) {
+	const (
+		read  = shared.ApiTokenScopeRead
+		tell  = shared.ApiTokenScopeTell
+		apply = shared.ApiTokenScopeApply
+		none  = shared.ApiTokenScope("")
+	)
+
+	expected := map[string]shared.ApiTokenScope{
+		"POST /accounts/email_verifications":                    none,
+		"POST /accounts/email_verifications/check_pin":          none,
+		"POST /accounts/sign_in_codes":                          none,
+		"POST /accounts/sign_in":                                none,
+		"POST /accounts/sign_out":                               none,
+		"POST /accounts":                                        none,
+		"GET /accounts/oidc":                                    none,
+		"POST /accounts/oidc/device":                            none,
+		"POST /accounts/oidc/device/token":                      none,
+		"GET /orgs/session":                                     read,
+		"GET /orgs":                                             read,
+		"POST /orgs":                                            none,
+		"GET /users":                                            read,
+		"DELETE /orgs/users/{userId}":                           tell,
+		"PUT /orgs/users/{userId}/role":                         tell,
+		"GET /orgs/roles":                                       read,
+		"POST /orgs/roles":                                      tell,
+		"GET /orgs/roles/current":                               read,
+		"PUT /orgs/roles/{roleId}":                              tell,
+		"DELETE /orgs/roles/{roleId}":                           tell,
+		"GET /orgs/permissions":                                 read,
+		"POST /invites":                                         tell,
+		"GET /invites/pending":                                  read,
+		"GET /invites/accepted":                                 read,
+		"GET /invites/all":                                      read,
+		"DELETE /invites/{inviteId}":                            tell,
+		"PUT /invites/{inviteId}/role":                          tell,
+		"POST /projects":                                        tell,
+		"GET /projects":                                         read,
+		"PUT /projects/{projectId}/set_plan":                    tell,
+		"PUT /projects/{projectId}/rename":                      tell,
+		"POST /projects/{projectId}/plans/current_branches":     read,
+		"GET /plans":                                            read,
+		"GET /plans/archive":                                    read,
+		"GET /plans/ps":                                         read,
+		"GET /plans/shared":                                     read,
+		"POST /projects/{projectId}/plans":                      tell,
+		"DELETE /projects/{projectId}/plans":                    tell,
+		"GET /plans/{planId}":                                   read,
+		"DELETE /plans/{planId}":                                tell,
+		"GET /plans/{planId}/export":                            read,
+		"POST /projects/{projectId}/plans/import":               tell,
+		"GET /plans/{planId}/current_plan/{sha}":                read,
+		"GET /plans/{planId}/{branch}/current_plan":             read,
+		"PATCH /plans/{planId}/{branch}/apply":                  apply,
+		"PATCH /plans/{planId}/archive":                         tell,
+		"PATCH /plans/{planId}/unarchive":                       tell,
+		"PATCH /plans/{planId}/rename":                          tell,
+		"GET /plans/{planId}/shares":                            read,
+		"POST /plans/{planId}/shares":                           tell,
+		"DELETE /plans/{planId}/shares/{userId}":                tell,
+		"PATCH /plans/{planId}/{branch}/reject_all":             apply,
+		"PATCH /plans/{planId}/{branch}/reject_file":            apply,
+		"PATCH /plans/{planId}/{branch}/reject_files":           apply,
+		"PATCH /plans/{planId}/{branch}/reject_replacement":     apply,
+		"PATCH /plans/{planId}/{branch}/edit_replacement":       apply,
+		"GET /plans/{planId}/{branch}/diffs":                    read,
+		"GET /plans/{planId}/{branch}/context":                  read,
+		"POST /plans/{planId}/{branch}/context":                 tell,
+		"GET /plans/{planId}/{branch}/context/{contextId}/body": read,
+		"PUT /plans/{planId}/{branch}/context":                  tell,
+		"DELETE /plans/{planId}/{branch}/context":               tell,
+		"GET /plans/{planId}/{branch}/convo":                    read,
+		"PATCH /plans/{planId}/{branch}/rewind":                 tell,
+		"GET /plans/{planId}/{branch}/logs":                     read,
+		"GET /plans/{planId}/branches":                          read,
+		"DELETE /plans/{planId}/branches/{branch}":              tell,
+		"POST /plans/{planId}/{branch}/branches":                tell,
+		"GET /plans/{planId}/{branch}/settings":                 read,
+		"PUT /plans/{planId}/{branch}/settings":                 tell,
+		"GET /plans/{planId}/{branch}/status":                   read,
+		"POST /plans/{planId}/{branch}/tell":                    tell,
+		"PATCH /plans/{planId}/{branch}/build":                  tell,
+		"GET /custom_models":                                    read,
+		"POST /custom_models":                                   tell,
+		"DELETE /custom_models/{modelId}":                       tell,
+		"PUT /custom_models/{modelId}":                          tell,
+		"GET /model_sets":                                       read,
+		"POST /model_sets":                                      tell,
+		"DELETE /model_sets/{setId}":                            tell,
+		"PUT /model_sets/{setId}":                               tell,
+		"GET /default_settings":                                 read,
+		"PUT /default_settings":                                 tell,
+		"GET /spend_budgets":                                    read,
+		"PUT /spend_budgets":                                    tell,
+		"DELETE /spend_budgets/{budgetId}":                      tell,
+		"GET /exec_policy":                                      read,
+		"PUT /exec_policy":                                      tell,
+		"DELETE /exec_policy":                                   tell,
+		"GET /webhooks":                                         read,
+		"POST /webhooks":                                        tell,
+		"DELETE /webhooks/{webhookId}":                          tell,
+		"POST /webhooks/{webhookId}/test":                       tell,
+		"GET /webhooks/{webhookId}/deliveries":                  read,
+		"GET /api_tokens":                                       none,
+		"POST /api_tokens":                                      none,
+		"GET /api_tokens/current":                               read,
+		"DELETE /api_tokens/{tokenId}":                          none,
+		"GET /service_accounts":                                 none,
+		"POST /service_accounts":                                none,
+		"DELETE /service_accounts/{serviceAccountId}":           none,
+		"POST /usage/summary":                                   read,
+		"POST /usage/log":                                       read,
+		"POST /file_map":                                        read,
+		"POST /file_map/symbols":                                read,
+		"POST /plans/{planId}/{branch}/load_cached_file_map":    tell,
+		"GET /plans/{planId}/config":                            read,
+		"PUT /plans/{planId}/config":                            tell,
+		"GET /default_plan_config":                              read,
+		"PUT /default_plan_config":                              tell,
+		"PATCH /plans/{planId}/{branch}/connect":                read,
+		"DELETE /plans/{planId}/{branch}/stop":                  tell,
+		"POST /plans/{planId}/{branch}/respond_missing_file":    tell,
+		"POST /plans/{planId}/{branch}/diagnostics":             tell,
+		"POST /plans/{planId}/{branch}/auto_load_context":       tell,
+		"GET /plans/{planId}/{branch}/build_status":             read,
+	}
+
+	r := newTestRouter(t)
+
+	seen := map[string]bool{}
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// health routes accept any method and aren't authenticated
+			if path != "/health" && path != "/version" {
+				t.Errorf("%s is registered without a method", path)
+			}
+			return nil
+		}
+
+		key := strings.Join(methods, ",") + " " + path
+		seen[key] = true
+
+		want, ok := expected[key]
+		if !ok {
+			t.Errorf("%s isn't in the expected scopes", key)
+			return nil
+		}
+
+		scope, ok := handlers.GetApiTokenScope(route)
+		if want == none {
+			if ok {
+				t.Errorf("%s: expected API tokens to be rejected, got scope %s", key, scope)
+			}
+		} else if !ok || scope != want {
+			t.Errorf("%s: scope = %q, want %q", key, scope, want)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking routes: %v", err)
+	}
+
+	for key := range expected {
+		if !seen[key] {
+			t.Errorf("%s is expected but isn't registered", key)
+		}
+	}
+}
+
+func TestApiTokenScopeMatchesRequestRoute(t *testing.T) {
+	r := newTestRouter(t)
+
+	tests := []struct {
+		method string
+		path   string
+		want   shared.ApiTokenScope
+		wantOk bool
+	}{
+		{http.MethodGet, "/plans/plan-id/main/current_plan", shared.ApiTokenScopeRead, true},
+		{http.MethodPost, "/plans/plan-id/main/tell", shared.ApiTokenScopeTell, true},
+		{http.MethodPatch, "/plans/plan-id/main/apply", shared.ApiTokenScopeApply, true},
+		{http.MethodPatch, "/plans/plan-id/main/connect", shared.ApiTokenScopeRead, true},
+		{http.MethodPost, "/api_tokens", "", false},
+		{http.MethodPost, "/accounts/sign_out", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			var match mux.RouteMatch
+			if !r.Match(httptest.NewRequest(tt.method, tt.path, nil), &match) {
+				t.Fatal("no route matched")
+			}
+
+			scope, ok := handlers.GetApiTokenScope(match.Route)
+			if scope != tt.want || ok != tt.wantOk {
+				t.Errorf("scope = %q, %v, want %q, %v", scope, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"plandex-server/syntax/file_map"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+const (
+	// definitions longer than this are split into their nested definitions when they have any
+	maxChunkLines = 120
+
+	// files without definitions are split into fixed windows
+	windowChunkLines = 60
+
+	maxChunkBytes = 8000
+)
+
+type Chunk struct {
+	Name      string
+	StartLine int
+	EndLine   int
+	Body      string
+}
+
+// ChunkFile splits a file into one chunk per top-level definition, descending into large definitions like classes. Code between definitions isn't indexed. Files that can't be mapped, or have no definitions, are split into fixed windows of lines instead.
+func ChunkFile(ctx context.Context, path string, content string) ([]Chunk, error) {
+	lines := strings.Split(content, "\n")
+
+	var chunks []Chunk
+
+	if shared.HasFileMapSupport(path) {
+		fileMap, err := file_map.MapFile(ctx, path, []byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("error mapping %s: %v", path, err)
+		}
+
+		var walk func(defs []file_map.Definition, parentName string)
+		walk = func(defs []file_map.Definition, parentName string) {
+			for _, def := range defs {
+				name := def.Name
+				if parentName != "" && name != "" {
+					name = parentName + "." + name
+				} else if name == "" {
+					name = parentName
+				}
+
+				if def.Line <= 0 || def.EndLine < def.Line || def.EndLine > len(lines) {
+					walk(def.Children, name)
+					continue
+				}
+
+				if def.EndLine-def.Line+1 > maxChunkLines && len(def.Children) > 0 {
+					walk(def.Children, name)
+					continue
+				}
+
+				chunks = append(chunks, Chunk{
+					Name:      name,
+					StartLine: def.Line,
+					EndLine:   def.EndLine,
+					Body:      truncateChunk(strings.Join(lines[def.Line-1:def.EndLine], "\n")),
+				})
+			}
+		}
+		walk(fileMap.Definitions, "")
+	}
+
+	if len(chunks) > 0 {
+		return chunks, nil
+	}
+
+	name := filepath.Base(path)
+	for start := 0; start < len(lines); start += windowChunkLines {
+		end := min(start+windowChunkLines, len(lines))
+		body := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+
+		chunks = append(chunks, Chunk{
+			Name:      name,
+			StartLine: start + 1,
+			EndLine:   end,
+			Body:      truncateChunk(body),
+		})
+	}
+
+	return chunks, nil
+}
+
+func truncateChunk(body string) string {
+	if len(body) <= maxChunkBytes {
+		return body
+	}
+	return strings.ToValidUTF8(body[:maxChunkBytes], "")
+}
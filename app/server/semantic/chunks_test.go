@@ -0,0 +1,62 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkFile(t *testing.T) {
+	t.Run("definitions", func(t *testing.T) {
+		source := `package main
+
+import "fmt"
+
+type Server struct {
+	addr string
+}
+
+func (s *Server) Start() error {
+	fmt.Println("starting", s.addr)
+	return nil
+}
+`
+		chunks, err := ChunkFile(context.Background(), "main.go", source)
+		assert.NoError(t, err)
+		assert.Equal(t, []Chunk{
+			{Name: "Server", StartLine: 5, EndLine: 7, Body: "type Server struct {\n\taddr string\n}"},
+			{Name: "Server.Start", StartLine: 9, EndLine: 12, Body: "func (s *Server) Start() error {\n\tfmt.Println(\"starting\", s.addr)\n\treturn nil\n}"},
+		}, chunks)
+	})
+
+	t.Run("large definitions split into children", func(t *testing.T) {
+		var methods []string
+		for i := 0; i < 30; i++ {
+			methods = append(methods, fmt.Sprintf("    def method%d(self):\n        a = %d\n        b = a + 1\n        return b\n", i, i))
+		}
+		source := "class Handler:\n" + strings.Join(methods, "\n")
+
+		chunks, err := ChunkFile(context.Background(), "handler.py", source)
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 30)
+		assert.Equal(t, "Handler.method0", chunks[0].Name)
+		assert.Equal(t, 2, chunks[0].StartLine)
+	})
+
+	t.Run("unsupported files use line windows", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 130; i++ {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+
+		chunks, err := ChunkFile(context.Background(), "notes/todo.txt", strings.Join(lines, "\n"))
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 3)
+		assert.Equal(t, "todo.txt", chunks[2].Name)
+		assert.Equal(t, 121, chunks[2].StartLine)
+		assert.Equal(t, 130, chunks[2].EndLine)
+	})
+}
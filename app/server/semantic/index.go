@@ -0,0 +1,55 @@
+package semantic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// Semantic index for auto context. Project files are chunked by definition when they're mapped and stored in postgres with their search terms. Embeddings are added in the background the first time a plan with an embedding model searches the index (see search.go), so indexing never needs model credentials.
+
+// IndexFiles adds or replaces the chunks for each input. Inputs with an unchanged sha are skipped.
+func IndexFiles(ctx context.Context, orgId, projectId string, inputs shared.FileMapInputs) error {
+	indexedShas, err := db.GetSemanticIndexShas(projectId)
+	if err != nil {
+		return err
+	}
+
+	chunksByPath := map[string][]*db.SemanticChunk{}
+
+	for path, content := range inputs {
+		hash := sha256.Sum256([]byte(content))
+		sha := hex.EncodeToString(hash[:])
+		if indexedShas[path] == sha {
+			continue
+		}
+
+		chunks, err := ChunkFile(ctx, path, content)
+		if err != nil {
+			return fmt.Errorf("error chunking %s: %v", path, err)
+		}
+
+		dbChunks := []*db.SemanticChunk{}
+		for _, chunk := range chunks {
+			dbChunks = append(dbChunks, &db.SemanticChunk{
+				OrgId:     orgId,
+				ProjectId: projectId,
+				Path:      path,
+				Sha:       sha,
+				Name:      chunk.Name,
+				StartLine: chunk.StartLine,
+				EndLine:   chunk.EndLine,
+				Body:      chunk.Body,
+				Terms:     strings.Join(Terms(path+"\n"+chunk.Name+"\n"+chunk.Body), " "),
+			})
+		}
+		chunksByPath[path] = dbChunks
+	}
+
+	return db.ReplaceSemanticChunks(ctx, projectId, chunksByPath)
+}
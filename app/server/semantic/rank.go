@@ -0,0 +1,154 @@
+package semantic
+
+import (
+	"math"
+	"plandex-server/db"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// standard bm25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var identifierPattern = regexp.MustCompile(`[\p{L}_][\p{L}\p{N}_]*`)
+
+// Terms normalizes text into lowercase search terms. Identifiers are kept whole and also split on underscores and camelCase, so 'parseHTTPRequest' matches 'parse', 'http', 'request' and 'parsehttprequest'.
+func Terms(text string) []string {
+	var terms []string
+	for _, identifier := range identifierPattern.FindAllString(text, -1) {
+		parts := splitIdentifier(identifier)
+
+		whole := strings.ToLower(strings.Trim(identifier, "_"))
+		if len(parts) != 1 && len(whole) > 1 {
+			terms = append(terms, whole)
+		}
+
+		for _, part := range parts {
+			if len(part) > 1 {
+				terms = append(terms, part)
+			}
+		}
+	}
+	return terms
+}
+
+func splitIdentifier(identifier string) []string {
+	var parts []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(identifier)
+	for i, r := range runes {
+		if r == '_' {
+			flush()
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// 'parseHTTP' splits before 'H', 'HTTPRequest' splits before 'R'
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return parts
+}
+
+// bm25Scores scores each document's terms against the query terms
+func bm25Scores(query []string, docs [][]string) []float64 {
+	scores := make([]float64, len(docs))
+	if len(docs) == 0 {
+		return scores
+	}
+
+	queryTerms := map[string]bool{}
+	for _, term := range query {
+		queryTerms[term] = true
+	}
+
+	docFreq := map[string]int{}
+	termFreqs := make([]map[string]int, len(docs))
+	totalLen := 0
+	for i, doc := range docs {
+		totalLen += len(doc)
+		freqs := map[string]int{}
+		for _, term := range doc {
+			if queryTerms[term] {
+				freqs[term]++
+			}
+		}
+		for term := range freqs {
+			docFreq[term]++
+		}
+		termFreqs[i] = freqs
+	}
+
+	avgLen := float64(totalLen) / float64(len(docs))
+	if avgLen == 0 {
+		return scores
+	}
+	n := float64(len(docs))
+
+	for i, doc := range docs {
+		docLen := float64(len(doc))
+		for term := range queryTerms {
+			tf := float64(termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			scores[i] += idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*docLen/avgLen))
+		}
+	}
+
+	return scores
+}
+
+type scoredChunk struct {
+	id    string
+	score float64
+}
+
+// rankBM25 scores chunks by their stored terms, best first. Chunks that don't match at all are left out.
+func rankBM25(query string, chunks []*db.SemanticChunk, maxResults int) []scoredChunk {
+	docs := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		docs[i] = strings.Fields(chunk.Terms)
+	}
+	scores := bm25Scores(Terms(query), docs)
+
+	var res []scoredChunk
+	for i, chunk := range chunks {
+		if scores[i] > 0 {
+			res = append(res, scoredChunk{id: chunk.Id, score: scores[i]})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].score > res[j].score
+	})
+
+	if maxResults > 0 && len(res) > maxResults {
+		res = res[:maxResults]
+	}
+
+	return res
+}
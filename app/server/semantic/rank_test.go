@@ -0,0 +1,38 @@
+package semantic
+
+import (
+	"plandex-server/db"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"parsehttprequest", "parse", "http", "request"}, Terms("parseHTTPRequest"))
+	assert.Equal(t, []string{"max_retries", "max", "retries"}, Terms("MAX_RETRIES"))
+	assert.Equal(t, []string{"load", "context", "v2"}, Terms("load(context, v2)"))
+}
+
+func TestRankBM25(t *testing.T) {
+	newChunk := func(id, path, name, body string) *db.SemanticChunk {
+		return &db.SemanticChunk{
+			Id:    id,
+			Terms: strings.Join(Terms(path+"\n"+name+"\n"+body), " "),
+		}
+	}
+
+	chunks := []*db.SemanticChunk{
+		newChunk("refresh", "auth/session.go", "RefreshSession", "func RefreshSession(token string) error { return validateToken(token) }"),
+		newChunk("invoice", "billing/invoice.go", "CreateInvoice", "func CreateInvoice(orgId string) (*Invoice, error) { return nil, nil }"),
+		newChunk("validate", "auth/token.go", "validateToken", "func validateToken(token string) error { return nil }"),
+	}
+
+	results := rankBM25("where do we validate the session token?", chunks, 2)
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "refresh", results[0].id)
+	assert.Equal(t, "validate", results[1].id)
+
+	assert.Empty(t, rankBM25("kubernetes", chunks, 10))
+}
@@ -0,0 +1,236 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/shutdown"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// chunks embedded per round trip while embedding in the background
+	backgroundEmbedPageSize = 512
+
+	backgroundEmbedTimeout = 30 * time.Minute
+)
+
+// Embedder turns texts into vectors. Vectors from different embedders aren't comparable, so each is stored with its model name.
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+type SearchParams struct {
+	ProjectId  string
+	Query      string
+	Embedder   Embedder // nil ranks with bm25
+	MaxResults int
+}
+
+type Result struct {
+	Path      string
+	Name      string
+	StartLine int
+	EndLine   int
+	Body      string
+	Score     float64
+}
+
+// Search returns the indexed chunks most relevant to the query, best first. With an embedder, chunks are ranked by the pgvector index once they're all embedded--until then, missing embeddings are created in the background and the search falls back to bm25.
+func Search(ctx context.Context, params SearchParams) ([]*Result, error) {
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, nil
+	}
+
+	hasIndex, err := db.HasSemanticIndex(params.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if !hasIndex {
+		return nil, nil
+	}
+
+	if params.Embedder != nil {
+		results, ok, err := searchEmbeddings(ctx, params)
+		if err != nil {
+			log.Printf("Semantic search - error searching with %s, falling back to bm25: %v\n", params.Embedder.Model(), err)
+		} else if ok {
+			return results, nil
+		}
+	}
+
+	return searchBM25(params)
+}
+
+// searchEmbeddings returns ok=false when the index can't be ranked with the embedder yet
+func searchEmbeddings(ctx context.Context, params SearchParams) ([]*Result, bool, error) {
+	embedder := params.Embedder
+	model := embedder.Model()
+
+	enabled, err := db.SemanticVectorsEnabled()
+	if err != nil {
+		return nil, false, err
+	}
+	if !enabled {
+		log.Printf("Semantic search - pgvector isn't installed, using bm25 instead of %s\n", model)
+		return nil, false, nil
+	}
+
+	missing, err := db.CountUnembeddedSemanticChunks(params.ProjectId, model)
+	if err != nil {
+		return nil, false, err
+	}
+	if missing > 0 {
+		log.Printf("Semantic search - %d chunks aren't embedded with %s yet, using bm25 for now\n", missing, model)
+		embedInBackground(params.ProjectId, embedder)
+		return nil, false, nil
+	}
+
+	vectors, err := embedder.Embed(ctx, []string{params.Query})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(vectors) != 1 {
+		return nil, false, fmt.Errorf("expected 1 query embedding, got %d", len(vectors))
+	}
+
+	chunks, err := db.SearchSemanticEmbeddings(ctx, params.ProjectId, model, vectors[0], params.MaxResults)
+	if err != nil {
+		return nil, false, err
+	}
+
+	results := make([]*Result, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.Score <= 0 {
+			continue
+		}
+		results = append(results, newResult(chunk, chunk.Score))
+	}
+
+	return results, true, nil
+}
+
+func searchBM25(params SearchParams) ([]*Result, error) {
+	chunks, err := db.GetSemanticChunkTerms(params.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := rankBM25(params.Query, chunks, params.MaxResults)
+	if len(ranked) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.id
+	}
+
+	matches, err := db.GetSemanticChunksByIds(params.ProjectId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	matchesById := make(map[string]*db.SemanticChunk, len(matches))
+	for _, chunk := range matches {
+		matchesById[chunk.Id] = chunk
+	}
+
+	var results []*Result
+	for _, r := range ranked {
+		// a chunk can be replaced by re-indexing between the two queries
+		chunk, ok := matchesById[r.id]
+		if !ok {
+			continue
+		}
+		results = append(results, newResult(chunk, r.score))
+	}
+
+	return results, nil
+}
+
+func newResult(chunk *db.SemanticChunk, score float64) *Result {
+	return &Result{
+		Path:      chunk.Path,
+		Name:      chunk.Name,
+		StartLine: chunk.StartLine,
+		EndLine:   chunk.EndLine,
+		Body:      chunk.Body,
+		Score:     score,
+	}
+}
+
+// keyed by project id and model so each index is only embedded by one goroutine per server at a time
+var embeddingInBackground sync.Map
+
+// embedInBackground embeds the project's chunks that are missing a vector for the embedder's model, unless that's already underway. It runs after the search that started it returns, so the embedder must not depend on the request's context.
+func embedInBackground(projectId string, embedder Embedder) {
+	key := projectId + "|" + embedder.Model()
+	if _, loaded := embeddingInBackground.LoadOrStore(key, true); loaded {
+		return
+	}
+
+	go func() {
+		defer embeddingInBackground.Delete(key)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Semantic index - panic embedding in background: %v\n%s", r, debug.Stack())
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(shutdown.ShutdownCtx, backgroundEmbedTimeout)
+		defer cancel()
+
+		n, err := embedMissing(ctx, projectId, embedder)
+		if err != nil {
+			log.Printf("Semantic index - error embedding with %s after %d chunks: %v\n", embedder.Model(), n, err)
+			return
+		}
+		log.Printf("Semantic index - embedded %d chunks with %s\n", n, embedder.Model())
+	}()
+}
+
+func embedMissing(ctx context.Context, projectId string, embedder Embedder) (int, error) {
+	model := embedder.Model()
+	total := 0
+
+	for {
+		chunks, err := db.GetUnembeddedSemanticChunks(projectId, model, backgroundEmbedPageSize)
+		if err != nil {
+			return total, err
+		}
+		if len(chunks) == 0 {
+			return total, nil
+		}
+
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.Path + "\n" + chunk.Name + "\n" + chunk.Body
+		}
+
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return total, err
+		}
+		if len(vectors) != len(chunks) {
+			return total, fmt.Errorf("expected %d embeddings, got %d", len(chunks), len(vectors))
+		}
+
+		embeddingsById := make(map[string][]float32, len(chunks))
+		for i, chunk := range chunks {
+			embeddingsById[chunk.Id] = vectors[i]
+		}
+
+		err = db.StoreSemanticEmbeddings(ctx, model, embeddingsById)
+		if err != nil {
+			return total, err
+		}
+
+		total += len(chunks)
+	}
+}
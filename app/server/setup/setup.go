@@ -10,6 +10,8 @@ import (
 	"plandex-server/host"
 	"plandex-server/model/plan"
 	"plandex-server/shutdown"
+	"plandex-server/streambus"
+	"plandex-server/webhooks"
 	"syscall"
 	"time"
 )
@@ -36,6 +38,12 @@ func MustInitDb() {
 	if err != nil {
 		log.Fatal("Error caching org role ids: ", err)
 	}
+
+	// the postgres stream bus needs the db connection
+	err = streambus.Init()
+	if err != nil {
+		log.Fatal("Error initializing stream bus: ", err)
+	}
 }
 
 var shutdownHooks []func()
@@ -108,6 +116,8 @@ func StartServer(handler http.Handler, configureFn func(handler http.Handler) ht
 
 	log.Println("Started Plandex server on port " + externalPort)
 
+	webhooks.StartWorker(shutdown.ShutdownCtx)
+
 	if afterStart != nil {
 		afterStart()
 	}
@@ -0,0 +1,188 @@
+package streambus
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Stream fan-out between server instances. Active plans live in the memory of the instance that started them. With the default local backend, other instances reach them by proxying HTTP requests to that instance's internal ip. With STREAM_BUS=postgres, stream messages and control requests (stop, missing file responses, build status, etc.) go through postgres LISTEN/NOTIFY instead, so any instance behind a plain load balancer can serve them.
+
+type BackendType string
+
+const (
+	BackendLocal    BackendType = "local"
+	BackendPostgres BackendType = "postgres"
+)
+
+type Backend interface {
+	Publish(channel, payload string) error
+	// Subscribe returns a channel of messages that's closed if the subscriber falls too far behind, along with a func to unsubscribe
+	Subscribe(channel string) (<-chan string, func())
+	// Distributed is true when published messages reach subscribers on other server instances
+	Distributed() bool
+}
+
+var Current Backend = newLocalBackend()
+
+func Init() error {
+	backendType := BackendType(os.Getenv("STREAM_BUS"))
+
+	switch backendType {
+	case "", BackendLocal:
+		Current = newLocalBackend()
+	case BackendPostgres:
+		backend, err := newPostgresBackend()
+		if err != nil {
+			return fmt.Errorf("error starting postgres stream bus: %v", err)
+		}
+		Current = backend
+	default:
+		return fmt.Errorf("unknown STREAM_BUS %q", backendType)
+	}
+
+	log.Printf("Stream bus: %s\n", backendTypeOrDefault(backendType))
+
+	startControlServer()
+
+	return nil
+}
+
+func Distributed() bool {
+	return Current.Distributed()
+}
+
+func StreamChannel(planId, branch string) string {
+	return "stream:" + planId + "|" + branch
+}
+
+func backendTypeOrDefault(backendType BackendType) BackendType {
+	if backendType == "" {
+		return BackendLocal
+	}
+	return backendType
+}
+
+// hub dispatches messages to the subscribers in this process--both backends use it for delivery
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*subscriber]bool
+}
+
+func newHub() *hub {
+	return &hub{subscribers: map[string]map[*subscriber]bool{}}
+}
+
+func (h *hub) subscribe(channel string) (<-chan string, func()) {
+	sub := newSubscriber()
+
+	h.mu.Lock()
+	if h.subscribers[channel] == nil {
+		h.subscribers[channel] = map[*subscriber]bool{}
+	}
+	h.subscribers[channel][sub] = true
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[channel], sub)
+			if len(h.subscribers[channel]) == 0 {
+				delete(h.subscribers, channel)
+			}
+			h.mu.Unlock()
+			close(sub.done)
+		})
+	}
+
+	return sub.ch, unsubscribe
+}
+
+func (h *hub) dispatch(channel, payload string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[channel] {
+		sub.enqueue(payload)
+	}
+}
+
+// maxSubscriberQueue is how many messages can wait for a subscriber before it's disconnected
+const maxSubscriberQueue = 10000
+
+// subscriber queues messages so a slow reader never blocks dispatch. A reader that falls more than maxSubscriberQueue messages behind is disconnected--its channel is closed rather than silently dropping stream output, so it can reconnect and resume.
+type subscriber struct {
+	ch       chan string
+	mu       sync.Mutex
+	queue    []string
+	overflow bool
+	maxQueue int
+	signal   chan struct{}
+	done     chan struct{}
+}
+
+func newSubscriber() *subscriber {
+	sub := &subscriber{
+		ch:       make(chan string),
+		maxQueue: maxSubscriberQueue,
+		signal:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go sub.run()
+	return sub
+}
+
+func (sub *subscriber) enqueue(payload string) {
+	sub.mu.Lock()
+	if sub.overflow {
+		sub.mu.Unlock()
+		return
+	}
+	if len(sub.queue) >= sub.maxQueue {
+		log.Printf("Stream bus: subscriber fell %d messages behind--disconnecting it\n", len(sub.queue))
+		sub.overflow = true
+		sub.queue = nil
+	} else {
+		sub.queue = append(sub.queue, payload)
+	}
+	sub.mu.Unlock()
+
+	select {
+	case sub.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscriber) run() {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-sub.signal:
+		}
+
+		for {
+			sub.mu.Lock()
+			if sub.overflow {
+				sub.mu.Unlock()
+				close(sub.ch)
+				return
+			}
+			if len(sub.queue) == 0 {
+				sub.mu.Unlock()
+				break
+			}
+			payload := sub.queue[0]
+			sub.queue = sub.queue[1:]
+			sub.mu.Unlock()
+
+			select {
+			case <-sub.done:
+				return
+			case sub.ch <- payload:
+			}
+		}
+	}
+}
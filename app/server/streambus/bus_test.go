@@ -0,0 +1,151 @@
+package streambus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPgEnvelopesRoundTrip(t *testing.T) {
+	b := &postgresBackend{hub: newHub(), partials: map[string]*pgPartialMessage{}}
+
+	channel := StreamChannel("plan-id", "main")
+	ch, unsubscribe := b.Subscribe(channel)
+	defer unsubscribe()
+
+	payloads := []string{
+		"",
+		`{"type":"reply","replyChunk":"hello"}`,
+		strings.Repeat(`{"type":"reply","replyChunk":"line\n\"quoted\" ✅"}`, 1000),
+	}
+
+	for _, payload := range payloads {
+		envelopes, err := encodePgEnvelopes(channel, payload, 8000)
+		if err != nil {
+			t.Fatalf("encodePgEnvelopes: %v", err)
+		}
+
+		for _, envelope := range envelopes {
+			if len(envelope) >= 8000 {
+				t.Fatalf("envelope is %d bytes, over the NOTIFY limit", len(envelope))
+			}
+		}
+
+		if len(payload) > 8000 && len(envelopes) < 2 {
+			t.Fatalf("expected a %d byte payload to be chunked", len(payload))
+		}
+
+		// chunks can arrive interleaved with other messages--deliver them in reverse to check reassembly by index
+		for i := len(envelopes) - 1; i >= 0; i-- {
+			b.receive(envelopes[i])
+		}
+
+		select {
+		case got := <-ch:
+			if got != payload {
+				t.Fatalf("payload mismatch: got %d bytes, want %d", len(got), len(payload))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %d byte payload", len(payload))
+		}
+	}
+
+	if len(b.partials) != 0 {
+		t.Fatalf("expected no partial messages left, got %d", len(b.partials))
+	}
+}
+
+func TestControlRequestLocal(t *testing.T) {
+	Current = newLocalBackend()
+
+	RegisterControlHandler(func(req *ControlRequest) *ControlResponse {
+		if req.PlanId != "owned" {
+			return nil
+		}
+
+		var body map[string]string
+		json.Unmarshal(req.Body, &body)
+
+		return &ControlResponse{Status: http.StatusOK, Body: []byte(req.Method + ":" + body["choice"])}
+	})
+	startControlServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := Request(ctx, ControlRequest{PlanId: "owned", Branch: "main", Method: "respond_missing_file", Body: []byte(`{"choice":"load"}`)})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	if res.Status != http.StatusOK || string(res.Body) != "respond_missing_file:load" {
+		t.Fatalf("unexpected response: %d %s", res.Status, string(res.Body))
+	}
+
+	// nobody owns the plan, so the request times out
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = Request(ctx, ControlRequest{PlanId: "other", Branch: "main", Method: "stop"})
+	if err == nil {
+		t.Fatalf("expected an error for a plan with no owner")
+	}
+}
+
+func TestSlowSubscriberDisconnected(t *testing.T) {
+	h := newHub()
+
+	ch, unsubscribe := h.subscribe("slow")
+	defer unsubscribe()
+
+	h.mu.Lock()
+	for sub := range h.subscribers["slow"] {
+		sub.maxQueue = 5
+	}
+	h.mu.Unlock()
+
+	// the run loop takes the first message and blocks sending it, so 6 more fill the queue
+	for i := 0; i < 20; i++ {
+		h.dispatch("slow", "msg")
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the slow subscriber's channel to be closed")
+		}
+	}
+}
+
+func TestSubscriberKeepsOrder(t *testing.T) {
+	h := newHub()
+
+	ch, unsubscribe := h.subscribe("ordered")
+	defer unsubscribe()
+
+	for i := 0; i < 100; i++ {
+		h.dispatch("ordered", strings.Repeat("x", i))
+	}
+
+	for i := 0; i < 100; i++ {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				t.Fatal("channel closed under the queue limit")
+			}
+			if len(msg) != i {
+				t.Fatalf("message %d out of order: got length %d", i, len(msg))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package streambus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Control requests reach the instance that owns an active plan. Every instance receives each request; only the owner replies.
+
+const controlChannel = "control"
+
+func replyChannel(requestId string) string {
+	return "reply:" + requestId
+}
+
+// ControlRequest is published to every instance, so it never carries credentials. The forwarding instance authenticates the request and sends only the resolved ids.
+type ControlRequest struct {
+	Id         string `json:"id"`
+	PlanId     string `json:"planId"`
+	Branch     string `json:"branch"`
+	Method     string `json:"method"`
+	HttpMethod string `json:"httpMethod"`
+	UserId     string `json:"userId"`
+	OrgId      string `json:"orgId"`
+	ApiTokenId string `json:"apiTokenId,omitempty"`
+	Body       []byte `json:"body"`
+}
+
+type ControlResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// ControlHandler returns nil if this instance doesn't own the plan's active stream
+type ControlHandler func(req *ControlRequest) *ControlResponse
+
+var controlHandler ControlHandler
+
+// RegisterControlHandler sets the handler for control requests--call before Init
+func RegisterControlHandler(handler ControlHandler) {
+	controlHandler = handler
+}
+
+// Request sends a control request to the instance that owns the plan's active stream and waits for its reply
+func Request(ctx context.Context, req ControlRequest) (*ControlResponse, error) {
+	req.Id = uuid.New().String()
+
+	// subscribe before publishing so a fast reply can't be missed
+	ch, unsubscribe := Current.Subscribe(replyChannel(req.Id))
+	defer unsubscribe()
+
+	bytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling control request: %v", err)
+	}
+
+	err = Current.Publish(controlChannel, string(bytes))
+	if err != nil {
+		return nil, fmt.Errorf("error publishing control request: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no reply to %s control request: %v", req.Method, ctx.Err())
+	case msg, ok := <-ch:
+		if !ok {
+			return nil, fmt.Errorf("stream bus disconnected while waiting for %s control reply", req.Method)
+		}
+		var res ControlResponse
+		err := json.Unmarshal([]byte(msg), &res)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling control response: %v", err)
+		}
+		return &res, nil
+	}
+}
+
+func startControlServer() {
+	if controlHandler == nil {
+		return
+	}
+
+	ch, _ := Current.Subscribe(controlChannel)
+
+	go func() {
+		for {
+			for msg := range ch {
+				var req ControlRequest
+				err := json.Unmarshal([]byte(msg), &req)
+				if err != nil {
+					log.Printf("Stream bus: error unmarshalling control request: %v\n", err)
+					continue
+				}
+
+				go serveControlRequest(&req)
+			}
+
+			// only closed if the control server fell behind--keep serving
+			log.Println("Stream bus: control subscription disconnected, resubscribing")
+			ch, _ = Current.Subscribe(controlChannel)
+		}
+	}()
+}
+
+func serveControlRequest(req *ControlRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Stream bus: panic handling %s control request: %v\n", req.Method, r)
+		}
+	}()
+
+	res := controlHandler(req)
+	if res == nil {
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Stream bus: error marshalling control response: %v\n", err)
+		return
+	}
+
+	err = Current.Publish(replyChannel(req.Id), string(bytes))
+	if err != nil {
+		log.Printf("Stream bus: error publishing control response: %v\n", err)
+	}
+}
@@ -0,0 +1,23 @@
+package streambus
+
+// localBackend only delivers within this process. Cross-instance requests fall back to proxying by internal ip.
+type localBackend struct {
+	hub *hub
+}
+
+func newLocalBackend() *localBackend {
+	return &localBackend{hub: newHub()}
+}
+
+func (b *localBackend) Publish(channel, payload string) error {
+	b.hub.dispatch(channel, payload)
+	return nil
+}
+
+func (b *localBackend) Subscribe(channel string) (<-chan string, func()) {
+	return b.hub.subscribe(channel)
+}
+
+func (b *localBackend) Distributed() bool {
+	return false
+}
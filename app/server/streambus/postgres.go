@@ -0,0 +1,234 @@
+package streambus
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// every instance LISTENs on a single postgres channel and routes by the bus channel in each envelope--postgres channel names are capped at 63 bytes, which plan id + branch can exceed
+const pgNotifyChannel = "plandex_stream_bus"
+
+// incomplete chunked messages are dropped after this long
+const pgPartialMessageTimeout = time.Minute
+
+// NOTIFY payloads are capped at 8000 bytes, so larger messages are split into chunks and reassembled by each listener
+type pgEnvelope struct {
+	Channel string `json:"c"`
+	MsgId   string `json:"m"`
+	Index   int    `json:"i"`
+	Total   int    `json:"n"`
+	Data    string `json:"d"`
+}
+
+type pgPartialMessage struct {
+	chunks    []string
+	received  int
+	createdAt time.Time
+}
+
+type postgresBackend struct {
+	hub      *hub
+	listener *pq.Listener
+
+	partialMu sync.Mutex
+	partials  map[string]*pgPartialMessage
+}
+
+func newPostgresBackend() (*postgresBackend, error) {
+	listener, err := db.NewListener(func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			log.Printf("Stream bus: listener connection error: %v\n", err)
+		case pq.ListenerEventReconnected:
+			log.Println("Stream bus: listener reconnected--notifications sent while disconnected were missed")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = listener.Listen(pgNotifyChannel)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("error listening on %s: %v", pgNotifyChannel, err)
+	}
+
+	b := &postgresBackend{
+		hub:      newHub(),
+		listener: listener,
+		partials: map[string]*pgPartialMessage{},
+	}
+
+	go b.listen()
+
+	return b, nil
+}
+
+func (b *postgresBackend) Publish(channel, payload string) error {
+	envelopes, err := encodePgEnvelopes(channel, payload, db.NotifyPayloadLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, envelope := range envelopes {
+		err := db.Notify(pgNotifyChannel, envelope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *postgresBackend) Subscribe(channel string) (<-chan string, func()) {
+	return b.hub.subscribe(channel)
+}
+
+func (b *postgresBackend) Distributed() bool {
+	return true
+}
+
+func (b *postgresBackend) listen() {
+	for {
+		select {
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				log.Println("Stream bus: listener closed")
+				return
+			}
+			// nil notification after a reconnect
+			if n == nil {
+				continue
+			}
+			b.receive(n.Extra)
+
+		case <-time.After(90 * time.Second):
+			go func() {
+				err := b.listener.Ping()
+				if err != nil {
+					log.Printf("Stream bus: listener ping failed: %v\n", err)
+				}
+			}()
+		}
+	}
+}
+
+func (b *postgresBackend) receive(raw string) {
+	var envelope pgEnvelope
+	err := json.Unmarshal([]byte(raw), &envelope)
+	if err != nil {
+		log.Printf("Stream bus: error unmarshalling notification: %v\n", err)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(envelope.Data)
+	if err != nil {
+		log.Printf("Stream bus: error decoding notification: %v\n", err)
+		return
+	}
+
+	if envelope.Total <= 1 {
+		b.hub.dispatch(envelope.Channel, string(data))
+		return
+	}
+
+	payload, complete := b.addChunk(envelope, string(data))
+	if complete {
+		b.hub.dispatch(envelope.Channel, payload)
+	}
+}
+
+func (b *postgresBackend) addChunk(envelope pgEnvelope, data string) (string, bool) {
+	b.partialMu.Lock()
+	defer b.partialMu.Unlock()
+
+	now := time.Now()
+	for msgId, partial := range b.partials {
+		if now.Sub(partial.createdAt) > pgPartialMessageTimeout {
+			log.Printf("Stream bus: dropping incomplete message %s\n", msgId)
+			delete(b.partials, msgId)
+		}
+	}
+
+	if envelope.Index < 0 || envelope.Index >= envelope.Total {
+		log.Printf("Stream bus: invalid chunk index %d of %d for message %s\n", envelope.Index, envelope.Total, envelope.MsgId)
+		return "", false
+	}
+
+	partial, ok := b.partials[envelope.MsgId]
+	if !ok {
+		partial = &pgPartialMessage{
+			chunks:    make([]string, envelope.Total),
+			createdAt: now,
+		}
+		b.partials[envelope.MsgId] = partial
+	}
+
+	partial.chunks[envelope.Index] = data
+	partial.received++
+
+	if partial.received < envelope.Total {
+		return "", false
+	}
+
+	delete(b.partials, envelope.MsgId)
+
+	var payload strings.Builder
+	for _, chunk := range partial.chunks {
+		payload.WriteString(chunk)
+	}
+
+	return payload.String(), true
+}
+
+func encodePgEnvelopes(channel, payload string, limit int) ([]string, error) {
+	msgId := uuid.New().String()
+
+	// measure the envelope with a placeholder for the largest chunk count we could need
+	overhead, err := json.Marshal(pgEnvelope{Channel: channel, MsgId: msgId, Index: len(payload), Total: len(payload) + 1})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling notification: %v", err)
+	}
+
+	chunkSize := (limit - len(overhead)) / 4 * 3
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("stream bus channel name is too long: %s", channel)
+	}
+
+	total := (len(payload) + chunkSize - 1) / chunkSize
+	if total == 0 {
+		total = 1
+	}
+
+	envelopes := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		bytes, err := json.Marshal(pgEnvelope{
+			Channel: channel,
+			MsgId:   msgId,
+			Index:   i,
+			Total:   total,
+			Data:    base64.StdEncoding.EncodeToString([]byte(payload[i*chunkSize : end])),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling notification: %v", err)
+		}
+
+		envelopes = append(envelopes, string(bytes))
+	}
+
+	return envelopes, nil
+}
@@ -16,6 +16,8 @@ var verboseLogging = os.Getenv("VERBOSE_LOGGING") == "true"
 
 // FileMap represents a file's important definitions
 type FileMap struct {
+	Imports     []string // Import statements, one per imported module where the language groups them
+	Exports     []string // Exported names, for languages with explicit exports or a naming convention for them
 	Definitions []Definition
 }
 
@@ -25,8 +27,11 @@ type Definition struct {
 	Comments  []string     // Any comments that precede this definition
 	TagAttrs  []string     // For xml style markup tags, the class and id attributes
 	TagReps   int          // For tags, the number of times this tag is repeated
+	Name      string       // Declared name, including the receiver type for methods declared outside their type
 	Line      int          // Line number where definition starts
+	EndLine   int          // Line number where definition ends
 	Children  []Definition // For parent types that can contain nested definitions
+	Calls     []string     // Functions and methods called in the definition's body
 }
 
 type Node struct {
@@ -120,30 +125,35 @@ func MapFile(ctx context.Context, filename string, content []byte) (*FileMap, er
 		defer fallbackTree.Close()
 
 		if fallbackTree.RootNode().Type() != "error" {
-			return &FileMap{
-				Definitions: mapNode(fallbackTree.RootNode(), content, fallbackLang),
-			}, nil
+			return mapTree(fallbackTree.RootNode(), content, fallbackLang), nil
 		}
 	}
 
-	return &FileMap{
-		Definitions: mapNode(tree.RootNode(), content, lang),
-	}, nil
+	return mapTree(tree.RootNode(), content, lang), nil
 
 }
 
-func mapNode(node *tree_sitter.Node, content []byte, lang shared.Language) []Definition {
+func mapTree(node *tree_sitter.Node, content []byte, lang shared.Language) *FileMap {
 	switch lang {
 	case shared.LanguageHtml:
-		return mapMarkup(content)
+		return &FileMap{
+			Definitions: mapMarkup(content),
+		}
 	case shared.LanguageSvelte:
-		return mapSvelte(content)
+		return &FileMap{
+			Definitions: mapSvelte(content),
+		}
 	default:
-		return mapTraditional(Node{
+		root := Node{
 			Lang:   lang,
 			TsNode: node,
 			Bytes:  content,
-		}, nil)
+		}
+		return &FileMap{
+			Imports:     findImports(root),
+			Exports:     findExports(root),
+			Definitions: mapTraditional(root, nil),
+		}
 	}
 }
 
@@ -163,6 +173,17 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 				Bytes:  baseNode.Bytes,
 			}
 
+			if isUnwrapNode(node) {
+				if verboseLogging {
+					fmt.Println("unwrap node", node.Type)
+				}
+				defs = append(defs, mapTraditional(node, parentNode)...)
+				if !cursor.GoToNextSibling() {
+					break
+				}
+				continue
+			}
+
 			if isIncludeAndContinueNode(node) {
 				if verboseLogging {
 					fmt.Println("include and continue node", cursor.CurrentNode().Type())
@@ -187,8 +208,10 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 				}
 
 				def := Definition{
-					Type: node.Type,
-					Line: int(tsNode.StartPoint().Row) + 1,
+					Type:    node.Type,
+					Name:    definitionName(node),
+					Line:    int(tsNode.StartPoint().Row) + 1,
+					EndLine: endLine(tsNode),
 				}
 
 				if isAssignmentNode(node) {
@@ -276,6 +299,11 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 									fmt.Println("firstChild", firstChild.Type)
 								}
 								end = firstChild.TsNode.StartByte()
+
+								// leave out comments between the header and the first child
+								for prev := firstChild.TsNode.PrevSibling(); prev != nil && strings.Contains(prev.Type(), "comment"); prev = prev.PrevSibling() {
+									end = prev.StartByte()
+								}
 							} else {
 								if verboseLogging {
 									fmt.Println("firstChild == nil")
@@ -309,6 +337,11 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 					}
 				}
 
+				// parents list calls under their children instead
+				if len(def.Children) == 0 && !isParentNode(node) && !isPassThroughParentNode(node) {
+					def.Calls = findCalls(node)
+				}
+
 				// Get preceding comments
 				// no comments for now to minimize tokens
 				// def.Comments = getPrecedingComments(node)
@@ -424,6 +457,16 @@ func (m *FileMap) String() string {
 		}
 		b.WriteString("\n")
 
+		if len(def.Calls) > 0 {
+			b.WriteString(strings.Repeat("  ", depth+1))
+			if depth > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString("calls: ")
+			b.WriteString(strings.Join(def.Calls, ", "))
+			b.WriteString("\n")
+		}
+
 		// Write children with increased depth
 		for _, child := range def.Children {
 			writeDefinition(&child, depth+1)
@@ -434,6 +477,25 @@ func (m *FileMap) String() string {
 		}
 	}
 
+	if len(m.Imports) > 0 {
+		b.WriteString("imports:\n")
+		for _, imp := range m.Imports {
+			b.WriteString("  - ")
+			b.WriteString(imp)
+			b.WriteString("\n")
+		}
+	}
+
+	if len(m.Exports) > 0 {
+		b.WriteString("exports: ")
+		b.WriteString(strings.Join(m.Exports, ", "))
+		b.WriteString("\n")
+	}
+
+	if len(m.Imports) > 0 || len(m.Exports) > 0 {
+		b.WriteString("\n")
+	}
+
 	// Write all top-level definitions
 	for _, def := range m.Definitions {
 		writeDefinition(&def, 0)
@@ -0,0 +1,61 @@
+package file_map
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test ./syntax/file_map -run TestMapFileGolden -update
+var update = flag.Bool("update", false, "rewrite golden file maps in testdata")
+
+func TestMapFileGolden(t *testing.T) {
+	entries, err := os.ReadDir("examples")
+	if err != nil {
+		t.Fatalf("failed to read examples: %v", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !shared.HasFileMapSupport(name) {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("examples", name))
+			if err != nil {
+				t.Fatalf("failed to read example: %v", err)
+			}
+
+			fileMap, err := MapFile(context.Background(), name, content)
+			if err != nil {
+				t.Fatalf("failed to map file: %v", err)
+			}
+			got := fileMap.String()
+
+			goldenPath := filepath.Join("testdata", name+".map")
+			if *update {
+				if err := os.MkdirAll("testdata", 0755); err != nil {
+					t.Fatalf("failed to create testdata: %v", err)
+				}
+				if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
@@ -240,6 +240,7 @@ var assignmentNodeMap = nodeMap{
 		nodeMatch: matchTypeEqual,
 		languages: langSet{
 			shared.LanguageScala: true,
+			shared.LanguageOCaml: true,
 		},
 	},
 
@@ -249,6 +250,57 @@ var assignmentNodeMap = nodeMap{
 			shared.LanguageSwift: true,
 		},
 	},
+
+	"value_definition": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"instance_variable_definition": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"method_definition": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+
+	"attribute": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+
+	"field": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"map_field": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"oneof_field": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"enum_field": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
 }
 
 var definitionNodeMap = nodeMap{
@@ -388,6 +440,18 @@ var definitionNodeMap = nodeMap{
 			shared.LanguageDockerfile: true,
 		},
 	},
+	"value_specification": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"rpc": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
 
 	// Ignored patterns
 	"import_": {
@@ -581,6 +645,56 @@ var parentNodeMap = nodeMap{
 			"function_statement": true,
 		},
 	},
+
+	"protocol_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSwift: true,
+		},
+	},
+
+	// attributes are only listed for locals--for other blocks, they're mostly settings
+	"block": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+		onlyChildren: map[nodeType]bool{
+			"block":        true,
+			"locals_block": true,
+		},
+	},
+	"locals_block": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+
+	"message": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"enum": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"service": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"oneof": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
 }
 
 var implBoundaryNodeMap = nodeMap{
@@ -591,6 +705,7 @@ var implBoundaryNodeMap = nodeMap{
 		except: langSet{
 			shared.LanguageRuby:   true,
 			shared.LanguageElixir: true,
+			shared.LanguageHcl:    true,
 		},
 	},
 	"body": {
@@ -701,6 +816,39 @@ var implBoundaryNodeMap = nodeMap{
 			shared.LanguageScala: true,
 		},
 	},
+
+	"structure": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"signature": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"object_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+
+	// services and oneofs have no body node, so their children follow the header directly
+	"service": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+	"oneof": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
 }
 
 var assignmentBoundaryNodeMap = nodeMap{
@@ -765,6 +913,12 @@ var assignmentBoundaryNodeMap = nodeMap{
 			shared.LanguageSwift: true,
 		},
 	},
+	"computed_property": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSwift: true,
+		},
+	},
 }
 
 var identifierNodeMap = nodeMap{
@@ -817,3 +971,180 @@ var includeAndContinueNodeMap = nodeMap{
 		},
 	},
 }
+
+// the children of these nodes are mapped as if they were the node's siblings
+var unwrapNodeMap = nodeMap{
+	"export_statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJavascript: true,
+			shared.LanguageTypescript: true,
+			shared.LanguageJsx:        true,
+			shared.LanguageTsx:        true,
+		},
+	},
+	"body": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+}
+
+// onlyChildren lists the nodes inside a grouped import that are each listed as a separate import
+var importNodeMap = nodeMap{
+	"import_": {
+		nodeMatch: matchTypePrefix,
+		all:       true,
+	},
+	"import_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageGo: true,
+		},
+		onlyChildren: map[nodeType]bool{
+			"import_spec": true,
+		},
+	},
+	"import_list": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageKotlin: true,
+		},
+		onlyChildren: map[nodeType]bool{
+			"import_header": true,
+		},
+	},
+	"use_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageRust: true,
+		},
+	},
+	"using_directive": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageCsharp: true,
+		},
+	},
+	"preproc_include": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageC:   true,
+			shared.LanguageCpp: true,
+		},
+	},
+	"namespace_use_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+	"open_module": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"import": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageProtobuf: true,
+		},
+	},
+}
+
+var callNodeMap = nodeMap{
+	"call_expression": {
+		nodeMatch: matchTypeEqual,
+		all:       true,
+		except: langSet{
+			shared.LanguageCss: true,
+		},
+	},
+	"new_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJavascript: true,
+			shared.LanguageTypescript: true,
+			shared.LanguageJsx:        true,
+			shared.LanguageTsx:        true,
+		},
+	},
+	"call": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePython: true,
+			shared.LanguageRuby:   true,
+			shared.LanguageElixir: true,
+		},
+	},
+	"method_invocation": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJava: true,
+		},
+	},
+	"object_creation_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJava:   true,
+			shared.LanguageCsharp: true,
+			shared.LanguagePhp:    true,
+		},
+	},
+	"invocation_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageCsharp: true,
+		},
+	},
+	"_call_expression": {
+		nodeMatch: matchTypeSuffix,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+	"function_call": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageLua: true,
+			shared.LanguageHcl: true,
+		},
+	},
+	"function_call_expr": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageElm: true,
+		},
+	},
+	"application_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+	"method_invocation_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageOCaml: true,
+		},
+	},
+}
+
+// builtins and keywords that parse as calls but don't point anywhere useful
+var ignoredCallNames = map[shared.Language]map[string]bool{
+	shared.LanguageGo: {
+		"append": true, "cap": true, "clear": true, "close": true, "copy": true, "delete": true, "len": true, "make": true, "max": true, "min": true, "new": true, "panic": true, "print": true, "println": true, "recover": true,
+		"bool": true, "byte": true, "error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	},
+	shared.LanguagePython: {
+		"bool": true, "dict": true, "enumerate": true, "float": true, "getattr": true, "hasattr": true, "int": true, "isinstance": true, "len": true, "list": true, "print": true, "range": true, "repr": true, "set": true, "setattr": true, "sorted": true, "str": true, "super": true, "tuple": true, "type": true, "zip": true,
+	},
+	shared.LanguageSwift: {
+		"defer": true,
+	},
+	shared.LanguageElixir: {
+		"case": true, "cond": true, "fn": true, "for": true, "if": true, "quote": true, "receive": true, "try": true, "unless": true, "unquote": true, "with": true,
+	},
+}
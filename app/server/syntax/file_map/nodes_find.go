@@ -2,12 +2,20 @@ package file_map
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
+	"unicode"
 
 	shared "plandex-shared"
 )
 
 func isDefinitionNode(node Node, parentNode *Node) bool {
+	// keywords can share a name with the node they start, like 'service' in protobuf
+	if !node.TsNode.IsNamed() {
+		return false
+	}
+
 	setNodeType(&node)
 
 	res := false
@@ -16,7 +24,9 @@ func isDefinitionNode(node Node, parentNode *Node) bool {
 
 	var parentConfig *nodeConfig
 	if parentNode != nil {
-		parentConfig = parentNodeMap.getConfig(parentNode.Type, parentNode.Lang)
+		parent := *parentNode
+		setNodeType(&parent)
+		parentConfig = parentNodeMap.getConfig(parent.Type, parent.Lang)
 	}
 
 	if config == nil {
@@ -99,7 +109,53 @@ func isIncludeAndContinueNode(node Node) bool {
 	return !config.ignore
 }
 
+func isUnwrapNode(node Node) bool {
+	setNodeType(&node)
+	config := unwrapNodeMap.getConfig(node.Type, node.Lang)
+	if config == nil {
+		return false
+	}
+	return !config.ignore
+}
+
+func isImportNode(node Node) bool {
+	setNodeType(&node)
+	config := importNodeMap.getConfig(node.Type, node.Lang)
+	if config == nil {
+		return false
+	}
+	return !config.ignore
+}
+
+func isCallNode(node Node) bool {
+	setNodeType(&node)
+	config := callNodeMap.getConfig(node.Type, node.Lang)
+	if config == nil {
+		return false
+	}
+	return !config.ignore
+}
+
+type configCacheKey struct {
+	m    uintptr
+	t    string
+	lang shared.Language
+}
+
+// getConfig runs for nearly every node in a file, so results are cached--the prefix and suffix matches otherwise loop over the whole map on each miss
+var configCache sync.Map
+
 func (m nodeMap) getConfig(t string, lang shared.Language) *nodeConfig {
+	key := configCacheKey{reflect.ValueOf(m).Pointer(), t, lang}
+	if cached, ok := configCache.Load(key); ok {
+		return cached.(*nodeConfig)
+	}
+	config := m.findConfig(t, lang)
+	configCache.Store(key, config)
+	return config
+}
+
+func (m nodeMap) findConfig(t string, lang shared.Language) *nodeConfig {
 	// first look for exact match
 	config, ok := m[nodeType(t)]
 	if ok {
@@ -258,40 +314,63 @@ func findAssignmentBoundary(node Node) *Node {
 }
 
 func setNodeType(node *Node) {
-	if node.Lang == shared.LanguageElixir && node.Type == "call" {
-		content := node.TsNode.Content(node.Bytes)
-
-		switch {
-		case strings.HasPrefix(string(content), "defmodule"):
+	switch {
+	case node.Lang == shared.LanguageElixir && node.Type == "call":
+		switch leadingWord(node.TsNode.Content(node.Bytes)) {
+		case "defmodule":
 			node.Type = "module_definition"
-		case strings.HasPrefix(string(content), "defprotocol"):
+		case "defprotocol":
 			node.Type = "protocol_definition"
-		case strings.HasPrefix(string(content), "defimpl"):
+		case "defimpl":
 			node.Type = "protocol_implementation"
-		case strings.HasPrefix(string(content), "defstruct"):
+		case "defstruct":
 			node.Type = "struct_definition"
-		case strings.HasPrefix(string(content), "defexception"):
+		case "defexception":
 			node.Type = "exception_definition"
-		case strings.HasPrefix(string(content), "defdelegate"):
+		case "defdelegate":
 			node.Type = "delegate_definition"
-		case strings.HasPrefix(string(content), "defoverridable"):
+		case "defoverridable":
 			node.Type = "overridable_definition"
-		case strings.HasPrefix(string(content), "defcallback"):
+		case "defcallback":
 			node.Type = "callback_definition"
-		case strings.HasPrefix(string(content), "defmacrocallback"):
+		case "defmacrocallback":
 			node.Type = "macro_callback_definition"
-		case strings.HasPrefix(string(content), "defmacrop"):
+		case "defmacrop":
 			node.Type = "private_macro_definition"
-		case strings.HasPrefix(string(content), "defmacro"):
+		case "defmacro":
 			node.Type = "macro_definition"
-		case strings.HasPrefix(string(content), "defguardp"):
+		case "defguardp":
 			node.Type = "private_guard_definition"
-		case strings.HasPrefix(string(content), "defguard"):
+		case "defguard":
 			node.Type = "guard_definition"
-		case strings.HasPrefix(string(content), "defp"):
+		case "defp":
 			node.Type = "private_function_definition"
-		case strings.HasPrefix(string(content), "def"):
+		case "def":
 			node.Type = "function_definition"
+		case "alias", "import", "require", "use":
+			node.Type = "import_directive"
 		}
+
+	case node.Lang == shared.LanguageRuby && node.Type == "call":
+		switch leadingWord(node.TsNode.Content(node.Bytes)) {
+		case "require", "require_relative", "load":
+			node.Type = "import_require"
+		}
+
+	case node.Lang == shared.LanguageHcl && node.Type == "block":
+		if leadingWord(node.TsNode.Content(node.Bytes)) == "locals" {
+			node.Type = "locals_block"
+		}
+	}
+}
+
+// leadingWord returns the identifier a node's content starts with, like 'defmodule' in 'defmodule Foo do'
+func leadingWord(content string) string {
+	end := strings.IndexFunc(content, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if end == -1 {
+		return content
 	}
+	return content[:end]
 }
@@ -0,0 +1,410 @@
+package file_map
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// limits keep maps of large files from growing much past their definitions
+const (
+	maxImports         = 40
+	maxExports         = 40
+	maxDefinitionCalls = 12
+	maxImportLength    = 200
+	maxCalleeLength    = 40
+
+	// imports nested inside modules or namespaces sit a few levels down--deeper ones are local to a function
+	maxImportDepth = 4
+)
+
+var genericArgsPattern = regexp.MustCompile(`(::)?<[^<>]*>`)
+var calleeIdentifierPattern = regexp.MustCompile(`[\p{L}_$][\p{L}\p{N}_$!?]*`)
+
+// findImports returns a file's import statements with whitespace collapsed, or each import separately for languages that group them
+func findImports(node Node) []string {
+	var res []string
+
+	var walk func(node Node, depth int)
+	walk = func(node Node, depth int) {
+		if isImportNode(node) {
+			res = append(res, importItems(node)...)
+			return
+		}
+		if depth >= maxImportDepth {
+			return
+		}
+		for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+			walk(childNode(node, node.TsNode.NamedChild(i)), depth+1)
+		}
+	}
+	walk(node, 0)
+
+	return capList(dedupe(res), maxImports)
+}
+
+func importItems(node Node) []string {
+	setNodeType(&node)
+	config := importNodeMap.getConfig(node.Type, node.Lang)
+	if config == nil || config.onlyChildren == nil {
+		return []string{importText(node)}
+	}
+
+	var res []string
+	var walk func(node Node)
+	walk = func(node Node) {
+		if config.onlyChildren[nodeType(node.Type)] {
+			res = append(res, importText(node))
+			return
+		}
+		for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+			walk(childNode(node, node.TsNode.NamedChild(i)))
+		}
+	}
+	walk(node)
+
+	return res
+}
+
+func importText(node Node) string {
+	// some grammars include a trailing comment in the import
+	end := node.TsNode.EndByte()
+	for i := 0; i < int(node.TsNode.ChildCount()); i++ {
+		child := node.TsNode.Child(i)
+		if strings.Contains(child.Type(), "comment") {
+			end = child.StartByte()
+			break
+		}
+	}
+
+	text := strings.Join(strings.Fields(string(node.Bytes[node.TsNode.StartByte():end])), " ")
+	text = strings.TrimSuffix(text, ";")
+	if len(text) > maxImportLength {
+		text = text[:maxImportLength] + "..."
+	}
+	return text
+}
+
+// findExports returns the names a file exports, for languages with explicit exports or a convention for them
+func findExports(root Node) []string {
+	var res []string
+
+	for i := 0; i < int(root.TsNode.NamedChildCount()); i++ {
+		child := childNode(root, root.TsNode.NamedChild(i))
+
+		switch root.Lang {
+		case shared.LanguageJavascript, shared.LanguageTypescript, shared.LanguageJsx, shared.LanguageTsx:
+			if child.Type == "export_statement" {
+				res = append(res, jsExports(child)...)
+			}
+
+		case shared.LanguageGo:
+			// methods aren't referenced through the package
+			if child.Type == "method_declaration" {
+				continue
+			}
+			for _, name := range declaredNames(child) {
+				if r := []rune(name); len(r) > 0 && unicode.IsUpper(r[0]) {
+					res = append(res, name)
+				}
+			}
+
+		case shared.LanguageRust:
+			if !hasChildOfType(child, "visibility_modifier") {
+				continue
+			}
+			if child.Type == "use_declaration" {
+				if arg := child.TsNode.ChildByFieldName("argument"); arg != nil {
+					res = append(res, importText(childNode(child, arg)))
+				}
+				continue
+			}
+			res = append(res, declaredNames(child)...)
+
+		case shared.LanguagePython:
+			res = append(res, pythonAllExports(child)...)
+		}
+	}
+
+	return capList(dedupe(res), maxExports)
+}
+
+func jsExports(node Node) []string {
+	isDefault := hasChildOfType(node, "default")
+
+	if decl := node.TsNode.ChildByFieldName("declaration"); decl != nil {
+		names := declaredNames(childNode(node, decl))
+		if isDefault {
+			if len(names) == 0 {
+				return []string{"default"}
+			}
+			return []string{"default " + names[0]}
+		}
+		return names
+	}
+
+	if isDefault {
+		if value := node.TsNode.ChildByFieldName("value"); value != nil && value.Type() == "identifier" {
+			return []string{"default " + value.Content(node.Bytes)}
+		}
+		return []string{"default"}
+	}
+
+	var res []string
+	for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+		child := node.TsNode.NamedChild(i)
+		if child.Type() != "export_clause" {
+			continue
+		}
+		for j := 0; j < int(child.NamedChildCount()); j++ {
+			spec := child.NamedChild(j)
+			name := spec.ChildByFieldName("alias")
+			if name == nil {
+				name = spec.ChildByFieldName("name")
+			}
+			if name != nil {
+				res = append(res, name.Content(node.Bytes))
+			}
+		}
+	}
+
+	// export * from './mod'
+	if len(res) == 0 {
+		if source := node.TsNode.ChildByFieldName("source"); source != nil {
+			res = append(res, "* from "+source.Content(node.Bytes))
+		}
+	}
+
+	return res
+}
+
+func pythonAllExports(node Node) []string {
+	if node.Type != "expression_statement" || node.TsNode.NamedChildCount() == 0 {
+		return nil
+	}
+	assignment := node.TsNode.NamedChild(0)
+	if assignment.Type() != "assignment" {
+		return nil
+	}
+	left := assignment.ChildByFieldName("left")
+	right := assignment.ChildByFieldName("right")
+	if left == nil || right == nil || left.Content(node.Bytes) != "__all__" {
+		return nil
+	}
+
+	var res []string
+	for i := 0; i < int(right.NamedChildCount()); i++ {
+		item := right.NamedChild(i)
+		if item.Type() == "string" {
+			res = append(res, strings.Trim(item.Content(node.Bytes), `"'`))
+		}
+	}
+	return res
+}
+
+// declaredNames returns the names a top-level declaration introduces, using the 'name' fields of the declaration or its specs
+func declaredNames(node Node) []string {
+	if name := node.TsNode.ChildByFieldName("name"); name != nil {
+		return []string{name.Content(node.Bytes)}
+	}
+
+	var res []string
+	for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+		child := node.TsNode.NamedChild(i)
+		switch {
+		// go type aliases like 'type UserID = int64' aren't wrapped in a spec
+		case strings.HasSuffix(child.Type(), "_spec"), strings.HasSuffix(child.Type(), "_declarator"), strings.HasSuffix(child.Type(), "_spec_list"), child.Type() == "type_alias":
+			res = append(res, declaredNames(childNode(node, child))...)
+		}
+	}
+	return res
+}
+
+// findCalls returns the functions and methods called anywhere inside a definition, in the order they first appear
+func findCalls(node Node) []string {
+	// module attributes
+	if node.Lang == shared.LanguageElixir && node.Type == "unary_operator" {
+		return nil
+	}
+
+	var res []string
+	seen := map[string]bool{}
+
+	var walk func(node Node)
+	walk = func(node Node) {
+		if len(res) >= maxDefinitionCalls {
+			return
+		}
+		if isCallNode(node) && !isElixirFunctionHead(node) {
+			name := calleeName(node)
+			if name != "" && !seen[name] && !ignoredCallNames[node.Lang][name] {
+				seen[name] = true
+				res = append(res, name)
+			}
+		}
+		for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+			walk(childNode(node, node.TsNode.NamedChild(i)))
+		}
+	}
+
+	for i := 0; i < int(node.TsNode.NamedChildCount()); i++ {
+		walk(childNode(node, node.TsNode.NamedChild(i)))
+	}
+
+	return res
+}
+
+// node types for functions defined inline, across grammars
+var inlineFunctionTypes = map[string]bool{
+	"func_literal":                           true,
+	"function":                               true,
+	"function_expression":                    true,
+	"arrow_function":                         true,
+	"lambda":                                 true,
+	"lambda_expression":                      true,
+	"lambda_literal":                         true,
+	"closure_expression":                     true,
+	"anonymous_function":                     true,
+	"anonymous_function_creation_expression": true,
+	"anonymous_method_expression":            true,
+	"function_definition":                    true,
+	"fun_expression":                         true,
+}
+
+// calleeName returns what a call node calls, like 'fmt.Println' or 'user.save'. Callees that are themselves expressions fall back to their last identifier.
+func calleeName(node Node) string {
+	ts := node.TsNode
+
+	var callee *tree_sitter.Node
+	for _, field := range []string{"function", "constructor", "method", "name", "target", "type"} {
+		if c := ts.ChildByFieldName(field); c != nil {
+			callee = c
+			break
+		}
+	}
+	if callee == nil {
+		if ts.NamedChildCount() == 0 {
+			return ""
+		}
+		callee = ts.NamedChild(0)
+	}
+
+	for callee.Type() == "parenthesized_expression" && callee.NamedChildCount() == 1 {
+		callee = callee.NamedChild(0)
+	}
+
+	// an immediately invoked closure, like 'go func() { ... }()'--the calls inside it are found by walking into it
+	if inlineFunctionTypes[callee.Type()] {
+		return ""
+	}
+
+	name := callee.Content(node.Bytes)
+
+	// languages that keep the receiver in a separate field
+	for _, field := range []string{"receiver", "object", "scope"} {
+		if receiver := ts.ChildByFieldName(field); receiver != nil && !receiver.Equal(callee) {
+			sep := "."
+			if node.Lang == shared.LanguagePhp {
+				sep = "->"
+				if field == "scope" {
+					sep = "::"
+				}
+			}
+			name = receiver.Content(node.Bytes) + sep + name
+			break
+		}
+	}
+
+	name = strings.Join(strings.Fields(name), "")
+	for prev := ""; prev != name; {
+		prev = name
+		name = genericArgsPattern.ReplaceAllString(name, "")
+	}
+
+	if len(name) > maxCalleeLength || strings.ContainsAny(name, "()[]{}\"'`") {
+		matches := calleeIdentifierPattern.FindAllString(name, -1)
+		if len(matches) == 0 {
+			return ""
+		}
+		name = matches[len(matches)-1]
+	}
+
+	// literals like numbers that some grammars parse as callees
+	if !calleeIdentifierPattern.MatchString(name) {
+		return ""
+	}
+
+	return name
+}
+
+// isElixirFunctionHead returns true for the head of a def, like 'greet(name)' in 'def greet(name) do', which parses as a call
+func isElixirFunctionHead(node Node) bool {
+	if node.Lang != shared.LanguageElixir {
+		return false
+	}
+
+	head := node.TsNode
+	parent := head.Parent()
+	if parent != nil && parent.Type() == "binary_operator" {
+		// def greet(name) when is_binary(name) do
+		if left := parent.ChildByFieldName("left"); left == nil || !left.Equal(head) {
+			return false
+		}
+		head = parent
+		parent = parent.Parent()
+	}
+	if parent == nil || parent.Type() != "arguments" || parent.NamedChildCount() == 0 || !parent.NamedChild(0).Equal(head) {
+		return false
+	}
+
+	def := parent.Parent()
+	if def == nil {
+		return false
+	}
+	defNode := childNode(node, def)
+	setNodeType(&defNode)
+	return strings.HasSuffix(defNode.Type, "_definition")
+}
+
+func childNode(parent Node, child *tree_sitter.Node) Node {
+	return Node{
+		Type:   child.Type(),
+		Lang:   parent.Lang,
+		TsNode: child,
+		Bytes:  parent.Bytes,
+	}
+}
+
+func hasChildOfType(node Node, t string) bool {
+	for i := 0; i < int(node.TsNode.ChildCount()); i++ {
+		if node.TsNode.Child(i).Type() == t {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(items []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, item := range items {
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		res = append(res, item)
+	}
+	return res
+}
+
+func capList(items []string, max int) []string {
+	if len(items) <= max {
+		return items
+	}
+	return append(items[:max], fmt.Sprintf("(%d more)", len(items)-max))
+}
@@ -0,0 +1,183 @@
+package file_map
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindSymbol returns the source of each definition in a file matching symbol, joined by blank lines. The symbol can be a plain name like 'handleRequest' or qualified by its enclosing definitions like 'Server.handleRequest'. Nested matches are skipped once their parent matches.
+func FindSymbol(ctx context.Context, filename string, content []byte, symbol string) (string, bool, error) {
+	if !shared.HasFileMapSupport(filename) {
+		return "", false, nil
+	}
+
+	fileMap, err := MapFile(ctx, filename, content)
+	if err != nil {
+		return "", false, err
+	}
+
+	// rust and c++ paths use '::'
+	symbol = strings.ReplaceAll(strings.TrimSpace(symbol), "::", ".")
+	if symbol == "" {
+		return "", false, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var bodies []string
+
+	var walk func(defs []Definition, parentName string)
+	walk = func(defs []Definition, parentName string) {
+		for _, def := range defs {
+			// unnamed parents like impl blocks without a type still scope their children
+			qualified := parentName
+			if def.Name != "" {
+				if qualified != "" {
+					qualified += "."
+				}
+				qualified += def.Name
+			}
+
+			if def.Name != "" && (qualified == symbol || strings.HasSuffix(qualified, "."+symbol)) {
+				if def.Line > 0 && def.EndLine >= def.Line && def.EndLine <= len(lines) {
+					bodies = append(bodies, strings.Join(lines[def.Line-1:def.EndLine], "\n"))
+				}
+				continue
+			}
+
+			walk(def.Children, qualified)
+		}
+	}
+	walk(fileMap.Definitions, "")
+
+	if len(bodies) == 0 {
+		return "", false, nil
+	}
+
+	return strings.Join(bodies, "\n\n"), true, nil
+}
+
+// qualified names like 'User:greet' in lua or 'Foo::bar' in rust are matched with dots
+var qualifierPattern = regexp.MustCompile(`::|:|->`)
+var qualifiedNamePattern = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$!?]*(\.[\p{L}_$][\p{L}\p{N}_$!?]*)*$`)
+
+// definitionName returns the name a definition declares, falling back to its first identifier
+func definitionName(node Node) string {
+	ts := node.TsNode
+
+	// decorators and templates wrap the definition they apply to
+	if inner := ts.ChildByFieldName("definition"); inner != nil {
+		ts = inner
+	} else if node.Type == "template_declaration" && ts.NamedChildCount() > 0 {
+		ts = ts.NamedChild(int(ts.NamedChildCount()) - 1)
+	}
+	node = childNode(node, ts)
+
+	var name string
+	switch {
+	case node.Lang == shared.LanguageElixir:
+		// def greet(name) do -- the name is the target of the call in the arguments
+		if args := childOfType(ts, "arguments"); args != nil && args.NamedChildCount() > 0 {
+			head := args.NamedChild(0)
+			if head.Type() == "binary_operator" {
+				head = head.ChildByFieldName("left")
+			}
+			if head != nil {
+				if target := head.ChildByFieldName("target"); target != nil {
+					head = target
+				}
+				name = head.Content(node.Bytes)
+			}
+		}
+
+	case ts.ChildByFieldName("name") != nil:
+		name = ts.ChildByFieldName("name").Content(node.Bytes)
+
+	case ts.ChildByFieldName("declarator") != nil:
+		// c and c++ nest the name in declarators, like 'int *(*make)(void)'
+		declarator := ts.ChildByFieldName("declarator")
+		for next := declarator.ChildByFieldName("declarator"); next != nil; next = next.ChildByFieldName("declarator") {
+			declarator = next
+		}
+		name = declarator.Content(node.Bytes)
+
+	case ts.ChildByFieldName("type") != nil && strings.HasPrefix(node.Type, "impl"):
+		// rust impl blocks
+		name = ts.ChildByFieldName("type").Content(node.Bytes)
+
+	case node.Lang == shared.LanguageOCaml:
+		// let and type definitions name their bindings
+		for i := 0; i < int(ts.NamedChildCount()); i++ {
+			binding := ts.NamedChild(i)
+			if !strings.HasSuffix(binding.Type(), "_binding") {
+				continue
+			}
+			if n := binding.ChildByFieldName("name"); n != nil {
+				name = n.Content(node.Bytes)
+			} else if n := binding.ChildByFieldName("pattern"); n != nil {
+				name = n.Content(node.Bytes)
+			}
+			break
+		}
+
+	default:
+		if names := declaredNames(node); len(names) > 0 {
+			name = names[0]
+		} else if identifiers := findIdentifier(node); len(identifiers) > 0 {
+			name = identifiers[0].TsNode.Content(node.Bytes)
+		}
+	}
+
+	name = strings.Join(strings.Fields(name), "")
+	name = genericArgsPattern.ReplaceAllString(name, "")
+	name = qualifierPattern.ReplaceAllString(name, ".")
+	if !qualifiedNamePattern.MatchString(name) {
+		// names that are expressions, like computed keys or destructuring patterns
+		name = calleeIdentifierPattern.FindString(name)
+	}
+
+	// go methods are declared outside their type
+	if node.Lang == shared.LanguageGo && node.Type == "method_declaration" {
+		if receiver := ts.ChildByFieldName("receiver"); receiver != nil {
+			if typeName := receiverTypeName(receiver, node.Bytes); typeName != "" {
+				name = typeName + "." + name
+			}
+		}
+	}
+
+	return name
+}
+
+func receiverTypeName(node *tree_sitter.Node, content []byte) string {
+	if node.Type() == "type_identifier" {
+		return node.Content(content)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if name := receiverTypeName(node.NamedChild(i), content); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func childOfType(node *tree_sitter.Node, t string) *tree_sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == t {
+			return child
+		}
+	}
+	return nil
+}
+
+// endLine returns the last line a node covers, leaving out the line after a trailing newline
+func endLine(node *tree_sitter.Node) int {
+	end := node.EndPoint()
+	if end.Column == 0 && end.Row > node.StartPoint().Row {
+		return int(end.Row)
+	}
+	return int(end.Row) + 1
+}
@@ -0,0 +1,143 @@
+package file_map
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSymbol(t *testing.T) {
+	goSource := `package main
+
+type Server struct {
+	addr string
+}
+
+func NewServer(addr string) *Server {
+	return &Server{addr: addr}
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+type Client struct{}
+
+func (c Client) Start() error {
+	return nil
+}
+`
+
+	pySource := `class Processor:
+    def __init__(self):
+        self.items = []
+
+    @property
+    def count(self):
+        return len(self.items)
+
+
+def main():
+    Processor()
+`
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		symbol   string
+		want     string
+		notFound bool
+	}{
+		{
+			name:     "go function",
+			filename: "main.go",
+			content:  goSource,
+			symbol:   "NewServer",
+			want: `func NewServer(addr string) *Server {
+	return &Server{addr: addr}
+}`,
+		},
+		{
+			name:     "go type",
+			filename: "main.go",
+			content:  goSource,
+			symbol:   "Server",
+			want: `type Server struct {
+	addr string
+}`,
+		},
+		{
+			name:     "go method qualified by receiver",
+			filename: "main.go",
+			content:  goSource,
+			symbol:   "Client.Start",
+			want: `func (c Client) Start() error {
+	return nil
+}`,
+		},
+		{
+			name:     "go method name matches every receiver",
+			filename: "main.go",
+			content:  goSource,
+			symbol:   "Start",
+			want: `func (s *Server) Start() error {
+	return nil
+}
+
+func (c Client) Start() error {
+	return nil
+}`,
+		},
+		{
+			name:     "python class",
+			filename: "processor.py",
+			content:  pySource,
+			symbol:   "Processor",
+			want: `class Processor:
+    def __init__(self):
+        self.items = []
+
+    @property
+    def count(self):
+        return len(self.items)`,
+		},
+		{
+			name:     "python decorated method",
+			filename: "processor.py",
+			content:  pySource,
+			symbol:   "Processor.count",
+			want: `    @property
+    def count(self):
+        return len(self.items)`,
+		},
+		{
+			name:     "missing symbol",
+			filename: "main.go",
+			content:  goSource,
+			symbol:   "Stop",
+			notFound: true,
+		},
+		{
+			name:     "unsupported file",
+			filename: "config.yaml",
+			content:  "server: {}\n",
+			symbol:   "server",
+			notFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := FindSymbol(context.Background(), tt.filename, []byte(tt.content), tt.symbol)
+			assert.NoError(t, err)
+			if tt.notFound {
+				assert.False(t, found)
+				return
+			}
+			assert.True(t, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -33,6 +33,9 @@ type ApplyChangesResult struct {
 	NewFile            string
 	Proposed           string
 	NeedsVerifyReasons []NeedsVerifyReason
+	// the reference and removal comment lines found in Proposed, after any missing start and end references were added
+	References []Reference
+	Removals   []Removal
 	BlocksRemoved      []struct {
 		Start   int
 		End     int
@@ -314,6 +317,8 @@ func ApplyChanges(
 		)
 
 		res.Proposed = proposed
+		res.References = references
+		res.Removals = removals
 
 		if len(res.NeedsVerifyReasons) > 0 {
 			if verboseLogging {
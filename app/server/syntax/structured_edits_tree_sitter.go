@@ -26,6 +26,32 @@ type execApplyTreeSitterParams struct {
 	ctx         context.Context
 }
 
+type ApplyTreeSitterParams struct {
+	Original   string
+	Proposed   string
+	References []Reference
+	Removals   []Removal
+	Parser     *tree_sitter.Parser
+	Language   shared.Language
+}
+
+// ApplyTreeSitter runs the tree-sitter apply strategy on its own. ApplyChanges only falls back to it when a reference's location is ambiguous, so this lets the evals harness score it directly, with the Proposed, References and Removals from an ApplyChanges result.
+func ApplyTreeSitter(ctx context.Context, params ApplyTreeSitterParams) (*ApplyChangesResult, error) {
+	if params.Parser == nil {
+		return nil, fmt.Errorf("no tree-sitter parser")
+	}
+
+	return ExecApplyTreeSitter(execApplyTreeSitterParams{
+		original:   params.Original,
+		proposed:   params.Proposed,
+		references: params.References,
+		removals:   params.Removals,
+		language:   params.Language,
+		parser:     params.Parser,
+		ctx:        ctx,
+	})
+}
+
 func ExecApplyTreeSitter(
 	params execApplyTreeSitterParams,
 ) (*ApplyChangesResult, error) {
@@ -37,7 +63,10 @@ func ExecApplyTreeSitter(
 	language := params.language
 	parser := params.parser
 	ctx := params.ctx
-	res := &ApplyChangesResult{}
+	res := &ApplyChangesResult{
+		References: references,
+		Removals:   removals,
+	}
 
 	var b strings.Builder
 
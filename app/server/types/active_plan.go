@@ -9,6 +9,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/notify"
 	"plandex-server/shutdown"
+	"plandex-server/streambus"
 	"sync"
 	"time"
 
@@ -21,6 +22,9 @@ import (
 const MaxStreamRate = 70 * time.Millisecond
 const ActivePlanTimeout = 2 * time.Hour
 
+// number of sent stream messages kept so a client that drops its connection can resume without missing output
+const StreamReplayBufferSize = 1000
+
 type ActiveBuild struct {
 	ReplyId           string
 	FileDescription   string
@@ -83,6 +87,16 @@ type ActivePlan struct {
 	DidEditFiles          bool
 	SessionId             string
 
+	// extensions the connected CLI can type check--see RequestDiagnostics
+	DiagnosticsExts []string
+
+	budgetWarningMu   sync.Mutex
+	budgetWarningSent bool
+
+	diagnosticsMu       sync.Mutex
+	diagnosticsChs      map[string]chan shared.DiagnosticsResponse
+	diagnosticsTimedOut bool
+
 	subscriptions  map[string]*subscription
 	subscriptionMu sync.Mutex
 
@@ -90,6 +104,13 @@ type ActivePlan struct {
 	streamMu              sync.Mutex
 	lastStreamMessageSent time.Time
 	streamMessageBuffer   []shared.StreamMessage
+	streamSeq             int64
+	replayBuffer          []replayEntry
+}
+
+type replayEntry struct {
+	seq int64
+	msg string
 }
 
 func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, autoContext bool, sessionId string) *ActivePlan {
@@ -126,6 +147,7 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 		AllowOverwritePaths:   map[string]bool{},
 		SkippedPaths:          map[string]bool{},
 		SessionId:             sessionId,
+		diagnosticsChs:        map[string]chan shared.DiagnosticsResponse{},
 		streamCh:              make(chan string),
 		subscriptions:         map[string]*subscription{},
 		subscriptionMu:        sync.Mutex{},
@@ -151,6 +173,14 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 					sub.enqueueMessage(msg)
 				}
 
+				// clients connected through other server instances receive the stream over the bus
+				if streambus.Distributed() {
+					err := streambus.Current.Publish(streambus.StreamChannel(planId, branch), msg)
+					if err != nil {
+						log.Printf("Error publishing stream message to bus: %v\n", err)
+					}
+				}
+
 			}
 		}
 	}()
@@ -239,19 +269,6 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	}
 
 	// Direct send path
-	msgJson, err := json.Marshal(msg)
-	if err != nil {
-		ap.streamMu.Unlock()
-		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error marshalling stream message: %v", err))
-
-		ap.StreamDoneCh <- &shared.ApiError{
-			Type:   shared.ApiErrorTypeOther,
-			Status: http.StatusInternalServerError,
-			Msg:    "Error marshalling stream message: " + err.Error(),
-		}
-		return
-	}
-
 	if skipBuffer && len(ap.streamMessageBuffer) > 0 {
 		// Handle any remaining buffered messages before sending the message
 		// log.Println("ActivePlan.Stream: message is a skip buffer type and there are buffered messages")
@@ -289,11 +306,32 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 		}
 	}
 
+	ap.streamSeq++
+	msg.Seq = ap.streamSeq
+
+	msgJson, err := json.Marshal(msg)
+	if err != nil {
+		ap.streamMu.Unlock()
+		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error marshalling stream message: %v", err))
+
+		ap.StreamDoneCh <- &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error marshalling stream message: " + err.Error(),
+		}
+		return
+	}
+
 	if verboseStreamLogging {
 		log.Println("ActivePlan.Stream: sending direct message")
 		log.Println(string(msgJson))
 	}
 
+	ap.replayBuffer = append(ap.replayBuffer, replayEntry{seq: msg.Seq, msg: string(msgJson)})
+	if len(ap.replayBuffer) > StreamReplayBufferSize {
+		ap.replayBuffer = ap.replayBuffer[len(ap.replayBuffer)-StreamReplayBufferSize:]
+	}
+
 	ap.streamCh <- string(msgJson)
 
 	now := time.Now()
@@ -310,6 +348,52 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	}
 }
 
+// StreamMessagesSince returns the sent messages with a seq greater than the given one, along with the
+// latest seq they cover. If some of them are no longer in the replay buffer, it returns false and the
+// client needs the full connect state instead.
+func (ap *ActivePlan) StreamMessagesSince(seq int64) ([]string, int64, bool) {
+	ap.streamMu.Lock()
+	defer ap.streamMu.Unlock()
+
+	if seq == ap.streamSeq {
+		return nil, seq, true
+	}
+
+	// the client was following a different stream for this plan
+	if seq > ap.streamSeq {
+		return nil, 0, false
+	}
+
+	if len(ap.replayBuffer) == 0 || ap.replayBuffer[0].seq > seq+1 {
+		return nil, 0, false
+	}
+
+	var msgs []string
+	for _, entry := range ap.replayBuffer {
+		if entry.seq > seq {
+			msgs = append(msgs, entry.msg)
+		}
+	}
+
+	return msgs, ap.streamSeq, true
+}
+
+// StreamBudgetWarning sends a spend budget warning to the client once per active plan--every model request checks budgets, so it would otherwise repeat for each build and summary
+func (ap *ActivePlan) StreamBudgetWarning(warning string) {
+	ap.budgetWarningMu.Lock()
+	if ap.budgetWarningSent {
+		ap.budgetWarningMu.Unlock()
+		return
+	}
+	ap.budgetWarningSent = true
+	ap.budgetWarningMu.Unlock()
+
+	ap.Stream(shared.StreamMessage{
+		Type:    shared.StreamMessageWarning,
+		Warning: warning,
+	})
+}
+
 func (ap *ActivePlan) ResetModelCtx() {
 	ap.ModelStreamCtx, ap.CancelModelStreamFn = context.WithCancel(ap.Ctx)
 }
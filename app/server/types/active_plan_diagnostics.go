@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"plandex-server/streambus"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+// how long a build waits for the CLI to type check a file--language servers can take a while to load a project on the first check
+const DiagnosticsTimeout = 90 * time.Second
+
+// how often a build waiting on diagnostics checks that a client is still connected to answer
+const diagnosticsClientCheckInterval = time.Second
+
+func (ap *ActivePlan) DiagnosticsEnabledForPath(path string) bool {
+	if ap.diagnosticsClientGone() {
+		return false
+	}
+
+	ap.diagnosticsMu.Lock()
+	defer ap.diagnosticsMu.Unlock()
+
+	if ap.diagnosticsTimedOut {
+		return false
+	}
+
+	ext := shared.NormalizeDiagnosticsExt(filepath.Ext(path))
+	if ext == "" {
+		return false
+	}
+
+	for _, enabled := range ap.DiagnosticsExts {
+		if strings.EqualFold(enabled, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestDiagnostics streams a request to type check a built file to the CLI and waits for the errors it finds. If the CLI doesn't respond in time, diagnostics are turned off for the rest of the stream so later builds don't wait too.
+func (ap *ActivePlan) RequestDiagnostics(path, original, updated string) ([]string, error) {
+	id := uuid.New().String()
+	ch := make(chan shared.DiagnosticsResponse, 1)
+
+	ap.diagnosticsMu.Lock()
+	ap.diagnosticsChs[id] = ch
+	ap.diagnosticsMu.Unlock()
+
+	defer func() {
+		ap.diagnosticsMu.Lock()
+		delete(ap.diagnosticsChs, id)
+		ap.diagnosticsMu.Unlock()
+	}()
+
+	ap.Stream(shared.StreamMessage{
+		Type: shared.StreamMessageDiagnostics,
+		DiagnosticsRequest: &shared.DiagnosticsRequest{
+			Id:       id,
+			Path:     path,
+			Original: original,
+			Updated:  updated,
+		},
+	})
+
+	timer := time.NewTimer(DiagnosticsTimeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(diagnosticsClientCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ap.Ctx.Done():
+			return nil, ap.Ctx.Err()
+		case <-ticker.C:
+			if ap.diagnosticsClientGone() {
+				log.Printf("RequestDiagnostics - %s - no client connected, turning off diagnostics for this stream\n", path)
+				ap.disableDiagnostics()
+				return nil, fmt.Errorf("no client connected to check diagnostics")
+			}
+		case <-timer.C:
+			log.Printf("RequestDiagnostics - %s - timed out waiting for the CLI, turning off diagnostics for this stream\n", path)
+			ap.disableDiagnostics()
+			return nil, fmt.Errorf("timed out waiting for diagnostics")
+		case res := <-ch:
+			if res.Error != "" {
+				return nil, fmt.Errorf("error checking %s: %s", path, res.Error)
+			}
+			return res.Errors, nil
+		}
+	}
+}
+
+func (ap *ActivePlan) disableDiagnostics() {
+	ap.diagnosticsMu.Lock()
+	ap.diagnosticsTimedOut = true
+	ap.diagnosticsMu.Unlock()
+}
+
+// diagnosticsClientGone is true when no client is connected to answer diagnostics requests, like after the stream was sent to the background. Clients connected through other server instances aren't counted here, so with a distributed stream bus a build still waits for the timeout.
+func (ap *ActivePlan) diagnosticsClientGone() bool {
+	if streambus.Distributed() {
+		return false
+	}
+	return ap.NumSubscribers() == 0
+}
+
+// ResolveDiagnostics passes the CLI's response to the build waiting on it. It returns false if no build is waiting, which happens when more than one client is connected, or the build already timed out.
+func (ap *ActivePlan) ResolveDiagnostics(res shared.DiagnosticsResponse) bool {
+	ap.diagnosticsMu.Lock()
+	ch, ok := ap.diagnosticsChs[res.Id]
+	if ok {
+		delete(ap.diagnosticsChs, res.Id)
+	}
+	ap.diagnosticsMu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	ch <- res
+	return true
+}
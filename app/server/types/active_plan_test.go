@@ -0,0 +1,125 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"plandex-server/shutdown"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestStreamMessagesSince(t *testing.T) {
+	if shutdown.ShutdownCtx == nil {
+		shutdown.ShutdownCtx = context.Background()
+	}
+	ap := NewActivePlan("org", "user", "plan", "main", "prompt", false, false, "session")
+	defer ap.CancelFn()
+
+	// load context messages skip the rate limit buffer, so each one is sent with its own seq
+	for i := 1; i <= StreamReplayBufferSize+10; i++ {
+		ap.Stream(shared.StreamMessage{
+			Type:             shared.StreamMessageLoadContext,
+			LoadContextFiles: []string{fmt.Sprintf("file%d", i)},
+		})
+	}
+	latest := int64(StreamReplayBufferSize + 10)
+
+	msgs, upTo, ok := ap.StreamMessagesSince(latest - 3)
+	if !ok || upTo != latest || len(msgs) != 3 {
+		t.Fatalf("unexpected resume: %v %d %v", msgs, upTo, ok)
+	}
+	var first shared.StreamMessage
+	if err := json.Unmarshal([]byte(msgs[0]), &first); err != nil {
+		t.Fatalf("error unmarshalling replayed message: %v", err)
+	}
+	if first.Seq != latest-2 || first.LoadContextFiles[0] != fmt.Sprintf("file%d", latest-2) {
+		t.Fatalf("unexpected first replayed message: %+v", first)
+	}
+
+	msgs, upTo, ok = ap.StreamMessagesSince(latest)
+	if !ok || upTo != latest || len(msgs) != 0 {
+		t.Fatalf("expected nothing to replay when caught up: %v %d %v", msgs, upTo, ok)
+	}
+
+	// the oldest buffered message is seq 11, so resuming from 10 still works but 9 doesn't
+	if _, _, ok = ap.StreamMessagesSince(10); !ok {
+		t.Fatalf("expected resume from the message just before the buffer to succeed")
+	}
+	if _, _, ok = ap.StreamMessagesSince(9); ok {
+		t.Fatalf("expected resume to fail once messages were evicted")
+	}
+
+	if _, _, ok = ap.StreamMessagesSince(latest + 1); ok {
+		t.Fatalf("expected resume from a seq ahead of the stream to fail")
+	}
+
+	// rate limited messages are only numbered once they're flushed, as a single multi message
+	ap.Stream(shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "a"})
+	ap.Stream(shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "b"})
+	if _, upTo, _ = ap.StreamMessagesSince(latest); upTo != latest {
+		t.Fatalf("expected buffered messages not to be replayable yet, got up to %d", upTo)
+	}
+	time.Sleep(MaxStreamRate)
+	ap.FlushStreamBuffer()
+
+	msgs, upTo, ok = ap.StreamMessagesSince(latest)
+	if !ok || upTo != latest+1 || len(msgs) != 1 {
+		t.Fatalf("unexpected resume after flush: %v %d %v", msgs, upTo, ok)
+	}
+	var multi shared.StreamMessage
+	if err := json.Unmarshal([]byte(msgs[0]), &multi); err != nil {
+		t.Fatalf("error unmarshalling replayed message: %v", err)
+	}
+	if multi.Type != shared.StreamMessageMulti || len(multi.StreamMessages) != 2 {
+		t.Fatalf("expected the buffered replies as one multi message: %+v", multi)
+	}
+}
+
+func TestDiagnosticsEnabledForPath(t *testing.T) {
+	ap := &ActivePlan{
+		DiagnosticsExts: []string{".go", ".ts"},
+		subscriptions:   map[string]*subscription{"client": {}},
+	}
+
+	if !ap.DiagnosticsEnabledForPath("server/main.go") || !ap.DiagnosticsEnabledForPath("web/App.TS") {
+		t.Fatalf("expected diagnostics for configured extensions")
+	}
+	if ap.DiagnosticsEnabledForPath("README.md") || ap.DiagnosticsEnabledForPath("Makefile") {
+		t.Fatalf("expected no diagnostics for other files")
+	}
+
+	ap.subscriptions = map[string]*subscription{}
+	if ap.DiagnosticsEnabledForPath("server/main.go") {
+		t.Fatalf("expected diagnostics to be off with no client connected")
+	}
+
+	ap.subscriptions = map[string]*subscription{"client": {}}
+	ap.diagnosticsTimedOut = true
+	if ap.DiagnosticsEnabledForPath("server/main.go") {
+		t.Fatalf("expected diagnostics to be off after a timeout")
+	}
+}
+
+func TestRequestDiagnosticsNoClient(t *testing.T) {
+	if shutdown.ShutdownCtx == nil {
+		shutdown.ShutdownCtx = context.Background()
+	}
+	ap := NewActivePlan("org", "user", "plan", "main", "prompt", false, false, "session")
+	defer ap.CancelFn()
+	ap.DiagnosticsExts = []string{".go"}
+
+	start := time.Now()
+	_, err := ap.RequestDiagnostics("main.go", "package main", "package main\n")
+	if err == nil {
+		t.Fatalf("expected an error with no client connected")
+	}
+	if time.Since(start) > 5*diagnosticsClientCheckInterval {
+		t.Fatalf("expected the request to give up without waiting for the timeout, took %s", time.Since(start))
+	}
+	if ap.DiagnosticsEnabledForPath("main.go") {
+		t.Fatalf("expected diagnostics to stay off for the rest of the stream")
+	}
+}
@@ -11,6 +11,17 @@ type ServerAuth struct {
 	User        *db.User
 	OrgId       string
 	Permissions shared.Permissions
+
+	// set when the request was authenticated with an API token rather than a session
+	ApiToken *db.ApiToken
+}
+
+// HasScope reports whether an API token allows scope. Session auth isn't scoped.
+func (a *ServerAuth) HasScope(scope shared.ApiTokenScope) bool {
+	if a.ApiToken == nil {
+		return true
+	}
+	return a.ApiToken.Scopes.Includes(scope)
 }
 
 func (a *ServerAuth) HasPermission(permission shared.Permission) bool {
@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Webhook urls are set by org members, so deliveries can't be allowed to reach the server's own network.
+// Addresses are checked when each connection is made, after dns resolution, so a hostname can't be
+// pointed at an internal address once the webhook has been created.
+
+var errBlockedAddress = errors.New("webhook url resolves to a loopback, private, or link-local address")
+
+// blocked on top of the ranges covered by netip.Addr's own checks
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade nat, also used for some cloud metadata endpoints
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // nat64, which can map to any ipv4 address
+}
+
+// self-hosted servers can opt in to sending webhooks to internal services
+var allowPrivateAddrs = os.Getenv("WEBHOOKS_ALLOW_PRIVATE_NETWORKS") != "" && os.Getenv("IS_CLOUD") == ""
+
+var httpClient = &http.Client{
+	Timeout: deliveryTimeout,
+	Transport: &http.Transport{
+		// a proxy would make the connection on our behalf, skipping the address check
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: deliveryTimeout,
+			Control: checkDialAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   deliveryTimeout,
+		ResponseHeaderTimeout: deliveryTimeout,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       90 * time.Second,
+	},
+	// a redirect could point anywhere, including at an internal address over a different scheme
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func checkDialAddress(network, address string, c syscall.RawConn) error {
+	if allowPrivateAddrs {
+		return nil
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errBlockedAddress
+	}
+
+	if isBlockedAddr(addrPort.Addr()) {
+		return errBlockedAddress
+	}
+
+	return nil
+}
+
+func isBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return true
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
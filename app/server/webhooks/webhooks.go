@@ -0,0 +1,180 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"plandex-server/db"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+// Outgoing webhooks for plan lifecycle events. Triggering an event queues a delivery in postgres for each of the org's webhooks that subscribes to it; the delivery worker sends them and retries failures with backoff.
+
+type TriggerParams struct {
+	Event    shared.WebhookEvent
+	OrgId    string
+	UserId   string
+	PlanId   string
+	PlanName string
+	Branch   string
+
+	Path      string
+	Paths     []string
+	CommitMsg string
+	Error     string
+}
+
+// Enabled is false on Plandex Cloud, where webhooks aren't supported
+func Enabled() bool {
+	return os.Getenv("IS_CLOUD") == ""
+}
+
+// Trigger queues deliveries for an event without blocking the caller--errors are only logged
+func Trigger(params TriggerParams) {
+	if !Enabled() {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Webhooks: panic triggering %s: %v\n", params.Event, r)
+			}
+		}()
+
+		err := trigger(params)
+		if err != nil {
+			log.Printf("Webhooks: error triggering %s: %v\n", params.Event, err)
+		}
+	}()
+}
+
+func trigger(params TriggerParams) error {
+	webhooks, err := db.ListWebhooks(params.OrgId)
+	if err != nil {
+		return err
+	}
+
+	var subscribed []*db.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Events.Includes(params.Event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	if params.PlanId != "" && params.PlanName == "" {
+		plan, err := db.GetPlan(params.PlanId)
+		if err != nil {
+			return fmt.Errorf("error getting plan: %v", err)
+		}
+		if plan != nil {
+			params.PlanName = plan.Name
+		}
+	}
+
+	payload := newPayload(params)
+
+	for _, webhook := range subscribed {
+		err := queueDelivery(webhook, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	wakeWorker()
+
+	return nil
+}
+
+// SendPing sends a test delivery to a single webhook right away, whatever events it subscribes to. It isn't queued or retried.
+func SendPing(webhook *db.Webhook, userId string) (int, error) {
+	payload := newPayload(TriggerParams{
+		Event:  shared.WebhookEventPing,
+		OrgId:  webhook.OrgId,
+		UserId: userId,
+	})
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("error marshalling webhook payload: %v", err)
+	}
+
+	return send(webhook.Url, webhook.Secret, payload.Event, uuid.New().String(), bytes)
+}
+
+func newPayload(params TriggerParams) *shared.WebhookPayload {
+	payload := &shared.WebhookPayload{
+		Id:        uuid.New().String(),
+		Event:     params.Event,
+		CreatedAt: time.Now().UTC(),
+		OrgId:     params.OrgId,
+		UserId:    params.UserId,
+		PlanId:    params.PlanId,
+		PlanName:  params.PlanName,
+		Branch:    params.Branch,
+		Path:      params.Path,
+		Paths:     params.Paths,
+		CommitMsg: params.CommitMsg,
+		Error:     params.Error,
+	}
+	payload.Text = payloadText(payload)
+	return payload
+}
+
+func queueDelivery(webhook *db.Webhook, payload *shared.WebhookPayload) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %v", err)
+	}
+
+	return db.CreateWebhookDelivery(&db.WebhookDelivery{
+		WebhookId: webhook.Id,
+		OrgId:     webhook.OrgId,
+		Event:     payload.Event,
+		Payload:   string(bytes),
+	})
+}
+
+func payloadText(payload *shared.WebhookPayload) string {
+	plan := fmt.Sprintf("Plan '%s' (branch '%s')", payload.PlanName, payload.Branch)
+
+	switch payload.Event {
+	case shared.WebhookEventPlanFinished:
+		return plan + " finished"
+	case shared.WebhookEventPlanError:
+		return plan + " stopped with an error: " + payload.Error
+	case shared.WebhookEventBuildFinished:
+		return fmt.Sprintf("%s built %s", plan, payload.Path)
+	case shared.WebhookEventMissingFile:
+		return fmt.Sprintf("%s needs attention: it's waiting for a response about %s, which isn't in context", plan, payload.Path)
+	case shared.WebhookEventChangesApplied:
+		if len(payload.Paths) == 1 {
+			return fmt.Sprintf("%s applied changes to %s", plan, payload.Paths[0])
+		}
+		return fmt.Sprintf("%s applied changes to %d files", plan, len(payload.Paths))
+	case shared.WebhookEventPing:
+		return "Test delivery from Plandex"
+	}
+
+	return string(payload.Event)
+}
+
+func NewSecret() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}
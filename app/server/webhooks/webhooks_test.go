@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+// test servers listen on loopback, which deliveries are otherwise blocked from reaching
+func allowLoopback(t *testing.T) {
+	allowPrivateAddrs = true
+	t.Cleanup(func() { allowPrivateAddrs = false })
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	allowLoopback(t)
+
+	var gotHeader http.Header
+	var gotBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	body := []byte(`{"event":"plan_finished"}`)
+	status, err := send(server.URL, "secret", shared.WebhookEventPlanFinished, "delivery-id", body)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("got status %d", status)
+	}
+
+	if string(gotBody) != string(body) {
+		t.Fatalf("got body %s", gotBody)
+	}
+
+	if gotHeader.Get(shared.WebhookEventHeader) != "plan_finished" || gotHeader.Get(shared.WebhookDeliveryHeader) != "delivery-id" {
+		t.Fatalf("missing event headers: %v", gotHeader)
+	}
+
+	if !shared.VerifyWebhookSignature("secret", gotHeader.Get(shared.WebhookTimestampHeader), gotBody, gotHeader.Get(shared.WebhookSignatureHeader)) {
+		t.Fatalf("signature didn't verify: %s", gotHeader.Get(shared.WebhookSignatureHeader))
+	}
+}
+
+func TestSendErrorStatus(t *testing.T) {
+	allowLoopback(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	status, err := send(server.URL, "secret", shared.WebhookEventPing, "delivery-id", []byte(`{}`))
+	if err == nil || status != http.StatusBadGateway {
+		t.Fatalf("expected a 502 error, got %d %v", status, err)
+	}
+	if strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected the response body to be left out of the error: %v", err)
+	}
+}
+
+func TestSendBlocksLoopback(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	status, err := send(server.URL, "secret", shared.WebhookEventPing, "delivery-id", []byte(`{}`))
+	if err != errBlockedAddress || status != 0 || called {
+		t.Fatalf("expected the delivery to be blocked, got %d %v", status, err)
+	}
+
+	// a hostname that resolves to loopback is checked after resolution
+	status, err = send(strings.Replace(server.URL, "127.0.0.1", "localhost", 1), "secret", shared.WebhookEventPing, "delivery-id", []byte(`{}`))
+	if err != errBlockedAddress || status != 0 || called {
+		t.Fatalf("expected the delivery to localhost to be blocked, got %d %v", status, err)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	allowLoopback(t)
+
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	status, err := send(server.URL, "secret", shared.WebhookEventPing, "delivery-id", []byte(`{}`))
+	if err == nil || status != http.StatusTemporaryRedirect || redirected {
+		t.Fatalf("expected the redirect to be returned as a failed delivery, got %d %v", status, err)
+	}
+}
+
+func TestIsBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"64:ff9b::a00:1", true},
+		{"224.0.0.1", true},
+		{"8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		if got := isBlockedAddr(netip.MustParseAddr(tt.addr)); got != tt.blocked {
+			t.Errorf("isBlockedAddr(%s) = %v, want %v", tt.addr, got, tt.blocked)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if d := retryDelay(0); d == nil || *d != retryDelays[0] {
+		t.Fatalf("expected first retry after %s", retryDelays[0])
+	}
+
+	if d := retryDelay(len(retryDelays) - 1); d == nil || *d != retryDelays[len(retryDelays)-1] {
+		t.Fatalf("expected a last retry")
+	}
+
+	if d := retryDelay(len(retryDelays)); d != nil {
+		t.Fatalf("expected no retries left, got %s", *d)
+	}
+}
+
+func TestPayloadText(t *testing.T) {
+	payload := newPayload(TriggerParams{
+		Event:    shared.WebhookEventMissingFile,
+		PlanName: "auth",
+		Branch:   "main",
+		Path:     "src/auth.go",
+	})
+
+	if !strings.Contains(payload.Text, "'auth'") || !strings.Contains(payload.Text, "src/auth.go") {
+		t.Fatalf("unexpected text: %s", payload.Text)
+	}
+
+	payload = newPayload(TriggerParams{
+		Event:    shared.WebhookEventChangesApplied,
+		PlanName: "auth",
+		Branch:   "main",
+		Paths:    []string{"a.go", "b.go"},
+	})
+
+	if !strings.Contains(payload.Text, "2 files") {
+		t.Fatalf("unexpected text: %s", payload.Text)
+	}
+}
@@ -0,0 +1,194 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strconv"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const (
+	pollInterval      = 5 * time.Second
+	claimBatchSize    = 20
+	deliveryTimeout   = 10 * time.Second
+	deliveryRetention = 7 * 24 * time.Hour
+	pruneInterval     = time.Hour
+
+	// a claimed delivery is skipped by other instances for this long
+	claimLease = 2 * time.Minute
+)
+
+// delay before each retry--a delivery that still fails after the last one is marked failed
+var retryDelays = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	4 * time.Hour,
+	8 * time.Hour,
+}
+
+var wakeCh = make(chan struct{}, 1)
+
+func wakeWorker() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// StartWorker delivers queued webhooks until ctx is done. Every server instance runs one; claims keep them from sending the same delivery twice.
+func StartWorker(ctx context.Context) {
+	if !Enabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		lastPrune := time.Time{}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-wakeCh:
+			}
+
+			processDue()
+
+			if time.Since(lastPrune) > pruneInterval {
+				err := db.DeleteOldWebhookDeliveries(deliveryRetention)
+				if err != nil {
+					log.Printf("Webhooks: %v\n", err)
+				}
+				lastPrune = time.Now()
+			}
+		}
+	}()
+}
+
+func processDue() {
+	for {
+		deliveries, err := db.ClaimWebhookDeliveries(claimBatchSize, claimLease)
+		if err != nil {
+			log.Printf("Webhooks: %v\n", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *db.WebhookDelivery) {
+				defer wg.Done()
+				processDelivery(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < claimBatchSize {
+			return
+		}
+	}
+}
+
+func processDelivery(delivery *db.WebhookDelivery) {
+	webhook, err := db.GetWebhook(delivery.OrgId, delivery.WebhookId)
+	if err != nil {
+		log.Printf("Webhooks: %v\n", err)
+		return
+	}
+
+	// deleted since the delivery was queued--the cascade removes the delivery too
+	if webhook == nil {
+		return
+	}
+
+	statusCode, err := send(webhook.Url, webhook.Secret, delivery.Event, delivery.Id, []byte(delivery.Payload))
+
+	var statusCodePtr *int
+	if statusCode != 0 {
+		statusCodePtr = &statusCode
+	}
+
+	if err == nil {
+		err = db.UpdateWebhookDeliveryAttempt(delivery.Id, true, statusCodePtr, nil, nil)
+		if err != nil {
+			log.Printf("Webhooks: %v\n", err)
+		}
+		return
+	}
+
+	errMsg := err.Error()
+	retryIn := retryDelay(delivery.Attempts)
+
+	if retryIn == nil {
+		log.Printf("Webhooks: giving up on delivery %s to %s after %d attempts: %s\n", delivery.Id, webhook.Url, delivery.Attempts+1, errMsg)
+	} else {
+		log.Printf("Webhooks: delivery %s to %s failed, retrying in %s: %s\n", delivery.Id, webhook.Url, *retryIn, errMsg)
+	}
+
+	err = db.UpdateWebhookDeliveryAttempt(delivery.Id, false, statusCodePtr, &errMsg, retryIn)
+	if err != nil {
+		log.Printf("Webhooks: %v\n", err)
+	}
+}
+
+// retryDelay returns the delay before the next attempt after 'attempts' previous failed attempts plus the one that just failed, or nil if there are no retries left
+func retryDelay(attempts int) *time.Duration {
+	if attempts >= len(retryDelays) {
+		return nil
+	}
+	delay := retryDelays[attempts]
+	return &delay
+}
+
+// send posts a signed payload and returns the response status code, or an error for a failed request or a non-2xx response.
+// Errors are shown to org members, so they never include the response body or details of why a connection failed.
+func send(url, secret string, event shared.WebhookEvent, deliveryId string, body []byte) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plandex-Webhooks")
+	req.Header.Set(shared.WebhookEventHeader, string(event))
+	req.Header.Set(shared.WebhookDeliveryHeader, deliveryId)
+	req.Header.Set(shared.WebhookTimestampHeader, timestamp)
+	req.Header.Set(shared.WebhookSignatureHeader, shared.SignWebhookPayload(secret, timestamp, body))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf("Webhooks: error sending delivery %s: %v\n", deliveryId, err)
+		if errors.Is(err, errBlockedAddress) {
+			return 0, errBlockedAddress
+		}
+		return 0, errors.New("error sending request")
+	}
+	defer res.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1024))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("received status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
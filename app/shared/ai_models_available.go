@@ -1,6 +1,8 @@
 package shared
 
 import (
+	"strings"
+
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -112,6 +114,28 @@ var AvailableModels = []*AvailableModel{
 		},
 	},
 
+	{
+		Description:           "OpenAI o3-pro (via the Responses API)",
+		DefaultMaxConvoTokens: 15000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderOpenAI,
+			ModelName:                  "o3-pro",
+			ModelId:                    "openai/o3-pro",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            100000,
+			ReservedOutputTokens:       40000,
+			ApiKeyEnvVar:               OpenAIEnvVar,
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    OpenAIV1BaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			RoleParamsDisabled:         true,
+			ReasoningEffortEnabled:     true,
+			ReasoningEffort:            ReasoningEffortHigh,
+			StopDisabled:               true,
+			UsesOpenAIResponsesAPI:     true,
+		},
+	},
+
 	{
 		Description:           "OpenAI o4-mini-high",
 		DefaultMaxConvoTokens: 10000,
@@ -282,6 +306,85 @@ var AvailableModels = []*AvailableModel{
 		},
 	},
 
+	// Direct Anthropic models
+	{
+		Description:           "Anthropic Claude 3.7 Sonnet via the native Anthropic API",
+		DefaultMaxConvoTokens: 15000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-7-sonnet-20250219",
+			ModelId:                    "anthropic/claude-3.7-sonnet",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            64000,
+			ReservedOutputTokens:       20000,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               AnthropicApiKeyEnvVar,
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    AnthropicV1BaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			TokenEstimatePaddingPct:    0.10,
+		},
+	},
+	{
+		Description:           "Anthropic Claude 3.7 Sonnet (thinking—includes reasoning) via the native Anthropic API",
+		DefaultMaxConvoTokens: 15000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-7-sonnet-20250219",
+			ModelId:                    "anthropic/claude-3.7-sonnet:thinking",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            64000,
+			ReservedOutputTokens:       40000,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               AnthropicApiKeyEnvVar,
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    AnthropicV1BaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			IncludeReasoning:           true,
+			ReasoningBudget:            16000,
+			RoleParamsDisabled:         true,
+			TokenEstimatePaddingPct:    0.10,
+		},
+	},
+	{
+		Description:           "Anthropic Claude 3.7 Sonnet (thinking—reasoning hidden) via the native Anthropic API",
+		DefaultMaxConvoTokens: 15000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-7-sonnet-20250219",
+			ModelId:                    "anthropic/claude-3.7-sonnet:thinking-hidden",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            64000,
+			ReservedOutputTokens:       40000,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               AnthropicApiKeyEnvVar,
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    AnthropicV1BaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			ReasoningBudget:            16000,
+			RoleParamsDisabled:         true,
+			TokenEstimatePaddingPct:    0.10,
+		},
+	},
+	{
+		Description:           "Anthropic Claude 3.5 Haiku via the native Anthropic API",
+		DefaultMaxConvoTokens: 15000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-5-haiku-20241022",
+			ModelId:                    "anthropic/claude-3.5-haiku",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            8192,
+			ReservedOutputTokens:       8192,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               AnthropicApiKeyEnvVar,
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    AnthropicV1BaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			TokenEstimatePaddingPct:    0.10,
+		},
+	},
+
 	// OpenRouter models
 	{
 		Description:           "Anthropic Claude 3.7 Sonnet via OpenRouter",
@@ -827,6 +930,94 @@ var AvailableModels = []*AvailableModel{
 			ReasoningEffort:            ReasoningEffortLow,
 		},
 	},
+
+	// Local models via Ollama—context limits assume OLLAMA_CONTEXT_LENGTH is raised to at least 32k (Ollama's default is much lower)
+	// use 'plandex models discover' to add any other installed local models
+	{
+		Description:           "Qwen 2.5 Coder 32B via local Ollama",
+		DefaultMaxConvoTokens: 4000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderOllama,
+			ModelName:                  "qwen2.5-coder:32b",
+			ModelId:                    "ollama/qwen2.5-coder:32b",
+			MaxTokens:                  32768,
+			MaxOutputTokens:            8192,
+			ReservedOutputTokens:       8192,
+			ApiKeyEnvVar:               OllamaApiKeyEnvVar,
+			BaseUrl:                    OllamaBaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+		},
+	},
+	{
+		Description:           "Qwen 2.5 Coder 7B via local Ollama",
+		DefaultMaxConvoTokens: 4000,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderOllama,
+			ModelName:                  "qwen2.5-coder:7b",
+			ModelId:                    "ollama/qwen2.5-coder:7b",
+			MaxTokens:                  32768,
+			MaxOutputTokens:            8192,
+			ReservedOutputTokens:       8192,
+			ApiKeyEnvVar:               OllamaApiKeyEnvVar,
+			BaseUrl:                    OllamaBaseUrl,
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+		},
+	},
+}
+
+/*
+'ModelPricingByName' is the provider's list price for each model, keyed by the model name without any provider prefix or ':variant' suffix (so 'anthropic/claude-3.7-sonnet:thinking' uses the 'claude-3.7-sonnet' price). It's used to enforce spend budgets on self-hosted servers. Local models and models missing from this list are treated as free.
+*/
+var ModelPricingByName = map[string]ModelPricing{
+	// OpenAI
+	"o3":           {InputPerMillion: 2, CachedInputPerMillion: 0.5, OutputPerMillion: 8},
+	"o3-pro":       {InputPerMillion: 20, OutputPerMillion: 80},
+	"o4-mini":      {InputPerMillion: 1.1, CachedInputPerMillion: 0.275, OutputPerMillion: 4.4},
+	"o3-mini":      {InputPerMillion: 1.1, CachedInputPerMillion: 0.55, OutputPerMillion: 4.4},
+	"gpt-4.1":      {InputPerMillion: 2, CachedInputPerMillion: 0.5, OutputPerMillion: 8},
+	"gpt-4.1-mini": {InputPerMillion: 0.4, CachedInputPerMillion: 0.1, OutputPerMillion: 1.6},
+	"gpt-4.1-nano": {InputPerMillion: 0.1, CachedInputPerMillion: 0.025, OutputPerMillion: 0.4},
+
+	// OpenAI embeddings (semantic index)
+	"text-embedding-3-small": {InputPerMillion: 0.02},
+	"text-embedding-3-large": {InputPerMillion: 0.13},
+	"text-embedding-ada-002": {InputPerMillion: 0.1},
+
+	// Anthropic
+	"claude-3-7-sonnet-20250219": {InputPerMillion: 3, CachedInputPerMillion: 0.3, OutputPerMillion: 15},
+	"claude-3.7-sonnet":          {InputPerMillion: 3, CachedInputPerMillion: 0.3, OutputPerMillion: 15},
+	"claude-3.5-sonnet":          {InputPerMillion: 3, CachedInputPerMillion: 0.3, OutputPerMillion: 15},
+	"claude-3-5-haiku-20241022":  {InputPerMillion: 0.8, CachedInputPerMillion: 0.08, OutputPerMillion: 4},
+	"claude-3.5-haiku":           {InputPerMillion: 0.8, CachedInputPerMillion: 0.08, OutputPerMillion: 4},
+
+	// Google
+	"gemini-pro-1.5":               {InputPerMillion: 1.25, OutputPerMillion: 5},
+	"gemini-2.5-pro-preview-03-25": {InputPerMillion: 1.25, CachedInputPerMillion: 0.31, OutputPerMillion: 10},
+	"gemini-2.5-flash-preview":     {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+
+	// DeepSeek
+	"deepseek-chat-v3-0324": {InputPerMillion: 0.27, CachedInputPerMillion: 0.07, OutputPerMillion: 1.1},
+	"deepseek-r1":           {InputPerMillion: 0.55, CachedInputPerMillion: 0.14, OutputPerMillion: 2.19},
+
+	// Perplexity
+	"r1-1776":         {InputPerMillion: 2, OutputPerMillion: 8},
+	"sonar-reasoning": {InputPerMillion: 1, OutputPerMillion: 5},
+
+	// Qwen
+	"qwen-2.5-coder-32b-instruct": {InputPerMillion: 0.07, OutputPerMillion: 0.16},
+}
+
+func GetModelPricing(modelName ModelName) (ModelPricing, bool) {
+	name := string(modelName)
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ":"); i != -1 {
+		name = name[:i]
+	}
+
+	pricing, ok := ModelPricingByName[name]
+	return pricing, ok
 }
 
 var AvailableModelsByComposite = map[string]*AvailableModel{}
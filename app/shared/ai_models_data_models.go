@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type ModelCompatibility struct {
@@ -42,9 +44,12 @@ type BaseModelConfig struct {
 	IncludeReasoning           bool              `json:"includeReasoning"`
 	SupportsCacheControl       bool              `json:"supportsCacheControl"`
 
-	// for openai responses API, not fully implemented yet
+	// for openai models that are only available through the responses API
 	UsesOpenAIResponsesAPI bool `json:"usesOpenAIResponsesAPI"`
 
+	// for the native anthropic provider, token budget for extended thinking (0 disables thinking)
+	ReasoningBudget int `json:"reasoningBudget,omitempty"`
+
 	// for anthropic, single message system prompt needs to be flipped to 'user'
 	SingleMessageNoSystemPrompt bool `json:"singleMessageNoSystemPrompt"`
 
@@ -72,6 +77,33 @@ func (m *AvailableModel) ModelString() string {
 	return s
 }
 
+// prices are in USD per 1M tokens
+type ModelPricing struct {
+	InputPerMillion       float64
+	CachedInputPerMillion float64 // if 0, cached input is billed at the input price
+	OutputPerMillion      float64
+}
+
+// Cost returns the USD cost of a request—'inputTokens' includes 'cachedTokens'
+func (p ModelPricing) Cost(inputTokens, cachedTokens, outputTokens int) decimal.Decimal {
+	cachedPrice := p.CachedInputPerMillion
+	if cachedPrice == 0 {
+		cachedPrice = p.InputPerMillion
+	}
+
+	uncachedTokens := inputTokens - cachedTokens
+	if uncachedTokens < 0 {
+		uncachedTokens = 0
+	}
+
+	perMillion := decimal.NewFromInt(1000000)
+	input := decimal.NewFromFloat(p.InputPerMillion).Mul(decimal.NewFromInt(int64(uncachedTokens)))
+	cached := decimal.NewFromFloat(cachedPrice).Mul(decimal.NewFromInt(int64(cachedTokens)))
+	output := decimal.NewFromFloat(p.OutputPerMillion).Mul(decimal.NewFromInt(int64(outputTokens)))
+
+	return input.Add(cached).Add(output).Div(perMillion)
+}
+
 type PlannerModelConfig struct {
 	MaxConvoTokens int `json:"maxConvoTokens"`
 }
@@ -183,6 +215,27 @@ func (m *ModelPack) GetArchitect() ModelRoleConfig {
 	return *m.Architect
 }
 
+// UsesSelfHostedOnlyProvider is true if any role uses a provider that can't be reached from Plandex Cloud
+func (m *ModelPack) UsesSelfHostedOnlyProvider() bool {
+	roles := []ModelRoleConfig{
+		m.Planner.ModelRoleConfig,
+		m.GetCoder(),
+		m.GetArchitect(),
+		m.PlanSummary,
+		m.Builder,
+		m.GetWholeFileBuilder(),
+		m.Namer,
+		m.CommitMsg,
+		m.ExecStatus,
+	}
+	for _, role := range roles {
+		if role.BaseModelConfig.Provider.IsSelfHostedOnly() {
+			return true
+		}
+	}
+	return false
+}
+
 type ModelOverrides struct {
 	MaxConvoTokens       *int `json:"maxConvoTokens"`
 	MaxTokens            *int `json:"maxContextTokens"`
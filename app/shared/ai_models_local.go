@@ -0,0 +1,222 @@
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Local models served by Ollama (or any server exposing Ollama-style /api/tags and /api/show endpoints, like a llama.cpp server behind an Ollama-compatible proxy). Requests go through the OpenAI-compatible /v1 endpoint; the native endpoints are only used for discovery.
+
+// used when a local model doesn't report its context length
+const DefaultLocalModelContextLength = 8192
+
+type OllamaModelDetails struct {
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// an entry from GET /api/tags
+type OllamaModelTag struct {
+	Name    string             `json:"name"`
+	Model   string             `json:"model"`
+	Size    int64              `json:"size"`
+	Details OllamaModelDetails `json:"details"`
+}
+
+type OllamaTagsResponse struct {
+	Models []OllamaModelTag `json:"models"`
+}
+
+// response from POST /api/show
+type OllamaShowResponse struct {
+	Details      OllamaModelDetails `json:"details"`
+	ModelInfo    map[string]any     `json:"model_info"`
+	Capabilities []string           `json:"capabilities"`
+}
+
+// an entry from a llama.cpp server's GET /v1/models, used when /api/tags isn't available
+type LlamaCppModel struct {
+	Id   string `json:"id"`
+	Meta *struct {
+		NCtxTrain int `json:"n_ctx_train"`
+	} `json:"meta,omitempty"`
+}
+
+type LlamaCppModelsResponse struct {
+	Data []LlamaCppModel `json:"data"`
+}
+
+// LocalModelInfo is what discovery knows about an installed local model
+type LocalModelInfo struct {
+	Name            string
+	ContextLength   int
+	ParameterCount  float64 // in billions, 0 if unknown
+	HasImageSupport bool
+}
+
+// OllamaNativeBaseUrl converts an OpenAI-compatible base url (http://localhost:11434/v1) to the native Ollama api root (http://localhost:11434)
+func OllamaNativeBaseUrl(baseUrl string) string {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+	return strings.TrimSuffix(baseUrl, "/v1")
+}
+
+// OllamaOpenAIBaseUrl is the inverse of OllamaNativeBaseUrl
+func OllamaOpenAIBaseUrl(baseUrl string) string {
+	return OllamaNativeBaseUrl(baseUrl) + "/v1"
+}
+
+// GetContextLength finds the '<architecture>.context_length' key in model_info
+func (r *OllamaShowResponse) GetContextLength() int {
+	for key, val := range r.ModelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		switch v := val.(type) {
+		case float64:
+			return int(v)
+		case int:
+			return v
+		}
+	}
+	return 0
+}
+
+func (r *OllamaShowResponse) HasCapability(capability string) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseParameterSize parses Ollama's parameter_size strings like "32.8B" or "600M" into billions
+func ParseParameterSize(s string) float64 {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0
+	}
+
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "B"):
+		s = strings.TrimSuffix(s, "B")
+	case strings.HasSuffix(s, "M"):
+		s = strings.TrimSuffix(s, "M")
+		mult = 0.001
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return n * mult
+}
+
+// NewLocalAvailableModel builds the model config for a discovered local model, deriving token limits from its context length
+func NewLocalAvailableModel(baseUrl string, info LocalModelInfo) *AvailableModel {
+	contextLength := info.ContextLength
+	if contextLength <= 0 {
+		contextLength = DefaultLocalModelContextLength
+	}
+
+	// local models share one context window between input and output, so output is capped at a quarter of it
+	maxOutputTokens := contextLength / 4
+	if maxOutputTokens > 16384 {
+		maxOutputTokens = 16384
+	}
+
+	var defaultMaxConvoTokens int
+	switch {
+	case contextLength >= 180000:
+		defaultMaxConvoTokens = 15000
+	case contextLength >= 100000:
+		defaultMaxConvoTokens = 10000
+	default:
+		defaultMaxConvoTokens = contextLength / 8
+	}
+
+	description := fmt.Sprintf("Local model %s (%dk context)", info.Name, contextLength/1024)
+	if info.ParameterCount > 0 {
+		description = fmt.Sprintf("Local model %s (%.1fB params, %dk context)", info.Name, info.ParameterCount, contextLength/1024)
+	}
+
+	return &AvailableModel{
+		Description:           description,
+		DefaultMaxConvoTokens: defaultMaxConvoTokens,
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderOllama,
+			ModelName:                  ModelName(info.Name),
+			ModelId:                    ModelId("ollama/" + info.Name),
+			MaxTokens:                  contextLength,
+			MaxOutputTokens:            maxOutputTokens,
+			ReservedOutputTokens:       maxOutputTokens,
+			ApiKeyEnvVar:               OllamaApiKeyEnvVar,
+			BaseUrl:                    OllamaOpenAIBaseUrl(baseUrl),
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+			ModelCompatibility: ModelCompatibility{
+				HasImageSupport: info.HasImageSupport,
+			},
+		},
+	}
+}
+
+// NewLocalModelPack maps every model role to local models—'heavy' handles planning, coding, and building, while 'light' handles naming, commit messages, summaries, and auto-continue checks
+func NewLocalModelPack(name, description string, heavy, light *AvailableModel) ModelPack {
+	if light == nil {
+		light = heavy
+	}
+
+	roleConfig := func(role ModelRole, m *AvailableModel) ModelRoleConfig {
+		return ModelRoleConfig{
+			Role:            role,
+			BaseModelConfig: m.BaseModelConfig,
+			Temperature:     DefaultConfigByRole[role].Temperature,
+			TopP:            DefaultConfigByRole[role].TopP,
+		}
+	}
+
+	coder := roleConfig(ModelRoleCoder, heavy)
+	architect := roleConfig(ModelRoleArchitect, heavy)
+	wholeFileBuilder := roleConfig(ModelRoleWholeFileBuilder, heavy)
+
+	return ModelPack{
+		Name:        name,
+		Description: description,
+		Planner: PlannerRoleConfig{
+			ModelRoleConfig: roleConfig(ModelRolePlanner, heavy),
+			PlannerModelConfig: PlannerModelConfig{
+				MaxConvoTokens: heavy.DefaultMaxConvoTokens,
+			},
+		},
+		Coder:            &coder,
+		Architect:        &architect,
+		PlanSummary:      roleConfig(ModelRolePlanSummary, light),
+		Builder:          roleConfig(ModelRoleBuilder, heavy),
+		WholeFileBuilder: &wholeFileBuilder,
+		Namer:            roleConfig(ModelRoleName, light),
+		CommitMsg:        roleConfig(ModelRoleCommitMsg, light),
+		ExecStatus:       roleConfig(ModelRoleExecStatus, light),
+	}
+}
+
+// PickLocalPackModels chooses the heavy and light models for a local pack—the largest model by parameter count (then context length) is heavy, the smallest is light
+func PickLocalPackModels(infos []LocalModelInfo) (heavy, light *LocalModelInfo) {
+	if len(infos) == 0 {
+		return nil, nil
+	}
+
+	sorted := make([]LocalModelInfo, len(infos))
+	copy(sorted, infos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ParameterCount != sorted[j].ParameterCount {
+			return sorted[i].ParameterCount > sorted[j].ParameterCount
+		}
+		return sorted[i].ContextLength > sorted[j].ContextLength
+	})
+
+	return &sorted[0], &sorted[len(sorted)-1]
+}
@@ -0,0 +1,172 @@
+package shared
+
+import "testing"
+
+func TestParseParameterSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"32.8B", 32.8},
+		{"7b", 7},
+		{" 600M ", 0.6},
+		{"1.5", 1.5},
+		{"", 0},
+		{"unknown", 0},
+		{"12K", 0},
+	}
+
+	for _, tt := range tests {
+		got := ParseParameterSize(tt.in)
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("ParseParameterSize(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetContextLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelInfo map[string]any
+		want      int
+	}{
+		{"json number", map[string]any{"general.architecture": "qwen2", "qwen2.context_length": float64(32768)}, 32768},
+		{"int", map[string]any{"llama.context_length": 131072}, 131072},
+		{"missing", map[string]any{"general.architecture": "llama"}, 0},
+		{"wrong type", map[string]any{"llama.context_length": "8192"}, 0},
+		{"nil", nil, 0},
+	}
+
+	for _, tt := range tests {
+		r := OllamaShowResponse{ModelInfo: tt.modelInfo}
+		if got := r.GetContextLength(); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOllamaBaseUrls(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantNative string
+		wantOpenAI string
+	}{
+		{"http://localhost:11434/v1", "http://localhost:11434", "http://localhost:11434/v1"},
+		{"http://localhost:11434/v1/", "http://localhost:11434", "http://localhost:11434/v1"},
+		{"http://localhost:11434", "http://localhost:11434", "http://localhost:11434/v1"},
+		{"http://gpu-box:8080/", "http://gpu-box:8080", "http://gpu-box:8080/v1"},
+	}
+
+	for _, tt := range tests {
+		if got := OllamaNativeBaseUrl(tt.in); got != tt.wantNative {
+			t.Errorf("OllamaNativeBaseUrl(%q) = %q, want %q", tt.in, got, tt.wantNative)
+		}
+		if got := OllamaOpenAIBaseUrl(tt.in); got != tt.wantOpenAI {
+			t.Errorf("OllamaOpenAIBaseUrl(%q) = %q, want %q", tt.in, got, tt.wantOpenAI)
+		}
+	}
+}
+
+func TestNewLocalAvailableModel(t *testing.T) {
+	m := NewLocalAvailableModel("http://localhost:11434", LocalModelInfo{
+		Name:           "qwen2.5-coder:32b",
+		ContextLength:  32768,
+		ParameterCount: 32.8,
+	})
+
+	if m.Provider != ModelProviderOllama {
+		t.Errorf("provider = %q, want %q", m.Provider, ModelProviderOllama)
+	}
+	if m.ModelId != "ollama/qwen2.5-coder:32b" || m.ModelName != "qwen2.5-coder:32b" {
+		t.Errorf("unexpected model id/name: %q %q", m.ModelId, m.ModelName)
+	}
+	if m.BaseUrl != "http://localhost:11434/v1" {
+		t.Errorf("base url = %q", m.BaseUrl)
+	}
+	if m.ApiKeyEnvVar != OllamaApiKeyEnvVar {
+		t.Errorf("api key env var = %q", m.ApiKeyEnvVar)
+	}
+	if m.MaxTokens != 32768 || m.MaxOutputTokens != 8192 || m.ReservedOutputTokens != 8192 {
+		t.Errorf("unexpected token limits: max %d, output %d, reserved %d", m.MaxTokens, m.MaxOutputTokens, m.ReservedOutputTokens)
+	}
+	if m.DefaultMaxConvoTokens != 4096 {
+		t.Errorf("default max convo tokens = %d, want 4096", m.DefaultMaxConvoTokens)
+	}
+	if m.Description != "Local model qwen2.5-coder:32b (32.8B params, 32k context)" {
+		t.Errorf("description = %q", m.Description)
+	}
+}
+
+func TestNewLocalAvailableModelLimits(t *testing.T) {
+	unknown := NewLocalAvailableModel(OllamaBaseUrl, LocalModelInfo{Name: "mystery"})
+	if unknown.MaxTokens != DefaultLocalModelContextLength {
+		t.Errorf("unknown context length: max tokens = %d, want %d", unknown.MaxTokens, DefaultLocalModelContextLength)
+	}
+	if unknown.Description != "Local model mystery (8k context)" {
+		t.Errorf("description = %q", unknown.Description)
+	}
+
+	large := NewLocalAvailableModel(OllamaBaseUrl, LocalModelInfo{Name: "big", ContextLength: 200000})
+	if large.MaxOutputTokens != 16384 {
+		t.Errorf("large context: max output tokens = %d, want 16384", large.MaxOutputTokens)
+	}
+	if large.DefaultMaxConvoTokens != 15000 {
+		t.Errorf("large context: default max convo tokens = %d, want 15000", large.DefaultMaxConvoTokens)
+	}
+
+	medium := NewLocalAvailableModel(OllamaBaseUrl, LocalModelInfo{Name: "medium", ContextLength: 128000})
+	if medium.DefaultMaxConvoTokens != 10000 {
+		t.Errorf("medium context: default max convo tokens = %d, want 10000", medium.DefaultMaxConvoTokens)
+	}
+}
+
+func TestPickLocalPackModels(t *testing.T) {
+	heavy, light := PickLocalPackModels(nil)
+	if heavy != nil || light != nil {
+		t.Fatalf("expected nil models for no input")
+	}
+
+	infos := []LocalModelInfo{
+		{Name: "small", ParameterCount: 7, ContextLength: 32768},
+		{Name: "big-short", ParameterCount: 32, ContextLength: 8192},
+		{Name: "big-long", ParameterCount: 32, ContextLength: 32768},
+		{Name: "unknown", ContextLength: 4096},
+	}
+
+	heavy, light = PickLocalPackModels(infos)
+	if heavy.Name != "big-long" {
+		t.Errorf("heavy = %q, want big-long", heavy.Name)
+	}
+	if light.Name != "unknown" {
+		t.Errorf("light = %q, want unknown", light.Name)
+	}
+	if infos[0].Name != "small" {
+		t.Errorf("input was reordered")
+	}
+
+	heavy, light = PickLocalPackModels(infos[:1])
+	if heavy.Name != "small" || light.Name != "small" {
+		t.Errorf("single model: got %q and %q", heavy.Name, light.Name)
+	}
+}
+
+func TestGetBuiltInModelPacks(t *testing.T) {
+	hasOllama := func(packs []*ModelPack) bool {
+		for _, pack := range packs {
+			if pack.Name == OllamaModelPack.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !hasOllama(GetBuiltInModelPacks(false)) {
+		t.Errorf("expected the ollama pack when self-hosting")
+	}
+	if hasOllama(GetBuiltInModelPacks(true)) {
+		t.Errorf("expected no ollama pack on cloud")
+	}
+	if len(GetBuiltInModelPacks(true)) != len(BuiltInModelPacks)-1 {
+		t.Errorf("expected only the ollama pack to be removed on cloud")
+	}
+}
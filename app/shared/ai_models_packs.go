@@ -5,6 +5,7 @@ var StrongModelPack ModelPack
 var OSSModelPack ModelPack
 var CheapModelPack ModelPack
 var AnthropicModelPack ModelPack
+var AnthropicDirectModelPack ModelPack
 var OpenAIModelPack ModelPack
 
 var GeminiPreviewModelPack ModelPack
@@ -12,12 +13,15 @@ var GeminiExperimentalModelPack ModelPack
 var R1PlannerModelPack ModelPack
 var PerplexityPlannerModelPack ModelPack
 
+var OllamaModelPack ModelPack
+
 var BuiltInModelPacks = []*ModelPack{
 	&StrongModelPack,
 	&CheapModelPack,
 	&OSSModelPack,
 
 	&AnthropicModelPack,
+	&AnthropicDirectModelPack,
 	&OpenAIModelPack,
 
 	&GeminiPreviewModelPack,
@@ -25,10 +29,27 @@ var BuiltInModelPacks = []*ModelPack{
 
 	&R1PlannerModelPack,
 	&PerplexityPlannerModelPack,
+
+	&OllamaModelPack,
 }
 
 var DefaultModelPack *ModelPack = &StrongModelPack
 
+// GetBuiltInModelPacks returns the built-in model packs, leaving out local packs on Plandex Cloud since a local server can't be reached from there
+func GetBuiltInModelPacks(isCloud bool) []*ModelPack {
+	if !isCloud {
+		return BuiltInModelPacks
+	}
+
+	var res []*ModelPack
+	for _, pack := range BuiltInModelPacks {
+		if !pack.UsesSelfHostedOnlyProvider() {
+			res = append(res, pack)
+		}
+	}
+	return res
+}
+
 func init() {
 	DailyDriverModelPack = ModelPack{
 		Name:        "daily-driver",
@@ -163,6 +184,25 @@ func init() {
 		ExecStatus:       *claude37Sonnet(ModelRoleExecStatus, nil),
 	}
 
+	AnthropicDirectModelPack = ModelPack{
+		Name:        "anthropic-direct",
+		Description: "Uses Claude 3.7 Sonnet for planning and coding and Claude 3.5 Haiku for light tasks through the native Anthropic API, default models for other roles. Requires ANTHROPIC_API_KEY. Supports up to 160k input context.",
+		Planner: PlannerRoleConfig{
+			ModelRoleConfig:    *claude37SonnetDirect(ModelRolePlanner, nil),
+			PlannerModelConfig: getPlannerModelConfig(ModelProviderAnthropic, "anthropic/claude-3.7-sonnet"),
+		},
+		Coder:       claude37SonnetDirect(ModelRoleCoder, nil),
+		Architect:   claude37SonnetDirect(ModelRoleArchitect, nil),
+		PlanSummary: *openaio4miniLowWitho3MiniFallback(ModelRolePlanSummary, nil),
+		Builder: *openaio4miniMediumWitho3MiniFallback(ModelRoleBuilder, &modelConfig{
+			strongModel: openaio4miniHighWitho3MiniFallback(ModelRoleBuilder, nil),
+		}),
+		WholeFileBuilder: openaio4miniMediumWitho3MiniFallback(ModelRoleWholeFileBuilder, nil),
+		Namer:            *claude35haikuDirect(ModelRoleName, nil),
+		CommitMsg:        *claude35haikuDirect(ModelRoleCommitMsg, nil),
+		ExecStatus:       *openaio4miniLowWitho3MiniFallback(ModelRoleExecStatus, nil),
+	}
+
 	GeminiPreviewModelPack = ModelPack{
 		Name:        "gemini-preview",
 		Description: "Uses Gemini 2.5 Pro Preview for planning and coding, default models for other roles. Supports up to 1M input context.",
@@ -231,6 +271,13 @@ func init() {
 		ExecStatus:       *openaio4miniMediumWitho3MiniFallback(ModelRoleExecStatus, nil),
 	}
 
+	OllamaModelPack = NewLocalModelPack(
+		"ollama",
+		"Fully local models served by Ollama, no API keys required. Uses Qwen 2.5 Coder 32B for heavy lifting, Qwen 2.5 Coder 7B for lighter tasks. Supports up to 32k context—set OLLAMA_CONTEXT_LENGTH on the Ollama server to match.",
+		GetAvailableModel(ModelProviderOllama, "ollama/qwen2.5-coder:32b"),
+		GetAvailableModel(ModelProviderOllama, "ollama/qwen2.5-coder:7b"),
+	)
+
 }
 
 type modelConfig struct {
@@ -264,6 +311,10 @@ func claude37Sonnet(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
 	return getModelConfig(role, ModelProviderOpenRouter, "anthropic/claude-3.7-sonnet", fallbacks)
 }
 
+func claude37SonnetDirect(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
+	return getModelConfig(role, ModelProviderAnthropic, "anthropic/claude-3.7-sonnet", fallbacks)
+}
+
 func claude37SonnetThinking(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
 	return getModelConfig(role, ModelProviderOpenRouter, "anthropic/claude-3.7-sonnet:thinking", fallbacks)
 }
@@ -280,6 +331,10 @@ func claude35haiku(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
 	return getModelConfig(role, ModelProviderOpenRouter, "anthropic/claude-3.5-haiku", fallbacks)
 }
 
+func claude35haikuDirect(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
+	return getModelConfig(role, ModelProviderAnthropic, "anthropic/claude-3.5-haiku", fallbacks)
+}
+
 func gemini15pro(role ModelRole, fallbacks *modelConfig) *ModelRoleConfig {
 	return getModelConfig(role, ModelProviderOpenRouter, "google/gemini-pro-1.5", fallbacks)
 }
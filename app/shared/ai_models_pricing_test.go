@@ -0,0 +1,31 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestModelPricing(t *testing.T) {
+	pricing, ok := GetModelPricing("anthropic/claude-3.7-sonnet:thinking")
+	if !ok {
+		t.Fatalf("expected pricing for variant model name")
+	}
+
+	// 100k input (60k cached) + 10k output
+	cost := pricing.Cost(100000, 60000, 10000)
+	if !cost.Equal(decimal.RequireFromString("0.288")) {
+		t.Errorf("cost = %s, want 0.288", cost)
+	}
+
+	// without a cached price, cached input is billed at the input price
+	noCachePrice := ModelPricing{InputPerMillion: 2, OutputPerMillion: 8}
+	cost = noCachePrice.Cost(1000000, 500000, 0)
+	if !cost.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("cost = %s, want 2", cost)
+	}
+
+	if _, ok := GetModelPricing("ollama/qwen2.5-coder:32b"); ok {
+		t.Errorf("local models shouldn't have pricing")
+	}
+}
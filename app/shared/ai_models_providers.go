@@ -4,18 +4,26 @@ const OpenAIEnvVar = "OPENAI_API_KEY"
 const OpenAIV1BaseUrl = "https://api.openai.com/v1"
 const OpenRouterApiKeyEnvVar = "OPENROUTER_API_KEY"
 const OpenRouterBaseUrl = "https://openrouter.ai/api/v1"
+const AnthropicApiKeyEnvVar = "ANTHROPIC_API_KEY"
+const AnthropicV1BaseUrl = "https://api.anthropic.com/v1"
+const OllamaApiKeyEnvVar = "OLLAMA_API_KEY"
+const OllamaBaseUrl = "http://localhost:11434/v1"
 
 type ModelProvider string
 
 const (
 	ModelProviderOpenRouter ModelProvider = "openrouter"
 	ModelProviderOpenAI     ModelProvider = "openai"
+	ModelProviderAnthropic  ModelProvider = "anthropic"
+	ModelProviderOllama     ModelProvider = "ollama"
 	ModelProviderCustom     ModelProvider = "custom"
 )
 
 var AllModelProviders = []string{
 	string(ModelProviderOpenAI),
 	string(ModelProviderOpenRouter),
+	string(ModelProviderAnthropic),
+	string(ModelProviderOllama),
 	// string(ModelProviderTogether),
 	string(ModelProviderCustom),
 }
@@ -23,9 +31,23 @@ var AllModelProviders = []string{
 var BaseUrlByProvider = map[ModelProvider]string{
 	ModelProviderOpenAI:     OpenAIV1BaseUrl,
 	ModelProviderOpenRouter: OpenRouterBaseUrl,
+	ModelProviderAnthropic:  AnthropicV1BaseUrl,
+	ModelProviderOllama:     OllamaBaseUrl,
 }
 
 var ApiKeyByProvider = map[ModelProvider]string{
 	ModelProviderOpenAI:     OpenAIEnvVar,
 	ModelProviderOpenRouter: OpenRouterApiKeyEnvVar,
+	ModelProviderAnthropic:  AnthropicApiKeyEnvVar,
+	ModelProviderOllama:     OllamaApiKeyEnvVar,
+}
+
+// local providers don't need a real api key—if the env var isn't set, a placeholder is sent so the server can still create a client
+var OptionalApiKeyEnvVars = map[string]bool{
+	OllamaApiKeyEnvVar: true,
+}
+
+// local and custom providers can't be reached from Plandex Cloud
+func (p ModelProvider) IsSelfHostedOnly() bool {
+	return p == ModelProviderCustom || p == ModelProviderOllama
 }
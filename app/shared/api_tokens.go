@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ApiTokenPrefix starts every API token, so the server can tell them apart from session tokens
+const ApiTokenPrefix = "pdx_"
+
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, ApiTokenPrefix)
+}
+
+type ApiTokenScope string
+
+const (
+	// list and view plans, their context, convo, changes and logs, and connect to streams
+	ApiTokenScopeRead ApiTokenScope = "read"
+	// tell, build, and every other change to plans and projects
+	ApiTokenScopeTell ApiTokenScope = "tell"
+	// apply or reject pending changes
+	ApiTokenScopeApply ApiTokenScope = "apply"
+)
+
+var ApiTokenScopes = []ApiTokenScope{ApiTokenScopeRead, ApiTokenScopeTell, ApiTokenScopeApply}
+
+func IsValidApiTokenScope(scope ApiTokenScope) bool {
+	for _, s := range ApiTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type ApiTokenScopeList []ApiTokenScope
+
+// Includes reports whether the scopes allow scope. Every token can read, so tell and apply imply read.
+func (l ApiTokenScopeList) Includes(scope ApiTokenScope) bool {
+	if scope == ApiTokenScopeRead && len(l) > 0 {
+		return true
+	}
+	for _, s := range l {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ApiTokenScopeList) Scan(src interface{}) error {
+	if src == nil {
+		*l = ApiTokenScopeList{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, l)
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (l ApiTokenScopeList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// org admin permissions are never granted to API tokens, whatever the role of the user or service account they belong to
+var apiTokenExcludedPermissions = map[Permission]bool{
+	PermissionDeleteOrg:             true,
+	PermissionManageEmailDomainAuth: true,
+	PermissionManageBilling:         true,
+	PermissionInviteUser:            true,
+	PermissionRemoveUser:            true,
+	PermissionSetUserRole:           true,
+	PermissionManageExecPolicy:      true,
+	PermissionManageWebhooks:        true,
+	PermissionManageServiceAccounts: true,
+	PermissionManageOrgRoles:        true,
+	PermissionManageCustomModels:    true,
+	PermissionManageModelPacks:      true,
+}
+
+func IsApiTokenPermission(permission Permission) bool {
+	return !apiTokenExcludedPermissions[permission]
+}
+
+// ApiToken is a personal access token or a service account's token. The token itself is only returned when it's created.
+type ApiToken struct {
+	Id               string            `json:"id"`
+	OrgId            string            `json:"orgId"`
+	UserId           string            `json:"userId"`
+	ServiceAccountId *string           `json:"serviceAccountId,omitempty"`
+	Name             string            `json:"name"`
+	TokenPrefix      string            `json:"tokenPrefix"`
+	Scopes           ApiTokenScopeList `json:"scopes"`
+	ExpiresAt        *time.Time        `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time        `json:"lastUsedAt,omitempty"`
+	CreatedBy        *string           `json:"createdBy,omitempty"`
+	CreatedAt        time.Time         `json:"createdAt"`
+}
+
+// ServiceAccount is an org member that isn't a person, for CI pipelines and other automation. It owns the plans its tokens create.
+type ServiceAccount struct {
+	Id        string    `json:"id"`
+	OrgId     string    `json:"orgId"`
+	UserId    string    `json:"userId"`
+	Name      string    `json:"name"`
+	OrgRoleId string    `json:"orgRoleId"`
+	CreatedBy *string   `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
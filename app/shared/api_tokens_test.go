@@ -0,0 +1,42 @@
+package shared
+
+import "testing"
+
+func TestApiTokenScopeListIncludes(t *testing.T) {
+	tell := ApiTokenScopeList{ApiTokenScopeTell}
+
+	if !tell.Includes(ApiTokenScopeRead) {
+		t.Fatalf("expected every token to include read")
+	}
+	if !tell.Includes(ApiTokenScopeTell) {
+		t.Fatalf("expected tell token to include tell")
+	}
+	if tell.Includes(ApiTokenScopeApply) {
+		t.Fatalf("expected tell token not to include apply")
+	}
+
+	if (ApiTokenScopeList{}).Includes(ApiTokenScopeRead) {
+		t.Fatalf("expected a token without scopes not to include read")
+	}
+}
+
+func TestIsApiTokenPermission(t *testing.T) {
+	if !IsApiTokenPermission(PermissionManageAnyPlanShares) {
+		t.Fatalf("expected plan permissions to be allowed for API tokens")
+	}
+
+	for _, permission := range []Permission{PermissionManageBilling, PermissionInviteUser, PermissionManageServiceAccounts} {
+		if IsApiTokenPermission(permission) {
+			t.Fatalf("expected %s not to be allowed for API tokens", permission)
+		}
+	}
+}
+
+func TestIsApiToken(t *testing.T) {
+	if !IsApiToken("pdx_0123abcd") {
+		t.Fatalf("expected pdx_ token to be an API token")
+	}
+	if IsApiToken("0f6c2b0e-5f1e-4c57-9d7c-1a2b3c4d5e6f") {
+		t.Fatalf("expected session token not to be an API token")
+	}
+}
@@ -25,11 +25,16 @@ const (
 
 	ApiErrorTypeContinueNoMessages ApiErrorType = "continue_no_messages"
 
+	// another user or session is already streaming on the plan branch
+	ApiErrorTypePlanStreamActive ApiErrorType = "plan_stream_active"
+
 	ApiErrorTypeCloudInsufficientCredits ApiErrorType = "cloud_insufficient_credits"
 	ApiErrorTypeCloudMonthlyMaxReached   ApiErrorType = "cloud_monthly_max_reached"
 	ApiErrorTypeCloudSubscriptionPaused  ApiErrorType = "cloud_subscription_paused"
 	ApiErrorTypeCloudSubscriptionOverdue ApiErrorType = "cloud_subscription_overdue"
 
+	ApiErrorTypeSpendBudgetExceeded ApiErrorType = "spend_budget_exceeded"
+
 	ApiErrorTypeOther ApiErrorType = "other"
 )
 
@@ -31,6 +31,7 @@ type ContextUpdateResult struct {
 	NumImages       int
 	NumTrees        int
 	NumMaps         int
+	NumSymbols      int
 	MaxTokens       int
 }
 
@@ -59,6 +60,9 @@ func (c *Context) TypeAndIcon() (string, string) {
 	case ContextMapType:
 		icon = "🗺️ "
 		t = "map"
+	case ContextSymbolType:
+		icon = "🧩"
+		t = "symbol"
 	}
 
 	return t, icon
@@ -117,6 +121,7 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	var numTrees int
 	var numUrls int
 	var numMaps int
+	var numSymbols int
 
 	for _, context := range contexts {
 		switch context.ContextType {
@@ -132,6 +137,8 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 			hasPiped = true
 		case ContextMapType:
 			numMaps++
+		case ContextSymbolType:
+			numSymbols++
 		}
 	}
 
@@ -171,6 +178,13 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 		}
 		added = append(added, fmt.Sprintf("%d %s", numMaps, label))
 	}
+	if numSymbols > 0 {
+		label := "symbol"
+		if numSymbols > 1 {
+			label = "symbols"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numSymbols, label))
+	}
 
 	msg := "Loaded "
 
@@ -239,6 +253,7 @@ type SummaryForUpdateContextParams struct {
 	NumTrees    int
 	NumUrls     int
 	NumMaps     int
+	NumSymbols  int
 	TokensDiff  int
 	TotalTokens int
 }
@@ -248,6 +263,7 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 	numTrees := params.NumTrees
 	numUrls := params.NumUrls
 	numMaps := params.NumMaps
+	numSymbols := params.NumSymbols
 	tokensDiff := params.TokensDiff
 	totalTokens := params.TotalTokens
 
@@ -281,6 +297,13 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 		}
 		toAdd = append(toAdd, fmt.Sprintf("%d map%s", numMaps, postfix))
 	}
+	if numSymbols > 0 {
+		postfix := "s"
+		if numSymbols == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d symbol%s", numSymbols, postfix))
+	}
 
 	if len(toAdd) <= 2 {
 		msg += " " + strings.Join(toAdd, " and ")
@@ -24,6 +24,7 @@ type User struct {
 	Email            string `json:"email"`
 	IsTrial          bool   `json:"isTrial"`
 	NumNonDraftPlans int    `json:"numNonDraftPlans"`
+	IsServiceAccount bool   `json:"isServiceAccount,omitempty"`
 
 	DefaultPlanConfig *PlanConfig `json:"defaultPlanConfig,omitempty"`
 }
@@ -63,6 +64,9 @@ type Plan struct {
 	ArchivedAt      *time.Time  `json:"archivedAt,omitempty"`
 	CreatedAt       time.Time   `json:"createdAt"`
 	UpdatedAt       time.Time   `json:"updatedAt"`
+
+	// set when the plan is listed for a user it's shared with
+	SharedAccess PlanShareAccess `json:"sharedAccess,omitempty"`
 }
 
 type Branch struct {
@@ -90,6 +94,7 @@ const (
 	ContextPipedDataType     ContextType = "piped data"
 	ContextImageType         ContextType = "image"
 	ContextMapType           ContextType = "map"
+	ContextSymbolType        ContextType = "symbol"
 )
 
 type FileMapBodies map[string]string
@@ -101,6 +106,7 @@ type Context struct {
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
 	FilePath        string                `json:"file_path"`
+	Symbol          string                `json:"symbol,omitempty"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
 	Body            string                `json:"body,omitempty"`
@@ -300,9 +306,18 @@ type CurrentPlanState struct {
 
 type OrgRole struct {
 	Id          string `json:"id"`
+	Name        string `json:"name"`
 	IsDefault   bool   `json:"isDefault"`
 	Label       string `json:"label"`
 	Description string `json:"description"`
+	// permission keys, formatted like PermissionKey
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// OrgPermission is a permission that can be given to an org's custom roles
+type OrgPermission struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
 }
 
 type CloudBillingFields struct {
@@ -324,6 +339,71 @@ type CloudBillingFields struct {
 	StripePaymentMethod  *string    `json:"stripePaymentMethod"`
 }
 
+type SpendBudgetScope string
+
+const (
+	SpendBudgetScopeOrg  SpendBudgetScope = "org"
+	SpendBudgetScopePlan SpendBudgetScope = "plan"
+	SpendBudgetScopeUser SpendBudgetScope = "user"
+)
+
+type SpendBudgetPeriod string
+
+const (
+	SpendBudgetPeriodDaily   SpendBudgetPeriod = "daily"
+	SpendBudgetPeriodMonthly SpendBudgetPeriod = "monthly"
+)
+
+// Start returns the beginning of the budget period containing 'now'—periods are in UTC
+func (p SpendBudgetPeriod) Start(now time.Time) time.Time {
+	now = now.UTC()
+	if p == SpendBudgetPeriodMonthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SpendBudget limits model spend on a self-hosted server. A 'plan' or 'user' budget with no PlanId/UserId is the default for every plan/user in the org that doesn't have its own budget for the same period.
+type SpendBudget struct {
+	Id       string            `json:"id"`
+	OrgId    string            `json:"orgId"`
+	Scope    SpendBudgetScope  `json:"scope"`
+	PlanId   *string           `json:"planId,omitempty"`
+	UserId   *string           `json:"userId,omitempty"`
+	Period   SpendBudgetPeriod `json:"period"`
+	LimitUsd decimal.Decimal   `json:"limitUsd"`
+	WarnPct  int               `json:"warnPct"`
+
+	// only set when listing budgets
+	CurrentSpend *decimal.Decimal `json:"currentSpend,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UsageEntry is a single model request recorded in a self-hosted server's usage ledger
+type UsageEntry struct {
+	Id              string          `json:"id"`
+	UserId          *string         `json:"userId,omitempty"`
+	PlanId          *string         `json:"planId,omitempty"`
+	ModelProvider   ModelProvider   `json:"modelProvider"`
+	ModelName       ModelName       `json:"modelName"`
+	ModelRole       ModelRole       `json:"modelRole"`
+	ModelPackName   string          `json:"modelPackName"`
+	Purpose         string          `json:"purpose"`
+	InputTokens     int             `json:"inputTokens"`
+	OutputTokens    int             `json:"outputTokens"`
+	CachedTokens    int             `json:"cachedTokens"`
+	ReasoningTokens int             `json:"reasoningTokens"`
+	Cost            decimal.Decimal `json:"cost"`
+	CacheSavings    decimal.Decimal `json:"cacheSavings"`
+	StoppedEarly    bool            `json:"stoppedEarly"`
+	UserCancelled   bool            `json:"userCancelled"`
+	HadError        bool            `json:"hadError"`
+	NoReportedUsage bool            `json:"noReportedUsage"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
 type CreditsTransactionType string
 
 const (
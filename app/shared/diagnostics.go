@@ -0,0 +1,112 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DiagnosticsConfigFileName = ".plandex-diagnostics.json"
+
+// DiagnosticsConfig turns on type checking of built files before they're applied. For each file with a configured extension, the CLI runs a language server or a compiler command on the original and proposed content, then sends any errors that only the proposed content has back to the server, which tries to fix them.
+type DiagnosticsConfig struct {
+	LanguageServers []DiagnosticsLanguageServer `json:"languageServers,omitempty"`
+	Commands        []DiagnosticsCommand        `json:"commands,omitempty"`
+
+	// per check--defaults to 30 seconds
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// DiagnosticsLanguageServer is a language server that speaks LSP over stdio, like 'gopls', 'typescript-language-server --stdio', or 'pyright-langserver --stdio'. It's started in the project root on the first check and stopped when the stream ends.
+type DiagnosticsLanguageServer struct {
+	Extensions []string `json:"extensions"`
+	Command    []string `json:"command"`
+
+	// sent with each document--defaults to a guess from the extension
+	LanguageId string `json:"languageId,omitempty"`
+}
+
+// DiagnosticsCommand runs in a temp dir that mirrors the project, with the file to check replaced by the content to check. '{file}' in the command is replaced with that file's path. A non-zero exit status means the content has errors, and each non-empty line of output is an error.
+type DiagnosticsCommand struct {
+	Extensions []string `json:"extensions"`
+	Command    string   `json:"command"`
+}
+
+func (c *DiagnosticsConfig) Validate() error {
+	for _, server := range c.LanguageServers {
+		if len(server.Command) == 0 || strings.TrimSpace(server.Command[0]) == "" {
+			return fmt.Errorf("language server command can't be empty")
+		}
+		if len(server.Extensions) == 0 {
+			return fmt.Errorf("language server %s needs at least one extension", server.Command[0])
+		}
+	}
+
+	for _, command := range c.Commands {
+		if strings.TrimSpace(command.Command) == "" {
+			return fmt.Errorf("diagnostics command can't be empty")
+		}
+		if !strings.Contains(command.Command, "{file}") {
+			return fmt.Errorf("diagnostics command '%s' needs a {file} placeholder", command.Command)
+		}
+		if len(command.Extensions) == 0 {
+			return fmt.Errorf("diagnostics command '%s' needs at least one extension", command.Command)
+		}
+	}
+
+	return nil
+}
+
+// Extensions lists every file extension the config can check, normalized with a leading '.'
+func (c *DiagnosticsConfig) Extensions() []string {
+	seen := map[string]bool{}
+	res := []string{}
+
+	add := func(exts []string) {
+		for _, ext := range exts {
+			ext = NormalizeDiagnosticsExt(ext)
+			if ext == "" || seen[ext] {
+				continue
+			}
+			seen[ext] = true
+			res = append(res, ext)
+		}
+	}
+
+	for _, server := range c.LanguageServers {
+		add(server.Extensions)
+	}
+	for _, command := range c.Commands {
+		add(command.Extensions)
+	}
+
+	return res
+}
+
+func NormalizeDiagnosticsExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" {
+		return ""
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// DiagnosticsRequest is streamed to the CLI when a built file can be checked. Path is relative to the project root.
+type DiagnosticsRequest struct {
+	Id       string `json:"id"`
+	Path     string `json:"path"`
+	Original string `json:"original"`
+	Updated  string `json:"updated"`
+}
+
+type DiagnosticsResponse struct {
+	Id string `json:"id"`
+
+	// errors in the updated content that the original content doesn't have
+	Errors []string `json:"errors"`
+
+	// set if the check itself failed--the build continues without diagnostics for the file
+	Error string `json:"error,omitempty"`
+}
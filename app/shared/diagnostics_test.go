@@ -0,0 +1,42 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsConfigExtensions(t *testing.T) {
+	config := DiagnosticsConfig{
+		LanguageServers: []DiagnosticsLanguageServer{
+			{Command: []string{"typescript-language-server", "--stdio"}, Extensions: []string{".ts", "tsx"}},
+		},
+		Commands: []DiagnosticsCommand{
+			{Command: "python -m py_compile {file}", Extensions: []string{".PY", ".ts"}},
+		},
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	got := config.Extensions()
+	want := []string{".ts", ".tsx", ".py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestDiagnosticsConfigValidate(t *testing.T) {
+	invalid := []DiagnosticsConfig{
+		{LanguageServers: []DiagnosticsLanguageServer{{Extensions: []string{".go"}}}},
+		{LanguageServers: []DiagnosticsLanguageServer{{Command: []string{"gopls"}}}},
+		{Commands: []DiagnosticsCommand{{Command: "tsc --noEmit", Extensions: []string{".ts"}}}},
+		{Commands: []DiagnosticsCommand{{Command: "ruby -c {file}"}}},
+	}
+
+	for i, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected config %d to be invalid", i)
+		}
+	}
+}
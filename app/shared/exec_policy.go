@@ -0,0 +1,145 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const ExecPolicyFileName = ".plandex-exec-policy.json"
+
+// ExecPolicy limits which commands can run from _apply.sh. Patterns match a whole command—'*' matches any characters, and a pattern also matches the same command with additional arguments, so 'npm install' matches 'npm install lodash'. Deny patterns always win. If Allow is non-empty, any command that doesn't match an allow pattern needs confirmation, even with auto-exec.
+type ExecPolicy struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+func (p *ExecPolicy) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0)
+}
+
+func (p *ExecPolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("exec policy patterns can't be empty")
+		}
+	}
+	return nil
+}
+
+func (p *ExecPolicy) Scan(src interface{}) error {
+	if src == nil {
+		*p = ExecPolicy{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, p)
+	case string:
+		return json.Unmarshal([]byte(s), p)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (p ExecPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+type ExecPolicyViolationType string
+
+const (
+	ExecPolicyViolationDenied     ExecPolicyViolationType = "denied"
+	ExecPolicyViolationNotAllowed ExecPolicyViolationType = "not_allowed"
+	// the command can't be checked statically, e.g. its name comes from a variable--only reported when a policy has deny rules
+	ExecPolicyViolationUnparseable ExecPolicyViolationType = "unparseable"
+)
+
+type ExecPolicyViolation struct {
+	Type    ExecPolicyViolationType
+	Command string
+	Pattern string // the matching deny pattern
+	Source  string
+}
+
+// NamedExecPolicy is a policy along with where it came from (e.g. 'org' or the project policy file) for display
+type NamedExecPolicy struct {
+	Source string
+	Policy *ExecPolicy
+}
+
+// CheckExecPolicies checks every command in a script against each policy. A command must satisfy all of them—an org allowlist can't be loosened by a project allowlist. Deny patterns are also checked against the command with its directory removed ('/usr/bin/curl' → 'curl') and against commands run through wrappers like sudo or env. When a policy has deny rules, any command that can't be parsed is a violation.
+func CheckExecPolicies(script string, policies []NamedExecPolicy) []ExecPolicyViolation {
+	var violations []ExecPolicyViolation
+
+	commands := parseScript(script, 0)
+
+	for _, policy := range policies {
+		if policy.Policy.IsEmpty() {
+			continue
+		}
+
+		for _, command := range commands {
+			if len(policy.Policy.Deny) > 0 {
+				if pattern, ok := matchExecPatternsAny(policy.Policy.Deny, command.denyCandidates()); ok {
+					violations = append(violations, ExecPolicyViolation{
+						Type:    ExecPolicyViolationDenied,
+						Command: command.text,
+						Pattern: pattern,
+						Source:  policy.Source,
+					})
+					continue
+				}
+
+				if command.unparseable {
+					violations = append(violations, ExecPolicyViolation{
+						Type:    ExecPolicyViolationUnparseable,
+						Command: command.text,
+						Source:  policy.Source,
+					})
+					continue
+				}
+			}
+
+			if len(policy.Policy.Allow) > 0 {
+				if _, ok := matchExecPatterns(policy.Policy.Allow, command.text); !ok || command.unparseable {
+					violations = append(violations, ExecPolicyViolation{
+						Type:    ExecPolicyViolationNotAllowed,
+						Command: command.text,
+						Source:  policy.Source,
+					})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+func matchExecPatternsAny(patterns []string, candidates []string) (string, bool) {
+	for _, candidate := range candidates {
+		if pattern, ok := matchExecPatterns(patterns, candidate); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func matchExecPatterns(patterns []string, command string) (string, bool) {
+	for _, pattern := range patterns {
+		if execPatternRegexp(pattern).MatchString(command) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func execPatternRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(strings.Join(strings.Fields(pattern), " "), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`^` + strings.Join(parts, `.*`) + `(\s.*)?$`)
+}
@@ -0,0 +1,720 @@
+package shared
+
+import (
+	"regexp"
+	"strings"
+)
+
+// shell keywords that can prefix a command
+var shellPrefixKeywords = map[string]bool{"if": true, "then": true, "else": true, "elif": true, "do": true, "while": true, "until": true, "!": true, "{": true, "time": true}
+
+// shell keywords that close a block and don't run anything themselves
+var shellBlockEnds = map[string]bool{"fi": true, "done": true, "esac": true, "}": true, "else": true, "then": true, "do": true}
+
+// commands that run another command passed as arguments, like 'sudo curl ...'
+var execWrapperCommands = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "command": true, "builtin": true, "exec": true, "nohup": true, "nice": true, "ionice": true,
+	"time": true, "timeout": true, "stdbuf": true, "xargs": true, "setsid": true, "watch": true, "chroot": true, "unbuffer": true, "busybox": true,
+}
+
+// shells that run a script passed with -c, or read one from stdin
+var execShellCommands = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "ash": true, "mksh": true}
+
+var envAssignmentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[^\]]*\])?\+?=`)
+
+var redirectionRegexp = regexp.MustCompile(`^([0-9]*|&)(<<<|<<-|<<|<>|>>|>&|<&|>\||<|>)`)
+
+// limits how deeply 'sh -c', eval, and substitutions are followed
+const maxScriptNesting = 8
+
+type shellWord struct {
+	raw     string // as written
+	value   string // with quotes and escapes removed
+	literal bool   // false if the shell would expand it at runtime (variables, substitutions, globs)
+}
+
+// scriptCommand is a single command parsed from a script
+type scriptCommand struct {
+	text        string // as written, with leading env vars, redirections, and keywords removed
+	words       []shellWord
+	unparseable bool
+}
+
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+	expands   bool // an unquoted delimiter means substitutions in the body still run
+	segment   int
+}
+
+type scannedLine struct {
+	segments      []string
+	substitutions []string // bodies of $(...), `...`, <(...), and >(...), which run as separate scripts
+	heredocs      []heredoc
+	ok            bool
+}
+
+// ParseScriptCommands makes a best-effort split of a shell script into individual commands for policy checks. Comments are removed, line continuations are joined, and lines are split on ';', '&&', '||', '|', '&', and subshell parens outside of quotes. Leading env var assignments, redirections, and shell keywords are stripped. The bodies of $(...), backticks, process substitutions, 'sh -c' scripts, eval, and heredocs fed to a shell are parsed as separate commands; other heredoc bodies are skipped. Policies are still no substitute for the exec sandbox—a script can run other scripts or write a file and execute it.
+func ParseScriptCommands(script string) []string {
+	var commands []string
+	for _, command := range parseScript(script, 0) {
+		commands = append(commands, command.text)
+	}
+	return commands
+}
+
+func parseScript(script string, depth int) []scriptCommand {
+	if depth > maxScriptNesting {
+		return []scriptCommand{{text: strings.Join(strings.Fields(script), " "), unparseable: true}}
+	}
+
+	script = strings.ReplaceAll(script, "\\\n", "")
+	lines := strings.Split(script, "\n")
+
+	var commands []scriptCommand
+	for i := 0; i < len(lines); i++ {
+		line := scanShellLine(lines[i])
+		if !line.ok {
+			commands = append(commands, scriptCommand{text: strings.Join(strings.Fields(lines[i]), " "), unparseable: true})
+			continue
+		}
+
+		// heredoc bodies are input, not commands—unless they're fed to a shell or have substitutions
+		bodies := map[int][]string{}
+		for _, doc := range line.heredocs {
+			var body []string
+			for i+1 < len(lines) {
+				i++
+				bodyLine := lines[i]
+				if doc.stripTabs {
+					bodyLine = strings.TrimLeft(bodyLine, "\t")
+				}
+				if bodyLine == doc.delimiter {
+					break
+				}
+				body = append(body, bodyLine)
+			}
+			bodies[doc.segment] = append(bodies[doc.segment], strings.Join(body, "\n"))
+
+			if doc.expands {
+				substitutions, ok := heredocSubstitutions(strings.Join(body, "\n"))
+				if !ok {
+					commands = append(commands, scriptCommand{text: "<<" + doc.delimiter, unparseable: true})
+				}
+				line.substitutions = append(line.substitutions, substitutions...)
+			}
+		}
+
+		for k, segment := range line.segments {
+			command, ok := parseShellCommand(segment)
+			if !ok {
+				continue
+			}
+
+			var nested []scriptCommand
+			if !command.unparseable {
+				programs, unparseable := command.programs()
+				command.unparseable = unparseable
+
+				for _, program := range programs {
+					scripts, readsStdin, unparseable := shellScripts(program)
+					if unparseable {
+						command.unparseable = true
+					}
+					if readsStdin {
+						if docs, ok := bodies[k]; ok {
+							scripts = append(scripts, docs...)
+						} else {
+							// e.g. 'curl ... | sh'—there's no way to know what runs
+							command.unparseable = true
+						}
+					}
+					for _, nestedScript := range scripts {
+						nested = append(nested, parseScript(nestedScript, depth+1)...)
+					}
+				}
+			}
+
+			commands = append(commands, command)
+			commands = append(commands, nested...)
+		}
+
+		for _, substitution := range line.substitutions {
+			commands = append(commands, parseScript(substitution, depth+1)...)
+		}
+	}
+
+	return commands
+}
+
+func scanShellLine(line string) scannedLine {
+	s := scannedLine{ok: true}
+	var current strings.Builder
+	var quote rune
+
+	endSegment := func() {
+		s.segments = append(s.segments, current.String())
+		current.Reset()
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if c == '\\' && quote != '\'' {
+			current.WriteRune(c)
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+			continue
+		}
+
+		if quote == '\'' {
+			if c == '\'' {
+				quote = 0
+			}
+			current.WriteRune(c)
+			continue
+		}
+
+		// substitutions run inside double quotes too
+		if c == '`' {
+			end := closingQuote(runes, i)
+			if end < 0 {
+				s.ok = false
+				return s
+			}
+			s.substitutions = append(s.substitutions, string(runes[i+1:end]))
+			current.WriteString(string(runes[i : end+1]))
+			i = end
+			continue
+		}
+
+		if (c == '$' || (quote == 0 && (c == '<' || c == '>'))) && i+1 < len(runes) && runes[i+1] == '(' {
+			end := matchParen(runes, i+1)
+			if end < 0 {
+				s.ok = false
+				return s
+			}
+			body := string(runes[i+2 : end])
+			if c == '$' && strings.HasPrefix(body, "(") && strings.HasSuffix(body, ")") {
+				// arithmetic—only substitutions inside it run anything
+				inner := scanShellLine(body)
+				if !inner.ok {
+					s.ok = false
+					return s
+				}
+				s.substitutions = append(s.substitutions, inner.substitutions...)
+			} else {
+				s.substitutions = append(s.substitutions, body)
+			}
+			current.WriteString(string(runes[i : end+1]))
+			i = end
+			continue
+		}
+
+		if quote == '"' {
+			if c == '"' {
+				quote = 0
+			}
+			current.WriteRune(c)
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			quote = c
+			current.WriteRune(c)
+		case '#':
+			// comment if at the start of a word
+			if i == 0 || strings.ContainsRune(" \t;|&()", runes[i-1]) {
+				endSegment()
+				return s
+			}
+			current.WriteRune(c)
+		case '<':
+			if i+2 < len(runes) && runes[i+1] == '<' && runes[i+2] == '<' {
+				current.WriteString("<<<")
+				i += 2
+				continue
+			}
+			if i+1 < len(runes) && runes[i+1] == '<' {
+				j := i + 2
+				stripTabs := false
+				if j < len(runes) && runes[j] == '-' {
+					stripTabs = true
+					j++
+				}
+				for j < len(runes) && (runes[j] == ' ' || runes[j] == '\t') {
+					j++
+				}
+				delimiter, quoted, end := heredocDelimiter(runes, j)
+				if delimiter == "" {
+					s.ok = false
+					return s
+				}
+				s.heredocs = append(s.heredocs, heredoc{delimiter: delimiter, stripTabs: stripTabs, expands: !quoted, segment: len(s.segments)})
+				current.WriteString(string(runes[i:end]))
+				i = end - 1
+				continue
+			}
+			current.WriteRune(c)
+		case ';', '|', '&':
+			// '>&', '&>', and '>|' are redirections, not separators
+			if (c == '&' && ((i > 0 && runes[i-1] == '>') || (i+1 < len(runes) && runes[i+1] == '>'))) || (c == '|' && i > 0 && runes[i-1] == '>') {
+				current.WriteRune(c)
+				continue
+			}
+			endSegment()
+			if i+1 < len(runes) && (runes[i+1] == c || (c == ';' && runes[i+1] == '&') || (c == '|' && runes[i+1] == '&')) {
+				i++
+			}
+		case '(', ')':
+			// '()' in a function definition stays with the name
+			if c == '(' && i+1 < len(runes) && runes[i+1] == ')' {
+				current.WriteString("()")
+				i++
+				continue
+			}
+			// otherwise a subshell or group
+			endSegment()
+		default:
+			current.WriteRune(c)
+		}
+	}
+
+	if quote != 0 {
+		s.ok = false
+		return s
+	}
+
+	endSegment()
+	return s
+}
+
+// heredocDelimiter reads the delimiter word starting at start, returning the index just past it
+func heredocDelimiter(runes []rune, start int) (string, bool, int) {
+	var delimiter strings.Builder
+	quoted := false
+
+	i := start
+	for ; i < len(runes); i++ {
+		c := runes[i]
+		if strings.ContainsRune(" \t;|&<>()", c) {
+			break
+		}
+		switch c {
+		case '\\':
+			quoted = true
+			if i+1 < len(runes) {
+				i++
+				delimiter.WriteRune(runes[i])
+			}
+		case '\'', '"':
+			end := closingQuote(runes, i)
+			if end < 0 {
+				return "", false, i
+			}
+			quoted = true
+			delimiter.WriteString(string(runes[i+1 : end]))
+			i = end
+		default:
+			delimiter.WriteRune(c)
+		}
+	}
+
+	return delimiter.String(), quoted, i
+}
+
+// heredocSubstitutions finds the substitutions in a heredoc body with an unquoted delimiter—quotes in the body are literal
+func heredocSubstitutions(body string) ([]string, bool) {
+	var substitutions []string
+
+	runes := []rune(body)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\':
+			i++
+		case runes[i] == '`':
+			end := closingQuote(runes, i)
+			if end < 0 {
+				return nil, false
+			}
+			substitutions = append(substitutions, string(runes[i+1:end]))
+			i = end
+		case runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '(':
+			end := matchParen(runes, i+1)
+			if end < 0 {
+				return nil, false
+			}
+			substitutions = append(substitutions, string(runes[i+2:end]))
+			i = end
+		}
+	}
+
+	return substitutions, true
+}
+
+// closingQuote returns the index of the quote closing the one at start, or -1
+func closingQuote(runes []rune, start int) int {
+	q := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == '\\' && q != '\'' {
+			i++
+			continue
+		}
+		if runes[i] == q {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchParen returns the index of the ')' closing the '(' at open, skipping quoted text, or -1
+func matchParen(runes []rune, open int) int {
+	depth := 0
+	for i := open; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '\'', '"', '`':
+			end := closingQuote(runes, i)
+			if end < 0 {
+				return -1
+			}
+			i = end
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expansionEnd returns the index of the last rune of the expansion starting with the '$' or '`' at start, or -1
+func expansionEnd(runes []rune, start int) int {
+	if runes[start] == '`' {
+		return closingQuote(runes, start)
+	}
+	if start+1 >= len(runes) {
+		return start
+	}
+
+	switch next := runes[start+1]; {
+	case next == '(':
+		return matchParen(runes, start+1)
+	case next == '\'':
+		return closingQuote(runes, start+1)
+	case next == '{':
+		depth := 0
+		for i := start + 1; i < len(runes); i++ {
+			switch runes[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+		return -1
+	default:
+		i := start + 1
+		for i < len(runes) && (runes[i] == '_' || (runes[i] >= 'a' && runes[i] <= 'z') || (runes[i] >= 'A' && runes[i] <= 'Z') || (runes[i] >= '0' && runes[i] <= '9')) {
+			i++
+		}
+		if i == start+1 && strings.ContainsRune("@*#?$!-", next) {
+			return start + 1
+		}
+		return i - 1
+	}
+}
+
+// shellWords splits a command into words the way the shell would, without running any expansions. It returns false if a quote or substitution is unterminated.
+func shellWords(text string) ([]shellWord, bool) {
+	var words []shellWord
+	var raw, value, unquoted strings.Builder
+	inWord, literal := false, true
+
+	flush := func() {
+		if !inWord {
+			return
+		}
+		w := shellWord{raw: raw.String(), value: value.String(), literal: literal}
+		u := unquoted.String()
+		if strings.ContainsAny(u, "*?") ||
+			(strings.Contains(u, "[") && w.value != "[" && w.value != "[[") ||
+			(strings.Contains(u, "{") && strings.Contains(u, "}") && (strings.Contains(u, ",") || strings.Contains(u, ".."))) {
+			w.literal = false
+		}
+		words = append(words, w)
+		raw.Reset()
+		value.Reset()
+		unquoted.Reset()
+		inWord, literal = false, true
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t':
+			flush()
+		case c == '\\':
+			inWord = true
+			raw.WriteRune(c)
+			if i+1 < len(runes) {
+				i++
+				raw.WriteRune(runes[i])
+				value.WriteRune(runes[i])
+			}
+		case c == '\'':
+			end := closingQuote(runes, i)
+			if end < 0 {
+				return nil, false
+			}
+			inWord = true
+			raw.WriteString(string(runes[i : end+1]))
+			value.WriteString(string(runes[i+1 : end]))
+			i = end
+		case c == '"':
+			inWord = true
+			raw.WriteRune(c)
+			end := -1
+			for j := i + 1; j < len(runes); j++ {
+				d := runes[j]
+				if d == '\\' && j+1 < len(runes) && strings.ContainsRune("$`\"\\", runes[j+1]) {
+					raw.WriteRune(d)
+					raw.WriteRune(runes[j+1])
+					value.WriteRune(runes[j+1])
+					j++
+					continue
+				}
+				if d == '"' {
+					end = j
+					break
+				}
+				if d == '$' || d == '`' {
+					n := expansionEnd(runes, j)
+					if n < 0 {
+						return nil, false
+					}
+					literal = false
+					raw.WriteString(string(runes[j : n+1]))
+					value.WriteString(string(runes[j : n+1]))
+					j = n
+					continue
+				}
+				raw.WriteRune(d)
+				value.WriteRune(d)
+			}
+			if end < 0 {
+				return nil, false
+			}
+			raw.WriteRune('"')
+			i = end
+		case c == '$' || c == '`' || ((c == '<' || c == '>') && i+1 < len(runes) && runes[i+1] == '('):
+			var n int
+			if c == '$' || c == '`' {
+				n = expansionEnd(runes, i)
+			} else {
+				n = matchParen(runes, i+1)
+			}
+			if n < 0 {
+				return nil, false
+			}
+			inWord, literal = true, false
+			raw.WriteString(string(runes[i : n+1]))
+			value.WriteString(string(runes[i : n+1]))
+			i = n
+		default:
+			inWord = true
+			raw.WriteRune(c)
+			value.WriteRune(c)
+			unquoted.WriteRune(c)
+		}
+	}
+	flush()
+
+	return words, true
+}
+
+// parseShellCommand parses a single command, returning false if the segment doesn't run anything
+func parseShellCommand(segment string) (scriptCommand, bool) {
+	words, ok := shellWords(segment)
+	if !ok {
+		return scriptCommand{text: strings.Join(strings.Fields(segment), " "), unparseable: true}, true
+	}
+
+	words = stripCommandPrefix(words)
+	if len(words) == 0 {
+		return scriptCommand{}, false
+	}
+
+	first := words[0].raw
+	if len(words) == 1 && shellBlockEnds[first] {
+		return scriptCommand{}, false
+	}
+
+	// loop headers and function definitions don't run anything themselves
+	if first == "for" || first == "case" || first == "select" || first == "function" || strings.HasSuffix(first, "()") || (len(words) > 1 && words[1].raw == "()") {
+		return scriptCommand{}, false
+	}
+
+	raws := make([]string, len(words))
+	for i, w := range words {
+		raws[i] = w.raw
+	}
+
+	return scriptCommand{
+		text:        strings.Join(raws, " "),
+		words:       words,
+		unparseable: !words[0].literal,
+	}, true
+}
+
+func stripCommandPrefix(words []shellWord) []shellWord {
+	for len(words) > 0 {
+		w := words[0]
+		switch {
+		case shellPrefixKeywords[w.raw], envAssignmentRegexp.MatchString(w.raw):
+			words = words[1:]
+		case redirectionRegexp.MatchString(w.raw):
+			words = words[1:]
+			// the target is the next word if it isn't attached
+			if redirectionRegexp.FindString(w.raw) == w.raw && len(words) > 0 {
+				words = words[1:]
+			}
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// programs returns the command along with any commands it runs through wrappers like sudo or env. Wrapper options aren't parsed, so every later word is tried as the start of a command. unparseable is set if a wrapper runs a word that's expanded at runtime.
+func (c scriptCommand) programs() ([][]shellWord, bool) {
+	if len(c.words) == 0 {
+		return nil, c.unparseable
+	}
+
+	programs := [][]shellWord{c.words}
+	if !execWrapperCommands[commandName(c.words[0].value)] {
+		return programs, false
+	}
+
+	for i := 1; i < len(c.words); i++ {
+		w := c.words[i]
+		if strings.HasPrefix(w.value, "-") || envAssignmentRegexp.MatchString(w.raw) || redirectionRegexp.MatchString(w.raw) {
+			continue
+		}
+		if !w.literal {
+			// probably an option's argument, like 'sudo -u $USER'
+			if strings.HasPrefix(c.words[i-1].value, "-") {
+				continue
+			}
+			return programs, true
+		}
+		programs = append(programs, c.words[i:])
+	}
+
+	return programs, false
+}
+
+// denyCandidates are the forms of the command checked against deny patterns: as written, with quotes removed, and with the program's directory removed, for the command and everything it runs through wrappers
+func (c scriptCommand) denyCandidates() []string {
+	programs, _ := c.programs()
+	if len(programs) == 0 {
+		return []string{c.text}
+	}
+
+	var candidates []string
+	for _, program := range programs {
+		raws := make([]string, len(program))
+		values := make([]string, len(program))
+		for i, w := range program {
+			raws[i] = w.raw
+			values[i] = w.value
+		}
+		candidates = append(candidates, strings.Join(raws, " "), strings.Join(values, " "))
+		values[0] = commandName(values[0])
+		candidates = append(candidates, strings.Join(values, " "))
+	}
+	return candidates
+}
+
+// shellScripts returns the scripts a program runs through a shell's -c flag, a here-string, or eval. readsStdin is set for a shell that reads its script from stdin. unparseable is set if the script is built at runtime.
+func shellScripts(program []shellWord) (scripts []string, readsStdin bool, unparseable bool) {
+	name := commandName(program[0].value)
+
+	if name == "eval" {
+		var parts []string
+		for _, w := range program[1:] {
+			if !w.literal {
+				return nil, false, true
+			}
+			parts = append(parts, w.value)
+		}
+		return []string{strings.Join(parts, " ")}, false, false
+	}
+
+	if !execShellCommands[name] {
+		return nil, false, false
+	}
+
+	readsStdin = true
+	for i := 1; i < len(program); i++ {
+		w := program[i]
+
+		if op := redirectionRegexp.FindString(w.raw); op != "" {
+			target := strings.TrimPrefix(w.raw, op)
+			targetWord := shellWord{value: target, literal: true}
+			if target == "" && i+1 < len(program) {
+				i++
+				targetWord = program[i]
+			}
+			if strings.HasSuffix(op, "<<<") {
+				if !targetWord.literal {
+					return nil, false, true
+				}
+				scripts = append(scripts, targetWord.value)
+				readsStdin = false
+			} else if strings.Contains(op, "<<") {
+				// a heredoc is still stdin
+			} else if strings.HasSuffix(op, "<") {
+				// reads its script from a file
+				readsStdin = false
+			}
+			continue
+		}
+
+		if w.value == "-o" || w.value == "+o" || w.value == "-O" || w.value == "+O" {
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(w.value, "-") && w.value != "-" && w.value != "--" && strings.Contains(w.value, "c") {
+			if i+1 >= len(program) || !program[i+1].literal {
+				return nil, false, true
+			}
+			return append(scripts, program[i+1].value), false, false
+		}
+
+		if !strings.HasPrefix(w.value, "-") && !strings.HasPrefix(w.value, "+") {
+			// runs a script file
+			return scripts, false, false
+		}
+	}
+
+	return scripts, readsStdin, false
+}
+
+func commandName(command string) string {
+	return command[strings.LastIndex(command, "/")+1:]
+}
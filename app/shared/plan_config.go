@@ -50,6 +50,42 @@ var AutoModeLabels = map[AutoModeType]string{}
 // populated in init()
 var AutoModeChoices []string
 
+type ExecSandboxMode string
+
+const (
+	ExecSandboxModeNone    ExecSandboxMode = "none"
+	ExecSandboxModeSandbox ExecSandboxMode = "sandbox"
+)
+
+var ExecSandboxModeChoices = []string{string(ExecSandboxModeNone), string(ExecSandboxModeSandbox)}
+
+// EmbeddingModelBM25 ranks the semantic index locally with bm25 instead of calling an embedding model
+const EmbeddingModelBM25 = "bm25"
+
+// any other embedding model served by the embedding provider can be set as a custom choice
+var EmbeddingModelChoices = []string{EmbeddingModelBM25, "text-embedding-3-small", "text-embedding-3-large"}
+
+// providers with an OpenAI-compatible embeddings endpoint
+var EmbeddingProviderChoices = []string{
+	string(ModelProviderOpenAI),
+	string(ModelProviderOpenRouter),
+	string(ModelProviderOllama),
+}
+
+// ExecSandboxConfig controls how _apply.sh is executed. In 'sandbox' mode, commands run with a read-only filesystem outside the project root, only allowlisted env vars, and optional network and resource limits. Zero limits mean no limit.
+type ExecSandboxConfig struct {
+	Mode           ExecSandboxMode `json:"mode"`
+	DisableNetwork bool            `json:"disableNetwork"`
+	EnvAllowlist   []string        `json:"envAllowlist"`
+	CpuSeconds     int             `json:"cpuSeconds"`
+	MemoryMb       int             `json:"memoryMb"`
+	TimeoutSeconds int             `json:"timeoutSeconds"`
+}
+
+func (c ExecSandboxConfig) Enabled() bool {
+	return c.Mode == ExecSandboxModeSandbox
+}
+
 type PlanConfig struct {
 	AutoMode AutoModeType `json:"autoMode"`
 	// QuietMode bool         `json:"quietMode"`
@@ -62,6 +98,10 @@ type PlanConfig struct {
 	AutoLoadContext   bool `json:"autoContext"`
 	SmartContext      bool `json:"smartContext"`
 
+	SemanticIndex     bool          `json:"semanticIndex"`
+	EmbeddingModel    string        `json:"embeddingModel"`
+	EmbeddingProvider ModelProvider `json:"embeddingProvider"`
+
 	// AutoApproveContext bool `json:"autoApproveContext"`
 	// QuietContext       bool `json:"quietContext"`
 
@@ -81,6 +121,8 @@ type PlanConfig struct {
 
 	AutoRevertOnRewind bool `json:"autoRevertOnRewind"`
 
+	ExecSandbox ExecSandboxConfig `json:"execSandbox"`
+
 	// ReplMode    bool     `json:"replMode"`
 	// DefaultRepl ReplType `json:"defaultRepl"`
 
@@ -120,6 +162,27 @@ func (p PlanConfig) Value() (driver.Value, error) {
 	return json.Marshal(p)
 }
 
+// GetEmbeddingModel returns the model used to rank the semantic index, defaulting to local bm25
+func (p *PlanConfig) GetEmbeddingModel() string {
+	if p.EmbeddingModel == "" {
+		return EmbeddingModelBM25
+	}
+	return p.EmbeddingModel
+}
+
+// GetEmbeddingProvider returns the provider that serves the embedding model, defaulting to OpenAI
+func (p *PlanConfig) GetEmbeddingProvider() ModelProvider {
+	if p.EmbeddingProvider == "" {
+		return ModelProviderOpenAI
+	}
+	return p.EmbeddingProvider
+}
+
+// UsesEmbeddings is true when the semantic index is ranked with an embedding model rather than locally with bm25
+func (p *PlanConfig) UsesEmbeddings() bool {
+	return p.SemanticIndex && p.GetEmbeddingModel() != EmbeddingModelBM25
+}
+
 func (p *PlanConfig) SetAutoMode(mode AutoModeType) {
 	p.AutoMode = mode
 
@@ -323,6 +386,48 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.SmartContext)
 		},
 	},
+	"semanticindex": {
+		Name: "semantic-index",
+		Desc: "Index project code and pass the most relevant definitions to auto context (helps on large projects)",
+		Visible: func(p *PlanConfig) bool {
+			return p.AutoLoadContext
+		},
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.SemanticIndex = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.SemanticIndex)
+		},
+	},
+	"embeddingmodel": {
+		Name: "embedding-model",
+		Desc: "Model that ranks the semantic index--bm25 runs locally, others are called through the embedding provider",
+		Visible: func(p *PlanConfig) bool {
+			return p.AutoLoadContext && p.SemanticIndex
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.EmbeddingModel = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return p.GetEmbeddingModel()
+		},
+		Choices:         &EmbeddingModelChoices,
+		HasCustomChoice: true,
+	},
+	"embeddingprovider": {
+		Name: "embedding-provider",
+		Desc: "Provider that serves the embedding model, using its API key from your environment",
+		Visible: func(p *PlanConfig) bool {
+			return p.AutoLoadContext && p.UsesEmbeddings()
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.EmbeddingProvider = ModelProvider(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return string(p.GetEmbeddingProvider())
+		},
+		Choices: &EmbeddingProviderChoices,
+	},
 	"autocommit": {
 		Name: "auto-commit",
 		Desc: "Automatically commit changes to git after apply",
@@ -452,6 +557,94 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%d", p.AutoDebugTries)
 		},
 	},
+	"execsandbox": {
+		Name: "exec-sandbox",
+		Desc: "Run commands in a sandbox: read-only filesystem outside the project, allowlisted env vars, optional network and resource limits",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecSandbox.Mode = ExecSandboxMode(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			if p.ExecSandbox.Mode == "" {
+				return string(ExecSandboxModeNone)
+			}
+			return string(p.ExecSandbox.Mode)
+		},
+		Choices: &ExecSandboxModeChoices,
+	},
+	"execdisablenetwork": {
+		Name: "exec-disable-network",
+		Desc: "Disable network access for sandboxed commands",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.ExecSandbox.Enabled()
+		},
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.ExecSandbox.DisableNetwork = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.ExecSandbox.DisableNetwork)
+		},
+	},
+	"execenvallowlist": {
+		Name: "exec-env-allowlist",
+		Desc: "Comma-separated env vars to pass to sandboxed commands (in addition to PATH, HOME, etc.)",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.ExecSandbox.Enabled()
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecSandbox.EnvAllowlist = nil
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name != "" {
+					p.ExecSandbox.EnvAllowlist = append(p.ExecSandbox.EnvAllowlist, name)
+				}
+			}
+		},
+		Getter: func(p *PlanConfig) string {
+			return strings.Join(p.ExecSandbox.EnvAllowlist, ",")
+		},
+	},
+	"execcpulimit": {
+		Name: "exec-cpu-limit",
+		Desc: "CPU time limit in seconds for sandboxed commands (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.ExecSandbox.Enabled()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandbox.CpuSeconds = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.ExecSandbox.CpuSeconds)
+		},
+	},
+	"execmemorylimit": {
+		Name: "exec-memory-limit",
+		Desc: "Memory limit in MB for sandboxed commands (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.ExecSandbox.Enabled()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandbox.MemoryMb = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.ExecSandbox.MemoryMb)
+		},
+	},
+	"exectimeout": {
+		Name: "exec-timeout",
+		Desc: "Wall-clock time limit in seconds for sandboxed commands (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.ExecSandbox.Enabled()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandbox.TimeoutSeconds = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.ExecSandbox.TimeoutSeconds)
+		},
+	},
 	"autorevert": {
 		Name: "auto-revert",
 		Desc: "Automatically update project files when rewinding plan",
@@ -22,6 +22,17 @@ func (res *PlanFileResult) NumPendingReplacements() int {
 	return numPending
 }
 
+// NonRejectedReplacements returns the replacements that haven't been rejected during review
+func (res *PlanFileResult) NonRejectedReplacements() []*Replacement {
+	var replacements []*Replacement
+	for _, rep := range res.Replacements {
+		if rep.RejectedAt == nil {
+			replacements = append(replacements, rep)
+		}
+	}
+	return replacements
+}
+
 func (res *PlanFileResult) IsPending() bool {
 	return res.AppliedAt == nil && res.RejectedAt == nil && (res.Content != "" || res.NumPendingReplacements() > 0 || res.RemovedFile)
 }
@@ -97,7 +108,7 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 			}
 
 			var succeeded bool
-			updated, succeeded = ApplyReplacements(maybeWithLineNums, res.Replacements, false)
+			updated, succeeded = ApplyReplacements(maybeWithLineNums, res.NonRejectedReplacements(), false)
 
 			updated = RemoveLineNums(LineNumberedTextType(updated))
 
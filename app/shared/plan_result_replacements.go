@@ -87,7 +87,7 @@ func applyReplacements(content string, replacements []*Replacement, setFailed, v
 				lastInsertedIdx = 0
 			} else {
 				if verbose {
-					log.Printf("originalIdx: %%d, len(replacement.Old): %%d\n", originalIdx, len(replacement.Old))
+					log.Printf("originalIdx: %d, len(replacement.Old): %d\n", originalIdx, len(replacement.Old))
 					log.Println("Old: ", replacement.Old)
 					log.Println("New: ", replacement.New)
 				}
@@ -101,7 +101,7 @@ func applyReplacements(content string, replacements []*Replacement, setFailed, v
 				updated = pre + replaced
 
 				if verbose {
-					log.Printf("lastInsertedIdx: %%d, originalIdx: %%d, len(replacement.New): %%d\n", lastInsertedIdx, originalIdx, len(replacement.New))
+					log.Printf("lastInsertedIdx: %d, originalIdx: %d, len(replacement.New): %d\n", lastInsertedIdx, originalIdx, len(replacement.New))
 					log.Println("updated after replacement:")
 					log.Println(updated)
 				}
@@ -227,6 +227,10 @@ func (planState *CurrentPlanState) GetFilesBeforeReplacement(
 					foundTarget = true
 					break
 				}
+				// rejected hunks are left out so the rest of the file can still be applied
+				if replacement.RejectedAt != nil {
+					continue
+				}
 				replacements = append(replacements, replacement)
 			}
 
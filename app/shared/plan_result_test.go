@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNonRejectedReplacements(t *testing.T) {
+	now := time.Now()
+	kept := &Replacement{Id: "kept"}
+	failed := &Replacement{Id: "failed", Failed: true}
+	rejected := &Replacement{Id: "rejected", RejectedAt: &now}
+
+	res := &PlanFileResult{Replacements: []*Replacement{kept, rejected, failed}}
+
+	got := res.NonRejectedReplacements()
+	if len(got) != 2 || got[0] != kept || got[1] != failed {
+		t.Errorf("NonRejectedReplacements() = %+v", got)
+	}
+
+	// failed replacements aren't pending, but they aren't rejected either
+	if res.NumPendingReplacements() != 1 {
+		t.Errorf("NumPendingReplacements() = %d, want 1", res.NumPendingReplacements())
+	}
+
+	allRejected := &PlanFileResult{Replacements: []*Replacement{rejected}}
+	if len(allRejected.NonRejectedReplacements()) != 0 {
+		t.Errorf("expected no replacements")
+	}
+	if allRejected.IsPending() {
+		t.Errorf("a result with every replacement rejected shouldn't be pending")
+	}
+}
@@ -0,0 +1,28 @@
+package shared
+
+import "time"
+
+type PlanShareAccess string
+
+const (
+	// can view the plan, its context, convo, changes and logs, and connect to its streams
+	PlanShareAccessRead PlanShareAccess = "read"
+	// can also tell, build, apply, reject, and update context, settings and branches--everything but renaming, archiving or deleting the plan
+	PlanShareAccessCollaborator PlanShareAccess = "collaborator"
+)
+
+func IsValidPlanShareAccess(access PlanShareAccess) bool {
+	return access == PlanShareAccessRead || access == PlanShareAccessCollaborator
+}
+
+type PlanShare struct {
+	Id        string          `json:"id"`
+	PlanId    string          `json:"planId"`
+	UserId    string          `json:"userId"`
+	UserEmail string          `json:"userEmail"`
+	UserName  string          `json:"userName"`
+	Access    PlanShareAccess `json:"access"`
+	CreatedBy *string         `json:"createdBy,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
@@ -0,0 +1,24 @@
+package shared
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ProtectedConfigFileNames are project config files that control which commands Plandex runs. Plans can't create, change, or remove them, since a plan could otherwise give itself commands to run.
+var ProtectedConfigFileNames = []string{
+	DiagnosticsConfigFileName,
+	VerifyConfigFileName,
+	ExecPolicyFileName,
+}
+
+// IsProtectedConfigPath reports whether a project-relative path is one of the protected config files in the project root. It's case-insensitive, since the project may be on a case-insensitive filesystem.
+func IsProtectedConfigPath(path string) bool {
+	cleaned := filepath.ToSlash(filepath.Clean(path))
+	for _, name := range ProtectedConfigFileNames {
+		if strings.EqualFold(cleaned, name) {
+			return true
+		}
+	}
+	return false
+}
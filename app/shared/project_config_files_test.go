@@ -0,0 +1,26 @@
+package shared
+
+import "testing"
+
+func TestIsProtectedConfigPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{DiagnosticsConfigFileName, true},
+		{"./" + DiagnosticsConfigFileName, true},
+		{"src/../" + DiagnosticsConfigFileName, true},
+		{".PLANDEX-DIAGNOSTICS.JSON", true},
+		{VerifyConfigFileName, true},
+		{ExecPolicyFileName, true},
+		{"src/" + DiagnosticsConfigFileName, false},
+		{"diagnostics.json", false},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsProtectedConfigPath(tt.path); got != tt.want {
+			t.Errorf("IsProtectedConfigPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -23,11 +23,49 @@ const (
 	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
 	PermissionUpdateAnyPlan         Permission = "update_any_plan"
 	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionManageExecPolicy      Permission = "manage_exec_policy"
+	PermissionManageWebhooks        Permission = "manage_webhooks"
+	PermissionManageServiceAccounts Permission = "manage_service_accounts"
+	PermissionManageOrgRoles        Permission = "manage_org_roles"
+	PermissionApplyPlan             Permission = "apply_plan"
+	PermissionExecCommands          Permission = "exec_commands"
+	PermissionManageCustomModels    Permission = "manage_custom_models"
+	PermissionManageModelPacks      Permission = "manage_model_packs"
 )
 
+// role-scoped permissions apply to users and invites with a particular role, so they're always granted with that role's id as the resource
+var roleScopedPermissions = map[Permission]bool{
+	PermissionInviteUser:  true,
+	PermissionRemoveUser:  true,
+	PermissionSetUserRole: true,
+}
+
+func IsRoleScopedPermission(permission Permission) bool {
+	return roleScopedPermissions[permission]
+}
+
+// PermissionKey formats a permission the way it's keyed in Permissions: 'name' or 'name|resourceId'
+func PermissionKey(permission Permission, resourceId string) string {
+	if resourceId == "" {
+		return string(permission)
+	}
+	return string(permission) + "|" + resourceId
+}
+
+// ParsePermissionKey splits a key from PermissionKey into its permission and resource id (empty if it has none)
+func ParsePermissionKey(key string) (Permission, string) {
+	perm, resourceId, _ := strings.Cut(key, "|")
+	return Permission(perm), resourceId
+}
+
 type Permissions map[string]bool
 
+// HasPermission matches either a full key like 'invite_user|roleId' or a bare permission name, which matches the permission for any resource
 func (perms Permissions) HasPermission(permission Permission) bool {
+	if perms[string(permission)] {
+		return true
+	}
+
 	for p := range perms {
 		split := strings.Split(p, "|")
 		perm := Permission(split[0])
@@ -41,9 +79,7 @@ func (perms Permissions) HasPermission(permission Permission) bool {
 
 func (perms Permissions) HasPermissionForResource(permission Permission, resourceId string) bool {
 	for p := range perms {
-		split := strings.Split(p, "|")
-		perm := Permission(split[0])
-		resId := split[1]
+		perm, resId := ParsePermissionKey(p)
 
 		if perm == permission && resId == resourceId {
 			return true
@@ -0,0 +1,61 @@
+package shared
+
+import "testing"
+
+func TestPermissionKey(t *testing.T) {
+	key := PermissionKey(PermissionInviteUser, "role-id")
+	if key != "invite_user|role-id" {
+		t.Fatalf("unexpected key: %s", key)
+	}
+
+	perm, resourceId := ParsePermissionKey(key)
+	if perm != PermissionInviteUser || resourceId != "role-id" {
+		t.Fatalf("unexpected parsed key: %s, %s", perm, resourceId)
+	}
+
+	if PermissionKey(PermissionApplyPlan, "") != "apply_plan" {
+		t.Fatalf("expected a permission without a resource to have no separator")
+	}
+
+	perm, resourceId = ParsePermissionKey("apply_plan")
+	if perm != PermissionApplyPlan || resourceId != "" {
+		t.Fatalf("unexpected parsed key: %s, %s", perm, resourceId)
+	}
+}
+
+func TestHasPermissionForResource(t *testing.T) {
+	perms := Permissions{
+		"create_plan":         true,
+		"invite_user|role-id": true,
+	}
+
+	if !perms.HasPermissionForResource(PermissionInviteUser, "role-id") {
+		t.Fatalf("expected invite permission for role")
+	}
+	if perms.HasPermissionForResource(PermissionInviteUser, "other-role-id") {
+		t.Fatalf("expected no invite permission for other role")
+	}
+	if perms.HasPermissionForResource(PermissionCreatePlan, "role-id") {
+		t.Fatalf("expected a permission without a resource not to match a resource")
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	perms := Permissions{
+		"create_plan":         true,
+		"invite_user|role-id": true,
+	}
+
+	if !perms.HasPermission(PermissionCreatePlan) {
+		t.Fatalf("expected create plan permission")
+	}
+	if !perms.HasPermission(Permission(PermissionKey(PermissionInviteUser, "role-id"))) {
+		t.Fatalf("expected a full key to match")
+	}
+	if perms.HasPermission(Permission(PermissionKey(PermissionInviteUser, "other-role-id"))) {
+		t.Fatalf("expected a full key for another role not to match")
+	}
+	if !perms.HasPermission(PermissionInviteUser) {
+		t.Fatalf("expected a bare name to match the permission for any resource")
+	}
+}
@@ -1,6 +1,9 @@
 package shared
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
@@ -98,6 +101,12 @@ type CreatePlanResponse struct {
 	Name string `json:"name"`
 }
 
+type ImportPlanResponse struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	NumBranches int    `json:"numBranches"`
+}
+
 type GetCurrentBranchByPlanIdRequest struct {
 	CurrentBranchByPlanId map[string]string `json:"currentBranchByPlanId"`
 }
@@ -139,17 +148,47 @@ type TellPlanRequest struct {
 	IsImplementationOfChat bool              `json:"isImplementationOfChat"`
 	IsGitRepo              bool              `json:"isGitRepo"`
 	SessionId              string            `json:"sessionId"`
+	ExecPolicy             *ExecPolicy       `json:"execPolicy,omitempty"`      // project policy from the exec policy file
+	DiagnosticsExts        []string          `json:"diagnosticsExts,omitempty"` // extensions the connected CLI can type check before apply
 }
 
 type BuildPlanRequest struct {
-	ConnectStream bool              `json:"connectStream"`
-	ApiKey        string            `json:"apiKey"`   // deprecated
-	Endpoint      string            `json:"endpoint"` // deprecated
-	ApiKeys       map[string]string `json:"apiKeys"`
-	OpenAIBase    string            `json:"openAIBase"`
-	OpenAIOrgId   string            `json:"openAIOrgId"`
-	ProjectPaths  map[string]bool   `json:"projectPaths"`
-	SessionId     string            `json:"sessionId"`
+	ConnectStream   bool              `json:"connectStream"`
+	ApiKey          string            `json:"apiKey"`   // deprecated
+	Endpoint        string            `json:"endpoint"` // deprecated
+	ApiKeys         map[string]string `json:"apiKeys"`
+	OpenAIBase      string            `json:"openAIBase"`
+	OpenAIOrgId     string            `json:"openAIOrgId"`
+	ProjectPaths    map[string]bool   `json:"projectPaths"`
+	SessionId       string            `json:"sessionId"`
+	DiagnosticsExts []string          `json:"diagnosticsExts,omitempty"` // extensions the connected CLI can type check before apply
+}
+
+// ConnectPlanRequest is sent by a client reconnecting to its own plan stream. If the server running the stream went away, the server that receives it takes the stream over and continues the plan with these credentials.
+type ConnectPlanRequest struct {
+	ApiKeys     map[string]string `json:"apiKeys"`
+	OpenAIBase  string            `json:"openAIBase"`
+	OpenAIOrgId string            `json:"openAIOrgId"`
+}
+
+// model streams store the tell request they were started with (minus credentials) so another server can resume them
+func (r *TellPlanRequest) Scan(src interface{}) error {
+	if src == nil {
+		*r = TellPlanRequest{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, r)
+	case string:
+		return json.Unmarshal([]byte(s), r)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (r TellPlanRequest) Value() (driver.Value, error) {
+	return json.Marshal(r)
 }
 
 const NoBuildsErr string = "No builds"
@@ -187,6 +226,7 @@ type LoadContextParams struct {
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
 	FilePath        string                `json:"file_path"`
+	Symbol          string                `json:"symbol"`
 	Body            string                `json:"body"`
 	ForceSkipIgnore bool                  `json:"forceSkipIgnore"`
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
@@ -225,12 +265,27 @@ type UpdateContextParams struct {
 
 type GetFileMapRequest struct {
 	MapInputs FileMapInputs `json:"mapInputs"`
+	PlanId    string        `json:"planId,omitempty"` // when the plan has the semantic index enabled, inputs are also indexed for its project
 }
 
 type GetFileMapResponse struct {
 	MapBodies FileMapBodies `json:"mapBodies"`
 }
 
+type FileSymbolsInput struct {
+	Content string   `json:"content"`
+	Symbols []string `json:"symbols"`
+}
+
+type GetFileSymbolsRequest struct {
+	Inputs map[string]*FileSymbolsInput `json:"inputs"`
+}
+
+type GetFileSymbolsResponse struct {
+	// source by path, then by symbol--symbols that aren't found in a file are left out
+	Bodies map[string]map[string]string `json:"bodies"`
+}
+
 type LoadCachedFileMapRequest struct {
 	FilePaths []string `json:"filePaths"`
 }
@@ -270,6 +325,17 @@ type RejectFilesRequest struct {
 	Paths []string `json:"paths"`
 }
 
+type RejectReplacementRequest struct {
+	ResultId      string `json:"resultId"`
+	ReplacementId string `json:"replacementId"`
+}
+
+type EditReplacementRequest struct {
+	ResultId      string `json:"resultId"`
+	ReplacementId string `json:"replacementId"`
+	New           string `json:"new"`
+}
+
 type RewindPlanRequest struct {
 	Sha string `json:"sha"`
 }
@@ -279,9 +345,16 @@ type RewindPlanResponse struct {
 	LatestCommit string `json:"latestCommit"`
 }
 
+type LogEntry struct {
+	Sha       string    `json:"sha"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type LogResponse struct {
-	Shas []string `json:"shas"`
-	Body string   `json:"body"`
+	Shas    []string   `json:"shas"`
+	Body    string     `json:"body"`
+	Entries []LogEntry `json:"entries"`
 }
 
 type CreateBranchRequest struct {
@@ -322,6 +395,9 @@ type ApplyPlanRequest struct {
 	OpenAIBase  string            `json:"openAIBase"`
 	OpenAIOrgId string            `json:"openAIOrgId"`
 	SessionId   string            `json:"sessionId"`
+
+	// if set, only pending changes to these paths are applied--the rest stay pending
+	Paths []string `json:"paths,omitempty"`
 }
 
 type RenamePlanRequest struct {
@@ -333,6 +409,65 @@ type GetBuildStatusResponse struct {
 	IsBuildingByPath map[string]bool `json:"isBuildingByPath"`
 }
 
+type SetSpendBudgetRequest struct {
+	Scope    SpendBudgetScope  `json:"scope"`
+	PlanId   *string           `json:"planId,omitempty"`
+	UserId   *string           `json:"userId,omitempty"`
+	Period   SpendBudgetPeriod `json:"period"`
+	LimitUsd decimal.Decimal   `json:"limitUsd"`
+	WarnPct  int               `json:"warnPct"`
+}
+
+type ListSpendBudgetsResponse struct {
+	Budgets        []*SpendBudget    `json:"budgets"`
+	PlanNamesById  map[string]string `json:"planNamesById"`
+	UserEmailsById map[string]string `json:"userEmailsById"`
+}
+
+type UsageRequest struct {
+	PlanId    string     `json:"planId"`
+	SessionId string     `json:"sessionId"`
+	DayStart  *time.Time `json:"dayStart"`
+	Month     bool       `json:"month"`
+}
+
+type UsageBreakdown struct {
+	Spend        decimal.Decimal `json:"spend"`
+	NumRequests  int             `json:"numRequests"`
+	InputTokens  int             `json:"inputTokens"`
+	OutputTokens int             `json:"outputTokens"`
+	CachedTokens int             `json:"cachedTokens"`
+}
+
+// CacheHitRate is the share of input tokens that were served from the prompt cache
+func (b UsageBreakdown) CacheHitRate() float64 {
+	if b.InputTokens == 0 {
+		return 0
+	}
+	return float64(b.CachedTokens) / float64(b.InputTokens)
+}
+
+type UsageSummaryResponse struct {
+	Total        UsageBreakdown  `json:"total"`
+	CacheSavings decimal.Decimal `json:"cacheSavings"`
+
+	MonthStart time.Time `json:"monthStart"`
+
+	ByPlanId      map[string]UsageBreakdown `json:"byPlanId"`
+	PlanNamesById map[string]string         `json:"planNamesById"`
+
+	ByModelName map[string]UsageBreakdown `json:"byModelName"`
+	ByModelRole map[string]UsageBreakdown `json:"byModelRole"`
+	ByPurpose   map[string]UsageBreakdown `json:"byPurpose"`
+}
+
+type UsageLogResponse struct {
+	Entries       []*UsageEntry     `json:"entries"`
+	NumPages      int               `json:"numPages"`
+	MonthStart    time.Time         `json:"monthStart"`
+	PlanNamesById map[string]string `json:"planNamesById"`
+}
+
 // Cloud requests and responses
 type CreditsLogRequest struct {
 	TransactionType CreditsTransactionType `json:"transactionType"`
@@ -369,3 +504,104 @@ type CreditsSummaryResponse struct {
 type GetBalanceResponse struct {
 	Balance decimal.Decimal `json:"balance"`
 }
+
+type CreateWebhookRequest struct {
+	Url    string           `json:"url"`
+	Events WebhookEventList `json:"events"`
+}
+
+type CreateWebhookResponse struct {
+	Webhook *Webhook `json:"webhook"`
+	// used to sign deliveries--only returned here
+	Secret string `json:"secret"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
+
+type SharePlanRequest struct {
+	Email  string          `json:"email"`
+	Access PlanShareAccess `json:"access"`
+}
+
+type CreateApiTokenRequest struct {
+	Name   string            `json:"name"`
+	Scopes ApiTokenScopeList `json:"scopes"`
+	// 0 for a token that doesn't expire
+	ExpiresInDays int `json:"expiresInDays"`
+	// creates a token for the service account rather than a personal access token
+	ServiceAccountId string `json:"serviceAccountId,omitempty"`
+}
+
+type CreateApiTokenResponse struct {
+	ApiToken *ApiToken `json:"apiToken"`
+	Token    string    `json:"token"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name string `json:"name"`
+	// defaults to the member role
+	OrgRoleId string `json:"orgRoleId,omitempty"`
+}
+
+type CreateOrgRoleRequest struct {
+	Name        string   `json:"name"`
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateOrgRoleRequest replaces a custom role's label, description and permissions
+type UpdateOrgRoleRequest struct {
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type SetOrgRoleRequest struct {
+	OrgRoleId string `json:"orgRoleId"`
+}
+
+type ApiTokenSessionResponse struct {
+	ApiToken *ApiToken `json:"apiToken"`
+	UserId   string    `json:"userId"`
+	Email    string    `json:"email"`
+	UserName string    `json:"userName"`
+	Org      *Org      `json:"org"`
+}
+
+type OidcConfigResponse struct {
+	Enabled bool   `json:"enabled"`
+	Issuer  string `json:"issuer,omitempty"`
+	// email pin sign in is disabled
+	Required bool `json:"required"`
+}
+
+type StartOidcDeviceAuthResponse struct {
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationUri         string `json:"verificationUri"`
+	VerificationUriComplete string `json:"verificationUriComplete,omitempty"`
+	ExpiresIn               int    `json:"expiresIn"`
+	// seconds to wait between polls
+	Interval int `json:"interval"`
+}
+
+type PollOidcDeviceAuthRequest struct {
+	DeviceCode string `json:"deviceCode"`
+}
+
+type OidcDeviceAuthStatus string
+
+const (
+	OidcDeviceAuthStatusPending  OidcDeviceAuthStatus = "pending"
+	OidcDeviceAuthStatusSlowDown OidcDeviceAuthStatus = "slow_down"
+	OidcDeviceAuthStatusComplete OidcDeviceAuthStatus = "complete"
+)
+
+type PollOidcDeviceAuthResponse struct {
+	Status OidcDeviceAuthStatus `json:"status"`
+	// set when status is complete
+	Session *SessionResponse `json:"session,omitempty"`
+}
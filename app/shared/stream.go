@@ -21,9 +21,11 @@ const (
 	StreamMessageBuildInfo         StreamMessageType = "buildInfo"
 	StreamMessagePromptMissingFile StreamMessageType = "promptMissingFile"
 	StreamMessageLoadContext       StreamMessageType = "loadContext"
+	StreamMessageDiagnostics       StreamMessageType = "diagnostics"
 	StreamMessageAborted           StreamMessageType = "aborted"
 	StreamMessageFinished          StreamMessageType = "finished"
 	StreamMessageError             StreamMessageType = "error"
+	StreamMessageWarning           StreamMessageType = "warning"
 
 	StreamMessageMulti StreamMessageType = "multi"
 )
@@ -31,11 +33,15 @@ const (
 type StreamMessage struct {
 	Type StreamMessageType `json:"type"`
 
+	// monotonic per active plan stream, starting at 1--messages sent only to a single connection (start, connectActive) have no seq
+	Seq int64 `json:"seq,omitempty"`
+
 	ReplyChunk string `json:"replyChunk,omitempty"`
 
 	BuildInfo              *BuildInfo               `json:"buildInfo,omitempty"`
 	Description            *ConvoMessageDescription `json:"description,omitempty"`
 	Error                  *ApiError                `json:"error,omitempty"`
+	Warning                string                   `json:"warning,omitempty"`
 	MissingFilePath        string                   `json:"missingFilePath,omitempty"`
 	MissingFileAutoContext bool                     `json:"missingFileAutoContext,omitempty"`
 	ModelStreamId          string                   `json:"modelStreamId,omitempty"`
@@ -43,6 +49,7 @@ type StreamMessage struct {
 	InitPrompt             string                   `json:"initPrompt,omitempty"`
 	InitReplies            []string                 `json:"initReplies,omitempty"`
 	InitBuildOnly          bool                     `json:"initBuildOnly,omitempty"`
+	DiagnosticsRequest     *DiagnosticsRequest      `json:"diagnosticsRequest,omitempty"`
 
 	StreamMessages []StreamMessage `json:"streamMessages,omitempty"`
 }
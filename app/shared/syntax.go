@@ -81,16 +81,13 @@ var Languages = []Language{
 
 var lacksFileMapSupport = []Language{
 	// config languages aren't mapped, model decides whether to load them based on file name
-	LanguageHcl,
 	LanguageYaml,
 	LanguageToml,
 	LanguageCue,
 	LanguageJson,
-	LanguageProtobuf,
 
 	// these just need more work for mapping
 	LanguageGroovy,
-	LanguageOCaml,
 }
 
 var SkipTreeSitter = map[Language]bool{
@@ -0,0 +1,11 @@
+package shared
+
+const VerifyConfigFileName = ".plandex-verify.json"
+
+// VerifyConfig is a project's verification command, like 'go test ./...'. It runs in the project root after changes are applied, and if it fails, the changes are rolled back.
+type VerifyConfig struct {
+	Command string `json:"command"`
+
+	// defaults to 10 minutes
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
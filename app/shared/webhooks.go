@@ -0,0 +1,151 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventPlanFinished   WebhookEvent = "plan_finished"
+	WebhookEventPlanError      WebhookEvent = "plan_error"
+	WebhookEventBuildFinished  WebhookEvent = "build_finished"
+	WebhookEventMissingFile    WebhookEvent = "missing_file"
+	WebhookEventChangesApplied WebhookEvent = "changes_applied"
+
+	// sent by 'plandex webhooks test'--every webhook receives it regardless of its events
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEventPlanFinished,
+	WebhookEventPlanError,
+	WebhookEventBuildFinished,
+	WebhookEventMissingFile,
+	WebhookEventChangesApplied,
+}
+
+func IsValidWebhookEvent(event WebhookEvent) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	WebhookSignatureHeader = "X-Plandex-Signature"
+	WebhookTimestampHeader = "X-Plandex-Timestamp"
+	WebhookEventHeader     = "X-Plandex-Event"
+	WebhookDeliveryHeader  = "X-Plandex-Delivery"
+)
+
+// SignWebhookPayload returns the X-Plandex-Signature header value for a delivery: 'sha256=' followed by the hex HMAC-SHA256 of '<timestamp>.<body>' keyed with the webhook's secret. Including the timestamp lets receivers reject replayed deliveries.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a delivery's X-Plandex-Signature header in constant time
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	expected := SignWebhookPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature)))
+}
+
+type WebhookEventList []WebhookEvent
+
+func (l WebhookEventList) Includes(event WebhookEvent) bool {
+	for _, e := range l {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *WebhookEventList) Scan(src interface{}) error {
+	if src == nil {
+		*l = WebhookEventList{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, l)
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (l WebhookEventList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// Webhook is an outgoing webhook configured for an org on a self-hosted server. Its secret is only returned when it's created.
+type Webhook struct {
+	Id     string           `json:"id"`
+	OrgId  string           `json:"orgId"`
+	Url    string           `json:"url"`
+	Events WebhookEventList `json:"events"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	WebhookId      string                `json:"webhookId"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode *int                  `json:"lastStatusCode,omitempty"`
+	LastError      *string               `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+// WebhookPayload is the JSON body of every webhook delivery
+type WebhookPayload struct {
+	Id        string       `json:"id"`
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"createdAt"`
+	OrgId     string       `json:"orgId"`
+	UserId    string       `json:"userId,omitempty"`
+	PlanId    string       `json:"planId,omitempty"`
+	PlanName  string       `json:"planName,omitempty"`
+	Branch    string       `json:"branch,omitempty"`
+
+	// build_finished and missing_file
+	Path string `json:"path,omitempty"`
+
+	// changes_applied
+	Paths     []string `json:"paths,omitempty"`
+	CommitMsg string   `json:"commitMsg,omitempty"`
+
+	// plan_error
+	Error string `json:"error,omitempty"`
+
+	// a one-line summary of the event--the field name works with Slack and Mattermost incoming webhooks as-is
+	Text string `json:"text"`
+}
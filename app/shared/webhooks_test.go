@@ -0,0 +1,30 @@
+package shared
+
+import "testing"
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"plan_finished","text":"Plan 'auth' finished on branch main"}`)
+
+	// computed independently with: printf '1700000000.%s' "$body" | openssl dgst -sha256 -hmac secret
+	sig := SignWebhookPayload("secret", "1700000000", body)
+	want := "sha256=e9a2bc9e60edbafdb385ed7f186b067140a18d3d919bed59d97841375a4257e0"
+	if sig != want {
+		t.Fatalf("got signature %s, want %s", sig, want)
+	}
+
+	if !VerifyWebhookSignature("secret", "1700000000", body, sig) {
+		t.Fatalf("expected signature to verify")
+	}
+
+	if VerifyWebhookSignature("other-secret", "1700000000", body, sig) {
+		t.Fatalf("expected signature with a different secret to fail")
+	}
+
+	if VerifyWebhookSignature("secret", "1700000001", body, sig) {
+		t.Fatalf("expected signature with a different timestamp to fail")
+	}
+
+	if VerifyWebhookSignature("secret", "1700000000", append(body, ' '), sig) {
+		t.Fatalf("expected signature of a modified body to fail")
+	}
+}
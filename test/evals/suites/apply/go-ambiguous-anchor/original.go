@@ -0,0 +1,13 @@
+package store
+
+func (s *Store) Save(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Put(item)
+}
+
+func (s *Store) Delete(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Remove(item)
+}
@@ -0,0 +1,8 @@
+// ... existing code ...
+
+func (s *Store) Delete(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log.Printf("deleting %s", item.Id)
+	return s.db.Remove(item)
+}
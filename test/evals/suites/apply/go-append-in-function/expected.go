@@ -0,0 +1,10 @@
+package users
+
+func processUser(id int) error {
+	validate(id)
+	startTx()
+	updateUser(id)
+	commit()
+	log.Info("processing user")
+	return nil
+}
@@ -0,0 +1,9 @@
+package users
+
+func processUser(id int) error {
+	validate(id)
+	startTx()
+	updateUser(id)
+	commit()
+	return nil
+}
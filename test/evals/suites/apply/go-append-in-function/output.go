@@ -0,0 +1,7 @@
+package users
+
+func processUser(id int) error {
+	// ... existing code ...
+	log.Info("processing user")
+	return nil
+}
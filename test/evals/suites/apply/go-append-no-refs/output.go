@@ -0,0 +1,6 @@
+func Min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,16 @@
+package handlers
+
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	user, err := decodeUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	saveUser(user)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func DeleteUser(w http.ResponseWriter, r *http.Request) {
+	deleteUser(r.URL.Query().Get("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
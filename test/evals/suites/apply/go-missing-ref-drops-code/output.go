@@ -0,0 +1,16 @@
+// ... existing code ...
+
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateUser")
+	user, err := decodeUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	saveUser(user)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func DeleteUser(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteUser")
+}
@@ -0,0 +1,24 @@
+package users
+
+import "log"
+
+func init() {
+	log.Println("init")
+}
+
+type UserService struct {
+	db    *DB
+	cache *Cache
+}
+
+func (s *UserService) Process() {
+	s.validate()
+	s.update()
+	s.notify()
+}
+
+func (s *UserService) Update() {
+	s.db.begin()
+	s.db.exec()
+	s.db.commit()
+}
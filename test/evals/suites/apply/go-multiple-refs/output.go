@@ -0,0 +1,14 @@
+// ... existing code ...
+
+type UserService struct {
+	// ... existing code ...
+	metrics *Metrics
+}
+
+func (s *UserService) Process() {
+	// ... existing code ...
+	s.metrics.Record()
+	// ... existing code ...
+}
+
+// ... existing code ...
@@ -0,0 +1,5 @@
+package version
+
+const Version = "1.1.0"
+
+const Codename = "lark"
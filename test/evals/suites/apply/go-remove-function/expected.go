@@ -0,0 +1,11 @@
+package util
+
+import "strings"
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
@@ -0,0 +1,15 @@
+package util
+
+import "strings"
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func LegacyTrim(s string) string {
+	return strings.Trim(s, " ")
+}
+
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
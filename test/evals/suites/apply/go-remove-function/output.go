@@ -0,0 +1,11 @@
+// ... existing code ...
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// Plandex: removed code
+
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
@@ -0,0 +1,10 @@
+package worker
+
+func (w *Worker) Run() {
+	for job := range w.jobs {
+		if job == nil {
+			continue
+		}
+		w.process(job)
+	}
+}
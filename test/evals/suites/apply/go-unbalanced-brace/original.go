@@ -0,0 +1,7 @@
+package worker
+
+func (w *Worker) Run() {
+	for job := range w.jobs {
+		w.process(job)
+	}
+}
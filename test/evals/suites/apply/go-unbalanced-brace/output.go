@@ -0,0 +1,9 @@
+// ... existing code ...
+
+func (w *Worker) Run() {
+	for job := range w.jobs {
+		if job == nil {
+			continue
+		w.process(job)
+	}
+}
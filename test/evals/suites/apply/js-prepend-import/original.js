@@ -0,0 +1,3 @@
+const app = express();
+
+app.listen(3000);
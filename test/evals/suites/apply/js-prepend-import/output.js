@@ -0,0 +1,3 @@
+const express = require("express");
+
+// ... existing code ...
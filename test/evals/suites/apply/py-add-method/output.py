@@ -0,0 +1,5 @@
+class Cart:
+    # ... existing code ...
+
+    def clear(self):
+        self.items = []
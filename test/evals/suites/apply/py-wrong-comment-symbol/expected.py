@@ -0,0 +1,7 @@
+import os
+
+DEBUG = os.getenv("DEBUG") == "1"
+
+
+def load():
+    return {"debug": DEBUG, "port": int(os.getenv("PORT", "8000"))}
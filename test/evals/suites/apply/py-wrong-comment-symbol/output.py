@@ -0,0 +1,4 @@
+// ... existing code ...
+
+def load():
+    return {"debug": DEBUG, "port": int(os.getenv("PORT", "8000"))}
@@ -0,0 +1,9 @@
+// ... existing code ...
+
+impl Counter {
+    // ... existing code ...
+
+    pub fn reset(&mut self) {
+        self.count = 0;
+    }
+}
@@ -0,0 +1,11 @@
+export class Cart {
+  private items: Item[] = [];
+
+  add(item: Item) {
+    this.items.push(item);
+  }
+
+  total(): number {
+    return this.items.reduce((sum, item) => sum + item.price, 0);
+  }
+}
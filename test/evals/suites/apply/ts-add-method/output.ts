@@ -0,0 +1,11 @@
+export class Cart {
+  // ... existing code ...
+
+  total(): number {
+    return this.items.reduce((sum, item) => sum + item.price, 0);
+  }
+
+  clear() {
+    this.items = [];
+  }
+}
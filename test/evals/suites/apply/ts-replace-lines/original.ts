@@ -0,0 +1,8 @@
+export function formatPrice(cents: number): string {
+  const dollars = cents / 100;
+  return "$" + dollars;
+}
+
+export function formatName(first: string, last: string): string {
+  return first + " " + last;
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import "fmt"
+
+func greet(name string) {
+	if name == "" {
+		name = "world"
+	}
+	fmt.Println("Hello, " + name)
+}
@@ -0,0 +1,7 @@
+package cmd
+
+import "fmt"
+
+func greet(name string) {
+	fmt.Println("Hello, " + name)
+}
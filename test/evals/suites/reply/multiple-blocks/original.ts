@@ -0,0 +1,4 @@
+export async function getUser(id: string) {
+  const res = await fetch(`/api/users/${id}`);
+  return res.json();
+}
@@ -0,0 +1,2 @@
+def render(code):
+    return code